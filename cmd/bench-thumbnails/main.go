@@ -0,0 +1,129 @@
+// Comando bench-thumbnails compara o tempo e a memória das implementações de miniatura (THUMBNAIL_RESIZER)
+// sobre as mesmas imagens, para decidir se vale instalar a libvips.
+//
+// Uso:
+//
+//	go run ./cmd/bench-thumbnails [-resizers go,vips] [-size 320] [-runs 3] [-vips vipsthumbnail] foto1.jpg foto2.jpg ...
+//
+// Para cada implementação, mostra o tempo médio por imagem, a memória alocada pelo processo e o pico do
+// heap. O vipsthumbnail roda em outro processo: sua memória não entra na conta, mas também não pesa no
+// servidor.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"photo-manager/internal/thumbnail"
+	"runtime"
+	"strings"
+	"time"
+)
+
+func main() {
+	resizers := flag.String("resizers", thumbnail.ResizerGo+","+thumbnail.ResizerVips, "Implementações comparadas, separadas por vírgula")
+	size := flag.Int("size", thumbnail.DefaultMaxSize, "Maior lado da miniatura, em pixels")
+	runs := flag.Int("runs", 3, "Repetições de cada imagem")
+	vipsBinary := flag.String("vips", "vipsthumbnail", "Executável do vipsthumbnail")
+	flag.Parse()
+
+	files := flag.Args()
+	if len(files) == 0 || *runs <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir, err := os.MkdirTemp("", "bench-thumbnails")
+	if err != nil {
+		log.Fatalf("Não foi possível criar o diretório temporário: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fmt.Printf("%-8s %8s %12s %14s %14s\n", "resizer", "imagens", "média/imagem", "alocado/imagem", "pico do heap")
+	for _, name := range strings.Split(*resizers, ",") {
+		var resizer thumbnail.Resizer
+		switch strings.TrimSpace(name) {
+		case thumbnail.ResizerGo:
+			resizer = thumbnail.GoResizer{}
+		case thumbnail.ResizerVips:
+			vips, err := thumbnail.NewVipsResizer(*vipsBinary, 0)
+			if err != nil {
+				log.Printf("Ignorando vips: %v\n", err)
+				continue
+			}
+			resizer = vips
+		default:
+			log.Fatalf("Implementação desconhecida: '%s'", name)
+		}
+		benchmark(resizer, files, dir, *size, *runs)
+	}
+}
+
+// benchmark gera as miniaturas de todos os arquivos runs vezes e imprime uma linha com as médias.
+func benchmark(resizer thumbnail.Resizer, files []string, dir string, size, runs int) {
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	// Amostra o heap em segundo plano para estimar o pico durante as conversões
+	peak := before.HeapAlloc
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				var stats runtime.MemStats
+				runtime.ReadMemStats(&stats)
+				peak = max(peak, stats.HeapAlloc)
+			}
+		}
+	}()
+
+	count, failed := 0, 0
+	start := time.Now()
+	for run := 0; run < runs; run++ {
+		for i, file := range files {
+			dst := filepath.Join(dir, fmt.Sprintf("%s-%d.jpg", resizer.Name(), i))
+			if err := resizer.Resize(context.Background(), file, dst, size); err != nil {
+				if run == 0 {
+					log.Printf("%s: falha em '%s': %v\n", resizer.Name(), file, err)
+				}
+				failed++
+				continue
+			}
+			count++
+		}
+	}
+	elapsed := time.Since(start)
+	close(done)
+	<-sampled
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	if count == 0 {
+		fmt.Printf("%-8s %8d %12s %14s %14s (%d falhas)\n", resizer.Name(), 0, "-", "-", "-", failed)
+		return
+	}
+	fmt.Printf("%-8s %8d %12s %14s %14s", resizer.Name(), count,
+		(elapsed / time.Duration(count)).Round(time.Millisecond),
+		formatBytes((after.TotalAlloc-before.TotalAlloc)/uint64(count)),
+		formatBytes(peak))
+	if failed > 0 {
+		fmt.Printf(" (%d falhas)", failed)
+	}
+	fmt.Println()
+}
+
+// formatBytes formata um tamanho em MB, com uma casa decimal.
+func formatBytes(n uint64) string {
+	return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+}
@@ -0,0 +1,133 @@
+// Comando db-maintenance faz a manutenção do banco SQLite: cópia de segurança consistente (mesmo com o
+// servidor em uso), ANALYZE, VACUUM e o relatório de tamanho, páginas e índices.
+//
+// Uso:
+//
+//	go run ./cmd/db-maintenance [-backup] [-analyze] [-vacuum]
+//
+// Sem opções, apenas exibe o relatório. As operações são executadas na ordem backup, analyze, vacuum, e o
+// relatório é exibido ao final. Termina com código 1 se alguma operação falhar.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"photo-manager/internal/config"
+	"photo-manager/internal/database"
+	"photo-manager/internal/service"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	backup := flag.Bool("backup", false, "Grava uma cópia de segurança em DB_BACKUP_PATH")
+	analyze := flag.Bool("analyze", false, "Atualiza as estatísticas dos índices (ANALYZE)")
+	vacuum := flag.Bool("vacuum", false, "Reescreve o banco, liberando as páginas livres (VACUUM; bloqueia as escritas do servidor)")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Atenção: Nenhum arquivo .env encontrado. Usando variáveis de ambiente do sistema.")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Configuração inválida: %v", err)
+	}
+
+	database.InitDB(cfg.DatabasePath, 0) // O VACUUM de um banco grande pode levar minutos
+	maintenance := service.NewDatabaseMaintenanceService(database.DB, cfg.DatabasePath, cfg.DBBackupPath, cfg.DBBackupKeep)
+	ctx := context.Background()
+	failed := false
+
+	if *backup {
+		result, err := maintenance.Backup(ctx)
+		if err != nil {
+			log.Printf("Falha na cópia de segurança: %v\n", err)
+			failed = true
+		} else {
+			fmt.Printf("Cópia de segurança: %s (%s) em %s\n", result.Path, formatBytes(result.SizeBytes), result.Duration.Round(time.Millisecond))
+		}
+	}
+	operations := []struct {
+		enabled bool
+		run     func(context.Context) (*service.MaintenanceResult, error)
+	}{
+		{*analyze, maintenance.Analyze},
+		{*vacuum, maintenance.Vacuum},
+	}
+	for _, operation := range operations {
+		if !operation.enabled {
+			continue
+		}
+		result, err := operation.run(ctx)
+		if err != nil {
+			log.Printf("Falha na manutenção: %v\n", err)
+			failed = true
+			continue
+		}
+		fmt.Printf("%s: %s em %s (liberados %s)\n", strings.ToUpper(result.Operation), formatBytes(result.SizeAfter),
+			result.Duration.Round(time.Millisecond), formatBytes(result.FreedBytes))
+	}
+
+	stats, err := maintenance.Stats(ctx)
+	if err != nil {
+		log.Fatalf("Falha ao ler as estatísticas: %v", err)
+	}
+	printStats(stats)
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// printStats exibe o relatório do banco.
+func printStats(stats *service.DatabaseStats) {
+	fmt.Printf("\nBanco: %s (SQLite %s, journal %s)\n", stats.Path, stats.SQLiteVersion, stats.JournalMode)
+	fmt.Printf("Tamanho: %s (+ %s de WAL), %d páginas de %d bytes, %d livres (%s)\n", formatBytes(stats.SizeBytes),
+		formatBytes(stats.WALSizeBytes), stats.PageCount, stats.PageSize, stats.FreePages, formatBytes(stats.FreeBytes))
+	if stats.LastBackup != nil {
+		fmt.Printf("Última cópia: %s (%s)\n", stats.LastBackup.Name, stats.LastBackup.CreatedAt.Format(time.RFC3339))
+	} else {
+		fmt.Println("Última cópia: nenhuma")
+	}
+
+	fmt.Println("\nTabelas:")
+	for _, table := range stats.Tables {
+		fmt.Printf("  %-28s %10d linhas\n", table.Name, table.Rows)
+	}
+
+	fmt.Println("\nÍndices:")
+	if !stats.Analyzed {
+		fmt.Println("  (sem estatísticas: execute com -analyze)")
+	}
+	for _, index := range stats.Indexes {
+		notes := []string{}
+		if index.Unique {
+			notes = append(notes, "único")
+		}
+		if index.Redundant != "" {
+			notes = append(notes, "redundante com "+index.Redundant)
+		}
+		if index.LowSelectivity {
+			notes = append(notes, fmt.Sprintf("pouco seletivo (%.0f linhas por valor)", index.RowsPerKey))
+		}
+		fmt.Printf("  %-40s %s(%s) %s\n", index.Name, index.Table, strings.Join(index.Columns, ", "), strings.Join(notes, "; "))
+	}
+}
+
+// formatBytes formata um tamanho em bytes com a unidade adequada.
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,81 @@
+// Comando dedupe lista os grupos de fotos duplicadas da biblioteca (idênticas pixel a pixel e quase
+// idênticas) com o espaço recuperável e, com -execute, remove as cópias redundantes.
+//
+// Uso:
+//
+//	go run ./cmd/dedupe [-policy keep-oldest|keep-largest|keep-raw] [-threshold 4] [-exact-only] [-execute]
+//
+// Sem -execute nada é removido: revise o relatório e execute novamente com os mesmos parâmetros.
+// A foto mantida herda os álbuns das cópias removidas.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"photo-manager/internal/config"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/service"
+	"photo-manager/internal/storage"
+	"photo-manager/internal/thumbnail"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	policy := flag.String("policy", service.DedupeKeepOldest, "Foto mantida em cada grupo: 'keep-oldest', 'keep-largest' ou 'keep-raw'")
+	threshold := flag.Int("threshold", service.DefaultNearDuplicateThreshold, "Distância máxima entre hashes perceptuais de fotos quase idênticas (0 a 16)")
+	exactOnly := flag.Bool("exact-only", false, "Considera apenas fotos idênticas pixel a pixel")
+	execute := flag.Bool("execute", false, "Remove as cópias (sem esta opção, apenas simula)")
+	flag.Parse()
+
+	if !service.ValidDedupePolicy(*policy) {
+		log.Fatalf("Política inválida: '%s' (use '%s', '%s' ou '%s')", *policy, service.DedupeKeepOldest, service.DedupeKeepLargest, service.DedupeKeepRaw)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Atenção: Nenhum arquivo .env encontrado. Usando variáveis de ambiente do sistema.")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Configuração inválida: %v", err)
+	}
+
+	database.InitDB(cfg.DatabasePath, 0) // O cálculo das impressões digitais pode levar mais que o limite de uma consulta da API
+
+	fileManager := storage.NewFileManager(cfg.PhotoStoragePath)
+	if err := service.NewVolumeService(database.DB, fileManager).LoadVolumes(); err != nil {
+		log.Fatalf("Falha ao carregar volumes de armazenamento: %v", err)
+	}
+
+	photoService := service.NewPhotoService(database.DB, fileManager)
+	photoService.Thumbnails = thumbnail.NewGenerator(cfg.ThumbnailPath, cfg.ThumbnailMaxSize)
+	photoService.Thumbnails.MaxPixels = cfg.Validation.MaxPixels
+	photoService.Thumbnails.Resizer = thumbnail.SelectResizer(cfg.ThumbnailResizer, cfg.VipsThumbnail)
+	if cfg.LedgerEnabled {
+		// Registra as remoções no livro-razão, como o servidor faria
+		bus := events.NewBus()
+		service.NewLedgerService(database.DB, nil).Subscribe(bus)
+		photoService.Events = bus
+	}
+	duplicateService := service.NewDuplicateService(database.DB, photoService, service.NewAlbumService(database.DB, nil))
+
+	opts := service.DuplicateOptions{Policy: *policy, Threshold: *threshold, IncludeNear: !*exactOnly}
+	report, err := duplicateService.Reclaim(context.Background(), opts, !*execute)
+	if err != nil {
+		log.Fatalf("Falha na deduplicação: %v", err)
+	}
+
+	for _, group := range report.Groups {
+		fmt.Printf("[%s] manter %d, remover %v (%d bytes)\n", group.Kind, group.KeepID, group.RemoveIDs, group.ReclaimableBytes)
+	}
+	fmt.Printf("Grupos: %d, espaço recuperável: %.1f MB, impressões calculadas: %d, ilegíveis: %d\n",
+		len(report.Groups), float64(report.ReclaimableBytes)/(1<<20), report.Fingerprinted, report.Unreadable)
+	if report.DryRun {
+		fmt.Println("Simulação: nenhuma foto foi removida. Use -execute para remover as cópias.")
+	} else {
+		fmt.Printf("Fotos removidas: %d, com falha: %d\n", report.Removed, report.Failed)
+	}
+}
@@ -1,15 +1,28 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"photo-manager/internal/api"
+	"photo-manager/internal/config"
 	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/graph"
+	"photo-manager/internal/hooks"
+	"photo-manager/internal/ledger"
+	"photo-manager/internal/logger"
+	"photo-manager/internal/notify"
+	"photo-manager/internal/rules"
 	"photo-manager/internal/service"
 	"photo-manager/internal/storage" // Importa nosso pacote de storage
+	"photo-manager/internal/thumbnail"
+	"photo-manager/internal/tracing"
+	"photo-manager/internal/validation"
+	"photo-manager/internal/video"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -22,17 +35,14 @@ func main() {
 		log.Println("Atenção: Nenhum arquivo .env encontrado. Usando variáveis de ambiente do sistema.")
 	}
 
-	// Obtém a porta do ambiente ou usa 8080 como padrão
-	port := os.Getenv("APP_PORT")
-	if port == "" {
-		port = "8080"
+	// Carrega a configuração a partir das variáveis de ambiente
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Configuração inválida: %v", err)
 	}
-
-	// Obtém o caminho do banco de dados do ambiente
-	dbPath := os.Getenv("DATABASE_URL")
-	if dbPath == "" {
-		dbPath = "./data/photo_manager.db" // Caminho padrão se não estiver no .env
-		log.Printf("DATABASE_URL não configurado. Usando padrão: %s\n", dbPath)
+	logger.SetLevel(cfg.LogLevel)
+	if cfg.ConfigFile != "" {
+		log.Printf("Arquivo de configuração: %s\n", cfg.ConfigFile)
 	}
 
 	// Garante que o diretório 'data' exista para o SQLite
@@ -41,31 +51,268 @@ func main() {
 		log.Fatalf("Falha ao criar diretório de dados '%s': %v", dataDir, err)
 	}
 
-	// Obtém o caminho para armazenamento das fotos
-	photoStoragePath := os.Getenv("PHOTO_STORAGE_PATH")
-	if photoStoragePath == "" {
-		photoStoragePath = "./data/photos" // Caminho padrão
-		log.Printf("PHOTO_STORAGE_PATH não configurado. Usando padrão: %s\n", photoStoragePath)
-	}
 	// Garante que o diretório de armazenamento de fotos exista
-	if err := os.MkdirAll(photoStoragePath, 0755); err != nil {
-		log.Fatalf("Falha ao criar diretório de armazenamento de fotos '%s': %v", photoStoragePath, err)
+	if err := os.MkdirAll(cfg.PhotoStoragePath, 0755); err != nil {
+		log.Fatalf("Falha ao criar diretório de armazenamento de fotos '%s': %v", cfg.PhotoStoragePath, err)
+	}
+
+	// Envia os traces (requisições, serviços, banco e armazenamento) para o coletor OpenTelemetry, se configurado
+	if cfg.TracingEndpoint != "" {
+		tracing.Init(tracing.Options{
+			Endpoint:    cfg.TracingEndpoint,
+			ServiceName: cfg.TracingServiceName,
+			SampleRatio: cfg.TracingSampleRatio,
+			Headers:     cfg.TracingHeaders,
+		})
 	}
 
 	// Inicializa a conexão com o banco de dados
-	database.InitDB(dbPath)
+	database.InitDB(cfg.DatabasePath, cfg.QueryTimeout)
+
+	// Cópias de segurança, VACUUM e ANALYZE do banco, sob demanda e na janela de manutenção diária
+	dbMaintenance := service.NewDatabaseMaintenanceService(database.DB, cfg.DatabasePath, cfg.DBBackupPath, cfg.DBBackupKeep)
+	if cfg.DBMaintenanceAt >= 0 {
+		dbMaintenance.StartScheduler(context.Background(), cfg.DBMaintenanceAt)
+	}
+	databaseHandler := api.NewDatabaseHandler(dbMaintenance)
+
+	// Inicializa o barramento de eventos da aplicação
+	eventBus := events.NewBus()
+
+	// Envia notificações (Telegram, ntfy, Gotify) para os eventos configurados
+	if notifier := notify.NewDispatcher(cfg.Notify); notifier.Enabled() {
+		notifier.Subscribe(eventBus)
+	}
+
+	// Registra o hash de cada foto no livro-razão encadeado, para arquivamento com prova de integridade
+	if cfg.LedgerEnabled {
+		var timestamps *ledger.TimestampClient
+		if cfg.LedgerTSAURL != "" {
+			timestamps = ledger.NewTimestampClient(cfg.LedgerTSAURL)
+		}
+		ledgerService := service.NewLedgerService(database.DB, timestamps)
+		ledgerService.Subscribe(eventBus)
+		ledgerService.Start(context.Background())
+		log.Println("Livro-razão de integridade ativado. Verifique com: go run ./cmd/verify-ledger")
+	}
 
 	// Inicializa o gerenciador de arquivos
-	fileManager := storage.NewFileManager(photoStoragePath)
+	fileManager := storage.NewFileManager(cfg.PhotoStoragePath)
+	fileManager.PlacementPolicy = cfg.PlacementPolicy
+	fileManager.Layout = cfg.StorageLayout
+	fileManager.ReserveBytes = cfg.StorageReserveBytes
+	fileManager.LowSpaceWarningBytes = cfg.LowSpaceWarningBytes
+	fileManager.SyncDir = cfg.StorageSyncDir
+	fileManager.Events = eventBus
+
+	// Carrega os volumes de armazenamento registrados
+	volumeService := service.NewVolumeService(database.DB, fileManager)
+	if err := volumeService.LoadVolumes(); err != nil {
+		log.Fatalf("Falha ao carregar volumes de armazenamento: %v", err)
+	}
 
 	// Inicializa o serviço de fotos
 	photoService := service.NewPhotoService(database.DB, fileManager)
+	photoService.Events = eventBus
+	photoService.Thumbnails = thumbnail.NewGenerator(cfg.ThumbnailPath, cfg.ThumbnailMaxSize)
+	photoService.Thumbnails.MaxPixels = cfg.Validation.MaxPixels
+	photoService.Thumbnails.Resizer = thumbnail.SelectResizer(cfg.ThumbnailResizer, cfg.VipsThumbnail)
+	photoService.QuarantineDir = cfg.QuarantinePath
+	if photoService.Timeline, err = service.ParseTimelineZone(cfg.TimelineTimeZone, service.TimelineZone{}); err != nil {
+		log.Fatalf("Fuso da linha do tempo inválido: %v", err)
+	}
+	if cfg.FFmpegPath != "" {
+		photoService.Transcoder = video.NewTranscoder(video.FFmpegRunner{Binary: cfg.FFmpegPath}, cfg.VideoCachePath, cfg.VideoWorkers)
+	}
+	// Serviços com workers em segundo plano, cujas métricas aparecem em GET /admin/workers
+	var workerPools []service.PoolReporter
+	var ingestHooks *service.IngestHookService
+
+	photoService.Validators = validation.NewChain(cfg.Validation)
+	if cfg.Hooks.PreIngest != "" {
+		// O hook de pré-ingestão é o último validador: recebe o tipo e as dimensões já verificados
+		hook := hooks.New(cfg.Hooks.PreIngest, cfg.Hooks.PreIngestTimeout)
+		photoService.Validators = append(photoService.Validators, hooks.Validator(hook, cfg.Hooks.PreIngestOnFailure))
+		log.Printf("Hook de pré-ingestão ativado (falhas: %s)\n", cfg.Hooks.PreIngestOnFailure)
+	}
+	if cfg.Hooks.PostIngest != "" {
+		hook := hooks.New(cfg.Hooks.PostIngest, cfg.Hooks.PostIngestTimeout)
+		ingestHooks = service.NewIngestHookService(database.DB, hook, cfg.Hooks.PostIngestRetries, cfg.Hooks.PostIngestWorkers, cfg.Hooks.PostIngestQueue)
+		ingestHooks.Subscribe(eventBus)
+		ingestHooks.Start(context.Background())
+		workerPools = append(workerPools, ingestHooks)
+		log.Println("Hook de pós-ingestão ativado")
+	}
+	if cfg.RulesFile != "" {
+		organizationRules, err := rules.Load(cfg.RulesFile)
+		if err != nil {
+			log.Fatalf("Regras de organização inválidas em '%s': %v", cfg.RulesFile, err)
+		}
+		photoService.Rules = organizationRules
+		log.Printf("%d regra(s) de organização carregada(s) de %s\n", organizationRules.Len(), cfg.RulesFile)
+	}
+	photoService.Downscale.MaxPixels = cfg.DownscaleMaxPixels
+	if cfg.OriginalsPath != "" {
+		if err := os.MkdirAll(cfg.OriginalsPath, 0755); err != nil {
+			log.Fatalf("Falha ao criar diretório de originais '%s': %v", cfg.OriginalsPath, err)
+		}
+		photoService.Downscale.Originals = storage.NewFileManager(cfg.OriginalsPath)
+		photoService.Downscale.Originals.Layout = cfg.StorageLayout
+	}
+
+	// Inicializa os serviços de álbuns e tags
+	albumService := service.NewAlbumService(database.DB, eventBus)
+	if err := albumService.BackfillDateRanges(); err != nil {
+		log.Printf("Falha ao calcular o período dos álbuns: %v\n", err)
+	}
+	tagService := service.NewTagService(database.DB, eventBus)
+
+	// Inicializa o serviço de importação e retoma importações interrompidas
+	importReports := service.NewImportReportService(database.DB)
+	importService := service.NewImportService(database.DB, photoService, albumService, cfg.ImportWorkers, cfg.ImportQueue)
+	importService.Reports = importReports
+	importService.Start(context.Background())
+	workerPools = append(workerPools, importService)
+	if err := importService.ResumePendingImports(); err != nil {
+		log.Printf("Falha ao retomar importações pendentes: %v\n", err)
+	}
+
+	// Inicializa o serviço de exportação completa e refaz exportações interrompidas
+	exportService := service.NewExportService(database.DB, cfg.ExportPath, eventBus)
+	if err := exportService.ResumePendingExports(); err != nil {
+		log.Printf("Falha ao retomar exportações pendentes: %v\n", err)
+	}
+
+	// Inicializa o serviço de download de seleções e refaz as preparações interrompidas
+	downloadService := service.NewDownloadService(database.DB, cfg.ExportPath, cfg.DownloadPrepareBytes, eventBus)
+	if err := downloadService.ResumePendingDownloads(); err != nil {
+		log.Printf("Falha ao retomar downloads pendentes: %v\n", err)
+	}
 
 	// Inicializa o handler da API de fotos
 	photoHandler := api.NewPhotoHandler(photoService)
+	photoHandler.AccessStats = service.NewAccessStatsService(database.DB)
+	photoHandler.ImportReports = importReports
+	photoHandler.AccessStats.Start(context.Background(), cfg.AccessFlushInterval)
+	photoHandler.Thumbnails = service.NewThumbnailService(database.DB, photoService, cfg.ThumbnailPolicy,
+		cfg.ThumbnailWorkers, cfg.ThumbnailRenders, cfg.ThumbnailQueue, cfg.ThumbnailPending)
+	photoHandler.Thumbnails.QueueTimeout = cfg.ThumbnailWait
+	photoHandler.Thumbnails.Subscribe(eventBus)
+	photoHandler.Thumbnails.Start(context.Background(), cfg.ThumbnailHour)
+	thumbnailHandler := api.NewThumbnailHandler(photoHandler.Thumbnails)
+	workerPools = append(workerPools, photoHandler.Thumbnails)
+	workerHandler := api.NewWorkerHandler(workerPools)
+
+	// Inicializa o handler da API de importação
+	importHandler := api.NewImportHandler(importService)
+	importReportHandler := api.NewImportReportHandler(importReports)
+	exportHandler := api.NewExportHandler(exportService)
+	downloadHandler := api.NewDownloadHandler(downloadService, photoService)
+
+	// Inicializa os handlers de álbuns e tags
+	albumHandler := api.NewAlbumHandler(albumService, photoService)
+	albumHandler.AlbumImport.Reports = importReports
+	tagHandler := api.NewTagHandler(tagService)
+	shareService := service.NewShareService(database.DB, albumService)
+	shareHandler := api.NewShareHandler(shareService, photoHandler)
+	selectionService := service.NewSelectionService(database.DB, albumService, tagService, shareService)
+	selectionHandler := api.NewSelectionHandler(selectionService, photoService, downloadHandler)
+	reactionHandler := api.NewReactionHandler(service.NewReactionService(database.DB, shareService))
+	relationHandler := api.NewPhotoRelationHandler(service.NewPhotoRelationService(database.DB, eventBus))
+	annotationService := service.NewPhotoAnnotationService(database.DB, eventBus)
+	annotationHandler := api.NewPhotoAnnotationHandler(annotationService)
+	photoHandler.Annotations = annotationService
+	photoEventHandler := api.NewPhotoEventHandler(service.NewPhotoEventService(database.DB, albumService))
+
+	// Inicializa os handlers de volumes e estatísticas
+	volumeHandler := api.NewVolumeHandler(volumeService)
+	statsHandler := api.NewStatsHandler(service.NewStatsService(database.DB, fileManager, eventBus))
+
+	// Inicializa a API GraphQL sobre os mesmos serviços
+	graphServer, err := graph.NewServer(photoService, albumService, tagService)
+	if err != nil {
+		log.Fatalf("Falha ao inicializar a API GraphQL: %v", err)
+	}
+	graphQLHandler := api.NewGraphQLHandler(graphServer)
+
+	// Inicializa o handler do relatório de duplicatas
+	duplicateService := service.NewDuplicateService(database.DB, photoService, albumService)
+	duplicateHandler := api.NewDuplicateHandler(duplicateService)
+	privacyHandler := api.NewPrivacyHandler(photoService)
+	textReplaceHandler := api.NewTextReplaceHandler(service.NewTextReplaceService(database.DB, eventBus))
+	exifReconcileHandler := api.NewExifReconcileHandler(service.NewExifReconcileService(database.DB, eventBus))
+	storageDriftService := service.NewStorageDriftService(photoService)
+	storageDriftHandler := api.NewStorageDriftHandler(storageDriftService)
+	if cfg.StorageDriftInterval > 0 {
+		storageDriftService.StartScheduler(context.Background(), cfg.StorageDriftInterval)
+	}
+	rulesHandler := api.NewRulesHandler(photoService)
+
+	// Inicializa as políticas de ciclo de vida e as cotas dos álbuns, executadas periodicamente
+	albumPolicyService := service.NewAlbumPolicyService(database.DB, photoService, albumService, eventBus)
+	albumPolicyHandler := api.NewAlbumPolicyHandler(albumPolicyService)
+	sourceAlbumHandler := api.NewSourceAlbumHandler(service.NewSourceAlbumService(database.DB, albumService))
+	albumTemplateHandler := api.NewAlbumTemplateHandler(service.NewAlbumTemplateService(database.DB, albumService, eventBus))
+	duplicatePolicyHandler := api.NewSourceDuplicatePolicyHandler(service.NewSourceDuplicatePolicyService(database.DB, albumService))
+	adminPlanHandler := api.NewAdminPlanHandler(service.NewAdminPlanService(database.DB, duplicateService, albumPolicyService))
+	if cfg.AlbumCleanupInterval > 0 {
+		albumService.StartOrphanCleanup(context.Background(), cfg.AlbumCleanupInterval)
+	}
+	if cfg.AlbumPolicyInterval > 0 {
+		albumPolicyService.StartScheduler(context.Background(), cfg.AlbumPolicyInterval)
+	}
+
+	mirrorService := service.NewMirrorService(database.DB, photoService, eventBus)
+	mirrorHandler := api.NewMirrorHandler(mirrorService)
+	mirrorService.StartScheduler(context.Background(), time.Minute) // Cada origem define seu próprio intervalo
 
 	// Inicializa o roteador do Gin
-	router := gin.Default()
+	gin.SetMode(cfg.GinMode)
+	router := gin.New()
+	// Log das requisições apenas a partir do nível info (LOG_LEVEL), ajustável numa recarga da configuração
+	router.Use(gin.LoggerWithConfig(gin.LoggerConfig{Skip: func(*gin.Context) bool { return !logger.Enabled(logger.LevelInfo) }}))
+	router.Use(gin.Recovery())
+	if err := configureProxies(router, cfg); err != nil {
+		log.Fatalf("Configuração inválida: %v", err)
+	}
+	router.Use(api.Tracing()) // Span de cada requisição, com o ID do trace em X-Trace-Id e nas respostas de erro
+	router.Use(api.Locale())  // Mensagens da API em português ou inglês, conforme o Accept-Language
+	router.Use(api.Compress(cfg.CompressionLevel, cfg.CompressionMin))
+	// Limita o corpo das requisições antes de qualquer leitura: uploads têm um limite próprio, maior
+	router.Use(api.MaxBodySize(cfg.MaxRequestBodyBytes, map[string]int64{
+		"/upload":         cfg.MaxUploadRequestBytes,
+		"/upload/preview": cfg.MaxUploadRequestBytes,
+		"/albums/import":  cfg.MaxUploadRequestBytes,
+
+		// Trilhas GPS de dias inteiros passam de alguns MB
+		"/photos/geotag-from-track": cfg.MaxUploadRequestBytes,
+	}))
+
+	// Uploads podem ter a velocidade de recebimento limitada por cliente
+	uploadThrottle := api.NewUploadThrottle(cfg.UploadBandwidth)
+	throttleUploads := uploadThrottle.Handler()
+	galleryLimiter := api.NewRateLimiter(cfg.PublicRateLimit)
+
+	// Recarga da configuração (SIGHUP ou POST /admin/config/reload): aplica as configurações ajustáveis
+	// sem reiniciar; as demais são apenas informadas como pendentes de reinício
+	reloader := config.NewReloader(cfg, func(next *config.Config) {
+		logger.SetLevel(next.LogLevel)
+		galleryLimiter.SetLimit(next.PublicRateLimit)
+		uploadThrottle.SetRate(next.UploadBandwidth)
+		photoHandler.Thumbnails.SetWorkers(next.ThumbnailWorkers)
+		importService.SetWorkers(next.ImportWorkers)
+		if ingestHooks != nil {
+			ingestHooks.SetWorkers(next.Hooks.PostIngestWorkers)
+		}
+	})
+	reloader.WatchSignals(context.Background())
+	configHandler := api.NewConfigHandler(reloader)
+
+	// Rotas de administração e de desbloqueio exigem ADMIN_TOKEN, se configurado
+	requireAdmin := api.RequireAdmin(cfg.AdminToken)
+	if cfg.AdminToken == "" {
+		log.Println("Atenção: ADMIN_TOKEN não configurado. Rotas de administração e desbloqueio estão abertas.")
+	}
 
 	// Define uma rota simples para testar
 	router.GET("/ping", func(c *gin.Context) {
@@ -74,16 +321,204 @@ func main() {
 		})
 	})
 
+	// Versões da API: as rotas abaixo respondem também sob /api/v1 (ver api.Versioned, aplicado em serve)
+	router.GET("/api", api.VersionsHandler)
+
 	// Rota para upload de fotos
-	// Cuidado: Gin tem um limite de tamanho de corpo padrão. Para uploads maiores, configure MaxMultipartMemory
-	// router.MaxMultipartMemory = 8 << 20 // 8MB - padrão. Aumente se necessário, ex: 64 << 20 (64MB)
-	router.POST("/upload", photoHandler.UploadPhotoHandler)
+	// MaxMultipartMemory (32MB) só define quanto do formulário fica em memória; o restante vai para arquivos
+	// temporários. O tamanho total do corpo é limitado por UPLOAD_MAX_REQUEST_MB (ver MaxBodySize).
+	router.POST("/upload", throttleUploads, photoHandler.UploadPhotoHandler)
+	router.POST("/upload/preview", throttleUploads, photoHandler.PreviewUploadHandler)
 
 	// Novas rotas para busca e linha do tempo
 	router.GET("/photos", photoHandler.GetPhotosHandler)
+	router.GET("/photos/search", photoHandler.SearchPhotosHandler)
 	router.GET("/photos/timeline", photoHandler.GetPhotosTimelineHandler)
+	router.GET("/photos/random", photoHandler.GetRandomPhotosHandler)
+	router.GET("/photos/popular", photoHandler.GetPopularPhotosHandler)
+	router.GET("/photos/sources", photoHandler.GetPhotoSourcesHandler)
+	router.GET("/photos/compare", photoHandler.ComparePhotosHandler)
+	router.GET("/photos/export.csv", photoHandler.ExportPhotosCSVHandler)
+	router.GET("/photos/source-changes", photoHandler.ListSourceChangesHandler)
+	router.POST("/photos/source-changes/adopt", photoHandler.AdoptSourceChangesHandler)
+	router.GET("/photos/by-hash/:hash", photoHandler.GetPhotosByHashHandler)
+	router.POST("/photos/by-hashes", photoHandler.GetPhotosByHashesHandler)
+	router.GET("/photos/:id", photoHandler.GetPhotoHandler)
+	router.GET("/photos/:id/file", photoHandler.GetPhotoFileHandler)
+	router.GET("/photos/:id/exif", photoHandler.GetPhotoExifHandler)
+	router.GET("/photos/:id/print-check", photoHandler.PrintCheckHandler)
+	router.GET("/photos/:id/thumbnail", photoHandler.GetPhotoThumbnailHandler)
+	router.GET("/photos/:id/stream", photoHandler.GetPhotoStreamHandler)
+	router.GET("/photos/:id/stream/:file", photoHandler.GetPhotoStreamFileHandler)
+	router.POST("/photos/:id/adopt-source", photoHandler.AdoptPhotoSourceHandler)
+	router.DELETE("/photos/:id", photoHandler.DeletePhotoHandler)
+	router.PUT("/photos/:id/tags", tagHandler.SetPhotoTagsHandler)
+	router.PUT("/photos/:id/favorite", photoHandler.SetFavoriteHandler)
+	router.PUT("/photos/:id/hidden", photoHandler.SetHiddenHandler)
+	router.PUT("/photos/timeline-visibility", photoHandler.SetTimelineVisibilityHandler)
+	router.PUT("/photos/circa-date", photoHandler.SetCircaDateHandler)
+	router.POST("/photos/geotag-from-track", photoHandler.GeotagFromTrackHandler)
+	router.GET("/photos/date-fixes", photoHandler.ListDateFixesHandler)
+	router.POST("/photos/date-fixes/confirm", photoHandler.ConfirmDateFixesHandler)
+	router.PUT("/photos/:id/lock", photoHandler.LockPhotoHandler)
+	router.PUT("/photos/:id/unlock", requireAdmin, photoHandler.UnlockPhotoHandler)
+	router.GET("/photos/:id/relations", relationHandler.ListRelationsHandler)
+	router.GET("/photos/:id/relations/graph", relationHandler.RelationGraphHandler)
+	router.POST("/photos/:id/relations", relationHandler.CreateRelationHandler)
+	router.DELETE("/photos/:id/relations/:relation_id", relationHandler.DeleteRelationHandler)
+	router.GET("/photos/:id/annotations", annotationHandler.ListAnnotationsHandler)
+	router.POST("/photos/:id/annotations", annotationHandler.CreateAnnotationHandler)
+	router.PUT("/photos/:id/annotations/:annotation_id", annotationHandler.UpdateAnnotationHandler)
+	router.DELETE("/photos/:id/annotations/:annotation_id", annotationHandler.DeleteAnnotationHandler)
+	router.POST("/photos/:id/annotations/import", annotationHandler.ImportRegionsHandler)
+	router.GET("/photos/:id/regions.xmp", annotationHandler.ExportRegionsHandler)
+
+	// Rotas de álbuns e tags
+	router.GET("/albums", albumHandler.ListAlbumsHandler)
+	router.POST("/albums", albumHandler.CreateAlbumHandler)
+	router.POST("/albums/from-filter", albumHandler.CreateAlbumFromFilterHandler)
+	router.POST("/albums/from-template", albumTemplateHandler.CreateFromTemplateHandler)
+	router.POST("/albums/import", throttleUploads, albumHandler.ImportZipHandler)
+	router.GET("/albums/:id", albumHandler.GetAlbumHandler)
+	router.PUT("/albums/:id/pinned", albumHandler.SetPinnedHandler)
+	router.PUT("/albums/:id/metadata", albumHandler.SetMetadataHandler)
+	router.PATCH("/albums/:id/metadata", albumHandler.UpdateMetadataHandler)
+	router.PUT("/albums/:id/lock", albumHandler.LockAlbumHandler)
+	router.PUT("/albums/:id/unlock", requireAdmin, albumHandler.UnlockAlbumHandler)
+	router.GET("/albums/:id/export/gallery.zip", albumHandler.ExportGalleryHandler)
+	router.GET("/albums/:id/contact-sheet.pdf", albumHandler.ContactSheetHandler)
+	router.POST("/albums/:id/photos", albumHandler.AddAlbumPhotosHandler)
+	router.DELETE("/albums/:id", albumHandler.DeleteAlbumHandler)
+	router.DELETE("/albums/:id/photos/:photo_id", albumHandler.RemoveAlbumPhotoHandler)
+	router.PUT("/albums/:id/quota", albumPolicyHandler.SetQuotaHandler)
+	router.GET("/albums/:id/policies", albumPolicyHandler.ListPoliciesHandler)
+	router.POST("/albums/:id/policies", albumPolicyHandler.CreatePolicyHandler)
+	router.DELETE("/albums/:id/policies/:policy_id", albumPolicyHandler.DeletePolicyHandler)
+	router.GET("/albums/:id/policies/:policy_id/preview", albumPolicyHandler.PreviewPolicyHandler)
+	router.POST("/albums/:id/policies/:policy_id/confirm", albumPolicyHandler.ConfirmPolicyHandler)
+	router.POST("/albums/:id/policies/:policy_id/plan", albumPolicyHandler.PlanPolicyHandler)
+	router.GET("/album-templates", albumTemplateHandler.ListTemplatesHandler)
+	router.POST("/album-templates", albumTemplateHandler.CreateTemplateHandler)
+	router.GET("/album-templates/:id", albumTemplateHandler.GetTemplateHandler)
+	router.DELETE("/album-templates/:id", albumTemplateHandler.DeleteTemplateHandler)
+	router.GET("/sources/albums", sourceAlbumHandler.ListSourceAlbumsHandler)
+	router.PUT("/sources/albums", sourceAlbumHandler.SetSourceAlbumHandler)
+	router.DELETE("/sources/albums/:id", sourceAlbumHandler.DeleteSourceAlbumHandler)
+	router.GET("/sources/duplicate-policies", duplicatePolicyHandler.ListPoliciesHandler)
+	router.PUT("/sources/duplicate-policies", duplicatePolicyHandler.SetPolicyHandler)
+	router.DELETE("/sources/duplicate-policies/:id", duplicatePolicyHandler.DeletePolicyHandler)
+	router.GET("/tags", tagHandler.ListTagsHandler)
+	router.PUT("/tags/:id/rename", tagHandler.RenameTagHandler)
+	router.POST("/tags/merge", tagHandler.MergeTagsHandler)
+
+	// Links públicos de compartilhamento de álbuns
+	router.POST("/albums/:id/shares", shareHandler.CreateAlbumShareHandler)
+	router.GET("/s/:token", shareHandler.GetShareHandler)
+	router.GET("/s/:token/photos/:photo_id/thumbnail", shareHandler.SharedThumbnailHandler)
+	router.GET("/s/:token/photos/:photo_id/image", shareHandler.SharedImageHandler)
+
+	// Reações (votos) às fotos de álbuns compartilhados, ex: para escolher quais fotos imprimir
+	router.PUT("/s/:token/photos/:photo_id/reactions", reactionHandler.ReactHandler)
+	router.DELETE("/s/:token/photos/:photo_id/reactions", reactionHandler.UnreactHandler)
+	router.GET("/s/:token/reactions", reactionHandler.SharedTopHandler)
+	router.GET("/albums/:id/reactions", reactionHandler.AlbumTopHandler)
+
+	// Galeria pública (portfólio): álbuns publicados, somente leitura, com limite por IP e proteção contra hotlinks
+	if publicGallery := service.NewPublicGalleryService(database.DB, albumService, cfg.PublicAlbumIDs); publicGallery.Enabled() {
+		galleryHandler := api.NewPublicGalleryHandler(publicGallery, photoHandler, cfg.PublicImageSize, cfg.PublicCacheMaxAge)
+		hotlink := api.HotlinkProtection(cfg.PublicAllowedReferer)
+		gallery := router.Group("/gallery", galleryLimiter.Handler())
+		gallery.GET("", galleryHandler.IndexHandler)
+		gallery.GET("/albums/:id", galleryHandler.AlbumHandler)
+		gallery.GET("/albums/:id/photos/:photo_id/thumbnail", hotlink, galleryHandler.ThumbnailHandler)
+		gallery.GET("/albums/:id/photos/:photo_id/image", hotlink, galleryHandler.ImageHandler)
+		log.Printf("Galeria pública ativa em /gallery com %d álbum(ns)\n", len(publicGallery.AlbumIDs))
+	}
+
+	// Eventos sugeridos (agrupamento por tempo e localização)
+	router.GET("/events", photoEventHandler.ListEventsHandler)
+	router.POST("/events/:id/album", photoEventHandler.PromoteEventHandler)
+
+	// Rotas de importação de diretórios locais: indexam no local arquivos de qualquer diretório do servidor
+	// (que a remoção com delete_file apaga do disco), então são protegidas como as rotas de administração
+	router.POST("/imports", requireAdmin, importHandler.CreateImportHandler)
+	router.GET("/imports/:id", requireAdmin, importHandler.GetImportHandler)
+
+	// Relatórios dos lotes de upload e importação, com o resultado de cada arquivo
+	router.GET("/import-reports", importReportHandler.ListReportsHandler)
+	router.GET("/import-reports/:id", importReportHandler.GetReportHandler)
+
+	// Exportação completa da biblioteca (originais e metadados), protegida como as rotas de administração
+	me := router.Group("/me", requireAdmin)
+	me.GET("/export", exportHandler.StartExportHandler)
+	me.GET("/export/:id", exportHandler.GetExportHandler)
+	me.GET("/export/:id/download", exportHandler.DownloadExportHandler)
+
+	// Download dos originais de uma seleção de fotos: estimativa, envio direto ou preparação em background
+	router.POST("/downloads/estimate", downloadHandler.EstimateDownloadHandler)
+	router.POST("/downloads", downloadHandler.StartDownloadHandler)
+	router.GET("/downloads/:id", downloadHandler.GetDownloadHandler)
+	router.GET("/downloads/:id/download", downloadHandler.DownloadArchiveHandler)
+
+	// Seleções temporárias de fotos (cestas), montadas em várias chamadas e usadas em ações em lote
+	router.POST("/selections", selectionHandler.CreateSelectionHandler)
+	router.GET("/selections/:token", selectionHandler.GetSelectionHandler)
+	router.DELETE("/selections/:token", selectionHandler.DeleteSelectionHandler)
+	router.POST("/selections/:token/photos", selectionHandler.AddPhotosHandler)
+	router.DELETE("/selections/:token/photos", selectionHandler.RemovePhotosHandler)
+	router.POST("/selections/:token/download", selectionHandler.DownloadHandler)
+	router.POST("/selections/:token/album", selectionHandler.AlbumHandler)
+	router.POST("/selections/:token/tags", selectionHandler.TagsHandler)
+	router.POST("/selections/:token/share", selectionHandler.ShareHandler)
+
+	// Rotas de volumes de armazenamento e estatísticas. Registrar um volume cria o diretório informado e passa
+	// a gravar uploads nele, então as alterações são restritas ao administrador
+	router.GET("/volumes", volumeHandler.ListVolumesHandler)
+	router.POST("/volumes", requireAdmin, volumeHandler.CreateVolumeHandler)
+	router.DELETE("/volumes/:id", requireAdmin, volumeHandler.DeleteVolumeHandler)
+	router.GET("/stats", statsHandler.GetStatsHandler)
+	router.GET("/stats/cameras", statsHandler.GetCamerasHandler)
+	router.GET("/stats/lenses", statsHandler.GetLensesHandler)
+
+	// Rotas de administração: relatório de duplicatas e remoção das cópias (simulação por padrão)
+	admin := router.Group("/admin", requireAdmin)
+	admin.GET("/duplicates", duplicateHandler.ListDuplicatesHandler)
+	admin.POST("/duplicates/reclaim", duplicateHandler.ReclaimDuplicatesHandler)
+	admin.GET("/plans", adminPlanHandler.ListPlansHandler)
+	admin.GET("/plans/:id", adminPlanHandler.GetPlanHandler)
+	admin.POST("/plans/:id/confirm", adminPlanHandler.ConfirmPlanHandler)
+	admin.DELETE("/plans/:id", adminPlanHandler.DiscardPlanHandler)
+	admin.GET("/albums/health", albumHandler.AlbumHealthHandler)
+	admin.POST("/albums/cleanup", albumHandler.CleanupAlbumsHandler)
+	admin.POST("/rules/check", rulesHandler.CheckRulesHandler)
+	admin.GET("/privacy-audit", privacyHandler.AuditHandler)
+	admin.POST("/privacy-audit/strip", privacyHandler.StripHandler)
+	admin.POST("/metadata/replace", textReplaceHandler.ReplaceHandler)
+	admin.POST("/metadata/reconcile", exifReconcileHandler.ReconcileHandler)
+	admin.GET("/metadata/changes", exifReconcileHandler.ListChangesHandler)
+	admin.GET("/storage/drift", storageDriftHandler.ScanHandler)
+	admin.POST("/storage/drift/reconcile", storageDriftHandler.ReconcileHandler)
+	admin.POST("/photos/projections", photoHandler.DetectProjectionsHandler)
+	admin.POST("/photos/auxiliary-assets", photoHandler.DetectAuxiliaryAssetsHandler)
+	admin.GET("/thumbnails", thumbnailHandler.MetricsHandler)
+	admin.GET("/workers", workerHandler.MetricsHandler)
+	admin.POST("/config/reload", configHandler.ReloadHandler)
+	admin.GET("/database", databaseHandler.StatsHandler)
+	admin.POST("/database/backup", databaseHandler.BackupHandler)
+	admin.GET("/database/backups", databaseHandler.ListBackupsHandler)
+	admin.GET("/database/backups/:name", databaseHandler.DownloadBackupHandler)
+	admin.POST("/database/vacuum", databaseHandler.VacuumHandler)
+	admin.POST("/database/analyze", databaseHandler.AnalyzeHandler)
+	admin.POST("/thumbnails/backfill", thumbnailHandler.BackfillHandler)
+	admin.GET("/mirrors", mirrorHandler.ListMirrorsHandler)
+	admin.POST("/mirrors", mirrorHandler.CreateMirrorHandler)
+	admin.GET("/mirrors/:id", mirrorHandler.GetMirrorHandler)
+	admin.DELETE("/mirrors/:id", mirrorHandler.DeleteMirrorHandler)
+	admin.POST("/mirrors/:id/sync", mirrorHandler.SyncMirrorHandler)
+
+	// API GraphQL
+	router.POST("/graphql", graphQLHandler.QueryHandler)
 
-	// Inicia o servidor HTTP
-	fmt.Printf("Servidor iniciado na porta %s\n", port)
-	log.Fatal(router.Run(":" + port)) // Inicia o servidor na porta especificada
+	// Inicia o servidor HTTP (ou HTTPS, se configurado)
+	log.Fatal(serve(router, cfg))
 }
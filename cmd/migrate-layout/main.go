@@ -0,0 +1,60 @@
+// Comando migrate-layout move os arquivos do armazenamento gerenciado entre os layouts por data
+// (ano/mês) e por hash (endereçado por conteúdo), atualizando os caminhos no banco de dados.
+//
+// Uso:
+//
+//	go run ./cmd/migrate-layout -layout hash [-dry-run]
+//
+// Depois de migrar, ajuste STORAGE_LAYOUT para que novas fotos sigam o mesmo layout.
+// A aplicação não deve estar em execução durante a migração.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"photo-manager/internal/config"
+	"photo-manager/internal/database"
+	"photo-manager/internal/service"
+	"photo-manager/internal/storage"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	layout := flag.String("layout", "", "Layout de destino: 'date' ou 'hash'")
+	dryRun := flag.Bool("dry-run", false, "Apenas informa quantos arquivos seriam movidos")
+	flag.Parse()
+
+	if !storage.ValidLayout(*layout) {
+		log.Fatalf("Layout inválido: '%s' (use '%s' ou '%s')", *layout, storage.LayoutDate, storage.LayoutHash)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Atenção: Nenhum arquivo .env encontrado. Usando variáveis de ambiente do sistema.")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Configuração inválida: %v", err)
+	}
+
+	database.InitDB(cfg.DatabasePath, 0) // A migração pode levar mais que o limite de uma consulta da API
+
+	fileManager := storage.NewFileManager(cfg.PhotoStoragePath)
+	if err := service.NewVolumeService(database.DB, fileManager).LoadVolumes(); err != nil {
+		log.Fatalf("Falha ao carregar volumes de armazenamento: %v", err)
+	}
+
+	photoService := service.NewPhotoService(database.DB, fileManager)
+	result, err := photoService.MigrateLayout(context.Background(), *layout, *dryRun)
+	if err != nil {
+		log.Fatalf("Falha na migração: %v", err)
+	}
+
+	verb := "movidos"
+	if *dryRun {
+		verb = "a mover"
+	}
+	fmt.Printf("Arquivos %s: %d, ignorados: %d, com falha: %d\n", verb, result.Moved, result.Skipped, result.Failed)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"photo-manager/internal/api"
+	"photo-manager/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// configureProxies define em quais proxies reversos o roteador confia para obter o IP real do cliente
+// (c.ClientIP(), usado nos logs). Sem proxies configurados, os cabeçalhos X-Forwarded-For e X-Real-IP são
+// ignorados e o IP da conexão é usado, evitando que clientes falsifiquem o próprio endereço.
+func configureProxies(router *gin.Engine, cfg *config.Config) error {
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return fmt.Errorf("proxies confiáveis inválidos: %w", err)
+	}
+	if cfg.ClientIPHeader != "" {
+		// Plataformas como Cloudflare e Google App Engine informam o IP do cliente em um cabeçalho próprio
+		router.TrustedPlatform = cfg.ClientIPHeader
+	}
+	if len(cfg.TrustedProxies) > 0 {
+		log.Printf("Proxies reversos confiáveis: %s\n", strings.Join(cfg.TrustedProxies, ", "))
+	}
+	return nil
+}
+
+// publicPaths são os prefixos das rotas atendidas pelos listeners de escopo public.
+var publicPaths = []string{"/ping", "/s/", "/gallery/"}
+
+// serve inicia o servidor em cada endereço de LISTEN_ADDRS (ou na porta APP_PORT), com HTTPS nos endereços
+// TCP quando há certificado configurado (arquivos ou Let's Encrypt). Sockets Unix atendem sempre por HTTP,
+// pois o TLS fica a cargo do proxy local. Com TLS_HTTP_REDIRECT_PORT, uma porta HTTP adicional redireciona
+// para HTTPS e, com certificados automáticos, responde aos desafios ACME. Com HTTP2_ENABLED, as conexões
+// HTTPS negociam HTTP/2 via ALPN. Retorna quando um dos servidores para.
+func serve(router *gin.Engine, cfg *config.Config) error {
+	var tlsConfig *tls.Config
+	var redirect http.Handler
+	if cfg.TLSEnabled() {
+		redirect = redirectToHTTPS(httpsPort(cfg))
+		if len(cfg.AutocertDomains) > 0 {
+			manager := &autocert.Manager{
+				Prompt:     autocert.AcceptTOS,
+				HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+				Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+				Email:      cfg.AutocertEmail,
+			}
+			tlsConfig = manager.TLSConfig()
+			redirect = manager.HTTPHandler(redirect)
+			log.Printf("Certificados TLS automáticos (Let's Encrypt) para: %s\n", strings.Join(cfg.AutocertDomains, ", "))
+		} else {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		tlsConfig.NextProtos = alpnProtocols(tlsConfig.NextProtos, cfg.HTTP2Enabled)
+		if cfg.HTTP2Enabled {
+			log.Println("HTTP/2 habilitado nas conexões HTTPS")
+		}
+	}
+
+	errs := make(chan error, len(cfg.Listeners)+1)
+	for _, l := range cfg.Listeners {
+		listener, err := listen(l, cfg.SocketMode)
+		if err != nil {
+			return err
+		}
+		server := &http.Server{Handler: api.Versioned(scopeHandler(router, l.Scope))}
+		if tlsConfig != nil && l.Network == "tcp" {
+			server.TLSConfig = tlsConfig
+			if !cfg.HTTP2Enabled {
+				// Um mapa vazio (não nil) impede que o net/http configure o HTTP/2 automaticamente
+				server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+			}
+			fmt.Printf("Servidor HTTPS iniciado em %s (rotas: %s)\n", l, l.Scope)
+			// Com certificados automáticos, os arquivos ficam vazios: o certificado vem de TLSConfig.GetCertificate
+			go func() { errs <- server.ServeTLS(listener, cfg.TLSCertFile, cfg.TLSKeyFile) }()
+		} else {
+			fmt.Printf("Servidor iniciado em %s (rotas: %s)\n", l, l.Scope)
+			go func() { errs <- server.Serve(listener) }()
+		}
+	}
+
+	if cfg.HTTPRedirectPort != "" {
+		go func() {
+			log.Printf("Redirecionamento HTTP para HTTPS na porta %s\n", cfg.HTTPRedirectPort)
+			if err := http.ListenAndServe(":"+cfg.HTTPRedirectPort, redirect); err != nil {
+				log.Printf("Erro no servidor de redirecionamento HTTP: %v\n", err)
+			}
+		}()
+	}
+	return <-errs
+}
+
+// alpnProtocols monta a lista de protocolos ALPN: "h2" (se habilitado) antes de "http/1.1", seguidos dos
+// demais já presentes (ex: "acme-tls/1" dos desafios do Let's Encrypt).
+func alpnProtocols(existing []string, http2 bool) []string {
+	protos := []string{"http/1.1"}
+	if http2 {
+		protos = []string{"h2", "http/1.1"}
+	}
+	for _, p := range existing {
+		if p != "h2" && p != "http/1.1" {
+			protos = append(protos, p)
+		}
+	}
+	return protos
+}
+
+// listen abre o endereço do listener. Um socket Unix que sobrou de uma execução anterior é removido antes,
+// e o novo recebe as permissões configuradas (ex: para que o nginx consiga se conectar).
+func listen(l config.Listener, socketMode os.FileMode) (net.Listener, error) {
+	if l.Network != "unix" {
+		return net.Listen(l.Network, l.Address)
+	}
+	if info, err := os.Lstat(l.Address); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(l.Address)
+	}
+	listener, err := net.Listen("unix", l.Address)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(l.Address, socketMode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("não foi possível definir as permissões do socket '%s': %w", l.Address, err)
+	}
+	return listener, nil
+}
+
+// scopeHandler restringe as rotas atendidas por um listener. No escopo public, apenas os links de
+// compartilhamento, a galeria pública e /ping respondem; as demais rotas recebem 404, como se não existissem.
+func scopeHandler(router *gin.Engine, scope string) http.Handler {
+	if scope != config.ScopePublic {
+		return router
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, prefix := range publicPaths {
+			if r.URL.Path == strings.TrimSuffix(prefix, "/") || strings.HasPrefix(r.URL.Path, prefix) {
+				router.ServeHTTP(w, r)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+}
+
+// httpsPort retorna a porta do primeiro endereço TCP, para onde o redirecionamento HTTP aponta.
+func httpsPort(cfg *config.Config) string {
+	for _, l := range cfg.Listeners {
+		if l.Network != "tcp" {
+			continue
+		}
+		if _, port, err := net.SplitHostPort(l.Address); err == nil {
+			return port
+		}
+	}
+	return cfg.Port
+}
+
+// redirectToHTTPS redireciona a requisição para o mesmo endereço em HTTPS, na porta do servidor principal.
+func redirectToHTTPS(httpsPort string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	}
+}
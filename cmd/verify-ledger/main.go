@@ -0,0 +1,109 @@
+// Comando verify-ledger confere o livro-razão de integridade (LEDGER_ENABLED): o encadeamento das entradas,
+// o SHA-256 atual de cada arquivo contra o registrado e os carimbos de tempo obtidos da autoridade externa.
+//
+// Uso:
+//
+//	go run ./cmd/verify-ledger [-record-missing] [-export-tokens dir]
+//
+// Termina com código 1 se alguma divergência for encontrada. Com -record-missing, as fotos ainda sem
+// registro (anteriores à ativação do livro-razão) são incluídas na cadeia antes da verificação.
+// Com -export-tokens, cada carimbo de tempo é gravado como <seq>.tsr, para verificação independente da
+// assinatura da autoridade, ex: openssl ts -verify -digest <hash da entrada> -in 12.tsr -token_in -CAfile tsa.pem.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"photo-manager/internal/config"
+	"photo-manager/internal/database"
+	"photo-manager/internal/ledger"
+	"photo-manager/internal/service"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	recordMissing := flag.Bool("record-missing", false, "Registra na cadeia as fotos ainda sem entrada antes de verificar")
+	exportTokens := flag.String("export-tokens", "", "Diretório onde gravar os carimbos de tempo (<seq>.tsr)")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Atenção: Nenhum arquivo .env encontrado. Usando variáveis de ambiente do sistema.")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Configuração inválida: %v", err)
+	}
+	if !cfg.LedgerEnabled {
+		log.Println("Atenção: LEDGER_ENABLED não está ativado; novas fotos não estão sendo registradas.")
+	}
+
+	database.InitDB(cfg.DatabasePath, 0) // A verificação relê todos os arquivos
+
+	var timestamps *ledger.TimestampClient
+	if cfg.LedgerTSAURL != "" {
+		timestamps = ledger.NewTimestampClient(cfg.LedgerTSAURL)
+	}
+	ledgerService := service.NewLedgerService(database.DB, timestamps)
+	ctx := context.Background()
+
+	if *recordMissing {
+		recorded, err := ledgerService.RecordMissing(ctx)
+		fmt.Printf("Fotos registradas: %d\n", recorded)
+		if err != nil {
+			log.Printf("Falha ao registrar fotos existentes: %v\n", err)
+		}
+		if _, err := ledgerService.AnchorHead(ctx); err != nil {
+			log.Printf("Falha ao carimbar a cadeia: %v\n", err)
+		}
+	}
+
+	report, err := ledgerService.Verify(ctx)
+	if err != nil {
+		log.Fatalf("Falha na verificação: %v", err)
+	}
+
+	if *exportTokens != "" {
+		if err := writeTokens(*exportTokens); err != nil {
+			log.Fatalf("Falha ao exportar os carimbos de tempo: %v", err)
+		}
+	}
+
+	for _, problem := range report.Problems {
+		fmt.Printf("[%s] entrada %d, foto %d: %s\n", problem.Kind, problem.Seq, problem.PhotoID, problem.Detail)
+	}
+	if len(report.Updated) > 0 {
+		fmt.Printf("Fotos alteradas pela aplicação depois da ingestão (registradas na cadeia): %v\n", report.Updated)
+	}
+	if len(report.Unrecorded) > 0 {
+		fmt.Printf("Fotos sem registro: %d (use -record-missing para incluí-las)\n", len(report.Unrecorded))
+	}
+	fmt.Printf("Entradas: %d, arquivos conferidos: %d, carimbos de tempo: %d, divergências: %d\n",
+		report.Entries, report.Photos, report.Timestamps, len(report.Problems))
+	if !report.Intact() {
+		os.Exit(1)
+	}
+	fmt.Println("Livro-razão íntegro: nenhum arquivo ou entrada foi alterado desde o registro.")
+}
+
+// writeTokens grava cada carimbo de tempo no diretório, nomeado pela entrada carimbada.
+func writeTokens(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	var timestamps []database.LedgerTimestamp
+	if err := database.DB.Order("seq").Find(&timestamps).Error; err != nil {
+		return err
+	}
+	for _, timestamp := range timestamps {
+		name := filepath.Join(dir, fmt.Sprintf("%d.tsr", timestamp.Seq))
+		if err := os.WriteFile(name, timestamp.Token, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
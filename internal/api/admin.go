@@ -0,0 +1,32 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"photo-manager/internal/i18n"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin restringe a rota ao administrador, identificado pelo token informado em
+// "Authorization: Bearer <token>" ou no cabeçalho X-Admin-Token. Sem token configurado (instalações de
+// um único usuário), as rotas continuam abertas.
+func RequireAdmin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		provided := c.GetHeader("X-Admin-Token")
+		if auth := c.GetHeader("Authorization"); provided == "" && strings.HasPrefix(auth, "Bearer ") {
+			provided = strings.TrimPrefix(auth, "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message(c, i18n.CodeAdminRequired), "code": i18n.CodeAdminRequired})
+			return
+		}
+		c.Next()
+	}
+}
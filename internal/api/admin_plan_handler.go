@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminPlanHandler gerencia os planos de ação gravados pelas simulações das operações destrutivas
+// (POST /admin/duplicates/reclaim e POST /albums/:id/policies/:policy_id/plan).
+type AdminPlanHandler struct {
+	PlanService *service.AdminPlanService
+}
+
+// NewAdminPlanHandler cria uma nova instância de AdminPlanHandler.
+func NewAdminPlanHandler(s *service.AdminPlanService) *AdminPlanHandler {
+	return &AdminPlanHandler{
+		PlanService: s,
+	}
+}
+
+// ListPlansHandler lista os planos, mais recentes primeiro: ?operation=dedupe_reclaim|album_policy,
+// ?status=pending|executing|executed|discarded, ?limit (padrão 50) e ?offset.
+func (h *AdminPlanHandler) ListPlansHandler(c *gin.Context) {
+	var query struct {
+		Operation string `form:"operation" binding:"omitempty,oneof=dedupe_reclaim album_policy"`
+		Status    string `form:"status" binding:"omitempty,oneof=pending executing executed discarded"`
+		Limit     int    `form:"limit,default=50" binding:"min=1,max=500"`
+		Offset    int    `form:"offset" binding:"min=0"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	plans, total, err := h.PlanService.ListPlans(service.AdminPlanFilter{
+		Operation: query.Operation,
+		Status:    query.Status,
+		Limit:     query.Limit,
+		Offset:    query.Offset,
+	})
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePlansFetchFailed, err)
+		return
+	}
+
+	response := []gin.H{}
+	for _, plan := range plans {
+		response = append(response, adminPlanResponse(plan))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": response, "total": total})
+}
+
+// GetPlanHandler retorna um plano com todas as ações: as fotos e os arquivos afetados e os bytes liberados.
+func (h *AdminPlanHandler) GetPlanHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidPlanID)
+	if !ok {
+		return
+	}
+	plan, actions, err := h.PlanService.GetPlan(id)
+	if err != nil {
+		respondPlanError(c, err, i18n.CodePlansFetchFailed)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": adminPlanDetailResponse(*plan, actions)})
+}
+
+// ConfirmPlanHandler executa um plano pendente, aplicando exatamente as ações revisadas. Ações cuja foto
+// mudou desde o plano são ignoradas. Planos executados, descartados ou antigos demais respondem 409.
+func (h *AdminPlanHandler) ConfirmPlanHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidPlanID)
+	if !ok {
+		return
+	}
+	plan, actions, err := h.PlanService.Execute(c.Request.Context(), id)
+	if err != nil {
+		respondPlanError(c, err, i18n.CodePlanExecuteFailed)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": adminPlanDetailResponse(*plan, actions)})
+}
+
+// DiscardPlanHandler descarta um plano pendente sem executá-lo.
+func (h *AdminPlanHandler) DiscardPlanHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidPlanID)
+	if !ok {
+		return
+	}
+	plan, err := h.PlanService.Discard(id)
+	if err != nil {
+		respondPlanError(c, err, i18n.CodeInternalError)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": adminPlanResponse(*plan)})
+}
+
+// respondPlanError responde 404 para planos inexistentes, 409 para planos que não podem mais ser
+// executados e 500 (com o código informado) para os demais erros.
+func respondPlanError(c *gin.Context, err error, code string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondError(c, http.StatusNotFound, i18n.CodePlanNotFound)
+	case errors.Is(err, service.ErrPlanNotPending), errors.Is(err, service.ErrPlanExpired):
+		respondServiceError(c, http.StatusConflict, err, code)
+	default:
+		respondErrorCause(c, http.StatusInternalServerError, code, err)
+	}
+}
+
+// adminPlanResponse formata o resumo de um plano para a resposta da API.
+func adminPlanResponse(plan database.AdminPlan) gin.H {
+	executedAt := ""
+	if plan.ExecutedAt != nil {
+		executedAt = plan.ExecutedAt.Format(time.RFC3339)
+	}
+	params := json.RawMessage(plan.Params)
+	if !json.Valid(params) {
+		params = json.RawMessage("{}")
+	}
+	return gin.H{
+		"id":           plan.ID,
+		"operation":    plan.Operation,
+		"params":       params,
+		"status":       plan.Status,
+		"action_count": plan.ActionCount,
+		"bytes":        plan.Bytes,
+		"done":         plan.Done,
+		"skipped":      plan.Skipped,
+		"failed":       plan.Failed,
+		"created_at":   plan.CreatedAt.Format(time.RFC3339),
+		"executed_at":  executedAt,
+	}
+}
+
+// adminPlanDetailResponse formata um plano com suas ações para a resposta da API.
+func adminPlanDetailResponse(plan database.AdminPlan, actions []database.AdminPlanAction) gin.H {
+	items := make([]gin.H, 0, len(actions))
+	for _, action := range actions {
+		items = append(items, gin.H{
+			"id":            action.ID,
+			"action":        action.Action,
+			"photo_id":      action.PhotoID,
+			"album_id":      action.AlbumID,
+			"keep_photo_id": action.KeepPhotoID,
+			"stored_path":   action.StoredPath,
+			"file_size":     action.FileSize,
+			"bytes":         action.Bytes,
+			"status":        action.Status,
+			"error":         action.Error,
+		})
+	}
+	response := adminPlanResponse(plan)
+	response["actions"] = items
+	return response
+}
@@ -0,0 +1,575 @@
+package api
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"photo-manager/internal/contactsheet"
+	"photo-manager/internal/gallery"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AlbumHandler gerencia as requisições HTTP para álbuns.
+type AlbumHandler struct {
+	AlbumService *service.AlbumService
+	PhotoService *service.PhotoService
+	AlbumImport  *service.AlbumImportService // Importação de álbuns em ZIP
+}
+
+// NewAlbumHandler cria uma nova instância de AlbumHandler.
+func NewAlbumHandler(s *service.AlbumService, ps *service.PhotoService) *AlbumHandler {
+	return &AlbumHandler{
+		AlbumService: s,
+		PhotoService: ps,
+		AlbumImport:  service.NewAlbumImportService(ps, s),
+	}
+}
+
+// ListAlbumsHandler lista os álbuns com a contagem de fotos e o total de bytes de cada um.
+// ?fields= seleciona os campos de cada álbum, ex: ?fields=id,name,photo_count. Os álbuns vêm do mais
+// recente para o mais antigo, pelo período das fotos; ?sort=name ordena pelo nome.
+func (h *AlbumHandler) ListAlbumsHandler(c *gin.Context) {
+	var query struct {
+		Sort     string   `form:"sort" binding:"omitempty,oneof=date name"`
+		Metadata []string `form:"metadata"` // Campos personalizados: "Cliente" (preenchido) ou "Cliente:Maria"
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+	serialize, ok := albumFieldsSerializer(c)
+	if !ok {
+		return
+	}
+
+	albums, err := h.AlbumService.ListAlbums()
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAlbumsFetchFailed, err)
+		return
+	}
+	if query.Sort == "name" {
+		// A listagem vem do cache compartilhado: ordena uma cópia
+		albums = slices.Clone(albums)
+		slices.SortStableFunc(albums, func(a, b service.AlbumSummary) int {
+			if a.Pinned != b.Pinned {
+				if a.Pinned {
+					return -1
+				}
+				return 1
+			}
+			return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		})
+	}
+
+	conditions := service.ParseAlbumMetadataConditions(query.Metadata)
+	responseAlbums := []gin.H{}
+	for _, album := range albums {
+		if service.MatchAlbumMetadata(album.Album, conditions) {
+			responseAlbums = append(responseAlbums, serialize(album))
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": responseAlbums})
+}
+
+// GetAlbumHandler retorna um álbum com suas agregações e fotos.
+func (h *AlbumHandler) GetAlbumHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumID)
+	if !ok {
+		return
+	}
+
+	album, err := h.AlbumService.GetAlbum(id)
+	if err != nil {
+		respondAlbumError(c, err)
+		return
+	}
+
+	photos, err := h.AlbumService.GetAlbumPhotos(id)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAlbumPhotosFetchFailed, err)
+		return
+	}
+
+	responsePhotos := []gin.H{}
+	for _, photo := range photos {
+		responsePhotos = append(responsePhotos, photoResponse(photo))
+	}
+
+	response := albumResponse(*album)
+	response["photos"] = responsePhotos
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// CreateAlbumHandler cria um novo álbum.
+func (h *AlbumHandler) CreateAlbumHandler(c *gin.Context) {
+	var req struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldRequired, "name")
+		return
+	}
+
+	album, err := h.AlbumService.CreateAlbum(req.Name, req.Description)
+	if err != nil {
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeAlbumCreateFailed, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": albumResponse(service.AlbumSummary{Album: *album})})
+}
+
+// CreateAlbumFromFilterHandler cria um álbum com todas as fotos que atendem ao filtro informado na query
+// string (mesmos parâmetros de GET /photos: year, month, filename, tag, album_id, favorites).
+// As fotos são copiadas no momento da chamada; o álbum não é atualizado se o filtro passar a retornar outras fotos.
+func (h *AlbumHandler) CreateAlbumFromFilterHandler(c *gin.Context) {
+	filter, ok := parsePhotoFilter(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldRequired, "name")
+		return
+	}
+
+	photoIDs, err := h.PhotoService.GetPhotoIDs(c.Request.Context(), filter)
+	if err != nil {
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeAlbumFilterFailed, err)
+		return
+	}
+
+	album, err := h.AlbumService.CreateAlbumWithPhotos(req.Name, req.Description, photoIDs)
+	if err != nil {
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeAlbumCreateFailed, err)
+		return
+	}
+
+	summary, err := h.AlbumService.GetAlbum(album.ID)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAlbumCreatedFetch, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": albumResponse(*summary)})
+}
+
+// AddAlbumPhotosHandler adiciona fotos a um álbum.
+func (h *AlbumHandler) AddAlbumPhotosHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumID)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		PhotoIDs []uint `json:"photo_ids" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeAlbumPhotoIDsEmpty)
+		return
+	}
+
+	added, err := h.AlbumService.AddPhotosToAlbum(id, req.PhotoIDs)
+	if err != nil {
+		respondAlbumError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message(c, i18n.CodeAlbumPhotosAdded, added), "added": added})
+}
+
+// RemoveAlbumPhotoHandler remove uma foto de um álbum, sem apagar a foto.
+func (h *AlbumHandler) RemoveAlbumPhotoHandler(c *gin.Context) {
+	albumID, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumID)
+	if !ok {
+		return
+	}
+	photoID, ok := parseIDParam(c, "photo_id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+
+	if err := h.AlbumService.RemovePhotoFromAlbum(albumID, photoID); err != nil {
+		respondAlbumError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message(c, i18n.CodeAlbumPhotoRemoved)})
+}
+
+// DeleteAlbumHandler remove um álbum. As fotos são apenas retiradas do álbum; com ?delete_photos=true, as
+// que só estão nele (e não estão bloqueadas) são apagadas da biblioteca, o que exige ?confirm=<quantidade>.
+// Sem a confirmação correta nada é alterado e a resposta 409 traz a prévia ("photos_to_delete" e
+// "kept_photo_ids"). ?delete_file=true apaga também o original das fotos indexadas no local, como em
+// DELETE /photos/:id.
+func (h *AlbumHandler) DeleteAlbumHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumID)
+	if !ok {
+		return
+	}
+	var query struct {
+		DeletePhotos bool `form:"delete_photos"`
+		Confirm      *int `form:"confirm" binding:"omitempty,min=0"`
+		DeleteFile   bool `form:"delete_file"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	report, err := h.PhotoService.DeleteAlbum(c.Request.Context(), id, service.AlbumDeleteOptions{
+		DeletePhotos: query.DeletePhotos,
+		Confirm:      query.Confirm,
+		DeleteFiles:  query.DeleteFile,
+	})
+	var confirmErr *service.AlbumDeleteConfirmationError
+	switch {
+	case err == nil:
+	case errors.As(err, &confirmErr):
+		body := errorBody(c, confirmErr.Localize(locale(c)), i18n.CodeAlbumDeleteConfirmationRequired)
+		body["photos_to_delete"] = confirmErr.PhotosToDelete
+		body["kept_photo_ids"] = confirmErr.KeptPhotoIDs
+		c.JSON(http.StatusConflict, body)
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondError(c, http.StatusNotFound, i18n.CodeAlbumNotFound)
+		return
+	case errors.Is(err, service.ErrAlbumLocked):
+		respondError(c, http.StatusLocked, i18n.CodeAlbumLocked)
+		return
+	default:
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAlbumDeleteFailed, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message(c, i18n.CodeAlbumDeleted),
+		"data": gin.H{
+			"album_id":          report.AlbumID,
+			"detached_photos":   report.DetachedPhotos,
+			"deleted_photo_ids": report.DeletedPhotoIDs,
+			"kept_photo_ids":    report.KeptPhotoIDs,
+		},
+	})
+}
+
+// SetPinnedHandler fixa ou desafixa um álbum no topo da listagem de álbuns.
+func (h *AlbumHandler) SetPinnedHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumID)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Pinned *bool `json:"pinned" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldRequired, "pinned")
+		return
+	}
+
+	album, err := h.AlbumService.SetPinned(id, *req.Pinned)
+	if err != nil {
+		respondAlbumError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": albumResponse(*album)})
+}
+
+// SetMetadataHandler substitui os campos personalizados do álbum (PUT /albums/:id/metadata), ex:
+// {"metadata": {"Cliente": "Maria Souza", "Local": "Praia do Forte", "Cachê": "1500"}}; {"metadata": {}} remove
+// todos. Os campos aparecem nas respostas dos álbuns, filtram a listagem (GET /albums?metadata=Cliente:Maria) e
+// acompanham as exportações.
+func (h *AlbumHandler) SetMetadataHandler(c *gin.Context) {
+	h.setMetadata(c, false)
+}
+
+// UpdateMetadataHandler altera apenas os campos personalizados informados (PATCH /albums/:id/metadata),
+// mantendo os demais; um valor vazio remove o campo, ex: {"metadata": {"Cachê": ""}}.
+func (h *AlbumHandler) UpdateMetadataHandler(c *gin.Context) {
+	h.setMetadata(c, true)
+}
+
+// setMetadata grava os campos personalizados do álbum da rota, substituindo ou combinando com os anteriores.
+func (h *AlbumHandler) setMetadata(c *gin.Context, merge bool) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumID)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Metadata map[string]string `json:"metadata" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeAlbumMetadataInvalid)
+		return
+	}
+
+	album, err := h.AlbumService.SetAlbumMetadata(id, req.Metadata, merge)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || i18n.Code(err) != "" {
+			respondAlbumError(c, err)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAlbumMetadataUpdateFailed, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": albumResponse(*album)})
+}
+
+// LockAlbumHandler bloqueia o álbum: suas fotos não podem ser adicionadas, removidas, alteradas ou apagadas.
+func (h *AlbumHandler) LockAlbumHandler(c *gin.Context) {
+	h.setLocked(c, true)
+}
+
+// UnlockAlbumHandler desbloqueia o álbum. A rota é separada do bloqueio para poder ser restrita ao administrador.
+func (h *AlbumHandler) UnlockAlbumHandler(c *gin.Context) {
+	h.setLocked(c, false)
+}
+
+// setLocked aplica o bloqueio ou desbloqueio do álbum da rota.
+func (h *AlbumHandler) setLocked(c *gin.Context, locked bool) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumID)
+	if !ok {
+		return
+	}
+
+	album, err := h.AlbumService.SetLocked(id, locked)
+	if err != nil {
+		respondAlbumError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": albumResponse(*album)})
+}
+
+// ExportGalleryHandler exporta o álbum como uma galeria HTML estática em um arquivo ZIP (index.html,
+// páginas das fotos, imagens e miniaturas), pronta para abrir do disco ou publicar em qualquer servidor estático.
+// ?size= define o maior lado das imagens, em pixels (padrão: 1600).
+func (h *AlbumHandler) ExportGalleryHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumID)
+	if !ok {
+		return
+	}
+
+	var query struct {
+		Size int `form:"size" binding:"omitempty,min=320,max=4096"` // min = gallery.DefaultThumbSize
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+	size := gallery.DefaultImageSize
+	if query.Size != 0 {
+		size = query.Size
+	}
+
+	album, err := h.AlbumService.GetAlbum(id)
+	if err != nil {
+		respondAlbumError(c, err)
+		return
+	}
+
+	photos, err := h.AlbumService.GetAlbumPhotos(id)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAlbumPhotosFetchFailed, err)
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"album-%d-galeria.zip\"", album.ID))
+
+	// O ZIP é gerado diretamente na resposta; após o início do envio, erros só podem ser registrados no log
+	zw := zip.NewWriter(c.Writer)
+	result, err := gallery.WriteZip(c.Request.Context(), zw,
+		gallery.Album{Name: album.Name, Description: album.Description, Metadata: service.AlbumMetadata(album.Album)},
+		photos,
+		gallery.Options{ImageSize: size, MaxPixels: h.PhotoService.Thumbnails.MaxPixels},
+	)
+	if err != nil {
+		log.Printf("Erro ao exportar a galeria do álbum %d: %v\n", album.ID, err)
+		return
+	}
+	if err := zw.Close(); err != nil {
+		log.Printf("Erro ao finalizar a galeria do álbum %d: %v\n", album.ID, err)
+		return
+	}
+	log.Printf("Galeria do álbum %d exportada: %d foto(s), %d ignorada(s)\n", album.ID, result.Exported, result.Skipped)
+}
+
+// ContactSheetHandler gera uma folha de contatos em PDF do álbum: uma grade paginada de miniaturas com
+// legenda e data, para impressão e revisão rápida.
+// ?columns= define as colunas por página (1 a 8, padrão: 4), ?page_size= o tamanho da página
+// (a4, a3, letter ou legal; padrão: a4) e ?orientation=landscape gira a página.
+func (h *AlbumHandler) ContactSheetHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumID)
+	if !ok {
+		return
+	}
+
+	var query struct {
+		Columns     int    `form:"columns" binding:"omitempty,min=1,max=8"` // max = contactsheet.MaxColumns
+		PageSize    string `form:"page_size,default=a4" binding:"oneof=a4 a3 letter legal"`
+		Orientation string `form:"orientation,default=portrait" binding:"oneof=portrait landscape"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+	opts := contactsheet.Options{
+		Columns:   query.Columns,
+		PageSize:  query.PageSize,
+		Landscape: query.Orientation == "landscape",
+		MaxPixels: h.PhotoService.Thumbnails.MaxPixels,
+	}
+
+	album, err := h.AlbumService.GetAlbum(id)
+	if err != nil {
+		respondAlbumError(c, err)
+		return
+	}
+
+	photos, err := h.AlbumService.GetAlbumPhotos(id)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAlbumPhotosFetchFailed, err)
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"album-%d-contatos.pdf\"", album.ID))
+
+	// O PDF é gerado diretamente na resposta; após o início do envio, erros só podem ser registrados no log
+	result, err := contactsheet.Write(c.Request.Context(), c.Writer, album.Name, photos, opts)
+	if err != nil {
+		log.Printf("Erro ao gerar a folha de contatos do álbum %d: %v\n", album.ID, err)
+		return
+	}
+	log.Printf("Folha de contatos do álbum %d gerada: %d página(s), %d foto(s), %d ignorada(s)\n",
+		album.ID, result.Pages, result.Exported, result.Skipped)
+}
+
+// respondAlbumError responde com 404 para registros inexistentes, 423 para álbuns bloqueados e 400 para os demais erros.
+func respondAlbumError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(c, http.StatusNotFound, i18n.CodeAlbumNotFound)
+		return
+	}
+	if errors.Is(err, service.ErrAlbumLocked) {
+		respondError(c, http.StatusLocked, i18n.CodeAlbumLocked)
+		return
+	}
+	respondServiceError(c, http.StatusBadRequest, err, i18n.CodeBadRequest)
+}
+
+// albumResponse formata um álbum e suas agregações para a resposta da API.
+func albumResponse(album service.AlbumSummary) gin.H {
+	return gin.H{
+		"id":               album.ID,
+		"public_id":        album.PublicID,
+		"name":             album.Name,
+		"description":      album.Description,
+		"pinned":           album.Pinned,
+		"locked":           album.Locked,
+		"created_at":       album.CreatedAt.Format(time.RFC3339),
+		"start_date":       formatOptionalDate(album.StartDate),
+		"end_date":         formatOptionalDate(album.EndDate),
+		"photo_count":      album.PhotoCount,
+		"total_bytes":      album.TotalBytes,
+		"soft_quota_bytes": album.SoftQuotaBytes,
+		"over_quota":       album.QuotaExceeded,
+		"default_tags":     album.DefaultTags,
+		"metadata":         service.AlbumMetadata(album.Album),
+	}
+}
+
+// formatOptionalDate formata uma data opcional em RFC 3339 (vazio se ausente).
+func formatOptionalDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// AlbumHealthHandler diagnostica associações órfãs entre álbuns e fotos, álbuns vazios e fotos fora de
+// qualquer álbum, sem alterar nada.
+func (h *AlbumHandler) AlbumHealthHandler(c *gin.Context) {
+	report, err := h.AlbumService.AlbumHealth(c.Request.Context())
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAlbumHealthFailed, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": albumHealthResponse(report)})
+}
+
+// CleanupAlbumsHandler remove as associações órfãs e, com "empty_albums": true, os álbuns vazios que não
+// estão fixados nem bloqueados (opcionalmente só os criados há mais de "empty_older_than_days" dias). Por
+// segurança, o padrão é a simulação: nada é removido sem "dry_run": false.
+func (h *AlbumHandler) CleanupAlbumsHandler(c *gin.Context) {
+	var req struct {
+		OrphanLinks        *bool `json:"orphan_links"`
+		EmptyAlbums        bool  `json:"empty_albums"`
+		EmptyOlderThanDays int   `json:"empty_older_than_days" binding:"min=0"`
+		DryRun             *bool `json:"dry_run"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, i18n.CodeAlbumCleanupInvalid)
+			return
+		}
+	}
+
+	opts := service.AlbumCleanupOptions{
+		OrphanLinks:    req.OrphanLinks == nil || *req.OrphanLinks,
+		EmptyAlbums:    req.EmptyAlbums,
+		EmptyOlderThan: time.Duration(req.EmptyOlderThanDays) * 24 * time.Hour,
+		DryRun:         req.DryRun == nil || *req.DryRun,
+	}
+	report, err := h.AlbumService.CleanupAlbums(c.Request.Context(), opts)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAlbumCleanupFailed, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": albumHealthResponse(report)})
+}
+
+// albumHealthResponse formata o diagnóstico dos álbuns para a resposta da API.
+func albumHealthResponse(report *service.AlbumHealthReport) gin.H {
+	links := []gin.H{}
+	for _, link := range report.OrphanLinks {
+		links = append(links, gin.H{"id": link.ID, "album_id": link.AlbumID, "photo_id": link.PhotoID, "reason": link.Reason})
+	}
+	albums := []gin.H{}
+	for _, album := range report.EmptyAlbums {
+		albums = append(albums, gin.H{
+			"id":         album.ID,
+			"name":       album.Name,
+			"created_at": album.CreatedAt.Format(time.RFC3339),
+			"pinned":     album.Pinned,
+			"locked":     album.Locked,
+		})
+	}
+	return gin.H{
+		"orphan_links":       links,
+		"orphan_link_count":  len(report.OrphanLinks),
+		"empty_albums":       albums,
+		"empty_album_count":  len(report.EmptyAlbums),
+		"photos_in_no_album": report.PhotosInNoAlbum,
+		"dry_run":            report.DryRun,
+		"removed_links":      report.RemovedLinks,
+		"removed_album_ids":  report.RemovedAlbumIDs,
+		"skipped_album_ids":  report.SkippedAlbumIDs,
+	}
+}
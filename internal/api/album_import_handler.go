@@ -0,0 +1,110 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportZipHandler importa um arquivo ZIP enviado no campo "archive" (POST /albums/import), expandindo-o no
+// servidor: cada pasta de primeiro nível vira um álbum com o seu nome e as imagens da raiz vão para um álbum
+// com o nome do arquivo. O campo "album" coloca todas as imagens em um único álbum com o nome informado.
+// Aceita também "source" e "device", como o upload.
+func (h *AlbumHandler) ImportZipHandler(c *gin.Context) {
+	form, err := c.MultipartForm()
+	if limit, tooLarge := bodyTooLarge(err); tooLarge {
+		respondError(c, http.StatusRequestEntityTooLarge, i18n.CodeRequestTooLarge, limit>>20)
+		return
+	}
+	if err != nil {
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeMultipartInvalid, err)
+		return
+	}
+	files := form.File["archive"]
+	if len(files) != 1 {
+		respondError(c, http.StatusBadRequest, i18n.CodeZipRequired)
+		return
+	}
+	origin, ok := uploadOrigin(c, form)
+	if !ok {
+		return
+	}
+
+	archiveName := files[0].Filename
+	defaultAlbum := strings.TrimSpace(strings.TrimSuffix(archiveName, filepath.Ext(archiveName)))
+	singleAlbum := false
+	if values := form.Value["album"]; len(values) > 0 && strings.TrimSpace(values[0]) != "" {
+		defaultAlbum, singleAlbum = strings.TrimSpace(values[0]), true
+	}
+	if defaultAlbum == "" {
+		respondError(c, http.StatusBadRequest, i18n.CodeAlbumNameRequired)
+		return
+	}
+
+	archive, err := files[0].Open()
+	if err != nil {
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeMultipartInvalid, err)
+		return
+	}
+	defer archive.Close()
+
+	report, err := h.AlbumImport.ImportZip(c.Request.Context(), archive, files[0].Size, archiveName, defaultAlbum, singleAlbum, origin)
+	if report == nil && err != nil {
+		switch {
+		case isStorageUnavailable(err):
+			respondStorageError(c, err)
+		case i18n.Code(err) == i18n.CodeZipInvalid || i18n.Code(err) == i18n.CodeZipTooManyEntries:
+			respondServiceError(c, http.StatusBadRequest, err, i18n.CodeZipInvalid)
+		default:
+			respondErrorCause(c, http.StatusInternalServerError, i18n.CodeZipImportFailed, err)
+		}
+		return
+	}
+
+	albums := []gin.H{}
+	for _, album := range report.Albums {
+		albums = append(albums, gin.H{"id": album.ID, "name": album.Name, "created": album.Created, "added": album.Added})
+	}
+	failed := []gin.H{}
+	for _, failure := range report.Failed {
+		entry := gin.H{"entry": failure.Entry, "error": i18n.Localize(failure.Err, locale(c)), "code": i18n.Code(failure.Err)}
+		if validationErr, ok := validation.AsError(failure.Err); ok {
+			entry["error"], entry["code"], entry["validator"] = validationErr.Localize(locale(c)), validationErr.Code, validationErr.Validator
+		}
+		failed = append(failed, entry)
+	}
+	data := gin.H{
+		"albums":     albums,
+		"imported":   report.Imported,
+		"duplicates": report.Duplicates,
+		"skipped":    report.Skipped,
+		"failed":     failed,
+		"report_id":  report.ReportID,
+	}
+
+	if err != nil {
+		// Interrompida no meio (sem espaço, cliente desconectado ou erro ao preencher um álbum)
+		log.Printf("Importação do ZIP '%s' interrompida: %v\n", archiveName, err)
+		status := http.StatusInternalServerError
+		if isStorageUnavailable(err) {
+			status = storageErrorStatus(err)
+		}
+		c.JSON(status, gin.H{
+			"error": message(c, i18n.CodeZipImportFailed) + ": " + i18n.Localize(err, locale(c)),
+			"code":  i18n.CodeZipImportFailed,
+			"data":  data,
+		})
+		return
+	}
+	status := http.StatusOK
+	if len(report.Failed) > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, gin.H{"data": data})
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AlbumPolicyHandler gerencia as políticas de ciclo de vida e a cota flexível dos álbuns.
+type AlbumPolicyHandler struct {
+	AlbumPolicyService *service.AlbumPolicyService
+}
+
+// NewAlbumPolicyHandler cria uma nova instância de AlbumPolicyHandler.
+func NewAlbumPolicyHandler(s *service.AlbumPolicyService) *AlbumPolicyHandler {
+	return &AlbumPolicyHandler{
+		AlbumPolicyService: s,
+	}
+}
+
+// ListPoliciesHandler lista as políticas do álbum.
+func (h *AlbumPolicyHandler) ListPoliciesHandler(c *gin.Context) {
+	albumID, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumID)
+	if !ok {
+		return
+	}
+
+	policies, err := h.AlbumPolicyService.ListPolicies(albumID)
+	if err != nil {
+		respondAlbumError(c, err)
+		return
+	}
+
+	responsePolicies := []gin.H{}
+	for _, policy := range policies {
+		responsePolicies = append(responsePolicies, albumPolicyResponse(policy))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": responsePolicies})
+}
+
+// CreatePolicyHandler cria uma política no álbum, ex: {"name": "Capturas antigas", "action": "delete",
+// "older_than_days": 90, "screenshots_only": true}. A política só é executada depois de confirmada.
+func (h *AlbumPolicyHandler) CreatePolicyHandler(c *gin.Context) {
+	albumID, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumID)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name            string `json:"name" binding:"required"`
+		Action          string `json:"action" binding:"required"`
+		OlderThanDays   int    `json:"older_than_days" binding:"required"`
+		ScreenshotsOnly bool   `json:"screenshots_only"`
+		ExceptFavorites bool   `json:"except_favorites"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldsRequired, "'name', 'action', 'older_than_days'")
+		return
+	}
+
+	policy := database.AlbumPolicy{
+		AlbumID:         albumID,
+		Name:            req.Name,
+		Action:          req.Action,
+		OlderThanDays:   req.OlderThanDays,
+		ScreenshotsOnly: req.ScreenshotsOnly,
+		ExceptFavorites: req.ExceptFavorites,
+	}
+	if err := h.AlbumPolicyService.CreatePolicy(&policy); err != nil {
+		respondAlbumError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": albumPolicyResponse(policy)})
+}
+
+// DeletePolicyHandler remove uma política do álbum.
+func (h *AlbumPolicyHandler) DeletePolicyHandler(c *gin.Context) {
+	albumID, policyID, ok := parsePolicyParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.AlbumPolicyService.DeletePolicy(albumID, policyID); err != nil {
+		respondPolicyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message(c, i18n.CodePolicyRemoved)})
+}
+
+// PreviewPolicyHandler retorna as fotos que a política afetaria se fosse executada agora.
+func (h *AlbumPolicyHandler) PreviewPolicyHandler(c *gin.Context) {
+	albumID, policyID, ok := parsePolicyParams(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.AlbumPolicyService.GetPolicy(albumID, policyID)
+	if err != nil {
+		respondPolicyError(c, err)
+		return
+	}
+
+	preview, err := h.AlbumPolicyService.Preview(c.Request.Context(), policy)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePolicyPreviewFailed, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"policy":      albumPolicyResponse(*policy),
+		"photo_ids":   preview.PhotoIDs,
+		"photo_count": len(preview.PhotoIDs),
+		"bytes":       preview.Bytes,
+		"locked_ids":  preview.LockedIDs,
+	}})
+}
+
+// PlanPolicyHandler simula a execução da política e grava o plano de ação com as fotos e os arquivos
+// afetados e os bytes liberados. A revisão é feita em GET /admin/plans/:id e a execução, uma única vez e
+// exatamente como planejada, em POST /admin/plans/:id/confirm.
+func (h *AlbumPolicyHandler) PlanPolicyHandler(c *gin.Context) {
+	albumID, policyID, ok := parsePolicyParams(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.AlbumPolicyService.GetPolicy(albumID, policyID)
+	if err != nil {
+		respondPolicyError(c, err)
+		return
+	}
+
+	plan, err := h.AlbumPolicyService.PlanPolicy(c.Request.Context(), policy)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePlanCreateFailed, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": adminPlanResponse(*plan)})
+}
+
+// ConfirmPolicyHandler libera a política para execução pelo agendador após a revisão da prévia.
+// Com {"run_now": true} a política também é executada imediatamente.
+func (h *AlbumPolicyHandler) ConfirmPolicyHandler(c *gin.Context) {
+	albumID, policyID, ok := parsePolicyParams(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		RunNow bool `json:"run_now"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, i18n.CodeInvalidRequestBody)
+			return
+		}
+	}
+
+	policy, err := h.AlbumPolicyService.Confirm(albumID, policyID)
+	if err != nil {
+		respondPolicyError(c, err)
+		return
+	}
+
+	if req.RunNow {
+		if _, err := h.AlbumPolicyService.Execute(c.Request.Context(), policy); err != nil {
+			respondErrorCause(c, http.StatusInternalServerError, i18n.CodePolicyExecuteFailed, err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": albumPolicyResponse(*policy)})
+}
+
+// SetQuotaHandler define a cota flexível do álbum: {"soft_quota_mb": 500} (0 remove a cota).
+// Ultrapassar a cota apenas gera o aviso album.quota_exceeded; nenhum upload é bloqueado.
+func (h *AlbumPolicyHandler) SetQuotaHandler(c *gin.Context) {
+	albumID, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumID)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		SoftQuotaMB *int64 `json:"soft_quota_mb" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || *req.SoftQuotaMB < 0 {
+		respondError(c, http.StatusBadRequest, i18n.CodeSoftQuotaInvalid)
+		return
+	}
+
+	album, err := h.AlbumPolicyService.SetSoftQuota(albumID, *req.SoftQuotaMB<<20)
+	if err != nil {
+		respondAlbumError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": albumResponse(*album)})
+}
+
+// parsePolicyParams lê os parâmetros de rota :id (álbum) e :policy_id.
+func parsePolicyParams(c *gin.Context) (uint, uint, bool) {
+	albumID, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumID)
+	if !ok {
+		return 0, 0, false
+	}
+	policyID, ok := parseIDParam(c, "policy_id", i18n.CodeInvalidPolicyID)
+	if !ok {
+		return 0, 0, false
+	}
+	return albumID, policyID, true
+}
+
+// respondPolicyError responde com 404 para políticas inexistentes e 500 para os demais erros.
+func respondPolicyError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(c, http.StatusNotFound, i18n.CodePolicyNotFound)
+		return
+	}
+	respondServiceError(c, http.StatusInternalServerError, err, i18n.CodeInternalError)
+}
+
+// albumPolicyResponse formata uma política para a resposta da API.
+func albumPolicyResponse(policy database.AlbumPolicy) gin.H {
+	lastRunAt := ""
+	if policy.LastRunAt != nil {
+		lastRunAt = policy.LastRunAt.Format(time.RFC3339)
+	}
+	return gin.H{
+		"id":                policy.ID,
+		"album_id":          policy.AlbumID,
+		"name":              policy.Name,
+		"action":            policy.Action,
+		"older_than_days":   policy.OlderThanDays,
+		"screenshots_only":  policy.ScreenshotsOnly,
+		"except_favorites":  policy.ExceptFavorites,
+		"confirmed":         policy.Confirmed,
+		"last_run_at":       lastRunAt,
+		"last_run_affected": policy.LastRunAffected,
+	}
+}
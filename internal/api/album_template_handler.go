@@ -0,0 +1,225 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AlbumTemplateHandler gerencia os modelos de álbum e a criação de álbuns a partir deles.
+type AlbumTemplateHandler struct {
+	TemplateService *service.AlbumTemplateService
+}
+
+// NewAlbumTemplateHandler cria uma nova instância de AlbumTemplateHandler.
+func NewAlbumTemplateHandler(s *service.AlbumTemplateService) *AlbumTemplateHandler {
+	return &AlbumTemplateHandler{
+		TemplateService: s,
+	}
+}
+
+// ListTemplatesHandler lista os modelos de álbum (GET /album-templates).
+func (h *AlbumTemplateHandler) ListTemplatesHandler(c *gin.Context) {
+	templates, err := h.TemplateService.ListTemplates()
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAlbumTemplatesFailed, err)
+		return
+	}
+
+	response := []gin.H{}
+	for _, template := range templates {
+		response = append(response, albumTemplateResponse(template))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// GetTemplateHandler retorna um modelo de álbum (GET /album-templates/:id).
+func (h *AlbumTemplateHandler) GetTemplateHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumTemplateID)
+	if !ok {
+		return
+	}
+	template, err := h.TemplateService.GetTemplate(id)
+	if err != nil {
+		respondAlbumTemplateError(c, err, i18n.CodeAlbumTemplatesFailed)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": albumTemplateResponse(*template)})
+}
+
+// CreateTemplateHandler cria um modelo de álbum (POST /album-templates), ex: {"name": "Jantar mensal",
+// "name_pattern": "Jantar da família {{month_name}} {{year}}", "default_tags": ["família"], "share": true,
+// "policies": [{"name": "Limpar capturas", "action": "remove", "older_than_days": 30,
+// "screenshots_only": true}]}. Variáveis de data: {{year}}, {{month}}, {{month_name}}, {{day}}, {{week}} e
+// {{date}}; outras variáveis são informadas na criação do álbum.
+func (h *AlbumTemplateHandler) CreateTemplateHandler(c *gin.Context) {
+	var req struct {
+		Name               string   `json:"name" binding:"required"`
+		NamePattern        string   `json:"name_pattern" binding:"required"`
+		DescriptionPattern string   `json:"description_pattern"`
+		DefaultTags        []string `json:"default_tags"`
+		Pinned             bool     `json:"pinned"`
+		Share              bool     `json:"share"`
+		SoftQuotaBytes     int64    `json:"soft_quota_bytes"`
+		Policies           []struct {
+			Name            string `json:"name"`
+			Action          string `json:"action"`
+			OlderThanDays   int    `json:"older_than_days"`
+			ScreenshotsOnly bool   `json:"screenshots_only"`
+			ExceptFavorites bool   `json:"except_favorites"`
+		} `json:"policies"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldsRequired, "'name', 'name_pattern'")
+		return
+	}
+
+	template := database.AlbumTemplate{
+		Name:               req.Name,
+		NamePattern:        req.NamePattern,
+		DescriptionPattern: req.DescriptionPattern,
+		DefaultTags:        strings.Join(req.DefaultTags, ","),
+		Pinned:             req.Pinned,
+		Share:              req.Share,
+		SoftQuotaBytes:     req.SoftQuotaBytes,
+	}
+	for _, policy := range req.Policies {
+		template.Policies = append(template.Policies, database.AlbumTemplatePolicy{
+			Name:            policy.Name,
+			Action:          policy.Action,
+			OlderThanDays:   policy.OlderThanDays,
+			ScreenshotsOnly: policy.ScreenshotsOnly,
+			ExceptFavorites: policy.ExceptFavorites,
+		})
+	}
+	if err := h.TemplateService.CreateTemplate(&template); err != nil {
+		respondAlbumTemplateError(c, err, i18n.CodeAlbumTemplateSaveFailed)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": albumTemplateResponse(template)})
+}
+
+// DeleteTemplateHandler remove um modelo de álbum; os álbuns já criados não mudam
+// (DELETE /album-templates/:id).
+func (h *AlbumTemplateHandler) DeleteTemplateHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumTemplateID)
+	if !ok {
+		return
+	}
+	if err := h.TemplateService.DeleteTemplate(id); err != nil {
+		respondAlbumTemplateError(c, err, i18n.CodeAlbumTemplateSaveFailed)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message(c, i18n.CodeAlbumTemplateRemoved)})
+}
+
+// CreateFromTemplateHandler cria um álbum a partir de um modelo em uma única chamada (POST
+// /albums/from-template), ex: {"template_id": 1, "date": "2024-03-15", "variables": {"local": "casa da
+// vó"}, "photo_ids": [10, 11]}. Sem "date", as variáveis de data usam o dia atual; {{month_name}} segue o
+// idioma da requisição. Responde 201 com o álbum criado, ou 200 com o álbum já existente de mesmo nome, sem
+// alterá-lo, para que automações possam repetir a chamada.
+func (h *AlbumTemplateHandler) CreateFromTemplateHandler(c *gin.Context) {
+	var req struct {
+		TemplateID uint              `json:"template_id" binding:"required"`
+		Date       string            `json:"date"`
+		Variables  map[string]string `json:"variables"`
+		PhotoIDs   []uint            `json:"photo_ids"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldRequired, "template_id")
+		return
+	}
+	date := time.Now()
+	if req.Date != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", req.Date, time.Local)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, i18n.CodeAlbumTemplateDateInvalid, req.Date)
+			return
+		}
+		date = parsed
+	}
+
+	result, err := h.TemplateService.CreateAlbum(service.AlbumFromTemplateRequest{
+		TemplateID: req.TemplateID,
+		Date:       date,
+		Variables:  req.Variables,
+		Locale:     locale(c),
+		PhotoIDs:   req.PhotoIDs,
+	})
+	if err != nil {
+		respondAlbumTemplateError(c, err, i18n.CodeAlbumFromTemplateFailed)
+		return
+	}
+
+	policies := make([]gin.H, 0, len(result.Policies))
+	for _, policy := range result.Policies {
+		policies = append(policies, albumPolicyResponse(policy))
+	}
+	response := gin.H{
+		"album":    albumResponse(result.Album),
+		"created":  result.Created,
+		"added":    result.Added,
+		"policies": policies,
+	}
+	if result.Share != nil {
+		response["share"] = gin.H{
+			"token":    result.Share.Token,
+			"album_id": result.Share.AlbumID,
+			"url":      absoluteURL(c, "/s/"+result.Share.Token),
+		}
+	}
+	status := http.StatusCreated
+	if !result.Created {
+		status = http.StatusOK
+	}
+	c.JSON(status, gin.H{"data": response})
+}
+
+// respondAlbumTemplateError responde 404 para modelos inexistentes, 400 para dados rejeitados e 500 (com o
+// código informado) para os demais erros.
+func respondAlbumTemplateError(c *gin.Context, err error, code string) {
+	switch {
+	case errors.Is(err, service.ErrAlbumTemplateNotFound):
+		respondError(c, http.StatusNotFound, i18n.CodeAlbumTemplateNotFound)
+	case i18n.Code(err) == i18n.CodeAlbumTemplateExists:
+		respondServiceError(c, http.StatusConflict, err, code)
+	case i18n.Code(err) == i18n.CodePhotosNotFound:
+		respondServiceError(c, http.StatusNotFound, err, code)
+	case i18n.Code(err) != "":
+		respondServiceError(c, http.StatusBadRequest, err, code)
+	default:
+		respondErrorCause(c, http.StatusInternalServerError, code, err)
+	}
+}
+
+// albumTemplateResponse formata um modelo de álbum para a resposta da API.
+func albumTemplateResponse(template database.AlbumTemplate) gin.H {
+	policies := make([]gin.H, 0, len(template.Policies))
+	for _, policy := range template.Policies {
+		policies = append(policies, gin.H{
+			"name":             policy.Name,
+			"action":           policy.Action,
+			"older_than_days":  policy.OlderThanDays,
+			"screenshots_only": policy.ScreenshotsOnly,
+			"except_favorites": policy.ExceptFavorites,
+		})
+	}
+	return gin.H{
+		"id":                  template.ID,
+		"name":                template.Name,
+		"name_pattern":        template.NamePattern,
+		"description_pattern": template.DescriptionPattern,
+		"default_tags":        template.DefaultTags,
+		"pinned":              template.Pinned,
+		"share":               template.Share,
+		"soft_quota_bytes":    template.SoftQuotaBytes,
+		"policies":            policies,
+		"created_at":          template.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxBodySize limita o corpo de cada requisição: limit bytes por padrão e, nas rotas de routeLimits
+// (ex: "/upload"), o limite da rota. Requisições com Content-Length acima do limite recebem 413 antes de o
+// corpo ser lido; nas demais (ex: envio em partes), a leitura falha ao ultrapassar o limite, sem gravar o
+// restante em disco. Limite 0 desativa a verificação.
+func MaxBodySize(limit int64, routeLimits map[string]int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		max := limit
+		if routeLimit, ok := routeLimits[c.FullPath()]; ok {
+			max = routeLimit
+		}
+		if max <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > max {
+			// Sem ler o corpo, a conexão não pode ser reaproveitada
+			c.Header("Connection", "close")
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": message(c, i18n.CodeRequestTooLarge, max>>20),
+				"code":  i18n.CodeRequestTooLarge,
+			})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, max)
+		c.Next()
+	}
+}
+
+// bodyTooLarge indica se o erro veio da leitura de um corpo acima do limite de MaxBodySize, retornando o
+// limite ultrapassado.
+func bodyTooLarge(err error) (int64, bool) {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return maxErr.Limit, true
+	}
+	return 0, false
+}
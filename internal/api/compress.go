@@ -0,0 +1,210 @@
+package api
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Compress comprime as respostas textuais (JSON, NDJSON, HTML, CSS, JavaScript, SVG, XML) com gzip ou
+// deflate, conforme o Accept-Encoding do cliente. Imagens, vídeos, PDFs e ZIPs, que já são comprimidos,
+// passam intactos, assim como respostas parciais (Range) e as menores que minSize bytes. level vai de 1 (mais rápido) a 9 (menor resposta); 0 desativa a compressão.
+func Compress(level, minSize int) gin.HandlerFunc {
+	if level <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if level > gzip.BestCompression {
+		level = gzip.BestCompression
+	}
+
+	gzipPool := sync.Pool{New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(io.Discard, level)
+		return w
+	}}
+	flatePool := sync.Pool{New: func() interface{} {
+		w, _ := flate.NewWriter(io.Discard, level)
+		return w
+	}}
+
+	return func(c *gin.Context) {
+		encoding := acceptedEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: minSize}
+		w.newEncoder = func(dst io.Writer) compressEncoder {
+			if encoding == "gzip" {
+				enc := gzipPool.Get().(*gzip.Writer)
+				enc.Reset(dst)
+				return enc
+			}
+			enc := flatePool.Get().(*flate.Writer)
+			enc.Reset(dst)
+			return enc
+		}
+		c.Writer = w
+		defer func() {
+			w.finish()
+			switch enc := w.encoder.(type) {
+			case *gzip.Writer:
+				gzipPool.Put(enc)
+			case *flate.Writer:
+				flatePool.Put(enc)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// compressEncoder é o compressor em uso (gzip.Writer ou flate.Writer).
+type compressEncoder interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// compressWriter decide na primeira escrita, pelos cabeçalhos já definidos pelo handler, se a resposta
+// pode ser comprimida. Nesse caso, os primeiros bytes ficam em memória até somarem minSize: respostas
+// menores seguem sem compressão, pois o cabeçalho do gzip não compensaria.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding   string
+	minSize    int
+	decided    bool
+	candidate  bool   // Tipo de conteúdo compressível
+	pending    []byte // Início da resposta, aguardando atingir minSize
+	encoder    compressEncoder
+	newEncoder func(io.Writer) compressEncoder
+}
+
+// decide verifica uma única vez se a resposta é candidata à compressão.
+func (w *compressWriter) decide() {
+	if !w.decided {
+		w.decided = true
+		w.candidate = compressibleResponse(w.Status(), w.Header(), w.minSize)
+	}
+}
+
+// start ajusta os cabeçalhos, envia-os e passa a comprimir, começando pelos bytes retidos.
+func (w *compressWriter) start() error {
+	header := w.Header()
+	header.Set("Content-Encoding", w.encoding)
+	header.Add("Vary", "Accept-Encoding")
+	header.Del("Content-Length")
+	w.encoder = w.newEncoder(w.ResponseWriter)
+	w.ResponseWriter.WriteHeaderNow()
+
+	pending := w.pending
+	w.pending = nil
+	_, err := w.encoder.Write(pending)
+	return err
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	w.decide()
+	switch {
+	case !w.candidate:
+		return w.ResponseWriter.Write(data)
+	case w.encoder != nil:
+		return w.encoder.Write(data)
+	}
+
+	w.pending = append(w.pending, data...)
+	if len(w.pending) >= w.minSize {
+		if err := w.start(); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	w.decide()
+	if !w.candidate {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+	// Candidatas: o cabeçalho é enviado por start ou finish, quando se souber se haverá compressão
+}
+
+// Flush envia o que já foi escrito (ex: as linhas de uma listagem em NDJSON). Uma resposta enviada aos
+// poucos é comprimida mesmo antes de atingir minSize.
+func (w *compressWriter) Flush() {
+	w.decide()
+	if w.candidate && w.encoder == nil {
+		w.start()
+	}
+	if w.encoder != nil {
+		w.encoder.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// finish conclui a resposta: fecha o compressor ou, se a resposta ficou abaixo de minSize, envia os bytes
+// retidos sem compressão.
+func (w *compressWriter) finish() {
+	if w.encoder != nil {
+		w.encoder.Close()
+		return
+	}
+	if w.candidate {
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.ResponseWriter.WriteHeaderNow()
+		if len(w.pending) > 0 {
+			w.ResponseWriter.Write(w.pending)
+		}
+	}
+}
+
+// compressibleResponse indica se vale comprimir a resposta, pelo status e pelos cabeçalhos.
+func compressibleResponse(status int, header http.Header, minSize int) bool {
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified ||
+		status == http.StatusPartialContent || header.Get("Content-Range") != "" || header.Get("Content-Encoding") != "" {
+		return false
+	}
+	if length, err := strconv.Atoi(header.Get("Content-Length")); err == nil && length < minSize {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "json") || // application/json, application/x-ndjson, application/geo+json
+		strings.HasSuffix(mediaType, "xml") || // application/xml, image/svg+xml
+		mediaType == "application/javascript"
+}
+
+// acceptedEncoding escolhe gzip ou deflate a partir do Accept-Encoding (vazio se nenhum for aceito).
+func acceptedEncoding(header string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				continue
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	switch {
+	case accepted["gzip"] || accepted["*"]:
+		return "gzip"
+	case accepted["deflate"]:
+		return "deflate"
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+	"photo-manager/internal/config"
+	"photo-manager/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigHandler recarrega a configuração do servidor sob demanda.
+type ConfigHandler struct {
+	Reloader *config.Reloader
+}
+
+// NewConfigHandler cria uma nova instância de ConfigHandler.
+func NewConfigHandler(r *config.Reloader) *ConfigHandler {
+	return &ConfigHandler{
+		Reloader: r,
+	}
+}
+
+// ReloadHandler relê as variáveis de ambiente e o arquivo de configuração (POST /admin/config/reload), como
+// um SIGHUP. "applied" lista as configurações alteradas e já em vigor; "restart_required", as alteradas que
+// só valem após reiniciar o servidor. Uma configuração inválida responde 400 e a anterior continua valendo.
+func (h *ConfigHandler) ReloadHandler(c *gin.Context) {
+	result, err := h.Reloader.Reload()
+	if err != nil {
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeConfigReloadFailed, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"applied":          result.Applied,
+		"restart_required": result.RestartRequired,
+		"hot_reloadable":   config.HotReloadable,
+	}})
+}
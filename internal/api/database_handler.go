@@ -0,0 +1,163 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DatabaseHandler expõe a manutenção do banco: cópias de segurança, VACUUM, ANALYZE e estatísticas.
+type DatabaseHandler struct {
+	MaintenanceService *service.DatabaseMaintenanceService
+}
+
+// NewDatabaseHandler cria uma nova instância de DatabaseHandler.
+func NewDatabaseHandler(s *service.DatabaseMaintenanceService) *DatabaseHandler {
+	return &DatabaseHandler{
+		MaintenanceService: s,
+	}
+}
+
+// StatsHandler retorna o tamanho do banco, as páginas livres, as linhas de cada tabela, os índices (com as
+// estatísticas do ANALYZE e os indícios de índices inúteis) e a última cópia de segurança.
+func (h *DatabaseHandler) StatsHandler(c *gin.Context) {
+	stats, err := h.MaintenanceService.Stats(c.Request.Context())
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeDatabaseStatsFailed, err)
+		return
+	}
+
+	tables := make([]gin.H, 0, len(stats.Tables))
+	for _, table := range stats.Tables {
+		tables = append(tables, gin.H{"name": table.Name, "rows": table.Rows})
+	}
+	indexes := make([]gin.H, 0, len(stats.Indexes))
+	for _, index := range stats.Indexes {
+		indexes = append(indexes, gin.H{
+			"name":            index.Name,
+			"table":           index.Table,
+			"columns":         index.Columns,
+			"unique":          index.Unique,
+			"rows":            index.Rows,
+			"rows_per_key":    index.RowsPerKey,
+			"redundant_with":  index.Redundant,
+			"low_selectivity": index.LowSelectivity,
+		})
+	}
+	var lastBackup gin.H
+	if stats.LastBackup != nil {
+		lastBackup = backupResponse(stats.LastBackup)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"path":           stats.Path,
+		"size_bytes":     stats.SizeBytes,
+		"wal_size_bytes": stats.WALSizeBytes,
+		"sqlite_version": stats.SQLiteVersion,
+		"sqlite_driver":  stats.SQLiteDriver,
+		"journal_mode":   stats.JournalMode,
+		"page_size":      stats.PageSize,
+		"page_count":     stats.PageCount,
+		"free_pages":     stats.FreePages,
+		"free_bytes":     stats.FreeBytes,
+		"analyzed":       stats.Analyzed,
+		"tables":         tables,
+		"indexes":        indexes,
+		"last_backup":    lastBackup,
+	}})
+}
+
+// BackupHandler grava uma cópia consistente do banco com o servidor em uso (201 Created).
+func (h *DatabaseHandler) BackupHandler(c *gin.Context) {
+	backup, err := h.MaintenanceService.Backup(c.Request.Context())
+	if err != nil {
+		respondMaintenanceError(c, err, i18n.CodeDatabaseBackupFailed)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": backupResponse(backup)})
+}
+
+// ListBackupsHandler lista as cópias de segurança, da mais recente para a mais antiga.
+func (h *DatabaseHandler) ListBackupsHandler(c *gin.Context) {
+	backups, err := h.MaintenanceService.ListBackups()
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeDatabaseBackupFailed, err)
+		return
+	}
+	response := make([]gin.H, 0, len(backups))
+	for i := range backups {
+		response = append(response, backupResponse(&backups[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// DownloadBackupHandler envia o arquivo de uma cópia de segurança.
+func (h *DatabaseHandler) DownloadBackupHandler(c *gin.Context) {
+	name := c.Param("name")
+	path, err := h.MaintenanceService.BackupPath(name)
+	if err != nil {
+		respondError(c, http.StatusNotFound, i18n.CodeDatabaseBackupNotFound)
+		return
+	}
+	c.FileAttachment(path, name)
+}
+
+// VacuumHandler executa o VACUUM. As escritas ficam bloqueadas enquanto ele roda: prefira a janela de
+// manutenção (DB_MAINTENANCE_HOUR).
+func (h *DatabaseHandler) VacuumHandler(c *gin.Context) {
+	result, err := h.MaintenanceService.Vacuum(c.Request.Context())
+	if err != nil {
+		respondMaintenanceError(c, err, i18n.CodeDatabaseMaintenanceFailed)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maintenanceResponse(result)})
+}
+
+// AnalyzeHandler atualiza as estatísticas dos índices usadas pelo planejador de consultas.
+func (h *DatabaseHandler) AnalyzeHandler(c *gin.Context) {
+	result, err := h.MaintenanceService.Analyze(c.Request.Context())
+	if err != nil {
+		respondMaintenanceError(c, err, i18n.CodeDatabaseMaintenanceFailed)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": maintenanceResponse(result)})
+}
+
+// respondMaintenanceError responde 409 se outra manutenção estiver em andamento e 500 nas demais falhas.
+func respondMaintenanceError(c *gin.Context, err error, code string) {
+	if errors.Is(err, service.ErrMaintenanceRunning) {
+		respondServiceError(c, http.StatusConflict, err, code)
+		return
+	}
+	respondErrorCause(c, http.StatusInternalServerError, code, err)
+}
+
+// backupResponse formata uma cópia de segurança para a resposta da API.
+func backupResponse(backup *service.DatabaseBackup) gin.H {
+	response := gin.H{
+		"name":         backup.Name,
+		"size_bytes":   backup.SizeBytes,
+		"created_at":   backup.CreatedAt.Format(time.RFC3339),
+		"download_url": "/admin/database/backups/" + backup.Name,
+	}
+	if backup.Duration > 0 {
+		response["duration_ms"] = backup.Duration.Milliseconds()
+	}
+	return response
+}
+
+// maintenanceResponse formata o resultado de um VACUUM ou ANALYZE para a resposta da API.
+func maintenanceResponse(result *service.MaintenanceResult) gin.H {
+	return gin.H{
+		"operation":    result.Operation,
+		"duration_ms":  result.Duration.Milliseconds(),
+		"size_before":  result.SizeBefore,
+		"size_after":   result.SizeAfter,
+		"freed_bytes":  result.FreedBytes,
+		"completed_at": result.CompletedAt.Format(time.RFC3339),
+	}
+}
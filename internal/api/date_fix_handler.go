@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListDateFixesHandler é o primeiro passo do assistente de correção de datas (GET /photos/date-fixes): lista
+// as fotos com a data suspeita (reason: epoch, camera_default ou missing) e a data sugerida para cada uma, com
+// a fonte (basis: filename_date, filename_sequence ou import_order), a confiança e as fotos vizinhas usadas.
+// ?photo_ids= (repetido) limita a verificação a algumas fotos. Nada é alterado: as datas escolhidas são
+// confirmadas em POST /photos/date-fixes/confirm.
+func (h *PhotoHandler) ListDateFixesHandler(c *gin.Context) {
+	var query struct {
+		PhotoIDs []uint `form:"photo_ids"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	report, err := h.PhotoService.SuggestDateFixes(c.Request.Context(), query.PhotoIDs)
+	if err != nil {
+		if i18n.Code(err) == i18n.CodePhotosNotFound {
+			respondError(c, http.StatusNotFound, i18n.CodePhotosNotFound)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeDateFixFailed, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": dateFixesResponse(report)})
+}
+
+// ConfirmDateFixesHandler aplica as datas confirmadas (POST /photos/date-fixes/confirm, corpo {"fixes":
+// [{"photo_id": 12, "date": "2019-07-04T12:30:00-03:00", "time_zone_offset": -180}]}): normalmente as
+// sugeridas, revisadas pelo usuário. "time_zone_offset" (minutos) é o fuso da captura, como na sugestão; sem
+// ele, o fuso fica desconhecido. As datas anteriores ficam no histórico (GET /admin/metadata/changes), e
+// fotos bloqueadas não são alteradas.
+func (h *PhotoHandler) ConfirmDateFixesHandler(c *gin.Context) {
+	var req struct {
+		Fixes []struct {
+			PhotoID        uint      `json:"photo_id" binding:"required"`
+			Date           time.Time `json:"date" binding:"required"`
+			TimeZoneOffset *int      `json:"time_zone_offset"`
+		} `json:"fixes" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeDateFixInvalid)
+		return
+	}
+	fixes := make([]service.DateFix, 0, len(req.Fixes))
+	for _, fix := range req.Fixes {
+		fixes = append(fixes, service.DateFix{PhotoID: fix.PhotoID, Date: fix.Date, TimeZoneOffset: fix.TimeZoneOffset})
+	}
+
+	updated, locked, err := h.PhotoService.ApplyDateFixes(c.Request.Context(), fixes)
+	if err != nil {
+		switch i18n.Code(err) {
+		case i18n.CodePhotosNotFound:
+			respondError(c, http.StatusNotFound, i18n.CodePhotosNotFound)
+		case i18n.CodeDateFixInvalid:
+			respondError(c, http.StatusBadRequest, i18n.CodeDateFixInvalid)
+		default:
+			respondErrorCause(c, http.StatusInternalServerError, i18n.CodeDateFixFailed, err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"updated": updated, "locked": locked}})
+}
+
+// dateFixesResponse formata o relatório das datas suspeitas para a resposta da API. As datas sugeridas são
+// formatadas no fuso sugerido, se conhecido.
+func dateFixesResponse(report *service.DateFixReport) gin.H {
+	photos := make([]gin.H, 0, len(report.Photos))
+	for _, suggestion := range report.Photos {
+		item := gin.H{
+			"photo_id":    suggestion.PhotoID,
+			"filename":    suggestion.Filename,
+			"reason":      suggestion.Reason,
+			"upload_date": suggestion.UploadDate.Format(time.RFC3339),
+			"locked":      suggestion.Locked,
+		}
+		if suggestion.CurrentDate != nil {
+			item["current_date"] = suggestion.CurrentDate.Format(time.RFC3339)
+		}
+		if suggestion.SuggestedDate != nil {
+			suggested := *suggestion.SuggestedDate
+			if suggestion.TimeZoneOffset != nil {
+				suggested = suggested.In(time.FixedZone("", *suggestion.TimeZoneOffset*60))
+				item["time_zone_offset"] = *suggestion.TimeZoneOffset
+			}
+			item["suggested_date"] = suggested.Format(time.RFC3339)
+			item["basis"] = suggestion.Basis
+			item["confidence"] = suggestion.Confidence
+		}
+		if suggestion.PreviousID != 0 {
+			item["previous_photo_id"] = suggestion.PreviousID
+		}
+		if suggestion.NextID != 0 {
+			item["next_photo_id"] = suggestion.NextID
+		}
+		photos = append(photos, item)
+	}
+	return gin.H{
+		"checked":   report.Checked,
+		"flagged":   report.Flagged,
+		"suggested": report.Suggested,
+		"photos":    photos,
+		"truncated": report.Truncated,
+	}
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DownloadHandler gerencia o download dos originais de uma seleção de fotos em um ZIP.
+type DownloadHandler struct {
+	DownloadService *service.DownloadService
+	PhotoService    *service.PhotoService
+}
+
+// NewDownloadHandler cria uma nova instância de DownloadHandler.
+func NewDownloadHandler(ds *service.DownloadService, ps *service.PhotoService) *DownloadHandler {
+	return &DownloadHandler{
+		DownloadService: ds,
+		PhotoService:    ps,
+	}
+}
+
+// EstimateDownloadHandler informa o tamanho do ZIP de uma seleção antes de gerá-lo (POST /downloads/estimate).
+// A seleção é {"photo_ids": [1, 2, 3]} ou, sem corpo, o filtro da query string (mesmos parâmetros de
+// GET /photos, ex: ?album_id=4 ou ?year=2023&tag=praia). "prepare" indica que a seleção passa do limite e
+// será preparada em background por POST /downloads.
+func (h *DownloadHandler) EstimateDownloadHandler(c *gin.Context) {
+	photoIDs, ok := h.selection(c)
+	if !ok {
+		return
+	}
+
+	estimate, err := h.DownloadService.EstimateDownload(c.Request.Context(), photoIDs)
+	if err != nil {
+		respondDownloadError(c, err, i18n.CodeDownloadEstimateFailed)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"file_count":      estimate.Files,
+		"missing_files":   estimate.Missing,
+		"total_bytes":     estimate.TotalBytes,
+		"archive_bytes":   estimate.ArchiveBytes,
+		"prepare":         estimate.Prepare,
+		"threshold_bytes": h.DownloadService.PrepareThreshold,
+	}})
+}
+
+// StartDownloadHandler baixa os originais da seleção em um ZIP (POST /downloads, mesma seleção de
+// POST /downloads/estimate). Seleções até o limite são enviadas diretamente; as maiores, ou com
+// ?prepare=true, são preparadas em background: a resposta é 202 com o job, e o evento download.ready avisa
+// quando o arquivo pode ser baixado em GET /downloads/:id/download.
+func (h *DownloadHandler) StartDownloadHandler(c *gin.Context) {
+	var query struct {
+		Prepare bool `form:"prepare"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+	photoIDs, ok := h.selection(c)
+	if !ok {
+		return
+	}
+	h.sendDownload(c, photoIDs, query.Prepare)
+}
+
+// sendDownload envia o ZIP das fotos ou, acima do limite ou com prepare, inicia a sua preparação em
+// background e responde 202 com o job.
+func (h *DownloadHandler) sendDownload(c *gin.Context, photoIDs []uint, prepare bool) {
+	estimate, err := h.DownloadService.EstimateDownload(c.Request.Context(), photoIDs)
+	if err != nil {
+		respondDownloadError(c, err, i18n.CodeDownloadStartFailed)
+		return
+	}
+
+	if estimate.Prepare || prepare {
+		job, err := h.DownloadService.PrepareDownload(photoIDs, estimate)
+		if err != nil {
+			respondErrorCause(c, http.StatusInternalServerError, i18n.CodeDownloadStartFailed, err)
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"data": downloadJobResponse(job)})
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"photo-manager-%s.zip\"", time.Now().Format("2006-01-02")))
+
+	// O ZIP é gerado diretamente na resposta; após o início do envio, erros só podem ser registrados no log
+	archived, skipped, err := h.DownloadService.WriteDownload(c.Request.Context(), c.Writer, photoIDs)
+	if err != nil {
+		log.Printf("Erro ao gerar o download de %d foto(s): %v\n", len(photoIDs), err)
+		return
+	}
+	log.Printf("Download de seleção enviado: %d foto(s), %d ignorada(s)\n", archived, skipped)
+}
+
+// GetDownloadHandler retorna o status e o progresso da preparação de um download.
+func (h *DownloadHandler) GetDownloadHandler(c *gin.Context) {
+	job, ok := h.loadDownload(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": downloadJobResponse(job)})
+}
+
+// DownloadArchiveHandler envia o ZIP preparado de um download concluído.
+func (h *DownloadHandler) DownloadArchiveHandler(c *gin.Context) {
+	job, ok := h.loadDownload(c)
+	if !ok {
+		return
+	}
+	if job.Status != database.ExportStatusCompleted {
+		respondError(c, http.StatusConflict, i18n.CodeDownloadNotReady)
+		return
+	}
+	if _, err := os.Stat(job.ArchivePath); job.ArchivePath == "" || err != nil {
+		respondError(c, http.StatusGone, i18n.CodeDownloadArchiveMissing)
+		return
+	}
+
+	c.FileAttachment(job.ArchivePath, fmt.Sprintf("photo-manager-%s-%d.zip", job.CreatedAt.Format("2006-01-02"), job.ID))
+}
+
+// selection lê as fotos selecionadas: "photo_ids" do corpo JSON ou, sem corpo, as fotos do filtro da
+// query string.
+func (h *DownloadHandler) selection(c *gin.Context) ([]uint, bool) {
+	return bindPhotoSelection(c, h.PhotoService, i18n.CodeDownloadSelectionEmpty, i18n.CodeDownloadEstimateFailed)
+}
+
+// bindPhotoSelection lê uma seleção de fotos: {"photo_ids": [1, 2, 3]} no corpo ou, sem corpo, as fotos do
+// filtro da query string (mesmos parâmetros de GET /photos). invalidCode responde um corpo inválido, e
+// filterCode uma falha ao aplicar o filtro.
+func bindPhotoSelection(c *gin.Context, photos *service.PhotoService, invalidCode, filterCode string) ([]uint, bool) {
+	var req struct {
+		PhotoIDs []uint `json:"photo_ids"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, invalidCode)
+			return nil, false
+		}
+	}
+	if len(req.PhotoIDs) > 0 {
+		return req.PhotoIDs, true
+	}
+
+	filter, ok := parsePhotoFilter(c)
+	if !ok {
+		return nil, false
+	}
+	photoIDs, err := photos.GetPhotoIDs(c.Request.Context(), filter)
+	if err != nil {
+		respondErrorCause(c, http.StatusBadRequest, filterCode, err)
+		return nil, false
+	}
+	return photoIDs, true
+}
+
+// loadDownload busca o download indicado pelo parâmetro :id, respondendo o erro adequado se não existir.
+func (h *DownloadHandler) loadDownload(c *gin.Context) (*database.DownloadJob, bool) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidDownloadID)
+	if !ok {
+		return nil, false
+	}
+
+	job, err := h.DownloadService.GetDownloadJob(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodeDownloadNotFound)
+			return nil, false
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeDownloadFetchFailed, err)
+		return nil, false
+	}
+	return job, true
+}
+
+// respondDownloadError responde 400 para seleções vazias, 404 para fotos inexistentes e 500 (com o código
+// informado) para os demais erros.
+func respondDownloadError(c *gin.Context, err error, code string) {
+	switch {
+	case errors.Is(err, service.ErrDownloadSelectionEmpty):
+		respondError(c, http.StatusBadRequest, i18n.CodeDownloadSelectionEmpty)
+	case i18n.Code(err) == i18n.CodePhotosNotFound:
+		respondServiceError(c, http.StatusNotFound, err, code)
+	default:
+		respondErrorCause(c, http.StatusInternalServerError, code, err)
+	}
+}
+
+// downloadJobResponse formata um DownloadJob para a resposta da API.
+func downloadJobResponse(job *database.DownloadJob) gin.H {
+	formatTime := func(t *time.Time) string {
+		if t != nil {
+			return t.Format(time.RFC3339)
+		}
+		return ""
+	}
+
+	downloadURL := ""
+	if job.Status == database.ExportStatusCompleted && job.ArchivePath != "" {
+		downloadURL = fmt.Sprintf("/downloads/%d/download", job.ID)
+	}
+
+	return gin.H{
+		"id":              job.ID,
+		"status":          job.Status,
+		"total_photos":    job.TotalPhotos,
+		"archived_photos": job.ArchivedPhotos,
+		"skipped_photos":  job.SkippedPhotos,
+		"estimated_bytes": job.EstimatedBytes,
+		"size_bytes":      job.SizeBytes,
+		"last_error":      job.LastError,
+		"download_url":    downloadURL,
+		"started_at":      formatTime(job.StartedAt),
+		"finished_at":     formatTime(job.FinishedAt),
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"net/http"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DuplicateHandler expõe o relatório de duplicatas da biblioteca e a remoção das cópias redundantes.
+type DuplicateHandler struct {
+	DuplicateService *service.DuplicateService
+}
+
+// NewDuplicateHandler cria uma nova instância de DuplicateHandler.
+func NewDuplicateHandler(s *service.DuplicateService) *DuplicateHandler {
+	return &DuplicateHandler{
+		DuplicateService: s,
+	}
+}
+
+// ListDuplicatesHandler retorna os grupos de fotos duplicadas e o espaço recuperável, sem remover nada.
+// ?policy= escolhe a foto mantida (keep-oldest, keep-largest ou keep-raw; padrão: keep-oldest),
+// ?threshold= a distância máxima entre hashes perceptuais (0 a 16, padrão: 4) e ?near=false
+// restringe o relatório às fotos idênticas pixel a pixel.
+func (h *DuplicateHandler) ListDuplicatesHandler(c *gin.Context) {
+	opts, ok := parseDuplicateOptions(c, c.Query("policy"), c.Query("threshold"), c.Query("near"))
+	if !ok {
+		return
+	}
+
+	report, err := h.DuplicateService.FindDuplicates(c.Request.Context(), opts)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeDuplicatesFailed, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": duplicateReportResponse(report)})
+}
+
+// ReclaimDuplicatesHandler remove as cópias redundantes de cada grupo. Por segurança, o padrão é a
+// simulação: o corpo {"policy", "threshold", "near", "dry_run"} só remove fotos com "dry_run": false.
+// A simulação grava o plano de ação (em "plan"); revise-o em GET /admin/plans/:id e execute exatamente
+// ele com POST /admin/plans/:id/confirm.
+func (h *DuplicateHandler) ReclaimDuplicatesHandler(c *gin.Context) {
+	var req struct {
+		Policy    string `json:"policy"`
+		Threshold *int   `json:"threshold"`
+		Near      *bool  `json:"near"`
+		DryRun    *bool  `json:"dry_run"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, i18n.CodeInvalidRequestBody)
+			return
+		}
+	}
+
+	threshold, near := "", ""
+	if req.Threshold != nil {
+		threshold = strconv.Itoa(*req.Threshold)
+	}
+	if req.Near != nil {
+		near = strconv.FormatBool(*req.Near)
+	}
+	opts, ok := parseDuplicateOptions(c, req.Policy, threshold, near)
+	if !ok {
+		return
+	}
+	dryRun := req.DryRun == nil || *req.DryRun
+
+	if dryRun {
+		report, plan, err := h.DuplicateService.PlanReclaim(c.Request.Context(), opts)
+		if err != nil {
+			respondErrorCause(c, http.StatusInternalServerError, i18n.CodeDuplicatesReclaimFailed, err)
+			return
+		}
+		response := duplicateReportResponse(report)
+		response["plan"] = adminPlanResponse(*plan)
+		c.JSON(http.StatusOK, gin.H{"data": response})
+		return
+	}
+
+	report, err := h.DuplicateService.Reclaim(c.Request.Context(), opts, false)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeDuplicatesReclaimFailed, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": duplicateReportResponse(report)})
+}
+
+// parseDuplicateOptions valida os parâmetros de detecção, respondendo 400 se algum for inválido.
+func parseDuplicateOptions(c *gin.Context, policy, threshold, near string) (service.DuplicateOptions, bool) {
+	opts := service.DuplicateOptions{
+		Policy:      service.DedupeKeepOldest,
+		Threshold:   service.DefaultNearDuplicateThreshold,
+		IncludeNear: true,
+	}
+	if policy != "" {
+		opts.Policy = policy
+	}
+	if !service.ValidDedupePolicy(opts.Policy) {
+		respondError(c, http.StatusBadRequest, i18n.CodeDuplicatePolicyInvalid,
+			opts.Policy, service.DedupeKeepOldest, service.DedupeKeepLargest, service.DedupeKeepRaw)
+		return opts, false
+	}
+	if threshold != "" {
+		n, err := strconv.Atoi(threshold)
+		if err != nil || n < 0 || n > service.MaxNearDuplicateThreshold {
+			respondError(c, http.StatusBadRequest, i18n.CodeDuplicateThreshold, service.MaxNearDuplicateThreshold)
+			return opts, false
+		}
+		opts.Threshold = n
+	}
+	if near != "" {
+		include, err := strconv.ParseBool(near)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, i18n.CodeDuplicateNearInvalid)
+			return opts, false
+		}
+		opts.IncludeNear = include
+	}
+	return opts, true
+}
+
+// duplicateReportResponse formata o relatório de duplicatas para a resposta da API.
+func duplicateReportResponse(report *service.DuplicateReport) gin.H {
+	groups := []gin.H{}
+	for _, group := range report.Groups {
+		groups = append(groups, gin.H{
+			"kind":              group.Kind,
+			"photo_ids":         group.PhotoIDs,
+			"keep_id":           group.KeepID,
+			"remove_ids":        group.RemoveIDs,
+			"locked_ids":        group.LockedIDs,
+			"reclaimable_bytes": group.ReclaimableBytes,
+		})
+	}
+	return gin.H{
+		"policy":            report.Policy,
+		"dry_run":           report.DryRun,
+		"group_count":       len(report.Groups),
+		"groups":            groups,
+		"reclaimable_bytes": report.ReclaimableBytes,
+		"fingerprinted":     report.Fingerprinted,
+		"unreadable":        report.Unreadable,
+		"removed":           report.Removed,
+		"failed":            report.Failed,
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"net/http"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExifReconcileHandler gerencia a reconciliação dos metadados com o EXIF dos arquivos e o histórico de
+// alterações.
+type ExifReconcileHandler struct {
+	Reconcile *service.ExifReconcileService
+}
+
+// NewExifReconcileHandler cria uma nova instância de ExifReconcileHandler.
+func NewExifReconcileHandler(s *service.ExifReconcileService) *ExifReconcileHandler {
+	return &ExifReconcileHandler{
+		Reconcile: s,
+	}
+}
+
+// ReconcileHandler relê o EXIF dos arquivos armazenados e compara com o banco:
+// {"policy": "file", "fields": ["exif_date"], "photo_ids": [1, 2]}. A política decide qual lado prevalece:
+// "file" (o arquivo, sempre que tiver o campo), "fill" (o arquivo só preenche campos vazios) ou "newer" (o
+// arquivo, se modificado depois da última alteração da foto). Por segurança, o padrão é a simulação, que
+// lista as divergências sem gravar nada; elas só são aplicadas, e registradas no histórico, com
+// "dry_run": false.
+func (h *ExifReconcileHandler) ReconcileHandler(c *gin.Context) {
+	var req struct {
+		Policy   string   `json:"policy"`
+		Fields   []string `json:"fields"`
+		PhotoIDs []uint   `json:"photo_ids"`
+		DryRun   *bool    `json:"dry_run"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeInvalidRequestBody, err)
+		return
+	}
+
+	report, err := h.Reconcile.Reconcile(c.Request.Context(), service.ReconcileOptions{
+		Policy:   req.Policy,
+		Fields:   req.Fields,
+		PhotoIDs: req.PhotoIDs,
+		DryRun:   req.DryRun == nil || *req.DryRun,
+	})
+	if err != nil {
+		if i18n.Code(err) == i18n.CodeReconcileInvalid {
+			respondServiceError(c, http.StatusBadRequest, err, i18n.CodeReconcileInvalid)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeReconcileFailed, err)
+		return
+	}
+
+	photos := make([]gin.H, 0, len(report.Photos))
+	for _, photo := range report.Photos {
+		differences := make([]gin.H, 0, len(photo.Differences))
+		for _, difference := range photo.Differences {
+			item := gin.H{
+				"field":    difference.Field,
+				"database": difference.Database,
+				"file":     difference.File,
+				"apply":    difference.Apply,
+			}
+			if difference.Kept != "" {
+				item["kept"] = difference.Kept
+			}
+			differences = append(differences, item)
+		}
+		photos = append(photos, gin.H{"photo_id": photo.PhotoID, "filename": photo.Filename, "differences": differences})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"dry_run":        report.DryRun,
+		"policy":         report.Policy,
+		"scanned":        report.Scanned,
+		"differing":      report.Differing,
+		"updated":        report.Updated,
+		"fields_changed": report.FieldsChanged,
+		"missing":        report.Missing,
+		"unreadable":     report.Unreadable,
+		"photos":         photos,
+		"truncated":      report.Truncated,
+	}})
+}
+
+// ListChangesHandler lista o histórico de alterações de metadados (?photo_id= filtra por foto; ?limit=,
+// padrão 100, máximo 1000), das mais recentes para as mais antigas.
+func (h *ExifReconcileHandler) ListChangesHandler(c *gin.Context) {
+	var query struct {
+		PhotoID uint `form:"photo_id"`
+		Limit   int  `form:"limit"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+	if query.Limit <= 0 {
+		query.Limit = 100
+	}
+	query.Limit = min(query.Limit, 1000)
+
+	changes, err := h.Reconcile.ListChanges(query.PhotoID, query.Limit)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeMetadataChangesFailed, err)
+		return
+	}
+	response := make([]gin.H, 0, len(changes))
+	for _, change := range changes {
+		response = append(response, gin.H{
+			"id":         change.ID,
+			"photo_id":   change.PhotoID,
+			"field":      change.Field,
+			"old_value":  change.OldValue,
+			"new_value":  change.NewValue,
+			"source":     change.Source,
+			"policy":     change.Policy,
+			"applied_at": change.AppliedAt.Format(time.RFC3339),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
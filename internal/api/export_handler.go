@@ -0,0 +1,125 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ExportHandler gerencia as requisições HTTP da exportação completa da biblioteca.
+type ExportHandler struct {
+	ExportService *service.ExportService
+}
+
+// NewExportHandler cria uma nova instância de ExportHandler.
+func NewExportHandler(s *service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		ExportService: s,
+	}
+}
+
+// StartExportHandler inicia a exportação completa da biblioteca: um ZIP com os originais e o manifesto
+// metadata.json (fotos, álbuns, tags e compartilhamentos). Responde 202 enquanto a exportação é gerada e
+// 200 quando a última exportação concluída ainda está disponível; ?new=true força uma nova exportação.
+func (h *ExportHandler) StartExportHandler(c *gin.Context) {
+	var query struct {
+		New bool `form:"new"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	job, _, err := h.ExportService.StartExport(query.New)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeExportStartFailed, err)
+		return
+	}
+
+	status := http.StatusAccepted
+	if job.Status == database.ExportStatusCompleted {
+		status = http.StatusOK
+	}
+	c.JSON(status, gin.H{"data": exportJobResponse(job)})
+}
+
+// GetExportHandler retorna o status e o progresso de uma exportação.
+func (h *ExportHandler) GetExportHandler(c *gin.Context) {
+	job, ok := h.loadExport(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": exportJobResponse(job)})
+}
+
+// DownloadExportHandler envia o arquivo ZIP de uma exportação concluída.
+func (h *ExportHandler) DownloadExportHandler(c *gin.Context) {
+	job, ok := h.loadExport(c)
+	if !ok {
+		return
+	}
+	if job.Status != database.ExportStatusCompleted {
+		respondError(c, http.StatusConflict, i18n.CodeExportNotReady)
+		return
+	}
+	if _, err := os.Stat(job.ArchivePath); job.ArchivePath == "" || err != nil {
+		respondError(c, http.StatusGone, i18n.CodeExportArchiveMissing)
+		return
+	}
+
+	c.FileAttachment(job.ArchivePath, fmt.Sprintf("photo-manager-export-%s.zip", job.CreatedAt.Format("2006-01-02")))
+}
+
+// loadExport busca a exportação indicada pelo parâmetro :id, respondendo o erro adequado se não existir.
+func (h *ExportHandler) loadExport(c *gin.Context) (*database.ExportJob, bool) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidExportID)
+	if !ok {
+		return nil, false
+	}
+
+	job, err := h.ExportService.GetExportJob(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodeExportNotFound)
+			return nil, false
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeExportFetchFailed, err)
+		return nil, false
+	}
+	return job, true
+}
+
+// exportJobResponse formata um ExportJob para a resposta da API.
+func exportJobResponse(job *database.ExportJob) gin.H {
+	formatTime := func(t *time.Time) string {
+		if t != nil {
+			return t.Format(time.RFC3339)
+		}
+		return ""
+	}
+
+	downloadURL := ""
+	if job.Status == database.ExportStatusCompleted && job.ArchivePath != "" {
+		downloadURL = fmt.Sprintf("/me/export/%d/download", job.ID)
+	}
+
+	return gin.H{
+		"id":              job.ID,
+		"status":          job.Status,
+		"total_photos":    job.TotalPhotos,
+		"exported_photos": job.ExportedPhotos,
+		"skipped_photos":  job.SkippedPhotos,
+		"size_bytes":      job.SizeBytes,
+		"last_error":      job.LastError,
+		"download_url":    downloadURL,
+		"started_at":      formatTime(job.StartedAt),
+		"finished_at":     formatTime(job.FinishedAt),
+	}
+}
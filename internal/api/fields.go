@@ -0,0 +1,107 @@
+package api
+
+import (
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fieldSet é a seleção de campos pedida em ?fields= (sparse fieldsets, no estilo do JSON:API), usada para
+// reduzir o tamanho das respostas em clientes com banda ou memória limitadas. nil representa todos os campos.
+type fieldSet map[string]bool
+
+// parseFieldSet lê ?fields=id,filename,... validando os nomes contra os campos de sample (uma resposta
+// completa do serializador). "id" é sempre incluído. Responde 422 e retorna false para campos desconhecidos.
+func parseFieldSet(c *gin.Context, sample gin.H) (fieldSet, bool) {
+	raw := strings.TrimSpace(c.Query("fields"))
+	if raw == "" {
+		return nil, true
+	}
+
+	fields := fieldSet{"id": true}
+	var unknown []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := sample[name]; !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		fields[name] = true
+	}
+	if len(unknown) > 0 {
+		available := make([]string, 0, len(sample))
+		for name := range sample {
+			available = append(available, name)
+		}
+		sort.Strings(available)
+		respondInvalidParam(c, "fields", message(c, i18n.CodeFieldsUnknown, strings.Join(unknown, ", "), strings.Join(available, ", ")))
+		return nil, false
+	}
+	return fields, true
+}
+
+// pick retorna apenas os campos selecionados de response (ou response inteira se não houver seleção).
+func (f fieldSet) pick(response gin.H) gin.H {
+	if f == nil {
+		return response
+	}
+	picked := make(gin.H, len(f))
+	for name := range f {
+		if value, ok := response[name]; ok {
+			picked[name] = value
+		}
+	}
+	return picked
+}
+
+// photoSerializer formata uma foto de uma listagem.
+type photoSerializer func(database.Photo) gin.H
+
+// photoFieldsSerializer lê ?fields= das listagens de fotos: vazio ou "full" usa photoResponse, "minimal"
+// usa photoMinimalResponse (id, miniatura, data e dimensões) e uma lista de nomes, ex:
+// ?fields=id,filename,thumbnail_url,exif_date, seleciona os campos de ambas as respostas.
+func photoFieldsSerializer(c *gin.Context) (photoSerializer, bool) {
+	switch c.Query("fields") {
+	case "", "full":
+		return photoResponse, true
+	case "minimal":
+		return photoMinimalResponse, true
+	}
+
+	fields, ok := parseFieldSet(c, photoAllFields(database.Photo{}))
+	if !ok {
+		return nil, false
+	}
+	return func(photo database.Photo) gin.H {
+		return fields.pick(photoAllFields(photo))
+	}, true
+}
+
+// photoAllFields reúne os campos de photoResponse e de photoMinimalResponse (ex: "date").
+func photoAllFields(photo database.Photo) gin.H {
+	response := photoResponse(photo)
+	for name, value := range photoMinimalResponse(photo) {
+		if _, ok := response[name]; !ok {
+			response[name] = value
+		}
+	}
+	return response
+}
+
+// albumFieldsSerializer lê ?fields= da listagem de álbuns, ex: ?fields=id,name,photo_count.
+func albumFieldsSerializer(c *gin.Context) (func(service.AlbumSummary) gin.H, bool) {
+	fields, ok := parseFieldSet(c, albumResponse(service.AlbumSummary{}))
+	if !ok {
+		return nil, false
+	}
+	return func(album service.AlbumSummary) gin.H {
+		return fields.pick(albumResponse(album))
+	}, true
+}
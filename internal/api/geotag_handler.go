@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http"
+	"photo-manager/internal/geotrack"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GeotagFromTrackHandler geolocaliza fotos de câmeras sem GPS pela trilha gravada por outro aparelho
+// (POST /photos/geotag-from-track, multipart): o arquivo GPX ou KML vai no campo "track", e as fotos em
+// "photo_ids" (repetido) ou, sem ele, pelo filtro da query string (mesmos parâmetros de GET /photos, ex:
+// ?year=2023&month=7). O horário de cada foto é correlacionado com os pontos da trilha, interpolando entre
+// dois pontos próximos. Campos opcionais:
+//   - "time_zone": fuso em que o relógio da câmera estava acertado (ex: Europe/Lisbon ou +01:00), para câmeras
+//     que não gravam o fuso;
+//   - "clock_offset": correção do relógio da câmera em segundos (ex: 120 para uma câmera 2 minutos atrasada);
+//   - "max_gap_minutes": maior intervalo entre pontos, ou até o ponto mais próximo, para aceitar uma posição
+//     (padrão: 10);
+//   - "overwrite": "true" substitui a localização das fotos que já têm uma.
+//
+// Como na substituição de textos, o padrão é a prévia, que responde a posição de cada foto sem gravar nada; a
+// localização só é gravada com "dry_run" igual a "false".
+func (h *PhotoHandler) GeotagFromTrackHandler(c *gin.Context) {
+	form, err := c.MultipartForm()
+	if limit, tooLarge := bodyTooLarge(err); tooLarge {
+		respondError(c, http.StatusRequestEntityTooLarge, i18n.CodeRequestTooLarge, limit>>20)
+		return
+	}
+	if err != nil {
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeMultipartInvalid, err)
+		return
+	}
+	files := form.File["track"]
+	if len(files) != 1 {
+		respondError(c, http.StatusBadRequest, i18n.CodeGeotagTrackRequired)
+		return
+	}
+
+	var req struct {
+		PhotoIDs      []uint `form:"photo_ids"`
+		TimeZone      string `form:"time_zone"`
+		ClockOffset   int    `form:"clock_offset"`
+		MaxGapMinutes *int   `form:"max_gap_minutes" binding:"omitempty,min=1,max=1440"`
+		Overwrite     bool   `form:"overwrite"`
+		DryRun        *bool  `form:"dry_run"`
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeGeotagOptionsInvalid)
+		return
+	}
+	cameraZone, err := service.ParseCameraZone(req.TimeZone)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeGeotagTimeZoneInvalid)
+		return
+	}
+	photoIDs := req.PhotoIDs
+	if len(photoIDs) == 0 {
+		filter, ok := parsePhotoFilter(c)
+		if !ok {
+			return
+		}
+		if photoIDs, err = h.PhotoService.GetPhotoIDs(c.Request.Context(), filter); err != nil {
+			respondErrorCause(c, http.StatusBadRequest, i18n.CodeGeotagFailed, err)
+			return
+		}
+	}
+
+	file, err := files[0].Open()
+	if err != nil {
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeMultipartInvalid, err)
+		return
+	}
+	defer file.Close()
+	track, err := geotrack.Parse(file)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeGeotagTrackInvalid, err.Error())
+		return
+	}
+
+	opts := service.GeotagOptions{
+		CameraZone:  cameraZone,
+		ClockOffset: time.Duration(req.ClockOffset) * time.Second,
+		MaxGap:      10 * time.Minute,
+		Overwrite:   req.Overwrite,
+		DryRun:      req.DryRun == nil || *req.DryRun,
+	}
+	if req.MaxGapMinutes != nil {
+		opts.MaxGap = time.Duration(*req.MaxGapMinutes) * time.Minute
+	}
+	report, err := h.PhotoService.GeotagFromTrack(c.Request.Context(), track, photoIDs, opts)
+	if err != nil {
+		if i18n.Code(err) == i18n.CodePhotosNotFound {
+			respondError(c, http.StatusNotFound, i18n.CodePhotosNotFound)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeGeotagFailed, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": geotagResponse(report)})
+}
+
+// geotagResponse formata o relatório da geolocalização para a resposta da API.
+func geotagResponse(report *service.GeotagReport) gin.H {
+	photos := []gin.H{}
+	for _, match := range report.Photos {
+		item := gin.H{"photo_id": match.PhotoID, "latitude": match.Latitude, "longitude": match.Longitude}
+		if match.CaptureTime != nil {
+			item["capture_time"] = match.CaptureTime.Format(time.RFC3339)
+		}
+		if match.Skipped != "" {
+			item["skipped"] = match.Skipped
+		}
+		photos = append(photos, item)
+	}
+	return gin.H{
+		"dry_run": report.DryRun,
+		"track": gin.H{
+			"points": report.TrackPoints,
+			"start":  report.TrackStart.Format(time.RFC3339),
+			"end":    report.TrackEnd.Format(time.RFC3339),
+		},
+		"photos":    photos,
+		"matched":   report.Matched,
+		"skipped":   report.Skipped,
+		"truncated": report.Truncated,
+	}
+}
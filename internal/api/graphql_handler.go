@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"photo-manager/internal/graph"
+	"photo-manager/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GraphQLHandler gerencia as requisições HTTP da API GraphQL.
+type GraphQLHandler struct {
+	Server *graph.Server
+}
+
+// NewGraphQLHandler cria uma nova instância de GraphQLHandler.
+func NewGraphQLHandler(s *graph.Server) *GraphQLHandler {
+	return &GraphQLHandler{
+		Server: s,
+	}
+}
+
+// graphQLRequest é o corpo de uma requisição GraphQL sobre HTTP.
+type graphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// QueryHandler executa uma consulta GraphQL. Erros da consulta são retornados no campo "errors"
+// da resposta, conforme a especificação, com status 200.
+func (h *GraphQLHandler) QueryHandler(c *gin.Context) {
+	var req graphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeGraphQLQueryEmpty)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.Server.Exec(c.Request.Context(), req.Query, req.OperationName, req.Variables))
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"photo-manager/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+const localeKey = "photo_manager:locale"
+
+// Locale escolhe o idioma das mensagens da API (português ou inglês) pelo cabeçalho Accept-Language
+// e o informa na resposta em Content-Language.
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		c.Set(localeKey, locale)
+		c.Header("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// locale retorna o idioma da requisição.
+func locale(c *gin.Context) string {
+	if value, ok := c.Get(localeKey); ok {
+		return value.(string)
+	}
+	return i18n.Negotiate(c.GetHeader("Accept-Language"))
+}
+
+// message retorna a mensagem do código no idioma da requisição.
+func message(c *gin.Context, code string, args ...interface{}) string {
+	return i18n.Message(locale(c), code, args...)
+}
+
+// respondError responde {"error": mensagem, "code": código} com a mensagem no idioma da requisição (e
+// "trace_id", com o tracing ativo).
+func respondError(c *gin.Context, status int, code string, args ...interface{}) {
+	c.JSON(status, errorBody(c, message(c, code, args...), code))
+}
+
+// respondErrorCause responde como respondError, acrescentando à mensagem a causa (traduzida, se possível).
+func respondErrorCause(c *gin.Context, status int, code string, err error) {
+	c.JSON(status, errorBody(c, message(c, code)+": "+i18n.Localize(err, locale(c)), code))
+}
+
+// respondServiceError responde com a mensagem do próprio erro, traduzida quando o serviço a identificou com
+// um código (ex: validações); fallbackCode é usado para erros sem código.
+func respondServiceError(c *gin.Context, status int, err error, fallbackCode string) {
+	code := i18n.Code(err)
+	if code == "" {
+		code = fallbackCode
+	}
+	c.JSON(status, errorBody(c, i18n.Localize(err, locale(c)), code))
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ImportHandler gerencia as requisições HTTP para jobs de importação.
+type ImportHandler struct {
+	ImportService *service.ImportService
+}
+
+// NewImportHandler cria uma nova instância de ImportHandler.
+func NewImportHandler(s *service.ImportService) *ImportHandler {
+	return &ImportHandler{
+		ImportService: s,
+	}
+}
+
+// CreateImportHandler inicia a importação de um diretório local do servidor.
+// Com "in_place": true as fotos são apenas indexadas; repetir a importação do mesmo diretório reindexa-o.
+// "format" importa uma biblioteca do PhotoPrism (diretório com originals/ e storage/) ou do Immich
+// (diretório dos originais e "manifest_path" opcional), preservando álbuns, favoritos e tags.
+func (h *ImportHandler) CreateImportHandler(c *gin.Context) {
+	var req struct {
+		SourcePath   string `json:"source_path" binding:"required"`
+		InPlace      bool   `json:"in_place"`      // Indexa no local, sem copiar para o armazenamento gerenciado
+		Format       string `json:"format"`        // photoprism ou immich; vazio para um diretório comum
+		ManifestPath string `json:"manifest_path"` // JSON com álbuns e metadados da API do Immich
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldRequired, "source_path")
+		return
+	}
+
+	job, err := h.ImportService.StartImport(req.SourcePath, req.InPlace, req.Format, req.ManifestPath)
+	if err != nil {
+		if errors.Is(err, service.ErrImportQueueFull) {
+			respondServiceError(c, http.StatusTooManyRequests, err, i18n.CodeImportQueueFull)
+			return
+		}
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeImportStartFailed, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"data": importJobResponse(job)})
+}
+
+// GetImportHandler retorna o status e o progresso de um job de importação.
+func (h *ImportHandler) GetImportHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeInvalidImportID)
+		return
+	}
+
+	job, err := h.ImportService.GetImportJob(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodeImportNotFound)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeImportFetchFailed, err)
+		return
+	}
+
+	response := importJobResponse(job)
+	response["report_id"] = h.ImportService.Reports.JobReportID(job.ID)
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// importJobResponse formata um ImportJob para a resposta da API.
+func importJobResponse(job *database.ImportJob) gin.H {
+	formatTime := func(t *time.Time) string {
+		if t != nil {
+			return t.Format(time.RFC3339)
+		}
+		return ""
+	}
+
+	return gin.H{
+		"id":              job.ID,
+		"source_path":     job.SourcePath,
+		"in_place":        job.InPlace,
+		"format":          job.Format,
+		"manifest_path":   job.ManifestPath,
+		"status":          job.Status,
+		"cursor":          job.Cursor,
+		"processed_files": job.ProcessedFiles,
+		"imported_files":  job.ImportedFiles,
+		"updated_files":   job.UpdatedFiles,
+		"skipped_files":   job.SkippedFiles,
+		"failed_files":    job.FailedFiles,
+		"missing_files":   job.MissingFiles,
+		"last_error":      job.LastError,
+		"started_at":      formatTime(job.StartedAt),
+		"finished_at":     formatTime(job.FinishedAt),
+	}
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ImportReportHandler gerencia as requisições HTTP para os relatórios de importação e upload.
+type ImportReportHandler struct {
+	ReportService *service.ImportReportService
+}
+
+// NewImportReportHandler cria uma nova instância de ImportReportHandler.
+func NewImportReportHandler(s *service.ImportReportService) *ImportReportHandler {
+	return &ImportReportHandler{
+		ReportService: s,
+	}
+}
+
+// ListReportsHandler lista os relatórios dos lotes, mais recentes primeiro: ?kind=upload|zip|directory,
+// ?limit (padrão 50) e ?offset.
+func (h *ImportReportHandler) ListReportsHandler(c *gin.Context) {
+	var query struct {
+		Kind   string `form:"kind" binding:"omitempty,oneof=upload zip directory"`
+		Limit  int    `form:"limit,default=50" binding:"min=1,max=500"`
+		Offset int    `form:"offset" binding:"min=0"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	reports, total, err := h.ReportService.ListReports(service.ImportReportFilter{Kind: query.Kind, Limit: query.Limit, Offset: query.Offset})
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeImportReportsFetchFailed, err)
+		return
+	}
+
+	response := []gin.H{}
+	for _, report := range reports {
+		response = append(response, importReportResponse(report))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": response, "total": total})
+}
+
+// GetReportHandler retorna um relatório com os arquivos não importados: duplicatas (com o link da foto
+// existente), ignorados e falhas com o motivo. ?outcome=duplicate|skipped|failed filtra os arquivos.
+func (h *ImportReportHandler) GetReportHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidImportReportID)
+	if !ok {
+		return
+	}
+	var query struct {
+		Outcome string `form:"outcome" binding:"omitempty,oneof=duplicate skipped failed"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	report, entries, err := h.ReportService.GetReport(id, query.Outcome)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodeImportReportNotFound)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeImportReportsFetchFailed, err)
+		return
+	}
+
+	files := []gin.H{}
+	for _, entry := range entries {
+		file := gin.H{
+			"name":      entry.Name,
+			"outcome":   entry.Outcome,
+			"code":      entry.Code,
+			"validator": entry.Validator,
+			"error":     entry.Error,
+			"photo_id":  entry.PhotoID,
+			"photo_url": "",
+		}
+		if entry.PhotoID != nil {
+			file["photo_url"] = fmt.Sprintf("/photos/%d", *entry.PhotoID)
+		}
+		files = append(files, file)
+	}
+	response := importReportResponse(*report)
+	response["files"] = files
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// importReportResponse formata o resumo de um relatório para a resposta da API.
+func importReportResponse(report database.ImportReport) gin.H {
+	finishedAt := ""
+	if report.FinishedAt != nil {
+		finishedAt = report.FinishedAt.Format(time.RFC3339)
+	}
+	return gin.H{
+		"id":            report.ID,
+		"kind":          report.Kind,
+		"source":        report.Source,
+		"import_job_id": report.ImportJobID,
+		"total":         report.Total,
+		"imported":      report.Imported,
+		"updated":       report.Updated,
+		"unchanged":     report.Unchanged,
+		"duplicates":    report.Duplicates,
+		"skipped":       report.Skipped,
+		"failed":        report.Failed,
+		"interrupted":   report.Interrupted,
+		"created_at":    report.CreatedAt.Format(time.RFC3339),
+		"finished_at":   finishedAt,
+	}
+}
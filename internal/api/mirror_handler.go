@@ -0,0 +1,190 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MirrorHandler gerencia as origens remotas espelhadas para a biblioteca.
+type MirrorHandler struct {
+	MirrorService *service.MirrorService
+}
+
+// NewMirrorHandler cria uma nova instância de MirrorHandler.
+func NewMirrorHandler(s *service.MirrorService) *MirrorHandler {
+	return &MirrorHandler{
+		MirrorService: s,
+	}
+}
+
+// ListMirrorsHandler lista as origens registradas.
+func (h *MirrorHandler) ListMirrorsHandler(c *gin.Context) {
+	sources, err := h.MirrorService.ListSources()
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeMirrorsFetchFailed, err)
+		return
+	}
+
+	responseSources := []gin.H{}
+	for _, source := range sources {
+		responseSources = append(responseSources, mirrorResponse(source))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": responseSources})
+}
+
+// CreateMirrorHandler registra uma origem, ex: {"name": "Fotos antigas", "kind": "s3", "bucket": "backup-fotos",
+// "prefix": "2015/", "region": "sa-east-1", "access_key": "...", "secret_key": "..."} ou
+// {"name": "NAS", "kind": "dir", "path": "/mnt/nas/fotos"}. Para assinar um álbum compartilhado por outra
+// instância, {"name": "Netos", "kind": "share", "url": "https://fotos.familia.com/s/<token>",
+// "interval_minutes": 30}: as fotos vão para um álbum local bloqueado, que acompanha o original. A primeira
+// sincronização é iniciada em seguida.
+func (h *MirrorHandler) CreateMirrorHandler(c *gin.Context) {
+	var req struct {
+		Name            string `json:"name" binding:"required"`
+		Kind            string `json:"kind" binding:"required"`
+		Path            string `json:"path"`
+		Endpoint        string `json:"endpoint"`
+		Region          string `json:"region"`
+		Bucket          string `json:"bucket"`
+		Prefix          string `json:"prefix"`
+		AccessKey       string `json:"access_key"`
+		SecretKey       string `json:"secret_key"`
+		URL             string `json:"url"`
+		IntervalMinutes *int   `json:"interval_minutes"` // Padrão: 60
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldsRequired, "'name', 'kind'")
+		return
+	}
+
+	source := database.MirrorSource{
+		Name:            req.Name,
+		Kind:            req.Kind,
+		Path:            req.Path,
+		Endpoint:        req.Endpoint,
+		Region:          req.Region,
+		Bucket:          req.Bucket,
+		Prefix:          req.Prefix,
+		AccessKey:       req.AccessKey,
+		SecretKey:       req.SecretKey,
+		URL:             req.URL,
+		IntervalMinutes: 60,
+	}
+	if req.IntervalMinutes != nil {
+		source.IntervalMinutes = *req.IntervalMinutes
+	}
+	if err := h.MirrorService.CreateSource(&source); err != nil {
+		if i18n.Code(err) != "" {
+			respondServiceError(c, http.StatusBadRequest, err, i18n.CodeBadRequest)
+			return
+		}
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeMirrorCreateFailed, err)
+		return
+	}
+
+	h.MirrorService.StartSync(source.ID)
+	c.JSON(http.StatusCreated, gin.H{"data": mirrorResponse(source)})
+}
+
+// GetMirrorHandler retorna a origem com a contagem dos objetos já processados por situação.
+func (h *MirrorHandler) GetMirrorHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidMirrorID)
+	if !ok {
+		return
+	}
+
+	source, err := h.MirrorService.GetSource(id)
+	if err != nil {
+		respondMirrorError(c, err)
+		return
+	}
+	counts, err := h.MirrorService.ObjectCounts(id)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeMirrorsFetchFailed, err)
+		return
+	}
+
+	response := mirrorResponse(*source)
+	response["objects"] = counts
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// SyncMirrorHandler inicia a sincronização imediata da origem, em background (202 Accepted).
+func (h *MirrorHandler) SyncMirrorHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidMirrorID)
+	if !ok {
+		return
+	}
+
+	source, err := h.MirrorService.StartSync(id)
+	if errors.Is(err, service.ErrMirrorRunning) {
+		respondError(c, http.StatusConflict, i18n.CodeMirrorRunning)
+		return
+	}
+	if err != nil {
+		respondMirrorError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"data": mirrorResponse(*source)})
+}
+
+// DeleteMirrorHandler remove a origem; as fotos importadas dela permanecem na biblioteca.
+func (h *MirrorHandler) DeleteMirrorHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidMirrorID)
+	if !ok {
+		return
+	}
+
+	if err := h.MirrorService.DeleteSource(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodeMirrorNotFound)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeMirrorDeleteFailed, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message(c, i18n.CodeMirrorDeleted)})
+}
+
+// respondMirrorError responde com 404 para origens inexistentes e 500 para os demais erros.
+func respondMirrorError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(c, http.StatusNotFound, i18n.CodeMirrorNotFound)
+		return
+	}
+	respondErrorCause(c, http.StatusInternalServerError, i18n.CodeMirrorsFetchFailed, err)
+}
+
+// mirrorResponse formata uma origem para a resposta da API. A chave secreta nunca é devolvida.
+func mirrorResponse(source database.MirrorSource) gin.H {
+	lastSyncAt := ""
+	if source.LastSyncAt != nil {
+		lastSyncAt = source.LastSyncAt.Format(time.RFC3339)
+	}
+	return gin.H{
+		"id":               source.ID,
+		"name":             source.Name,
+		"kind":             source.Kind,
+		"path":             source.Path,
+		"endpoint":         source.Endpoint,
+		"region":           source.Region,
+		"bucket":           source.Bucket,
+		"prefix":           source.Prefix,
+		"access_key":       source.AccessKey,
+		"url":              source.URL,
+		"album_id":         source.AlbumID,
+		"interval_minutes": source.IntervalMinutes,
+		"last_sync_at":     lastSyncAt,
+		"last_imported":    source.LastImported,
+		"last_duplicates":  source.LastSkipped,
+		"last_failed":      source.LastFailed,
+		"last_error":       source.LastError,
+	}
+}
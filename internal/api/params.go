@@ -0,0 +1,142 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		// Os erros de validação usam o nome do parâmetro (tag form ou json), não o nome do campo Go
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"form", "json"} {
+				if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" && name != "-" {
+					return name
+				}
+			}
+			return field.Name
+		})
+		v.RegisterValidation("photo_order", func(fl validator.FieldLevel) bool {
+			return service.ValidPhotoOrder(fl.Field().String())
+		})
+	}
+}
+
+// parseIDParam lê um parâmetro de rota numérico (ex: :id), respondendo 400 com a mensagem de code se for inválido.
+func parseIDParam(c *gin.Context, name, code string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		respondError(c, http.StatusBadRequest, code)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// fieldError descreve um parâmetro inválido.
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// bindQuery preenche obj (ponteiro para struct com tags form e binding) a partir da query string.
+// Em caso de erro responde 422 com {"error", "field", "details"}, nomeando o parâmetro inválido.
+func bindQuery(c *gin.Context, obj interface{}) bool {
+	err := c.ShouldBindQuery(obj)
+	if err == nil {
+		return true
+	}
+
+	var details []fieldError
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for _, fe := range validationErrors {
+			details = append(details, fieldError{Field: fe.Field(), Message: validationMessage(c, fe)})
+		}
+	} else {
+		// Erros de conversão (ex: texto em parâmetro numérico) não informam o parâmetro
+		details = append(details, fieldError{Field: invalidQueryField(c, obj), Message: message(c, i18n.CodeParamFormat)})
+	}
+
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":   message(c, i18n.CodeInvalidParam, details[0].Field, details[0].Message),
+		"code":    i18n.CodeInvalidParam,
+		"field":   details[0].Field,
+		"details": details,
+	})
+	return false
+}
+
+// respondInvalidParam responde 422 para um parâmetro da query string validado fora de bindQuery,
+// no mesmo formato dos erros de bindQuery.
+func respondInvalidParam(c *gin.Context, field, detail string) {
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":   message(c, i18n.CodeInvalidParam, field, detail),
+		"code":    i18n.CodeInvalidParam,
+		"field":   field,
+		"details": []fieldError{{Field: field, Message: detail}},
+	})
+}
+
+// validationMessage descreve a regra de validação violada, no idioma da requisição.
+func validationMessage(c *gin.Context, fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_with":
+		return message(c, i18n.CodeParamRequired)
+	case "min", "gte":
+		return message(c, i18n.CodeParamMin, fe.Param())
+	case "max", "lte":
+		return message(c, i18n.CodeParamMax, fe.Param())
+	case "oneof":
+		return message(c, i18n.CodeParamOneOf, strings.Join(strings.Fields(fe.Param()), ", "))
+	case "photo_order":
+		return message(c, i18n.CodeParamPhotoOrder, strings.Join(service.PhotoOrderColumns, ", "))
+	default:
+		return message(c, i18n.CodeParamInvalid)
+	}
+}
+
+// invalidQueryField encontra o parâmetro da query string que não pôde ser convertido para o tipo do campo,
+// convertendo cada parâmetro isoladamente.
+func invalidQueryField(c *gin.Context, obj interface{}) string {
+	query := c.Request.URL.Query()
+	var find func(t reflect.Type) string
+	find = func(t reflect.Type) string {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if name := find(field.Type); name != "" {
+					return name
+				}
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+			values, ok := query[name]
+			if name == "" || !ok {
+				continue
+			}
+			probe := reflect.New(t).Interface()
+			if err := binding.MapFormWithTag(probe, map[string][]string{name: values}, "form"); err != nil {
+				return name
+			}
+		}
+		return ""
+	}
+
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if name := find(t); name != "" {
+		return name
+	}
+	return "query"
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PhotoAnnotationHandler gerencia as anotações de regiões das fotos ("esta é a casa do vovô").
+type PhotoAnnotationHandler struct {
+	AnnotationService *service.PhotoAnnotationService
+}
+
+// NewPhotoAnnotationHandler cria uma nova instância de PhotoAnnotationHandler.
+func NewPhotoAnnotationHandler(s *service.PhotoAnnotationService) *PhotoAnnotationHandler {
+	return &PhotoAnnotationHandler{
+		AnnotationService: s,
+	}
+}
+
+// annotationRequest descreve uma anotação: a região, em frações da largura e da altura da imagem (0 a 1, a
+// partir do canto superior esquerdo), o texto e, opcionalmente, o tipo (note, face ou pet; nos rostos e
+// animais o texto é o nome).
+type annotationRequest struct {
+	X      *float64 `json:"x" binding:"required"`
+	Y      *float64 `json:"y" binding:"required"`
+	Width  float64  `json:"width" binding:"required"`
+	Height float64  `json:"height" binding:"required"`
+	Text   string   `json:"text" binding:"required"`
+	Kind   string   `json:"kind"`
+}
+
+// region converte a requisição na região da anotação.
+func (r annotationRequest) region() service.AnnotationRegion {
+	return service.AnnotationRegion{X: *r.X, Y: *r.Y, Width: r.Width, Height: r.Height}
+}
+
+// ListAnnotationsHandler lista as anotações da foto (GET /photos/:id/annotations).
+func (h *PhotoAnnotationHandler) ListAnnotationsHandler(c *gin.Context) {
+	photoID, ok := parseIDParam(c, "id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+
+	annotations, err := h.AnnotationService.ListAnnotations(c.Request.Context(), photoID)
+	if err != nil {
+		respondAnnotationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": annotationsResponse(annotations)})
+}
+
+// CreateAnnotationHandler anota uma região da foto (POST /photos/:id/annotations, corpo {"x": 0.1,
+// "y": 0.2, "width": 0.3, "height": 0.25, "text": "Casa do vovô"}, ou com "kind": "face" e o nome da pessoa).
+func (h *PhotoAnnotationHandler) CreateAnnotationHandler(c *gin.Context) {
+	photoID, ok := parseIDParam(c, "id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+	var req annotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldsRequired, "'x', 'y', 'width', 'height', 'text'")
+		return
+	}
+
+	annotation, err := h.AnnotationService.CreateAnnotation(c.Request.Context(), photoID, req.region(), req.Text, req.Kind)
+	if err != nil {
+		respondAnnotationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": annotationResponse(*annotation)})
+}
+
+// UpdateAnnotationHandler substitui a região e o texto de uma anotação
+// (PUT /photos/:id/annotations/:annotation_id, mesmo corpo da criação; sem "kind", o tipo é mantido).
+func (h *PhotoAnnotationHandler) UpdateAnnotationHandler(c *gin.Context) {
+	photoID, ok := parseIDParam(c, "id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+	annotationID, ok := parseIDParam(c, "annotation_id", i18n.CodeInvalidAnnotationID)
+	if !ok {
+		return
+	}
+	var req annotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldsRequired, "'x', 'y', 'width', 'height', 'text'")
+		return
+	}
+
+	annotation, err := h.AnnotationService.UpdateAnnotation(c.Request.Context(), photoID, annotationID, req.region(), req.Text, req.Kind)
+	if err != nil {
+		respondAnnotationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": annotationResponse(*annotation)})
+}
+
+// DeleteAnnotationHandler remove uma anotação da foto (DELETE /photos/:id/annotations/:annotation_id).
+func (h *PhotoAnnotationHandler) DeleteAnnotationHandler(c *gin.Context) {
+	photoID, ok := parseIDParam(c, "id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+	annotationID, ok := parseIDParam(c, "annotation_id", i18n.CodeInvalidAnnotationID)
+	if !ok {
+		return
+	}
+
+	if err := h.AnnotationService.DeleteAnnotation(c.Request.Context(), photoID, annotationID); err != nil {
+		respondAnnotationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message(c, i18n.CodeAnnotationRemoved)})
+}
+
+// ImportRegionsHandler importa como anotações as regiões nomeadas do XMP no esquema MWG ou Microsoft Photo
+// (POST /photos/:id/annotations/import): os rostos marcados no Picasa, digiKam ou Lightroom viram anotações do
+// tipo face com o nome da pessoa. O corpo é um arquivo XMP lateral; sem corpo, as regiões são lidas do XMP ao
+// lado do arquivo da foto (foto.xmp ou foto.jpg.xmp) ou do embutido nele. Regiões já anotadas são ignoradas.
+func (h *PhotoAnnotationHandler) ImportRegionsHandler(c *gin.Context) {
+	photoID, ok := parseIDParam(c, "id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+	var xmp []byte
+	if c.Request.ContentLength != 0 {
+		body, err := io.ReadAll(c.Request.Body)
+		if limit, tooLarge := bodyTooLarge(err); tooLarge {
+			respondError(c, http.StatusRequestEntityTooLarge, i18n.CodeRequestTooLarge, limit>>20)
+			return
+		}
+		if err != nil {
+			respondErrorCause(c, http.StatusBadRequest, i18n.CodeAnnotationXMPFailed, err)
+			return
+		}
+		if len(body) > 0 {
+			xmp = body
+		}
+	}
+
+	report, err := h.AnnotationService.ImportRegions(c.Request.Context(), photoID, xmp)
+	if err != nil {
+		if i18n.Code(err) == "" && !errors.Is(err, gorm.ErrRecordNotFound) {
+			respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAnnotationXMPFailed, err)
+			return
+		}
+		respondAnnotationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":     annotationsResponse(report.Annotations),
+		"imported": report.Imported,
+		"skipped":  report.Skipped,
+	})
+}
+
+// ExportRegionsHandler baixa as anotações da foto como um arquivo XMP lateral com regiões MWG (e os rostos
+// também no esquema Microsoft Photo), com o nome da foto e extensão .xmp (GET /photos/:id/regions.xmp). Salvo
+// ao lado do arquivo da foto, leva os nomes atribuídos aqui para o digiKam, Lightroom e afins.
+func (h *PhotoAnnotationHandler) ExportRegionsHandler(c *gin.Context) {
+	photoID, ok := parseIDParam(c, "id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+
+	data, photo, err := h.AnnotationService.ExportRegions(c.Request.Context(), photoID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAnnotationXMPFailed, err)
+		return
+	}
+	name := strings.TrimSuffix(photo.Filename, filepath.Ext(photo.Filename)) + ".xmp"
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	c.Data(http.StatusOK, "application/rdf+xml", data)
+}
+
+// respondAnnotationError traduz os erros de PhotoAnnotationService em respostas HTTP.
+func respondAnnotationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondError(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+	case errors.Is(err, service.ErrAnnotationNotFound):
+		respondServiceError(c, http.StatusNotFound, err, i18n.CodeAnnotationNotFound)
+	case errors.Is(err, service.ErrPhotoLocked):
+		respondError(c, http.StatusLocked, i18n.CodePhotoLocked)
+	case errors.Is(err, service.ErrAnnotationRegionInvalid), errors.Is(err, service.ErrAnnotationTextRequired),
+		errors.Is(err, service.ErrAnnotationKindInvalid), i18n.Code(err) == i18n.CodeAnnotationXMPInvalid:
+		respondServiceError(c, http.StatusBadRequest, err, i18n.CodeAnnotationFailed)
+	case c.Request.Method == http.MethodGet:
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAnnotationsFetchFailed, err)
+	default:
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAnnotationFailed, err)
+	}
+}
+
+// annotationsResponse formata as anotações de uma foto.
+func annotationsResponse(annotations []database.PhotoAnnotation) []gin.H {
+	items := make([]gin.H, 0, len(annotations))
+	for _, annotation := range annotations {
+		items = append(items, annotationResponse(annotation))
+	}
+	return items
+}
+
+// annotationResponse formata uma anotação de região.
+func annotationResponse(annotation database.PhotoAnnotation) gin.H {
+	return gin.H{
+		"id":         annotation.ID,
+		"photo_id":   annotation.PhotoID,
+		"x":          annotation.X,
+		"y":          annotation.Y,
+		"width":      annotation.Width,
+		"height":     annotation.Height,
+		"text":       annotation.Text,
+		"kind":       annotation.Kind,
+		"created_at": annotation.CreatedAt.Format(time.RFC3339),
+		"updated_at": annotation.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/png"
+	"math"
+	"net/http"
+
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// comparePhotosQuery são os parâmetros de GET /photos/compare.
+type comparePhotosQuery struct {
+	A      uint   `form:"a" binding:"required,min=1"`
+	B      uint   `form:"b" binding:"required,min=1"`
+	Policy string `form:"policy" binding:"omitempty,oneof=keep-oldest keep-largest keep-raw"`
+}
+
+// ComparePhotosHandler compara duas fotos lado a lado (?a=1&b=2), para a revisão de quase duplicatas:
+// diferenças de metadados, semelhança visual após alinhar as imagens, um mapa das diferenças (PNG em data
+// URI) e a foto que a política de deduplicação (?policy=, padrão keep-largest) manteria.
+func (h *PhotoHandler) ComparePhotosHandler(c *gin.Context) {
+	var query comparePhotosQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	if query.Policy == "" {
+		query.Policy = service.DedupeKeepLargest
+	}
+
+	a, ok := h.loadPhotoByID(c, query.A)
+	if !ok {
+		return
+	}
+	b, ok := h.loadPhotoByID(c, query.B)
+	if !ok {
+		return
+	}
+	thumbA, ok := h.thumbnailPath(c, a)
+	if !ok {
+		return
+	}
+	thumbB, ok := h.thumbnailPath(c, b)
+	if !ok {
+		return
+	}
+
+	comparison, err := h.PhotoService.ComparePhotos(a, b, thumbA, thumbB, query.Policy)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotoCompareFailed, err)
+		return
+	}
+
+	var diff bytes.Buffer
+	if err := png.Encode(&diff, comparison.Visual.Diff); err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotoCompareFailed, err)
+		return
+	}
+
+	fields := []gin.H{}
+	differing := 0
+	for _, field := range comparison.Fields {
+		fields = append(fields, gin.H{"field": field.Field, "a": field.A, "b": field.B, "same": field.Same})
+		if !field.Same {
+			differing++
+		}
+	}
+	visual := comparison.Visual
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"a":                photoResponse(*a),
+		"b":                photoResponse(*b),
+		"identical":        comparison.Identical,
+		"fields":           fields,
+		"differing_fields": differing,
+		"visual": gin.H{
+			"similarity":          math.Round(visual.Similarity*10000) / 10000,
+			"psnr":                math.Round(visual.PSNR*100) / 100,
+			"offset_x":            visual.OffsetX,
+			"offset_y":            visual.OffsetY,
+			"perceptual_distance": visual.PerceptualDistance,
+			"aspect_mismatch":     visual.AspectMismatch,
+			"diff_image":          "data:image/png;base64," + base64.StdEncoding.EncodeToString(diff.Bytes()),
+		},
+		"suggested_keep": gin.H{"photo_id": comparison.KeepID, "policy": comparison.KeepBy},
+	}})
+}
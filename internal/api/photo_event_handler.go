@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PhotoEventHandler gerencia os eventos sugeridos (viagens, festas...) detectados a partir das fotos.
+type PhotoEventHandler struct {
+	PhotoEventService *service.PhotoEventService
+}
+
+// NewPhotoEventHandler cria uma nova instância de PhotoEventHandler.
+func NewPhotoEventHandler(s *service.PhotoEventService) *PhotoEventHandler {
+	return &PhotoEventHandler{
+		PhotoEventService: s,
+	}
+}
+
+// ListEventsHandler retorna os eventos detectados, do mais recente para o mais antigo.
+// ?min_photos= omite eventos com poucas fotos (padrão: 1).
+func (h *PhotoEventHandler) ListEventsHandler(c *gin.Context) {
+	var query struct {
+		MinPhotos int `form:"min_photos,default=1" binding:"min=1"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	events, err := h.PhotoEventService.DetectEvents(c.Request.Context(), query.MinPhotos)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeEventsFailed, err)
+		return
+	}
+
+	responseEvents := []gin.H{}
+	for _, event := range events {
+		responseEvents = append(responseEvents, photoEventResponse(event))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": responseEvents})
+}
+
+// PromoteEventHandler cria um álbum com as fotos do evento. O corpo é opcional: {"name", "description"};
+// sem nome, o álbum recebe o nome sugerido do evento.
+func (h *PhotoEventHandler) PromoteEventHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidEventID)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, i18n.CodeInvalidRequestBody)
+			return
+		}
+	}
+
+	album, err := h.PhotoEventService.PromoteEvent(c.Request.Context(), id, req.Name, req.Description)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodeEventNotFound)
+			return
+		}
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeAlbumCreateFailed, err)
+		return
+	}
+
+	summary, err := h.PhotoEventService.AlbumService.GetAlbum(album.ID)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAlbumCreatedFetch, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": albumResponse(*summary)})
+}
+
+// photoEventResponse formata um evento para as respostas da API.
+func photoEventResponse(event service.PhotoEvent) gin.H {
+	return gin.H{
+		"id":                  event.ID,
+		"name":                event.Name,
+		"place":               event.Place,
+		"start":               event.Start.Format(time.RFC3339),
+		"end":                 event.End.Format(time.RFC3339),
+		"photo_count":         len(event.PhotoIDs),
+		"photo_ids":           event.PhotoIDs,
+		"latitude":            event.Latitude,
+		"longitude":           event.Longitude,
+		"cover_thumbnail_url": fmt.Sprintf("/photos/%d/thumbnail", event.PhotoIDs[0]),
+	}
+}
@@ -1,19 +1,41 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"mime/multipart"
 	"net/http"
+	"os"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
 	"photo-manager/internal/service"
+	"photo-manager/internal/storage"
+	"photo-manager/internal/thumbnail"
+	"photo-manager/internal/validation"
+	"photo-manager/internal/video"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // PhotoHandler gerencia as requisições HTTP para fotos.
 type PhotoHandler struct {
 	PhotoService *service.PhotoService
+	AccessStats  *service.AccessStatsService // Contabiliza visualizações e downloads (opcional)
+	Thumbnails   *service.ThumbnailService   // Geração das miniaturas com solicitações simultâneas compartilhadas (opcional)
+
+	ImportReports *service.ImportReportService    // Relatório de cada lote de upload (opcional)
+	Annotations   *service.PhotoAnnotationService // Anotações de regiões incluídas no detalhe da foto (opcional)
 }
 
 // NewPhotoHandler cria uma nova instância de PhotoHandler.
@@ -24,42 +46,106 @@ func NewPhotoHandler(s *service.PhotoService) *PhotoHandler {
 }
 
 // UploadPhotoHandler lida com o upload de uma ou múltiplas fotos.
+// Para verificar a integridade, o cliente pode enviar o SHA-256 esperado de cada arquivo: campos "sha256" do
+// formulário, na mesma ordem dos arquivos "photos", ou o cabeçalho X-Content-SHA256 quando há um único arquivo.
+// A procedência pode ser informada nos campos "source" (upload, sync, email ou share_upload; padrão upload)
+// e "device" (ex: "Celular da Mãe"); o User-Agent do cliente é registrado como detalhe.
+// Duplicatas seguem a política de duplicatas da origem (ver /sources/duplicate-policies): as ignoradas vêm em
+// "skipped", com o ID da foto existente; as rejeitadas, em "errors".
 func (h *PhotoHandler) UploadPhotoHandler(c *gin.Context) {
 	form, err := c.MultipartForm()
+	if limit, tooLarge := bodyTooLarge(err); tooLarge {
+		respondError(c, http.StatusRequestEntityTooLarge, i18n.CodeRequestTooLarge, limit>>20)
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Não foi possível ler o formulário multipart: %v", err)})
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeMultipartInvalid, err)
 		return
 	}
 
 	files := form.File["photos"] // Nome do campo do input type="file" no HTML
 
 	if len(files) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Nenhum arquivo 'photos' encontrado no formulário."})
+		respondError(c, http.StatusBadRequest, i18n.CodeUploadNoFiles)
+		return
+	}
+
+	checksums, ok := uploadChecksums(c, form, len(files))
+	if !ok {
+		return
+	}
+
+	origin, ok := uploadOrigin(c, form)
+	if !ok {
+		return
+	}
+
+	// Verifica antes de processar se os volumes comportam o lote inteiro
+	sizes := make([]int64, 0, len(files))
+	for _, file := range files {
+		sizes = append(sizes, file.Size)
+	}
+	if err := h.PhotoService.FileManager.CheckBatchCapacity(sizes); err != nil {
+		respondStorageError(c, err)
 		return
 	}
 
 	uploadedPhotos := []map[string]string{}
+	skipped := []map[string]string{} // Duplicatas ignoradas pela política de duplicatas da origem
 	errors := []map[string]string{}
 
-	for _, file := range files {
-		// Adicionar validação de MIME type e tamanho máximo aqui!
-		// Exemplo básico de validação de MIME type:
-		if file.Header.Get("Content-Type") != "image/jpeg" && file.Header.Get("Content-Type") != "image/png" {
-			errors = append(errors, map[string]string{"filename": file.Filename, "error": "Tipo de arquivo não permitido. Apenas JPG/PNG."})
-			continue
+	recorder := h.ImportReports.Begin(database.ImportKindUpload, origin.Source)
+	ctx := c.Request.Context()
+	for i, file := range files {
+		// Cliente desconectou: interrompe o processamento dos arquivos restantes
+		if ctx.Err() != nil {
+			log.Printf("Upload cancelado pelo cliente; %d arquivo(s) não processado(s)\n", len(files)-i)
+			recorder.Finish(ctx.Err())
+			return
 		}
 
-		// Limite de 10MB por arquivo
-		const maxUploadSize = 10 << 20 // 10 MB
-		if file.Size > maxUploadSize {
-			errors = append(errors, map[string]string{"filename": file.Filename, "error": fmt.Sprintf("Tamanho do arquivo excede o limite de %dMB", maxUploadSize/(1<<20))})
-			continue
+		expectedSHA256 := ""
+		if len(checksums) > 0 {
+			expectedSHA256 = checksums[i]
 		}
 
-		photo, err := h.PhotoService.UploadPhoto(file)
+		// Checksum, tamanho, tipo, dimensões e antivírus são verificados pelo PhotoService
+		photo, err := h.PhotoService.UploadPhoto(ctx, file, expectedSHA256, origin)
+		if !isStorageUnavailable(err) {
+			recorder.Record(file.Filename, photo, err)
+		}
+		if validationErr, ok := validation.AsError(err); ok {
+			errors = append(errors, map[string]string{
+				"filename":  file.Filename,
+				"error":     validationErr.Localize(locale(c)),
+				"code":      validationErr.Code,
+				"validator": validationErr.Validator,
+			})
+			continue
+		}
+		if i18n.Code(err) == i18n.CodePhotoDuplicateSkipped {
+			skipped = append(skipped, map[string]string{
+				"filename":    file.Filename,
+				"existing_id": fmt.Sprintf("%d", photo.ID),
+				"code":        i18n.CodePhotoDuplicateSkipped,
+			})
+			continue
+		}
 		if err != nil {
 			log.Printf("Erro ao processar o upload da foto '%s': %v\n", file.Filename, err)
-			errors = append(errors, map[string]string{"filename": file.Filename, "error": err.Error()})
+			if isStorageUnavailable(err) {
+				// Sem espaço (ou volume) não adianta tentar os arquivos restantes
+				recorder.Finish(err)
+				c.JSON(storageErrorStatus(err), gin.H{
+					"error":     message(c, i18n.CodeUploadInterrupted) + ": " + i18n.Localize(err, locale(c)),
+					"code":      i18n.CodeUploadInterrupted,
+					"uploaded":  uploadedPhotos,
+					"pending":   len(files) - i,
+					"report_id": recorder.ReportID(),
+				})
+				return
+			}
+			errors = append(errors, map[string]string{"filename": file.Filename, "error": i18n.Localize(err, locale(c)), "code": i18n.Code(err)})
 		} else {
 			uploadedPhotos = append(uploadedPhotos, map[string]string{
 				"id":        fmt.Sprintf("%d", photo.ID),
@@ -75,109 +161,694 @@ func (h *PhotoHandler) UploadPhotoHandler(c *gin.Context) {
 		}
 	}
 
+	recorder.Finish(nil)
 	if len(errors) > 0 {
 		c.JSON(http.StatusMultiStatus, gin.H{
-			"message":  "Algumas fotos foram processadas com erros.",
-			"uploaded": uploadedPhotos,
-			"errors":   errors,
+			"message":   message(c, i18n.CodeUploadPartial),
+			"uploaded":  uploadedPhotos,
+			"skipped":   skipped,
+			"errors":    errors,
+			"report_id": recorder.ReportID(),
 		})
 	} else {
 		c.JSON(http.StatusOK, gin.H{
-			"message":  "Uploads processados com sucesso!",
-			"uploaded": uploadedPhotos,
+			"message":   message(c, i18n.CodeUploadSucceeded),
+			"uploaded":  uploadedPhotos,
+			"skipped":   skipped,
+			"report_id": recorder.ReportID(),
 		})
 	}
 }
 
+// maxDeviceNameLength limita o nome do dispositivo informado no upload.
+const maxDeviceNameLength = 100
+
+// uploadChecksums lê os SHA-256 esperados dos arquivos: o campo "sha256" (um por arquivo, na mesma ordem)
+// ou o cabeçalho X-Content-SHA256 (upload de um único arquivo). Responde com erro se forem inválidos.
+func uploadChecksums(c *gin.Context, form *multipart.Form, fileCount int) ([]string, bool) {
+	checksums := form.Value["sha256"]
+	if header := c.GetHeader("X-Content-SHA256"); header != "" {
+		if fileCount != 1 || len(checksums) > 0 {
+			respondError(c, http.StatusBadRequest, i18n.CodeChecksumHeaderSingle)
+			return nil, false
+		}
+		checksums = []string{header}
+	}
+	if len(checksums) > 0 && len(checksums) != fileCount {
+		respondError(c, http.StatusBadRequest, i18n.CodeChecksumCountMismatch, len(checksums), fileCount)
+		return nil, false
+	}
+	for _, checksum := range checksums {
+		if !isSHA256Hex(checksum) {
+			respondError(c, http.StatusBadRequest, i18n.CodeChecksumInvalid, checksum)
+			return nil, false
+		}
+	}
+	return checksums, true
+}
+
+// uploadOrigin lê a procedência declarada no formulário de upload. Responde 400 e retorna false se for inválida.
+func uploadOrigin(c *gin.Context, form *multipart.Form) (service.PhotoOrigin, bool) {
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 200 {
+		userAgent = userAgent[:200]
+	}
+	origin := service.PhotoOrigin{Source: database.SourceUpload, Detail: userAgent}
+	if values := form.Value["source"]; len(values) > 0 && values[0] != "" {
+		origin.Source = values[0]
+		if !slices.Contains(database.ClientSources, origin.Source) {
+			respondError(c, http.StatusBadRequest, i18n.CodeUploadSourceInvalid, origin.Source, strings.Join(database.ClientSources, ", "))
+			return origin, false
+		}
+	}
+	if values := form.Value["device"]; len(values) > 0 {
+		origin.Device = strings.TrimSpace(values[0])
+		if utf8.RuneCountInString(origin.Device) > maxDeviceNameLength {
+			respondError(c, http.StatusBadRequest, i18n.CodeUploadDeviceTooLong, maxDeviceNameLength)
+			return origin, false
+		}
+	}
+	return origin, true
+}
+
+// isSHA256Hex verifica se o valor é um SHA-256 em hexadecimal.
+func isSHA256Hex(value string) bool {
+	decoded, err := hex.DecodeString(value)
+	return err == nil && len(decoded) == sha256.Size
+}
+
+// listPhotosQuery são os parâmetros de GET /photos: filtros, paginação e ordenação.
+type listPhotosQuery struct {
+	photoFilterQuery
+	Limit   int    `form:"limit" binding:"min=0,max=500"` // 0 = sem limite
+	Offset  int    `form:"offset" binding:"min=0"`
+	OrderBy string `form:"order_by" binding:"omitempty,photo_order"`
+}
+
 // GetPhotosHandler lida com a busca e listagem de fotos com filtros.
 func (h *PhotoHandler) GetPhotosHandler(c *gin.Context) {
-	var filter service.PhotoFilter
+	var query listPhotosQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filter := query.filter()
+	filter.Limit = query.Limit
+	filter.Offset = query.Offset
+	filter.OrderBy = query.OrderBy
+	h.listPhotos(c, filter)
+}
 
-	// Extrai parâmetros da query string
-	if yearStr := c.Query("year"); yearStr != "" {
-		year, err := strconv.Atoi(yearStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Ano inválido."})
-			return
+// searchPhotosQuery são os parâmetros de GET /photos/search: a busca compacta q, além dos mesmos
+// parâmetros de GET /photos.
+type searchPhotosQuery struct {
+	listPhotosQuery
+	Q string `form:"q"`
+}
+
+// SearchPhotosHandler busca fotos pela linguagem de busca compacta, ex:
+// ?q=tag:praia year:2023 camera:"Canon" has:gps -tag:screenshots (ver service.ApplySearchQuery).
+// Os critérios de q são combinados com os demais parâmetros, que podem ser usados juntos.
+func (h *PhotoHandler) SearchPhotosHandler(c *gin.Context) {
+	var query searchPhotosQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filter := query.filter()
+	filter.Limit = query.Limit
+	filter.Offset = query.Offset
+	filter.OrderBy = query.OrderBy
+	if err := service.ApplySearchQuery(&filter, query.Q); err != nil {
+		respondInvalidParam(c, "q", i18n.Localize(err, locale(c)))
+		return
+	}
+	h.listPhotos(c, filter)
+}
+
+// listPhotos responde com as fotos do filtro, em JSON ou NDJSON conforme o cabeçalho Accept, com os
+// campos selecionados em ?fields= (ver photoFieldsSerializer).
+func (h *PhotoHandler) listPhotos(c *gin.Context, filter service.PhotoFilter) {
+	serialize, ok := photoFieldsSerializer(c)
+	if !ok {
+		return
+	}
+	if c.NegotiateFormat(gin.MIMEJSON, mimeNDJSON) == mimeNDJSON {
+		h.streamPhotos(c, filter, serialize)
+		return
+	}
+
+	photos, err := h.PhotoService.GetPhotos(c.Request.Context(), filter)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotosFetchFailed, err)
+		return
+	}
+
+	// Transformar as fotos para um formato de resposta mais amigável, se necessário
+	// Por exemplo, formatar datas, incluir URLs de acesso, etc.
+	responsePhotos := []gin.H{}
+	for _, photo := range photos {
+		responsePhotos = append(responsePhotos, serialize(photo))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": responsePhotos})
+}
+
+// mimeNDJSON é o tipo do formato JSON delimitado por linhas, um objeto por linha.
+const mimeNDJSON = "application/x-ndjson"
+
+// ndjsonFlushEvery é a quantidade de linhas enviadas entre cada descarga do buffer da resposta.
+const ndjsonFlushEvery = 500
+
+// streamPhotos envia as fotos como NDJSON (Accept: application/x-ndjson), uma por linha, à medida que são
+// lidas do banco, permitindo exportar os metadados de bibliotecas grandes sem carregá-las em memória.
+// Como o status já foi enviado, um erro no meio da listagem é informado numa última linha {"error": "..."}.
+func (h *PhotoHandler) streamPhotos(c *gin.Context, filter service.PhotoFilter, serialize photoSerializer) {
+	c.Header("Content-Type", mimeNDJSON)
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	count := 0
+	err := h.PhotoService.StreamPhotos(c.Request.Context(), filter, func(photo database.Photo) error {
+		if err := encoder.Encode(serialize(photo)); err != nil {
+			return err
+		}
+		if count++; count%ndjsonFlushEvery == 0 {
+			c.Writer.Flush()
 		}
-		filter.Year = year
+		return nil
+	})
+	if err != nil {
+		log.Printf("Erro ao enviar a listagem de fotos em NDJSON após %d foto(s): %v\n", count, err)
+		if c.Request.Context().Err() == nil {
+			encoder.Encode(gin.H{"error": message(c, i18n.CodePhotosFetchFailed) + ": " + i18n.Localize(err, locale(c)), "code": i18n.CodePhotosFetchFailed})
+		}
+	}
+	c.Writer.Flush()
+}
+
+// popularPhotosQuery são os parâmetros de GET /photos/popular.
+type popularPhotosQuery struct {
+	photoFilterQuery
+	By    string `form:"by" binding:"omitempty,oneof=views downloads"`
+	Limit int    `form:"limit" binding:"min=0,max=100"`
+}
+
+// GetPopularPhotosHandler retorna as fotos mais acessadas: ?by=views (padrão) ou ?by=downloads, com os
+// mesmos filtros de GET /photos (ex: album_id, year). Fotos nunca acessadas não entram no ranking.
+func (h *PhotoHandler) GetPopularPhotosHandler(c *gin.Context) {
+	var query popularPhotosQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	serialize, ok := photoFieldsSerializer(c)
+	if !ok {
+		return
+	}
+	if query.By == "" {
+		query.By = "views"
+	}
+	filter := query.filter()
+	filter.Limit = query.Limit
+	if filter.Limit == 0 {
+		filter.Limit = 20
+	}
+
+	// Grava os acessos acumulados para que o ranking reflita os mais recentes
+	if h.AccessStats != nil {
+		if err := h.AccessStats.Flush(c.Request.Context()); err != nil {
+			log.Printf("%v\n", err)
+		}
+	}
+
+	photos, err := h.PhotoService.PopularPhotos(c.Request.Context(), filter, query.By)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePopularPhotosFailed, err)
+		return
+	}
+
+	responsePhotos := []gin.H{}
+	for _, photo := range photos {
+		responsePhotos = append(responsePhotos, serialize(photo))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": responsePhotos})
+}
+
+// GetRandomPhotosHandler retorna fotos aleatórias que respeitam os filtros (album_id, tag, year, favorites...).
+// Sem cursor, inicia um novo embaralhamento (opcionalmente reproduzível via ?seed=); com ?cursor=, continua o
+// embaralhamento anterior sem repetir fotos. "next_cursor" vem vazio quando todas as fotos já foram entregues.
+func (h *PhotoHandler) GetRandomPhotosHandler(c *gin.Context) {
+	var query struct {
+		photoFilterQuery
+		Count  int    `form:"count,default=1" binding:"min=1,max=100"`
+		Cursor string `form:"cursor"`
+		Seed   uint64 `form:"seed"`
+	}
+	if !bindQuery(c, &query) {
+		return
 	}
-	if monthStr := c.Query("month"); monthStr != "" {
-		month, err := strconv.Atoi(monthStr)
+	serialize, ok := photoFieldsSerializer(c)
+	if !ok {
+		return
+	}
+	filter := query.filter()
+
+	var cursor *service.ShuffleCursor
+	if query.Cursor != "" {
+		var err error
+		cursor, err = service.ParseShuffleCursor(query.Cursor)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Mês inválido."})
+			respondInvalidParam(c, "cursor", message(c, i18n.CodeParamInvalid))
 			return
 		}
-		filter.Month = month
+	} else if c.Query("seed") != "" {
+		cursor = &service.ShuffleCursor{Seed: query.Seed}
 	}
-	filter.Filename = c.Query("filename")
-	filter.Tag = c.Query("tag")
 
-	if limitStr := c.Query("limit"); limitStr != "" {
-		limit, err := strconv.Atoi(limitStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Limite inválido."})
+	photos, next, err := h.PhotoService.GetRandomPhotos(c.Request.Context(), filter, query.Count, cursor)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeRandomPhotosFailed, err)
+		return
+	}
+
+	responsePhotos := []gin.H{}
+	for _, photo := range photos {
+		responsePhotos = append(responsePhotos, serialize(photo))
+	}
+
+	nextCursor := ""
+	if next != nil {
+		nextCursor = next.Encode()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": responsePhotos, "next_cursor": nextCursor})
+}
+
+// GetPhotoSourcesHandler lista as procedências registradas (canal de entrada e dispositivo) com a quantidade
+// de fotos de cada uma, para montar os filtros source e device da busca.
+func (h *PhotoHandler) GetPhotoSourcesHandler(c *gin.Context) {
+	counts, err := h.PhotoService.SourceCounts(c.Request.Context())
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotoSourcesFailed, err)
+		return
+	}
+
+	sources := []gin.H{}
+	for _, count := range counts {
+		sources = append(sources, gin.H{"source": count.Source, "device": count.Device, "photo_count": count.Count})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": sources})
+}
+
+// GetPhotoThumbnailHandler serve a miniatura JPEG da foto, gerando-a na primeira solicitação.
+// Com ?fast=true, uma foto sem miniatura recebe a prévia embutida no EXIF, se houver (ver X-Thumbnail-Preview).
+func (h *PhotoHandler) GetPhotoThumbnailHandler(c *gin.Context) {
+	photo, ok := h.loadPhoto(c)
+	if !ok {
+		return
+	}
+
+	// Com fast=true, enquanto a miniatura não existe, a prévia embutida no EXIF é servida na hora e a
+	// miniatura é gerada em segundo plano (útil durante grandes importações)
+	if c.Query("fast") == "true" && h.Thumbnails != nil && !h.Thumbnails.Ready(photo) && photo.PreviewPath != "" {
+		if _, err := os.Stat(photo.PreviewPath); err == nil {
+			h.Thumbnails.Enqueue(photo.ID)
+			c.Header("Cache-Control", "no-cache") // O cliente deve buscar de novo para obter a miniatura completa
+			c.Header("Content-Type", "image/jpeg")
+			c.Header("X-Thumbnail-Preview", "exif")
+			c.File(photo.PreviewPath)
 			return
 		}
-		filter.Limit = limit
 	}
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		offset, err := strconv.Atoi(offsetStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Offset inválido."})
+
+	thumbPath, ok := h.thumbnailPath(c, photo)
+	if !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Header("Content-Type", "image/jpeg")
+	c.File(thumbPath)
+}
+
+// thumbnailPath retorna o caminho da miniatura da foto, gerando-a se necessário. Responde com erro se a
+// miniatura não puder ser obtida.
+func (h *PhotoHandler) thumbnailPath(c *gin.Context, photo *database.Photo) (string, bool) {
+	var thumbPath string
+	var err error
+	if h.Thumbnails != nil {
+		thumbPath, err = h.Thumbnails.Get(c.Request.Context(), photo)
+	} else {
+		thumbPath, err = h.PhotoService.GetThumbnailPath(c.Request.Context(), photo)
+	}
+	if errors.Is(err, service.ErrThumbnailBusy) {
+		// Sobrecarga: a geração segue na fila e o cliente tenta de novo depois do Retry-After
+		retryAfter := int(h.Thumbnails.RetryAfter().Seconds())
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusAccepted, gin.H{
+			"code":        i18n.CodeThumbnailBusy,
+			"message":     message(c, i18n.CodeThumbnailBusy),
+			"retry_after": retryAfter,
+		})
+		return "", false
+	}
+	if errors.Is(err, service.ErrPhotoQuarantined) || errors.Is(err, thumbnail.ErrInvalidImage) || errors.Is(err, service.ErrNotImage) ||
+		errors.Is(err, thumbnail.ErrUnsupportedFormat) {
+		respondErrorCause(c, http.StatusUnprocessableEntity, i18n.CodeThumbnailUnavailable, err)
+		return "", false
+	}
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeThumbnailFailed, err)
+		return "", false
+	}
+	return thumbPath, true
+}
+
+// DetectProjectionsHandler reavalia a projeção (panorama, 360°) das fotos já cadastradas, pelos metadados
+// GPano e pela proporção, e descarta as miniaturas das que mudaram (POST /admin/photos/projections).
+func (h *PhotoHandler) DetectProjectionsHandler(c *gin.Context) {
+	report, err := h.PhotoService.DetectProjections(c.Request.Context())
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeProjectionDetectFailed, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"scanned": report.Scanned,
+		"updated": report.Updated,
+		"missing": report.Missing,
+	}})
+}
+
+// DetectAuxiliaryAssetsHandler procura mapas de profundidade, mapas de ganho HDR e máscaras de retrato nas
+// fotos já cadastradas (POST /admin/photos/auxiliary-assets).
+func (h *PhotoHandler) DetectAuxiliaryAssetsHandler(c *gin.Context) {
+	report, err := h.PhotoService.DetectAuxiliaryAssets(c.Request.Context())
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAuxiliaryDetectFailed, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"scanned": report.Scanned,
+		"updated": report.Updated,
+		"missing": report.Missing,
+	}})
+}
+
+// GetPhotoExifHandler retorna todos os metadados EXIF e IPTC da foto, e não só os campos guardados no banco.
+// "source" informa se foram lidos do original guardado ("original") ou da cópia armazenada ("stored").
+func (h *PhotoHandler) GetPhotoExifHandler(c *gin.Context) {
+	photo, ok := h.loadPhoto(c)
+	if !ok {
+		return
+	}
+
+	metadata, err := h.PhotoService.PhotoMetadata(c.Request.Context(), photo)
+	if errors.Is(err, service.ErrMetadataVideo) {
+		respondError(c, http.StatusUnprocessableEntity, i18n.CodePhotoExifVideo)
+		return
+	}
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotoExifFailed, err)
+		return
+	}
+
+	source := "stored"
+	if metadata.FromOriginal {
+		source = "original"
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"photo_id":  photo.ID,
+		"source":    source,
+		"cached":    metadata.Cached,
+		"parsed_at": metadata.ParsedAt.Format(time.RFC3339),
+		"exif":      metadata.Exif,
+		"iptc":      metadata.IPTC,
+	}})
+}
+
+// GetPhotosByHashHandler retorna as fotos com o hash informado: o MD5 do arquivo (campo "hash") ou o
+// SHA-256 dos pixels, que também encontra a mesma imagem regravada com outros metadados (disponível para as
+// fotos já analisadas pelo relatório de duplicatas). Responde 404 se nenhuma foto tiver o hash.
+func (h *PhotoHandler) GetPhotosByHashHandler(c *gin.Context) {
+	hash := strings.ToLower(strings.TrimSpace(c.Param("hash")))
+	if service.HashKind(hash) == "" {
+		respondError(c, http.StatusBadRequest, i18n.CodeHashInvalid, c.Param("hash"))
+		return
+	}
+
+	matches, err := h.PhotoService.FindByHashes(c.Request.Context(), []string{hash})
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeHashLookupFailed, err)
+		return
+	}
+	if len(matches[hash]) == 0 {
+		respondError(c, http.StatusNotFound, i18n.CodeHashNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": hashMatchesResponse(matches[hash])})
+}
+
+// GetPhotosByHashesHandler busca vários hashes de uma vez ({"hashes": [...]}, até service.MaxHashLookup),
+// para ferramentas externas verificarem quais arquivos já estão na biblioteca. Responde com as fotos de
+// cada hash encontrado em "found" e os hashes sem foto em "missing".
+func (h *PhotoHandler) GetPhotosByHashesHandler(c *gin.Context) {
+	var req struct {
+		Hashes []string `json:"hashes" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Hashes) > service.MaxHashLookup {
+		respondError(c, http.StatusBadRequest, i18n.CodeHashesInvalid, service.MaxHashLookup)
+		return
+	}
+
+	hashes := make([]string, 0, len(req.Hashes))
+	for _, raw := range req.Hashes {
+		hash := strings.ToLower(strings.TrimSpace(raw))
+		if service.HashKind(hash) == "" {
+			respondError(c, http.StatusBadRequest, i18n.CodeHashInvalid, raw)
 			return
 		}
-		filter.Offset = offset
+		if !slices.Contains(hashes, hash) {
+			hashes = append(hashes, hash)
+		}
 	}
-	filter.OrderBy = c.Query("order_by")
 
-	photos, err := h.PhotoService.GetPhotos(filter)
+	matches, err := h.PhotoService.FindByHashes(c.Request.Context(), hashes)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Erro ao buscar fotos: %v", err)})
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeHashLookupFailed, err)
 		return
 	}
 
-	// Transformar as fotos para um formato de resposta mais amigável, se necessário
-	// Por exemplo, formatar datas, incluir URLs de acesso, etc.
-	responsePhotos := []gin.H{}
-	for _, photo := range photos {
-		responsePhotos = append(responsePhotos, gin.H{
-			"id":          photo.ID,
-			"filename":    photo.Filename,
-			"stored_path": photo.StoredPath,
-			"upload_date": photo.UploadDate.Format(time.RFC3339),
-			"exif_date": func() string {
-				if photo.ExifDate != nil {
-					return photo.ExifDate.Format(time.RFC3339)
-				}
-				return ""
-			}(),
-			"hash":           photo.Hash,
-			"file_size":      photo.FileSize,
-			"mime_type":      photo.MimeType,
-			"width":          photo.Width,
-			"height":         photo.Height,
-			"description":    photo.Description,
-			"tags":           photo.Tags,
-			"thumbnail_path": photo.ThumbnailPath, // Incluir se houver miniaturas
+	found := gin.H{}
+	missing := []string{}
+	for _, hash := range hashes {
+		if len(matches[hash]) == 0 {
+			missing = append(missing, hash)
+			continue
+		}
+		found[hash] = hashMatchesResponse(matches[hash])
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"found": found, "missing": missing}})
+}
+
+// hashMatchesResponse formata as fotos encontradas por hash, apenas com os campos úteis para comparação.
+func hashMatchesResponse(matches []service.HashMatch) []gin.H {
+	response := []gin.H{}
+	for _, match := range matches {
+		response = append(response, gin.H{
+			"id":          match.Photo.ID,
+			"filename":    match.Photo.Filename,
+			"stored_path": match.Photo.StoredPath,
+			"file_size":   match.Photo.FileSize,
+			"hash":        match.Photo.Hash,
+			"pixel_hash":  match.Photo.PixelHash,
+			"match":       match.Kind,
 		})
 	}
+	return response
+}
 
-	c.JSON(http.StatusOK, gin.H{"data": responsePhotos})
+// SetFavoriteHandler marca ou desmarca uma foto como favorita.
+func (h *PhotoHandler) SetFavoriteHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Favorite *bool  `json:"favorite" binding:"required"`
+		Version  string `json:"version"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldRequired, "favorite")
+		return
+	}
+
+	photo, err := h.PhotoService.SetFavorite(c.Request.Context(), id, *req.Favorite, expectedPhotoVersion(c, req.Version))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+			return
+		}
+		if respondPhotoVersionConflict(c, err) {
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotoUpdateFailed, err)
+		return
+	}
+
+	setPhotoETag(c, *photo)
+	c.JSON(http.StatusOK, gin.H{"data": photoResponse(*photo)})
+}
+
+// SetHiddenHandler oculta ou volta a exibir uma foto na linha do tempo e nas buscas.
+func (h *PhotoHandler) SetHiddenHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Hidden  *bool  `json:"hidden" binding:"required"`
+		Version string `json:"version"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldRequired, "hidden")
+		return
+	}
+
+	photo, err := h.PhotoService.SetHidden(c.Request.Context(), id, *req.Hidden, expectedPhotoVersion(c, req.Version))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+			return
+		}
+		if respondPhotoVersionConflict(c, err) {
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotoUpdateFailed, err)
+		return
+	}
+
+	setPhotoETag(c, *photo)
+	c.JSON(http.StatusOK, gin.H{"data": photoResponse(*photo)})
+}
+
+// SetTimelineVisibilityHandler tira fotos da linha do tempo ou as devolve a ela, em lote:
+// {"photo_ids": [1, 2], "hide_from_timeline": true}. As fotos continuam nos álbuns e nas buscas, o que
+// atende imagens utilitárias (recibos, quadros brancos) guardadas em álbuns próprios.
+func (h *PhotoHandler) SetTimelineVisibilityHandler(c *gin.Context) {
+	var req struct {
+		PhotoIDs         []uint `json:"photo_ids" binding:"required,min=1"`
+		HideFromTimeline *bool  `json:"hide_from_timeline" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldsRequired, "'photo_ids', 'hide_from_timeline'")
+		return
+	}
+
+	updated, err := h.PhotoService.SetTimelineHidden(c.Request.Context(), req.PhotoIDs, *req.HideFromTimeline)
+	if err != nil {
+		if i18n.Code(err) == i18n.CodePhotosNotFound {
+			respondError(c, http.StatusNotFound, i18n.CodePhotosNotFound)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotoUpdateFailed, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"updated": updated}})
+}
+
+// SetCircaDateHandler grava a data aproximada de fotos sem data confiável, como fotos analógicas
+// digitalizadas (PUT /photos/circa-date), ex: {"photo_ids": [1, 2], "circa_date": "1980s"}. Formatos:
+// "1985-03" (mês), "1985" (ano) ou "1980s" (década); "" remove a data aproximada. A data aproximada prevalece
+// sobre as datas EXIF e de upload na linha do tempo e nos filtros por ano e mês. Fotos bloqueadas são ignoradas.
+func (h *PhotoHandler) SetCircaDateHandler(c *gin.Context) {
+	var req struct {
+		PhotoIDs  []uint  `json:"photo_ids" binding:"required,min=1"`
+		CircaDate *string `json:"circa_date" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldsRequired, "'photo_ids', 'circa_date'")
+		return
+	}
+
+	updated, locked, err := h.PhotoService.SetCircaDate(c.Request.Context(), req.PhotoIDs, *req.CircaDate)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrCircaDateInvalid):
+			respondError(c, http.StatusBadRequest, i18n.CodeCircaDateInvalid)
+		case i18n.Code(err) == i18n.CodePhotosNotFound:
+			respondError(c, http.StatusNotFound, i18n.CodePhotosNotFound)
+		default:
+			respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotoUpdateFailed, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"updated": updated, "skipped_locked": locked}})
+}
+
+// LockPhotoHandler bloqueia a foto contra alterações e remoção.
+func (h *PhotoHandler) LockPhotoHandler(c *gin.Context) {
+	h.setLocked(c, true)
+}
+
+// UnlockPhotoHandler desbloqueia a foto. A rota é separada do bloqueio para poder ser restrita ao administrador.
+func (h *PhotoHandler) UnlockPhotoHandler(c *gin.Context) {
+	h.setLocked(c, false)
+}
+
+// setLocked aplica o bloqueio ou desbloqueio da foto da rota.
+func (h *PhotoHandler) setLocked(c *gin.Context, locked bool) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+
+	photo, err := h.PhotoService.SetLocked(c.Request.Context(), id, locked)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotoUpdateFailed, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": photoResponse(*photo)})
 }
 
 // GetPhotosTimelineHandler retorna fotos organizadas por ano e mês.
+// Com ?fields=minimal cada foto traz apenas id, URL da miniatura, data e dimensões, reduzindo bastante o
+// tamanho da resposta para renderização de grades (ex: em dispositivos móveis); ?fields= também aceita
+// uma lista de campos, como as demais listagens.
+// Fotos ocultas e as tiradas da linha do tempo só aparecem com ?include_hidden=true.
+// O mês de cada foto é o da sua data no fuso padrão (TIMELINE_TIMEZONE) ou no informado em ?tz=: um nome IANA
+// (ex: America/Sao_Paulo), local (fuso do servidor) ou photo (fuso em que cada foto foi tirada, quando conhecido).
+// Fotos com data aproximada (circa_date) de ano ou década aparecem no mês "00" do ano (ou do primeiro ano da
+// década), para que a interface as mostre à parte, sem uma data exata inventada.
 func (h *PhotoHandler) GetPhotosTimelineHandler(c *gin.Context) {
-	limitPerMonthStr := c.DefaultQuery("limit_per_month", "0") // Default 0 means no limit
-	limitPerMonth, err := strconv.Atoi(limitPerMonthStr)
+	var query struct {
+		LimitPerMonth int    `form:"limit_per_month" binding:"min=0"` // 0 = sem limite
+		IncludeHidden bool   `form:"include_hidden"`
+		TimeZone      string `form:"tz"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+	zone, err := service.ParseTimelineZone(query.TimeZone, h.PhotoService.Timeline)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Limite por mês inválido."})
+		respondServiceError(c, http.StatusBadRequest, err, i18n.CodeInvalidTimeZone)
+		return
+	}
+	serialize, ok := photoFieldsSerializer(c)
+	if !ok {
 		return
 	}
 
-	timeline, err := h.PhotoService.GetPhotosByTimeline(limitPerMonth)
+	timeline, err := h.PhotoService.GetPhotosByTimeline(c.Request.Context(), query.LimitPerMonth, query.IncludeHidden, zone)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Erro ao buscar linha do tempo: %v", err)})
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeTimelineFailed, err)
 		return
 	}
 
@@ -190,26 +861,7 @@ func (h *PhotoHandler) GetPhotosTimelineHandler(c *gin.Context) {
 			monthStr := fmt.Sprintf("%02d", month) // Formatar mês com dois dígitos
 			photoList := []gin.H{}
 			for _, photo := range photos {
-				photoList = append(photoList, gin.H{
-					"id":          photo.ID,
-					"filename":    photo.Filename,
-					"stored_path": photo.StoredPath,
-					"upload_date": photo.UploadDate.Format(time.RFC3339),
-					"exif_date": func() string {
-						if photo.ExifDate != nil {
-							return photo.ExifDate.Format(time.RFC3339)
-						}
-						return ""
-					}(),
-					"hash":           photo.Hash,
-					"file_size":      photo.FileSize,
-					"mime_type":      photo.MimeType,
-					"width":          photo.Width,
-					"height":         photo.Height,
-					"description":    photo.Description,
-					"tags":           photo.Tags,
-					"thumbnail_path": photo.ThumbnailPath,
-				})
+				photoList = append(photoList, serialize(photo))
 			}
 			responseTimeline[yearStr].(gin.H)[monthStr] = photoList
 		}
@@ -217,3 +869,497 @@ func (h *PhotoHandler) GetPhotosTimelineHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"data": responseTimeline})
 }
+
+// GetPhotoHandler retorna os detalhes de uma foto, com as anotações de regiões.
+func (h *PhotoHandler) GetPhotoHandler(c *gin.Context) {
+	photo, ok := h.loadPhoto(c)
+	if !ok {
+		return
+	}
+
+	h.AccessStats.RecordView(photo.ID)
+	h.AccessStats.ApplyPending(photo)
+	response := photoResponse(*photo)
+	if h.Annotations != nil {
+		annotations, err := h.Annotations.ListAnnotations(c.Request.Context(), photo.ID)
+		if err != nil {
+			respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAnnotationsFetchFailed, err)
+			return
+		}
+		response["annotations"] = annotationsResponse(annotations)
+	}
+	setPhotoETag(c, *photo)
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// GetPhotoFileHandler serve o arquivo original da foto.
+// Fotos indexadas no local são servidas (somente leitura) a partir do caminho original.
+// Para fotos reduzidas na ingestão, ?original=true serve o original em resolução completa, se tiver sido guardado.
+// Originais que os navegadores não exibem (HEIC, TIFF, RAW) são servidos convertidos para WebP ou JPEG,
+// conforme o Accept, a menos que o cliente aceite o formato explicitamente ou peça ?original=true.
+func (h *PhotoHandler) GetPhotoFileHandler(c *gin.Context) {
+	photo, ok := h.loadPhoto(c)
+	if !ok {
+		return
+	}
+
+	if c.Query("original") == "true" && photo.Downscaled {
+		if photo.OriginalPath == "" {
+			respondError(c, http.StatusNotFound, i18n.CodeOriginalNotStored)
+			return
+		}
+		h.recordAccess(c, photo, h.AccessStats.RecordDownload)
+		c.Header("Content-Type", photo.MimeType)
+		c.File(photo.OriginalPath)
+		return
+	}
+
+	if !h.checkFileAvailable(c, photo) {
+		return
+	}
+
+	h.recordAccess(c, photo, h.AccessStats.RecordDownload)
+	if c.Query("original") != "true" && !video.IsVideo(photo.MimeType) && !thumbnail.BrowserCompatible(photo.MimeType) {
+		c.Header("Vary", "Accept")
+		// Clientes que declaram aceitar o formato (ex: Safari com image/heic) recebem o original
+		if !acceptsMediaType(c.GetHeader("Accept"), photo.MimeType) && h.serveRendition(c, photo) {
+			return
+		}
+	}
+	c.Header("Content-Type", photo.MimeType)
+	c.File(photo.StoredPath)
+}
+
+// serveRendition serve uma versão em WebP (se o cliente aceitar) ou JPEG de um original em HEIC, TIFF ou
+// RAW. Sem conversor externo, serve a prévia embutida pela câmera, se houver. Retorna false quando não há
+// versão convertida disponível, para que o original seja servido como antes.
+func (h *PhotoHandler) serveRendition(c *gin.Context, photo *database.Photo) bool {
+	format := thumbnail.RenditionJPEG
+	if acceptsMediaType(c.GetHeader("Accept"), "image/webp") {
+		format = thumbnail.RenditionWebP
+	}
+
+	path, err := h.PhotoService.GetRendition(c.Request.Context(), photo, format)
+	if errors.Is(err, thumbnail.ErrUnsupportedFormat) && photo.PreviewPath != "" {
+		if _, statErr := os.Stat(photo.PreviewPath); statErr == nil {
+			path, format, err = photo.PreviewPath, "exif-preview", nil
+		}
+	}
+	if err != nil {
+		if !errors.Is(err, thumbnail.ErrUnsupportedFormat) {
+			log.Printf("Aviso: não foi possível converter a foto %d para exibição: %v\n", photo.ID, err)
+		}
+		return false
+	}
+
+	c.Header("Content-Type", thumbnail.RenditionContentType(format))
+	c.Header("X-Rendition", format)
+	c.File(path)
+	return true
+}
+
+// acceptsMediaType indica se o cabeçalho Accept lista explicitamente o tipo (curingas como */* e image/*
+// não contam, pois os navegadores os enviam mesmo sem exibir HEIC ou TIFF).
+func acceptsMediaType(accept, mediaType string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), mediaType) {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// GetPhotoStreamHandler reproduz um vídeo. Contêineres suportados pelos navegadores (MP4, WebM) são servidos
+// diretamente, com suporte a requisições Range. Os demais, ou qualquer vídeo com ?format=hls, são redirecionados
+// para a playlist HLS transcodificada para H.264.
+func (h *PhotoHandler) GetPhotoStreamHandler(c *gin.Context) {
+	photo, ok := h.loadPhoto(c)
+	if !ok {
+		return
+	}
+	if !video.IsVideo(photo.MimeType) {
+		respondError(c, http.StatusUnsupportedMediaType, i18n.CodePhotoNotVideo)
+		return
+	}
+
+	if c.Query("format") != "hls" && video.IsBrowserCompatible(photo.MimeType) {
+		if !h.checkFileAvailable(c, photo) {
+			return
+		}
+		h.recordAccess(c, photo, h.AccessStats.RecordView)
+		c.Header("Content-Type", photo.MimeType)
+		c.File(photo.StoredPath) // http.ServeContent trata Range/If-Range
+		return
+	}
+
+	if h.PhotoService.Transcoder == nil {
+		respondError(c, http.StatusUnsupportedMediaType, i18n.CodeVideoUnsupported)
+		return
+	}
+	h.AccessStats.RecordView(photo.ID) // A playlist e os segmentos não contam como novas reproduções
+	c.Redirect(http.StatusFound, fmt.Sprintf("/photos/%d/stream/%s", photo.ID, video.PlaylistName))
+}
+
+// recordAccess registra o acesso ao arquivo da foto, exceto nas requisições Range que continuam um
+// download ou reprodução já iniciado (ex: o navegador buscando o restante de um vídeo).
+func (h *PhotoHandler) recordAccess(c *gin.Context, photo *database.Photo, record func(uint)) {
+	if rangeHeader := c.GetHeader("Range"); rangeHeader != "" && !strings.HasPrefix(rangeHeader, "bytes=0-") {
+		return
+	}
+	record(photo.ID)
+}
+
+// GetPhotoStreamFileHandler serve a playlist HLS ou um segmento do vídeo transcodificado.
+// A primeira solicitação inicia a transcodificação em segundo plano; enquanto ela não termina, a resposta é
+// 202 com Retry-After, e as seguintes usam o cache.
+func (h *PhotoHandler) GetPhotoStreamFileHandler(c *gin.Context) {
+	photo, ok := h.loadPhoto(c)
+	if !ok {
+		return
+	}
+	if !h.checkFileAvailable(c, photo) {
+		return
+	}
+
+	path, err := h.PhotoService.GetStreamFile(c.Request.Context(), photo, c.Param("file"))
+	switch {
+	case errors.Is(err, service.ErrNotVideo):
+		respondError(c, http.StatusUnsupportedMediaType, i18n.CodePhotoNotVideo)
+		return
+	case errors.Is(err, service.ErrTranscodingDisabled):
+		respondError(c, http.StatusUnsupportedMediaType, i18n.CodeTranscodingDisabled)
+		return
+	case errors.Is(err, video.ErrInvalidSegment):
+		respondError(c, http.StatusBadRequest, i18n.CodeInvalidHLSName)
+		return
+	case errors.Is(err, video.ErrTranscodePending):
+		retryAfter := int(video.PendingRetryAfter.Seconds())
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusAccepted, gin.H{
+			"code":        i18n.CodeTranscodingPending,
+			"message":     message(c, i18n.CodeTranscodingPending),
+			"retry_after": retryAfter,
+		})
+		return
+	case err != nil:
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeTranscodingFailed, err)
+		return
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		respondError(c, http.StatusNotFound, i18n.CodeSegmentNotFound)
+		return
+	}
+	if c.Param("file") == video.PlaylistName {
+		c.Header("Content-Type", "application/vnd.apple.mpegurl")
+	} else {
+		c.Header("Content-Type", "video/mp2t")
+	}
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.File(path)
+}
+
+// checkFileAvailable verifica se o arquivo original da foto está acessível, escrevendo a resposta de erro
+// (503 para volume offline, 404 para arquivo ausente) quando não estiver.
+func (h *PhotoHandler) checkFileAvailable(c *gin.Context, photo *database.Photo) bool {
+	if _, err := os.Stat(photo.StoredPath); err != nil {
+		// Se o volume onde a foto está não estiver acessível (ex: disco desmontado), informa indisponibilidade temporária
+		if !photo.ManagedExternally && !h.PhotoService.FileManager.IsVolumeOnline(photo.Volume) {
+			respondError(c, http.StatusServiceUnavailable, i18n.CodeVolumeOffline, photo.Volume)
+			return false
+		}
+		respondError(c, http.StatusNotFound, i18n.CodePhotoFileMissing)
+		return false
+	}
+	return true
+}
+
+// DeletePhotoHandler remove uma foto da biblioteca.
+// Para fotos no armazenamento gerenciado o arquivo é sempre apagado. Para fotos indexadas no local,
+// por padrão apenas a entrada do índice é removida; use ?delete_file=true para apagar também o arquivo original.
+func (h *PhotoHandler) DeletePhotoHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeInvalidPhotoID)
+		return
+	}
+
+	var query struct {
+		DeleteFile bool `form:"delete_file"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	if err := h.PhotoService.DeletePhoto(c.Request.Context(), uint(id), query.DeleteFile); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrPhotoLocked) {
+			respondError(c, http.StatusLocked, i18n.CodePhotoLocked)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotoDeleteFailed, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message(c, i18n.CodePhotoDeleted)})
+}
+
+// photoFilterQuery são os critérios de filtro comuns às buscas de fotos, lidos da query string.
+type photoFilterQuery struct {
+	Year          int    `form:"year" binding:"required_with=Month,omitempty,min=1,max=9999"`
+	Month         int    `form:"month" binding:"omitempty,min=1,max=12"`
+	Filename      string `form:"filename"`
+	Tag           string `form:"tag"`
+	AlbumID       uint   `form:"album_id"`
+	Favorites     bool   `form:"favorites"`
+	Quarantined   bool   `form:"quarantined"`
+	IncludeHidden bool   `form:"include_hidden"`
+	Source        string `form:"source" binding:"omitempty,oneof=upload sync email share_upload import mirror"`
+	Device        string `form:"device"`
+	// Exclusões e fotos desorganizadas
+	ExcludeTags    []string `form:"exclude_tag"`
+	ExcludeAlbumID uint     `form:"exclude_album_id"`
+	ExcludeSource  string   `form:"exclude_source" binding:"omitempty,oneof=upload sync email share_upload import mirror"`
+	Untagged       bool     `form:"untagged"`
+	NoExifDate     bool     `form:"no_exif_date"`
+	NoGPS          bool     `form:"no_gps"`
+	NotInAlbum     bool     `form:"not_in_album"`
+	// Panoramas: any (qualquer um), equirectangular (360°), cylindrical ou panorama (plano)
+	Projection string `form:"projection" binding:"omitempty,oneof=any equirectangular cylindrical panorama"`
+	// Fuso dos limites de year e month: nome IANA, local (servidor) ou photo (fuso de cada foto)
+	TimeZone string `form:"tz" binding:"omitempty,oneof=photo local|timezone"`
+}
+
+// filter converte os parâmetros para o filtro do serviço.
+func (q photoFilterQuery) filter() service.PhotoFilter {
+	return service.PhotoFilter{
+		Year:            q.Year,
+		Month:           q.Month,
+		Filename:        q.Filename,
+		Tag:             q.Tag,
+		AlbumID:         q.AlbumID,
+		FavoritesOnly:   q.Favorites,
+		QuarantinedOnly: q.Quarantined,
+		IncludeHidden:   q.IncludeHidden,
+		Source:          q.Source,
+		Device:          q.Device,
+		ExcludeTags:     q.ExcludeTags,
+		ExcludeAlbumID:  q.ExcludeAlbumID,
+		ExcludeSource:   q.ExcludeSource,
+		Tagged:          absentIf(q.Untagged),
+		Dated:           absentIf(q.NoExifDate),
+		GPS:             absentIf(q.NoGPS),
+		InAlbum:         absentIf(q.NotInAlbum),
+		Projection:      q.Projection,
+		TimeZone:        q.TimeZone,
+	}
+}
+
+// absentIf retorna o critério "sem" (ponteiro para false) quando o parâmetro booleano foi ativado, ou nil
+// (indiferente) caso contrário.
+func absentIf(enabled bool) *bool {
+	if !enabled {
+		return nil
+	}
+	present := false
+	return &present
+}
+
+// parsePhotoFilter extrai da query string os critérios de filtro comuns às buscas de fotos
+// (year, month, filename, tag, album_id, favorites, quarantined, include_hidden, source, device, exclude_tag,
+// exclude_album_id, exclude_source, untagged, no_exif_date, no_gps, not_in_album, projection, tz). Responde 422 e retorna
+// false se algum for inválido.
+func parsePhotoFilter(c *gin.Context) (service.PhotoFilter, bool) {
+	var query photoFilterQuery
+	if !bindQuery(c, &query) {
+		return service.PhotoFilter{}, false
+	}
+	return query.filter(), true
+}
+
+// isStorageUnavailable indica se o erro decorre da falta de espaço ou de volumes de armazenamento.
+func isStorageUnavailable(err error) bool {
+	return errors.Is(err, storage.ErrInsufficientStorage) || errors.Is(err, storage.ErrNoVolumeAvailable)
+}
+
+// storageErrorStatus mapeia erros de armazenamento para o status HTTP correspondente:
+// 507 Insufficient Storage quando falta espaço, 503 quando nenhum volume está online.
+func storageErrorStatus(err error) int {
+	if errors.Is(err, storage.ErrInsufficientStorage) {
+		return http.StatusInsufficientStorage
+	}
+	return http.StatusServiceUnavailable
+}
+
+// respondStorageError responde a um erro de armazenamento com o status HTTP adequado.
+func respondStorageError(c *gin.Context, err error) {
+	respondErrorCause(c, storageErrorStatus(err), i18n.CodeStorageUnavailable, err)
+}
+
+// loadPhoto busca a foto indicada pelo parâmetro :id, escrevendo a resposta de erro quando necessário.
+func (h *PhotoHandler) loadPhoto(c *gin.Context) (*database.Photo, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeInvalidPhotoID)
+		return nil, false
+	}
+	return h.loadPhotoByID(c, uint(id))
+}
+
+// loadPhotoByID busca a foto informada, respondendo 404 se ela não existir.
+func (h *PhotoHandler) loadPhotoByID(c *gin.Context, id uint) (*database.Photo, bool) {
+	photo, err := h.PhotoService.GetPhotoByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+			return nil, false
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotoFetchFailed, err)
+		return nil, false
+	}
+
+	return photo, true
+}
+
+// photoResponse formata uma foto para as respostas da API.
+func photoResponse(photo database.Photo) gin.H {
+	return gin.H{
+		"id":          photo.ID,
+		"public_id":   photo.PublicID,
+		"filename":    photo.Filename,
+		"stored_path": photo.StoredPath,
+		"upload_date": photo.UploadDate.Format(time.RFC3339),
+		"exif_date": func() string {
+			if photo.ExifDate != nil {
+				return photo.ExifDate.Format(time.RFC3339)
+			}
+			return ""
+		}(),
+		"hash":               photo.Hash,
+		"file_size":          photo.FileSize,
+		"mime_type":          photo.MimeType,
+		"width":              photo.Width,
+		"height":             photo.Height,
+		"description":        photo.Description,
+		"tags":               photo.Tags,
+		"favorite":           photo.Favorite,
+		"hidden":             photo.Hidden,
+		"hide_from_timeline": photo.HideFromTimeline,
+		"locked":             photo.Locked,
+		"latitude":           photo.Latitude,
+		"longitude":          photo.Longitude,
+		"camera_make":        photo.CameraMake,
+		"camera_model":       photo.CameraModel,
+		"lens_model":         photo.LensModel,
+		"focal_length":       photo.FocalLength,
+		"iso":                photo.ISO,
+		"thumbnail_path":     photo.ThumbnailPath, // Incluir se houver miniaturas
+		"has_preview":        photo.PreviewPath != "",
+		"thumbnail_url":      thumbnailURL(photo),
+		"managed_externally": photo.ManagedExternally,
+		"volume":             photo.Volume,
+		"quarantined":        photo.Quarantined,
+		"downscaled":         photo.Downscaled,
+		"has_original":       photo.OriginalPath != "",
+		"stream_url":         streamURL(photo),
+		"view_count":         photo.ViewCount,
+		"download_count":     photo.DownloadCount,
+		"source":             photo.Source,
+		"source_device":      photo.SourceDevice,
+		"source_detail":      photo.SourceDetail,
+		"projection":         photo.Projection,
+		"panorama":           panoramaResponse(photo),
+		"has_depth_map":      photo.HasDepthMap,
+		"has_gain_map":       photo.HasGainMap,
+		"has_portrait_matte": photo.HasPortraitMatte,
+		"time_zone_offset":   photo.TimeZoneOffset, // Fuso da captura em minutos a leste de UTC (null se desconhecido)
+
+		// Data aproximada informada manualmente (ex: "1985-03", "1985", "1980s"; vazia se não houver)
+		"circa_date":      service.FormatCircaDate(photo),
+		"circa_precision": photo.CircaPrecision,
+
+		// Versão da foto, para as edições com If-Match ou "version" (ver service.PhotoVersion)
+		"version": service.PhotoVersion(photo),
+	}
+}
+
+// panoramaResponse retorna os dados para abrir a foto em um visualizador de panoramas (nil para fotos
+// comuns). As medidas seguem o formato GPano (full_width, cropped_x...) em pixels do arquivo servido:
+// se a foto foi reduzida na ingestão, as medidas declaradas pela câmera são convertidas na mesma escala.
+func panoramaResponse(photo database.Photo) gin.H {
+	if photo.Projection == "" {
+		return nil
+	}
+	fullWidth, fullHeight := photo.PanoFullWidth, photo.PanoFullHeight
+	croppedWidth, croppedHeight := photo.PanoCroppedWidth, photo.PanoCroppedHeight
+	croppedX, croppedY := photo.PanoCroppedLeft, photo.PanoCroppedTop
+	if croppedWidth > 0 && photo.Width > 0 && croppedWidth != photo.Width {
+		scale := float64(photo.Width) / float64(croppedWidth)
+		scaled := func(value int) int { return int(math.Round(float64(value) * scale)) }
+		fullWidth, fullHeight = scaled(fullWidth), scaled(fullHeight)
+		croppedWidth, croppedHeight = photo.Width, photo.Height
+		croppedX, croppedY = scaled(croppedX), scaled(croppedY)
+	}
+	if croppedWidth == 0 || croppedHeight == 0 {
+		croppedWidth, croppedHeight = photo.Width, photo.Height
+	}
+	if fullWidth == 0 || fullHeight == 0 {
+		fullWidth, fullHeight = croppedWidth, croppedHeight
+	}
+
+	return gin.H{
+		"projection":      photo.Projection,
+		"viewer_360":      photo.Projection == database.ProjectionEquirectangular,
+		"full_width":      fullWidth,
+		"full_height":     fullHeight,
+		"cropped_width":   croppedWidth,
+		"cropped_height":  croppedHeight,
+		"cropped_x":       croppedX,
+		"cropped_y":       croppedY,
+		"initial_heading": photo.PanoInitialHeading,
+	}
+}
+
+// streamURL retorna a URL de reprodução de um vídeo, ou vazio para imagens.
+func streamURL(photo database.Photo) string {
+	if !video.IsVideo(photo.MimeType) {
+		return ""
+	}
+	return fmt.Sprintf("/photos/%d/stream", photo.ID)
+}
+
+// photoMinimalResponse formata uma foto com apenas os campos necessários para renderizar uma grade.
+func photoMinimalResponse(photo database.Photo) gin.H {
+	date := photo.UploadDate
+	if photo.CircaDate != nil {
+		date = *photo.CircaDate
+	} else if photo.ExifDate != nil {
+		date = *photo.ExifDate
+	}
+
+	return gin.H{
+		"id":            photo.ID,
+		"public_id":     photo.PublicID,
+		"thumbnail_url": thumbnailURL(photo),
+		"date":          date.Format(time.RFC3339),
+		"circa_date":    service.FormatCircaDate(photo), // Com data aproximada, "date" é o início do período
+		"width":         photo.Width,
+		"height":        photo.Height,
+	}
+}
+
+// thumbnailURL retorna a URL da API que serve a miniatura da foto.
+func thumbnailURL(photo database.Photo) string {
+	return fmt.Sprintf("/photos/%d/thumbnail", photo.ID)
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PhotoRelationHandler gerencia as relações entre fotos (edição e RAW, quadros de panorama, digitalizações).
+type PhotoRelationHandler struct {
+	RelationService *service.PhotoRelationService
+}
+
+// NewPhotoRelationHandler cria uma nova instância de PhotoRelationHandler.
+func NewPhotoRelationHandler(s *service.PhotoRelationService) *PhotoRelationHandler {
+	return &PhotoRelationHandler{
+		RelationService: s,
+	}
+}
+
+// createRelationRequest liga a foto da rota a outra foto.
+type createRelationRequest struct {
+	ToPhotoID uint   `json:"to_photo_id" binding:"required"`
+	Kind      string `json:"kind" binding:"required"`
+	Note      string `json:"note"`
+}
+
+// relationKindQuery filtra as relações por tipo.
+type relationKindQuery struct {
+	Kind string `form:"kind"`
+}
+
+// CreateRelationHandler liga a foto da rota (origem) a outra foto (destino)
+// (POST /photos/:id/relations, corpo {"to_photo_id": 12, "kind": "edited_from", "note": "..."}).
+// A direção vai da foto derivada para a de referência: a edição aponta para o RAW, o quadro para o panorama.
+func (h *PhotoRelationHandler) CreateRelationHandler(c *gin.Context) {
+	photoID, ok := parseIDParam(c, "id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+	var req createRelationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeInvalidRequestBody, err)
+		return
+	}
+
+	relation, err := h.RelationService.CreateRelation(c.Request.Context(), photoID, req.ToPhotoID, req.Kind, req.Note)
+	if err != nil {
+		respondRelationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": photoRelationResponse(*relation)})
+}
+
+// DeleteRelationHandler remove uma relação da foto (DELETE /photos/:id/relations/:relation_id).
+func (h *PhotoRelationHandler) DeleteRelationHandler(c *gin.Context) {
+	photoID, ok := parseIDParam(c, "id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+	relationID, ok := parseIDParam(c, "relation_id", i18n.CodeRelationNotFound)
+	if !ok {
+		return
+	}
+
+	if err := h.RelationService.DeleteRelation(c.Request.Context(), photoID, relationID); err != nil {
+		respondRelationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message(c, i18n.CodeRelationRemoved)})
+}
+
+// ListRelationsHandler lista as relações diretas da foto nas duas direções (GET /photos/:id/relations).
+// Cada item traz o tipo armazenado (kind) e o nome da relação vista desta foto (label): a edição vê
+// "edited_from" e o RAW vê "edits". Aceita ?kind= para um só tipo.
+func (h *PhotoRelationHandler) ListRelationsHandler(c *gin.Context) {
+	photoID, ok := parseIDParam(c, "id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+	var query relationKindQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	related, err := h.RelationService.ListRelations(c.Request.Context(), photoID, query.Kind)
+	if err != nil {
+		respondRelationError(c, err)
+		return
+	}
+
+	items := make([]gin.H, 0, len(related))
+	for _, item := range related {
+		direction := "incoming"
+		if item.Outgoing {
+			direction = "outgoing"
+		}
+		items = append(items, gin.H{
+			"relation_id": item.RelationID,
+			"kind":        item.Kind,
+			"label":       item.Label,
+			"direction":   direction,
+			"note":        item.Note,
+			"photo":       photoResponse(item.Photo),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// RelationGraphHandler retorna o grupo de fotos ligadas à foto, seguindo as relações em qualquer
+// direção (GET /photos/:id/relations/graph). Com ?kind=panorama_part, reúne os quadros de um panorama.
+func (h *PhotoRelationHandler) RelationGraphHandler(c *gin.Context) {
+	photoID, ok := parseIDParam(c, "id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+	var query relationKindQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	graph, err := h.RelationService.RelationGraph(c.Request.Context(), photoID, query.Kind)
+	if err != nil {
+		respondRelationError(c, err)
+		return
+	}
+
+	photos := make([]gin.H, 0, len(graph.Photos))
+	for _, photo := range graph.Photos {
+		photos = append(photos, photoResponse(photo))
+	}
+	relations := make([]gin.H, 0, len(graph.Relations))
+	for _, relation := range graph.Relations {
+		relations = append(relations, photoRelationResponse(relation))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"photos":    photos,
+		"relations": relations,
+		"truncated": graph.Truncated,
+	}})
+}
+
+// respondRelationError traduz os erros de PhotoRelationService em respostas HTTP.
+func respondRelationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondError(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+	case errors.Is(err, service.ErrRelationNotFound):
+		respondServiceError(c, http.StatusNotFound, err, i18n.CodeRelationNotFound)
+	case i18n.Code(err) == i18n.CodePhotosNotFound:
+		respondServiceError(c, http.StatusNotFound, err, i18n.CodePhotosNotFound)
+	case errors.Is(err, service.ErrRelationExists):
+		respondServiceError(c, http.StatusConflict, err, i18n.CodeRelationExists)
+	case errors.Is(err, service.ErrRelationSelf), i18n.Code(err) == i18n.CodeRelationKindInvalid:
+		respondServiceError(c, http.StatusBadRequest, err, i18n.CodeRelationFailed)
+	case c.Request.Method == http.MethodGet:
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeRelationListFailed, err)
+	default:
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeRelationFailed, err)
+	}
+}
+
+// photoRelationResponse formata uma relação entre fotos.
+func photoRelationResponse(relation database.PhotoRelation) gin.H {
+	return gin.H{
+		"id":            relation.ID,
+		"from_photo_id": relation.FromPhotoID,
+		"to_photo_id":   relation.ToPhotoID,
+		"kind":          relation.Kind,
+		"note":          relation.Note,
+		"created_at":    relation.CreatedAt.Format(time.RFC3339),
+	}
+}
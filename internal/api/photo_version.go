@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setPhotoETag informa a versão da foto no cabeçalho ETag, para o cliente devolvê-la em If-Match.
+func setPhotoETag(c *gin.Context, photo database.Photo) {
+	c.Header("ETag", `"`+service.PhotoVersion(photo)+`"`)
+}
+
+// expectedPhotoVersion retorna a versão que o cliente espera alterar: o cabeçalho If-Match ou, na falta
+// dele, o campo "version" do corpo. Vazio (ou If-Match: *) dispensa a verificação.
+func expectedPhotoVersion(c *gin.Context, bodyVersion string) string {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return strings.TrimSpace(bodyVersion)
+	}
+	if header == "*" {
+		return ""
+	}
+	// Só uma versão é comparada; o prefixo de ETag fraco é aceito, pois a versão não depende da representação
+	header, _, _ = strings.Cut(header, ",")
+	return strings.Trim(strings.TrimPrefix(strings.TrimSpace(header), "W/"), `"`)
+}
+
+// respondPhotoVersionConflict responde 409 com o estado atual da foto (em "data") se err for um conflito
+// de versão.
+func respondPhotoVersionConflict(c *gin.Context, err error) bool {
+	var conflictErr *service.PhotoVersionConflictError
+	if !errors.As(err, &conflictErr) {
+		return false
+	}
+	body := errorBody(c, conflictErr.Localize(locale(c)), i18n.CodePhotoVersionConflict)
+	body["data"] = photoResponse(conflictErr.Current)
+	setPhotoETag(c, conflictErr.Current)
+	c.JSON(http.StatusConflict, body)
+	return true
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"math"
+	"net/http"
+
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// printCheckQuery são os parâmetros de GET /photos/:id/print-check.
+type printCheckQuery struct {
+	Size   string  `form:"size" binding:"required"`
+	MinDPI float64 `form:"min_dpi" binding:"omitempty,min=1,max=1200"`
+}
+
+// PrintCheckHandler verifica se a foto tem resolução suficiente para o tamanho de impressão pedido
+// (?size=10x15, em cm; aceita também 4x6in e 130x180mm) e sugere o recorte centralizado com a proporção
+// do papel, evitando impressões borradas ou cortes inesperados no laboratório. ?min_dpi= define a
+// resolução mínima aceita (padrão: 150).
+func (h *PhotoHandler) PrintCheckHandler(c *gin.Context) {
+	var query printCheckQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	size, err := service.ParsePrintSize(query.Size)
+	if err != nil {
+		respondServiceError(c, http.StatusBadRequest, err, i18n.CodePrintSizeInvalid)
+		return
+	}
+
+	photo, ok := h.loadPhoto(c)
+	if !ok {
+		return
+	}
+
+	check, err := h.PhotoService.CheckPrint(photo, size, query.MinDPI)
+	if err != nil {
+		// Vídeos (ErrNotImage) e imagens sem dimensões legíveis (ErrPrintCheckNoDimensions)
+		respondServiceError(c, http.StatusUnprocessableEntity, err, i18n.CodePrintCheckNoDimensions)
+		return
+	}
+
+	round := func(v float64) float64 { return math.Round(v*10) / 10 }
+	orientation := "portrait"
+	if check.Landscape {
+		orientation = "landscape"
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"photo_id":    photo.ID,
+		"size":        check.Size.String(),
+		"orientation": orientation,
+		"image":       gin.H{"width": check.ImageWidth, "height": check.ImageHeight},
+		"dpi":         math.Round(check.DPI),
+		"min_dpi":     check.MinDPI,
+		"quality":     check.Quality,
+		"printable":   check.Printable,
+		"suggested_crop": gin.H{
+			"x":      check.Crop.X,
+			"y":      check.Crop.Y,
+			"width":  check.Crop.Width,
+			"height": check.Crop.Height,
+		},
+		"cropped_percent": round(check.CroppedPct),
+		"max_size_cm": gin.H{
+			"width":  round(check.MaxSizeCM.WidthCM),
+			"height": round(check.MaxSizeCM.HeightCM),
+			"dpi":    service.PrintDPIExcellent,
+		},
+	}})
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/exif"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PrivacyHandler audita e remove metadados sensíveis (GPS, números de série, proprietário) do EXIF das fotos.
+type PrivacyHandler struct {
+	PhotoService *service.PhotoService
+}
+
+// NewPrivacyHandler cria uma nova instância de PrivacyHandler.
+func NewPrivacyHandler(s *service.PhotoService) *PrivacyHandler {
+	return &PrivacyHandler{
+		PhotoService: s,
+	}
+}
+
+// privacyAuditQuery são os parâmetros de GET /admin/privacy-audit.
+type privacyAuditQuery struct {
+	photoFilterQuery
+	Fields string `form:"fields"` // Grupos separados por vírgula (vazio = todos)
+}
+
+// AuditHandler lista as fotos com campos sensíveis no EXIF, ex: antes de compartilhar um álbum:
+// GET /admin/privacy-audit?album_id=3&fields=gps,serial. Aceita os mesmos filtros de GET /photos.
+func (h *PrivacyHandler) AuditHandler(c *gin.Context) {
+	var query privacyAuditQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	var fields []string
+	if query.Fields != "" {
+		for _, field := range strings.Split(query.Fields, ",") {
+			field = strings.TrimSpace(field)
+			if !exif.ValidPrivacyField(field) {
+				respondInvalidParam(c, "fields", message(c, i18n.CodeParamOneOf, strings.Join(exif.PrivacyFields, ", ")))
+				return
+			}
+			fields = append(fields, field)
+		}
+	}
+
+	findings, scanned, err := h.PhotoService.AuditPrivacy(c.Request.Context(), query.filter(), fields)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePrivacyAuditFailed, err)
+		return
+	}
+
+	responseFindings := []gin.H{}
+	for _, finding := range findings {
+		responseFindings = append(responseFindings, gin.H{
+			"photo_id":          finding.Photo.ID,
+			"filename":          finding.Photo.Filename,
+			"thumbnail_url":     thumbnailURL(finding.Photo),
+			"fields":            finding.Report.Fields,
+			"has_gps":           finding.Report.HasGPS,
+			"body_serial":       finding.Report.BodySerial,
+			"lens_serial":       finding.Report.LensSerial,
+			"camera_owner_name": finding.Report.CameraOwnerName,
+			"artist":            finding.Report.Artist,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": responseFindings, "scanned": scanned, "flagged": len(findings)})
+}
+
+// StripHandler remove os grupos de campos das cópias armazenadas das fotos:
+// {"photo_ids": [1, 2], "fields": ["gps", "serial", "owner"]}. O resultado é informado por foto.
+func (h *PrivacyHandler) StripHandler(c *gin.Context) {
+	var req struct {
+		PhotoIDs []uint   `json:"photo_ids" binding:"required,min=1"`
+		Fields   []string `json:"fields" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldsRequired, "'photo_ids', 'fields'")
+		return
+	}
+	for _, field := range req.Fields {
+		if !exif.ValidPrivacyField(field) {
+			respondError(c, http.StatusBadRequest, i18n.CodePrivacyFieldsInvalid, field)
+			return
+		}
+	}
+
+	results := h.PhotoService.StripPrivacyFields(c.Request.Context(), req.PhotoIDs, req.Fields)
+
+	responseResults := []gin.H{}
+	stripped, failed := 0, 0
+	for _, result := range results {
+		item := gin.H{"photo_id": result.PhotoID, "stripped": result.Stripped}
+		switch {
+		case errors.Is(result.Err, gorm.ErrRecordNotFound):
+			item["error"], item["code"] = message(c, i18n.CodePhotoNotFound), i18n.CodePhotoNotFound
+		case result.Err != nil:
+			item["error"], item["code"] = i18n.Localize(result.Err, locale(c)), i18n.Code(result.Err)
+		}
+		if result.Err != nil {
+			failed++
+		} else if len(result.Stripped) > 0 {
+			stripped++
+		}
+		if result.Stripped == nil {
+			item["stripped"] = []string{}
+		}
+		responseResults = append(responseResults, item)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": responseResults, "stripped": stripped, "failed": failed})
+}
@@ -0,0 +1,351 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"photo-manager/internal/thumbnail"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PublicGalleryHandler serve a galeria pública: os álbuns publicados em PUBLIC_GALLERY_ALBUMS, sem token e
+// somente para leitura, como um portfólio. Só são servidas cópias reduzidas das fotos, nunca os originais.
+type PublicGalleryHandler struct {
+	Gallery     *service.PublicGalleryService
+	Photos      *PhotoHandler // Gera as miniaturas, com a mesma fila das demais rotas
+	ImageSize   int           // Maior lado das imagens servidas
+	CacheMaxAge time.Duration // max-age do Cache-Control
+}
+
+// NewPublicGalleryHandler cria uma nova instância de PublicGalleryHandler.
+func NewPublicGalleryHandler(s *service.PublicGalleryService, photos *PhotoHandler, imageSize int, cacheMaxAge time.Duration) *PublicGalleryHandler {
+	return &PublicGalleryHandler{
+		Gallery:     s,
+		Photos:      photos,
+		ImageSize:   imageSize,
+		CacheMaxAge: cacheMaxAge,
+	}
+}
+
+// galleryIndexTemplate é a página inicial da galeria, com a capa de cada álbum.
+var galleryIndexTemplate = template.Must(template.New("gallery-index").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Galeria</title>
+<style>
+body { font-family: sans-serif; margin: 2rem; }
+.grid { display: flex; flex-wrap: wrap; gap: 16px; }
+.grid a { color: inherit; text-decoration: none; }
+.grid img { height: 200px; display: block; }
+</style>
+</head>
+<body>
+<div class="grid">
+{{- range .}}
+<a href="{{.URL}}">{{if .CoverURL}}<img src="{{.CoverURL}}" loading="lazy" alt="">{{end}}<p>{{.Name}} · {{.PhotoCount}} foto(s)</p></a>
+{{- end}}
+</div>
+</body>
+</html>
+`))
+
+// galleryAlbumTemplate é a página de um álbum da galeria: a grade de miniaturas, cada uma levando à imagem.
+var galleryAlbumTemplate = template.Must(template.New("gallery-album").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Name}}</title>
+<meta name="description" content="{{.Description}}">
+<meta property="og:title" content="{{.Name}}">
+{{- if .CoverURL}}
+<meta property="og:image" content="{{.CoverURL}}">
+{{- end}}
+<style>
+body { font-family: sans-serif; margin: 2rem; }
+.grid { display: flex; flex-wrap: wrap; gap: 8px; }
+.grid img { height: 200px; }
+</style>
+</head>
+<body>
+<p><a href="/gallery">Galeria</a></p>
+<h1>{{.Name}}</h1>
+<p>{{.Description}}</p>
+<div class="grid">
+{{- range .Photos}}
+<a href="{{.ImageURL}}"><img src="{{.ThumbnailURL}}" loading="lazy" alt="{{.Description}}"></a>
+{{- end}}
+</div>
+</body>
+</html>
+`))
+
+// galleryAlbumPage contém os dados de um álbum na galeria.
+type galleryAlbumPage struct {
+	ID          string // Identificador público
+	Name        string
+	Description string
+	PhotoCount  int64
+	URL         string
+	CoverURL    string
+	Photos      []galleryPhoto
+}
+
+// galleryPhoto é uma foto na galeria: apenas o necessário para exibi-la, sem nome do arquivo, caminho ou GPS.
+type galleryPhoto struct {
+	ID           string // Identificador público
+	Description  string
+	Date         time.Time
+	Width        int
+	Height       int
+	ThumbnailURL string
+	ImageURL     string
+}
+
+// IndexHandler lista os álbuns publicados: HTML para navegadores, JSON para Accept: application/json.
+func (h *PublicGalleryHandler) IndexHandler(c *gin.Context) {
+	albums, err := h.Gallery.Albums()
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAlbumsFetchFailed, err)
+		return
+	}
+
+	pages := make([]galleryAlbumPage, 0, len(albums))
+	for _, album := range albums {
+		page := galleryAlbumPage{
+			ID:          album.PublicID,
+			Name:        album.Name,
+			Description: album.Description,
+			PhotoCount:  album.PhotoCount,
+			URL:         "/gallery/albums/" + album.PublicID,
+		}
+		if album.Cover != nil {
+			page.CoverURL = h.photo(album.PublicID, *album.Cover).ThumbnailURL
+		}
+		pages = append(pages, page)
+	}
+
+	h.setCacheHeaders(c, false)
+	if c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+		response := []gin.H{}
+		for _, page := range pages {
+			response = append(response, galleryAlbumResponse(page))
+		}
+		c.JSON(http.StatusOK, gin.H{"data": response})
+		return
+	}
+	renderGalleryPage(c, galleryIndexTemplate, pages)
+}
+
+// AlbumHandler exibe um álbum publicado e as suas fotos visíveis. O álbum é indicado pelo identificador
+// público; o ID numérico dos links antigos ainda é aceito.
+func (h *PublicGalleryHandler) AlbumHandler(c *gin.Context) {
+	album, photos, err := h.Gallery.Album(c.Param("id"))
+	if err != nil {
+		respondAlbumError(c, err)
+		return
+	}
+
+	page := galleryAlbumPage{
+		ID:          album.PublicID,
+		Name:        album.Name,
+		Description: album.Description,
+		PhotoCount:  album.PhotoCount,
+		URL:         "/gallery/albums/" + album.PublicID,
+	}
+	for _, photo := range photos {
+		page.Photos = append(page.Photos, h.photo(album.PublicID, photo))
+	}
+	if len(page.Photos) > 0 {
+		page.CoverURL = absoluteURL(c, page.Photos[0].ThumbnailURL)
+	}
+
+	h.setCacheHeaders(c, false)
+	if c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+		response := galleryAlbumResponse(page)
+		responsePhotos := []gin.H{}
+		for _, photo := range page.Photos {
+			responsePhotos = append(responsePhotos, gin.H{
+				"id":            photo.ID,
+				"description":   photo.Description,
+				"date":          photo.Date.Format(time.RFC3339),
+				"width":         photo.Width,
+				"height":        photo.Height,
+				"thumbnail_url": photo.ThumbnailURL,
+				"image_url":     photo.ImageURL,
+			})
+		}
+		response["photos"] = responsePhotos
+		c.JSON(http.StatusOK, gin.H{"data": response})
+		return
+	}
+	renderGalleryPage(c, galleryAlbumTemplate, page)
+}
+
+// ThumbnailHandler serve a miniatura de uma foto da galeria.
+func (h *PublicGalleryHandler) ThumbnailHandler(c *gin.Context) {
+	photo, ok := h.loadPhoto(c)
+	if !ok {
+		return
+	}
+	thumbPath, ok := h.Photos.thumbnailPath(c, photo)
+	if !ok {
+		return
+	}
+	h.setCacheHeaders(c, true)
+	c.Header("Content-Type", "image/jpeg")
+	c.File(thumbPath)
+}
+
+// ImageHandler serve a foto reduzida a ImageSize pixels no maior lado; os originais nunca são servidos.
+func (h *PublicGalleryHandler) ImageHandler(c *gin.Context) {
+	photo, ok := h.loadPhoto(c)
+	if !ok {
+		return
+	}
+	imagePath, err := h.Photos.PhotoService.GetResizedImage(c.Request.Context(), photo, h.ImageSize)
+	if err != nil {
+		if errors.Is(err, service.ErrPhotoQuarantined) || errors.Is(err, thumbnail.ErrInvalidImage) || errors.Is(err, thumbnail.ErrUnsupportedFormat) {
+			respondErrorCause(c, http.StatusUnprocessableEntity, i18n.CodeThumbnailUnavailable, err)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeThumbnailFailed, err)
+		return
+	}
+	h.setCacheHeaders(c, true)
+	c.Header("Content-Type", "image/jpeg")
+	c.File(imagePath)
+}
+
+// loadPhoto busca a foto da rota, que precisa estar visível em um álbum publicado.
+func (h *PublicGalleryHandler) loadPhoto(c *gin.Context) (*database.Photo, bool) {
+	photo, err := h.Gallery.Photo(c.Param("id"), c.Param("photo_id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+			return nil, false
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotoFetchFailed, err)
+		return nil, false
+	}
+	return photo, true
+}
+
+// photo monta os dados de exibição de uma foto. As URLs das imagens levam o início do hash, então uma foto
+// alterada muda de URL e as respostas podem ficar em cache como imutáveis.
+func (h *PublicGalleryHandler) photo(albumID string, photo database.Photo) galleryPhoto {
+	version := photo.Hash
+	if len(version) > 8 {
+		version = version[:8]
+	}
+	base := fmt.Sprintf("/gallery/albums/%s/photos/%s", albumID, photo.PublicID)
+
+	date := photo.UploadDate
+	if photo.ExifDate != nil {
+		date = *photo.ExifDate
+	}
+	width, height := photo.Width, photo.Height
+	if longest := max(width, height); longest > h.ImageSize {
+		width, height = width*h.ImageSize/longest, height*h.ImageSize/longest
+	}
+	return galleryPhoto{
+		ID:           photo.PublicID,
+		Description:  photo.Description,
+		Date:         date,
+		Width:        width,
+		Height:       height,
+		ThumbnailURL: base + "/thumbnail?v=" + version,
+		ImageURL:     base + "/image?v=" + version,
+	}
+}
+
+// setCacheHeaders define o cache das respostas. Imagens (com a versão na URL) são imutáveis; páginas são
+// revalidadas após max-age, para que novas fotos apareçam.
+func (h *PublicGalleryHandler) setCacheHeaders(c *gin.Context, immutable bool) {
+	maxAge := int(h.CacheMaxAge.Seconds())
+	if immutable {
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", maxAge))
+		return
+	}
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", min(maxAge, 300)))
+	c.Header("Vary", "Accept")
+}
+
+// renderGalleryPage responde uma página HTML da galeria.
+func renderGalleryPage(c *gin.Context, tmpl *template.Template, data interface{}) {
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(c.Writer, data); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// galleryAlbumResponse formata um álbum da galeria para a resposta JSON.
+func galleryAlbumResponse(page galleryAlbumPage) gin.H {
+	return gin.H{
+		"id":          page.ID,
+		"name":        page.Name,
+		"description": page.Description,
+		"photo_count": page.PhotoCount,
+		"url":         page.URL,
+		"cover_url":   page.CoverURL,
+	}
+}
+
+// HotlinkProtection recusa com 403 as requisições de imagens vindas de páginas de outros sites (pelo
+// Referer), exceto dos hosts em allowed (e dos seus subdomínios). Requisições sem Referer (acesso direto,
+// navegadores que o omitem) são aceitas.
+func HotlinkProtection(allowed []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		referer := c.GetHeader("Referer")
+		if referer == "" {
+			c.Next()
+			return
+		}
+		parsed, err := url.Parse(referer)
+		if err == nil && refererAllowed(parsed.Hostname(), hostOnly(c.Request.Host), allowed) {
+			c.Next()
+			return
+		}
+		respondError(c, http.StatusForbidden, i18n.CodeHotlinkForbidden)
+		c.Abort()
+	}
+}
+
+// refererAllowed indica se o host do Referer é o próprio servidor ou um dos hosts autorizados.
+func refererAllowed(host, self string, allowed []string) bool {
+	host = strings.ToLower(host)
+	if host == "" {
+		return false
+	}
+	if host == strings.ToLower(self) {
+		return true
+	}
+	for _, candidate := range allowed {
+		candidate = strings.ToLower(candidate)
+		if host == candidate || strings.HasSuffix(host, "."+candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostOnly remove a porta de um host (ex: "example.com:8443").
+func hostOnly(host string) string {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return name
+	}
+	return host
+}
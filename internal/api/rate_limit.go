@@ -0,0 +1,112 @@
+package api
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"photo-manager/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimiter limita as requisições de cada cliente (IP) a um número por minuto, permitindo rajadas do mesmo
+// tamanho (ex: uma página da galeria carregando todas as miniaturas de uma vez). Acima do limite responde 429
+// com Retry-After. O limite pode ser alterado com o servidor em execução (ver SetLimit).
+type RateLimiter struct {
+	limiter atomic.Pointer[requestLimiter] // nil: sem limite
+}
+
+// NewRateLimiter cria um RateLimiter com perMinute requisições por minuto. perMinute 0 desativa o limite.
+func NewRateLimiter(perMinute int) *RateLimiter {
+	r := &RateLimiter{}
+	r.SetLimit(perMinute)
+	return r
+}
+
+// SetLimit altera o limite de requisições por minuto. O saldo dos clientes recomeça cheio.
+func (r *RateLimiter) SetLimit(perMinute int) {
+	if perMinute <= 0 {
+		if r.limiter.Swap(nil) != nil {
+			log.Println("Galeria pública sem limite de requisições")
+		}
+		return
+	}
+	if current := r.limiter.Load(); current != nil && current.capacity == float64(perMinute) {
+		return // Mesmo limite: mantém o saldo dos clientes
+	}
+	r.limiter.Store(&requestLimiter{
+		capacity: float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		clients:  make(map[string]*requestAllowance),
+	})
+	log.Printf("Galeria pública limitada a %d requisições por minuto por cliente\n", perMinute)
+}
+
+// Handler retorna o middleware que aplica o limite atual.
+func (r *RateLimiter) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limiter := r.limiter.Load()
+		if limiter == nil {
+			c.Next()
+			return
+		}
+		if wait, ok := limiter.allow(c.ClientIP(), time.Now()); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			respondError(c, http.StatusTooManyRequests, i18n.CodeRateLimited)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestLimiter guarda o saldo de requisições de cada cliente.
+type requestLimiter struct {
+	capacity float64 // Saldo máximo (rajada)
+	rate     float64 // Requisições liberadas por segundo
+
+	mu        sync.Mutex
+	clients   map[string]*requestAllowance
+	lastSweep time.Time
+}
+
+// requestAllowance é o saldo de um cliente no instante last.
+type requestAllowance struct {
+	tokens float64
+	last   time.Time
+}
+
+// allow consome uma requisição do saldo do cliente. Se não houver saldo, retorna o tempo até haver.
+func (l *requestLimiter) allow(ip string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Clientes parados há tempo suficiente para encher o saldo equivalem a clientes novos: são descartados
+	full := time.Duration(l.capacity / l.rate * float64(time.Second))
+	if now.Sub(l.lastSweep) > full {
+		for key, client := range l.clients {
+			if now.Sub(client.last) > full {
+				delete(l.clients, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	client, ok := l.clients[ip]
+	if !ok {
+		client = &requestAllowance{tokens: l.capacity, last: now}
+		l.clients[ip] = client
+	}
+	client.tokens = math.Min(l.capacity, client.tokens+now.Sub(client.last).Seconds()*l.rate)
+	client.last = now
+	if client.tokens < 1 {
+		return time.Duration((1 - client.tokens) / l.rate * float64(time.Second)), false
+	}
+	client.tokens--
+	return 0, true
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ReactionHandler gerencia as reações (votos) às fotos de álbuns compartilhados.
+type ReactionHandler struct {
+	ReactionService *service.ReactionService
+}
+
+// NewReactionHandler cria uma nova instância de ReactionHandler.
+func NewReactionHandler(s *service.ReactionService) *ReactionHandler {
+	return &ReactionHandler{
+		ReactionService: s,
+	}
+}
+
+// reactionRequest identifica quem reage e o tipo de reação (thumbs_up ou heart).
+type reactionRequest struct {
+	Voter string `json:"voter" form:"voter"`
+	Kind  string `json:"kind" form:"kind"`
+}
+
+// topReactionsQuery são os filtros da lista de fotos mais votadas.
+type topReactionsQuery struct {
+	Kind  string `form:"kind"`
+	Limit int    `form:"limit" binding:"min=0,max=500"` // 0 = todas
+}
+
+// ReactHandler registra a reação de um visitante do link de compartilhamento a uma foto do álbum
+// (PUT /s/:token/photos/:photo_id/reactions, corpo {"voter": "Ana", "kind": "heart"}). A foto é indicada
+// pelo identificador público; o ID numérico ainda é aceito, para os clientes anteriores a ele.
+func (h *ReactionHandler) ReactHandler(c *gin.Context) {
+	var req reactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeReactionVoterInvalid)
+		return
+	}
+
+	reactions, err := h.ReactionService.React(c.Param("token"), c.Param("photo_id"), req.Voter, req.Kind)
+	if err != nil {
+		respondReactionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": photoReactionsResponse(*reactions)})
+}
+
+// UnreactHandler remove a reação de um visitante a uma foto do álbum
+// (DELETE /s/:token/photos/:photo_id/reactions?voter=Ana&kind=heart).
+func (h *ReactionHandler) UnreactHandler(c *gin.Context) {
+	var req reactionRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	reactions, err := h.ReactionService.Unreact(c.Param("token"), c.Param("photo_id"), req.Voter, req.Kind)
+	if err != nil {
+		respondReactionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": photoReactionsResponse(*reactions)})
+}
+
+// SharedTopHandler lista as fotos mais votadas de um álbum compartilhado, para quem tem o link.
+// Aceita ?kind= (apenas um tipo de reação) e ?limit=.
+func (h *ReactionHandler) SharedTopHandler(c *gin.Context) {
+	var query topReactionsQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	top, err := h.ReactionService.TopSharedPhotos(c.Param("token"), query.Kind, query.Limit)
+	if err != nil {
+		respondReactionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": reactedPhotosResponse(top)})
+}
+
+// AlbumTopHandler lista as fotos mais votadas de um álbum (ex: para escolher quais imprimir), somando
+// as reações recebidas por todos os links de compartilhamento do álbum.
+func (h *ReactionHandler) AlbumTopHandler(c *gin.Context) {
+	albumID, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumID)
+	if !ok {
+		return
+	}
+	var query topReactionsQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	top, err := h.ReactionService.TopPhotos(albumID, query.Kind, query.Limit)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodeAlbumNotFound)
+			return
+		}
+		respondReactionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": reactedPhotosResponse(top)})
+}
+
+// respondReactionError responde o erro adequado para as operações de reação.
+func respondReactionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondError(c, http.StatusNotFound, i18n.CodeShareNotFound)
+	case errors.Is(err, service.ErrReactionKindInvalid), errors.Is(err, service.ErrReactionVoterInvalid):
+		respondServiceError(c, http.StatusBadRequest, err, i18n.CodeReactionFailed)
+	case errors.Is(err, service.ErrReactionPhotoNotShared):
+		respondServiceError(c, http.StatusNotFound, err, i18n.CodeReactionFailed)
+	case c.Request.Method == http.MethodGet:
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeReactionListFailed, err)
+	default:
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeReactionFailed, err)
+	}
+}
+
+// photoReactionsResponse formata o resumo das reações de uma foto.
+func photoReactionsResponse(reactions service.PhotoReactions) gin.H {
+	return gin.H{
+		"photo_id": reactions.PhotoID,
+		"counts":   reactions.Counts,
+		"total":    reactions.Total,
+		"voters":   reactions.Voters,
+	}
+}
+
+// reactedPhotosResponse formata a lista de fotos mais votadas.
+func reactedPhotosResponse(top []service.ReactedPhoto) []gin.H {
+	response := make([]gin.H, 0, len(top))
+	for _, reacted := range top {
+		item := photoMinimalResponse(reacted.Photo)
+		item["reactions"] = photoReactionsResponse(reacted.Reactions)
+		response = append(response, item)
+	}
+	return response
+}
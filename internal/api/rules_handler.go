@@ -0,0 +1,65 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/rules"
+	"photo-manager/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RulesHandler gerencia as requisições HTTP das regras de organização.
+type RulesHandler struct {
+	PhotoService *service.PhotoService
+}
+
+// NewRulesHandler cria uma nova instância de RulesHandler.
+func NewRulesHandler(s *service.PhotoService) *RulesHandler {
+	return &RulesHandler{PhotoService: s}
+}
+
+// CheckRulesHandler valida um script de regras e, se photo_id for informado, mostra as ações que ele
+// produziria para essa foto, sem aplicá-las. Sem "rules", usa as regras carregadas de RULES_FILE.
+// Responde 400 com a linha do erro se o script for inválido.
+func (h *RulesHandler) CheckRulesHandler(c *gin.Context) {
+	var req struct {
+		Rules   string `json:"rules"`
+		PhotoID uint   `json:"photo_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeInvalidRequestBody)
+		return
+	}
+
+	set := h.PhotoService.Rules
+	if req.Rules != "" {
+		parsed, err := rules.Parse(req.Rules)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, i18n.CodeRulesInvalid, err.Error())
+			return
+		}
+		set = parsed
+	}
+
+	data := gin.H{"rules": set.Len()}
+	if req.PhotoID != 0 {
+		result, err := h.PhotoService.PreviewRules(c.Request.Context(), set, req.PhotoID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				respondError(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+				return
+			}
+			respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotoFetchFailed, err)
+			return
+		}
+		data["photo_id"] = req.PhotoID
+		data["matched_lines"] = result.Matched
+		data["tags"] = result.Tags
+		data["albums"] = result.Albums
+		data["folder"] = result.Folder
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SelectionHandler gerencia as seleções temporárias de fotos (cestas): montadas em várias chamadas, por IDs
+// ou pelo resultado de filtros, e usadas em ações em lote (download, álbum, tags, compartilhamento).
+type SelectionHandler struct {
+	SelectionService *service.SelectionService
+	PhotoService     *service.PhotoService
+	Downloads        *DownloadHandler
+}
+
+// NewSelectionHandler cria uma nova instância de SelectionHandler.
+func NewSelectionHandler(s *service.SelectionService, ps *service.PhotoService, downloads *DownloadHandler) *SelectionHandler {
+	return &SelectionHandler{
+		SelectionService: s,
+		PhotoService:     ps,
+		Downloads:        downloads,
+	}
+}
+
+// CreateSelectionHandler cria uma seleção (POST /selections), vazia ou já com {"photo_ids": [1, 2, 3]}.
+// O token da resposta identifica a seleção nas demais rotas; ela expira após um dia sem uso.
+func (h *SelectionHandler) CreateSelectionHandler(c *gin.Context) {
+	var req struct {
+		PhotoIDs []uint `json:"photo_ids"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, i18n.CodeSelectionPhotosInvalid)
+			return
+		}
+	}
+
+	summary, err := h.SelectionService.CreateSelection(req.PhotoIDs)
+	if err != nil {
+		respondSelectionError(c, err, i18n.CodeSelectionUpdateFailed)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": selectionResponse(summary)})
+}
+
+// GetSelectionHandler retorna as fotos de uma seleção (GET /selections/:token).
+func (h *SelectionHandler) GetSelectionHandler(c *gin.Context) {
+	summary, err := h.SelectionService.GetSelection(c.Param("token"))
+	if err != nil {
+		respondSelectionError(c, err, i18n.CodeSelectionFetchFailed)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": selectionResponse(summary)})
+}
+
+// DeleteSelectionHandler descarta uma seleção, sem alterar as fotos (DELETE /selections/:token).
+func (h *SelectionHandler) DeleteSelectionHandler(c *gin.Context) {
+	if err := h.SelectionService.DeleteSelection(c.Param("token")); err != nil {
+		respondSelectionError(c, err, i18n.CodeSelectionUpdateFailed)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message(c, i18n.CodeSelectionRemoved)})
+}
+
+// AddPhotosHandler acrescenta fotos à seleção (POST /selections/:token/photos): {"photo_ids": [1, 2, 3]} ou,
+// sem corpo, todas as fotos do filtro da query string (mesmos parâmetros de GET /photos, ex: ?year=2023&tag=praia).
+func (h *SelectionHandler) AddPhotosHandler(c *gin.Context) {
+	photoIDs, ok := bindPhotoSelection(c, h.PhotoService, i18n.CodeSelectionPhotosInvalid, i18n.CodeSelectionUpdateFailed)
+	if !ok {
+		return
+	}
+	added, summary, err := h.SelectionService.AddPhotos(c.Param("token"), photoIDs)
+	if err != nil {
+		respondSelectionError(c, err, i18n.CodeSelectionUpdateFailed)
+		return
+	}
+	response := selectionResponse(summary)
+	response["added"] = added
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// RemovePhotosHandler retira fotos da seleção (DELETE /selections/:token/photos), com a mesma seleção de
+// POST /selections/:token/photos. Sem corpo nem filtro, esvazia a seleção.
+func (h *SelectionHandler) RemovePhotosHandler(c *gin.Context) {
+	photoIDs, ok := bindPhotoSelection(c, h.PhotoService, i18n.CodeSelectionPhotosInvalid, i18n.CodeSelectionUpdateFailed)
+	if !ok {
+		return
+	}
+	removed, summary, err := h.SelectionService.RemovePhotos(c.Param("token"), photoIDs)
+	if err != nil {
+		respondSelectionError(c, err, i18n.CodeSelectionUpdateFailed)
+		return
+	}
+	response := selectionResponse(summary)
+	response["removed"] = removed
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// DownloadHandler baixa os originais da seleção em um ZIP (POST /selections/:token/download), como
+// POST /downloads: seleções grandes, ou com ?prepare=true, são preparadas em background (resposta 202).
+func (h *SelectionHandler) DownloadHandler(c *gin.Context) {
+	var query struct {
+		Prepare bool `form:"prepare"`
+	}
+	if !bindQuery(c, &query) {
+		return
+	}
+	photoIDs, err := h.SelectionService.PhotoIDs(c.Param("token"))
+	if err != nil {
+		respondSelectionError(c, err, i18n.CodeSelectionFetchFailed)
+		return
+	}
+	h.Downloads.sendDownload(c, photoIDs, query.Prepare)
+}
+
+// AlbumHandler coloca as fotos da seleção em um álbum (POST /selections/:token/album): {"album_id": 4} para
+// um álbum existente (as tags padrão do álbum são aplicadas) ou {"name": "Viagem", "description": "..."}
+// para um novo.
+func (h *SelectionHandler) AlbumHandler(c *gin.Context) {
+	var req struct {
+		AlbumID     uint   `json:"album_id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || (req.AlbumID == 0 && req.Name == "") {
+		respondError(c, http.StatusBadRequest, i18n.CodeSelectionAlbumTarget)
+		return
+	}
+
+	token := c.Param("token")
+	if req.AlbumID != 0 {
+		added, err := h.SelectionService.AddToAlbum(token, req.AlbumID)
+		if err != nil {
+			respondSelectionError(c, err, i18n.CodeSelectionActionFailed)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": message(c, i18n.CodeAlbumPhotosAdded, added), "added": added})
+		return
+	}
+
+	album, err := h.SelectionService.CreateAlbum(token, req.Name, req.Description)
+	if err != nil {
+		respondSelectionError(c, err, i18n.CodeSelectionActionFailed)
+		return
+	}
+	summary, err := h.SelectionService.Albums.GetAlbum(album.ID)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAlbumCreatedFetch, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": albumResponse(*summary)})
+}
+
+// TagsHandler acrescenta tags às fotos da seleção, mantendo as que elas já têm
+// (POST /selections/:token/tags), ex: {"tags": ["praia", "2023"]}. Fotos bloqueadas são ignoradas.
+func (h *SelectionHandler) TagsHandler(c *gin.Context) {
+	var req struct {
+		Tags []string `json:"tags" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldRequired, "tags")
+		return
+	}
+
+	tagged, err := h.SelectionService.AddTags(c.Param("token"), req.Tags)
+	if err != nil {
+		respondSelectionError(c, err, i18n.CodeSelectionActionFailed)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message(c, i18n.CodeSelectionPhotosTagged, tagged), "tagged": tagged})
+}
+
+// ShareHandler compartilha as fotos da seleção (POST /selections/:token/share): cria um álbum com elas,
+// ex: {"name": "Fotos do casamento"}, e um link público para ele.
+func (h *SelectionHandler) ShareHandler(c *gin.Context) {
+	var req struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldRequired, "name")
+		return
+	}
+
+	album, link, err := h.SelectionService.Share(c.Param("token"), req.Name, req.Description)
+	if err != nil {
+		respondSelectionError(c, err, i18n.CodeSelectionActionFailed)
+		return
+	}
+	summary, err := h.SelectionService.Albums.GetAlbum(album.ID)
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeAlbumCreatedFetch, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": gin.H{
+		"token":    link.Token,
+		"album_id": link.AlbumID,
+		"album":    albumResponse(*summary),
+		"url":      absoluteURL(c, "/s/"+link.Token),
+	}})
+}
+
+// respondSelectionError responde 404 para seleções ou álbuns inexistentes, 423 para álbuns bloqueados,
+// 400 para dados rejeitados e 500 (com o código informado) para os demais erros.
+func respondSelectionError(c *gin.Context, err error, code string) {
+	switch {
+	case errors.Is(err, service.ErrSelectionNotFound):
+		respondError(c, http.StatusNotFound, i18n.CodeSelectionNotFound)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondError(c, http.StatusNotFound, i18n.CodeAlbumNotFound)
+	case errors.Is(err, service.ErrAlbumLocked):
+		respondError(c, http.StatusLocked, i18n.CodeAlbumLocked)
+	case i18n.Code(err) == i18n.CodePhotosNotFound:
+		respondServiceError(c, http.StatusNotFound, err, code)
+	case i18n.Code(err) != "":
+		respondServiceError(c, http.StatusBadRequest, err, code)
+	default:
+		respondErrorCause(c, http.StatusInternalServerError, code, err)
+	}
+}
+
+// selectionResponse formata uma seleção para a resposta da API.
+func selectionResponse(summary *service.SelectionSummary) gin.H {
+	return gin.H{
+		"token":       summary.Token,
+		"photo_ids":   summary.PhotoIDs,
+		"photo_count": len(summary.PhotoIDs),
+		"total_bytes": summary.TotalBytes,
+		"expires_at":  summary.ExpiresAt.Format(time.RFC3339),
+		"created_at":  summary.CreatedAt.Format(time.RFC3339),
+	}
+}
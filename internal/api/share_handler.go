@@ -0,0 +1,216 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ShareHandler gerencia os links públicos de compartilhamento de álbuns.
+type ShareHandler struct {
+	ShareService *service.ShareService
+	Photos       *PhotoHandler // Gera as miniaturas das fotos compartilhadas, com a mesma fila das demais rotas
+}
+
+// NewShareHandler cria uma nova instância de ShareHandler.
+func NewShareHandler(s *service.ShareService, photos *PhotoHandler) *ShareHandler {
+	return &ShareHandler{
+		ShareService: s,
+		Photos:       photos,
+	}
+}
+
+// sharePageTemplate é a página HTML de um álbum compartilhado. As meta tags OpenGraph/Twitter
+// permitem que aplicativos de conversa mostrem uma prévia (título, capa e quantidade de fotos).
+var sharePageTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<meta name="description" content="{{.Description}}">
+<meta property="og:type" content="website">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.URL}}">
+{{- if .CoverURL}}
+<meta property="og:image" content="{{.CoverURL}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:image" content="{{.CoverURL}}">
+{{- else}}
+<meta name="twitter:card" content="summary">
+{{- end}}
+<meta name="twitter:title" content="{{.Title}}">
+<meta name="twitter:description" content="{{.Description}}">
+<style>
+body { font-family: sans-serif; margin: 2rem; }
+.grid { display: flex; flex-wrap: wrap; gap: 8px; }
+.grid img { height: 160px; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Description}}</p>
+<div class="grid">
+{{- range .ThumbnailURLs}}
+<img src="{{.}}" loading="lazy" alt="">
+{{- end}}
+</div>
+</body>
+</html>
+`))
+
+// sharePage contém os dados da página de um álbum compartilhado.
+type sharePage struct {
+	Title         string
+	Description   string
+	URL           string
+	CoverURL      string
+	ThumbnailURLs []string
+}
+
+// CreateAlbumShareHandler cria um link de compartilhamento para o álbum.
+func (h *ShareHandler) CreateAlbumShareHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidAlbumID)
+	if !ok {
+		return
+	}
+
+	link, err := h.ShareService.CreateAlbumShare(id)
+	if err != nil {
+		respondAlbumError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": gin.H{
+		"token":    link.Token,
+		"album_id": link.AlbumID,
+		"url":      absoluteURL(c, "/s/"+link.Token),
+	}})
+}
+
+// GetShareHandler exibe um álbum compartilhado. Navegadores e robôs de prévia (Accept: text/html ou */*)
+// recebem a página HTML com meta tags OpenGraph; clientes da API (Accept: application/json) recebem JSON.
+// O álbum e as fotos são identificados pelos identificadores públicos, e as miniaturas são servidas pelo
+// próprio link: quem o recebe não vê os IDs internos nem acessa as rotas de fotos da API.
+func (h *ShareHandler) GetShareHandler(c *gin.Context) {
+	token := c.Param("token")
+	shared, err := h.ShareService.GetSharedAlbum(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodeShareNotFound)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeShareFetchFailed, err)
+		return
+	}
+
+	if c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+		responsePhotos := []gin.H{}
+		for _, photo := range shared.Photos {
+			item := photoMinimalResponse(photo)
+			item["id"] = photo.PublicID
+			item["thumbnail_url"] = sharedThumbnailURL(token, photo)
+			item["image_url"] = "/s/" + token + "/photos/" + photo.PublicID + "/image"
+			// Usados por outras instâncias que assinam o álbum (espelhamento do tipo share)
+			item["filename"] = photo.Filename
+			item["hash"] = photo.Hash
+			item["file_size"] = photo.FileSize
+			delete(item, "public_id")
+			responsePhotos = append(responsePhotos, item)
+		}
+		response := albumResponse(shared.Album)
+		response["id"] = shared.Album.PublicID
+		delete(response, "public_id")
+		response["photos"] = responsePhotos
+		c.JSON(http.StatusOK, gin.H{"data": response})
+		return
+	}
+
+	page := sharePage{
+		Title:       shared.Album.Name,
+		Description: shared.Album.Description,
+		URL:         absoluteURL(c, c.Request.URL.Path),
+	}
+	if page.Description == "" {
+		page.Description = fmt.Sprintf("%d foto(s)", shared.Album.PhotoCount)
+	} else {
+		page.Description = fmt.Sprintf("%s · %d foto(s)", page.Description, shared.Album.PhotoCount)
+	}
+	for _, photo := range shared.Photos {
+		page.ThumbnailURLs = append(page.ThumbnailURLs, sharedThumbnailURL(token, photo))
+	}
+	if len(shared.Photos) > 0 {
+		page.CoverURL = absoluteURL(c, sharedThumbnailURL(token, shared.Photos[0]))
+	}
+
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := sharePageTemplate.Execute(c.Writer, page); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// SharedThumbnailHandler serve a miniatura de uma foto do álbum compartilhado
+// (GET /s/:token/photos/:photo_id/thumbnail, com o identificador público da foto).
+func (h *ShareHandler) SharedThumbnailHandler(c *gin.Context) {
+	photo, err := h.ShareService.SharedPhoto(c.Param("token"), c.Param("photo_id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotoFetchFailed, err)
+		return
+	}
+	thumbPath, ok := h.Photos.thumbnailPath(c, photo)
+	if !ok {
+		return
+	}
+	c.Header("Content-Type", "image/jpeg")
+	c.File(thumbPath)
+}
+
+// SharedImageHandler serve o arquivo original de uma foto do álbum compartilhado
+// (GET /s/:token/photos/:photo_id/image), usado também pelas instâncias que assinam o álbum.
+func (h *ShareHandler) SharedImageHandler(c *gin.Context) {
+	photo, err := h.ShareService.SharedPhoto(c.Param("token"), c.Param("photo_id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodePhotoFetchFailed, err)
+		return
+	}
+	if !h.Photos.checkFileAvailable(c, photo) {
+		return
+	}
+	c.Header("Content-Type", photo.MimeType)
+	c.File(photo.StoredPath)
+}
+
+// sharedThumbnailURL retorna a URL da miniatura de uma foto servida pelo link de compartilhamento.
+func sharedThumbnailURL(token string, photo database.Photo) string {
+	return "/s/" + token + "/photos/" + photo.PublicID + "/thumbnail"
+}
+
+// absoluteURL monta uma URL absoluta para o caminho, a partir do host da requisição.
+// Respeita X-Forwarded-Proto quando a aplicação está atrás de um proxy reverso.
+func absoluteURL(c *gin.Context, path string) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, c.Request.Host, path)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SourceAlbumHandler gerencia os álbuns padrão por canal de entrada e dispositivo.
+type SourceAlbumHandler struct {
+	SourceAlbumService *service.SourceAlbumService
+}
+
+// NewSourceAlbumHandler cria uma nova instância de SourceAlbumHandler.
+func NewSourceAlbumHandler(s *service.SourceAlbumService) *SourceAlbumHandler {
+	return &SourceAlbumHandler{
+		SourceAlbumService: s,
+	}
+}
+
+// ListSourceAlbumsHandler lista os álbuns padrão configurados.
+func (h *SourceAlbumHandler) ListSourceAlbumsHandler(c *gin.Context) {
+	sourceAlbums, err := h.SourceAlbumService.ListSourceAlbums()
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeSourceAlbumsFailed, err)
+		return
+	}
+
+	response := []gin.H{}
+	for _, sourceAlbum := range sourceAlbums {
+		response = append(response, sourceAlbumResponse(sourceAlbum))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// SetSourceAlbumHandler define o álbum padrão de um canal ou dispositivo, ex: {"source": "sync",
+// "device": "Câmera da porta", "album_id": 3}. Sem "device", vale para todas as fotos do canal.
+func (h *SourceAlbumHandler) SetSourceAlbumHandler(c *gin.Context) {
+	var req struct {
+		Source  string `json:"source" binding:"required"`
+		Device  string `json:"device"`
+		AlbumID uint   `json:"album_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldsRequired, "'source', 'album_id'")
+		return
+	}
+
+	sourceAlbum, err := h.SourceAlbumService.SetSourceAlbum(req.Source, req.Device, req.AlbumID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(c, http.StatusNotFound, i18n.CodeAlbumNotFound)
+		return
+	}
+	if err != nil {
+		respondServiceError(c, http.StatusBadRequest, err, i18n.CodeSourceAlbumSaveFailed)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": sourceAlbumResponse(*sourceAlbum)})
+}
+
+// DeleteSourceAlbumHandler remove um álbum padrão; as fotos já incluídas permanecem no álbum.
+func (h *SourceAlbumHandler) DeleteSourceAlbumHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidSourceAlbumID)
+	if !ok {
+		return
+	}
+
+	err := h.SourceAlbumService.DeleteSourceAlbum(id)
+	if errors.Is(err, service.ErrSourceAlbumNotFound) {
+		respondError(c, http.StatusNotFound, i18n.CodeSourceAlbumNotFound)
+		return
+	}
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeSourceAlbumSaveFailed, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message(c, i18n.CodeSourceAlbumRemoved)})
+}
+
+// sourceAlbumResponse formata um álbum padrão para a resposta da API.
+func sourceAlbumResponse(sourceAlbum database.SourceAlbum) gin.H {
+	return gin.H{
+		"id":         sourceAlbum.ID,
+		"source":     sourceAlbum.Source,
+		"device":     sourceAlbum.Device,
+		"album_id":   sourceAlbum.AlbumID,
+		"album_name": sourceAlbum.Album.Name,
+		"updated_at": sourceAlbum.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListSourceChangesHandler lista as fotos indexadas no local cujo arquivo original mudou desde a indexação
+// (ex: editado em outro programa) ou não existe mais (GET /photos/source-changes). ?photo_ids= (repetido)
+// limita a verificação a algumas fotos, e ?verify_hash=true compara também o conteúdo dos arquivos com o mesmo
+// tamanho e data de modificação (lento: lê cada arquivo). Nada é alterado.
+func (h *PhotoHandler) ListSourceChangesHandler(c *gin.Context) {
+	var query struct {
+		PhotoIDs   []uint `form:"photo_ids"`
+		VerifyHash bool   `form:"verify_hash"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeSourceChangesInvalid)
+		return
+	}
+	h.checkSourceChanges(c, service.SourceChangesOptions{PhotoIDs: query.PhotoIDs, VerifyHash: query.VerifyHash})
+}
+
+// AdoptSourceChangesHandler adota a nova versão dos arquivos alterados (POST /photos/source-changes/adopt):
+// os metadados são reextraídos, a miniatura e a prévia são geradas de novo e os valores anteriores ficam no
+// histórico (GET /admin/metadata/changes). Corpo opcional: {"photo_ids": [...]} (padrão: todas as fotos
+// indexadas no local) e "verify_hash", como na listagem. Fotos bloqueadas não são alteradas.
+func (h *PhotoHandler) AdoptSourceChangesHandler(c *gin.Context) {
+	var req struct {
+		PhotoIDs   []uint `json:"photo_ids"`
+		VerifyHash bool   `json:"verify_hash"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, i18n.CodeSourceChangesInvalid)
+			return
+		}
+	}
+	h.checkSourceChanges(c, service.SourceChangesOptions{PhotoIDs: req.PhotoIDs, VerifyHash: req.VerifyHash, Adopt: true})
+}
+
+// AdoptPhotoSourceHandler adota a nova versão do arquivo de uma foto (POST /photos/:id/adopt-source). O
+// conteúdo é sempre comparado, então também vale para arquivos alterados sem mudar o tamanho nem a data.
+func (h *PhotoHandler) AdoptPhotoSourceHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+	h.checkSourceChanges(c, service.SourceChangesOptions{PhotoIDs: []uint{id}, VerifyHash: true, Adopt: true})
+}
+
+// checkSourceChanges executa a verificação (e a adoção, se pedida) e responde o relatório.
+func (h *PhotoHandler) checkSourceChanges(c *gin.Context, opts service.SourceChangesOptions) {
+	report, err := h.PhotoService.CheckSourceChanges(c.Request.Context(), opts)
+	if err != nil {
+		switch i18n.Code(err) {
+		case i18n.CodePhotosNotFound:
+			respondError(c, http.StatusNotFound, i18n.CodePhotosNotFound)
+		case i18n.CodeSourceChangesNotExternal:
+			respondServiceError(c, http.StatusBadRequest, err, i18n.CodeSourceChangesFailed)
+		default:
+			respondErrorCause(c, http.StatusInternalServerError, i18n.CodeSourceChangesFailed, err)
+		}
+		return
+	}
+
+	// As miniaturas descartadas são geradas de novo em segundo plano
+	if h.Thumbnails != nil {
+		for _, id := range report.AdoptedIDs {
+			h.Thumbnails.Enqueue(id)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": sourceChangesResponse(report)})
+}
+
+// sourceChangesResponse formata o relatório da verificação dos arquivos para a resposta da API.
+func sourceChangesResponse(report *service.SourceChangesReport) gin.H {
+	photos := make([]gin.H, 0, len(report.Photos))
+	for _, change := range report.Photos {
+		item := gin.H{
+			"photo_id":     change.PhotoID,
+			"filename":     change.Filename,
+			"path":         change.Path,
+			"reasons":      change.Reasons,
+			"indexed_size": change.IndexedSize,
+			"size":         change.Size,
+			"locked":       change.Locked,
+			"adopted":      change.Adopted,
+		}
+		if change.IndexedMod != nil {
+			item["indexed_mod_time"] = change.IndexedMod.Format(time.RFC3339)
+		}
+		if change.ModTime != nil {
+			item["mod_time"] = change.ModTime.Format(time.RFC3339)
+		}
+		if change.Error != "" {
+			item["error"] = change.Error
+		}
+		photos = append(photos, item)
+	}
+	return gin.H{
+		"checked":   report.Checked,
+		"changed":   report.Changed,
+		"missing":   report.Missing,
+		"adopted":   report.Adopted,
+		"failed":    report.Failed,
+		"photos":    photos,
+		"truncated": report.Truncated,
+	}
+}
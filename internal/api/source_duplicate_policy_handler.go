@@ -0,0 +1,105 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SourceDuplicatePolicyHandler gerencia as políticas de duplicatas por canal de entrada e dispositivo.
+type SourceDuplicatePolicyHandler struct {
+	PolicyService *service.SourceDuplicatePolicyService
+}
+
+// NewSourceDuplicatePolicyHandler cria uma nova instância de SourceDuplicatePolicyHandler.
+func NewSourceDuplicatePolicyHandler(s *service.SourceDuplicatePolicyService) *SourceDuplicatePolicyHandler {
+	return &SourceDuplicatePolicyHandler{
+		PolicyService: s,
+	}
+}
+
+// ListPoliciesHandler lista as políticas de duplicatas configuradas.
+func (h *SourceDuplicatePolicyHandler) ListPoliciesHandler(c *gin.Context) {
+	policies, err := h.PolicyService.ListPolicies()
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeDuplicatePoliciesFailed, err)
+		return
+	}
+
+	response := []gin.H{}
+	for _, policy := range policies {
+		response = append(response, duplicatePolicyResponse(policy))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// SetPolicyHandler define a política de duplicatas de um canal ou dispositivo, ex: {"source": "sync",
+// "device": "Câmera da porta", "policy": "version"}. Políticas: reject (padrão, duplicatas são recusadas),
+// skip (ignoradas sem erro), link (ignoradas, e a foto existente vai para "album_id" ou para o álbum padrão
+// da origem) e version (gravadas mesmo assim como cópia da foto existente). Sem "device", vale para todo o
+// canal.
+func (h *SourceDuplicatePolicyHandler) SetPolicyHandler(c *gin.Context) {
+	var req struct {
+		Source  string `json:"source" binding:"required"`
+		Device  string `json:"device"`
+		Policy  string `json:"policy" binding:"required"`
+		AlbumID *uint  `json:"album_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldsRequired, "'source', 'policy'")
+		return
+	}
+
+	policy, err := h.PolicyService.SetPolicy(req.Source, req.Device, req.Policy, req.AlbumID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(c, http.StatusNotFound, i18n.CodeAlbumNotFound)
+		return
+	}
+	if err != nil {
+		respondServiceError(c, http.StatusBadRequest, err, i18n.CodeDuplicatePolicySaveFailed)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": duplicatePolicyResponse(*policy)})
+}
+
+// DeletePolicyHandler remove uma política de duplicatas; a origem volta a rejeitar as duplicatas.
+func (h *SourceDuplicatePolicyHandler) DeletePolicyHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidDuplicatePolicyID)
+	if !ok {
+		return
+	}
+
+	err := h.PolicyService.DeletePolicy(id)
+	if errors.Is(err, service.ErrDuplicatePolicyNotFound) {
+		respondError(c, http.StatusNotFound, i18n.CodeDuplicatePolicyNotFound)
+		return
+	}
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeDuplicatePolicySaveFailed, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message(c, i18n.CodeDuplicatePolicyRemoved)})
+}
+
+// duplicatePolicyResponse formata uma política de duplicatas para a resposta da API.
+func duplicatePolicyResponse(policy database.SourceDuplicatePolicy) gin.H {
+	albumName := ""
+	if policy.Album != nil {
+		albumName = policy.Album.Name
+	}
+	return gin.H{
+		"id":         policy.ID,
+		"source":     policy.Source,
+		"device":     policy.Device,
+		"policy":     policy.Policy,
+		"album_id":   policy.AlbumID,
+		"album_name": albumName,
+		"updated_at": policy.UpdatedAt.Format(time.RFC3339),
+	}
+}
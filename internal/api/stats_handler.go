@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatsHandler gerencia as requisições HTTP de estatísticas.
+type StatsHandler struct {
+	StatsService *service.StatsService
+}
+
+// NewStatsHandler cria uma nova instância de StatsHandler.
+func NewStatsHandler(s *service.StatsService) *StatsHandler {
+	return &StatsHandler{
+		StatsService: s,
+	}
+}
+
+// GetStatsHandler retorna as estatísticas gerais da biblioteca e o espaço livre de cada volume.
+func (h *StatsHandler) GetStatsHandler(c *gin.Context) {
+	stats, err := h.StatsService.GetStats()
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeStatsFailed, err)
+		return
+	}
+
+	volumes := []gin.H{}
+	for _, v := range stats.Volumes {
+		volumes = append(volumes, gin.H{
+			"name":        v.Name,
+			"path":        v.Path,
+			"online":      v.Online,
+			"free_bytes":  v.FreeBytes,
+			"total_bytes": v.TotalBytes,
+			"low_space":   v.LowSpace,
+			"photo_count": v.PhotoCount,
+			"photo_bytes": v.PhotoBytes,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"photo_count": stats.PhotoCount,
+		"total_bytes": stats.TotalBytes,
+		"volumes":     volumes,
+		"events":      stats.EventCounts,
+	}})
+}
+
+// GetCamerasHandler retorna, para cada câmera, a quantidade de fotos, a distribuição de distâncias focais
+// e o histograma de ISO, a partir dos dados EXIF.
+func (h *StatsHandler) GetCamerasHandler(c *gin.Context) {
+	stats, err := h.StatsService.CameraStats()
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeCameraStatsFailed, err)
+		return
+	}
+
+	cameras := []gin.H{}
+	for _, s := range stats {
+		entry := equipmentResponse(s)
+		entry["make"] = s.Make
+		entry["model"] = s.Model
+		cameras = append(cameras, entry)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": cameras})
+}
+
+// GetLensesHandler retorna, para cada lente, a quantidade de fotos, a distribuição de distâncias focais
+// e o histograma de ISO, a partir dos dados EXIF.
+func (h *StatsHandler) GetLensesHandler(c *gin.Context) {
+	stats, err := h.StatsService.LensStats()
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeLensStatsFailed, err)
+		return
+	}
+
+	lenses := []gin.H{}
+	for _, s := range stats {
+		entry := equipmentResponse(s)
+		entry["lens"] = s.Lens
+		lenses = append(lenses, entry)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": lenses})
+}
+
+// equipmentResponse formata os campos comuns das estatísticas de câmeras e lentes.
+func equipmentResponse(s service.EquipmentStats) gin.H {
+	focalLengths := []gin.H{}
+	for _, f := range s.FocalLengths {
+		focalLengths = append(focalLengths, gin.H{"focal_length": f.FocalLength, "count": f.Count})
+	}
+	isoHistogram := []gin.H{}
+	for _, b := range s.ISOHistogram {
+		bucket := gin.H{"min": b.Min, "count": b.Count}
+		if b.Max > 0 {
+			bucket["max"] = b.Max
+		}
+		isoHistogram = append(isoHistogram, bucket)
+	}
+	return gin.H{
+		"photo_count":   s.PhotoCount,
+		"focal_lengths": focalLengths,
+		"iso_histogram": isoHistogram,
+	}
+}
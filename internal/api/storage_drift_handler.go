@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net/http"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StorageDriftHandler gerencia a verificação de divergências entre os volumes de armazenamento e o banco.
+type StorageDriftHandler struct {
+	Drift *service.StorageDriftService
+}
+
+// NewStorageDriftHandler cria uma nova instância de StorageDriftHandler.
+func NewStorageDriftHandler(s *service.StorageDriftService) *StorageDriftHandler {
+	return &StorageDriftHandler{
+		Drift: s,
+	}
+}
+
+// ScanHandler percorre os volumes e lista as divergências com a ação sugerida para cada uma: adopt (arquivo
+// sem foto), relink (arquivo movido ou renomeado, encontrado pelo hash), duplicate (cópia solta de uma
+// foto), size_mismatch (arquivo alterado) e missing (arquivo ausente). Nada é alterado.
+func (h *StorageDriftHandler) ScanHandler(c *gin.Context) {
+	report, err := h.Drift.Scan(c.Request.Context())
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeStorageDriftScanFailed, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": storageDriftResponse(report)})
+}
+
+// ReconcileHandler aplica as sugestões escolhidas: {"relink": true} aponta as fotos para os arquivos movidos
+// e {"adopt": true} importa os arquivos sem foto. Por segurança, o padrão é a simulação: nada é alterado
+// sem "dry_run": false.
+func (h *StorageDriftHandler) ReconcileHandler(c *gin.Context) {
+	var req struct {
+		Relink bool  `json:"relink"`
+		Adopt  bool  `json:"adopt"`
+		DryRun *bool `json:"dry_run"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, i18n.CodeStorageDriftReconcileInvalid)
+			return
+		}
+	}
+
+	report, err := h.Drift.Reconcile(c.Request.Context(), service.StorageDriftOptions{
+		Relink: req.Relink,
+		Adopt:  req.Adopt,
+		DryRun: req.DryRun == nil || *req.DryRun,
+	})
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeStorageDriftReconcileFailed, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": storageDriftResponse(report)})
+}
+
+// storageDriftResponse formata o relatório da verificação do armazenamento para a resposta da API.
+func storageDriftResponse(report *service.StorageDriftReport) gin.H {
+	suggestions := make([]gin.H, 0, len(report.Suggestions))
+	for _, suggestion := range report.Suggestions {
+		item := gin.H{
+			"kind":          suggestion.Kind,
+			"path":          suggestion.Path,
+			"photo_id":      suggestion.PhotoID,
+			"stored_path":   suggestion.StoredPath,
+			"size":          suggestion.Size,
+			"expected_size": suggestion.ExpectedSize,
+			"applied":       suggestion.Applied,
+		}
+		if suggestion.Error != "" {
+			item["error"] = suggestion.Error
+		}
+		suggestions = append(suggestions, item)
+	}
+
+	counts := gin.H{}
+	for _, kind := range []string{service.DriftAdopt, service.DriftRelink, service.DriftDuplicate, service.DriftSizeMismatch, service.DriftMissing} {
+		counts[kind] = report.Count(kind)
+	}
+	return gin.H{
+		"dry_run":         report.DryRun,
+		"scanned_files":   report.ScannedFiles,
+		"checked_photos":  report.CheckedPhotos,
+		"offline_volumes": report.OfflineVolumes,
+		"counts":          counts,
+		"suggestions":     suggestions,
+		"relinked":        report.Relinked,
+		"adopted":         report.Adopted,
+	}
+}
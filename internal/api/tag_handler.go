@@ -0,0 +1,147 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TagHandler gerencia as requisições HTTP para tags.
+type TagHandler struct {
+	TagService *service.TagService
+}
+
+// NewTagHandler cria uma nova instância de TagHandler.
+func NewTagHandler(s *service.TagService) *TagHandler {
+	return &TagHandler{
+		TagService: s,
+	}
+}
+
+// ListTagsHandler lista as tags com a contagem de fotos e o total de bytes de cada uma.
+func (h *TagHandler) ListTagsHandler(c *gin.Context) {
+	tags, err := h.TagService.ListTags()
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeTagsFetchFailed, err)
+		return
+	}
+
+	responseTags := []gin.H{}
+	for _, tag := range tags {
+		responseTags = append(responseTags, gin.H{
+			"id":          tag.ID,
+			"name":        tag.Name,
+			"photo_count": tag.PhotoCount,
+			"total_bytes": tag.TotalBytes,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": responseTags})
+}
+
+// SetPhotoTagsHandler substitui as tags de uma foto.
+func (h *TagHandler) SetPhotoTagsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidPhotoID)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Tags    []string `json:"tags"`
+		Version string   `json:"version"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeTagsInvalid)
+		return
+	}
+
+	photo, err := h.TagService.SetPhotoTags(id, req.Tags, expectedPhotoVersion(c, req.Version))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodePhotoNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrPhotoLocked) {
+			respondError(c, http.StatusLocked, i18n.CodePhotoLocked)
+			return
+		}
+		if respondPhotoVersionConflict(c, err) {
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeTagsUpdateFailed, err)
+		return
+	}
+
+	setPhotoETag(c, *photo)
+	c.JSON(http.StatusOK, gin.H{"data": photoResponse(*photo)})
+}
+
+// RenameTagHandler renomeia uma tag em todas as fotos marcadas com ela.
+func (h *TagHandler) RenameTagHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", i18n.CodeInvalidTagID)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeTagNameInvalid)
+		return
+	}
+
+	tag, photoCount, err := h.TagService.RenameTag(id, req.Name)
+	if err != nil {
+		respondTagError(c, err, i18n.CodeTagRenameFailed)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"id": tag.ID, "name": tag.Name, "photo_count": photoCount}})
+}
+
+// MergeTagsHandler mescla as tags de origem na tag de destino, removendo as de origem.
+func (h *TagHandler) MergeTagsHandler(c *gin.Context) {
+	var req struct {
+		SourceIDs []uint `json:"source_ids" binding:"required,min=1"`
+		TargetID  uint   `json:"target_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeTagMergeInvalid)
+		return
+	}
+
+	tag, photoCount, err := h.TagService.MergeTags(req.SourceIDs, req.TargetID)
+	if err != nil {
+		respondTagError(c, err, i18n.CodeTagMergeFailed)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"id": tag.ID, "name": tag.Name, "photo_count": photoCount}})
+}
+
+// respondTagError responde com 404 para tags inexistentes, 409 para nomes já usados, 423 quando há fotos
+// bloqueadas, 400 para as demais validações e 500 para os demais erros.
+func respondTagError(c *gin.Context, err error, fallbackCode string) {
+	var lockedErr *service.TagPhotosLockedError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondError(c, http.StatusNotFound, i18n.CodeTagNotFound)
+	case errors.As(err, &lockedErr):
+		c.JSON(http.StatusLocked, gin.H{
+			"error":      lockedErr.Localize(locale(c)),
+			"code":       i18n.CodeTagPhotosLocked,
+			"locked_ids": lockedErr.PhotoIDs,
+		})
+	case i18n.Code(err) == i18n.CodeTagNameConflict:
+		respondServiceError(c, http.StatusConflict, err, fallbackCode)
+	case errors.Is(err, service.ErrTagNameInvalid), errors.Is(err, service.ErrTagMergeInvalid):
+		respondServiceError(c, http.StatusBadRequest, err, fallbackCode)
+	default:
+		respondErrorCause(c, http.StatusInternalServerError, fallbackCode, err)
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TextReplaceHandler gerencia a busca e substituição em lote nas descrições e nas tags.
+type TextReplaceHandler struct {
+	Replace *service.TextReplaceService
+}
+
+// NewTextReplaceHandler cria uma nova instância de TextReplaceHandler.
+func NewTextReplaceHandler(s *service.TextReplaceService) *TextReplaceHandler {
+	return &TextReplaceHandler{
+		Replace: s,
+	}
+}
+
+// ReplaceHandler busca e substitui texto nas descrições das fotos e nos nomes das tags:
+// {"find": "IMG_", "replace": "", "fields": ["description", "tags"]}. Com "regex": true, find é uma
+// expressão regular e replace aceita referências ($1); "ignore_case": true ignora maiúsculas. Por
+// segurança, o padrão é a simulação, que responde a prévia (antes e depois) sem gravar nada; as
+// alterações só são aplicadas com "dry_run": false.
+func (h *TextReplaceHandler) ReplaceHandler(c *gin.Context) {
+	var req struct {
+		Find       string   `json:"find" binding:"required"`
+		Replace    string   `json:"replace"`
+		Regex      bool     `json:"regex"`
+		IgnoreCase bool     `json:"ignore_case"`
+		Fields     []string `json:"fields"`
+		DryRun     *bool    `json:"dry_run"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldRequired, "find")
+		return
+	}
+
+	report, err := h.Replace.Replace(service.TextReplaceOptions{
+		Find:       req.Find,
+		Replace:    req.Replace,
+		Regex:      req.Regex,
+		IgnoreCase: req.IgnoreCase,
+		Fields:     req.Fields,
+		DryRun:     req.DryRun == nil || *req.DryRun,
+	})
+	if err != nil {
+		if i18n.Code(err) == i18n.CodeTextReplaceInvalid {
+			respondServiceError(c, http.StatusBadRequest, err, i18n.CodeTextReplaceInvalid)
+			return
+		}
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeTextReplaceFailed, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": textReplaceResponse(report)})
+}
+
+// textReplaceResponse formata o relatório da substituição para a resposta da API.
+func textReplaceResponse(report *service.TextReplaceReport) gin.H {
+	changes := func(list []service.TextChange, idField string) []gin.H {
+		result := []gin.H{}
+		for _, change := range list {
+			item := gin.H{idField: change.ID, "before": change.Before, "after": change.After}
+			if idField == "tag_id" {
+				item["photo_count"] = change.PhotoCount
+			}
+			if change.Skipped != "" {
+				item["skipped"] = change.Skipped
+			}
+			result = append(result, item)
+		}
+		return result
+	}
+
+	return gin.H{
+		"dry_run":             report.DryRun,
+		"descriptions":        changes(report.Descriptions, "photo_id"),
+		"description_matches": report.DescriptionMatches,
+		"tags":                changes(report.Tags, "tag_id"),
+		"tag_matches":         report.TagMatches,
+		"skipped":             report.Skipped,
+		"truncated":           report.Truncated,
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// throttleChunk é o maior bloco lido de uma vez do corpo limitado, para que a taxa seja suave mesmo com
+// buffers de leitura grandes.
+const throttleChunk = 32 << 10
+
+// UploadThrottle limita a velocidade com que o corpo dos uploads é lido, a uma taxa por cliente (IP).
+// Uploads simultâneos do mesmo cliente dividem a mesma taxa. Como o servidor lê o corpo mais devagar, o
+// controle de fluxo do TCP reduz o envio na origem, e um celular fazendo backup completo não ocupa todo o
+// link. A taxa pode ser alterada com o servidor em execução (ver SetRate).
+type UploadThrottle struct {
+	buckets atomic.Pointer[uploadBuckets] // nil: sem limite
+}
+
+// NewUploadThrottle cria um UploadThrottle com bytesPerSecond por cliente. bytesPerSecond 0 desativa o limite.
+func NewUploadThrottle(bytesPerSecond int64) *UploadThrottle {
+	t := &UploadThrottle{}
+	t.SetRate(bytesPerSecond)
+	return t
+}
+
+// SetRate altera a taxa por cliente. Os uploads em andamento terminam na taxa anterior; a nova vale para os
+// próximos.
+func (t *UploadThrottle) SetRate(bytesPerSecond int64) {
+	if bytesPerSecond <= 0 {
+		if t.buckets.Swap(nil) != nil {
+			log.Println("Uploads sem limite de velocidade")
+		}
+		return
+	}
+	if current := t.buckets.Load(); current != nil && current.rate == float64(bytesPerSecond) {
+		return
+	}
+	t.buckets.Store(&uploadBuckets{rate: float64(bytesPerSecond), clients: make(map[string]*clientBucket)})
+	log.Printf("Uploads limitados a %d KB/s por cliente\n", bytesPerSecond>>10)
+}
+
+// Handler retorna o middleware que aplica a taxa atual.
+func (t *UploadThrottle) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		buckets := t.buckets.Load()
+		if buckets == nil || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		ip := c.ClientIP()
+		bucket := buckets.acquire(ip)
+		defer buckets.release(ip)
+
+		c.Request.Body = &throttledBody{ReadCloser: c.Request.Body, ctx: c.Request.Context(), bucket: bucket}
+		c.Next()
+	}
+}
+
+// uploadBuckets mantém um balde de tokens por cliente enquanto ele tiver uploads em andamento.
+type uploadBuckets struct {
+	rate    float64
+	mu      sync.Mutex
+	clients map[string]*clientBucket
+}
+
+// clientBucket é o balde de um cliente e a quantidade de uploads que o usam.
+type clientBucket struct {
+	*tokenBucket
+	refs int
+}
+
+func (b *uploadBuckets) acquire(ip string) *tokenBucket {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	client, ok := b.clients[ip]
+	if !ok {
+		client = &clientBucket{tokenBucket: newTokenBucket(b.rate)}
+		b.clients[ip] = client
+	}
+	client.refs++
+	return client.tokenBucket
+}
+
+func (b *uploadBuckets) release(ip string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if client, ok := b.clients[ip]; ok {
+		if client.refs--; client.refs <= 0 {
+			delete(b.clients, ip)
+		}
+	}
+}
+
+// tokenBucket libera rate bytes por segundo, acumulando no máximo um segundo de folga (burst).
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64) *tokenBucket {
+	return &tokenBucket{rate: rate, tokens: rate, last: time.Now()}
+}
+
+// take consome n bytes do balde, esperando o tempo necessário (ou até ctx ser cancelado).
+func (b *tokenBucket) take(ctx context.Context, n int) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+	// O saldo pode ficar negativo: a espera corresponde ao tempo para quitá-lo, e as leituras seguintes
+	// do mesmo cliente esperam a sua vez
+	b.tokens -= float64(n)
+	wait := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// throttledBody lê o corpo da requisição respeitando o balde do cliente.
+type throttledBody struct {
+	io.ReadCloser
+	ctx    context.Context
+	bucket *tokenBucket
+}
+
+func (r *throttledBody) Read(p []byte) (int, error) {
+	if len(p) > throttleChunk {
+		p = p[:throttleChunk]
+	}
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		if waitErr := r.bucket.take(r.ctx, n); waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	return n, err
+}
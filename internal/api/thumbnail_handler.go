@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ThumbnailHandler expõe as métricas e o lote de geração das miniaturas.
+type ThumbnailHandler struct {
+	ThumbnailService *service.ThumbnailService
+}
+
+// NewThumbnailHandler cria uma nova instância de ThumbnailHandler.
+func NewThumbnailHandler(s *service.ThumbnailService) *ThumbnailHandler {
+	return &ThumbnailHandler{
+		ThumbnailService: s,
+	}
+}
+
+// MetricsHandler retorna a política de geração, a profundidade da fila e a latência das gerações.
+func (h *ThumbnailHandler) MetricsHandler(c *gin.Context) {
+	metrics := h.ThumbnailService.Metrics()
+
+	lastBackfillAt := ""
+	if metrics.LastBackfillAt != nil {
+		lastBackfillAt = metrics.LastBackfillAt.Format(time.RFC3339)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"policy":               metrics.Policy,
+		"workers":              metrics.Workers,
+		"queue_depth":          metrics.QueueDepth,
+		"queue_capacity":       metrics.QueueCapacity,
+		"in_flight":            metrics.InFlight,
+		"render_workers":       metrics.RenderWorkers,
+		"rendering":            metrics.Rendering,
+		"queue_timeout_ms":     metrics.QueueTimeout.Milliseconds(),
+		"busy":                 metrics.Busy,
+		"generated":            metrics.Generated,
+		"failed":               metrics.Failed,
+		"shared":               metrics.Shared,
+		"dropped":              metrics.Dropped,
+		"avg_latency_ms":       metrics.AvgLatency.Milliseconds(),
+		"max_latency_ms":       metrics.MaxLatency.Milliseconds(),
+		"last_backfill_at":     lastBackfillAt,
+		"last_backfill_queued": metrics.LastBackfillQueued,
+	}})
+}
+
+// BackfillHandler enfileira, em background, a geração das miniaturas de todas as fotos que ainda não
+// têm uma (202 Accepted), independentemente da política configurada.
+func (h *ThumbnailHandler) BackfillHandler(c *gin.Context) {
+	go func() {
+		queued, err := h.ThumbnailService.Backfill(context.Background())
+		if err != nil {
+			log.Printf("Miniaturas: falha no lote: %v\n", err)
+			return
+		}
+		log.Printf("Miniaturas: %d fotos enfileiradas pelo lote\n", queued)
+	}()
+	c.JSON(http.StatusAccepted, gin.H{"message": message(c, i18n.CodeThumbnailBackfillStarted)})
+}
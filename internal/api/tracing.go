@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"photo-manager/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tracing abre um span para cada requisição, continuando o trace do cabeçalho traceparent quando o cliente
+// (ou um proxy) o envia. Os spans dos serviços, do banco e do armazenamento ficam aninhados nele pelo
+// contexto da requisição, e o ID do trace é devolvido em X-Trace-Id e nas respostas de erro.
+func Tracing() gin.HandlerFunc {
+	if !tracing.Enabled() {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "(sem rota)"
+		}
+		ctx, span := tracing.StartRemote(c.Request.Context(), c.GetHeader("traceparent"), c.Request.Method+" "+route,
+			tracing.String("http.method", c.Request.Method),
+			tracing.String("http.route", route),
+			tracing.String("http.target", c.Request.URL.Path),
+			tracing.String("http.client_ip", c.ClientIP()),
+			tracing.String("http.user_agent", c.Request.UserAgent()),
+		)
+		c.Request = c.Request.WithContext(ctx)
+		c.Header("X-Trace-Id", span.TraceID.String())
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttr(
+			tracing.Int("http.status_code", int64(status)),
+			tracing.Int("http.response_size", int64(c.Writer.Size())),
+		)
+		var err error
+		if status >= http.StatusInternalServerError {
+			err = fmt.Errorf("HTTP %d", status)
+			if last := c.Errors.Last(); last != nil {
+				err = last.Err
+			}
+		}
+		span.Finish(err)
+	}
+}
+
+// traceID retorna o ID do trace da requisição (vazio com o tracing desativado).
+func traceID(c *gin.Context) string {
+	return tracing.TraceIDFromContext(c.Request.Context())
+}
+
+// errorBody monta o corpo das respostas de erro, com o ID do trace quando houver.
+func errorBody(c *gin.Context, text, code string) gin.H {
+	body := gin.H{"error": text, "code": code}
+	if id := traceID(c); id != "" {
+		body["trace_id"] = id
+	}
+	return body
+}
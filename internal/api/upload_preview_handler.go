@@ -0,0 +1,145 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"photo-manager/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreviewUploadHandler simula um upload (POST /upload/preview): recebe os mesmos campos de POST /upload e
+// informa, para cada arquivo, se a foto seria criada (com o caminho de destino), ignorada como duplicata ou
+// rejeitada, sem gravar nada. Útil para clientes de sincronização e interfaces que mostram o resultado antes
+// de confirmar o envio.
+func (h *PhotoHandler) PreviewUploadHandler(c *gin.Context) {
+	form, err := c.MultipartForm()
+	if limit, tooLarge := bodyTooLarge(err); tooLarge {
+		respondError(c, http.StatusRequestEntityTooLarge, i18n.CodeRequestTooLarge, limit>>20)
+		return
+	}
+	if err != nil {
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeMultipartInvalid, err)
+		return
+	}
+
+	files := form.File["photos"]
+	if len(files) == 0 {
+		respondError(c, http.StatusBadRequest, i18n.CodeUploadNoFiles)
+		return
+	}
+	checksums, ok := uploadChecksums(c, form, len(files))
+	if !ok {
+		return
+	}
+	origin, ok := uploadOrigin(c, form)
+	if !ok {
+		return
+	}
+
+	results := []gin.H{}
+	summary := gin.H{service.PreviewCreate: 0, service.PreviewDuplicate: 0, service.PreviewReject: 0}
+	batchHashes := map[string]string{} // Hash -> nome do primeiro arquivo do lote com esse conteúdo
+	batchNames := map[string]bool{}
+
+	ctx := c.Request.Context()
+	for i, file := range files {
+		if ctx.Err() != nil {
+			return
+		}
+		expectedSHA256 := ""
+		if len(checksums) > 0 {
+			expectedSHA256 = checksums[i]
+		}
+
+		preview, err := h.PhotoService.PreviewUpload(ctx, file, expectedSHA256, origin)
+		if err != nil {
+			log.Printf("Erro ao simular o upload da foto '%s': %v\n", file.Filename, err)
+			respondErrorCause(c, http.StatusInternalServerError, i18n.CodeUploadPreviewFailed, err)
+			return
+		}
+
+		// Arquivos repetidos dentro do próprio lote também seriam ignorados ou recusados
+		if preview.Action == service.PreviewCreate {
+			if first, seen := batchHashes[preview.Photo.Hash]; seen {
+				preview.Action = service.PreviewDuplicate
+				result := previewResponse(c, file.Filename, preview)
+				result["duplicate_of_file"] = first
+				results = append(results, result)
+				summary[preview.Action] = summary[preview.Action].(int) + 1
+				continue
+			}
+			if batchNames[file.Filename] {
+				preview.Action, preview.Rejection = service.PreviewReject, i18n.NewError(i18n.CodeFilenameTaken, file.Filename)
+			}
+		}
+		if preview.Action == service.PreviewCreate {
+			batchHashes[preview.Photo.Hash] = file.Filename
+			batchNames[file.Filename] = true
+		}
+
+		results = append(results, previewResponse(c, file.Filename, preview))
+		summary[preview.Action] = summary[preview.Action].(int) + 1
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"files": results, "summary": summary}})
+}
+
+// previewResponse monta o resultado previsto para um arquivo.
+func previewResponse(c *gin.Context, filename string, preview *service.UploadPreview) gin.H {
+	result := gin.H{"filename": filename, "action": preview.Action}
+	photo := preview.Photo
+	if photo.Hash != "" {
+		result["hash"] = photo.Hash
+	}
+	// Duplicatas são detectadas antes da leitura das dimensões e não trazem os demais metadados
+	if photo.MimeType != "" {
+		result["mime_type"] = photo.MimeType
+		result["file_size"] = photo.FileSize
+		result["width"] = photo.Width
+		result["height"] = photo.Height
+		result["exif_date"] = ""
+		if photo.ExifDate != nil {
+			result["exif_date"] = photo.ExifDate.Format(time.RFC3339)
+		}
+		result["camera_make"] = photo.CameraMake
+		result["camera_model"] = photo.CameraModel
+		result["latitude"] = photo.Latitude
+		result["longitude"] = photo.Longitude
+	}
+
+	switch preview.Action {
+	case service.PreviewCreate:
+		result["target_path"] = preview.TargetPath
+		result["volume"] = preview.Volume
+		result["downscale"] = preview.Downscale
+		result["tags"] = preview.Tags
+		result["albums"] = preview.Albums
+		if preview.Duplicate != nil {
+			// Cópia de uma foto existente, gravada pela política de duplicatas version da origem
+			result["stored_filename"] = photo.Filename
+			result["duplicate_policy"] = preview.Policy
+			result["version_of"] = gin.H{"id": preview.Duplicate.ID, "filename": preview.Duplicate.Filename, "stored_path": preview.Duplicate.StoredPath}
+		}
+	case service.PreviewDuplicate:
+		if preview.Duplicate != nil {
+			result["duplicate_of"] = gin.H{"id": preview.Duplicate.ID, "filename": preview.Duplicate.Filename, "stored_path": preview.Duplicate.StoredPath}
+		}
+		if preview.Policy != "" {
+			result["duplicate_policy"] = preview.Policy
+		}
+	case service.PreviewReject:
+		result["error"] = i18n.Localize(preview.Rejection, locale(c))
+		result["code"] = i18n.Code(preview.Rejection)
+		if validationErr, ok := validation.AsError(preview.Rejection); ok {
+			result["error"] = validationErr.Localize(locale(c))
+			result["code"] = validationErr.Code
+			result["validator"] = validationErr.Validator
+		}
+	}
+	return result
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"photo-manager/internal/i18n"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Versionamento da API REST. As rotas atuais respondem sob /api/v1 (ex: /api/v1/photos) e, por
+// compatibilidade com os clientes existentes (apps de sincronização, scripts), também sem o prefixo. Uma
+// mudança incompatível (formato das respostas, paginação) entra em uma nova versão: acrescentada a
+// APIVersions e registrada no roteador sob o próprio prefixo (ex: router.Group("/api/v2")), sem alterar as
+// rotas das versões anteriores.
+const (
+	apiPrefix = "/api/"
+
+	// CompatAPIVersion é a versão atendida pelas rotas sem prefixo, que nunca muda: clientes antigos
+	// continuam recebendo as respostas no formato que conhecem.
+	CompatAPIVersion = "v1"
+)
+
+// APIVersions são as versões publicadas da API, da mais antiga para a mais recente.
+var APIVersions = []string{CompatAPIVersion}
+
+type apiVersionKey struct{}
+
+// Versioned atende as rotas da API sob /api/<versão>: as de CompatAPIVersion são as mesmas rotas sem prefixo,
+// e as demais versões seguem para o roteador como vieram (registradas sob o próprio prefixo). Versões
+// desconhecidas recebem 404. A versão atendida é informada em X-API-Version e, aos handlers, em APIVersion.
+func Versioned(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version := CompatAPIVersion
+		if rest, ok := strings.CutPrefix(r.URL.Path, apiPrefix); ok && rest != "" {
+			requested, _, _ := strings.Cut(rest, "/")
+			if !slices.Contains(APIVersions, requested) {
+				respondUnsupportedVersion(w, r, requested)
+				return
+			}
+			version = requested
+			if version == CompatAPIVersion {
+				r = stripVersionPrefix(r, apiPrefix+version)
+			}
+		}
+
+		w.Header().Set("X-API-Version", version)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiVersionKey{}, version)))
+	})
+}
+
+// APIVersion retorna a versão da API pedida na requisição (CompatAPIVersion nas rotas sem prefixo), para
+// handlers compartilhados entre versões que precisem responder conforme ela.
+func APIVersion(c *gin.Context) string {
+	if version, ok := c.Request.Context().Value(apiVersionKey{}).(string); ok {
+		return version
+	}
+	return CompatAPIVersion
+}
+
+// VersionsHandler lista as versões publicadas da API (GET /api).
+func VersionsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"versions": APIVersions,
+		"latest":   APIVersions[len(APIVersions)-1],
+		"default":  CompatAPIVersion, // Versão das rotas sem prefixo
+	}})
+}
+
+// stripVersionPrefix retorna uma cópia da requisição com o caminho sem o prefixo da versão.
+func stripVersionPrefix(r *http.Request, prefix string) *http.Request {
+	stripped := new(http.Request)
+	*stripped = *r
+	u := *r.URL
+	u.Path = strings.TrimPrefix(u.Path, prefix)
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	if u.RawPath != "" {
+		u.RawPath = strings.TrimPrefix(u.RawPath, prefix)
+	}
+	stripped.URL = &u
+	return stripped
+}
+
+// respondUnsupportedVersion responde 404 para uma versão da API inexistente, no formato de erro da API. A
+// requisição ainda não passou pelo roteador, então o idioma é negociado aqui.
+func respondUnsupportedVersion(w http.ResponseWriter, r *http.Request, version string) {
+	locale := i18n.Negotiate(r.Header.Get("Accept-Language"))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Language", locale)
+	w.WriteHeader(http.StatusNotFound)
+	_ = json.NewEncoder(w).Encode(gin.H{
+		"error": i18n.Message(locale, i18n.CodeAPIVersionUnsupported, version, strings.Join(APIVersions, ", ")),
+		"code":  i18n.CodeAPIVersionUnsupported,
+	})
+}
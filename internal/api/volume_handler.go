@@ -0,0 +1,128 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// VolumeHandler gerencia as requisições HTTP para volumes de armazenamento.
+type VolumeHandler struct {
+	VolumeService *service.VolumeService
+}
+
+// NewVolumeHandler cria uma nova instância de VolumeHandler.
+func NewVolumeHandler(s *service.VolumeService) *VolumeHandler {
+	return &VolumeHandler{
+		VolumeService: s,
+	}
+}
+
+// ListVolumesHandler lista os volumes registrados.
+func (h *VolumeHandler) ListVolumesHandler(c *gin.Context) {
+	volumes, err := h.VolumeService.ListVolumes()
+	if err != nil {
+		respondErrorCause(c, http.StatusInternalServerError, i18n.CodeVolumesFetchFailed, err)
+		return
+	}
+
+	responseVolumes := []gin.H{}
+	for _, volume := range volumes {
+		responseVolumes = append(responseVolumes, volumeResponse(volume))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": responseVolumes})
+}
+
+// CreateVolumeHandler registra um novo volume de armazenamento.
+// As datas opcionais (formato AAAA-MM-DD) são usadas pela política de alocação "date-range".
+func (h *VolumeHandler) CreateVolumeHandler(c *gin.Context) {
+	var req struct {
+		Name     string `json:"name" binding:"required"`
+		Path     string `json:"path" binding:"required"`
+		Priority int    `json:"priority"`
+		DateFrom string `json:"date_from"`
+		DateTo   string `json:"date_to"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeFieldsRequired, "'name', 'path'")
+		return
+	}
+
+	volume := database.StorageVolume{
+		Name:     req.Name,
+		Path:     req.Path,
+		Priority: req.Priority,
+	}
+	if req.DateFrom != "" {
+		dateFrom, err := time.Parse("2006-01-02", req.DateFrom)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, i18n.CodeInvalidStartDate)
+			return
+		}
+		volume.DateFrom = &dateFrom
+	}
+	if req.DateTo != "" {
+		dateTo, err := time.Parse("2006-01-02", req.DateTo)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, i18n.CodeInvalidEndDate)
+			return
+		}
+		endOfDay := dateTo.AddDate(0, 0, 1).Add(-time.Nanosecond) // Inclui o dia inteiro
+		volume.DateTo = &endOfDay
+	}
+
+	created, err := h.VolumeService.CreateVolume(volume)
+	if err != nil {
+		respondErrorCause(c, http.StatusBadRequest, i18n.CodeVolumeRegisterFailed, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": volumeResponse(*created)})
+}
+
+// DeleteVolumeHandler remove o registro de um volume vazio.
+func (h *VolumeHandler) DeleteVolumeHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, i18n.CodeInvalidVolumeID)
+		return
+	}
+
+	if err := h.VolumeService.DeleteVolume(uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, i18n.CodeVolumeNotFound)
+			return
+		}
+		respondErrorCause(c, http.StatusConflict, i18n.CodeVolumeDeleteFailed, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message(c, i18n.CodeVolumeDeleted)})
+}
+
+// volumeResponse formata um StorageVolume para a resposta da API.
+func volumeResponse(volume database.StorageVolume) gin.H {
+	formatDate := func(t *time.Time) string {
+		if t != nil {
+			return t.Format("2006-01-02")
+		}
+		return ""
+	}
+
+	return gin.H{
+		"id":        volume.ID,
+		"name":      volume.Name,
+		"path":      volume.Path,
+		"priority":  volume.Priority,
+		"date_from": formatDate(volume.DateFrom),
+		"date_to":   formatDate(volume.DateTo),
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+	"photo-manager/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkerHandler expõe as métricas dos workers em segundo plano (miniaturas, importações, hooks).
+type WorkerHandler struct {
+	Pools []service.PoolReporter
+}
+
+// NewWorkerHandler cria uma nova instância de WorkerHandler.
+func NewWorkerHandler(pools []service.PoolReporter) *WorkerHandler {
+	return &WorkerHandler{
+		Pools: pools,
+	}
+}
+
+// MetricsHandler retorna, para cada conjunto de workers, quantos trabalham, a ocupação da fila e quantas
+// tarefas foram recusadas por fila cheia. Recusas crescendo indicam que vale aumentar os workers ou a fila.
+func (h *WorkerHandler) MetricsHandler(c *gin.Context) {
+	pools := []gin.H{}
+	for _, reporter := range h.Pools {
+		for _, metrics := range reporter.PoolMetrics() {
+			pools = append(pools, gin.H{
+				"name":           metrics.Name,
+				"workers":        metrics.Workers,
+				"active":         metrics.Active,
+				"queue_depth":    metrics.QueueDepth,
+				"queue_capacity": metrics.QueueCapacity,
+				"saturated":      metrics.QueueDepth >= metrics.QueueCapacity,
+				"completed":      metrics.Completed,
+				"rejected":       metrics.Rejected,
+			})
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": pools})
+}
@@ -0,0 +1,668 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"photo-manager/internal/hooks"
+	"photo-manager/internal/logger"
+	"photo-manager/internal/notify"
+	"photo-manager/internal/storage"
+	"photo-manager/internal/thumbnail"
+	"photo-manager/internal/validation"
+)
+
+// Config contém as configurações da aplicação, lidas das variáveis de ambiente e do arquivo de configuração.
+type Config struct {
+	ConfigFile string // Arquivo YAML com as configurações não definidas no ambiente (CONFIG_FILE; vazio desativa)
+	LogLevel   string // Detalhe do log das requisições e do banco: debug, info, warn ou error (LOG_LEVEL)
+
+	// Valor efetivo de cada variável lida, para comparar configurações numa recarga
+	values map[string]string
+
+	Port             string        // Porta HTTP (APP_PORT)
+	AdminToken       string        // Token exigido nas rotas de administração e de desbloqueio (ADMIN_TOKEN; vazio deixa as rotas abertas)
+	DatabasePath     string        // Caminho do banco SQLite (DATABASE_URL)
+	QueryTimeout     time.Duration // Duração máxima de cada comando SQL (DB_QUERY_TIMEOUT_SECONDS, 0 desativa)
+	DBBackupPath     string        // Diretório das cópias de segurança do banco (DB_BACKUP_PATH)
+	DBBackupKeep     int           // Cópias do banco mantidas; as mais antigas são apagadas (DB_BACKUP_KEEP, 0 mantém todas)
+	DBMaintenanceAt  int           // Hora da manutenção diária do banco: cópia, ANALYZE e VACUUM, 0 a 23 (DB_MAINTENANCE_HOUR; -1 desativa)
+	PhotoStoragePath string        // Diretório do volume padrão de fotos (PHOTO_STORAGE_PATH)
+	ThumbnailPath    string        // Diretório das miniaturas geradas (THUMBNAIL_PATH)
+	ThumbnailMaxSize int           // Tamanho do maior lado das miniaturas, em pixels (THUMBNAIL_MAX_SIZE)
+	ThumbnailPolicy  string        // Geração das miniaturas: lazy, eager ou scheduled (THUMBNAIL_POLICY)
+	ThumbnailWorkers int           // Miniaturas geradas simultaneamente em segundo plano (THUMBNAIL_WORKERS)
+	ThumbnailHour    int           // Hora do lote diário de miniaturas na política scheduled, 0 a 23 (THUMBNAIL_BACKFILL_HOUR)
+	ThumbnailRenders int           // Máximo de miniaturas geradas ao mesmo tempo, sob demanda e em segundo plano (THUMBNAIL_RENDER_WORKERS)
+	ThumbnailWait    time.Duration // Espera máxima pelo início da geração sob demanda antes de responder 202 (THUMBNAIL_QUEUE_TIMEOUT_SECONDS, 0 desativa)
+	ThumbnailQueue   int           // Fotos aguardando a geração em segundo plano; acima disso ficam para a geração sob demanda (THUMBNAIL_QUEUE_SIZE)
+	ThumbnailPending int           // Fotos aguardando um worker de geração; acima disso a geração sob demanda responde 202 na hora (THUMBNAIL_MAX_PENDING)
+	ImportWorkers    int           // Importações executadas simultaneamente (IMPORT_WORKERS)
+	ImportQueue      int           // Importações aguardando; acima disso POST /imports responde 429 (IMPORT_QUEUE_SIZE)
+	ThumbnailResizer string        // Implementação que gera as miniaturas: go ou vips (THUMBNAIL_RESIZER)
+	VipsThumbnail    string        // Executável do vipsthumbnail usado por THUMBNAIL_RESIZER=vips (VIPS_THUMBNAIL_PATH)
+	QuarantinePath   string        // Diretório para onde vão fotos que falham repetidamente ao serem decodificadas (QUARANTINE_PATH)
+	FFmpegPath       string        // Executável do ffmpeg para transcodificar vídeos (FFMPEG_PATH; vazio desativa)
+	VideoCachePath   string        // Diretório dos vídeos transcodificados para HLS (VIDEO_CACHE_PATH)
+	VideoWorkers     int           // Vídeos transcodificados ao mesmo tempo (VIDEO_TRANSCODE_WORKERS)
+	ExportPath       string        // Diretório dos arquivos da exportação completa da biblioteca (EXPORT_PATH)
+
+	DownloadPrepareBytes int64 // Downloads de seleções acima deste tamanho são preparados em background (DOWNLOAD_PREPARE_THRESHOLD_MB, 0 desativa)
+
+	MaxRequestBodyBytes   int64 // Tamanho máximo do corpo das requisições, exceto uploads (MAX_REQUEST_BODY_MB, 0 desativa)
+	MaxUploadRequestBytes int64 // Tamanho máximo do corpo de cada requisição de upload, com todos os arquivos (UPLOAD_MAX_REQUEST_MB, 0 desativa)
+	UploadBandwidth       int64 // Velocidade máxima de recebimento dos uploads de cada cliente, em bytes/s (UPLOAD_BANDWIDTH_KBPS, 0 desativa)
+
+	// Servidor HTTP: modo do Gin, proxies reversos confiáveis e TLS
+	GinMode          string      // debug, release ou test (GIN_MODE)
+	TrustedProxies   []string    // IPs ou CIDRs dos proxies cujos cabeçalhos X-Forwarded-For/X-Real-IP são aceitos (TRUSTED_PROXIES; vazio não confia em nenhum)
+	ClientIPHeader   string      // Cabeçalho definido pela plataforma com o IP do cliente, ex: CF-Connecting-IP (CLIENT_IP_HEADER; vazio desativa)
+	TLSCertFile      string      // Certificado TLS em PEM (TLS_CERT_FILE; requer TLS_KEY_FILE)
+	TLSKeyFile       string      // Chave privada do certificado (TLS_KEY_FILE)
+	AutocertDomains  []string    // Domínios com certificado obtido automaticamente do Let's Encrypt (TLS_AUTOCERT_DOMAINS)
+	AutocertEmail    string      // E-mail de contato da conta ACME (TLS_AUTOCERT_EMAIL; opcional)
+	AutocertCacheDir string      // Diretório dos certificados obtidos (TLS_AUTOCERT_CACHE)
+	HTTPRedirectPort string      // Porta HTTP que redireciona para HTTPS e responde aos desafios ACME (TLS_HTTP_REDIRECT_PORT; vazio desativa)
+	Listeners        []Listener  // Endereços onde o servidor atende (LISTEN_ADDRS; vazio usa APP_PORT em todas as interfaces)
+	SocketMode       os.FileMode // Permissões dos sockets Unix criados (LISTEN_SOCKET_MODE, em octal)
+	HTTP2Enabled     bool        // Negocia HTTP/2 nas conexões HTTPS (HTTP2_ENABLED)
+	CompressionLevel int         // Nível do gzip/deflate das respostas textuais, 1 a 9 (COMPRESSION_LEVEL, 0 desativa)
+	CompressionMin   int         // Respostas com tamanho conhecido menor que isto não são comprimidas (COMPRESSION_MIN_BYTES)
+
+	PlacementPolicy      string // Política de alocação entre volumes (STORAGE_PLACEMENT_POLICY)
+	StorageLayout        string // Organização dos arquivos nos volumes: date ou hash (STORAGE_LAYOUT)
+	StorageReserveBytes  uint64 // Espaço livre mínimo mantido em cada volume (STORAGE_RESERVE_MB)
+	LowSpaceWarningBytes uint64 // Limite de espaço livre abaixo do qual um aviso é emitido (STORAGE_LOW_SPACE_WARNING_MB)
+	StorageSyncDir       bool   // Sincroniza também a pasta após gravar cada foto, para durabilidade (STORAGE_FSYNC_DIR)
+
+	StorageDriftInterval time.Duration // Intervalo da verificação de divergências entre os volumes e o banco (STORAGE_DRIFT_SCAN_INTERVAL_MINUTES, 0 desativa)
+
+	DownscaleMaxPixels int64  // Fotos acima deste total de pixels são reduzidas na ingestão (DOWNSCALE_MAX_MEGAPIXELS, 0 desativa)
+	OriginalsPath      string // Diretório dos originais das fotos reduzidas (ORIGINALS_PATH; vazio descarta os originais)
+
+	AlbumPolicyInterval  time.Duration // Intervalo de execução das políticas de ciclo de vida dos álbuns (ALBUM_POLICY_INTERVAL_MINUTES, 0 desativa)
+	AlbumCleanupInterval time.Duration // Intervalo da remoção automática das associações órfãs entre álbuns e fotos (ALBUM_ORPHAN_CLEANUP_INTERVAL_MINUTES, 0 desativa)
+	AccessFlushInterval  time.Duration // Intervalo de gravação das estatísticas de acesso das fotos (ACCESS_STATS_FLUSH_SECONDS)
+
+	LedgerEnabled bool   // Registra o hash de cada foto no livro-razão encadeado de integridade (LEDGER_ENABLED)
+	LedgerTSAURL  string // Autoridade de carimbo de tempo RFC 3161 para a cabeça do livro-razão (LEDGER_TSA_URL; vazio desativa)
+
+	// Provedores de notificação e os eventos enviados a cada um (NOTIFY_TELEGRAM_*, NOTIFY_NTFY_*, NOTIFY_GOTIFY_*)
+	Notify notify.Options
+
+	RulesFile string // Script de regras de organização avaliado na ingestão (RULES_FILE; vazio desativa)
+
+	// Fuso padrão da linha do tempo e dos filtros por ano e mês: nome IANA, local (servidor) ou photo (fuso de
+	// cada foto) (TIMELINE_TIMEZONE)
+	TimelineTimeZone string
+
+	// Galeria pública (portfólio): álbuns expostos sem token, somente leitura, em /gallery
+	PublicAlbumIDs       []uint        // Álbuns publicados (PUBLIC_GALLERY_ALBUMS; vazio desativa a galeria)
+	PublicImageSize      int           // Maior lado das imagens servidas pela galeria, em pixels (PUBLIC_GALLERY_IMAGE_SIZE)
+	PublicRateLimit      int           // Requisições por minuto aceitas de cada IP na galeria (PUBLIC_GALLERY_RATE_LIMIT, 0 desativa)
+	PublicCacheMaxAge    time.Duration // max-age do Cache-Control das respostas da galeria (PUBLIC_GALLERY_CACHE_SECONDS)
+	PublicAllowedReferer []string      // Hosts autorizados a incorporar as imagens, além do próprio (PUBLIC_GALLERY_ALLOWED_REFERERS)
+
+	// Tracing (OpenTelemetry): spans das requisições, serviços, banco e armazenamento enviados por OTLP/HTTP
+	TracingEndpoint    string            // URL base do coletor, ex: http://localhost:4318 (OTEL_EXPORTER_OTLP_ENDPOINT; vazio desativa)
+	TracingServiceName string            // service.name dos spans (OTEL_SERVICE_NAME)
+	TracingSampleRatio float64           // Fração dos traces registrados, de 0 a 1 (OTEL_TRACES_SAMPLER_ARG)
+	TracingHeaders     map[string]string // Cabeçalhos do envio ao coletor (OTEL_EXPORTER_OTLP_HEADERS, ex: authorization=Bearer x)
+
+	// Comandos ou URLs executados antes de aceitar e depois de ingerir cada arquivo (HOOK_PRE_INGEST*, HOOK_POST_INGEST*)
+	Hooks hooks.Options
+
+	// Limites da validação de arquivos (UPLOAD_MAX_SIZE_MB, UPLOAD_ALLOWED_TYPES, UPLOAD_MAX_DIMENSION, UPLOAD_MAX_MEGAPIXELS, UPLOAD_SCAN_COMMAND)
+	Validation validation.Options
+}
+
+// loadMu serializa os Load (ex: uma recarga por SIGHUP simultânea a uma pelo endpoint).
+var loadMu sync.Mutex
+
+// Load lê a configuração das variáveis de ambiente e do arquivo de configuração (CONFIG_FILE), aplicando
+// valores padrão quando ausentes. As variáveis de ambiente têm prioridade sobre o arquivo. Valores
+// inválidos e chaves desconhecidas no arquivo são rejeitados, com a linha correspondente.
+func Load() (*Config, error) {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	s, err := readConfigFile(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return nil, err
+	}
+	state = s
+	defer func() { state = nil }()
+
+	cfg, err := load()
+	if err != nil {
+		return nil, s.locate(err)
+	}
+	if err := s.checkUnknown(); err != nil {
+		return nil, err
+	}
+	cfg.ConfigFile = s.path
+	cfg.values = s.read
+	return cfg, nil
+}
+
+// load lê cada configuração por lookup.
+func load() (*Config, error) {
+	cfg := &Config{
+		Port:             getEnv("APP_PORT", "8080"),
+		AdminToken:       lookup("ADMIN_TOKEN"),
+		DatabasePath:     getEnvLogged("DATABASE_URL", "./data/photo_manager.db"),
+		PhotoStoragePath: getEnvLogged("PHOTO_STORAGE_PATH", "./data/photos"),
+		ThumbnailPath:    getEnv("THUMBNAIL_PATH", "./data/thumbnails"),
+		QuarantinePath:   getEnv("QUARANTINE_PATH", "./data/quarantine"),
+		FFmpegPath:       lookup("FFMPEG_PATH"),
+		VideoCachePath:   getEnv("VIDEO_CACHE_PATH", "./data/video-cache"),
+		ExportPath:       getEnv("EXPORT_PATH", "./data/exports"),
+		DBBackupPath:     getEnv("DB_BACKUP_PATH", "./data/backups"),
+		OriginalsPath:    lookup("ORIGINALS_PATH"),
+		PlacementPolicy:  getEnv("STORAGE_PLACEMENT_POLICY", storage.PlacementFillFirst),
+		StorageLayout:    getEnv("STORAGE_LAYOUT", storage.LayoutDate),
+		ThumbnailPolicy:  getEnv("THUMBNAIL_POLICY", thumbnail.PolicyLazy),
+		ThumbnailResizer: getEnv("THUMBNAIL_RESIZER", thumbnail.ResizerGo),
+		VipsThumbnail:    getEnv("VIPS_THUMBNAIL_PATH", "vipsthumbnail"),
+		LedgerTSAURL:     lookup("LEDGER_TSA_URL"),
+		RulesFile:        lookup("RULES_FILE"),
+		GinMode:          getEnv("GIN_MODE", "debug"),
+		LogLevel:         getEnv("LOG_LEVEL", logger.LevelInfo),
+		TrustedProxies:   getEnvList("TRUSTED_PROXIES"),
+		ClientIPHeader:   lookup("CLIENT_IP_HEADER"),
+		TLSCertFile:      lookup("TLS_CERT_FILE"),
+		TLSKeyFile:       lookup("TLS_KEY_FILE"),
+		AutocertDomains:  getEnvList("TLS_AUTOCERT_DOMAINS"),
+		AutocertEmail:    lookup("TLS_AUTOCERT_EMAIL"),
+		AutocertCacheDir: getEnv("TLS_AUTOCERT_CACHE", "./data/autocert"),
+		HTTPRedirectPort: lookup("TLS_HTTP_REDIRECT_PORT"),
+	}
+	listeners, err := parseListeners(getEnvList("LISTEN_ADDRS"), cfg.Port)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Listeners = listeners
+	socketMode, err := strconv.ParseUint(getEnv("LISTEN_SOCKET_MODE", "0660"), 8, 32)
+	if err != nil || socketMode > 0777 {
+		return nil, fmt.Errorf("LISTEN_SOCKET_MODE inválido: '%s' (use permissões em octal, ex: 0660)", lookup("LISTEN_SOCKET_MODE"))
+	}
+	cfg.SocketMode = os.FileMode(socketMode)
+	cfg.HTTP2Enabled, err = getEnvBool("HTTP2_ENABLED", true)
+	if err != nil {
+		return nil, err
+	}
+	cfg.CompressionLevel, err = getEnvInt("COMPRESSION_LEVEL", 5)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.CompressionLevel > 9 {
+		return nil, fmt.Errorf("COMPRESSION_LEVEL inválido: '%d' (esperado de 0 a 9)", cfg.CompressionLevel)
+	}
+	cfg.CompressionMin, err = getEnvInt("COMPRESSION_MIN_BYTES", 1024)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateServer(cfg); err != nil {
+		return nil, err
+	}
+
+	if !logger.Valid(cfg.LogLevel) {
+		return nil, fmt.Errorf("LOG_LEVEL inválido: '%s' (use %s)", cfg.LogLevel, strings.Join(logger.Levels, ", "))
+	}
+
+	if !storage.ValidPlacementPolicy(cfg.PlacementPolicy) {
+		return nil, fmt.Errorf("STORAGE_PLACEMENT_POLICY inválido: '%s' (use '%s' ou '%s')", cfg.PlacementPolicy, storage.PlacementFillFirst, storage.PlacementDateRange)
+	}
+
+	if !storage.ValidLayout(cfg.StorageLayout) {
+		return nil, fmt.Errorf("STORAGE_LAYOUT inválido: '%s' (use '%s' ou '%s')", cfg.StorageLayout, storage.LayoutDate, storage.LayoutHash)
+	}
+
+	cfg.ThumbnailMaxSize, err = getEnvInt("THUMBNAIL_MAX_SIZE", thumbnail.DefaultMaxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if !thumbnail.ValidPolicy(cfg.ThumbnailPolicy) {
+		return nil, fmt.Errorf("THUMBNAIL_POLICY inválido: '%s' (use '%s', '%s' ou '%s')", cfg.ThumbnailPolicy, thumbnail.PolicyLazy, thumbnail.PolicyEager, thumbnail.PolicyScheduled)
+	}
+
+	if !thumbnail.ValidResizer(cfg.ThumbnailResizer) {
+		return nil, fmt.Errorf("THUMBNAIL_RESIZER inválido: '%s' (use '%s' ou '%s')", cfg.ThumbnailResizer, thumbnail.ResizerGo, thumbnail.ResizerVips)
+	}
+
+	if err := loadWorkerPools(cfg); err != nil {
+		return nil, err
+	}
+
+	waitSeconds, err := getEnvInt("THUMBNAIL_QUEUE_TIMEOUT_SECONDS", 5)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ThumbnailWait = time.Duration(waitSeconds) * time.Second
+
+	cfg.ThumbnailHour, err = getEnvInt("THUMBNAIL_BACKFILL_HOUR", 3)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ThumbnailHour > 23 {
+		return nil, fmt.Errorf("THUMBNAIL_BACKFILL_HOUR inválido: '%d' (esperado de 0 a 23)", cfg.ThumbnailHour)
+	}
+
+	if cfg.DBBackupKeep, err = getEnvInt("DB_BACKUP_KEEP", 7); err != nil {
+		return nil, err
+	}
+	cfg.DBMaintenanceAt = -1
+	if value := lookup("DB_MAINTENANCE_HOUR"); value != "" && value != "-1" {
+		hour, err := strconv.Atoi(value)
+		if err != nil || hour < 0 || hour > 23 {
+			return nil, fmt.Errorf("DB_MAINTENANCE_HOUR inválido: '%s' (esperado de 0 a 23, ou -1 para desativar)", value)
+		}
+		cfg.DBMaintenanceAt = hour
+	}
+
+	timeoutSeconds, err := getEnvInt("DB_QUERY_TIMEOUT_SECONDS", 30)
+	if err != nil {
+		return nil, err
+	}
+	cfg.QueryTimeout = time.Duration(timeoutSeconds) * time.Second
+
+	policyMinutes, err := getEnvInt("ALBUM_POLICY_INTERVAL_MINUTES", 60)
+	if err != nil {
+		return nil, err
+	}
+	cfg.AlbumPolicyInterval = time.Duration(policyMinutes) * time.Minute
+
+	cleanupMinutes, err := getEnvInt("ALBUM_ORPHAN_CLEANUP_INTERVAL_MINUTES", 1440)
+	if err != nil {
+		return nil, err
+	}
+	cfg.AlbumCleanupInterval = time.Duration(cleanupMinutes) * time.Minute
+
+	flushSeconds, err := getEnvInt("ACCESS_STATS_FLUSH_SECONDS", 30)
+	if err != nil {
+		return nil, err
+	}
+	if flushSeconds == 0 {
+		return nil, fmt.Errorf("ACCESS_STATS_FLUSH_SECONDS inválido: '0' (esperado um inteiro positivo)")
+	}
+	cfg.AccessFlushInterval = time.Duration(flushSeconds) * time.Second
+
+	cfg.LedgerEnabled, err = getEnvBool("LEDGER_ENABLED", false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadPublicGallery(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadTracing(cfg); err != nil {
+		return nil, err
+	}
+
+	cfg.TimelineTimeZone = getEnv("TIMELINE_TIMEZONE", "local")
+	if zone := strings.ToLower(cfg.TimelineTimeZone); zone != "local" && zone != "photo" {
+		if _, err := time.LoadLocation(cfg.TimelineTimeZone); err != nil {
+			return nil, fmt.Errorf("TIMELINE_TIMEZONE inválido: '%s' (use um nome IANA, ex: America/Sao_Paulo, 'local' ou 'photo')", cfg.TimelineTimeZone)
+		}
+	}
+
+	reserveMB, err := getEnvInt("STORAGE_RESERVE_MB", 100)
+	if err != nil {
+		return nil, err
+	}
+	cfg.StorageReserveBytes = uint64(reserveMB) << 20
+
+	warningMB, err := getEnvInt("STORAGE_LOW_SPACE_WARNING_MB", 1024)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LowSpaceWarningBytes = uint64(warningMB) << 20
+
+	cfg.StorageSyncDir, err = getEnvBool("STORAGE_FSYNC_DIR", false)
+	if err != nil {
+		return nil, err
+	}
+
+	driftMinutes, err := getEnvInt("STORAGE_DRIFT_SCAN_INTERVAL_MINUTES", 1440)
+	if err != nil {
+		return nil, err
+	}
+	cfg.StorageDriftInterval = time.Duration(driftMinutes) * time.Minute
+
+	downloadMB, err := getEnvInt("DOWNLOAD_PREPARE_THRESHOLD_MB", 1024)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DownloadPrepareBytes = int64(downloadMB) << 20
+
+	downscaleMegapixels, err := getEnvInt("DOWNSCALE_MAX_MEGAPIXELS", 0)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DownscaleMaxPixels = int64(downscaleMegapixels) * 1_000_000
+
+	cfg.Validation = validation.DefaultOptions()
+	bodyMB, err := getEnvInt("MAX_REQUEST_BODY_MB", 10)
+	if err != nil {
+		return nil, err
+	}
+	cfg.MaxRequestBodyBytes = int64(bodyMB) << 20
+	uploadRequestMB, err := getEnvInt("UPLOAD_MAX_REQUEST_MB", 1024)
+	if err != nil {
+		return nil, err
+	}
+	cfg.MaxUploadRequestBytes = int64(uploadRequestMB) << 20
+	uploadKBps, err := getEnvInt("UPLOAD_BANDWIDTH_KBPS", 0)
+	if err != nil {
+		return nil, err
+	}
+	cfg.UploadBandwidth = int64(uploadKBps) << 10
+
+	maxSizeMB, err := getEnvInt("UPLOAD_MAX_SIZE_MB", int(cfg.Validation.MaxFileSize>>20))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Validation.MaxFileSize = int64(maxSizeMB) << 20
+
+	cfg.Validation.MaxDimension, err = getEnvInt("UPLOAD_MAX_DIMENSION", cfg.Validation.MaxDimension)
+	if err != nil {
+		return nil, err
+	}
+
+	megapixels, err := getEnvInt("UPLOAD_MAX_MEGAPIXELS", int(cfg.Validation.MaxPixels/1_000_000))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Validation.MaxPixels = int64(megapixels) * 1_000_000
+
+	cfg.Validation.ScanCommand = strings.Fields(lookup("UPLOAD_SCAN_COMMAND"))
+
+	if types := lookup("UPLOAD_ALLOWED_TYPES"); types != "" {
+		cfg.Validation.AllowedTypes = nil
+		for _, mimeType := range strings.Split(types, ",") {
+			if mimeType = strings.TrimSpace(mimeType); mimeType != "" {
+				cfg.Validation.AllowedTypes = append(cfg.Validation.AllowedTypes, mimeType)
+			}
+		}
+	}
+
+	cfg.Notify = notify.Options{
+		TelegramToken:  lookup("NOTIFY_TELEGRAM_TOKEN"),
+		TelegramChatID: lookup("NOTIFY_TELEGRAM_CHAT_ID"),
+		TelegramEvents: getEnvList("NOTIFY_TELEGRAM_EVENTS"),
+		NtfyURL:        lookup("NOTIFY_NTFY_URL"),
+		NtfyToken:      lookup("NOTIFY_NTFY_TOKEN"),
+		NtfyEvents:     getEnvList("NOTIFY_NTFY_EVENTS"),
+		GotifyURL:      lookup("NOTIFY_GOTIFY_URL"),
+		GotifyToken:    lookup("NOTIFY_GOTIFY_TOKEN"),
+		GotifyEvents:   getEnvList("NOTIFY_GOTIFY_EVENTS"),
+	}
+
+	cfg.Hooks = hooks.Options{
+		PreIngest:          lookup("HOOK_PRE_INGEST"),
+		PreIngestOnFailure: getEnv("HOOK_PRE_INGEST_ON_FAILURE", hooks.FailReject),
+		PostIngest:         lookup("HOOK_POST_INGEST"),
+	}
+	if cfg.Hooks.PreIngestOnFailure != hooks.FailReject && cfg.Hooks.PreIngestOnFailure != hooks.FailAccept {
+		return nil, fmt.Errorf("HOOK_PRE_INGEST_ON_FAILURE inválido: '%s' (use '%s' ou '%s')", cfg.Hooks.PreIngestOnFailure, hooks.FailReject, hooks.FailAccept)
+	}
+	preTimeout, err := getEnvInt("HOOK_PRE_INGEST_TIMEOUT_SECONDS", 10)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Hooks.PreIngestTimeout = time.Duration(preTimeout) * time.Second
+	postTimeout, err := getEnvInt("HOOK_POST_INGEST_TIMEOUT_SECONDS", 30)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Hooks.PostIngestTimeout = time.Duration(postTimeout) * time.Second
+	cfg.Hooks.PostIngestRetries, err = getEnvInt("HOOK_POST_INGEST_RETRIES", 2)
+	if err != nil {
+		return nil, err
+	}
+	// Os hooks esperam por comandos e servidores externos, não pela CPU: o mínimo é 4
+	if cfg.Hooks.PostIngestWorkers, err = getEnvPositiveInt("HOOK_POST_INGEST_WORKERS", max(4, runtime.NumCPU())); err != nil {
+		return nil, err
+	}
+	if cfg.Hooks.PostIngestQueue, err = getEnvPositiveInt("HOOK_POST_INGEST_QUEUE_SIZE", 10000); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// validateServer verifica o modo do Gin, os proxies confiáveis e a combinação das opções de TLS.
+func validateServer(cfg *Config) error {
+	if cfg.GinMode != "debug" && cfg.GinMode != "release" && cfg.GinMode != "test" {
+		return fmt.Errorf("GIN_MODE inválido: '%s' (use 'debug', 'release' ou 'test')", cfg.GinMode)
+	}
+	for _, proxy := range cfg.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return fmt.Errorf("TRUSTED_PROXIES inválido: '%s' (esperado um IP ou CIDR, ex: 10.0.0.0/8)", proxy)
+		}
+	}
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return fmt.Errorf("TLS_CERT_FILE e TLS_KEY_FILE devem ser informados juntos")
+	}
+	if cfg.TLSCertFile != "" && len(cfg.AutocertDomains) > 0 {
+		return fmt.Errorf("use TLS_CERT_FILE/TLS_KEY_FILE ou TLS_AUTOCERT_DOMAINS, não ambos")
+	}
+	if cfg.HTTPRedirectPort != "" && !cfg.TLSEnabled() {
+		return fmt.Errorf("TLS_HTTP_REDIRECT_PORT requer TLS (TLS_CERT_FILE ou TLS_AUTOCERT_DOMAINS)")
+	}
+	return nil
+}
+
+// Escopos de um listener: quais rotas ele atende.
+const (
+	ScopeAll    = "all"    // Todas as rotas (padrão)
+	ScopePublic = "public" // Apenas as rotas públicas: links de compartilhamento, galeria pública e /ping
+)
+
+// Listener é um endereço onde o servidor atende: TCP (IPv4 ou IPv6) ou socket Unix.
+type Listener struct {
+	Network string // tcp ou unix
+	Address string // host:porta, [ipv6]:porta, :porta ou caminho do socket
+	Scope   string // Ver constantes Scope*
+}
+
+func (l Listener) String() string {
+	if l.Network == "unix" {
+		return "unix:" + l.Address
+	}
+	return l.Address
+}
+
+// parseListeners interpreta LISTEN_ADDRS: endereços separados por vírgula no formato [escopo@]endereço, onde
+// o endereço é host:porta, [ipv6]:porta, :porta ou unix:/caminho/do/socket. Ex:
+// "192.168.0.10:8080,public@:8443,unix:/run/photo-manager.sock". Sem endereços, usa :APP_PORT.
+func parseListeners(values []string, port string) ([]Listener, error) {
+	if len(values) == 0 {
+		return []Listener{{Network: "tcp", Address: ":" + port, Scope: ScopeAll}}, nil
+	}
+	listeners := make([]Listener, 0, len(values))
+	for _, value := range values {
+		l := Listener{Network: "tcp", Address: value, Scope: ScopeAll}
+		if scope, address, found := strings.Cut(value, "@"); found {
+			l.Scope, l.Address = strings.TrimSpace(scope), strings.TrimSpace(address)
+			if l.Scope != ScopeAll && l.Scope != ScopePublic {
+				return nil, fmt.Errorf("LISTEN_ADDRS inválido: escopo '%s' em '%s' (use '%s' ou '%s')", l.Scope, value, ScopeAll, ScopePublic)
+			}
+		}
+		if path, found := strings.CutPrefix(l.Address, "unix:"); found {
+			l.Network, l.Address = "unix", path
+			if path == "" {
+				return nil, fmt.Errorf("LISTEN_ADDRS inválido: '%s' (informe o caminho do socket, ex: unix:/run/photo-manager.sock)", value)
+			}
+		} else if _, p, err := net.SplitHostPort(l.Address); err != nil || p == "" {
+			return nil, fmt.Errorf("LISTEN_ADDRS inválido: '%s' (use host:porta, [ipv6]:porta, :porta ou unix:/caminho)", value)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+// TLSEnabled indica se o servidor atende por HTTPS.
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" || len(c.AutocertDomains) > 0
+}
+
+// loadWorkerPools lê o tamanho dos conjuntos de workers em segundo plano e das suas filas. Os padrões
+// acompanham o número de CPUs: a geração de miniaturas em segundo plano usa metade delas, deixando a
+// outra metade para as solicitações sob demanda, e as importações, que alternam leitura de disco e
+// decodificação, um quarto (no mínimo 2). O ffmpeg já usa várias CPUs por vídeo, então poucas
+// transcodificações rodam ao mesmo tempo.
+func loadWorkerPools(cfg *Config) error {
+	cpus := runtime.NumCPU()
+	var err error
+	if cfg.ThumbnailWorkers, err = getEnvPositiveInt("THUMBNAIL_WORKERS", max(1, cpus/2)); err != nil {
+		return err
+	}
+	if cfg.ThumbnailRenders, err = getEnvPositiveInt("THUMBNAIL_RENDER_WORKERS", cpus); err != nil {
+		return err
+	}
+	if cfg.ThumbnailQueue, err = getEnvPositiveInt("THUMBNAIL_QUEUE_SIZE", 1024); err != nil {
+		return err
+	}
+	if cfg.ThumbnailPending, err = getEnvInt("THUMBNAIL_MAX_PENDING", 256); err != nil {
+		return err
+	}
+	if cfg.ImportWorkers, err = getEnvPositiveInt("IMPORT_WORKERS", max(2, cpus/4)); err != nil {
+		return err
+	}
+	if cfg.ImportQueue, err = getEnvPositiveInt("IMPORT_QUEUE_SIZE", 32); err != nil {
+		return err
+	}
+	if cfg.VideoWorkers, err = getEnvPositiveInt("VIDEO_TRANSCODE_WORKERS", 2); err != nil {
+		return err
+	}
+	return nil
+}
+
+// loadPublicGallery lê a configuração da galeria pública (PUBLIC_GALLERY_*).
+func loadPublicGallery(cfg *Config) error {
+	for _, value := range getEnvList("PUBLIC_GALLERY_ALBUMS") {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || id == 0 {
+			return fmt.Errorf("PUBLIC_GALLERY_ALBUMS inválido: '%s' (esperado IDs de álbuns separados por vírgula)", value)
+		}
+		cfg.PublicAlbumIDs = append(cfg.PublicAlbumIDs, uint(id))
+	}
+
+	var err error
+	cfg.PublicImageSize, err = getEnvInt("PUBLIC_GALLERY_IMAGE_SIZE", 1600)
+	if err != nil {
+		return err
+	}
+	if cfg.PublicImageSize < 100 {
+		return fmt.Errorf("PUBLIC_GALLERY_IMAGE_SIZE inválido: '%d' (mínimo 100)", cfg.PublicImageSize)
+	}
+	cfg.PublicRateLimit, err = getEnvInt("PUBLIC_GALLERY_RATE_LIMIT", 120)
+	if err != nil {
+		return err
+	}
+	cacheSeconds, err := getEnvInt("PUBLIC_GALLERY_CACHE_SECONDS", 86400)
+	if err != nil {
+		return err
+	}
+	cfg.PublicCacheMaxAge = time.Duration(cacheSeconds) * time.Second
+	cfg.PublicAllowedReferer = getEnvList("PUBLIC_GALLERY_ALLOWED_REFERERS")
+	return nil
+}
+
+// loadTracing lê a configuração do tracing (OTEL_*), nos nomes usados pelos SDKs do OpenTelemetry.
+func loadTracing(cfg *Config) error {
+	cfg.TracingEndpoint = strings.TrimSpace(lookup("OTEL_EXPORTER_OTLP_ENDPOINT"))
+	cfg.TracingServiceName = getEnv("OTEL_SERVICE_NAME", "photo-manager")
+
+	cfg.TracingSampleRatio = 1
+	if value := lookup("OTEL_TRACES_SAMPLER_ARG"); value != "" {
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return fmt.Errorf("OTEL_TRACES_SAMPLER_ARG inválido: '%s' (esperado um número entre 0 e 1)", value)
+		}
+		cfg.TracingSampleRatio = ratio
+	}
+
+	cfg.TracingHeaders = make(map[string]string)
+	for _, pair := range getEnvList("OTEL_EXPORTER_OTLP_HEADERS") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("OTEL_EXPORTER_OTLP_HEADERS inválido: '%s' (esperado chave=valor separados por vírgula)", pair)
+		}
+		cfg.TracingHeaders[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return nil
+}
+
+// getEnvList lê uma variável de ambiente com valores separados por vírgula, ignorando itens vazios.
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(lookup(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnv retorna o valor da variável de ambiente ou o valor padrão.
+func getEnv(key, defaultValue string) string {
+	if value := lookup(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvLogged funciona como getEnv, mas registra no log quando o valor padrão é usado.
+func getEnvLogged(key, defaultValue string) string {
+	value := lookup(key)
+	if value == "" {
+		log.Printf("%s não configurado. Usando padrão: %s\n", key, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvBool lê uma variável de ambiente booleana (true/false, 1/0).
+func getEnvBool(key string, defaultValue bool) (bool, error) {
+	value := lookup(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s inválido: '%s' (esperado true ou false)", key, value)
+	}
+	return b, nil
+}
+
+// getEnvPositiveInt lê uma variável de ambiente inteira positiva.
+func getEnvPositiveInt(key string, defaultValue int) (int, error) {
+	n, err := getEnvInt(key, defaultValue)
+	if err == nil && n == 0 {
+		return 0, fmt.Errorf("%s inválido: '0' (esperado um inteiro positivo)", key)
+	}
+	return n, err
+}
+
+// getEnvInt lê uma variável de ambiente inteira não negativa.
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value := lookup(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s inválido: '%s' (esperado um inteiro não negativo)", key, value)
+	}
+	return n, nil
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileValue é uma configuração lida do arquivo (CONFIG_FILE), com a posição para as mensagens de erro.
+type fileValue struct {
+	value string
+	name  string // Caminho da chave no arquivo, ex: thumbnail.workers
+	line  int
+}
+
+// loadState acompanha um Load: os valores do arquivo de configuração e o valor efetivo de cada variável
+// consultada, usados para rejeitar chaves desconhecidas e para comparar configurações numa recarga.
+type loadState struct {
+	path   string
+	values map[string]fileValue
+	read   map[string]string
+}
+
+// state é o Load em andamento (protegido por loadMu, ver Load).
+var state *loadState
+
+// lookup retorna o valor de uma configuração: a variável de ambiente, se definida, ou o valor do arquivo de
+// configuração. Todas as leituras de Load passam por aqui.
+func lookup(key string) string {
+	value := os.Getenv(key)
+	if state == nil {
+		return value
+	}
+	if entry, ok := state.values[key]; ok && value == "" {
+		value = entry.value
+	}
+	state.read[key] = value
+	return value
+}
+
+// readConfigFile lê o arquivo de configuração YAML. As chaves são as mesmas variáveis de ambiente, em
+// qualquer caixa, podendo ser agrupadas em seções: "thumbnail: {workers: 4}" equivale a THUMBNAIL_WORKERS=4.
+// Listas viram valores separados por vírgula. path vazio retorna um estado sem valores.
+func readConfigFile(path string) (*loadState, error) {
+	s := &loadState{path: path, values: make(map[string]fileValue), read: make(map[string]string)}
+	if path == "" {
+		return s, nil
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext != ".yaml" && ext != ".yml" {
+		return nil, fmt.Errorf("CONFIG_FILE inválido: '%s' (use um arquivo YAML, .yaml ou .yml)", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível ler o arquivo de configuração: %w", err)
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: YAML inválido: %w", path, err)
+	}
+	if len(root.Content) == 0 {
+		return s, nil // Arquivo vazio
+	}
+	if err := s.collect(root.Content[0], "", ""); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// collect percorre um mapeamento do YAML, registrando os valores com a chave de ambiente correspondente.
+func (s *loadState) collect(node *yaml.Node, prefix, name string) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s:%d: esperado um mapeamento de chaves e valores", s.path, node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		key := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(keyNode.Value))
+		keyName := keyNode.Value
+		if prefix != "" {
+			key, keyName = prefix+"_"+key, name+"."+keyName
+		}
+
+		var value string
+		switch valueNode.Kind {
+		case yaml.MappingNode:
+			if err := s.collect(valueNode, key, keyName); err != nil {
+				return err
+			}
+			continue
+		case yaml.ScalarNode:
+			if valueNode.Tag != "!!null" {
+				value = valueNode.Value
+			}
+		case yaml.SequenceNode:
+			items := make([]string, 0, len(valueNode.Content))
+			for _, item := range valueNode.Content {
+				if item.Kind != yaml.ScalarNode {
+					return fmt.Errorf("%s:%d: '%s' deve ser uma lista de valores simples", s.path, item.Line, keyName)
+				}
+				items = append(items, item.Value)
+			}
+			value = strings.Join(items, ",")
+		default:
+			return fmt.Errorf("%s:%d: valor inválido em '%s'", s.path, valueNode.Line, keyName)
+		}
+
+		if previous, ok := s.values[key]; ok {
+			return fmt.Errorf("%s:%d: '%s' repete a configuração %s, já definida na linha %d", s.path, keyNode.Line, keyName, key, previous.line)
+		}
+		s.values[key] = fileValue{value: value, name: keyName, line: keyNode.Line}
+	}
+	return nil
+}
+
+// checkUnknown rejeita as chaves do arquivo que não correspondem a nenhuma configuração, sugerindo a mais
+// parecida (ex: um erro de digitação em thumbnail.wokers).
+func (s *loadState) checkUnknown() error {
+	known := make([]string, 0, len(s.read))
+	for key := range s.read {
+		known = append(known, key)
+	}
+
+	var errs []error
+	for key, entry := range s.values {
+		if _, ok := s.read[key]; ok {
+			continue
+		}
+		msg := fmt.Sprintf("%s:%d: configuração desconhecida '%s' (%s)", s.path, entry.line, entry.name, key)
+		if suggestion := closestKey(key, known); suggestion != "" {
+			msg += fmt.Sprintf("; você quis dizer %s?", suggestion)
+		}
+		errs = append(errs, errors.New(msg))
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
+
+// locate acrescenta a um erro de validação a linha do arquivo de onde veio o valor rejeitado. Os erros de
+// Load começam pelo nome da variável (ex: "THUMBNAIL_WORKERS inválido: ...").
+func (s *loadState) locate(err error) error {
+	for key, entry := range s.values {
+		if strings.HasPrefix(err.Error(), key+" ") && os.Getenv(key) == "" {
+			return fmt.Errorf("%w (%s:%d, '%s')", err, s.path, entry.line, entry.name)
+		}
+	}
+	return err
+}
+
+// closestKey retorna a configuração conhecida mais parecida com key, se a diferença for pequena.
+func closestKey(key string, known []string) string {
+	best, bestDistance := "", 4
+	for _, candidate := range known {
+		if d := editDistance(key, candidate); d < bestDistance || (d == bestDistance && candidate < best) {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance calcula a distância de Levenshtein entre a e b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
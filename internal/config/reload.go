@@ -0,0 +1,116 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"sync"
+	"syscall"
+)
+
+// HotReloadable são as configurações aplicadas por uma recarga, sem reiniciar o servidor. As demais
+// alterações só valem após um reinício.
+var HotReloadable = []string{
+	"LOG_LEVEL",
+	"PUBLIC_GALLERY_RATE_LIMIT",
+	"UPLOAD_BANDWIDTH_KBPS",
+	"THUMBNAIL_WORKERS",
+	"IMPORT_WORKERS",
+	"HOOK_POST_INGEST_WORKERS",
+}
+
+// ReloadResult descreve uma recarga da configuração.
+type ReloadResult struct {
+	Applied         []string // Configurações alteradas e já aplicadas
+	RestartRequired []string // Configurações alteradas que só valem após reiniciar o servidor
+}
+
+// Reloader relê a configuração sob demanda (SIGHUP ou POST /admin/config/reload) e repassa as
+// configurações de HotReloadable para apply. Uma configuração inválida é rejeitada por inteiro, e a
+// anterior continua valendo.
+type Reloader struct {
+	apply func(*Config)
+
+	mu      sync.Mutex
+	started map[string]string // Valores da inicialização, em vigor para as configurações que exigem reinício
+	applied map[string]string // Valores da última configuração aplicada
+}
+
+// NewReloader cria um Reloader a partir da configuração carregada na inicialização. apply recebe a nova
+// configuração a cada recarga bem-sucedida e deve aplicar as configurações de HotReloadable.
+func NewReloader(cfg *Config, apply func(*Config)) *Reloader {
+	return &Reloader{apply: apply, started: cfg.values, applied: cfg.values}
+}
+
+// Reload relê as variáveis de ambiente e o arquivo de configuração, aplicando as configurações ajustáveis.
+// As tarefas em andamento não são interrompidas: ex: com menos workers, os excedentes param ao terminar a
+// tarefa atual.
+func (r *Reloader) Reload() (*ReloadResult, error) {
+	next, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := &ReloadResult{Applied: []string{}, RestartRequired: []string{}}
+	for _, key := range changedKeys(r.applied, next.values) {
+		if slices.Contains(HotReloadable, key) {
+			result.Applied = append(result.Applied, key)
+		}
+	}
+	for _, key := range changedKeys(r.started, next.values) {
+		if !slices.Contains(HotReloadable, key) {
+			result.RestartRequired = append(result.RestartRequired, key)
+		}
+	}
+	r.apply(next)
+	r.applied = next.values
+
+	log.Printf("Configuração recarregada: %d alteração(ões) aplicada(s), %d aguardando reinício\n",
+		len(result.Applied), len(result.RestartRequired))
+	if len(result.RestartRequired) > 0 {
+		log.Printf("Aviso: estas configurações só valem após reiniciar o servidor: %v\n", result.RestartRequired)
+	}
+	return result, nil
+}
+
+// WatchSignals recarrega a configuração a cada SIGHUP, até o contexto ser cancelado.
+func (r *Reloader) WatchSignals(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				log.Println("SIGHUP recebido, recarregando a configuração")
+				if _, err := r.Reload(); err != nil {
+					log.Printf("Configuração inválida, recarga ignorada: %v\n", err)
+				}
+			}
+		}
+	}()
+}
+
+// changedKeys retorna, em ordem alfabética, as variáveis cujo valor efetivo difere entre as duas leituras.
+func changedKeys(previous, next map[string]string) []string {
+	var keys []string
+	for key, value := range next {
+		if previous[key] != value {
+			keys = append(keys, key)
+		}
+	}
+	for key := range previous {
+		if _, ok := next[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,240 @@
+package contactsheet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"log"
+	"math"
+	"photo-manager/internal/database"
+	"photo-manager/internal/thumbnail"
+	"photo-manager/internal/video"
+	"strings"
+	"time"
+)
+
+// Limites e padrões do layout.
+const (
+	DefaultColumns  = 4
+	MaxColumns      = 8
+	DefaultPageSize = "a4"
+)
+
+// Medidas do layout, em pontos (1/72 de polegada).
+const (
+	margin       = 36.0
+	gap          = 10.0
+	headerHeight = 28.0
+	captionSize  = 8.0
+	dateSize     = 7.0
+	captionSpace = 4 + captionSize + 2 + dateSize + 2 // Espaço abaixo da imagem para legenda e data
+	imageDPI     = 150.0                              // Resolução das imagens embutidas, suficiente para impressão
+)
+
+// PageSize é o tamanho de uma página, em pontos.
+type PageSize struct {
+	Width  float64
+	Height float64
+}
+
+// PageSizes são os tamanhos de página aceitos.
+var PageSizes = map[string]PageSize{
+	"a4":     {595.28, 841.89},
+	"a3":     {841.89, 1190.55},
+	"letter": {612, 792},
+	"legal":  {612, 1008},
+}
+
+// Options controla o layout da folha de contatos.
+type Options struct {
+	Columns   int    // Colunas por página (1 a MaxColumns)
+	PageSize  string // Chave de PageSizes
+	Landscape bool   // Página na horizontal
+	MaxPixels int64  // Limite de pixels ao decodificar os originais (0 = sem limite)
+}
+
+// Result resume a geração.
+type Result struct {
+	Pages    int // Páginas geradas
+	Exported int // Fotos com imagem na folha
+	Skipped  int // Vídeos e fotos em quarentena omitidos, ou imagens que não puderam ser decodificadas (exibidas como espaço vazio)
+}
+
+// item é uma foto posicionada na folha.
+type item struct {
+	photo   database.Photo
+	caption string
+	date    string
+}
+
+// layout contém as medidas calculadas para as opções informadas.
+type layout struct {
+	page        PageSize
+	columns     int
+	rows        int
+	cellWidth   float64
+	cellHeight  float64
+	imagePixels int // Maior lado, em pixels, das imagens embutidas
+}
+
+// newLayout valida as opções e calcula a grade.
+func newLayout(opts Options) (layout, error) {
+	if opts.Columns == 0 {
+		opts.Columns = DefaultColumns
+	}
+	if opts.Columns < 1 || opts.Columns > MaxColumns {
+		return layout{}, fmt.Errorf("quantidade de colunas inválida: use de 1 a %d", MaxColumns)
+	}
+	if opts.PageSize == "" {
+		opts.PageSize = DefaultPageSize
+	}
+	page, ok := PageSizes[strings.ToLower(opts.PageSize)]
+	if !ok {
+		return layout{}, fmt.Errorf("tamanho de página '%s' não suportado", opts.PageSize)
+	}
+	if opts.Landscape {
+		page.Width, page.Height = page.Height, page.Width
+	}
+
+	l := layout{page: page, columns: opts.Columns}
+	l.cellWidth = (page.Width - 2*margin - float64(opts.Columns-1)*gap) / float64(opts.Columns)
+	l.cellHeight = l.cellWidth + captionSpace
+	usable := page.Height - 2*margin - headerHeight
+	l.rows = max(1, int((usable+gap)/(l.cellHeight+gap)))
+	l.imagePixels = min(1200, int(math.Ceil(l.cellWidth*imageDPI/72)))
+	return l, nil
+}
+
+// ValidateOptions verifica as opções de layout antes do início da geração (ex: para responder 400 antes de enviar o PDF).
+func ValidateOptions(opts Options) error {
+	_, err := newLayout(opts)
+	return err
+}
+
+// Write gera no destino um PDF com a grade de miniaturas das fotos, com legenda (descrição ou nome do
+// arquivo) e data de cada uma, paginado conforme as opções. O PDF é gravado à medida que as imagens são
+// processadas; apenas uma imagem fica em memória por vez.
+func Write(ctx context.Context, w io.Writer, title string, photos []database.Photo, opts Options) (Result, error) {
+	var result Result
+	l, err := newLayout(opts)
+	if err != nil {
+		return result, err
+	}
+
+	var items []item
+	for _, photo := range photos {
+		if photo.Quarantined || video.IsVideo(photo.MimeType) {
+			result.Skipped++
+			continue
+		}
+		caption := photo.Description
+		if caption == "" {
+			caption = photo.Filename
+		}
+		items = append(items, item{photo: photo, caption: caption, date: takenAt(photo).Format("02/01/2006 15:04")})
+	}
+
+	perPage := l.columns * l.rows
+	result.Pages = max(1, (len(items)+perPage-1)/perPage)
+
+	p := newPDFWriter(w)
+	catalog, pages, font := p.reserve(), p.reserve(), p.reserve()
+	p.object(font, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+
+	var kids []string
+	for pageIndex := 0; pageIndex < result.Pages; pageIndex++ {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		start := pageIndex * perPage
+		end := min(start+perPage, len(items))
+		pageItems := items[start:end]
+
+		var content bytes.Buffer
+		header := fmt.Sprintf("Página %d de %d", pageIndex+1, result.Pages)
+		headerY := l.page.Height - margin - 12
+		fmt.Fprintf(&content, "BT /F1 12 Tf %.2f %.2f Td %s Tj ET\n", margin, headerY,
+			pdfString(fitText(title, 12, l.page.Width-2*margin-textWidth(header, 9)-gap)))
+		fmt.Fprintf(&content, "0.4 g BT /F1 9 Tf %.2f %.2f Td %s Tj ET 0 g\n",
+			l.page.Width-margin-textWidth(header, 9), headerY, pdfString(header))
+
+		var xobjects []string
+		for i, it := range pageItems {
+			x := margin + float64(i%l.columns)*(l.cellWidth+gap)
+			top := l.page.Height - margin - headerHeight - float64(i/l.columns)*(l.cellHeight+gap)
+			boxY := top - l.cellWidth
+
+			fmt.Fprintf(&content, "q 0.85 G 0.5 w %.2f %.2f %.2f %.2f re S Q\n", x, boxY, l.cellWidth, l.cellWidth)
+
+			jpegData, width, height, err := encodeImage(it.photo, l.imagePixels, opts.MaxPixels)
+			if err != nil {
+				// A célula fica vazia; a folha segue com as demais fotos
+				log.Printf("Folha de contatos: imagem da foto %d ignorada: %v\n", it.photo.ID, err)
+				result.Skipped++
+			} else {
+				num := p.reserve()
+				p.stream(num, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode",
+					width, height), jpegData)
+				name := fmt.Sprintf("Im%d", num)
+				xobjects = append(xobjects, fmt.Sprintf("/%s %d 0 R", name, num))
+
+				scale := math.Min(l.cellWidth/float64(width), l.cellWidth/float64(height))
+				drawW, drawH := float64(width)*scale, float64(height)*scale
+				fmt.Fprintf(&content, "q %.2f 0 0 %.2f %.2f %.2f cm /%s Do Q\n",
+					drawW, drawH, x+(l.cellWidth-drawW)/2, boxY+(l.cellWidth-drawH)/2, name)
+				result.Exported++
+			}
+
+			captionY := boxY - 4 - captionSize
+			fmt.Fprintf(&content, "BT /F1 %.0f Tf %.2f %.2f Td %s Tj ET\n", captionSize, x, captionY,
+				pdfString(fitText(it.caption, captionSize, l.cellWidth)))
+			fmt.Fprintf(&content, "0.4 g BT /F1 %.0f Tf %.2f %.2f Td %s Tj ET 0 g\n", dateSize, x, captionY-2-dateSize,
+				pdfString(it.date))
+		}
+
+		contentNum := p.reserve()
+		p.stream(contentNum, "", content.Bytes())
+		pageNum := p.reserve()
+		p.object(pageNum, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 %d 0 R >> /XObject << %s >> >> /Contents %d 0 R >>",
+			pages, l.page.Width, l.page.Height, font, strings.Join(xobjects, " "), contentNum))
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageNum))
+	}
+
+	p.object(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	p.object(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+	return result, p.close(catalog)
+}
+
+// encodeImage decodifica a foto, reduz para maxSize e recodifica como JPEG RGB, compondo a
+// transparência sobre fundo branco.
+func encodeImage(photo database.Photo, maxSize int, maxPixels int64) ([]byte, int, int, error) {
+	img, _, err := thumbnail.Decode(photo.StoredPath, maxPixels)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	resized := thumbnail.Resize(img, maxSize)
+
+	bounds := resized.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(rgba, rgba.Bounds(), resized, bounds.Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, rgba, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, 0, 0, fmt.Errorf("não foi possível codificar a imagem: %w", err)
+	}
+	return buf.Bytes(), bounds.Dx(), bounds.Dy(), nil
+}
+
+// takenAt retorna a data da foto (EXIF, ou a data de upload na falta dela).
+func takenAt(photo database.Photo) time.Time {
+	if photo.ExifDate != nil {
+		return *photo.ExifDate
+	}
+	return photo.UploadDate
+}
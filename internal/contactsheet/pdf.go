@@ -0,0 +1,145 @@
+package contactsheet
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// pdfWriter gera um PDF mínimo (PDF 1.4) diretamente no destino, objeto por objeto, registrando as
+// posições de cada um para a tabela xref final. Suporta apenas o necessário para a folha de contatos:
+// páginas, imagens JPEG (DCTDecode) e texto com a fonte padrão Helvetica.
+type pdfWriter struct {
+	w       *bufio.Writer
+	n       int64   // Bytes gravados até o momento
+	offsets []int64 // Posição de cada objeto (índice = número do objeto - 1); 0 = reservado e ainda não gravado
+	err     error
+}
+
+func newPDFWriter(w io.Writer) *pdfWriter {
+	p := &pdfWriter{w: bufio.NewWriter(w)}
+	// O comentário binário indica aos leitores que o arquivo contém dados binários (as imagens)
+	p.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	return p
+}
+
+func (p *pdfWriter) printf(format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(p.w, format, args...)
+	p.n += int64(n)
+	p.err = err
+}
+
+func (p *pdfWriter) write(data []byte) {
+	if p.err != nil {
+		return
+	}
+	n, err := p.w.Write(data)
+	p.n += int64(n)
+	p.err = err
+}
+
+// reserve aloca o número de um objeto que será gravado depois (ex: a árvore de páginas, que referencia todas as páginas).
+func (p *pdfWriter) reserve() int {
+	p.offsets = append(p.offsets, 0)
+	return len(p.offsets)
+}
+
+// object grava um objeto de dicionário no número reservado.
+func (p *pdfWriter) object(num int, dict string) {
+	p.offsets[num-1] = p.n
+	p.printf("%d 0 obj\n%s\nendobj\n", num, dict)
+}
+
+// stream grava um objeto de stream; dict não deve incluir /Length.
+func (p *pdfWriter) stream(num int, dict string, data []byte) {
+	p.offsets[num-1] = p.n
+	p.printf("%d 0 obj\n<< %s /Length %d >>\nstream\n", num, dict, len(data))
+	p.write(data)
+	p.printf("\nendstream\nendobj\n")
+}
+
+// close grava a tabela xref e o trailer e descarrega o buffer.
+func (p *pdfWriter) close(root int) error {
+	xref := p.n
+	p.printf("xref\n0 %d\n0000000000 65535 f \n", len(p.offsets)+1)
+	for _, offset := range p.offsets {
+		p.printf("%010d 00000 n \n", offset)
+	}
+	p.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.offsets)+1, root, xref)
+	if p.err != nil {
+		return p.err
+	}
+	return p.w.Flush()
+}
+
+// pdfString codifica o texto como string literal do PDF em WinAnsiEncoding. Caracteres sem
+// representação na codificação são substituídos por '?'.
+func pdfString(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range text {
+		c := winAnsiByte(r)
+		if c == '(' || c == ')' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// winAnsiSpecial mapeia os caracteres da faixa 0x80–0x9F da WinAnsiEncoding mais comuns em legendas.
+var winAnsiSpecial = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+func winAnsiByte(r rune) byte {
+	switch {
+	case r >= 0x20 && r <= 0x7e, r >= 0xa0 && r <= 0xff:
+		return byte(r)
+	case winAnsiSpecial[r] != 0:
+		return winAnsiSpecial[r]
+	default:
+		return '?'
+	}
+}
+
+// helveticaWidths são as larguras (em milésimos do tamanho da fonte) dos caracteres ASCII 32–126 da Helvetica.
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// textWidth retorna a largura aproximada do texto, em pontos. Caracteres fora do ASCII usam a largura média.
+func textWidth(text string, size float64) float64 {
+	total := 0
+	for _, r := range text {
+		if r >= 32 && r <= 126 {
+			total += helveticaWidths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// fitText trunca o texto com reticências para que caiba na largura informada.
+func fitText(text string, size, width float64) string {
+	if textWidth(text, size) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && textWidth(string(runes)+"…", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -1,27 +1,74 @@
 package database
 
 import (
+	"fmt"
 	"log"
+	"photo-manager/internal/publicid"
+	"time"
 
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB // Variável global para a instância do banco de dados
 
 // InitDB inicializa a conexão com o banco de dados e realiza as migrações.
-func InitDB(databasePath string) {
+// queryTimeout limita a duração de cada comando SQL (0 desativa o limite).
+func InitDB(databasePath string, queryTimeout time.Duration) {
 	var err error
-	DB, err = gorm.Open(sqlite.Open(databasePath), &gorm.Config{})
+	DB, err = gorm.Open(openDialector(databasePath), &gorm.Config{Logger: newLevelLogger()})
 	if err != nil {
 		log.Fatalf("Falha ao conectar ao banco de dados: %v", err)
 	}
 
+	if err := registerQueryTimeout(DB, queryTimeout); err != nil {
+		log.Fatalf("Falha ao configurar o timeout das consultas: %v", err)
+	}
+	if err := registerTracing(DB); err != nil {
+		log.Fatalf("Falha ao configurar o tracing das consultas: %v", err)
+	}
+
 	// Migração automática do schema
-	err = DB.AutoMigrate(&Photo{}, &Album{}, &AlbumPhoto{})
+	err = DB.AutoMigrate(&Photo{}, &Album{}, &AlbumPhoto{}, &Tag{}, &PhotoTag{}, &ImportJob{}, &StorageVolume{}, &ShareLink{}, &AlbumPolicy{}, &MirrorSource{}, &MirrorObject{}, &LedgerEntry{}, &LedgerTimestamp{}, &MetadataDump{}, &SourceAlbum{}, &ExportJob{}, &PhotoReaction{}, &MetadataChange{}, &PhotoRelation{}, &ImportReport{}, &ImportReportEntry{}, &AdminPlan{}, &AdminPlanAction{}, &SourceDuplicatePolicy{}, &PhotoAnnotation{}, &AlbumTemplate{}, &AlbumTemplatePolicy{}, &DownloadJob{}, &Selection{}, &SelectionPhoto{})
 	if err != nil {
 		log.Fatalf("Falha ao migrar o schema do banco de dados: %v", err)
 	}
+	// O hash deixou de ser único sozinho: as cópias gravadas pela política de duplicatas "version" repetem o
+	// hash com outro CopyIndex (ver idx_photos_hash_copy)
+	if DB.Migrator().HasIndex(&Photo{}, "idx_photos_hash") {
+		if err := DB.Migrator().DropIndex(&Photo{}, "idx_photos_hash"); err != nil {
+			log.Fatalf("Falha ao remover o índice único antigo do hash das fotos: %v", err)
+		}
+	}
+	if err := assignPublicIDs(DB); err != nil {
+		log.Fatalf("Falha ao atribuir os identificadores públicos: %v", err)
+	}
+
+	log.Printf("Conexão com o banco de dados estabelecida (%s) e migrações executadas com sucesso!\n", Driver)
+}
 
-	log.Println("Conexão com o banco de dados estabelecida e migrações executadas com sucesso!")
+// assignPublicIDs atribui identificadores públicos às fotos e aos álbuns criados antes deles (inclusive os
+// removidos, que podem ser restaurados). Os identificadores já atribuídos nunca mudam.
+func assignPublicIDs(db *gorm.DB) error {
+	for _, table := range []string{"photos", "albums"} {
+		var ids []uint
+		if err := db.Table(table).Where("public_id IS NULL OR public_id = ''").Pluck("id", &ids).Error; err != nil {
+			return fmt.Errorf("erro ao buscar os registros de %s sem identificador público: %w", table, err)
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, id := range ids {
+				if err := tx.Table(table).Where("id = ?", id).UpdateColumn("public_id", publicid.New()).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("erro ao atribuir os identificadores públicos de %s: %w", table, err)
+		}
+		log.Printf("%d registro(s) de %s receberam identificador público\n", len(ids), table)
+	}
+	return nil
 }
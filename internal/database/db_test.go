@@ -0,0 +1,69 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestInitDBRoundTrip abre um banco novo, executa as migrações e faz um ciclo de criação, leitura, alteração e
+// remoção. Não depende do driver: rode com e sem -tags purego para comparar as duas implementações do SQLite.
+func TestInitDBRoundTrip(t *testing.T) {
+	InitDB(filepath.Join(t.TempDir(), "photo_manager.db"), 5*time.Second)
+	t.Cleanup(func() {
+		if sqlDB, err := DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	t.Logf("driver: %s", Driver)
+
+	photo := Photo{Filename: "a.jpg", StoredPath: "/fotos/a.jpg", Hash: "abc", FileSize: 123}
+	if err := DB.Create(&photo).Error; err != nil {
+		t.Fatalf("criar foto: %v", err)
+	}
+	if photo.ID == 0 || photo.PublicID == "" {
+		t.Fatalf("foto criada sem ID (%d) ou identificador público (%q)", photo.ID, photo.PublicID)
+	}
+
+	// O mesmo arquivo não pode ser gravado duas vezes
+	duplicate := Photo{Filename: "a.jpg", StoredPath: "/fotos/a.jpg", Hash: "abc"}
+	err := DB.Create(&duplicate).Error
+	if err == nil {
+		t.Fatal("foto duplicada gravada sem violar o índice único")
+	}
+	if !strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		t.Fatalf("erro da violação do índice único sem o texto do SQLite: %v", err)
+	}
+
+	album := Album{Name: "Férias"}
+	if err := DB.Create(&album).Error; err != nil {
+		t.Fatalf("criar álbum: %v", err)
+	}
+	if err := DB.Create(&AlbumPhoto{AlbumID: album.ID, PhotoID: photo.ID}).Error; err != nil {
+		t.Fatalf("associar foto ao álbum: %v", err)
+	}
+
+	if err := DB.Model(&Photo{}).Where("id = ?", photo.ID).Updates(map[string]interface{}{"favorite": true, "description": "praia"}).Error; err != nil {
+		t.Fatalf("alterar foto: %v", err)
+	}
+	var loaded Photo
+	if err := DB.First(&loaded, photo.ID).Error; err != nil {
+		t.Fatalf("ler foto: %v", err)
+	}
+	if !loaded.Favorite || loaded.Description != "praia" || loaded.FileSize != 123 {
+		t.Fatalf("foto lida difere da gravada: %+v", loaded)
+	}
+
+	var count int64
+	if err := DB.Model(&AlbumPhoto{}).Where("album_id = ?", album.ID).Count(&count).Error; err != nil || count != 1 {
+		t.Fatalf("fotos do álbum: %d (%v), esperado 1", count, err)
+	}
+
+	if err := DB.Unscoped().Delete(&Photo{}, photo.ID).Error; err != nil {
+		t.Fatalf("remover foto: %v", err)
+	}
+	if err := DB.Unscoped().Model(&Photo{}).Where("id = ?", photo.ID).Count(&count).Error; err != nil || count != 0 {
+		t.Fatalf("foto continua no banco: %d (%v)", count, err)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !purego
+
+package database
+
+import (
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Driver identifica a implementação do SQLite compilada no binário. O padrão usa a biblioteca C do SQLite
+// (mattn/go-sqlite3) e exige CGO; compile com -tags purego para a implementação em Go puro.
+const Driver = "mattn/go-sqlite3 (cgo)"
+
+// openDialector abre o banco em databasePath com o driver SQLite do build.
+func openDialector(databasePath string) gorm.Dialector {
+	return sqlite.Open(databasePath)
+}
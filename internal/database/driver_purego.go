@@ -0,0 +1,20 @@
+//go:build purego
+
+package database
+
+import (
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// Driver identifica a implementação do SQLite compilada no binário. Com -tags purego, o SQLite é o
+// modernc.org/sqlite (via glebarez/sqlite), em Go puro: o binário dispensa CGO e pode ser compilado
+// estaticamente e para outras arquiteturas (ex: CGO_ENABLED=0 GOARCH=arm64).
+const Driver = "modernc.org/sqlite (purego)"
+
+// openDialector abre o banco em databasePath com o driver SQLite do build. O glebarez/sqlite aceita o mesmo
+// caminho, e as mensagens de erro trazem o mesmo texto do SQLite que as do driver com CGO (com o código do
+// erro no final).
+func openDialector(databasePath string) gorm.Dialector {
+	return sqlite.Open(databasePath)
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"photo-manager/internal/logger"
+	"time"
+
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// levelLogger registra as consultas SQL conforme o nível de log atual (LOG_LEVEL), consultado a cada
+// mensagem: debug registra todas as consultas, info e warn as lentas e os erros, e error apenas os erros.
+type levelLogger struct {
+	levels map[string]gormlogger.Interface
+}
+
+// newLevelLogger cria o logger do GORM que acompanha o nível de log da aplicação.
+func newLevelLogger() gormlogger.Interface {
+	return &levelLogger{levels: map[string]gormlogger.Interface{
+		logger.LevelDebug: gormlogger.Default.LogMode(gormlogger.Info),
+		logger.LevelInfo:  gormlogger.Default.LogMode(gormlogger.Warn),
+		logger.LevelWarn:  gormlogger.Default.LogMode(gormlogger.Warn),
+		logger.LevelError: gormlogger.Default.LogMode(gormlogger.Error),
+	}}
+}
+
+// current retorna o logger do nível atual.
+func (l *levelLogger) current() gormlogger.Interface {
+	return l.levels[logger.Level()]
+}
+
+// LogMode é ignorado: o nível segue LOG_LEVEL.
+func (l *levelLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return l
+}
+
+func (l *levelLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	l.current().Info(ctx, msg, data...)
+}
+
+func (l *levelLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	l.current().Warn(ctx, msg, data...)
+}
+
+func (l *levelLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	l.current().Error(ctx, msg, data...)
+}
+
+func (l *levelLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	l.current().Trace(ctx, begin, fc, err)
+}
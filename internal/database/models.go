@@ -1,6 +1,7 @@
 package database
 
 import (
+	"photo-manager/internal/publicid"
 	"time"
 
 	"gorm.io/gorm"
@@ -8,28 +9,195 @@ import (
 
 // Photo representa a estrutura de uma foto no banco de dados.
 type Photo struct {
-	gorm.Model                 // Inclui campos padrão como ID, CreatedAt, UpdatedAt, DeletedAt
-	Filename      string       `gorm:"uniqueIndex;not null"` // Nome original do arquivo
-	StoredPath    string       `gorm:"uniqueIndex;not null"` // Caminho completo onde a foto está armazenada
-	ThumbnailPath string       // Caminho para a miniatura (opcional, para futuras implementações)
-	UploadDate    time.Time    // Data/hora do upload
-	ExifDate      *time.Time   // Data/hora da foto extraída do EXIF (pode ser nula)
-	Hash          string       `gorm:"uniqueIndex;not null"` // Hash da foto para detecção de duplicatas
-	FileSize      int64        // Tamanho do arquivo em bytes
-	MimeType      string       // Tipo MIME do arquivo (ex: image/jpeg)
-	Width         int          // Largura da imagem em pixels
-	Height        int          // Altura da imagem em pixels
-	Description   string       // Descrição ou legenda da foto
-	Tags          string       // Tags da foto, armazenadas como string separada por vírgulas (ex: "viagem,praia")
-	AlbumPhotos   []AlbumPhoto // Relação com a tabela de junção AlbumPhoto
+	gorm.Model               // Inclui campos padrão como ID, CreatedAt, UpdatedAt, DeletedAt
+	Filename      string     `gorm:"uniqueIndex;not null"` // Nome original do arquivo
+	StoredPath    string     `gorm:"uniqueIndex;not null"` // Caminho completo onde a foto está armazenada
+	ThumbnailPath string     // Caminho para a miniatura (opcional, para futuras implementações)
+	PreviewPath   string     // Prévia de baixa resolução: a miniatura embutida no EXIF, gravada na ingestão
+	UploadDate    time.Time  // Data/hora do upload
+	ExifDate      *time.Time // Data/hora da foto extraída do EXIF (pode ser nula)
+	Hash          string     `gorm:"uniqueIndex:idx_photos_hash_copy;not null"` // Hash da foto para detecção de duplicatas
+	FileSize      int64      // Tamanho do arquivo em bytes
+	MimeType      string     // Tipo MIME do arquivo (ex: image/jpeg)
+	Width         int        // Largura da imagem em pixels
+	Height        int        // Altura da imagem em pixels
+	Description   string     // Descrição ou legenda da foto
+	Tags          string     // Tags da foto, armazenadas como string separada por vírgulas (ex: "viagem,praia")
+	Favorite      bool       `gorm:"index;not null;default:false"` // Foto marcada como favorita
+	Hidden        bool       `gorm:"index;not null;default:false"` // Foto oculta da linha do tempo e das buscas
+	// Foto utilitária (recibo, quadro branco): fora da linha do tempo, mas visível nos álbuns e nas buscas
+	HideFromTimeline bool         `gorm:"index;not null;default:false"`
+	Locked           bool         `gorm:"not null;default:false"` // Foto protegida contra alterações e remoção
+	Latitude         *float64     // Latitude GPS extraída do EXIF (pode ser nula)
+	Longitude        *float64     // Longitude GPS extraída do EXIF (pode ser nula)
+	CameraMake       string       `gorm:"index"` // Fabricante da câmera extraído do EXIF
+	CameraModel      string       `gorm:"index"` // Modelo da câmera extraído do EXIF
+	LensModel        string       `gorm:"index"` // Modelo da lente extraído do EXIF
+	FocalLength      *float64     // Distância focal em mm extraída do EXIF (pode ser nula)
+	ISO              *int         // Sensibilidade ISO extraída do EXIF (pode ser nula)
+	AlbumPhotos      []AlbumPhoto // Relação com a tabela de junção AlbumPhoto
+
+	// Cópia de uma foto já existente, gravada mesmo assim pela política de duplicatas "version" da origem:
+	// 0 na primeira foto com o conteúdo, 1, 2... nas cópias seguintes (ligadas à primeira pela relação version_of)
+	CopyIndex int `gorm:"uniqueIndex:idx_photos_hash_copy;not null;default:0"`
+
+	// Indexação no local: a foto é servida a partir do caminho original (StoredPath), sem cópia
+	ManagedExternally bool       `gorm:"index;not null;default:false"` // true se o arquivo não pertence ao armazenamento gerenciado
+	SourceModTime     *time.Time // Data de modificação do arquivo original, usada na detecção de mudanças ao reindexar
+
+	// Procedência: por onde a foto chegou à biblioteca (vazio em fotos anteriores ao registro da procedência)
+	Source       string `gorm:"index"` // Canal de entrada (ver constantes Source*)
+	SourceDevice string `gorm:"index"` // Dispositivo informado pelo cliente (ex: "Celular da Mãe") ou nome da origem espelhada
+	SourceDetail string // Detalhe: cliente do upload (User-Agent), caminho importado ou chave do objeto espelhado
+
+	Volume string `gorm:"index"` // Nome do volume de armazenamento onde o arquivo está (vazio = volume padrão)
+	// Redução na ingestão: fotos acima do limite de megapixels são armazenadas em resolução menor
+	Downscaled   bool   `gorm:"not null;default:false"` // true se o arquivo armazenado foi reduzido
+	OriginalPath string // Original em resolução completa, se guardado no armazenamento de originais
+
+	// Impressões digitais visuais, calculadas sob demanda pelo relatório de duplicatas
+	PixelHash      string `gorm:"index"` // SHA-256 dos pixels decodificados (vazio se a imagem não pôde ser decodificada)
+	PerceptualHash string // dHash de 64 bits em hexadecimal, para detectar fotos quase idênticas
+	FingerprintOf  string // Hash do arquivo usado no cálculo; diferente de Hash indica impressões desatualizadas
+
+	// Quarentena: fotos cuja imagem falha repetidamente ao ser decodificada deixam de ser processadas
+	ProcessingFailures int    `gorm:"not null;default:0"`           // Falhas consecutivas ao decodificar a imagem
+	Quarantined        bool   `gorm:"index;not null;default:false"` // true após falhas repetidas
+	QuarantineReason   string // Erro que levou a foto à quarentena
+
+	// Estatísticas de acesso, gravadas em lote pelo AccessStatsService
+	ViewCount     int64 `gorm:"index;not null;default:0"` // Visualizações do detalhe da foto (ou reproduções do vídeo)
+	DownloadCount int64 `gorm:"not null;default:0"`       // Downloads do arquivo original
+
+	// Panoramas e fotos 360°: projeção detectada pelos metadados GPano (XMP) ou pela proporção da imagem.
+	// As medidas GPano são as do arquivo gravado pela câmera, antes de uma eventual redução na ingestão.
+	Projection         string `gorm:"index"` // Vazio para fotos comuns (ver constantes Projection*)
+	PanoFullWidth      int    // Largura do panorama completo (360°), se declarada
+	PanoFullHeight     int    // Altura do panorama completo (180°), se declarada
+	PanoCroppedWidth   int    // Largura da área capturada dentro do panorama completo
+	PanoCroppedHeight  int    // Altura da área capturada
+	PanoCroppedLeft    int    // Posição da área capturada dentro do panorama completo
+	PanoCroppedTop     int
+	PanoInitialHeading *float64 // Direção inicial da visualização, em graus
+
+	// Imagens auxiliares embutidas pela câmera no arquivo, preservadas no armazenamento e nas cópias reduzidas
+	HasDepthMap      bool `gorm:"index;not null;default:false"` // Mapa de profundidade (modo retrato)
+	HasGainMap       bool `gorm:"index;not null;default:false"` // Mapa de ganho HDR
+	HasPortraitMatte bool `gorm:"not null;default:false"`       // Máscara de recorte do modo retrato
+
+	// Fuso em que a foto foi tirada, em minutos a leste de UTC (nil se desconhecido): lido do EXIF
+	// (OffsetTimeOriginal, fuso da câmera ou hora do GPS), situa a data EXIF na hora local da captura
+	TimeZoneOffset *int
+
+	// Data aproximada informada manualmente, para fotos sem data confiável (ex: fotos analógicas
+	// digitalizadas): início do período (meia-noite UTC) e precisão (ver constantes CircaPrecision*).
+	// Prevalece sobre as datas EXIF e de upload na linha do tempo e nos filtros por ano e mês.
+	CircaDate      *time.Time
+	CircaPrecision string
+
+	// Identificador público imutável (ULID), usado nas URLs compartilhadas e na API pública no lugar do ID
+	PublicID string `gorm:"uniqueIndex;default:null"`
+}
+
+// Precisões da data aproximada de uma foto (Photo.CircaPrecision).
+const (
+	CircaPrecisionMonth  = "month"  // Mês conhecido, ex: março de 1985
+	CircaPrecisionYear   = "year"   // Só o ano, ex: 1985
+	CircaPrecisionDecade = "decade" // Só a década, ex: anos 1980
+)
+
+// BeforeCreate atribui o identificador público das fotos novas.
+func (p *Photo) BeforeCreate(tx *gorm.DB) error {
+	if p.PublicID == "" {
+		p.PublicID = publicid.New()
+	}
+	return nil
 }
 
+// Projeções das fotos panorâmicas (Photo.Projection).
+const (
+	ProjectionEquirectangular = "equirectangular" // Esfera completa ou parcial (foto 360°): abrir em um visualizador 360
+	ProjectionCylindrical     = "cylindrical"     // Panorama cilíndrico declarado no GPano
+	ProjectionPanorama        = "panorama"        // Panorama plano, detectado apenas pela proporção da imagem
+)
+
+// Canais de entrada das fotos (Photo.Source).
+const (
+	SourceUpload      = "upload"       // Upload pela API ou interface web
+	SourceSync        = "sync"         // Sincronização automática de um dispositivo (ex: app do celular)
+	SourceEmail       = "email"        // Recebida por e-mail (ponte de e-mail que envia à API)
+	SourceShareUpload = "share_upload" // Enviada por terceiros a partir de um compartilhamento
+	SourceImport      = "import"       // Job de importação de um diretório local
+	SourceMirror      = "mirror"       // Origem espelhada (S3, diretório montado)
+)
+
+// ClientSources são os canais que um cliente pode declarar no upload; os demais são definidos pela aplicação.
+var ClientSources = []string{SourceUpload, SourceSync, SourceEmail, SourceShareUpload}
+
+// Sources são todos os canais de entrada.
+var Sources = []string{SourceUpload, SourceSync, SourceEmail, SourceShareUpload, SourceImport, SourceMirror}
+
 // Album representa um álbum personalizado de fotos.
 type Album struct {
 	gorm.Model
-	Name        string       `gorm:"uniqueIndex;not null"` // Nome do álbum
-	Description string       // Descrição do álbum
+	Name        string `gorm:"uniqueIndex;not null"` // Nome do álbum
+	Description string // Descrição do álbum
+	Pinned      bool   `gorm:"not null;default:false"` // Álbum fixado no topo das listagens
+	Locked      bool   `gorm:"not null;default:false"` // Álbum protegido: fotos não podem ser adicionadas, removidas ou alteradas
+	// Cota flexível: ao ultrapassá-la o álbum apenas gera um aviso, nada é bloqueado
+	SoftQuotaBytes int64 `gorm:"not null;default:0"`     // Tamanho máximo desejado do álbum (0 = sem cota)
+	QuotaExceeded  bool  `gorm:"not null;default:false"` // Cota ultrapassada na última verificação (evita avisos repetidos)
+	// Período das fotos (data EXIF ou, na falta, de upload), recalculado quando as fotos do álbum mudam
+	StartDate   *time.Time   `gorm:"index"` // Data da foto mais antiga (nil em álbuns vazios)
+	EndDate     *time.Time   `gorm:"index"` // Data da foto mais recente (nil em álbuns vazios)
 	AlbumPhotos []AlbumPhoto // Relação com a tabela de junção AlbumPhoto
+
+	// Identificador público imutável (ULID), usado nas URLs compartilhadas e na API pública no lugar do ID
+	PublicID string `gorm:"uniqueIndex;default:null"`
+
+	// Tags aplicadas às fotos incluídas pelo POST /albums/:id/photos, separadas por vírgula (definidas pelo
+	// modelo de álbum)
+	DefaultTags string
+
+	// Campos personalizados definidos pelo usuário (ex: Cliente, Local, Cachê), em JSON: objeto de nomes para
+	// valores, ambos texto
+	Metadata string `gorm:"type:text"`
+}
+
+// BeforeCreate atribui o identificador público dos álbuns novos.
+func (a *Album) BeforeCreate(tx *gorm.DB) error {
+	if a.PublicID == "" {
+		a.PublicID = publicid.New()
+	}
+	return nil
+}
+
+// AlbumTemplate é um modelo de álbum para eventos recorrentes (ex: "Jantar da família {{month_name}}
+// {{year}}"): o padrão do nome e da descrição, as tags padrão, o compartilhamento e as políticas de ciclo de
+// vida aplicados a cada álbum criado a partir dele.
+type AlbumTemplate struct {
+	gorm.Model
+	Name               string `gorm:"uniqueIndex;not null"` // Nome do modelo
+	NamePattern        string `gorm:"not null"`             // Padrão do nome do álbum, com variáveis {{...}}
+	DescriptionPattern string // Padrão da descrição do álbum, com variáveis {{...}}
+	DefaultTags        string // Tags padrão do álbum, separadas por vírgula (ver Album.DefaultTags)
+	Pinned             bool   `gorm:"not null;default:false"` // Fixa o álbum no topo das listagens
+	Share              bool   `gorm:"not null;default:false"` // Cria um link de compartilhamento para o álbum
+	SoftQuotaBytes     int64  `gorm:"not null;default:0"`     // Cota flexível do álbum (0 = sem cota)
+
+	Policies []AlbumTemplatePolicy `gorm:"foreignkey:TemplateID"` // Políticas de ciclo de vida criadas no álbum
+}
+
+// AlbumTemplatePolicy é uma política de ciclo de vida de um modelo de álbum (ver AlbumPolicy). As políticas
+// criadas nos álbuns nascem não confirmadas, como as criadas diretamente.
+type AlbumTemplatePolicy struct {
+	ID              uint   `gorm:"primarykey"`
+	TemplateID      uint   `gorm:"index;not null"`
+	Name            string `gorm:"not null"`
+	Action          string `gorm:"not null"`
+	OlderThanDays   int    `gorm:"not null"`
+	ScreenshotsOnly bool   `gorm:"not null;default:false"`
+	ExceptFavorites bool   `gorm:"not null;default:false"`
 }
 
 // AlbumPhoto é uma tabela de junção para a relação muitos-para-muitos entre Photo e Album.
@@ -40,3 +208,450 @@ type AlbumPhoto struct {
 	AlbumID uint  // ID do álbum
 	Album   Album `gorm:"foreignkey:AlbumID"`
 }
+
+// Tag representa uma tag normalizada. O nome é único sem diferenciar maiúsculas de minúsculas.
+// Photo.Tags mantém uma cópia desnormalizada (separada por vírgulas) para buscas simples.
+type Tag struct {
+	gorm.Model
+	Name      string     `gorm:"uniqueIndex;not null"` // Nome da tag, como informado pela primeira vez
+	PhotoTags []PhotoTag // Relação com a tabela de junção PhotoTag
+}
+
+// PhotoTag é uma tabela de junção para a relação muitos-para-muitos entre Photo e Tag.
+type PhotoTag struct {
+	gorm.Model
+	PhotoID uint  `gorm:"index"` // ID da foto
+	Photo   Photo `gorm:"foreignkey:PhotoID"`
+	TagID   uint  `gorm:"index"` // ID da tag
+	Tag     Tag   `gorm:"foreignkey:TagID"`
+}
+
+// Status possíveis de um ImportJob.
+const (
+	ImportStatusPending   = "pending"   // Criado, aguardando execução
+	ImportStatusRunning   = "running"   // Em execução (ou interrompido por um reinício, será retomado)
+	ImportStatusCompleted = "completed" // Todos os arquivos do diretório foram processados
+	ImportStatusFailed    = "failed"    // Falha que impede a continuação (ex: diretório inexistente)
+)
+
+// ImportJob representa uma importação de diretório local com estado persistido,
+// permitindo retomar uma importação interrompida a partir do último arquivo processado.
+type ImportJob struct {
+	gorm.Model
+	SourcePath     string     `gorm:"not null"`               // Diretório de origem da importação
+	InPlace        bool       `gorm:"not null;default:false"` // Indexa os arquivos no local, sem copiá-los
+	Format         string     // Formato da biblioteca de origem (photoprism, immich); vazio para um diretório comum
+	ManifestPath   string     // JSON de metadados exportado da API do Immich (opcional)
+	Status         string     `gorm:"index;not null"` // Status atual do job (ver constantes ImportStatus*)
+	Cursor         string     // Caminho relativo do último arquivo processado (checkpoint na ordem do WalkDir)
+	ProcessedFiles int        // Total de arquivos processados (importados + atualizados + ignorados + com falha)
+	ImportedFiles  int        // Fotos importadas com sucesso
+	UpdatedFiles   int        // Fotos indexadas no local cujo arquivo mudou desde a última varredura
+	SkippedFiles   int        // Arquivos ignorados (duplicatas ou já indexados sem alterações)
+	FailedFiles    int        // Arquivos que falharam durante o processamento
+	MissingFiles   int        // Fotos indexadas no local cujo arquivo não existe mais (verificado ao final)
+	LastError      string     // Último erro registrado
+	StartedAt      *time.Time // Início da primeira execução
+	FinishedAt     *time.Time // Fim da execução (nil enquanto não terminar)
+}
+
+// Status possíveis de um ExportJob.
+const (
+	ExportStatusPending   = "pending"   // Criado, aguardando execução
+	ExportStatusRunning   = "running"   // Em execução (ou interrompido por um reinício, será refeito)
+	ExportStatusCompleted = "completed" // Arquivo pronto para download
+	ExportStatusFailed    = "failed"    // Falha ao gerar o arquivo
+)
+
+// ExportJob representa uma exportação completa da biblioteca (originais e manifesto de metadados) em um
+// arquivo ZIP, para portabilidade dos dados.
+type ExportJob struct {
+	gorm.Model
+	Status         string     `gorm:"index;not null"` // Status atual do job (ver constantes ExportStatus*)
+	ArchivePath    string     // Arquivo ZIP gerado (vazio até terminar ou após ser substituído por uma exportação mais nova)
+	TotalPhotos    int        // Fotos na biblioteca no início da exportação
+	ExportedPhotos int        // Fotos cujo arquivo foi incluído no ZIP
+	SkippedPhotos  int        // Fotos cujo arquivo não pôde ser lido (constam apenas no manifesto)
+	SizeBytes      int64      // Tamanho do arquivo ZIP
+	LastError      string     // Último erro registrado
+	StartedAt      *time.Time // Início da execução
+	FinishedAt     *time.Time // Fim da execução (nil enquanto não terminar)
+}
+
+// DownloadJob representa a preparação em background do ZIP de uma seleção de fotos grande demais para ser
+// gerada durante a requisição. Usa os mesmos status de ExportJob.
+type DownloadJob struct {
+	gorm.Model
+	Status         string     `gorm:"index;not null"` // Status atual do job (ver constantes ExportStatus*)
+	PhotoIDs       string     `gorm:"type:text"`      // Fotos selecionadas, em JSON (ex: [1,2,3])
+	EstimatedBytes int64      // Soma dos arquivos selecionados no momento do pedido
+	ArchivePath    string     // Arquivo ZIP gerado (vazio até terminar ou após expirar)
+	TotalPhotos    int        // Fotos selecionadas
+	ArchivedPhotos int        // Fotos cujo arquivo foi incluído no ZIP
+	SkippedPhotos  int        // Fotos cujo arquivo não pôde ser lido
+	SizeBytes      int64      // Tamanho do arquivo ZIP
+	LastError      string     // Último erro registrado
+	StartedAt      *time.Time // Início da execução
+	FinishedAt     *time.Time // Fim da execução (nil enquanto não terminar)
+}
+
+// Selection é uma seleção temporária de fotos (cesta), montada em várias chamadas e usada em ações em lote:
+// download, álbum, tags e compartilhamento. É identificada por um token aleatório e expira após um dia sem
+// uso.
+type Selection struct {
+	gorm.Model
+	Token     string    `gorm:"uniqueIndex;not null"` // Token usado na URL (/selections/:token)
+	ExpiresAt time.Time `gorm:"index;not null"`       // Renovado a cada uso da seleção
+}
+
+// SelectionPhoto é uma foto de uma seleção.
+type SelectionPhoto struct {
+	SelectionID uint      `gorm:"primaryKey"`
+	PhotoID     uint      `gorm:"primaryKey;index"`
+	CreatedAt   time.Time // Ordem em que a foto entrou na seleção
+}
+
+// StorageVolume representa uma raiz de armazenamento registrada (ex: um segundo disco).
+type StorageVolume struct {
+	gorm.Model
+	Name     string     `gorm:"uniqueIndex;not null"` // Nome do volume (referenciado por Photo.Volume)
+	Path     string     `gorm:"not null"`             // Diretório raiz do volume
+	Priority int        // Ordem de preenchimento (menor valor = usado primeiro)
+	DateFrom *time.Time // Início do intervalo de datas das fotos aceitas (política date-range), opcional
+	DateTo   *time.Time // Fim do intervalo de datas das fotos aceitas (política date-range), opcional
+}
+
+// ShareLink representa um link público de compartilhamento de um álbum, identificado por um token aleatório.
+type ShareLink struct {
+	gorm.Model
+	Token   string `gorm:"uniqueIndex;not null"` // Token usado na URL pública (/s/:token)
+	AlbumID uint   `gorm:"index;not null"`       // Álbum compartilhado
+	Album   Album  `gorm:"foreignkey:AlbumID"`
+}
+
+// Tipos de reação a uma foto de um álbum compartilhado.
+const (
+	ReactionThumbsUp = "thumbs_up" // 👍
+	ReactionHeart    = "heart"     // ❤️
+)
+
+// PhotoReaction é o voto de um visitante de um álbum compartilhado em uma das fotos (ex: a família
+// escolhendo quais fotos da viagem imprimir). Não há contas: o visitante se identifica pelo nome, e cada
+// nome vota no máximo uma vez por tipo de reação em cada foto do álbum.
+type PhotoReaction struct {
+	gorm.Model
+	AlbumID uint   `gorm:"uniqueIndex:idx_photo_reaction;not null"` // Álbum em que a foto recebeu a reação
+	PhotoID uint   `gorm:"uniqueIndex:idx_photo_reaction;index;not null"`
+	Voter   string `gorm:"uniqueIndex:idx_photo_reaction;not null"` // Nome informado pelo visitante
+	Kind    string `gorm:"uniqueIndex:idx_photo_reaction;not null"` // Tipo de reação (ver constantes Reaction*)
+}
+
+// SourceAlbum define o álbum onde entram automaticamente as fotos recebidas de um canal de entrada ou de um
+// dispositivo (ex: tudo o que chega da "Câmera da porta" vai para o álbum "Portaria").
+type SourceAlbum struct {
+	gorm.Model
+	Source  string `gorm:"uniqueIndex:idx_source_album_origin;not null"` // Canal de entrada (ver constantes Source*)
+	Device  string `gorm:"uniqueIndex:idx_source_album_origin"`          // Dispositivo; vazio vale para todo o canal
+	AlbumID uint   `gorm:"index;not null"`                               // Álbum padrão
+	Album   Album  `gorm:"foreignkey:AlbumID"`
+}
+
+// Políticas de duplicatas: o que acontece quando chega de uma origem um arquivo cujo conteúdo já está na
+// biblioteca.
+const (
+	DuplicatePolicyReject  = "reject"  // Rejeita o arquivo com o erro photo_duplicate (padrão)
+	DuplicatePolicySkip    = "skip"    // Ignora o arquivo silenciosamente, sem erro
+	DuplicatePolicyLink    = "link"    // Ignora o arquivo e inclui a foto existente no álbum da política
+	DuplicatePolicyVersion = "version" // Grava o arquivo mesmo assim, como cópia da foto existente
+)
+
+// DuplicatePolicies são as políticas de duplicatas aceitas.
+var DuplicatePolicies = []string{DuplicatePolicyReject, DuplicatePolicySkip, DuplicatePolicyLink, DuplicatePolicyVersion}
+
+// SourceDuplicatePolicy define a política de duplicatas de um canal de entrada ou de um dispositivo (ex: a
+// câmera de segurança grava tudo, o backup do celular ignora o que já foi enviado).
+type SourceDuplicatePolicy struct {
+	gorm.Model
+	Source  string `gorm:"uniqueIndex:idx_source_duplicate_policy_origin;not null"` // Canal de entrada (ver constantes Source*)
+	Device  string `gorm:"uniqueIndex:idx_source_duplicate_policy_origin"`          // Dispositivo; vazio vale para todo o canal
+	Policy  string `gorm:"not null"`                                                // Ver constantes DuplicatePolicy*
+	AlbumID *uint  // Álbum da política link (nil usa o álbum padrão da origem)
+	Album   *Album `gorm:"foreignkey:AlbumID"`
+}
+
+// Ações das políticas de ciclo de vida de álbuns.
+const (
+	AlbumPolicyActionDelete = "delete" // Apaga a foto da biblioteca
+	AlbumPolicyActionRemove = "remove" // Apenas retira a foto do álbum
+)
+
+// AlbumPolicy é uma regra de ciclo de vida de um álbum, executada periodicamente pelo agendador
+// (ex: "apagar capturas de tela com mais de 90 dias", "manter só as favoritas após 1 ano").
+// A regra só é executada depois de confirmada; qualquer alteração exige nova confirmação.
+type AlbumPolicy struct {
+	gorm.Model
+	AlbumID         uint       `gorm:"index;not null"`         // Álbum ao qual a regra se aplica
+	Name            string     `gorm:"not null"`               // Descrição da regra
+	Action          string     `gorm:"not null"`               // Ação sobre as fotos selecionadas (ver constantes AlbumPolicyAction*)
+	OlderThanDays   int        `gorm:"not null"`               // Idade mínima das fotos (data EXIF, ou de upload), em dias
+	ScreenshotsOnly bool       `gorm:"not null;default:false"` // Seleciona apenas capturas de tela (pelo nome do arquivo)
+	ExceptFavorites bool       `gorm:"not null;default:false"` // Preserva as fotos favoritas
+	Confirmed       bool       `gorm:"not null;default:false"` // A prévia foi revisada e a regra liberada para execução
+	LastRunAt       *time.Time // Última execução
+	LastRunAffected int        // Fotos afetadas na última execução
+}
+
+// Tipos de origem de espelhamento.
+const (
+	MirrorKindS3  = "s3"  // Bucket S3 ou de um serviço compatível
+	MirrorKindDir = "dir" // Diretório local ou montado (SMB, NFS, FTP via curlftpfs...)
+	// Link de compartilhamento de um álbum de outra instância do photo-manager (assinatura)
+	MirrorKindShare = "share"
+)
+
+// Situação de cada objeto espelhado.
+const (
+	MirrorObjectImported  = "imported"  // Importado como nova foto
+	MirrorObjectDuplicate = "duplicate" // Conteúdo já existente na biblioteca
+	MirrorObjectFailed    = "failed"    // Falhou ao baixar ou importar (nova tentativa só se o objeto mudar)
+)
+
+// MirrorSource é uma origem remota espelhada periodicamente para a biblioteca (somente de lá para cá:
+// nada é alterado ou removido na origem, e objetos removidos da origem continuam na biblioteca). As
+// assinaturas de álbuns compartilhados (tipo share) mantêm também um álbum local, somente leitura, com as
+// fotos do álbum compartilhado: as que saem de lá saem do álbum local, mas continuam na biblioteca.
+type MirrorSource struct {
+	gorm.Model
+	Name            string     `gorm:"uniqueIndex;not null"` // Nome da origem
+	Kind            string     `gorm:"not null"`             // Tipo da origem (ver constantes MirrorKind*)
+	Path            string     // Diretório espelhado (tipo dir)
+	Endpoint        string     // URL do serviço compatível com S3 (vazio: AWS)
+	Region          string     // Região do bucket (padrão: us-east-1)
+	Bucket          string     // Bucket espelhado (tipo s3)
+	Prefix          string     // Apenas objetos com este prefixo (tipo s3)
+	AccessKey       string     // Credenciais do S3 (vazias: acesso anônimo)
+	SecretKey       string     // Nunca devolvida pela API
+	IntervalMinutes int        `gorm:"not null"` // Intervalo entre sincronizações automáticas, em minutos (0 = apenas manual)
+	LastSyncAt      *time.Time // Fim da última sincronização
+	LastImported    int        // Objetos importados na última sincronização
+	LastSkipped     int        // Objetos duplicados na última sincronização
+	LastFailed      int        // Objetos com falha na última sincronização
+	LastError       string     // Erro que interrompeu a última sincronização (ou do último objeto com falha)
+
+	URL     string // Link de compartilhamento assinado (tipo share), ex: https://fotos.familia.com/s/<token>
+	AlbumID *uint  // Álbum local bloqueado que acompanha o álbum compartilhado (tipo share)
+}
+
+// MirrorObject registra um objeto já processado de uma origem, identificado pela chave e pelo ETag:
+// o objeto só é processado de novo se for substituído na origem (ETag diferente).
+type MirrorObject struct {
+	ID       uint      `gorm:"primaryKey"`
+	SourceID uint      `gorm:"uniqueIndex:idx_mirror_object;not null"` // Origem (MirrorSource)
+	Key      string    `gorm:"uniqueIndex:idx_mirror_object;not null"` // Chave do objeto na origem
+	ETag     string    `gorm:"not null"`                               // Versão processada
+	Status   string    `gorm:"not null"`                               // Resultado (ver constantes MirrorObject*)
+	PhotoID  *uint     `gorm:"index"`                                  // Foto importada (ou já existente, se duplicata)
+	Error    string    // Erro da última tentativa
+	SyncedAt time.Time // Data do processamento
+}
+
+// LedgerEntry é uma entrada do livro-razão de integridade: uma lista somente de inclusão em que cada
+// entrada guarda o hash do arquivo de uma foto e o hash da entrada anterior, de modo que qualquer
+// alteração posterior no histórico (ou nos arquivos) pode ser detectada.
+type LedgerEntry struct {
+	Seq           uint64    `gorm:"primaryKey;autoIncrement:false"` // Posição na cadeia, a partir de 1
+	PhotoID       uint      `gorm:"index;not null"`                 // Foto registrada (pode já ter sido removida)
+	Action        string    `gorm:"not null"`                       // Motivo do registro (ver constantes Action* do pacote ledger)
+	Filename      string    // Nome do arquivo na data do registro
+	FileHash      string    // Hash da foto na biblioteca (Photo.Hash) na data do registro
+	ContentSHA256 string    // SHA-256 do arquivo armazenado (vazio em remoções)
+	RecordedAt    time.Time `gorm:"not null"`
+	PrevHash      string    `gorm:"not null"`             // Hash da entrada anterior
+	EntryHash     string    `gorm:"uniqueIndex;not null"` // Hash desta entrada, que encadeia a seguinte
+}
+
+// LedgerTimestamp é um carimbo de tempo RFC 3161 emitido por uma autoridade externa para o hash de uma
+// entrada do livro-razão, provando que a cadeia até aquela entrada já existia na data atestada.
+type LedgerTimestamp struct {
+	ID        uint   `gorm:"primaryKey"`
+	Seq       uint64 `gorm:"index;not null"` // Entrada carimbada
+	EntryHash string `gorm:"not null"`       // Hash carimbado (o da entrada, na data do carimbo)
+	Authority string // URL da autoridade de carimbo de tempo
+	Token     []byte // Token devolvido pela autoridade (DER)
+	CreatedAt time.Time
+}
+
+// MetadataDump guarda todos os metadados EXIF e IPTC de uma foto, lidos na primeira consulta. O cache é
+// refeito quando o arquivo lido muda (tamanho ou data de modificação), ex: após a remoção de campos EXIF.
+type MetadataDump struct {
+	ID         uint      `gorm:"primaryKey"`
+	PhotoID    uint      `gorm:"uniqueIndex;not null"` // Foto
+	SourcePath string    `gorm:"not null"`             // Arquivo lido (o original, se guardado, ou a cópia armazenada)
+	SourceKey  string    `gorm:"not null"`             // Tamanho e data de modificação do arquivo na leitura
+	Data       string    `gorm:"not null"`             // Metadados em JSON (ver exif.Dump)
+	ParsedAt   time.Time `gorm:"not null"`
+}
+
+// MetadataChange registra a alteração de um campo de metadados de uma foto feita por uma reconciliação
+// com o arquivo (ex: datas corrigidas em outro programa) ou pela adoção de uma nova versão do arquivo de uma
+// foto indexada no local, para auditoria e eventual reversão manual.
+type MetadataChange struct {
+	ID        uint      `gorm:"primaryKey"`
+	PhotoID   uint      `gorm:"index;not null"`
+	Field     string    `gorm:"not null"` // Coluna alterada (ex: exif_date)
+	OldValue  string    // Valor anterior, formatado (vazio se nulo)
+	NewValue  string    // Valor aplicado, formatado (vazio se nulo)
+	Source    string    `gorm:"not null"` // Origem da alteração (ex: exif_reconcile, source_adopt)
+	Policy    string    // Política que decidiu a alteração
+	AppliedAt time.Time `gorm:"index;not null"`
+}
+
+// Tipos de relação entre fotos. A relação vai da foto derivada (FromPhotoID) para a foto de referência
+// (ToPhotoID): a edição aponta para o RAW, o quadro aponta para o panorama montado, a digitalização aponta
+// para a foto do mesmo papel.
+const (
+	RelationEditedFrom   = "edited_from"   // A foto é uma edição (ex: JPEG exportado) da foto de destino (ex: o RAW)
+	RelationScanOf       = "scan_of"       // A foto é uma digitalização da mesma foto física que a de destino
+	RelationPanoramaPart = "panorama_part" // A foto é um dos quadros usados para montar o panorama de destino
+	RelationRelated      = "related"       // Ligação livre, sem direção definida
+	RelationVersionOf    = "version_of"    // A foto é uma cópia do mesmo conteúdo gravada pela política de duplicatas "version"
+)
+
+// PhotoRelation liga duas fotos com um tipo de relação (ver constantes Relation*), para navegar entre
+// versões, quadros e digitalizações de uma mesma imagem.
+type PhotoRelation struct {
+	gorm.Model
+	FromPhotoID uint   `gorm:"uniqueIndex:idx_photo_relation;index;not null"`
+	ToPhotoID   uint   `gorm:"uniqueIndex:idx_photo_relation;index;not null"`
+	Kind        string `gorm:"uniqueIndex:idx_photo_relation;not null"`
+	Note        string // Observação livre (ex: "recorte para impressão")
+}
+
+// Tipos de anotação. Rostos e animais correspondem às regiões Face e Pet do esquema XMP MWG, lidas e gravadas
+// pelos programas de fotos (Picasa, digiKam, Lightroom).
+const (
+	AnnotationKindNote = "note" // Nota livre sobre a região
+	AnnotationKindFace = "face" // Rosto de uma pessoa; o texto é o nome
+	AnnotationKindPet  = "pet"  // Animal de estimação; o texto é o nome
+)
+
+// PhotoAnnotation é uma nota sobre uma região retangular da foto (ex: "a casa do vovô"). As coordenadas são
+// frações da largura e da altura da imagem (0 a 1, a partir do canto superior esquerdo), para valerem em
+// qualquer tamanho de exibição.
+type PhotoAnnotation struct {
+	gorm.Model
+	PhotoID uint    `gorm:"index;not null"`
+	X       float64 `gorm:"not null"` // Borda esquerda da região
+	Y       float64 `gorm:"not null"` // Borda superior da região
+	Width   float64 `gorm:"not null"` // Largura da região
+	Height  float64 `gorm:"not null"` // Altura da região
+	Text    string  `gorm:"not null"` // Texto da nota (nos rostos e animais, o nome)
+
+	Kind string `gorm:"index;not null;default:note"` // Tipo da região (ver constantes AnnotationKind*)
+}
+
+// Tipos de lote de um ImportReport.
+const (
+	ImportKindUpload    = "upload"    // Upload pela API (POST /upload)
+	ImportKindZip       = "zip"       // Arquivo ZIP de álbuns (POST /albums/import)
+	ImportKindDirectory = "directory" // Job de importação de diretório ou biblioteca (ImportJob)
+)
+
+// Resultados de um arquivo em um lote de importação.
+const (
+	ImportOutcomeImported  = "imported"  // Foto nova na biblioteca
+	ImportOutcomeUpdated   = "updated"   // Foto indexada no local com o arquivo alterado
+	ImportOutcomeUnchanged = "unchanged" // Foto indexada no local sem alterações desde a última varredura
+	ImportOutcomeDuplicate = "duplicate" // A foto já estava na biblioteca (ImportReportEntry.PhotoID é a existente)
+	ImportOutcomeSkipped   = "skipped"   // Arquivo ignorado: oculto, de sistema ou com extensão não suportada
+	ImportOutcomeFailed    = "failed"    // Arquivo rejeitado na validação ou com erro
+)
+
+// ImportReport é o relatório persistido de um lote de importação ou upload, com a contagem por resultado.
+// Os arquivos não importados (duplicatas, ignorados e falhas) são detalhados em ImportReportEntry.
+type ImportReport struct {
+	gorm.Model
+	Kind        string     `gorm:"index;not null"` // Tipo do lote (ver constantes ImportKind*)
+	Source      string     // Origem do lote: canal de entrada do upload, nome do ZIP ou diretório importado
+	ImportJobID *uint      `gorm:"uniqueIndex"` // Job de importação do lote (tipo directory)
+	Total       int        // Arquivos considerados (soma dos resultados)
+	Imported    int        // Fotos novas
+	Updated     int        // Fotos indexadas no local com o arquivo alterado
+	Unchanged   int        // Fotos indexadas no local sem alterações
+	Duplicates  int        // Fotos que já estavam na biblioteca
+	Skipped     int        // Arquivos ignorados
+	Failed      int        // Arquivos rejeitados ou com erro
+	Interrupted string     // Motivo da interrupção do lote antes do fim (ex: sem espaço), vazio se completo
+	FinishedAt  *time.Time // Fim do lote (nil enquanto em andamento)
+}
+
+// ImportReportEntry é um arquivo de um lote que não foi importado, com o motivo.
+type ImportReportEntry struct {
+	ID        uint   `gorm:"primaryKey"`
+	ReportID  uint   `gorm:"index;not null"`
+	Name      string `gorm:"not null"` // Nome do arquivo, entrada do ZIP ou caminho relativo ao diretório importado
+	Outcome   string `gorm:"not null"` // Ver constantes ImportOutcome*
+	PhotoID   *uint  // Foto existente, nas duplicatas
+	Code      string // Código do erro (i18n ou do validador), nas falhas
+	Validator string // Validador que rejeitou o arquivo, nas falhas de validação
+	Error     string // Mensagem do erro ou motivo do arquivo ignorado
+	CreatedAt time.Time
+}
+
+// Operações administrativas destrutivas que podem ser planejadas (simuladas) e executadas depois de revisadas.
+const (
+	PlanOperationDedupeReclaim = "dedupe_reclaim" // Remoção das cópias redundantes dos grupos de duplicatas
+	PlanOperationAlbumPolicy   = "album_policy"   // Execução de uma política de ciclo de vida de álbum
+)
+
+// Situação de um plano de ação.
+const (
+	PlanStatusPending   = "pending"   // Aguardando revisão e confirmação
+	PlanStatusExecuting = "executing" // Confirmado, em execução
+	PlanStatusExecuted  = "executed"  // Executado (ver a situação de cada ação)
+	PlanStatusDiscarded = "discarded" // Descartado sem execução
+)
+
+// Ações de um plano.
+const (
+	PlanActionDeletePhoto     = "delete_photo"      // Apaga a foto e o arquivo gerenciado (KeepPhotoID herda os álbuns dela)
+	PlanActionRemoveFromAlbum = "remove_from_album" // Retira a foto do álbum; nenhum arquivo é alterado
+)
+
+// Situação de cada ação após a execução do plano.
+const (
+	PlanActionDone    = "done"    // Aplicada
+	PlanActionSkipped = "skipped" // A foto mudou ou foi removida desde o plano
+	PlanActionFailed  = "failed"  // Falhou (ver Error)
+)
+
+// AdminPlan é o plano de ação gravado pela simulação de uma operação destrutiva: a lista completa do que
+// seria feito, para revisão. Só a confirmação do plano executa as ações, exatamente as listadas.
+type AdminPlan struct {
+	gorm.Model
+	Operation   string     `gorm:"index;not null"` // Ver constantes PlanOperation*
+	Params      string     // Parâmetros da operação, em JSON
+	Status      string     `gorm:"index;not null"` // Ver constantes PlanStatus*
+	ActionCount int        // Ações planejadas
+	Bytes       int64      // Bytes dos arquivos que seriam apagados
+	ExecutedAt  *time.Time // Fim da execução
+	Done        int        // Ações aplicadas
+	Skipped     int        // Ações ignoradas porque a foto mudou desde o plano
+	Failed      int        // Ações com falha
+}
+
+// AdminPlanAction é uma ação de um plano. StoredPath e FileSize são os da foto quando o plano foi feito:
+// se a foto mudar até a confirmação, a ação é ignorada em vez de aplicada sobre outro arquivo.
+type AdminPlanAction struct {
+	ID          uint   `gorm:"primaryKey"`
+	PlanID      uint   `gorm:"index;not null"`
+	Action      string `gorm:"not null"` // Ver constantes PlanAction*
+	PhotoID     uint   `gorm:"not null"`
+	AlbumID     uint   // Álbum, em PlanActionRemoveFromAlbum
+	KeepPhotoID uint   // Foto mantida no lugar da apagada, nas duplicatas
+	StoredPath  string // Arquivo afetado
+	FileSize    int64
+	Bytes       int64  // Bytes liberados (0 se o arquivo não é apagado, ex: foto indexada no local)
+	Status      string // Vazio até a execução; ver constantes PlanAction* de situação
+	Error       string
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	queryCancelKey = "photo_manager:query_cancel"
+	noTimeoutKey   = "photo_manager:no_timeout"
+)
+
+// WithoutQueryTimeout retorna uma sessão cujos comandos não estão sujeitos a DB_QUERY_TIMEOUT_SECONDS, para
+// operações que levam naturalmente mais tempo em bancos grandes (cópia, VACUUM, ANALYZE). Eles continuam
+// respeitando o cancelamento do contexto.
+func WithoutQueryTimeout(db *gorm.DB) *gorm.DB {
+	return db.Set(noTimeoutKey, true)
+}
+
+// registerQueryTimeout limita a duração de cada comando (create, query, update, delete e raw) ao timeout
+// informado, somando-se ao contexto da requisição propagado com WithContext. Comandos que devolvem linhas
+// para leitura posterior (Rows, Row e Scan) ficam de fora, pois o cancelamento interromperia a leitura;
+// eles continuam respeitando o cancelamento da requisição.
+func registerQueryTimeout(db *gorm.DB, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+
+	start := func(tx *gorm.DB) {
+		if skip, ok := tx.Get(noTimeoutKey); ok && skip == true {
+			return
+		}
+		ctx, cancel := context.WithTimeout(tx.Statement.Context, timeout)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(queryCancelKey, cancel)
+	}
+	end := func(tx *gorm.DB) {
+		if cancel, ok := tx.InstanceGet(queryCancelKey); ok {
+			cancel.(context.CancelFunc)()
+		}
+	}
+
+	// "*" posiciona os callbacks antes e depois de todos os outros (incluindo hooks e associações)
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("*").Register("photo_manager:timeout_start", start),
+		callbacks.Create().After("*").Register("photo_manager:timeout_end", end),
+		callbacks.Query().Before("*").Register("photo_manager:timeout_start", start),
+		callbacks.Query().After("*").Register("photo_manager:timeout_end", end),
+		callbacks.Update().Before("*").Register("photo_manager:timeout_start", start),
+		callbacks.Update().After("*").Register("photo_manager:timeout_end", end),
+		callbacks.Delete().Before("*").Register("photo_manager:timeout_start", start),
+		callbacks.Delete().After("*").Register("photo_manager:timeout_end", end),
+		callbacks.Raw().Before("*").Register("photo_manager:timeout_start", start),
+		callbacks.Raw().After("*").Register("photo_manager:timeout_end", end),
+	)
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"errors"
+	"photo-manager/internal/tracing"
+
+	"gorm.io/gorm"
+)
+
+const querySpanKey = "photo_manager:query_span"
+
+// registerTracing registra um span para cada comando (create, query, update, delete, raw e row) executado
+// com um contexto que carrega um trace (WithContext), com o SQL, a tabela e as linhas afetadas. Sem o
+// tracing ativo, nada é registrado.
+func registerTracing(db *gorm.DB) error {
+	if !tracing.Enabled() {
+		return nil
+	}
+
+	start := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			if tracing.FromContext(tx.Statement.Context) == nil {
+				return // Comandos fora de uma requisição rastreada (ex: tarefas em segundo plano)
+			}
+			_, span := tracing.StartKind(tx.Statement.Context, "db."+operation, tracing.KindClient,
+				tracing.String("db.system", "sqlite"),
+				tracing.String("db.operation", operation),
+			)
+			tx.InstanceSet(querySpanKey, span)
+		}
+	}
+	end := func(tx *gorm.DB) {
+		value, ok := tx.InstanceGet(querySpanKey)
+		if !ok {
+			return
+		}
+		span := value.(*tracing.Span)
+		span.SetAttr(
+			tracing.String("db.statement", tx.Statement.SQL.String()),
+			tracing.String("db.sql.table", tx.Statement.Table),
+			tracing.Int("db.rows_affected", tx.Statement.RowsAffected),
+		)
+		err := tx.Statement.Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil // Ausência de registro não é falha do banco
+		}
+		span.Finish(err)
+	}
+
+	// Os spans envolvem os demais callbacks, inclusive o timeout das consultas
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("*").Register("photo_manager:trace_start", start("insert")),
+		callbacks.Create().After("*").Register("photo_manager:trace_end", end),
+		callbacks.Query().Before("*").Register("photo_manager:trace_start", start("select")),
+		callbacks.Query().After("*").Register("photo_manager:trace_end", end),
+		callbacks.Update().Before("*").Register("photo_manager:trace_start", start("update")),
+		callbacks.Update().After("*").Register("photo_manager:trace_end", end),
+		callbacks.Delete().Before("*").Register("photo_manager:trace_start", start("delete")),
+		callbacks.Delete().After("*").Register("photo_manager:trace_end", end),
+		callbacks.Raw().Before("*").Register("photo_manager:trace_start", start("raw")),
+		callbacks.Raw().After("*").Register("photo_manager:trace_end", end),
+		callbacks.Row().Before("*").Register("photo_manager:trace_start", start("row")),
+		callbacks.Row().After("*").Register("photo_manager:trace_end", end),
+	)
+}
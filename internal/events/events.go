@@ -0,0 +1,90 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Tipos de evento emitidos pela aplicação.
+const (
+	TypeStorageLowSpace     = "storage.low_space"     // O espaço livre de um volume caiu abaixo do limite de aviso
+	TypePhotoCreated        = "photo.created"         // Uma nova foto foi adicionada à biblioteca
+	TypePhotoUpdated        = "photo.updated"         // Os metadados de uma foto foram alterados
+	TypePhotoDeleted        = "photo.deleted"         // Uma foto foi removida da biblioteca
+	TypePhotoQuarantined    = "photo.quarantined"     // Uma foto foi isolada após falhar repetidamente no processamento
+	TypeAlbumChanged        = "album.changed"         // Um álbum foi criado, alterado ou teve suas fotos modificadas
+	TypeTagsChanged         = "tags.changed"          // As tags de uma ou mais fotos foram alteradas
+	TypeImportFinished      = "import.finished"       // Um job de importação terminou (com sucesso ou falha)
+	TypeIntegrityFailure    = "integrity.failure"     // Um arquivo não confere com o esperado (checksum divergente ou arquivo ausente)
+	TypeAlbumQuotaExceeded  = "album.quota_exceeded"  // Um álbum ultrapassou sua cota flexível
+	TypeAlbumPolicyExecuted = "album.policy_executed" // Uma política de ciclo de vida de álbum foi executada
+	TypeMirrorSynced        = "mirror.synced"         // Uma origem espelhada foi sincronizada com novidades ou falhas
+	TypeExportFinished      = "export.finished"       // Uma exportação completa da biblioteca terminou (com sucesso ou falha)
+	TypeDownloadReady       = "download.ready"        // O ZIP preparado de uma seleção de fotos ficou pronto (ou falhou)
+)
+
+// Event representa algo relevante que aconteceu na aplicação.
+type Event struct {
+	Type string
+	Time time.Time
+	Data map[string]interface{}
+}
+
+// Handler processa eventos publicados no barramento.
+type Handler func(Event)
+
+// Bus é um barramento de eventos simples, em memória e síncrono.
+// Também mantém contadores por tipo de evento, expostos como métricas.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+	counts   map[string]int64
+}
+
+// NewBus cria um novo barramento de eventos.
+func NewBus() *Bus {
+	return &Bus{
+		counts: make(map[string]int64),
+	}
+}
+
+// Subscribe registra um handler que receberá todos os eventos publicados.
+func (b *Bus) Subscribe(h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, h)
+}
+
+// Publish envia um evento para todos os handlers registrados.
+// É seguro chamar Publish em um Bus nil (o evento é descartado).
+func (b *Bus) Publish(eventType string, data map[string]interface{}) {
+	if b == nil {
+		return
+	}
+
+	event := Event{Type: eventType, Time: time.Now(), Data: data}
+
+	b.mu.Lock()
+	b.counts[eventType]++
+	handlers := append([]Handler(nil), b.handlers...)
+	b.mu.Unlock()
+
+	for _, h := range handlers {
+		h(event)
+	}
+}
+
+// Counts retorna quantos eventos de cada tipo foram publicados desde a inicialização.
+func (b *Bus) Counts() map[string]int64 {
+	counts := make(map[string]int64)
+	if b == nil {
+		return counts
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for k, v := range b.counts {
+		counts[k] = v
+	}
+	return counts
+}
@@ -0,0 +1,91 @@
+package exif
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// AuxiliaryAssets indica as imagens auxiliares embutidas pela câmera junto da foto principal: mapas de
+// profundidade do modo retrato, mapas de ganho do HDR e máscaras de recorte (matte).
+type AuxiliaryAssets struct {
+	DepthMap      bool // Mapa de profundidade (Dynamic Depth/GDepth do Android, depthData/auxid:2 da Apple)
+	GainMap       bool // Mapa de ganho HDR (Ultra HDR, ISO 21496-1, HDR gain map da Apple)
+	PortraitMatte bool // Máscara de recorte do modo retrato (Apple)
+}
+
+// Any indica se a foto tem alguma imagem auxiliar.
+func (a AuxiliaryAssets) Any() bool {
+	return a.DepthMap || a.GainMap || a.PortraitMatte
+}
+
+// Assinaturas das imagens auxiliares. No JPEG elas ficam no XMP da foto principal ou das imagens
+// secundárias (MPF, no fim do arquivo); no HEIC, nas caixas auxC dos itens auxiliares.
+var (
+	depthMapSignatures = [][]byte{
+		[]byte("GDepth:Data"),
+		[]byte(`Item:Semantic="Depth"`),
+		[]byte("http://ns.apple.com/depthData/"),
+		[]byte("urn:mpeg:hevc:2015:auxid:2"),
+		[]byte("urn:mpeg:mpegB:cicp:systems:auxiliary:depth"),
+	}
+	gainMapSignatures = [][]byte{
+		[]byte("hdrgm:Version"),
+		[]byte(`Item:Semantic="GainMap"`),
+		[]byte("urn:iso:std:iso:ts:21496:-1"),
+		[]byte("urn:com:apple:photo:2020:aux:hdrgainmap"),
+	}
+	portraitMatteSignatures = [][]byte{
+		[]byte("urn:com:apple:photo:2018:aux:portraiteffectsmatte"),
+	}
+)
+
+// auxiliaryScanChunk é o tamanho de cada leitura do arquivo na procura das assinaturas.
+const auxiliaryScanChunk = 1 << 20
+
+// ExtractAuxiliaryAssets procura no arquivo as assinaturas das imagens auxiliares. O arquivo é lido
+// inteiro, em blocos: as imagens secundárias de um JPEG ficam depois da imagem principal.
+func ExtractAuxiliaryAssets(filePath string) (AuxiliaryAssets, error) {
+	var assets AuxiliaryAssets
+	f, err := os.Open(filePath)
+	if err != nil {
+		return assets, fmt.Errorf("não foi possível abrir o arquivo para procurar imagens auxiliares: %w", err)
+	}
+	defer f.Close()
+
+	// Cada bloco é procurado junto com o fim do anterior, para achar assinaturas divididas entre blocos
+	overlap := 0
+	for _, signatures := range [][][]byte{depthMapSignatures, gainMapSignatures, portraitMatteSignatures} {
+		for _, signature := range signatures {
+			overlap = max(overlap, len(signature)-1)
+		}
+	}
+	buf := make([]byte, overlap+auxiliaryScanChunk)
+	kept := 0
+	for {
+		n, err := io.ReadFull(f, buf[kept:])
+		window := buf[:kept+n]
+		assets.DepthMap = assets.DepthMap || containsAny(window, depthMapSignatures)
+		assets.GainMap = assets.GainMap || containsAny(window, gainMapSignatures)
+		assets.PortraitMatte = assets.PortraitMatte || containsAny(window, portraitMatteSignatures)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return assets, nil
+		}
+		if err != nil {
+			return assets, fmt.Errorf("não foi possível ler o arquivo para procurar imagens auxiliares: %w", err)
+		}
+		kept = min(overlap, len(window))
+		copy(buf, window[len(window)-kept:])
+	}
+}
+
+// containsAny indica se data contém alguma das assinaturas.
+func containsAny(data []byte, signatures [][]byte) bool {
+	for _, signature := range signatures {
+		if bytes.Contains(data, signature) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,317 @@
+package exif
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/mknote"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+func init() {
+	// Inclui nos campos os MakerNotes conhecidos (Canon, Nikon)
+	exif.RegisterParsers(mknote.All...)
+}
+
+// maxDumpValues limita a quantidade de valores exibidos de um campo com muitos elementos (ex: tabelas de
+// calibração dos MakerNotes).
+const maxDumpValues = 64
+
+// maxDumpText limita o tamanho de um campo de texto ou binário exibido por inteiro.
+const maxDumpText = 1024
+
+// Dump são todos os metadados EXIF e IPTC de um arquivo, com os valores já convertidos para exibição.
+type Dump struct {
+	Exif map[string]interface{} `json:"exif"`
+	IPTC map[string]interface{} `json:"iptc"`
+}
+
+// DumpMetadata lê todos os campos EXIF (incluindo GPS, interoperabilidade e MakerNotes conhecidos) e IPTC
+// (registro IIM do segmento APP13 de arquivos JPEG) do arquivo. Arquivos sem metadados resultam em mapas
+// vazios, não em erro.
+func DumpMetadata(filePath string) (*Dump, error) {
+	dump := &Dump{Exif: map[string]interface{}{}, IPTC: map[string]interface{}{}}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível abrir o arquivo para leitura dos metadados: %w", err)
+	}
+	defer f.Close()
+
+	// Sem EXIF legível (ex: PNG, arquivo sem metadados) o mapa fica vazio. Com erro apenas em um diretório
+	// secundário (ex: MakerNote corrompido), os campos já lidos são mantidos.
+	if x, _ := exif.Decode(f); x != nil {
+		x.Walk(dumpWalker(dump.Exif))
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("não foi possível ler o arquivo: %w", err)
+	}
+	if err := readIPTC(bufio.NewReader(f), dump.IPTC); err != nil {
+		return nil, err
+	}
+	return dump, nil
+}
+
+// dumpWalker grava cada campo EXIF no mapa.
+type dumpWalker map[string]interface{}
+
+func (w dumpWalker) Walk(name exif.FieldName, tag *tiff.Tag) error {
+	w[string(name)] = tagValue(tag)
+	return nil
+}
+
+// tagValue converte o valor de um campo EXIF: textos sem terminadores, números (ou listas de números),
+// racionais como "n/d" e dados binários como texto quando legíveis ou, caso contrário, pelo tamanho.
+func tagValue(tag *tiff.Tag) interface{} {
+	count := int(tag.Count)
+	values := make([]interface{}, 0, min(count, maxDumpValues))
+	for i := 0; i < count && i < maxDumpValues; i++ {
+		switch tag.Format() {
+		case tiff.IntVal:
+			if v, err := tag.Int64(i); err == nil {
+				values = append(values, v)
+			}
+		case tiff.RatVal:
+			if num, den, err := tag.Rat2(i); err == nil {
+				values = append(values, fmt.Sprintf("%d/%d", num, den))
+			}
+		case tiff.FloatVal:
+			if v, err := tag.Float(i); err == nil {
+				values = append(values, v)
+			}
+		}
+	}
+
+	switch tag.Format() {
+	case tiff.StringVal:
+		value, _ := tag.StringVal()
+		return truncateText(strings.TrimSpace(strings.TrimRight(value, "\x00")))
+	case tiff.UndefVal, tiff.OtherVal:
+		return binaryValue(tag.Val)
+	}
+	if count > maxDumpValues {
+		values = append(values, fmt.Sprintf("... (%d valores)", count))
+	}
+	if len(values) == 1 {
+		return values[0]
+	}
+	return values
+}
+
+// binaryValue exibe dados binários como texto quando forem texto legível (ex: ExifVersion "0232",
+// UserComment), ou apenas pelo tamanho.
+func binaryValue(data []byte) interface{} {
+	text := strings.TrimRight(string(data), "\x00 ")
+	// UserComment começa com o código de caracteres (ex: "ASCII\x00\x00\x00")
+	if rest, ok := strings.CutPrefix(text, "ASCII\x00\x00\x00"); ok {
+		text = strings.TrimRight(rest, "\x00 ")
+	}
+	if len(text) <= maxDumpText && isPrintable(text) {
+		return text
+	}
+	return fmt.Sprintf("<%d bytes>", len(data))
+}
+
+// isPrintable indica se o texto é UTF-8 válido sem caracteres de controle (exceto quebras de linha).
+func isPrintable(text string) bool {
+	if !utf8.ValidString(text) {
+		return false
+	}
+	for _, r := range text {
+		if !unicode.IsPrint(r) && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// truncateText limita o tamanho de um texto exibido.
+func truncateText(text string) string {
+	if len(text) <= maxDumpText {
+		return text
+	}
+	cut := maxDumpText
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "..."
+}
+
+// iptcDatasets nomeia os campos IPTC IIM mais comuns (registro:dataset), conforme a especificação IPTC IIM 4.2.
+var iptcDatasets = map[[2]byte]string{
+	{1, 90}:  "CodedCharacterSet",
+	{2, 0}:   "RecordVersion",
+	{2, 5}:   "ObjectName",
+	{2, 7}:   "EditStatus",
+	{2, 10}:  "Urgency",
+	{2, 12}:  "SubjectReference",
+	{2, 15}:  "Category",
+	{2, 20}:  "SupplementalCategories",
+	{2, 25}:  "Keywords",
+	{2, 26}:  "ContentLocationCode",
+	{2, 27}:  "ContentLocationName",
+	{2, 40}:  "SpecialInstructions",
+	{2, 55}:  "DateCreated",
+	{2, 60}:  "TimeCreated",
+	{2, 62}:  "DigitalCreationDate",
+	{2, 63}:  "DigitalCreationTime",
+	{2, 65}:  "OriginatingProgram",
+	{2, 70}:  "ProgramVersion",
+	{2, 80}:  "By-line",
+	{2, 85}:  "By-lineTitle",
+	{2, 90}:  "City",
+	{2, 92}:  "Sub-location",
+	{2, 95}:  "Province-State",
+	{2, 100}: "Country-PrimaryLocationCode",
+	{2, 101}: "Country-PrimaryLocationName",
+	{2, 103}: "OriginalTransmissionReference",
+	{2, 105}: "Headline",
+	{2, 110}: "Credit",
+	{2, 115}: "Source",
+	{2, 116}: "CopyrightNotice",
+	{2, 118}: "Contact",
+	{2, 120}: "Caption-Abstract",
+	{2, 122}: "Writer-Editor",
+}
+
+// iptcRepeatable são os campos IPTC que podem aparecer várias vezes, exibidos como lista.
+var iptcRepeatable = map[string]bool{
+	"SubjectReference":       true,
+	"SupplementalCategories": true,
+	"Keywords":               true,
+	"ContentLocationCode":    true,
+	"ContentLocationName":    true,
+	"By-line":                true,
+	"By-lineTitle":           true,
+	"Contact":                true,
+	"Writer-Editor":          true,
+}
+
+// errNotJPEG indica que o arquivo não é JPEG; nesse caso não há IPTC a ler.
+var errNotJPEG = errors.New("não é JPEG")
+
+// readIPTC lê o registro IPTC IIM do recurso 0x0404 do bloco Photoshop (segmento APP13) de um JPEG.
+func readIPTC(r *bufio.Reader, fields map[string]interface{}) error {
+	data, err := findPhotoshopSegment(r)
+	if errors.Is(err, errNotJPEG) || data == nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("não foi possível ler o segmento IPTC: %w", err)
+	}
+
+	iim := photoshopResource(data, 0x0404)
+	for len(iim) >= 5 && iim[0] == 0x1C {
+		record, dataset := iim[1], iim[2]
+		size := int(binary.BigEndian.Uint16(iim[3:5]))
+		iim = iim[5:]
+		if size&0x8000 != 0 || size > len(iim) {
+			break // Campos estendidos (acima de 32 KB) não são usados pelos metadados descritivos
+		}
+		value := iim[:size]
+		iim = iim[size:]
+
+		name, ok := iptcDatasets[[2]byte{record, dataset}]
+		if !ok {
+			name = fmt.Sprintf("%d:%03d", record, dataset)
+		}
+		var parsed interface{} = binaryValue(value)
+		if size == 2 && (name == "RecordVersion" || name == "1:000") {
+			parsed = int(binary.BigEndian.Uint16(value))
+		}
+		if iptcRepeatable[name] {
+			list, _ := fields[name].([]interface{})
+			fields[name] = append(list, parsed)
+		} else {
+			fields[name] = parsed
+		}
+	}
+	return nil
+}
+
+// findPhotoshopSegment percorre os segmentos do JPEG até o início da imagem (SOS) e retorna o conteúdo do
+// segmento APP13 "Photoshop 3.0", ou nil se não houver.
+func findPhotoshopSegment(r *bufio.Reader) ([]byte, error) {
+	var soi [2]byte
+	if _, err := io.ReadFull(r, soi[:]); err != nil || soi != [2]byte{0xFF, 0xD8} {
+		return nil, errNotJPEG
+	}
+	for {
+		marker, err := r.ReadByte()
+		if err != nil {
+			return nil, nil
+		}
+		if marker != 0xFF {
+			continue
+		}
+		kind, err := r.ReadByte()
+		if err != nil {
+			return nil, nil
+		}
+		switch {
+		case kind == 0xFF || kind == 0x00:
+			r.UnreadByte()
+			continue
+		case kind == 0xD9 || kind == 0xDA: // Fim da imagem ou início dos dados da imagem
+			return nil, nil
+		case kind >= 0xD0 && kind <= 0xD7:
+			continue // Marcadores sem tamanho
+		}
+		var sizeBytes [2]byte
+		if _, err := io.ReadFull(r, sizeBytes[:]); err != nil {
+			return nil, nil
+		}
+		size := int(binary.BigEndian.Uint16(sizeBytes[:])) - 2
+		if size < 0 {
+			return nil, nil
+		}
+		segment := make([]byte, size)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			return nil, err
+		}
+		if kind == 0xED {
+			if data, ok := bytes.CutPrefix(segment, []byte("Photoshop 3.0\x00")); ok {
+				return data, nil
+			}
+		}
+	}
+}
+
+// photoshopResource retorna os dados do recurso (bloco 8BIM) com o identificador informado, ou nil.
+func photoshopResource(data []byte, id uint16) []byte {
+	for len(data) >= 12 && bytes.Equal(data[:4], []byte("8BIM")) {
+		resourceID := binary.BigEndian.Uint16(data[4:6])
+		// Nome em Pascal string, com tamanho total (incluindo o byte do tamanho) par
+		nameSize := int(data[6]) + 1
+		if nameSize%2 != 0 {
+			nameSize++
+		}
+		offset := 6 + nameSize
+		if offset+4 > len(data) {
+			return nil
+		}
+		size := int(binary.BigEndian.Uint32(data[offset : offset+4]))
+		offset += 4
+		if size < 0 || offset+size > len(data) {
+			return nil
+		}
+		if resourceID == id {
+			return data[offset : offset+size]
+		}
+		if size%2 != 0 {
+			size++
+		}
+		data = data[min(offset+size, len(data)):]
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/rwcarlsen/goexif/exif"
@@ -11,10 +12,27 @@ import (
 
 // ExifData contém os metadados EXIF relevantes para a foto.
 type ExifData struct {
-	DateTime *time.Time // Data e hora da criação da foto
-	// Outros campos EXIF podem ser adicionados aqui conforme necessidade (e.g., Make, Model, GPS)
+	DateTime  *time.Time // Data e hora da criação da foto
+	Latitude  *float64   // Latitude GPS em graus decimais (nil se a foto não tiver localização)
+	Longitude *float64   // Longitude GPS em graus decimais
+
+	// Fuso da captura, em minutos a leste de UTC (nil se desconhecido). Quando conhecido, DateTime é o instante
+	// correto da captura; senão, a hora da câmera é interpretada no fuso do servidor.
+	TimeZoneOffset *int
+
+	// Câmera e parâmetros de captura (vazios ou nil quando ausentes)
+	CameraMake  string   // Fabricante da câmera (ex: Canon)
+	CameraModel string   // Modelo da câmera (ex: Canon EOS R6)
+	LensModel   string   // Modelo da lente
+	FocalLength *float64 // Distância focal, em mm
+	ISO         *int     // Sensibilidade ISO
+
+	Thumbnail []byte // Miniatura JPEG embutida pela câmera (nil se ausente)
 }
 
+// maxThumbnailSize é o tamanho máximo de uma miniatura embutida; o padrão EXIF limita o segmento a 64 KB.
+const maxThumbnailSize = 64 << 10
+
 // ExtractExifData extrai metadados EXIF de um arquivo de imagem.
 func ExtractExifData(filePath string) (*ExifData, error) {
 	f, err := os.Open(filePath)
@@ -30,6 +48,11 @@ func ExtractExifData(filePath string) (*ExifData, error) {
 		if err == io.EOF || err.Error() == "no exif data" { // goexif retorna io.EOF para arquivos sem EXIF
 			return nil, nil
 		}
+		// O goexif só lê o primeiro segmento APP1: em arquivos com apenas XMP (comuns em panoramas montados
+		// e fotos exportadas por editores), o segmento não é EXIF e a foto simplesmente não tem EXIF.
+		if strings.Contains(err.Error(), "failed to find exif intro marker") {
+			return nil, nil
+		}
 		return nil, fmt.Errorf("não foi possível decodificar dados EXIF: %w", err)
 	}
 
@@ -37,24 +60,61 @@ func ExtractExifData(filePath string) (*ExifData, error) {
 	// Tenta extrair a data e hora de criação.
 	tm, err := x.DateTime()
 	if err == nil {
+		wall := time.Date(tm.Year(), tm.Month(), tm.Day(), tm.Hour(), tm.Minute(), tm.Second(), tm.Nanosecond(), time.UTC)
+		if offset, ok := captureOffset(x, wall); ok {
+			// O instante correto, no fuso do servidor como as demais datas gravadas
+			tm = wall.Add(-time.Duration(offset) * time.Minute).In(time.Local)
+			exifData.TimeZoneOffset = &offset
+		}
 		exifData.DateTime = &tm
 	} else {
 		// Logar o erro se a data não puder ser extraída, mas não falhar
 		fmt.Printf("Aviso: Não foi possível extrair DateTime EXIF: %v\n", err)
 	}
 
-	// Adicione aqui a extração de outros campos EXIF se necessário
-	// Exemplo:
-	// camModel, err := x.Get(exif.Model)
-	// if err == nil {
-	// 	if modelStr, err := camModel.String(); err == nil {
-	// 		fmt.Println("Modelo da Câmera:", modelStr)
-	// 	}
-	// }
+	// Localização GPS, quando presente
+	if lat, long, err := x.LatLong(); err == nil {
+		exifData.Latitude, exifData.Longitude = &lat, &long
+	}
 
-	if exifData.DateTime == nil {
+	// Câmera, lente e parâmetros de captura
+	exifData.CameraMake = stringField(x, exif.Make)
+	exifData.CameraModel = stringField(x, exif.Model)
+	exifData.LensModel = stringField(x, exif.LensModel)
+	if tag, err := x.Get(exif.FocalLength); err == nil {
+		if num, den, err := tag.Rat2(0); err == nil && den != 0 && num > 0 {
+			focal := float64(num) / float64(den)
+			exifData.FocalLength = &focal
+		}
+	}
+	if tag, err := x.Get(exif.ISOSpeedRatings); err == nil {
+		if iso, err := tag.Int(0); err == nil && iso > 0 {
+			exifData.ISO = &iso
+		}
+	}
+
+	// Miniatura embutida (IFD1): usada como prévia até a miniatura completa ser gerada
+	if thumb, err := x.JpegThumbnail(); err == nil && len(thumb) > 0 && len(thumb) <= maxThumbnailSize {
+		exifData.Thumbnail = thumb
+	}
+
+	if exifData.DateTime == nil && exifData.Latitude == nil && exifData.CameraMake == "" && exifData.CameraModel == "" &&
+		exifData.LensModel == "" && exifData.FocalLength == nil && exifData.ISO == nil && exifData.Thumbnail == nil {
 		return nil, nil // Não há dados EXIF relevantes para retornar
 	}
 
 	return &exifData, nil
 }
+
+// stringField retorna o valor de um campo EXIF de texto, sem espaços e terminadores nulos, ou "" se ausente.
+func stringField(x *exif.Exif, name exif.FieldName) string {
+	tag, err := x.Get(name)
+	if err != nil {
+		return ""
+	}
+	value, err := tag.StringVal()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(value, "\x00"))
+}
@@ -0,0 +1,403 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Grupos de campos sensíveis verificados pela auditoria de privacidade.
+const (
+	PrivacyGPS    = "gps"    // Localização (IFD de GPS inteiro)
+	PrivacySerial = "serial" // Números de série do corpo da câmera e da lente
+	PrivacyOwner  = "owner"  // Nome do proprietário da câmera e autor (Artist)
+)
+
+// PrivacyFields são os grupos aceitos, na ordem em que são relatados.
+var PrivacyFields = []string{PrivacyGPS, PrivacySerial, PrivacyOwner}
+
+// Tags EXIF/TIFF sensíveis.
+const (
+	tagArtist              = 0x013B
+	tagExifIFD             = 0x8769
+	tagGPSIFD              = 0x8825
+	tagCameraOwnerName     = 0xA430
+	tagBodySerialNumber    = 0xA431
+	tagLensSerialNumber    = 0xA435
+	tagCameraSerialNumber  = 0xC62F // DNG
+	tagGPSLatitude         = 0x0002
+	tagGPSLongitude        = 0x0004
+	exifHeader             = "Exif\x00\x00"
+	jpegMarkerSOS          = 0xDA
+	jpegMarkerAPP1         = 0xE1
+	tiffEntrySize          = 12
+	maxPrivacyStringLength = 256
+)
+
+// privacyTags associa cada grupo às tags do IFD0 e do IFD EXIF que o compõem (o GPS é tratado à parte).
+var privacyTags = map[string][]uint16{
+	PrivacySerial: {tagBodySerialNumber, tagLensSerialNumber, tagCameraSerialNumber},
+	PrivacyOwner:  {tagCameraOwnerName, tagArtist},
+}
+
+// ErrNoExif indica que o arquivo não é um JPEG com segmento EXIF.
+var ErrNoExif = errors.New("o arquivo não contém EXIF em JPEG")
+
+// PrivacyReport lista os campos sensíveis encontrados no EXIF de uma foto.
+type PrivacyReport struct {
+	Fields          []string // Grupos encontrados (ver constantes Privacy*)
+	HasGPS          bool     // Há coordenadas GPS
+	BodySerial      string   // Número de série do corpo da câmera
+	LensSerial      string   // Número de série da lente
+	CameraOwnerName string   // Nome do proprietário da câmera
+	Artist          string   // Autor
+}
+
+// tiffEntry é uma entrada de um IFD.
+type tiffEntry struct {
+	pos   int // Posição da entrada no bloco TIFF
+	tag   uint16
+	typ   uint16
+	count uint32
+	value uint32 // Valor (se couber em 4 bytes) ou deslocamento dos dados
+}
+
+// tiffBlock é o bloco TIFF do segmento APP1 de um JPEG.
+type tiffBlock struct {
+	data  []byte // Fatia do arquivo: alterações são gravadas diretamente no conteúdo do arquivo
+	order binary.ByteOrder
+}
+
+// AuditPrivacy verifica se o EXIF da foto contém localização GPS, números de série ou nome do proprietário.
+// Arquivos sem EXIF (ou que não são JPEG) retornam um relatório vazio.
+func AuditPrivacy(filePath string) (*PrivacyReport, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível ler o arquivo: %w", err)
+	}
+	report := &PrivacyReport{}
+	tiff, err := findTIFF(content)
+	if errors.Is(err, ErrNoExif) {
+		return report, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ifds, err := tiff.privacyIFDs()
+	if err != nil {
+		return nil, err
+	}
+	for _, ifd := range ifds[:2] {
+		for _, entry := range ifd {
+			switch entry.tag {
+			case tagBodySerialNumber, tagCameraSerialNumber:
+				report.BodySerial = tiff.stringValue(entry)
+			case tagLensSerialNumber:
+				report.LensSerial = tiff.stringValue(entry)
+			case tagCameraOwnerName:
+				report.CameraOwnerName = tiff.stringValue(entry)
+			case tagArtist:
+				report.Artist = tiff.stringValue(entry)
+			}
+		}
+	}
+	for _, entry := range ifds[2] {
+		if entry.tag == tagGPSLatitude || entry.tag == tagGPSLongitude {
+			report.HasGPS = true
+		}
+	}
+
+	if report.HasGPS {
+		report.Fields = append(report.Fields, PrivacyGPS)
+	}
+	if report.BodySerial != "" || report.LensSerial != "" {
+		report.Fields = append(report.Fields, PrivacySerial)
+	}
+	if report.CameraOwnerName != "" || report.Artist != "" {
+		report.Fields = append(report.Fields, PrivacyOwner)
+	}
+	return report, nil
+}
+
+// StripPrivacyFields remove do EXIF os grupos de campos informados, regravando o arquivo. As entradas são
+// retiradas dos IFDs e os dados que elas apontavam são zerados, de modo que os valores não permaneçam no
+// arquivo; o restante do EXIF e a imagem não são alterados. Retorna os grupos efetivamente removidos.
+// A gravação é atômica (arquivo temporário e rename).
+func StripPrivacyFields(filePath string, fields []string) ([]string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível ler o arquivo: %w", err)
+	}
+	tiff, err := findTIFF(content)
+	if errors.Is(err, ErrNoExif) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var stripped []string
+	for _, field := range fields {
+		var removed bool
+		if field == PrivacyGPS {
+			removed, err = tiff.stripGPS()
+		} else {
+			removed, err = tiff.stripTags(privacyTags[field])
+		}
+		if err != nil {
+			return nil, err
+		}
+		if removed {
+			stripped = append(stripped, field)
+		}
+	}
+	if len(stripped) == 0 {
+		return nil, nil
+	}
+
+	if err := writeFileAtomic(filePath, content); err != nil {
+		return nil, err
+	}
+	return stripped, nil
+}
+
+// ValidPrivacyField indica se o grupo de campos é conhecido.
+func ValidPrivacyField(field string) bool {
+	return field == PrivacyGPS || privacyTags[field] != nil
+}
+
+// findTIFF localiza o bloco TIFF do segmento APP1 EXIF de um JPEG.
+func findTIFF(content []byte) (*tiffBlock, error) {
+	if len(content) < 4 || content[0] != 0xFF || content[1] != 0xD8 {
+		return nil, ErrNoExif
+	}
+	pos := 2
+	for pos+4 <= len(content) {
+		if content[pos] != 0xFF {
+			return nil, ErrNoExif
+		}
+		marker := content[pos+1]
+		if marker == 0xFF {
+			pos++ // Preenchimento entre segmentos
+			continue
+		}
+		if marker == jpegMarkerSOS {
+			return nil, ErrNoExif
+		}
+		length := int(binary.BigEndian.Uint16(content[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(content) {
+			return nil, fmt.Errorf("segmento JPEG truncado")
+		}
+		segment := content[pos+4 : end]
+		if marker == jpegMarkerAPP1 && bytes.HasPrefix(segment, []byte(exifHeader)) {
+			data := segment[len(exifHeader):]
+			if len(data) < 8 {
+				return nil, fmt.Errorf("cabeçalho TIFF truncado")
+			}
+			switch string(data[:2]) {
+			case "II":
+				return &tiffBlock{data: data, order: binary.LittleEndian}, nil
+			case "MM":
+				return &tiffBlock{data: data, order: binary.BigEndian}, nil
+			default:
+				return nil, fmt.Errorf("ordem de bytes TIFF inválida")
+			}
+		}
+		pos = end
+	}
+	return nil, ErrNoExif
+}
+
+// privacyIFDs retorna as entradas do IFD0, do IFD EXIF e do IFD de GPS (vazias se ausentes).
+func (t *tiffBlock) privacyIFDs() ([3][]tiffEntry, error) {
+	var ifds [3][]tiffEntry
+	ifd0, err := t.entries(int(t.order.Uint32(t.data[4:])))
+	if err != nil {
+		return ifds, err
+	}
+	ifds[0] = ifd0
+	for _, entry := range ifd0 {
+		switch entry.tag {
+		case tagExifIFD:
+			if ifds[1], err = t.entries(int(entry.value)); err != nil {
+				return ifds, err
+			}
+		case tagGPSIFD:
+			if ifds[2], err = t.entries(int(entry.value)); err != nil {
+				return ifds, err
+			}
+		}
+	}
+	return ifds, nil
+}
+
+// entries lê as entradas do IFD no deslocamento informado.
+func (t *tiffBlock) entries(offset int) ([]tiffEntry, error) {
+	if offset < 8 || offset+2 > len(t.data) {
+		return nil, fmt.Errorf("deslocamento de IFD inválido: %d", offset)
+	}
+	count := int(t.order.Uint16(t.data[offset:]))
+	if offset+2+count*tiffEntrySize+4 > len(t.data) {
+		return nil, fmt.Errorf("IFD truncado no deslocamento %d", offset)
+	}
+	entries := make([]tiffEntry, count)
+	for i := range entries {
+		pos := offset + 2 + i*tiffEntrySize
+		entries[i] = tiffEntry{
+			pos:   pos,
+			tag:   t.order.Uint16(t.data[pos:]),
+			typ:   t.order.Uint16(t.data[pos+2:]),
+			count: t.order.Uint32(t.data[pos+4:]),
+			value: t.order.Uint32(t.data[pos+8:]),
+		}
+	}
+	return entries, nil
+}
+
+// typeSizes é o tamanho, em bytes, de cada tipo de valor TIFF.
+var typeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+// dataRange retorna a posição e o tamanho dos dados da entrada fora do IFD (tamanho 0 se couberem na entrada).
+func (t *tiffBlock) dataRange(entry tiffEntry) (int, int) {
+	size := typeSizes[entry.typ] * int(entry.count)
+	if size <= 4 || int(entry.value)+size > len(t.data) {
+		return 0, 0
+	}
+	return int(entry.value), size
+}
+
+// stringValue lê o texto de uma entrada ASCII.
+func (t *tiffBlock) stringValue(entry tiffEntry) string {
+	var raw []byte
+	if start, size := t.dataRange(entry); size > 0 {
+		raw = t.data[start : start+size]
+	} else {
+		raw = t.data[entry.pos+8 : entry.pos+8+min(int(entry.count), 4)]
+	}
+	value := strings.TrimSpace(string(bytes.TrimRight(raw, "\x00")))
+	if len(value) > maxPrivacyStringLength {
+		value = value[:maxPrivacyStringLength]
+	}
+	return value
+}
+
+// removeEntry retira a entrada do IFD (deslocando as seguintes) e zera os dados que ela apontava.
+func (t *tiffBlock) removeEntry(ifdOffset int, entry tiffEntry) {
+	if start, size := t.dataRange(entry); size > 0 {
+		clear(t.data[start : start+size])
+	}
+	count := int(t.order.Uint16(t.data[ifdOffset:]))
+	end := ifdOffset + 2 + count*tiffEntrySize + 4 // Inclui o deslocamento do próximo IFD
+	copy(t.data[entry.pos:], t.data[entry.pos+tiffEntrySize:end])
+	clear(t.data[end-tiffEntrySize : end])
+	t.order.PutUint16(t.data[ifdOffset:], uint16(count-1))
+}
+
+// stripTags remove as tags informadas do IFD0 e do IFD EXIF.
+func (t *tiffBlock) stripTags(tags []uint16) (bool, error) {
+	removed := false
+	ifd0Offset := int(t.order.Uint32(t.data[4:]))
+	offsets := []int{ifd0Offset}
+	ifd0, err := t.entries(ifd0Offset)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range ifd0 {
+		if entry.tag == tagExifIFD {
+			offsets = append(offsets, int(entry.value))
+		}
+	}
+
+	for _, offset := range offsets {
+		// Relê o IFD após cada remoção, pois as posições das entradas seguintes mudam
+		for {
+			entries, err := t.entries(offset)
+			if err != nil {
+				return removed, err
+			}
+			found := false
+			for _, entry := range entries {
+				if containsTag(tags, entry.tag) {
+					t.removeEntry(offset, entry)
+					removed, found = true, true
+					break
+				}
+			}
+			if !found {
+				break
+			}
+		}
+	}
+	return removed, nil
+}
+
+// stripGPS remove o ponteiro para o IFD de GPS e zera o IFD e todos os seus dados.
+func (t *tiffBlock) stripGPS() (bool, error) {
+	ifd0Offset := int(t.order.Uint32(t.data[4:]))
+	ifd0, err := t.entries(ifd0Offset)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range ifd0 {
+		if entry.tag != tagGPSIFD {
+			continue
+		}
+		gpsOffset := int(entry.value)
+		if gps, err := t.entries(gpsOffset); err == nil {
+			for _, gpsEntry := range gps {
+				if start, size := t.dataRange(gpsEntry); size > 0 {
+					clear(t.data[start : start+size])
+				}
+			}
+			clear(t.data[gpsOffset : gpsOffset+2+len(gps)*tiffEntrySize+4])
+		}
+		t.removeEntry(ifd0Offset, entry)
+		return true, nil
+	}
+	return false, nil
+}
+
+func containsTag(tags []uint16, tag uint16) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFileAtomic grava o conteúdo num arquivo temporário no mesmo diretório e o renomeia sobre o destino,
+// preservando as permissões do arquivo original.
+func writeFileAtomic(filePath string, content []byte) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".privacy-*")
+	if err != nil {
+		return fmt.Errorf("não foi possível criar arquivo temporário: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // Sem efeito após o rename
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("não foi possível gravar o arquivo: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("não foi possível gravar o arquivo: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("não foi possível gravar o arquivo: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
@@ -0,0 +1,301 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Tipos de região do esquema MWG. Picasa, digiKam e Lightroom gravam os rostos com nome como Face.
+const (
+	RegionTypeFace = "Face"
+	RegionTypePet  = "Pet"
+)
+
+// Namespaces dos esquemas de regiões: o do Metadata Working Group (MWG), usado por digiKam, Lightroom e
+// Picasa, e o do Microsoft Photo (Windows Live Photo Gallery), que só descreve pessoas.
+const (
+	nsMWGRegions = "http://www.metadataworkinggroup.com/schemas/regions/"
+	nsArea       = "http://ns.adobe.com/xmp/sType/Area#"
+	nsDimensions = "http://ns.adobe.com/xap/1.0/sType/Dimensions#"
+	nsMPRegions  = "http://ns.microsoft.com/photo/1.2/t/RegionInfo#"
+	nsMPRegion   = "http://ns.microsoft.com/photo/1.2/t/Region#"
+	nsRDF        = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+)
+
+// Region é uma região nomeada da imagem (ex: o rosto de uma pessoa). As coordenadas são frações da largura e
+// da altura da imagem (0 a 1), a partir do canto superior esquerdo da região.
+type Region struct {
+	Name   string
+	Type   string // Ver constantes RegionType* (vazio se não informado)
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+// ReadRegions retorna as regiões nomeadas da foto, lidas do arquivo XMP lateral (foto.jpg.xmp ou foto.xmp,
+// gravados por digiKam e Lightroom) ou, sem ele, do XMP embutido no arquivo.
+func ReadRegions(filePath string) ([]Region, error) {
+	for _, sidecar := range []string{filePath + ".xmp", strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".xmp"} {
+		data, err := os.ReadFile(sidecar)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("não foi possível ler o XMP lateral '%s': %w", sidecar, err)
+		}
+		return ParseRegions(data)
+	}
+
+	xmp, err := ReadXMP(filePath)
+	if err != nil || xmp == nil {
+		return nil, err
+	}
+	return ParseRegions(xmp)
+}
+
+// ParseRegions lê as regiões nomeadas de um pacote XMP, no esquema MWG (mwg-rs:Regions) ou, se ele não existir,
+// no do Microsoft Photo (MP:RegionInfo). Regiões sem nome (ex: áreas de foco) são ignoradas.
+func ParseRegions(xmp []byte) ([]Region, error) {
+	root, err := parseXMPTree(xmp)
+	if err != nil {
+		return nil, err
+	}
+
+	var regions []Region
+	for _, list := range root.findAll(nsMWGRegions, "RegionList") {
+		for _, item := range list.findAll(nsRDF, "li") {
+			if region, ok := mwgRegion(item.description()); ok {
+				regions = append(regions, region)
+			}
+		}
+	}
+	if len(regions) > 0 {
+		return regions, nil
+	}
+
+	for _, list := range root.findAll(nsMPRegions, "Regions") {
+		for _, item := range list.findAll(nsRDF, "li") {
+			if region, ok := mpRegion(item.description()); ok {
+				regions = append(regions, region)
+			}
+		}
+	}
+	return regions, nil
+}
+
+// mwgRegion lê uma região MWG. A área é dada pelo centro (stArea:x, stArea:y) e pelo tamanho, normalizados.
+func mwgRegion(node *xmpNode) (Region, bool) {
+	name := strings.TrimSpace(node.property(nsMWGRegions, "Name"))
+	area := node.child(nsMWGRegions, "Area")
+	if name == "" || area == nil {
+		return Region{}, false
+	}
+	area = area.description()
+	if unit := area.property(nsArea, "unit"); unit != "" && unit != "normalized" {
+		return Region{}, false
+	}
+	x, errX := strconv.ParseFloat(area.property(nsArea, "x"), 64)
+	y, errY := strconv.ParseFloat(area.property(nsArea, "y"), 64)
+	w, errW := strconv.ParseFloat(area.property(nsArea, "w"), 64)
+	h, errH := strconv.ParseFloat(area.property(nsArea, "h"), 64)
+	if errX != nil || errY != nil || errW != nil || errH != nil {
+		return Region{}, false
+	}
+	return clampRegion(Region{
+		Name:   name,
+		Type:   strings.TrimSpace(node.property(nsMWGRegions, "Type")),
+		X:      x - w/2,
+		Y:      y - h/2,
+		Width:  w,
+		Height: h,
+	})
+}
+
+// mpRegion lê uma região do Microsoft Photo: MPReg:Rectangle é "x, y, largura, altura", normalizados, a partir
+// do canto superior esquerdo. Todas as regiões do esquema são pessoas.
+func mpRegion(node *xmpNode) (Region, bool) {
+	name := strings.TrimSpace(node.property(nsMPRegion, "PersonDisplayName"))
+	fields := strings.Split(node.property(nsMPRegion, "Rectangle"), ",")
+	if name == "" || len(fields) != 4 {
+		return Region{}, false
+	}
+	var values [4]float64
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return Region{}, false
+		}
+		values[i] = value
+	}
+	return clampRegion(Region{Name: name, Type: RegionTypeFace, X: values[0], Y: values[1], Width: values[2], Height: values[3]})
+}
+
+// clampRegion limita a região à imagem (rostos na borda podem ultrapassá-la um pouco); regiões vazias ou
+// inteiramente fora da imagem são descartadas.
+func clampRegion(r Region) (Region, bool) {
+	left, top := math.Max(r.X, 0), math.Max(r.Y, 0)
+	right, bottom := math.Min(r.X+r.Width, 1), math.Min(r.Y+r.Height, 1)
+	if math.IsNaN(left+top+right+bottom) || right <= left || bottom <= top {
+		return Region{}, false
+	}
+	r.X, r.Y, r.Width, r.Height = left, top, right-left, bottom-top
+	return r, true
+}
+
+// EncodeRegionsXMP gera um arquivo XMP lateral com as regiões, nos esquemas MWG e Microsoft Photo (este apenas
+// com os rostos), para que os nomes atribuídos aqui sejam lidos por digiKam, Lightroom e afins. width e height
+// são as dimensões da imagem (AppliedToDimensions), omitidas se desconhecidas.
+func EncodeRegionsXMP(regions []Region, width, height int) []byte {
+	var b bytes.Buffer
+	b.WriteString("<?xpacket begin=\"\ufeff\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	b.WriteString("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">\n")
+	b.WriteString(" <rdf:RDF xmlns:rdf=\"" + nsRDF + "\">\n")
+	b.WriteString("  <rdf:Description rdf:about=\"\"\n")
+	b.WriteString("    xmlns:mwg-rs=\"" + nsMWGRegions + "\"\n")
+	b.WriteString("    xmlns:stArea=\"" + nsArea + "\"\n")
+	b.WriteString("    xmlns:stDim=\"" + nsDimensions + "\"\n")
+	b.WriteString("    xmlns:MP=\"http://ns.microsoft.com/photo/1.2/\"\n")
+	b.WriteString("    xmlns:MPRI=\"" + nsMPRegions + "\"\n")
+	b.WriteString("    xmlns:MPReg=\"" + nsMPRegion + "\">\n")
+
+	b.WriteString("   <mwg-rs:Regions rdf:parseType=\"Resource\">\n")
+	if width > 0 && height > 0 {
+		fmt.Fprintf(&b, "    <mwg-rs:AppliedToDimensions stDim:w=\"%d\" stDim:h=\"%d\" stDim:unit=\"pixel\"/>\n", width, height)
+	}
+	b.WriteString("    <mwg-rs:RegionList>\n     <rdf:Bag>\n")
+	for _, region := range regions {
+		b.WriteString("      <rdf:li rdf:parseType=\"Resource\">\n")
+		fmt.Fprintf(&b, "       <mwg-rs:Name>%s</mwg-rs:Name>\n", escapeXML(region.Name))
+		if region.Type != "" {
+			fmt.Fprintf(&b, "       <mwg-rs:Type>%s</mwg-rs:Type>\n", escapeXML(region.Type))
+		}
+		fmt.Fprintf(&b, "       <mwg-rs:Area stArea:x=\"%s\" stArea:y=\"%s\" stArea:w=\"%s\" stArea:h=\"%s\" stArea:unit=\"normalized\"/>\n",
+			formatFraction(region.X+region.Width/2), formatFraction(region.Y+region.Height/2),
+			formatFraction(region.Width), formatFraction(region.Height))
+		b.WriteString("      </rdf:li>\n")
+	}
+	b.WriteString("     </rdf:Bag>\n    </mwg-rs:RegionList>\n   </mwg-rs:Regions>\n")
+
+	b.WriteString("   <MP:RegionInfo rdf:parseType=\"Resource\">\n    <MPRI:Regions>\n     <rdf:Bag>\n")
+	for _, region := range regions {
+		if region.Type != RegionTypeFace {
+			continue
+		}
+		fmt.Fprintf(&b, "      <rdf:li MPReg:PersonDisplayName=\"%s\" MPReg:Rectangle=\"%s, %s, %s, %s\"/>\n",
+			escapeXML(region.Name), formatFraction(region.X), formatFraction(region.Y),
+			formatFraction(region.Width), formatFraction(region.Height))
+	}
+	b.WriteString("     </rdf:Bag>\n    </MPRI:Regions>\n   </MP:RegionInfo>\n")
+
+	b.WriteString("  </rdf:Description>\n </rdf:RDF>\n</x:xmpmeta>\n<?xpacket end=\"w\"?>\n")
+	return b.Bytes()
+}
+
+// formatFraction formata uma coordenada normalizada com 6 casas decimais.
+func formatFraction(f float64) string {
+	return strconv.FormatFloat(f, 'f', 6, 64)
+}
+
+// escapeXML escapa um texto para uso em elementos e atributos XML.
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// xmpNode é um elemento do pacote XMP, com os atributos e os elementos filhos.
+type xmpNode struct {
+	Name     xml.Name
+	Attrs    []xml.Attr
+	Text     string
+	Children []*xmpNode
+}
+
+// parseXMPTree lê o pacote XMP inteiro como uma árvore de elementos. As propriedades RDF podem vir como
+// atributos ou como elementos, e os prefixos variam entre os programas: a árvore permite procurá-las pelo
+// namespace em qualquer das formas.
+func parseXMPTree(xmp []byte) (*xmpNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(xmp))
+	root := &xmpNode{}
+	stack := []*xmpNode{root}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("XMP inválido: %w", err)
+		}
+		current := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmpNode{Name: t.Name, Attrs: t.Attr}
+			current.Children = append(current.Children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			current.Text += string(t)
+		}
+	}
+	return root, nil
+}
+
+// findAll retorna os elementos descendentes com o nome informado.
+func (n *xmpNode) findAll(space, local string) []*xmpNode {
+	var found []*xmpNode
+	for _, child := range n.Children {
+		if child.Name.Space == space && child.Name.Local == local {
+			found = append(found, child)
+		}
+		found = append(found, child.findAll(space, local)...)
+	}
+	return found
+}
+
+// child retorna o primeiro elemento filho com o nome informado, ou nil.
+func (n *xmpNode) child(space, local string) *xmpNode {
+	for _, child := range n.Children {
+		if child.Name.Space == space && child.Name.Local == local {
+			return child
+		}
+	}
+	return nil
+}
+
+// description retorna o rdf:Description do elemento, se as propriedades estiverem nele, ou o próprio
+// elemento (rdf:parseType="Resource" ou propriedades em atributos).
+func (n *xmpNode) description() *xmpNode {
+	if description := n.child(nsRDF, "Description"); description != nil {
+		return description
+	}
+	return n
+}
+
+// property retorna o valor de uma propriedade, em atributo ou em elemento filho, ou "" se ausente. Valores em
+// rdf:Alt (ex: nomes por idioma) retornam o primeiro item.
+func (n *xmpNode) property(space, local string) string {
+	for _, attr := range n.Attrs {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			return strings.TrimSpace(attr.Value)
+		}
+	}
+	child := n.child(space, local)
+	if child == nil {
+		return ""
+	}
+	if items := child.findAll(nsRDF, "li"); len(items) > 0 {
+		return strings.TrimSpace(items[0].Text)
+	}
+	return strings.TrimSpace(child.Text)
+}
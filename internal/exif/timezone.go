@@ -0,0 +1,118 @@
+package exif
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// Etiquetas de fuso do EXIF 2.31, no sub-IFD EXIF. O goexif não as conhece, então são lidas diretamente.
+const (
+	tagOffsetTime         = 0x9010 // Fuso de DateTime
+	tagOffsetTimeOriginal = 0x9011 // Fuso de DateTimeOriginal
+)
+
+// maxTimeZoneOffset é o maior fuso aceito, em minutos (UTC+14, Kiribati).
+const maxTimeZoneOffset = 14 * 60
+
+// offsetPattern reconhece o formato das etiquetas OffsetTime*: "+09:00", "-03:00".
+var offsetPattern = regexp.MustCompile(`^([+-])(\d{2}):(\d{2})$`)
+
+// captureOffset retorna o fuso em que a foto foi tirada, em minutos a leste de UTC. Usa, nesta ordem, as
+// etiquetas OffsetTimeOriginal e OffsetTime, o fuso gravado pelas câmeras Canon e, por último, a diferença
+// entre a hora local da câmera (wall, lida como UTC) e a hora UTC do GPS, arredondada a 15 minutos.
+func captureOffset(x *exif.Exif, wall time.Time) (int, bool) {
+	for _, id := range []uint16{tagOffsetTimeOriginal, tagOffsetTime} {
+		if offset, ok := offsetTag(x, id); ok {
+			return offset, true
+		}
+	}
+	if tz, err := x.TimeZone(); err == nil && tz != nil {
+		_, seconds := wall.In(tz).Zone()
+		return seconds / 60, true
+	}
+	if gpsTime, ok := gpsDateTime(x); ok {
+		offset := int(math.Round(wall.Sub(gpsTime).Minutes()/15) * 15)
+		if offset >= -maxTimeZoneOffset && offset <= maxTimeZoneOffset {
+			return offset, true
+		}
+	}
+	return 0, false
+}
+
+// offsetTag lê uma etiqueta OffsetTime* do sub-IFD EXIF.
+func offsetTag(x *exif.Exif, id uint16) (int, bool) {
+	pointer, err := x.Get(exif.ExifIFDPointer)
+	if err != nil {
+		return 0, false
+	}
+	offset, err := pointer.Int64(0)
+	if err != nil || offset <= 0 || offset >= int64(len(x.Raw)) {
+		return 0, false
+	}
+	// Os valores do diretório são endereçados a partir do início do TIFF, como no goexif
+	r := bytes.NewReader(x.Raw)
+	if _, err := r.Seek(offset, 0); err != nil {
+		return 0, false
+	}
+	dir, _, err := tiff.DecodeDir(r, x.Tiff.Order)
+	if err != nil {
+		return 0, false
+	}
+	for _, tag := range dir.Tags {
+		if tag.Id != id {
+			continue
+		}
+		value, err := tag.StringVal()
+		if err != nil {
+			return 0, false
+		}
+		return parseOffset(strings.TrimSpace(strings.TrimRight(value, "\x00")))
+	}
+	return 0, false
+}
+
+// parseOffset converte um fuso no formato "+HH:MM" em minutos.
+func parseOffset(value string) (int, bool) {
+	match := offsetPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, false
+	}
+	hours, _ := strconv.Atoi(match[2])
+	minutes, _ := strconv.Atoi(match[3])
+	offset := hours*60 + minutes
+	if match[1] == "-" {
+		offset = -offset
+	}
+	if offset > maxTimeZoneOffset || minutes >= 60 {
+		return 0, false
+	}
+	return offset, true
+}
+
+// gpsDateTime retorna a hora UTC registrada pelo GPS (GPSDateStamp e GPSTimeStamp).
+func gpsDateTime(x *exif.Exif) (time.Time, bool) {
+	date, err := time.Parse("2006:01:02", stringField(x, exif.GPSDateStamp))
+	if err != nil {
+		return time.Time{}, false
+	}
+	tag, err := x.Get(exif.GPSTimeStamp)
+	if err != nil || tag.Count < 3 {
+		return time.Time{}, false
+	}
+	var seconds float64
+	for i, unit := range []float64{3600, 60, 1} {
+		num, den, err := tag.Rat2(i)
+		if err != nil || den == 0 {
+			return time.Time{}, false
+		}
+		seconds += float64(num) / float64(den) * unit
+	}
+	return date.Add(time.Duration(seconds * float64(time.Second))), true
+}
@@ -0,0 +1,108 @@
+package exif
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxXMPScan é quanto do início do arquivo é procurado pelo pacote XMP. No JPEG ele fica no segmento APP1,
+// logo após o EXIF; no PNG e no HEIC também vem antes dos dados da imagem.
+const maxXMPScan = 1 << 20
+
+// ReadXMP retorna o pacote XMP (<x:xmpmeta>...</x:xmpmeta>) do arquivo, ou nil se não houver.
+func ReadXMP(filePath string) ([]byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível abrir o arquivo para leitura XMP: %w", err)
+	}
+	defer f.Close()
+
+	head, err := io.ReadAll(io.LimitReader(f, maxXMPScan))
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível ler o arquivo para leitura XMP: %w", err)
+	}
+	start := bytes.Index(head, []byte("<x:xmpmeta"))
+	if start < 0 {
+		return nil, nil
+	}
+	end := bytes.Index(head[start:], []byte("</x:xmpmeta>"))
+	if end < 0 {
+		return nil, nil
+	}
+	return head[start : start+end+len("</x:xmpmeta>")], nil
+}
+
+// Panorama são os metadados GPano (Google Photo Sphere) de uma foto panorâmica ou 360°. As medidas são em
+// pixels da imagem como gravada pela câmera ou pelo programa de montagem; zero quando ausentes.
+type Panorama struct {
+	ProjectionType string // Projeção em minúsculas (ex: equirectangular, cylindrical)
+	FullWidth      int    // Largura do panorama completo (360°)
+	FullHeight     int    // Altura do panorama completo (180°)
+	CroppedWidth   int    // Largura da área capturada, normalmente a largura da própria imagem
+	CroppedHeight  int    // Altura da área capturada
+	CroppedLeft    int    // Posição da área capturada dentro do panorama completo
+	CroppedTop     int
+	InitialHeading *float64 // Direção inicial da visualização, em graus (nil se ausente)
+}
+
+// ParsePanorama lê os metadados GPano de um pacote XMP. Retorna nil se o pacote não declarar a projeção.
+// Os programas gravam as propriedades como atributos (GPano:ProjectionType="...") ou como elementos
+// (<GPano:ProjectionType>...</GPano:ProjectionType>); as duas formas são aceitas.
+func ParsePanorama(xmp []byte) *Panorama {
+	projection := strings.ToLower(gpanoValue(xmp, "ProjectionType"))
+	if projection == "" {
+		return nil
+	}
+	pano := &Panorama{
+		ProjectionType: projection,
+		FullWidth:      gpanoInt(xmp, "FullPanoWidthPixels"),
+		FullHeight:     gpanoInt(xmp, "FullPanoHeightPixels"),
+		CroppedWidth:   gpanoInt(xmp, "CroppedAreaImageWidthPixels"),
+		CroppedHeight:  gpanoInt(xmp, "CroppedAreaImageHeightPixels"),
+		CroppedLeft:    gpanoInt(xmp, "CroppedAreaLeftPixels"),
+		CroppedTop:     gpanoInt(xmp, "CroppedAreaTopPixels"),
+	}
+	if heading, err := strconv.ParseFloat(gpanoValue(xmp, "InitialViewHeadingDegrees"), 64); err == nil {
+		pano.InitialHeading = &heading
+	}
+	return pano
+}
+
+// gpanoPatterns são as expressões das propriedades GPano lidas por ParsePanorama.
+var gpanoPatterns = func() map[string]*regexp.Regexp {
+	patterns := make(map[string]*regexp.Regexp)
+	for _, name := range []string{
+		"ProjectionType", "FullPanoWidthPixels", "FullPanoHeightPixels", "CroppedAreaImageWidthPixels",
+		"CroppedAreaImageHeightPixels", "CroppedAreaLeftPixels", "CroppedAreaTopPixels",
+		"InitialViewHeadingDegrees",
+	} {
+		patterns[name] = regexp.MustCompile(`GPano:` + name + `(?:\s*=\s*["']([^"']*)["']|\s*>([^<]*)</GPano:` + name + `>)`)
+	}
+	return patterns
+}()
+
+// gpanoValue retorna o valor de uma propriedade GPano, em atributo ou em elemento, ou "" se ausente.
+func gpanoValue(xmp []byte, name string) string {
+	match := gpanoPatterns[name].FindSubmatch(xmp)
+	if match == nil {
+		return ""
+	}
+	if len(match[1]) > 0 {
+		return strings.TrimSpace(string(match[1]))
+	}
+	return strings.TrimSpace(string(match[2]))
+}
+
+// gpanoInt retorna uma propriedade GPano inteira, ou 0 se ausente ou inválida.
+func gpanoInt(xmp []byte, name string) int {
+	value, err := strconv.Atoi(gpanoValue(xmp, name))
+	if err != nil || value < 0 {
+		return 0
+	}
+	return value
+}
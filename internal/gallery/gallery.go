@@ -0,0 +1,177 @@
+package gallery
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"html/template"
+	"image"
+	"image/jpeg"
+	"log"
+	"photo-manager/internal/database"
+	"photo-manager/internal/thumbnail"
+	"time"
+)
+
+// Tamanhos padrão das imagens exportadas.
+const (
+	DefaultImageSize = 1600 // Maior lado das imagens exibidas nas páginas das fotos
+	DefaultThumbSize = 320  // Maior lado das miniaturas do índice
+)
+
+// Options controla a geração da galeria.
+type Options struct {
+	ImageSize int   // Maior lado das imagens, em pixels
+	ThumbSize int   // Maior lado das miniaturas, em pixels
+	MaxPixels int64 // Limite de pixels ao decodificar os originais (0 = sem limite)
+}
+
+// Album descreve o álbum exportado.
+type Album struct {
+	Name        string
+	Description string
+	Metadata    map[string]string // Campos personalizados, listados abaixo da descrição
+}
+
+// Result resume uma exportação.
+type Result struct {
+	Exported int // Fotos incluídas na galeria
+	Skipped  int // Fotos ignoradas (vídeos ou imagens que não puderam ser decodificadas)
+}
+
+// page é uma foto exportada, com os caminhos relativos dentro da galeria.
+type page struct {
+	Index     int
+	Caption   string
+	Date      string
+	Image     string
+	Thumb     string
+	Page      string
+	Prev      string
+	Next      string
+	Width     int
+	Height    int
+	AlbumName string
+}
+
+// WriteZip grava no ZIP uma galeria estática e autocontida do álbum: index.html com a grade de miniaturas,
+// uma página por foto com navegação anterior/próxima e as imagens redimensionadas. Não há dependências
+// externas (scripts, fontes, CDNs): a galeria funciona aberta direto do disco ou de um pendrive.
+func WriteZip(ctx context.Context, zw *zip.Writer, album Album, photos []database.Photo, opts Options) (Result, error) {
+	var result Result
+	if opts.ImageSize <= 0 {
+		opts.ImageSize = DefaultImageSize
+	}
+	if opts.ThumbSize <= 0 {
+		opts.ThumbSize = DefaultThumbSize
+	}
+
+	var pages []*page
+	for _, photo := range photos {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		if photo.Quarantined {
+			result.Skipped++
+			continue
+		}
+
+		p := &page{
+			Index:     len(pages) + 1,
+			Caption:   photo.Description,
+			Date:      photoDate(photo).Format("02/01/2006 15:04"),
+			AlbumName: album.Name,
+		}
+		if p.Caption == "" {
+			p.Caption = photo.Filename
+		}
+		p.Image = fmt.Sprintf("images/%04d.jpg", p.Index)
+		p.Thumb = fmt.Sprintf("thumbs/%04d.jpg", p.Index)
+		p.Page = fmt.Sprintf("photo-%04d.html", p.Index)
+
+		width, height, err := writeImages(zw, photo, p, opts)
+		if err != nil {
+			// Ex: vídeo ou arquivo corrompido; a galeria segue sem a foto
+			log.Printf("Exportação da galeria: foto %d ignorada: %v\n", photo.ID, err)
+			result.Skipped++
+			continue
+		}
+		p.Width, p.Height = width, height
+		pages = append(pages, p)
+	}
+
+	for i, p := range pages {
+		if i > 0 {
+			p.Prev = pages[i-1].Page
+		}
+		if i < len(pages)-1 {
+			p.Next = pages[i+1].Page
+		}
+		if err := writeTemplate(zw, p.Page, photoPageTemplate, p); err != nil {
+			return result, err
+		}
+	}
+
+	index := struct {
+		Album     Album
+		Pages     []*page
+		Count     int
+		Generated string
+	}{album, pages, len(pages), time.Now().Format("02/01/2006")}
+	if err := writeTemplate(zw, "index.html", indexTemplate, index); err != nil {
+		return result, err
+	}
+
+	result.Exported = len(pages)
+	return result, nil
+}
+
+// writeImages decodifica a foto uma única vez e grava a imagem e a miniatura redimensionadas.
+func writeImages(zw *zip.Writer, photo database.Photo, p *page, opts Options) (int, int, error) {
+	img, _, err := thumbnail.Decode(photo.StoredPath, opts.MaxPixels)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	resized := thumbnail.Resize(img, opts.ImageSize)
+	if err := writeJPEG(zw, p.Image, resized, 85); err != nil {
+		return 0, 0, err
+	}
+	if err := writeJPEG(zw, p.Thumb, thumbnail.Resize(resized, opts.ThumbSize), 80); err != nil {
+		return 0, 0, err
+	}
+	return resized.Bounds().Dx(), resized.Bounds().Dy(), nil
+}
+
+// writeJPEG grava uma imagem JPEG no ZIP. JPEGs já são comprimidos, então são armazenados sem compressão.
+func writeJPEG(zw *zip.Writer, name string, img image.Image, quality int) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return fmt.Errorf("não foi possível criar '%s' na galeria: %w", name, err)
+	}
+	if err := jpeg.Encode(w, img, &jpeg.Options{Quality: quality}); err != nil {
+		return fmt.Errorf("não foi possível gravar '%s' na galeria: %w", name, err)
+	}
+	return nil
+}
+
+// writeTemplate renderiza uma página HTML no ZIP.
+func writeTemplate(zw *zip.Writer, name string, tmpl *template.Template, data interface{}) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return fmt.Errorf("não foi possível criar '%s' na galeria: %w", name, err)
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("não foi possível gravar '%s' na galeria: %w", name, err)
+	}
+	return nil
+}
+
+// photoDate retorna a data da foto (EXIF, ou a data de upload na falta dela).
+func photoDate(photo database.Photo) time.Time {
+	if photo.ExifDate != nil {
+		return *photo.ExifDate
+	}
+	return photo.UploadDate
+}
@@ -0,0 +1,76 @@
+package gallery
+
+import "html/template"
+
+// galleryStyle é o CSS compartilhado pelas páginas, embutido para que a galeria não dependa de arquivos externos.
+const galleryStyle = `<style>
+body { font-family: sans-serif; margin: 0; padding: 1.5rem; background: #111; color: #eee; }
+a { color: #9cf; }
+h1 { margin-top: 0; }
+.grid { display: flex; flex-wrap: wrap; gap: 8px; }
+.grid a { display: block; }
+.grid img { height: 160px; display: block; }
+.photo { text-align: center; }
+.photo img { max-width: 100%; max-height: 80vh; height: auto; }
+nav { display: flex; justify-content: space-between; margin: 1rem 0; }
+.caption { margin-top: 0.5rem; }
+.date { color: #aaa; font-size: 0.9rem; }
+.fields { display: grid; grid-template-columns: max-content auto; gap: 0.2rem 1rem; }
+.fields dt { color: #aaa; }
+.fields dd { margin: 0; }
+</style>`
+
+// indexTemplate é a página inicial da galeria, com a grade de miniaturas.
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Album.Name}}</title>
+` + galleryStyle + `
+</head>
+<body>
+<h1>{{.Album.Name}}</h1>
+{{- if .Album.Description}}
+<p>{{.Album.Description}}</p>
+{{- end}}
+{{- if .Album.Metadata}}
+<dl class="fields">
+{{- range $key, $value := .Album.Metadata}}
+<dt>{{$key}}</dt><dd>{{$value}}</dd>
+{{- end}}
+</dl>
+{{- end}}
+<p class="date">{{.Count}} foto(s) · exportado em {{.Generated}}</p>
+<div class="grid">
+{{- range .Pages}}
+<a href="{{.Page}}"><img src="{{.Thumb}}" alt="{{.Caption}}" loading="lazy"></a>
+{{- end}}
+</div>
+</body>
+</html>
+`))
+
+// photoPageTemplate é a página de uma foto, com navegação para a anterior, a próxima e o índice.
+var photoPageTemplate = template.Must(template.New("photo").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Caption}} · {{.AlbumName}}</title>
+` + galleryStyle + `
+</head>
+<body>
+<nav>
+<span>{{if .Prev}}<a href="{{.Prev}}">&larr; Anterior</a>{{end}}</span>
+<a href="index.html">{{.AlbumName}}</a>
+<span>{{if .Next}}<a href="{{.Next}}">Próxima &rarr;</a>{{end}}</span>
+</nav>
+<div class="photo">
+<img src="{{.Image}}" width="{{.Width}}" height="{{.Height}}" alt="{{.Caption}}">
+<div class="caption">{{.Caption}}</div>
+<div class="date">{{.Date}}</div>
+</div>
+</body>
+</html>
+`))
@@ -0,0 +1,262 @@
+// Package geotrack lê trilhas GPS (GPX e KML) e estima a posição em um instante, para geolocalizar fotos de
+// câmeras sem GPS a partir da trilha gravada por outro aparelho (celular, relógio, GPS de mão).
+package geotrack
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Point é um ponto da trilha, com o instante (UTC) em que foi registrado.
+type Point struct {
+	Time      time.Time
+	Latitude  float64
+	Longitude float64
+}
+
+// Track é uma trilha, com os pontos em ordem cronológica.
+type Track struct {
+	Points []Point
+}
+
+// ErrNoPoints indica um arquivo sem pontos com data e hora (ex: rota planejada, sem o registro do percurso).
+var ErrNoPoints = errors.New("o arquivo não tem pontos com data e hora")
+
+// Start retorna o instante do primeiro ponto.
+func (t *Track) Start() time.Time {
+	return t.Points[0].Time
+}
+
+// End retorna o instante do último ponto.
+func (t *Track) End() time.Time {
+	return t.Points[len(t.Points)-1].Time
+}
+
+// Locate estima a posição no instante informado. Entre dois pontos separados por no máximo maxGap, a posição é
+// interpolada linearmente; fora da trilha ou em um intervalo maior (ex: aparelho desligado), vale o ponto mais
+// próximo, se estiver a no máximo maxGap. O segundo retorno indica se alguma posição foi encontrada.
+func (t *Track) Locate(at time.Time, maxGap time.Duration) (Point, bool) {
+	points := t.Points
+	i := sort.Search(len(points), func(i int) bool { return !points[i].Time.Before(at) })
+	if i < len(points) && points[i].Time.Equal(at) {
+		return points[i], true
+	}
+
+	if i > 0 && i < len(points) {
+		prev, next := points[i-1], points[i]
+		if span := next.Time.Sub(prev.Time); span <= maxGap {
+			return interpolate(prev, next, float64(at.Sub(prev.Time))/float64(span), at), true
+		}
+	}
+
+	var nearest *Point
+	var distance time.Duration
+	for _, j := range []int{i - 1, i} {
+		if j < 0 || j >= len(points) {
+			continue
+		}
+		if d := absDuration(at.Sub(points[j].Time)); nearest == nil || d < distance {
+			nearest, distance = &points[j], d
+		}
+	}
+	if nearest == nil || distance > maxGap {
+		return Point{}, false
+	}
+	return *nearest, true
+}
+
+// interpolate calcula a posição na fração f do caminho entre a e b, pelo menor arco da longitude (trilhas
+// que cruzam o antimeridiano).
+func interpolate(a, b Point, f float64, at time.Time) Point {
+	deltaLon := b.Longitude - a.Longitude
+	if deltaLon > 180 {
+		deltaLon -= 360
+	} else if deltaLon < -180 {
+		deltaLon += 360
+	}
+	lon := a.Longitude + deltaLon*f
+	if lon > 180 {
+		lon -= 360
+	} else if lon < -180 {
+		lon += 360
+	}
+	return Point{Time: at, Latitude: a.Latitude + (b.Latitude-a.Latitude)*f, Longitude: lon}
+}
+
+// Parse lê uma trilha GPX (trkpt, e também wpt e rtept com horário) ou KML (gx:Track e marcadores com
+// TimeStamp e Point). Pontos sem horário são ignorados; um arquivo sem nenhum resulta em ErrNoPoints.
+func Parse(r io.Reader) (*Track, error) {
+	decoder := xml.NewDecoder(r)
+	var points []Point
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("XML inválido: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		var parsed []Point
+		switch start.Name.Local {
+		case "trkpt", "wpt", "rtept":
+			parsed, err = decodeGPXPoint(decoder, start)
+		case "Placemark":
+			parsed, err = decodeKMLPlacemark(decoder, start)
+		case "Track":
+			var track kmlTrack
+			if err = decoder.DecodeElement(&track, &start); err == nil {
+				parsed, err = track.points()
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, parsed...)
+	}
+
+	if len(points) == 0 {
+		return nil, ErrNoPoints
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	return &Track{Points: points}, nil
+}
+
+// decodeGPXPoint lê um ponto GPX; sem <time>, o ponto é ignorado.
+func decodeGPXPoint(decoder *xml.Decoder, start xml.StartElement) ([]Point, error) {
+	var point struct {
+		Lat  float64 `xml:"lat,attr"`
+		Lon  float64 `xml:"lon,attr"`
+		Time string  `xml:"time"`
+	}
+	if err := decoder.DecodeElement(&point, &start); err != nil {
+		return nil, fmt.Errorf("ponto GPX inválido: %w", err)
+	}
+	if strings.TrimSpace(point.Time) == "" {
+		return nil, nil
+	}
+	at, err := parseTime(point.Time)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCoordinates(point.Lat, point.Lon); err != nil {
+		return nil, err
+	}
+	return []Point{{Time: at, Latitude: point.Lat, Longitude: point.Lon}}, nil
+}
+
+// kmlTrack é um gx:Track: listas paralelas de instantes e coordenadas ("lon lat alt").
+type kmlTrack struct {
+	When  []string `xml:"when"`
+	Coord []string `xml:"coord"`
+}
+
+// points combina os instantes e as coordenadas da trilha.
+func (t kmlTrack) points() ([]Point, error) {
+	if len(t.When) != len(t.Coord) {
+		return nil, fmt.Errorf("gx:Track inválido: %d horários para %d coordenadas", len(t.When), len(t.Coord))
+	}
+	var points []Point
+	for i, when := range t.When {
+		at, err := parseTime(when)
+		if err != nil {
+			return nil, err
+		}
+		point, err := parseCoordinates(at, strings.Fields(t.Coord[i]))
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// decodeKMLPlacemark lê um marcador KML: as trilhas gx:Track (também dentro de gx:MultiTrack) ou um ponto com
+// TimeStamp. Marcadores sem horário são ignorados.
+func decodeKMLPlacemark(decoder *xml.Decoder, start xml.StartElement) ([]Point, error) {
+	var placemark struct {
+		When        string     `xml:"TimeStamp>when"`
+		Coordinates string     `xml:"Point>coordinates"`
+		Tracks      []kmlTrack `xml:"Track"`
+		MultiTrack  []kmlTrack `xml:"MultiTrack>Track"`
+	}
+	if err := decoder.DecodeElement(&placemark, &start); err != nil {
+		return nil, fmt.Errorf("marcador KML inválido: %w", err)
+	}
+
+	var points []Point
+	for _, track := range append(placemark.Tracks, placemark.MultiTrack...) {
+		parsed, err := track.points()
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, parsed...)
+	}
+	if strings.TrimSpace(placemark.When) == "" || strings.TrimSpace(placemark.Coordinates) == "" {
+		return points, nil
+	}
+
+	at, err := parseTime(placemark.When)
+	if err != nil {
+		return nil, err
+	}
+	point, err := parseCoordinates(at, strings.Split(strings.TrimSpace(placemark.Coordinates), ","))
+	if err != nil {
+		return nil, err
+	}
+	return append(points, point), nil
+}
+
+// parseTime lê um horário RFC 3339, como os arquivos GPX e KML o gravam.
+func parseTime(value string) (time.Time, error) {
+	at, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("horário inválido: '%s'", strings.TrimSpace(value))
+	}
+	return at.UTC(), nil
+}
+
+// parseCoordinates lê uma coordenada KML, já separada em longitude, latitude e (opcional) altitude.
+func parseCoordinates(at time.Time, fields []string) (Point, error) {
+	if len(fields) < 2 {
+		return Point{}, fmt.Errorf("coordenada KML inválida: '%s'", strings.Join(fields, ","))
+	}
+	lon, errLon := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+	lat, errLat := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+	if errLon != nil || errLat != nil {
+		return Point{}, fmt.Errorf("coordenada KML inválida: '%s'", strings.Join(fields, ","))
+	}
+	if err := checkCoordinates(lat, lon); err != nil {
+		return Point{}, err
+	}
+	return Point{Time: at, Latitude: lat, Longitude: lon}, nil
+}
+
+// checkCoordinates rejeita latitudes e longitudes fora do intervalo válido.
+func checkCoordinates(lat, lon float64) error {
+	if math.IsNaN(lat) || math.IsNaN(lon) || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return fmt.Errorf("coordenada fora do intervalo válido: %g, %g", lat, lon)
+	}
+	return nil
+}
+
+// absDuration retorna o valor absoluto de uma duração.
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,146 @@
+package graph
+
+import (
+	"context"
+	"photo-manager/internal/database"
+	"photo-manager/internal/service"
+	"sync"
+)
+
+// loader agrupa buscas por chave para evitar o problema de N+1 consultas: as chaves registradas com
+// Prime (ex: os IDs de todos os álbuns de uma listagem) são buscadas juntas, em uma única chamada a
+// fetch, na primeira vez que qualquer uma delas é solicitada com Load. Os resultados ficam em cache
+// durante a requisição.
+type loader[K comparable, V any] struct {
+	fetch func(keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	values  map[K]V
+	errs    map[K]error
+	loaded  map[K]bool
+}
+
+// newLoader cria um loader que usa fetch para buscar um lote de chaves.
+func newLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:  fetch,
+		queued: make(map[K]bool),
+		values: make(map[K]V),
+		errs:   make(map[K]error),
+		loaded: make(map[K]bool),
+	}
+}
+
+// Prime registra chaves que serão buscadas no próximo lote.
+func (l *loader[K, V]) Prime(keys ...K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		l.enqueue(key)
+	}
+}
+
+// Load retorna o valor da chave, buscando junto todas as chaves pendentes se ela ainda não foi carregada.
+func (l *loader[K, V]) Load(key K) (V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.loaded[key] {
+		l.enqueue(key)
+		keys := l.pending
+		l.pending = nil
+		l.queued = make(map[K]bool)
+
+		values, err := l.fetch(keys)
+		for _, k := range keys {
+			l.loaded[k] = true
+			l.values[k] = values[k]
+			if err != nil {
+				l.errs[k] = err
+			}
+		}
+	}
+	return l.values[key], l.errs[key]
+}
+
+// enqueue adiciona a chave ao próximo lote, se ela ainda não foi carregada nem está na fila.
+func (l *loader[K, V]) enqueue(key K) {
+	if l.loaded[key] || l.queued[key] {
+		return
+	}
+	l.queued[key] = true
+	l.pending = append(l.pending, key)
+}
+
+// loaders reúne os loaders de uma requisição GraphQL. Cada requisição recebe sua própria instância,
+// de modo que o cache não sobrevive entre requisições.
+type loaders struct {
+	albums *service.AlbumService
+
+	albumSummaries *loader[uint, service.AlbumSummary]
+	albumTags      *loader[uint, []service.TagSummary]
+	photoAlbums    *loader[uint, []database.Album]
+
+	mu          sync.Mutex
+	albumIDs    []uint                                  // Álbuns já vistos na requisição
+	albumPhotos map[int]*loader[uint, []database.Photo] // Fotos dos álbuns, um loader por limite (argumento first)
+}
+
+// newLoaders cria os loaders de uma requisição.
+func newLoaders(albums *service.AlbumService) *loaders {
+	return &loaders{
+		albums:         albums,
+		albumSummaries: newLoader(albums.GetAlbumSummaries),
+		albumTags:      newLoader(albums.GetTagsForAlbums),
+		photoAlbums:    newLoader(albums.GetAlbumsForPhotos),
+		albumPhotos:    make(map[int]*loader[uint, []database.Photo]),
+	}
+}
+
+// seeAlbums registra álbuns resolvidos na requisição, para que seus campos sejam buscados em lote.
+func (l *loaders) seeAlbums(ids []uint) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.albumIDs = append(l.albumIDs, ids...)
+	l.albumSummaries.Prime(ids...)
+	l.albumTags.Prime(ids...)
+	for _, photos := range l.albumPhotos {
+		photos.Prime(ids...)
+	}
+}
+
+// seePhotos registra fotos resolvidas na requisição, para que seus campos sejam buscados em lote.
+func (l *loaders) seePhotos(ids []uint) {
+	l.photoAlbums.Prime(ids...)
+}
+
+// albumPhotosLoader retorna o loader das fotos dos álbuns com o limite informado (0 = todas).
+func (l *loaders) albumPhotosLoader(limit int) *loader[uint, []database.Photo] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	photos, ok := l.albumPhotos[limit]
+	if !ok {
+		photos = newLoader(func(ids []uint) (map[uint][]database.Photo, error) {
+			return l.albums.GetPhotosForAlbums(ids, limit)
+		})
+		photos.Prime(l.albumIDs...)
+		l.albumPhotos[limit] = photos
+	}
+	return photos
+}
+
+type loadersKey struct{}
+
+// withLoaders associa um novo conjunto de loaders ao contexto da requisição.
+func withLoaders(ctx context.Context, albums *service.AlbumService) context.Context {
+	return context.WithValue(ctx, loadersKey{}, newLoaders(albums))
+}
+
+// loadersFrom retorna os loaders da requisição.
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,398 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/service"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"gorm.io/gorm"
+)
+
+// Limites de paginação da consulta photos.
+const (
+	defaultPhotosLimit = 100
+	maxPhotosLimit     = 500
+)
+
+// Resolver é a raiz das consultas GraphQL.
+type Resolver struct {
+	PhotoService *service.PhotoService
+	AlbumService *service.AlbumService
+	TagService   *service.TagService
+}
+
+// Photos resolve a consulta photos.
+func (r *Resolver) Photos(ctx context.Context, args struct {
+	Year           *int32
+	Month          *int32
+	Filename       *string
+	Tag            *string
+	AlbumID        *graphql.ID
+	Favorites      *bool
+	IncludeHidden  *bool
+	Source         *string
+	Device         *string
+	Q              *string
+	ExcludeTags    *[]string
+	ExcludeAlbumID *graphql.ID
+	ExcludeSource  *string
+	Untagged       *bool
+	NoExifDate     *bool
+	NoGps          *bool
+	NotInAlbum     *bool
+	Offset         *int32
+	Limit          *int32
+}) ([]*photoResolver, error) {
+	filter := service.PhotoFilter{Limit: defaultPhotosLimit}
+	if args.Year != nil {
+		filter.Year = int(*args.Year)
+	}
+	if args.Month != nil {
+		filter.Month = int(*args.Month)
+	}
+	if args.Filename != nil {
+		filter.Filename = *args.Filename
+	}
+	if args.Tag != nil {
+		filter.Tag = *args.Tag
+	}
+	if args.AlbumID != nil {
+		albumID, err := parseID(*args.AlbumID)
+		if err != nil {
+			return nil, err
+		}
+		filter.AlbumID = albumID
+	}
+	if args.Favorites != nil {
+		filter.FavoritesOnly = *args.Favorites
+	}
+	if args.IncludeHidden != nil {
+		filter.IncludeHidden = *args.IncludeHidden
+	}
+	if args.Source != nil {
+		filter.Source = *args.Source
+	}
+	if args.Device != nil {
+		filter.Device = *args.Device
+	}
+	if args.ExcludeTags != nil {
+		filter.ExcludeTags = *args.ExcludeTags
+	}
+	if args.ExcludeAlbumID != nil {
+		albumID, err := parseID(*args.ExcludeAlbumID)
+		if err != nil {
+			return nil, err
+		}
+		filter.ExcludeAlbumID = albumID
+	}
+	if args.ExcludeSource != nil {
+		filter.ExcludeSource = *args.ExcludeSource
+	}
+	absent := false
+	if args.Untagged != nil && *args.Untagged {
+		filter.Tagged = &absent
+	}
+	if args.NoExifDate != nil && *args.NoExifDate {
+		filter.Dated = &absent
+	}
+	if args.NoGps != nil && *args.NoGps {
+		filter.GPS = &absent
+	}
+	if args.NotInAlbum != nil && *args.NotInAlbum {
+		filter.InAlbum = &absent
+	}
+	if args.Q != nil {
+		if err := service.ApplySearchQuery(&filter, *args.Q); err != nil {
+			return nil, err
+		}
+	}
+	if args.Offset != nil {
+		if *args.Offset < 0 {
+			return nil, fmt.Errorf("offset inválido")
+		}
+		filter.Offset = int(*args.Offset)
+	}
+	if args.Limit != nil {
+		if *args.Limit < 1 || *args.Limit > maxPhotosLimit {
+			return nil, fmt.Errorf("limit deve estar entre 1 e %d", maxPhotosLimit)
+		}
+		filter.Limit = int(*args.Limit)
+	}
+
+	photos, err := r.PhotoService.GetPhotos(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return newPhotoResolvers(ctx, photos), nil
+}
+
+// Photo resolve a consulta photo. Retorna null se a foto não existe.
+func (r *Resolver) Photo(ctx context.Context, args struct{ ID graphql.ID }) (*photoResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	photo, err := r.PhotoService.GetPhotoByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newPhotoResolvers(ctx, []database.Photo{*photo})[0], nil
+}
+
+// Albums resolve a consulta albums.
+func (r *Resolver) Albums(ctx context.Context) ([]*albumResolver, error) {
+	summaries, err := r.AlbumService.ListAlbums()
+	if err != nil {
+		return nil, err
+	}
+	return newAlbumResolvers(ctx, summaries, true), nil
+}
+
+// Album resolve a consulta album. Retorna null se o álbum não existe.
+func (r *Resolver) Album(ctx context.Context, args struct{ ID graphql.ID }) (*albumResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := r.AlbumService.GetAlbum(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newAlbumResolvers(ctx, []service.AlbumSummary{*summary}, true)[0], nil
+}
+
+// Tags resolve a consulta tags.
+func (r *Resolver) Tags() ([]*tagResolver, error) {
+	summaries, err := r.TagService.ListTags()
+	if err != nil {
+		return nil, err
+	}
+	return newTagResolvers(summaries), nil
+}
+
+// photoResolver resolve os campos de Photo.
+type photoResolver struct {
+	photo database.Photo
+}
+
+// newPhotoResolvers cria os resolvers de uma lista de fotos, registrando-as nos loaders
+// para que os campos aninhados sejam buscados em lote.
+func newPhotoResolvers(ctx context.Context, photos []database.Photo) []*photoResolver {
+	ids := make([]uint, 0, len(photos))
+	resolvers := make([]*photoResolver, 0, len(photos))
+	for _, photo := range photos {
+		ids = append(ids, photo.ID)
+		resolvers = append(resolvers, &photoResolver{photo: photo})
+	}
+	loadersFrom(ctx).seePhotos(ids)
+	return resolvers
+}
+
+func (p *photoResolver) ID() graphql.ID         { return formatID(p.photo.ID) }
+func (p *photoResolver) PublicID() string       { return p.photo.PublicID }
+func (p *photoResolver) Filename() string       { return p.photo.Filename }
+func (p *photoResolver) UploadDate() string     { return p.photo.UploadDate.Format(time.RFC3339) }
+func (p *photoResolver) Width() int32           { return int32(p.photo.Width) }
+func (p *photoResolver) Height() int32          { return int32(p.photo.Height) }
+func (p *photoResolver) FileSize() float64      { return float64(p.photo.FileSize) }
+func (p *photoResolver) MimeType() string       { return p.photo.MimeType }
+func (p *photoResolver) Description() string    { return p.photo.Description }
+func (p *photoResolver) Favorite() bool         { return p.photo.Favorite }
+func (p *photoResolver) Hidden() bool           { return p.photo.Hidden }
+func (p *photoResolver) HideFromTimeline() bool { return p.photo.HideFromTimeline }
+func (p *photoResolver) Locked() bool           { return p.photo.Locked }
+func (p *photoResolver) ThumbnailURL() string   { return fmt.Sprintf("/photos/%d/thumbnail", p.photo.ID) }
+func (p *photoResolver) Source() string         { return p.photo.Source }
+func (p *photoResolver) SourceDevice() string   { return p.photo.SourceDevice }
+
+func (p *photoResolver) ExifDate() *string { return formatOptionalTime(p.photo.ExifDate) }
+
+func (p *photoResolver) CircaDate() *string {
+	if p.photo.CircaDate == nil {
+		return nil
+	}
+	date := service.FormatCircaDate(p.photo)
+	return &date
+}
+
+// formatOptionalTime formata uma data opcional em RFC 3339 (nil se ausente).
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	date := t.Format(time.RFC3339)
+	return &date
+}
+
+func (p *photoResolver) Tags() []string {
+	tags := []string{}
+	for _, tag := range strings.Split(p.photo.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func (p *photoResolver) Albums(ctx context.Context) ([]*albumResolver, error) {
+	albums, err := loadersFrom(ctx).photoAlbums.Load(p.photo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]service.AlbumSummary, 0, len(albums))
+	for _, album := range albums {
+		summaries = append(summaries, service.AlbumSummary{Album: album})
+	}
+	// Os álbuns vêm de uma junção sem agregações: as contagens são buscadas sob demanda, em lote
+	return newAlbumResolvers(ctx, summaries, false), nil
+}
+
+// albumResolver resolve os campos de Album.
+type albumResolver struct {
+	summary   service.AlbumSummary
+	hasCounts bool // false quando o álbum veio de uma junção sem agregações
+}
+
+// newAlbumResolvers cria os resolvers de uma lista de álbuns, registrando-os nos loaders
+// para que os campos aninhados sejam buscados em lote. hasCounts indica se as agregações já estão preenchidas.
+func newAlbumResolvers(ctx context.Context, summaries []service.AlbumSummary, hasCounts bool) []*albumResolver {
+	ids := make([]uint, 0, len(summaries))
+	resolvers := make([]*albumResolver, 0, len(summaries))
+	for _, summary := range summaries {
+		ids = append(ids, summary.ID)
+		resolvers = append(resolvers, &albumResolver{summary: summary, hasCounts: hasCounts})
+	}
+	loadersFrom(ctx).seeAlbums(ids)
+	return resolvers
+}
+
+func (a *albumResolver) ID() graphql.ID      { return formatID(a.summary.ID) }
+func (a *albumResolver) PublicID() string    { return a.summary.PublicID }
+func (a *albumResolver) Name() string        { return a.summary.Name }
+func (a *albumResolver) Description() string { return a.summary.Description }
+func (a *albumResolver) Pinned() bool        { return a.summary.Pinned }
+func (a *albumResolver) Locked() bool        { return a.summary.Locked }
+func (a *albumResolver) StartDate() *string  { return formatOptionalTime(a.summary.StartDate) }
+func (a *albumResolver) EndDate() *string    { return formatOptionalTime(a.summary.EndDate) }
+
+// Metadata lista os campos personalizados do álbum, em ordem alfabética.
+func (a *albumResolver) Metadata() []*albumFieldResolver {
+	fields := service.AlbumMetadata(a.summary.Album)
+	resolvers := make([]*albumFieldResolver, 0, len(fields))
+	for key, value := range fields {
+		resolvers = append(resolvers, &albumFieldResolver{key: key, value: value})
+	}
+	sort.Slice(resolvers, func(i, j int) bool { return resolvers[i].key < resolvers[j].key })
+	return resolvers
+}
+
+// albumFieldResolver resolve um campo personalizado de um álbum.
+type albumFieldResolver struct {
+	key   string
+	value string
+}
+
+func (f *albumFieldResolver) Key() string   { return f.key }
+func (f *albumFieldResolver) Value() string { return f.value }
+
+func (a *albumResolver) PhotoCount(ctx context.Context) (int32, error) {
+	summary, err := a.counts(ctx)
+	return int32(summary.PhotoCount), err
+}
+
+func (a *albumResolver) TotalBytes(ctx context.Context) (float64, error) {
+	summary, err := a.counts(ctx)
+	return float64(summary.TotalBytes), err
+}
+
+func (a *albumResolver) Cover(ctx context.Context) (*photoResolver, error) {
+	photos, err := loadersFrom(ctx).albumPhotosLoader(1).Load(a.summary.ID)
+	if err != nil || len(photos) == 0 {
+		return nil, err
+	}
+	return newPhotoResolvers(ctx, photos)[0], nil
+}
+
+func (a *albumResolver) Photos(ctx context.Context, args struct{ First *int32 }) ([]*photoResolver, error) {
+	limit := 0
+	if args.First != nil {
+		if *args.First < 0 {
+			return nil, fmt.Errorf("first inválido")
+		}
+		if *args.First == 0 {
+			return []*photoResolver{}, nil
+		}
+		limit = int(*args.First)
+	}
+
+	photos, err := loadersFrom(ctx).albumPhotosLoader(limit).Load(a.summary.ID)
+	if err != nil {
+		return nil, err
+	}
+	return newPhotoResolvers(ctx, photos), nil
+}
+
+func (a *albumResolver) Tags(ctx context.Context) ([]*tagResolver, error) {
+	tags, err := loadersFrom(ctx).albumTags.Load(a.summary.ID)
+	if err != nil {
+		return nil, err
+	}
+	return newTagResolvers(tags), nil
+}
+
+// counts retorna as agregações do álbum, buscando-as em lote quando ainda não são conhecidas.
+func (a *albumResolver) counts(ctx context.Context) (service.AlbumSummary, error) {
+	if a.hasCounts {
+		return a.summary, nil
+	}
+	return loadersFrom(ctx).albumSummaries.Load(a.summary.ID)
+}
+
+// tagResolver resolve os campos de Tag.
+type tagResolver struct {
+	summary service.TagSummary
+}
+
+// newTagResolvers cria os resolvers de uma lista de tags.
+func newTagResolvers(summaries []service.TagSummary) []*tagResolver {
+	resolvers := make([]*tagResolver, 0, len(summaries))
+	for _, summary := range summaries {
+		resolvers = append(resolvers, &tagResolver{summary: summary})
+	}
+	return resolvers
+}
+
+func (t *tagResolver) ID() graphql.ID      { return formatID(t.summary.ID) }
+func (t *tagResolver) Name() string        { return t.summary.Name }
+func (t *tagResolver) PhotoCount() int32   { return int32(t.summary.PhotoCount) }
+func (t *tagResolver) TotalBytes() float64 { return float64(t.summary.TotalBytes) }
+
+// parseID converte um ID GraphQL em ID do banco de dados.
+func parseID(id graphql.ID) (uint, error) {
+	value, err := strconv.ParseUint(string(id), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("ID inválido: %s", id)
+	}
+	return uint(value), nil
+}
+
+// formatID converte um ID do banco de dados em ID GraphQL.
+func formatID(id uint) graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(id), 10))
+}
@@ -0,0 +1,115 @@
+package graph
+
+import (
+	"context"
+	"photo-manager/internal/service"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// schemaSDL descreve as consultas disponíveis. Valores em bytes usam Float, pois Int do GraphQL tem 32 bits.
+const schemaSDL = `
+schema {
+	query: Query
+}
+
+type Query {
+	# Fotos que respeitam o filtro, da mais recente para a mais antiga. limit padrão: 100, máximo: 500.
+	# Fotos ocultas só são retornadas com includeHidden: true. source e device filtram pela procedência.
+	# excludeTags, excludeAlbumId e excludeSource excluem fotos; untagged, noExifDate, noGps e notInAlbum
+	# encontram as fotos ainda não organizadas.
+	# q aceita a busca compacta de GET /photos/search (ex: "tag:praia year:2023 has:gps -tag:screenshots").
+	photos(year: Int, month: Int, filename: String, tag: String, albumId: ID, favorites: Boolean, includeHidden: Boolean, source: String, device: String, excludeTags: [String!], excludeAlbumId: ID, excludeSource: String, untagged: Boolean, noExifDate: Boolean, noGps: Boolean, notInAlbum: Boolean, q: String, offset: Int, limit: Int): [Photo!]!
+	photo(id: ID!): Photo
+	# Álbuns fixados primeiro, depois em ordem alfabética
+	albums: [Album!]!
+	album(id: ID!): Album
+	tags: [Tag!]!
+}
+
+type Photo {
+	id: ID!
+	# Identificador público imutável, usado nas URLs compartilhadas
+	publicId: String!
+	filename: String!
+	uploadDate: String!
+	exifDate: String
+	# Data aproximada informada manualmente: "1985-03" (mês), "1985" (ano) ou "1980s" (década)
+	circaDate: String
+	width: Int!
+	height: Int!
+	fileSize: Float!
+	mimeType: String!
+	description: String!
+	favorite: Boolean!
+	hidden: Boolean!
+	hideFromTimeline: Boolean!
+	locked: Boolean!
+	thumbnailUrl: String!
+	# Canal de entrada (upload, sync, email, share_upload, import, mirror) e dispositivo de origem
+	source: String!
+	sourceDevice: String!
+	tags: [String!]!
+	albums: [Album!]!
+}
+
+type Album {
+	id: ID!
+	# Identificador público imutável, usado nas URLs compartilhadas
+	publicId: String!
+	name: String!
+	description: String!
+	pinned: Boolean!
+	locked: Boolean!
+	# Período das fotos (RFC 3339); nulos em álbuns vazios
+	startDate: String
+	endDate: String
+	photoCount: Int!
+	totalBytes: Float!
+	# Foto mais recente do álbum
+	cover: Photo
+	# Fotos do álbum, da mais recente para a mais antiga; first limita a quantidade
+	photos(first: Int): [Photo!]!
+	# Tags das fotos do álbum, com contagens restritas ao álbum
+	tags: [Tag!]!
+	# Campos personalizados (ex: Cliente, Local), em ordem alfabética
+	metadata: [AlbumField!]!
+}
+
+type AlbumField {
+	key: String!
+	value: String!
+}
+
+type Tag {
+	id: ID!
+	name: String!
+	photoCount: Int!
+	totalBytes: Float!
+}
+`
+
+// maxQueryDepth limita o aninhamento das consultas (ex: álbum → fotos → álbuns → fotos ...).
+const maxQueryDepth = 8
+
+// Server executa consultas GraphQL sobre os serviços da aplicação.
+type Server struct {
+	schema *graphql.Schema
+	albums *service.AlbumService
+}
+
+// NewServer cria o servidor GraphQL, validando o schema contra os resolvers.
+func NewServer(photos *service.PhotoService, albums *service.AlbumService, tags *service.TagService) (*Server, error) {
+	resolver := &Resolver{PhotoService: photos, AlbumService: albums, TagService: tags}
+	schema, err := graphql.ParseSchema(schemaSDL, resolver, graphql.MaxDepth(maxQueryDepth))
+	if err != nil {
+		return nil, err
+	}
+	return &Server{schema: schema, albums: albums}, nil
+}
+
+// Exec executa uma consulta. Cada execução usa seus próprios loaders, que agrupam as buscas
+// de campos aninhados (fotos, tags e contagens dos álbuns) em uma consulta por campo.
+func (s *Server) Exec(ctx context.Context, query, operationName string, variables map[string]interface{}) *graphql.Response {
+	return s.schema.Exec(withLoaders(ctx, s.albums), query, operationName, variables)
+}
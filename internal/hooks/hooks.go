@@ -0,0 +1,185 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Fases em que os hooks são executados.
+const (
+	PhasePreIngest  = "pre_ingest"  // Antes de aceitar o arquivo: o hook pode rejeitá-lo
+	PhasePostIngest = "post_ingest" // Depois de a foto ser gravada: o hook apenas é avisado
+)
+
+// Políticas para quando o hook de pré-ingestão não responde (erro, timeout ou resposta inesperada).
+const (
+	FailReject = "reject" // Rejeita o arquivo (padrão)
+	FailAccept = "accept" // Aceita o arquivo, registrando a falha no log
+)
+
+// maxOutputSize limita o tamanho da resposta de um hook lida como motivo da rejeição.
+const maxOutputSize = 4 << 10
+
+// Options são os hooks configurados (HOOK_*).
+type Options struct {
+	PreIngest          string        // Comando ou URL http(s) executado antes de aceitar cada arquivo (vazio desativa)
+	PreIngestTimeout   time.Duration // Tempo máximo do hook de pré-ingestão
+	PreIngestOnFailure string        // Política quando o hook falha (ver constantes Fail*)
+	PostIngest         string        // Comando ou URL http(s) executado após cada ingestão (vazio desativa)
+	PostIngestTimeout  time.Duration // Tempo máximo de cada tentativa do hook de pós-ingestão
+	PostIngestRetries  int           // Novas tentativas do hook de pós-ingestão após uma falha
+	PostIngestWorkers  int           // Execuções simultâneas do hook de pós-ingestão
+	PostIngestQueue    int           // Fotos aguardando o hook de pós-ingestão; acima disso são descartadas
+}
+
+// Payload é o contrato enviado aos hooks, em JSON: no corpo do POST (URL) ou na entrada padrão (comando).
+type Payload struct {
+	Phase    string     `json:"phase"`     // Ver constantes Phase*
+	Path     string     `json:"path"`      // Arquivo local: o temporário na pré-ingestão, o armazenado na pós-ingestão
+	Filename string     `json:"filename"`  // Nome original do arquivo
+	Size     int64      `json:"size"`      // Tamanho em bytes
+	MimeType string     `json:"mime_type"` // Tipo detectado pelo conteúdo
+	Width    int        `json:"width,omitempty"`
+	Height   int        `json:"height,omitempty"`
+	Photo    *PhotoInfo `json:"photo,omitempty"` // Foto gravada (apenas na pós-ingestão)
+}
+
+// PhotoInfo são os dados da foto gravada, enviados na pós-ingestão.
+type PhotoInfo struct {
+	ID          uint       `json:"id"`
+	StoredPath  string     `json:"stored_path"`
+	Hash        string     `json:"hash"`
+	ExifDate    *time.Time `json:"exif_date,omitempty"`
+	Latitude    *float64   `json:"latitude,omitempty"`
+	Longitude   *float64   `json:"longitude,omitempty"`
+	CameraMake  string     `json:"camera_make,omitempty"`
+	CameraModel string     `json:"camera_model,omitempty"`
+	Source      string     `json:"source"`
+	Device      string     `json:"device,omitempty"`
+}
+
+// Rejection indica que o hook recusou o arquivo, com o motivo informado por ele.
+type Rejection struct {
+	Reason string
+}
+
+func (r *Rejection) Error() string {
+	return "arquivo rejeitado pelo hook: " + r.Reason
+}
+
+// Hook executa um comando externo ou chama uma URL com o payload da fase.
+//
+// Comando: recebe o payload na entrada padrão e o caminho do arquivo como último argumento. Saída 0 aceita;
+// saída 1 rejeita, com a saída do comando como motivo; outros códigos são falhas do hook.
+//
+// URL: recebe um POST com o payload. Status 2xx aceita; 4xx rejeita, com o corpo da resposta como motivo
+// (texto ou {"reason": "..."}); outros status e erros de rede são falhas do hook.
+type Hook struct {
+	Target     string
+	Timeout    time.Duration
+	HTTPClient *http.Client
+}
+
+// New cria o hook para o destino informado: uma URL http(s) ou um comando com argumentos.
+func New(target string, timeout time.Duration) *Hook {
+	return &Hook{Target: strings.TrimSpace(target), Timeout: timeout, HTTPClient: &http.Client{}}
+}
+
+// IsHTTP indica se o hook é uma URL.
+func (h *Hook) IsHTTP() bool {
+	return strings.HasPrefix(h.Target, "http://") || strings.HasPrefix(h.Target, "https://")
+}
+
+// Run executa o hook. Retorna *Rejection se o hook recusou o arquivo e outro erro se o hook falhou.
+func (h *Hook) Run(ctx context.Context, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("não foi possível codificar o payload do hook: %w", err)
+	}
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+	if h.IsHTTP() {
+		return h.post(ctx, payload.Phase, body)
+	}
+	return h.exec(ctx, payload, body)
+}
+
+// exec executa o hook como comando.
+func (h *Hook) exec(ctx context.Context, payload Payload, body []byte) error {
+	command := strings.Fields(h.Target)
+	if len(command) == 0 {
+		return errors.New("hook sem comando")
+	}
+	args := append(append([]string{}, command[1:]...), payload.Path)
+	cmd := exec.CommandContext(ctx, command[0], args...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(), "PHOTO_HOOK_PHASE="+payload.Phase)
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("o hook '%s' excedeu o tempo limite: %w", command[0], ctx.Err())
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return &Rejection{Reason: reason(output)}
+	}
+	return fmt.Errorf("erro ao executar o hook '%s': %w (%s)", command[0], err, reason(output))
+}
+
+// post executa o hook como chamada HTTP.
+func (h *Hook) post(ctx context.Context, phase string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.Target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Photo-Hook-Phase", phase)
+	resp, err := h.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("falha ao chamar o hook: %w", err)
+	}
+	defer resp.Body.Close()
+	output, _ := io.ReadAll(io.LimitReader(resp.Body, maxOutputSize))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		var decoded struct {
+			Reason string `json:"reason"`
+		}
+		if json.Unmarshal(output, &decoded) == nil && decoded.Reason != "" {
+			return &Rejection{Reason: decoded.Reason}
+		}
+		return &Rejection{Reason: reason(output)}
+	default:
+		return fmt.Errorf("o hook respondeu com status %d (%s)", resp.StatusCode, reason(output))
+	}
+}
+
+// reason resume a saída do hook para uso como motivo.
+func reason(output []byte) string {
+	text := strings.TrimSpace(string(output))
+	if len(text) > 500 {
+		text = text[:500] + "..."
+	}
+	if text == "" {
+		return "sem motivo informado"
+	}
+	return text
+}
@@ -0,0 +1,51 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"log"
+	"photo-manager/internal/validation"
+)
+
+// preIngestValidator executa o hook de pré-ingestão como último validador da cadeia, quando tipo e
+// dimensões do arquivo já foram preenchidos pelos validadores anteriores.
+type preIngestValidator struct {
+	hook      *Hook
+	onFailure string
+}
+
+// Validator cria o validador que consulta o hook antes de aceitar cada arquivo. Uma rejeição do hook
+// rejeita o arquivo; uma falha do hook (erro, timeout) segue a política onFailure (ver constantes Fail*).
+func Validator(h *Hook, onFailure string) validation.Validator {
+	return &preIngestValidator{hook: h, onFailure: onFailure}
+}
+
+func (v *preIngestValidator) Name() string { return "hook" }
+
+func (v *preIngestValidator) Validate(ctx context.Context, f *validation.File) error {
+	err := v.hook.Run(ctx, Payload{
+		Phase:    PhasePreIngest,
+		Path:     f.Path,
+		Filename: f.Filename,
+		Size:     f.Size,
+		MimeType: f.MimeType,
+		Width:    f.Width,
+		Height:   f.Height,
+	})
+	if err == nil {
+		return nil
+	}
+
+	var rejection *Rejection
+	if errors.As(err, &rejection) {
+		return validation.NewError("hook", validation.CodeHookRejected, rejection.Reason)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err() // Requisição cancelada pelo cliente: não é falha do hook
+	}
+	if v.onFailure == FailAccept {
+		log.Printf("Aviso: hook de pré-ingestão falhou; arquivo '%s' aceito pela política: %v\n", f.Filename, err)
+		return nil
+	}
+	return validation.NewError("hook", validation.CodeHookFailed, err)
+}
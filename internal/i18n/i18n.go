@@ -0,0 +1,150 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Idiomas suportados.
+const (
+	Portuguese    = "pt-BR"
+	English       = "en"
+	DefaultLocale = Portuguese // Usado quando o cliente não informa um idioma suportado e nas mensagens de log
+)
+
+// catalog contém as mensagens de cada idioma, indexadas pelo código.
+var catalog = map[string]map[string]string{
+	Portuguese: portuguese,
+	English:    english,
+}
+
+// Message retorna a mensagem do código no idioma informado, formatada com args (como fmt.Sprintf).
+// Idiomas ou códigos ausentes do catálogo recorrem ao idioma padrão e, por fim, ao próprio código.
+func Message(locale, code string, args ...interface{}) string {
+	template, ok := catalog[locale][code]
+	if !ok {
+		if template, ok = catalog[DefaultLocale][code]; !ok {
+			return code
+		}
+	}
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+// Negotiate escolhe o idioma suportado de maior preferência no cabeçalho Accept-Language
+// (ex: "en-US,en;q=0.9,pt;q=0.8"). Sem correspondência, retorna DefaultLocale.
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		locale  string
+		quality float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if locale := match(tag); locale != "" && quality > 0 {
+			candidates = append(candidates, candidate{locale, quality})
+		}
+	}
+	if len(candidates) == 0 {
+		return DefaultLocale
+	}
+	// SliceStable mantém a ordem do cabeçalho entre idiomas de mesma preferência
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].quality > candidates[j].quality })
+	return candidates[0].locale
+}
+
+// match associa uma etiqueta de idioma (ex: pt-PT, en-GB) ao idioma suportado de mesma língua.
+func match(tag string) string {
+	primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	switch primary {
+	case "pt":
+		return Portuguese
+	case "en":
+		return English
+	default:
+		return ""
+	}
+}
+
+// Localizable é implementado pelos erros que podem ser exibidos ao cliente no idioma dele.
+type Localizable interface {
+	error
+	MessageCode() string           // Código estável do erro, devolvido ao cliente junto com a mensagem
+	Localize(locale string) string // Mensagem no idioma informado
+}
+
+// Error é um erro identificado por um código do catálogo. Error() retorna a mensagem no idioma padrão,
+// para logs; Localize a traduz para o idioma do cliente.
+type Error struct {
+	Code string
+	Args []interface{}
+	Err  error // Causa, acrescentada à mensagem (opcional)
+}
+
+// NewError cria um erro com a mensagem do código.
+func NewError(code string, args ...interface{}) *Error {
+	return &Error{Code: code, Args: args}
+}
+
+// WrapError cria um erro com a mensagem do código, seguida da causa.
+func WrapError(err error, code string, args ...interface{}) *Error {
+	return &Error{Code: code, Args: args, Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Localize(DefaultLocale)
+}
+
+// MessageCode retorna o código do erro.
+func (e *Error) MessageCode() string {
+	return e.Code
+}
+
+// Localize retorna a mensagem no idioma informado, incluindo a causa (traduzida, se possível).
+func (e *Error) Localize(locale string) string {
+	message := Message(locale, e.Code, e.Args...)
+	if e.Err != nil {
+		message += ": " + Localize(e.Err, locale)
+	}
+	return message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Localize traduz a mensagem do erro traduzível mais externo contido em err; o contexto acrescentado
+// por fmt.Errorf ao redor dele é descartado. No idioma padrão, e para erros sem tradução (ex: falhas do
+// banco), a mensagem completa é devolvida como está.
+func Localize(err error, locale string) string {
+	if locale == DefaultLocale {
+		return err.Error()
+	}
+	var localizable Localizable
+	if errors.As(err, &localizable) {
+		return localizable.Localize(locale)
+	}
+	return err.Error()
+}
+
+// Code retorna o código do erro traduzível contido em err, ou "" se não houver.
+func Code(err error) string {
+	var localizable Localizable
+	if errors.As(err, &localizable) {
+		return localizable.MessageCode()
+	}
+	return ""
+}
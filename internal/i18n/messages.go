@@ -0,0 +1,1126 @@
+package i18n
+
+// Códigos das mensagens da API. São estáveis e devolvidos ao cliente no campo "code" das respostas de
+// erro, permitindo tratar falhas sem depender do texto.
+const (
+	// Requisição e parâmetros
+	CodeBadRequest         = "bad_request"    // Genérico, para erros sem código próprio
+	CodeInternalError      = "internal_error" // Genérico, para erros sem código próprio
+	CodeAdminRequired      = "admin_required"
+	CodeInvalidRequestBody = "invalid_request_body"
+	CodeRequestTooLarge    = "request_too_large"
+	CodeFieldRequired      = "field_required"
+	CodeFieldsRequired     = "fields_required"
+	CodeInvalidParam       = "invalid_param"
+	CodeParamRequired      = "param_required"
+	CodeParamMin           = "param_min"
+	CodeParamMax           = "param_max"
+	CodeParamOneOf         = "param_one_of"
+	CodeParamPhotoOrder    = "param_photo_order"
+	CodeParamFormat        = "param_format"
+	CodeParamInvalid       = "param_invalid"
+	CodeInvalidAlbumID     = "invalid_album_id"
+	CodeInvalidPhotoID     = "invalid_photo_id"
+	CodeInvalidPolicyID    = "invalid_policy_id"
+	CodeInvalidImportID    = "invalid_import_id"
+	CodeInvalidVolumeID    = "invalid_volume_id"
+	CodeInvalidEventID     = "invalid_event_id"
+	CodeInvalidStartDate   = "invalid_start_date"
+	CodeInvalidEndDate     = "invalid_end_date"
+	CodeGraphQLQueryEmpty  = "graphql_query_required"
+
+	// Fotos
+	CodePhotoNotFound         = "photo_not_found"
+	CodePhotosNotFound        = "photos_not_found"
+	CodePhotoLocked           = "photo_locked"
+	CodePhotoDuplicate        = "photo_duplicate"
+	CodePhotoQuarantined      = "photo_quarantined"
+	CodePhotoNotImage         = "photo_not_image"
+	CodePhotoNotVideo         = "photo_not_video"
+	CodePhotosFetchFailed     = "photos_fetch_failed"
+	CodePhotoFetchFailed      = "photo_fetch_failed"
+	CodePhotoUpdateFailed     = "photo_update_failed"
+	CodePhotoDeleteFailed     = "photo_delete_failed"
+	CodePhotoDeleted          = "photo_deleted"
+	CodePhotoFileMissing      = "photo_file_missing"
+	CodeOriginalNotStored     = "original_not_stored"
+	CodeRandomPhotosFailed    = "random_photos_failed"
+	CodePopularPhotosFailed   = "popular_photos_failed"
+	CodeTimelineFailed        = "timeline_failed"
+	CodeThumbnailUnavailable  = "thumbnail_unavailable"
+	CodeThumbnailFailed       = "thumbnail_failed"
+	CodeThumbnailBusy         = "thumbnail_busy"
+	CodeVideoUnsupported      = "video_unsupported"
+	CodeTranscodingDisabled   = "transcoding_disabled"
+	CodeTranscodingFailed     = "transcoding_failed"
+	CodeInvalidHLSName        = "invalid_hls_name"
+	CodeSegmentNotFound       = "segment_not_found"
+	CodeMultipartInvalid      = "multipart_invalid"
+	CodeUploadNoFiles         = "upload_no_files"
+	CodeChecksumHeaderSingle  = "checksum_header_single"
+	CodeChecksumCountMismatch = "checksum_count_mismatch"
+	CodeChecksumInvalid       = "checksum_invalid"
+	CodeUploadInterrupted     = "upload_interrupted"
+	CodeUploadPartial         = "upload_partial"
+	CodeUploadSucceeded       = "upload_succeeded"
+
+	// Validação de arquivos (ver pacote validation)
+	CodeFileTooLarge       = "file_too_large"
+	CodeUnsupportedType    = "unsupported_type"
+	CodeUnreadableImage    = "unreadable_image"
+	CodeDimensionsTooLarge = "dimensions_too_large"
+	CodeDecompressionBomb  = "decompression_bomb"
+	CodeMalwareDetected    = "malware_detected"
+	CodeChecksumMismatch   = "checksum_mismatch"
+	CodeHookRejected       = "hook_rejected"
+	CodeHookFailed         = "hook_failed"
+
+	// Armazenamento e volumes
+	CodeStorageUnavailable   = "storage_unavailable"
+	CodeNoVolumeAvailable    = "no_volume_available"
+	CodeInsufficientStorage  = "insufficient_storage"
+	CodeVolumeOffline        = "volume_offline"
+	CodeVolumesFetchFailed   = "volumes_fetch_failed"
+	CodeVolumeFieldsRequired = "volume_fields_required"
+	CodeVolumeDateRange      = "volume_date_range"
+	CodeVolumeRegisterFailed = "volume_register_failed"
+	CodeVolumeNotFound       = "volume_not_found"
+	CodeVolumeDefault        = "volume_default"
+	CodeVolumeNotEmpty       = "volume_not_empty"
+	CodeVolumeDeleteFailed   = "volume_delete_failed"
+	CodeVolumeDeleted        = "volume_deleted"
+
+	// Álbuns
+	CodeAlbumNotFound          = "album_not_found"
+	CodeAlbumLocked            = "album_locked"
+	CodeAlbumNameRequired      = "album_name_required"
+	CodeAlbumsFetchFailed      = "albums_fetch_failed"
+	CodeAlbumPhotosFetchFailed = "album_photos_fetch_failed"
+	CodeAlbumCreateFailed      = "album_create_failed"
+	CodeAlbumCreatedFetch      = "album_created_fetch_failed"
+	CodeAlbumFilterFailed      = "album_filter_failed"
+	CodeAlbumPhotoIDsEmpty     = "album_photo_ids_empty"
+	CodeAlbumPhotosAdded       = "album_photos_added"
+	CodeAlbumPhotoRemoved      = "album_photo_removed"
+
+	// Políticas e cotas de álbuns
+	CodePolicyNotFound      = "policy_not_found"
+	CodePolicyNotConfirmed  = "policy_not_confirmed"
+	CodePolicyNameRequired  = "policy_name_required"
+	CodePolicyInvalidAction = "policy_invalid_action"
+	CodePolicyInvalidAge    = "policy_invalid_age"
+	CodePolicyPreviewFailed = "policy_preview_failed"
+	CodePolicyExecuteFailed = "policy_execute_failed"
+	CodePolicyRemoved       = "policy_removed"
+	CodeSoftQuotaInvalid    = "soft_quota_invalid"
+
+	// Tags, eventos, compartilhamento, importação, duplicatas e estatísticas
+	CodeTagsFetchFailed         = "tags_fetch_failed"
+	CodeTagsInvalid             = "tags_invalid"
+	CodeTagsUpdateFailed        = "tags_update_failed"
+	CodeEventsFailed            = "events_failed"
+	CodeEventNotFound           = "event_not_found"
+	CodeShareNotFound           = "share_not_found"
+	CodeShareFetchFailed        = "share_fetch_failed"
+	CodeImportStartFailed       = "import_start_failed"
+	CodeImportNotFound          = "import_not_found"
+	CodeImportFetchFailed       = "import_fetch_failed"
+	CodeImportNotDirectory      = "import_not_directory"
+	CodeDuplicatesFailed        = "duplicates_failed"
+	CodeDuplicatesReclaimFailed = "duplicates_reclaim_failed"
+	CodeDuplicatePolicyInvalid  = "duplicate_policy_invalid"
+	CodeDuplicateThreshold      = "duplicate_threshold_invalid"
+	CodeDuplicateNearInvalid    = "duplicate_near_invalid"
+	CodeStatsFailed             = "stats_failed"
+	CodeCameraStatsFailed       = "camera_stats_failed"
+	CodeLensStatsFailed         = "lens_stats_failed"
+
+	// Espelhamento de origens remotas
+	CodeInvalidMirrorID             = "invalid_mirror_id"
+	CodeMirrorNotFound              = "mirror_not_found"
+	CodeMirrorsFetchFailed          = "mirrors_fetch_failed"
+	CodeMirrorCreateFailed          = "mirror_create_failed"
+	CodeMirrorDeleteFailed          = "mirror_delete_failed"
+	CodeMirrorDeleted               = "mirror_deleted"
+	CodeMirrorRunning               = "mirror_running"
+	CodeMirrorNameRequired          = "mirror_name_required"
+	CodeMirrorKindInvalid           = "mirror_kind_invalid"
+	CodeMirrorIntervalInvalid       = "mirror_interval_invalid"
+	CodeMirrorPathRequired          = "mirror_path_required"
+	CodeMirrorBucketRequired        = "mirror_bucket_required"
+	CodeMirrorCredentialsIncomplete = "mirror_credentials_incomplete"
+
+	// Auditoria de privacidade
+	CodePrivacyFieldsInvalid = "privacy_fields_invalid"
+	CodePrivacyAuditFailed   = "privacy_audit_failed"
+	CodePrivacyPhotoExternal = "privacy_photo_external"
+
+	// Miniaturas
+	CodeThumbnailBackfillStarted = "thumbnail_backfill_started"
+
+	// Procedência das fotos
+	CodeUploadSourceInvalid = "upload_source_invalid"
+	CodeUploadDeviceTooLong = "upload_device_too_long"
+	CodePhotoSourcesFailed  = "photo_sources_failed"
+
+	// Renomear e mesclar tags
+	CodeInvalidTagID    = "invalid_tag_id"
+	CodeTagNotFound     = "tag_not_found"
+	CodeTagNameInvalid  = "tag_name_invalid"
+	CodeTagNameConflict = "tag_name_conflict"
+	CodeTagMergeInvalid = "tag_merge_invalid"
+	CodeTagPhotosLocked = "tag_photos_locked"
+	CodeTagRenameFailed = "tag_rename_failed"
+	CodeTagMergeFailed  = "tag_merge_failed"
+
+	// Linguagem de busca (GET /photos/search?q=)
+	CodeSearchUnknownKey          = "search_unknown_key"
+	CodeSearchInvalidValue        = "search_invalid_value"
+	CodeSearchNegationUnsupported = "search_negation_unsupported"
+	CodeSearchUnclosedQuote       = "search_unclosed_quote"
+	CodeSearchMonthNeedsYear      = "search_month_needs_year"
+
+	// Manutenção de álbuns
+	CodeAlbumHealthFailed   = "album_health_failed"
+	CodeAlbumCleanupFailed  = "album_cleanup_failed"
+	CodeAlbumCleanupInvalid = "album_cleanup_invalid"
+
+	// Metadados completos da foto (GET /photos/:id/exif)
+	CodePhotoExifVideo  = "photo_exif_video"
+	CodePhotoExifFailed = "photo_exif_failed"
+
+	// Busca por hash
+	CodeHashInvalid      = "hash_invalid"
+	CodeHashesInvalid    = "hashes_invalid"
+	CodeHashNotFound     = "hash_not_found"
+	CodeHashLookupFailed = "hash_lookup_failed"
+
+	// Regras de organização
+	CodeRulesInvalid = "rules_invalid"
+
+	// Simulação de upload
+	CodeFilenameTaken       = "filename_taken"
+	CodeUploadPreviewFailed = "upload_preview_failed"
+
+	// Álbuns padrão por origem
+	CodeSourceAlbumNotFound   = "source_album_not_found"
+	CodeSourceAlbumsFailed    = "source_albums_failed"
+	CodeSourceAlbumSaveFailed = "source_album_save_failed"
+	CodeInvalidSourceAlbumID  = "invalid_source_album_id"
+	CodeSourceAlbumRemoved    = "source_album_removed"
+
+	// Importação de álbuns em ZIP
+	CodeZipInvalid        = "zip_invalid"
+	CodeZipTooManyEntries = "zip_too_many_entries"
+	CodeZipRequired       = "zip_required"
+	CodeZipImportFailed   = "zip_import_failed"
+
+	// Comparação de fotos
+	CodePhotoCompareFailed = "photo_compare_failed"
+
+	// Importação de bibliotecas de outros gerenciadores
+	CodeImportFormatInvalid  = "import_format_invalid"
+	CodeImportLibraryInvalid = "import_library_invalid"
+
+	// Exportação completa da biblioteca
+	CodeInvalidExportID      = "invalid_export_id"
+	CodeExportNotFound       = "export_not_found"
+	CodeExportFetchFailed    = "export_fetch_failed"
+	CodeExportStartFailed    = "export_start_failed"
+	CodeExportNotReady       = "export_not_ready"
+	CodeExportArchiveMissing = "export_archive_missing"
+
+	// Seleção de campos (?fields=)
+	CodeFieldsUnknown = "fields_unknown"
+
+	// Reações em álbuns compartilhados
+	CodeReactionKindInvalid    = "reaction_kind_invalid"
+	CodeReactionVoterInvalid   = "reaction_voter_invalid"
+	CodeReactionPhotoNotShared = "reaction_photo_not_shared"
+	CodeReactionFailed         = "reaction_failed"
+	CodeReactionListFailed     = "reaction_list_failed"
+
+	// Verificação de impressão
+	CodePrintSizeInvalid       = "print_size_invalid"
+	CodePrintCheckNoDimensions = "print_check_no_dimensions"
+
+	// Busca e substituição em lote
+	CodeTextReplaceInvalid = "text_replace_invalid"
+	CodeTextReplaceFailed  = "text_replace_failed"
+
+	// Galeria pública
+	CodeRateLimited      = "rate_limited"
+	CodeHotlinkForbidden = "hotlink_forbidden"
+
+	// Workers em segundo plano
+	CodeImportQueueFull = "import_queue_full"
+
+	// Manutenção do banco
+	CodeDatabaseMaintenanceBusy   = "database_maintenance_busy"
+	CodeDatabaseBackupNotFound    = "database_backup_not_found"
+	CodeDatabaseBackupFailed      = "database_backup_failed"
+	CodeDatabaseMaintenanceFailed = "database_maintenance_failed"
+	CodeDatabaseStatsFailed       = "database_stats_failed"
+
+	// Reconciliação de EXIF
+	CodeReconcileInvalid      = "reconcile_invalid"
+	CodeReconcileFailed       = "reconcile_failed"
+	CodeMetadataChangesFailed = "metadata_changes_failed"
+
+	// Relações entre fotos
+	CodeRelationKindInvalid = "relation_kind_invalid"
+	CodeRelationSelf        = "relation_self"
+	CodeRelationExists      = "relation_exists"
+	CodeRelationNotFound    = "relation_not_found"
+	CodeRelationFailed      = "relation_failed"
+	CodeRelationListFailed  = "relation_list_failed"
+	CodeRelationRemoved     = "relation_removed"
+
+	// Detecção de panoramas
+	CodeProjectionDetectFailed = "projection_detect_failed"
+
+	// Imagens auxiliares (profundidade, mapa de ganho)
+	CodeAuxiliaryDetectFailed = "auxiliary_detect_failed"
+
+	// Fuso horário da linha do tempo
+	CodeInvalidTimeZone = "invalid_time_zone"
+
+	// Concorrência otimista
+	CodePhotoVersionConflict = "photo_version_conflict"
+
+	// Remoção de álbuns
+	CodeAlbumDeleteConfirmationRequired = "album_delete_confirmation_required"
+	CodeAlbumDeleted                    = "album_deleted"
+	CodeAlbumDeleteFailed               = "album_delete_failed"
+
+	// Relatórios de importação
+	CodeImportFileSkipped        = "import_file_skipped"
+	CodeInvalidImportReportID    = "invalid_import_report_id"
+	CodeImportReportNotFound     = "import_report_not_found"
+	CodeImportReportsFetchFailed = "import_reports_fetch_failed"
+
+	// Verificação de divergências entre o armazenamento e o banco
+	CodeStorageDriftScanFailed       = "storage_drift_scan_failed"
+	CodeStorageDriftReconcileInvalid = "storage_drift_reconcile_invalid"
+	CodeStorageDriftReconcileFailed  = "storage_drift_reconcile_failed"
+
+	// Planos de ação das operações administrativas destrutivas
+	CodeInvalidPlanID     = "invalid_plan_id"
+	CodePlanNotFound      = "plan_not_found"
+	CodePlanNotPending    = "plan_not_pending"
+	CodePlanExpired       = "plan_expired"
+	CodePlansFetchFailed  = "plans_fetch_failed"
+	CodePlanCreateFailed  = "plan_create_failed"
+	CodePlanExecuteFailed = "plan_execute_failed"
+	CodePlanPhotoChanged  = "plan_photo_changed"
+
+	// Políticas de duplicatas por origem
+	CodeDuplicatePolicyNotFound        = "duplicate_policy_not_found"
+	CodeDuplicatePoliciesFailed        = "duplicate_policies_failed"
+	CodeDuplicatePolicySaveFailed      = "duplicate_policy_save_failed"
+	CodeInvalidDuplicatePolicyID       = "invalid_duplicate_policy_id"
+	CodeDuplicatePolicyRemoved         = "duplicate_policy_removed"
+	CodeSourceDuplicatePolicyInvalid   = "source_duplicate_policy_invalid"
+	CodeDuplicatePolicyAlbumNotAllowed = "duplicate_policy_album_not_allowed"
+	CodePhotoDuplicateSkipped          = "photo_duplicate_skipped"
+
+	// Anotações de regiões das fotos
+	CodeAnnotationNotFound      = "annotation_not_found"
+	CodeInvalidAnnotationID     = "invalid_annotation_id"
+	CodeAnnotationRegionInvalid = "annotation_region_invalid"
+	CodeAnnotationTextRequired  = "annotation_text_required"
+	CodeAnnotationFailed        = "annotation_failed"
+	CodeAnnotationsFetchFailed  = "annotations_fetch_failed"
+	CodeAnnotationRemoved       = "annotation_removed"
+
+	// Modelos de álbum
+	CodeAlbumTemplateNotFound        = "album_template_not_found"
+	CodeInvalidAlbumTemplateID       = "invalid_album_template_id"
+	CodeAlbumTemplateInvalid         = "album_template_invalid"
+	CodeAlbumTemplateExists          = "album_template_exists"
+	CodeAlbumTemplateVariableMissing = "album_template_variable_missing"
+	CodeAlbumTemplatesFailed         = "album_templates_failed"
+	CodeAlbumTemplateSaveFailed      = "album_template_save_failed"
+	CodeAlbumTemplateRemoved         = "album_template_removed"
+	CodeAlbumFromTemplateFailed      = "album_from_template_failed"
+
+	CodeAlbumTemplateDateInvalid = "album_template_date_invalid"
+
+	// Download de seleções de fotos
+	CodeDownloadSelectionEmpty = "download_selection_empty"
+	CodeDownloadEstimateFailed = "download_estimate_failed"
+	CodeDownloadStartFailed    = "download_start_failed"
+	CodeInvalidDownloadID      = "invalid_download_id"
+	CodeDownloadNotFound       = "download_not_found"
+	CodeDownloadFetchFailed    = "download_fetch_failed"
+	CodeDownloadNotReady       = "download_not_ready"
+	CodeDownloadArchiveMissing = "download_archive_missing"
+
+	// Configuração
+	CodeConfigReloadFailed = "config_reload_failed"
+
+	// Seleções de fotos (cestas)
+	CodeSelectionNotFound      = "selection_not_found"
+	CodeSelectionEmpty         = "selection_empty"
+	CodeSelectionPhotosInvalid = "selection_photos_invalid"
+	CodeSelectionFetchFailed   = "selection_fetch_failed"
+	CodeSelectionUpdateFailed  = "selection_update_failed"
+	CodeSelectionActionFailed  = "selection_action_failed"
+	CodeSelectionAlbumTarget   = "selection_album_target"
+	CodeSelectionRemoved       = "selection_removed"
+	CodeSelectionPhotosTagged  = "selection_photos_tagged"
+
+	// Data aproximada
+	CodeCircaDateInvalid = "circa_date_invalid"
+
+	// Assinatura de álbuns compartilhados
+	CodeMirrorShareURLInvalid = "mirror_share_url_invalid"
+
+	// Geolocalização por trilha GPS
+	CodeGeotagTrackRequired   = "geotag_track_required"
+	CodeGeotagTrackInvalid    = "geotag_track_invalid"
+	CodeGeotagTimeZoneInvalid = "geotag_time_zone_invalid"
+	CodeGeotagOptionsInvalid  = "geotag_options_invalid"
+	CodeGeotagFailed          = "geotag_failed"
+
+	// Campos personalizados dos álbuns
+	CodeAlbumMetadataInvalid      = "album_metadata_invalid"
+	CodeAlbumMetadataKeyInvalid   = "album_metadata_key_invalid"
+	CodeAlbumMetadataTooLarge     = "album_metadata_too_large"
+	CodeAlbumMetadataDuplicateKey = "album_metadata_duplicate_key"
+	CodeAlbumMetadataUpdateFailed = "album_metadata_update_failed"
+
+	// Versionamento da API
+	CodeAPIVersionUnsupported = "api_version_unsupported"
+
+	// Alterações nos arquivos das fotos indexadas no local
+	CodeSourceChangesInvalid     = "source_changes_invalid"
+	CodeSourceChangesNotExternal = "source_changes_not_external"
+	CodeSourceChangesFailed      = "source_changes_failed"
+
+	// Regiões XMP (MWG) das anotações
+	CodeAnnotationKindInvalid = "annotation_kind_invalid"
+	CodeAnnotationXMPInvalid  = "annotation_xmp_invalid"
+	CodeAnnotationXMPFailed   = "annotation_xmp_failed"
+
+	// Assistente de correção de datas
+	CodeDateFixInvalid = "date_fix_invalid"
+	CodeDateFixFailed  = "date_fix_failed"
+
+	// Transcodificação de vídeo em segundo plano
+	CodeTranscodingPending = "transcoding_pending"
+)
+
+// portuguese é o catálogo em português (idioma padrão).
+var portuguese = map[string]string{
+	CodeBadRequest:         "Requisição inválida",
+	CodeInternalError:      "Erro interno",
+	CodeAdminRequired:      "Operação restrita ao administrador.",
+	CodeInvalidRequestBody: "Corpo da requisição inválido.",
+	CodeRequestTooLarge:    "Corpo da requisição maior que o limite de %d MB.",
+	CodeFieldRequired:      "O campo '%s' é obrigatório.",
+	CodeFieldsRequired:     "Campos obrigatórios ausentes ou inválidos: %s.",
+	CodeInvalidParam:       "Parâmetro '%s' inválido: %s.",
+	CodeParamRequired:      "é obrigatório",
+	CodeParamMin:           "deve ser no mínimo %s",
+	CodeParamMax:           "deve ser no máximo %s",
+	CodeParamOneOf:         "use um dos valores: %s",
+	CodeParamPhotoOrder:    "use 'campo', 'campo ASC' ou 'campo DESC' com um dos campos: %s",
+	CodeParamFormat:        "valor em formato inválido",
+	CodeParamInvalid:       "valor inválido",
+	CodeInvalidAlbumID:     "ID de álbum inválido.",
+	CodeInvalidPhotoID:     "ID de foto inválido.",
+	CodeInvalidPolicyID:    "ID de política inválido.",
+	CodeInvalidImportID:    "ID de importação inválido.",
+	CodeInvalidVolumeID:    "ID de volume inválido.",
+	CodeInvalidEventID:     "ID de evento inválido.",
+	CodeInvalidStartDate:   "Data inicial inválida. Use o formato AAAA-MM-DD.",
+	CodeInvalidEndDate:     "Data final inválida. Use o formato AAAA-MM-DD.",
+	CodeGraphQLQueryEmpty:  "Informe a consulta GraphQL no campo 'query'.",
+
+	CodePhotoNotFound:         "Foto não encontrada.",
+	CodePhotosNotFound:        "uma ou mais fotos não existem",
+	CodePhotoLocked:           "A foto está bloqueada. Desbloqueie-a antes de alterá-la ou removê-la.",
+	CodePhotoDuplicate:        "foto duplicada detectada",
+	CodePhotoQuarantined:      "foto em quarentena",
+	CodePhotoNotImage:         "o arquivo não é uma imagem",
+	CodePhotoNotVideo:         "A foto não é um vídeo.",
+	CodePhotosFetchFailed:     "Erro ao buscar fotos",
+	CodePhotoFetchFailed:      "Erro ao buscar foto",
+	CodePhotoUpdateFailed:     "Erro ao atualizar foto",
+	CodePhotoDeleteFailed:     "Erro ao remover foto",
+	CodePhotoDeleted:          "Foto removida com sucesso.",
+	CodePhotoFileMissing:      "Arquivo da foto não encontrado no armazenamento.",
+	CodeOriginalNotStored:     "O original desta foto não foi guardado.",
+	CodeRandomPhotosFailed:    "Erro ao buscar fotos aleatórias",
+	CodePopularPhotosFailed:   "Erro ao buscar fotos populares",
+	CodeTimelineFailed:        "Erro ao buscar linha do tempo",
+	CodeThumbnailUnavailable:  "Não é possível gerar a miniatura desta foto",
+	CodeThumbnailFailed:       "Erro ao obter miniatura",
+	CodeThumbnailBusy:         "Miniatura em geração; tente novamente em instantes.",
+	CodeVideoUnsupported:      "O formato do vídeo não é suportado pelo navegador e a transcodificação está desativada.",
+	CodeTranscodingDisabled:   "A transcodificação de vídeos está desativada.",
+	CodeTranscodingFailed:     "Erro ao transcodificar vídeo",
+	CodeInvalidHLSName:        "Nome de arquivo HLS inválido.",
+	CodeSegmentNotFound:       "Segmento não encontrado.",
+	CodeMultipartInvalid:      "Não foi possível ler o formulário multipart",
+	CodeUploadNoFiles:         "Nenhum arquivo 'photos' encontrado no formulário.",
+	CodeChecksumHeaderSingle:  "O cabeçalho X-Content-SHA256 só pode ser usado com um único arquivo; para lotes, use os campos 'sha256'.",
+	CodeChecksumCountMismatch: "Foram informados %d campos 'sha256' para %d arquivos.",
+	CodeChecksumInvalid:       "SHA-256 inválido: '%s' (esperado 64 caracteres hexadecimais).",
+	CodeUploadInterrupted:     "Upload interrompido",
+	CodeUploadPartial:         "Algumas fotos foram processadas com erros.",
+	CodeUploadSucceeded:       "Uploads processados com sucesso!",
+
+	CodeFileTooLarge:       "Tamanho do arquivo excede o limite de %dMB",
+	CodeUnsupportedType:    "Tipo de arquivo não permitido (%s). Apenas %s",
+	CodeUnreadableImage:    "Não foi possível ler o cabeçalho da imagem: %v",
+	CodeDimensionsTooLarge: "Dimensões da imagem (%dx%d) excedem o limite de %dpx",
+	CodeDecompressionBomb:  "A imagem declara %d pixels, acima do limite de %d",
+	CodeMalwareDetected:    "Arquivo rejeitado pelo antivírus: %s",
+	CodeChecksumMismatch:   "SHA-256 do arquivo recebido (%s) difere do informado (%s)",
+	CodeHookRejected:       "Arquivo rejeitado pelo hook de pré-ingestão: %s",
+	CodeHookFailed:         "Hook de pré-ingestão indisponível: %v",
+
+	CodeStorageUnavailable:   "Não há espaço de armazenamento disponível",
+	CodeNoVolumeAvailable:    "nenhum volume de armazenamento disponível",
+	CodeInsufficientStorage:  "espaço em disco insuficiente",
+	CodeVolumeOffline:        "O volume de armazenamento '%s' está offline.",
+	CodeVolumesFetchFailed:   "Erro ao buscar volumes",
+	CodeVolumeFieldsRequired: "nome e caminho do volume são obrigatórios",
+	CodeVolumeDateRange:      "o fim do intervalo de datas deve ser posterior ao início",
+	CodeVolumeRegisterFailed: "Não foi possível registrar o volume",
+	CodeVolumeNotFound:       "Volume não encontrado.",
+	CodeVolumeDefault:        "o volume padrão não pode ser removido",
+	CodeVolumeNotEmpty:       "o volume '%s' ainda contém %d fotos",
+	CodeVolumeDeleteFailed:   "Não foi possível remover o volume",
+	CodeVolumeDeleted:        "Volume removido com sucesso.",
+
+	CodeAlbumNotFound:          "Álbum ou foto não encontrado.",
+	CodeAlbumLocked:            "O álbum está bloqueado. Desbloqueie-o antes de alterá-lo.",
+	CodeAlbumNameRequired:      "o nome do álbum é obrigatório",
+	CodeAlbumsFetchFailed:      "Erro ao buscar álbuns",
+	CodeAlbumPhotosFetchFailed: "Erro ao buscar fotos do álbum",
+	CodeAlbumCreateFailed:      "Não foi possível criar o álbum",
+	CodeAlbumCreatedFetch:      "Erro ao buscar álbum criado",
+	CodeAlbumFilterFailed:      "Erro ao aplicar o filtro",
+	CodeAlbumPhotoIDsEmpty:     "O campo 'photo_ids' deve conter ao menos um ID.",
+	CodeAlbumPhotosAdded:       "%d foto(s) adicionada(s) ao álbum.",
+	CodeAlbumPhotoRemoved:      "Foto removida do álbum.",
+
+	CodePolicyNotFound:      "Política não encontrada.",
+	CodePolicyNotConfirmed:  "a política ainda não foi confirmada; revise a prévia e confirme-a antes de executá-la",
+	CodePolicyNameRequired:  "o nome da política é obrigatório",
+	CodePolicyInvalidAction: "ação inválida: '%s' (use '%s' ou '%s')",
+	CodePolicyInvalidAge:    "a idade mínima das fotos deve ser de pelo menos 1 dia",
+	CodePolicyPreviewFailed: "Erro ao calcular a prévia",
+	CodePolicyExecuteFailed: "Erro ao executar a política",
+	CodePolicyRemoved:       "Política removida.",
+	CodeSoftQuotaInvalid:    "O campo 'soft_quota_mb' é obrigatório e não pode ser negativo.",
+
+	CodeTagsFetchFailed:         "Erro ao buscar tags",
+	CodeTagsInvalid:             "O campo 'tags' deve ser uma lista de textos.",
+	CodeTagsUpdateFailed:        "Erro ao atualizar tags",
+	CodeEventsFailed:            "Erro ao detectar eventos",
+	CodeEventNotFound:           "Evento não encontrado.",
+	CodeShareNotFound:           "Link de compartilhamento não encontrado.",
+	CodeShareFetchFailed:        "Erro ao buscar álbum compartilhado",
+	CodeImportStartFailed:       "Não foi possível iniciar a importação",
+	CodeImportNotFound:          "Importação não encontrada.",
+	CodeImportFetchFailed:       "Erro ao buscar importação",
+	CodeImportNotDirectory:      "o caminho de origem '%s' não é um diretório",
+	CodeDuplicatesFailed:        "Erro ao buscar duplicatas",
+	CodeDuplicatesReclaimFailed: "Erro ao remover duplicatas",
+	CodeDuplicatePolicyInvalid:  "Política '%s' inválida: use '%s', '%s' ou '%s'.",
+	CodeDuplicateThreshold:      "Valor de 'threshold' inválido: use de 0 a %d.",
+	CodeDuplicateNearInvalid:    "Valor de 'near' inválido.",
+	CodeStatsFailed:             "Erro ao calcular estatísticas",
+	CodeCameraStatsFailed:       "Erro ao calcular estatísticas de câmeras",
+	CodeLensStatsFailed:         "Erro ao calcular estatísticas de lentes",
+
+	CodeInvalidMirrorID:             "ID de origem inválido.",
+	CodeMirrorNotFound:              "Origem não encontrada.",
+	CodeMirrorsFetchFailed:          "Erro ao buscar origens",
+	CodeMirrorCreateFailed:          "Não foi possível registrar a origem",
+	CodeMirrorDeleteFailed:          "Não foi possível remover a origem",
+	CodeMirrorDeleted:               "Origem removida. As fotos importadas foram mantidas.",
+	CodeMirrorRunning:               "a origem já está sendo sincronizada",
+	CodeMirrorNameRequired:          "o nome da origem é obrigatório",
+	CodeMirrorKindInvalid:           "tipo de origem inválido: '%s' (use '%s', '%s' ou '%s')",
+	CodeMirrorIntervalInvalid:       "o intervalo de sincronização não pode ser negativo",
+	CodeMirrorPathRequired:          "o diretório da origem é obrigatório",
+	CodeMirrorBucketRequired:        "o bucket da origem é obrigatório",
+	CodeMirrorCredentialsIncomplete: "informe access_key e secret_key juntos (ou nenhum, para buckets públicos)",
+
+	CodePrivacyFieldsInvalid: "Campo de privacidade inválido: '%s' (use gps, serial ou owner).",
+	CodePrivacyAuditFailed:   "Erro ao auditar a biblioteca",
+	CodePrivacyPhotoExternal: "a foto é indexada no local; o arquivo original não é alterado",
+
+	CodeThumbnailBackfillStarted: "Geração das miniaturas pendentes iniciada.",
+
+	CodeUploadSourceInvalid: "Canal de origem inválido: '%s' (use %s).",
+	CodeUploadDeviceTooLong: "Nome do dispositivo muito longo (máximo de %d caracteres).",
+	CodePhotoSourcesFailed:  "Erro ao listar as procedências das fotos",
+
+	CodeInvalidTagID:    "ID de tag inválido.",
+	CodeTagNotFound:     "Tag não encontrada.",
+	CodeTagNameInvalid:  "Informe um nome de tag não vazio em 'name'.",
+	CodeTagNameConflict: "Já existe a tag '%s' (id %d). Use POST /tags/merge para mesclá-las.",
+	CodeTagMergeInvalid: "Informe 'source_ids' (tags a mesclar) e 'target_id' (tag mantida), diferentes entre si.",
+	CodeTagPhotosLocked: "%d foto(s) com a tag estão bloqueadas. Desbloqueie-as antes de renomear ou mesclar a tag.",
+	CodeTagRenameFailed: "Erro ao renomear a tag",
+	CodeTagMergeFailed:  "Erro ao mesclar as tags",
+
+	CodeSearchUnknownKey:          "filtro desconhecido '%s' (use: %s)",
+	CodeSearchInvalidValue:        "valor inválido em '%s'",
+	CodeSearchNegationUnsupported: "o filtro '%s' não pode ser negado com '-'",
+	CodeSearchUnclosedQuote:       "aspas não fechadas na busca",
+	CodeSearchMonthNeedsYear:      "o filtro 'month' requer também 'year'",
+
+	CodeAlbumHealthFailed:   "Erro ao diagnosticar os álbuns",
+	CodeAlbumCleanupFailed:  "Erro na limpeza dos álbuns",
+	CodeAlbumCleanupInvalid: "Corpo inválido: use {\"orphan_links\": bool, \"empty_albums\": bool, \"empty_older_than_days\": inteiro não negativo, \"dry_run\": bool}.",
+
+	CodePhotoExifVideo:  "Vídeos não têm metadados EXIF.",
+	CodePhotoExifFailed: "Erro ao ler os metadados da foto",
+
+	CodeHashInvalid:      "Hash inválido: '%s' (use o MD5 do arquivo, 32 dígitos hexadecimais, ou o SHA-256 dos pixels, 64).",
+	CodeHashesInvalid:    "Corpo inválido: use {\"hashes\": [...]} com 1 a %d hashes.",
+	CodeHashNotFound:     "Nenhuma foto com este hash.",
+	CodeHashLookupFailed: "Erro ao buscar fotos por hash",
+
+	CodeRulesInvalid: "Regras de organização inválidas: %s",
+
+	CodeFilenameTaken:       "Já existe uma foto com o nome '%s'.",
+	CodeUploadPreviewFailed: "Erro ao simular o upload",
+
+	CodeSourceAlbumNotFound:   "Álbum padrão não encontrado.",
+	CodeSourceAlbumsFailed:    "Erro ao buscar os álbuns padrão",
+	CodeSourceAlbumSaveFailed: "Erro ao gravar o álbum padrão",
+
+	CodeInvalidSourceAlbumID: "ID de álbum padrão inválido.",
+
+	CodeSourceAlbumRemoved: "Álbum padrão removido com sucesso.",
+
+	CodeZipInvalid:        "Arquivo ZIP inválido ou corrompido",
+	CodeZipTooManyEntries: "O arquivo ZIP tem mais de %d entradas.",
+	CodeZipRequired:       "Envie um arquivo ZIP no campo 'archive'.",
+	CodeZipImportFailed:   "Erro ao importar o arquivo ZIP",
+
+	CodePhotoCompareFailed: "Erro ao comparar as fotos",
+
+	CodeImportFormatInvalid:  "formato de importação desconhecido '%s' (use photoprism ou immich)",
+	CodeImportLibraryInvalid: "'%s' não contém uma biblioteca do formato informado: %s",
+
+	CodeInvalidExportID:      "ID de exportação inválido.",
+	CodeExportNotFound:       "Exportação não encontrada.",
+	CodeExportFetchFailed:    "Erro ao buscar exportação",
+	CodeExportStartFailed:    "Não foi possível iniciar a exportação",
+	CodeExportNotReady:       "A exportação ainda não terminou; consulte o status e tente novamente.",
+	CodeExportArchiveMissing: "O arquivo desta exportação não está mais disponível; inicie uma nova com GET /me/export?new=true.",
+
+	CodeFieldsUnknown: "campos desconhecidos: %s (disponíveis: %s)",
+
+	CodeReactionKindInvalid:    "Tipo de reação inválido (use thumbs_up ou heart).",
+	CodeReactionVoterInvalid:   "Informe o seu nome (até 64 caracteres) para reagir.",
+	CodeReactionPhotoNotShared: "A foto não faz parte do álbum compartilhado.",
+	CodeReactionFailed:         "Erro ao registrar a reação",
+	CodeReactionListFailed:     "Erro ao buscar as fotos mais votadas",
+
+	CodePrintSizeInvalid:       "Tamanho de impressão inválido: '%s' (use LARGURAxALTURA em cm, ex: 10x15, ou com unidade: 4x6in, 130x180mm).",
+	CodePrintCheckNoDimensions: "Não foi possível determinar as dimensões da imagem.",
+
+	CodeTextReplaceInvalid: "Substituição inválida: %s.",
+	CodeTextReplaceFailed:  "Não foi possível executar a substituição.",
+
+	CodeRateLimited:      "Muitas requisições. Tente novamente em instantes.",
+	CodeHotlinkForbidden: "Imagens da galeria não podem ser incorporadas em outros sites.",
+
+	CodeImportQueueFull: "Há importações demais na fila. Aguarde a conclusão de alguma e tente novamente.",
+
+	CodeDatabaseMaintenanceBusy:   "Outra operação de manutenção do banco está em andamento. Tente novamente quando ela terminar.",
+	CodeDatabaseBackupNotFound:    "Cópia de segurança do banco não encontrada.",
+	CodeDatabaseBackupFailed:      "Não foi possível copiar o banco",
+	CodeDatabaseMaintenanceFailed: "Falha na manutenção do banco",
+	CodeDatabaseStatsFailed:       "Não foi possível ler as estatísticas do banco",
+
+	CodeReconcileInvalid:      "Reconciliação inválida: %s.",
+	CodeReconcileFailed:       "Não foi possível reconciliar os metadados",
+	CodeMetadataChangesFailed: "Não foi possível buscar o histórico de alterações",
+
+	CodeRelationKindInvalid: "Tipo de relação inválido: '%s' (use %s).",
+	CodeRelationSelf:        "Uma foto não pode ser relacionada a ela mesma.",
+	CodeRelationExists:      "As fotos já têm essa relação.",
+	CodeRelationNotFound:    "Relação não encontrada.",
+	CodeRelationFailed:      "Erro ao salvar a relação entre as fotos",
+	CodeRelationListFailed:  "Erro ao buscar as relações da foto",
+	CodeRelationRemoved:     "Relação removida.",
+
+	CodeProjectionDetectFailed: "Erro ao detectar os panoramas",
+
+	CodeAuxiliaryDetectFailed: "Erro ao procurar as imagens auxiliares",
+
+	CodeInvalidTimeZone: "Fuso horário inválido: use um nome IANA (ex: America/Sao_Paulo), local ou photo",
+
+	CodePhotoVersionConflict: "A foto foi alterada por outra pessoa desde a versão informada (atual: %s). Revise o estado atual e tente de novo.",
+
+	CodeAlbumDeleteConfirmationRequired: "A remoção apagaria %d foto(s) da biblioteca. Confirme com confirm=<quantidade> para prosseguir.",
+	CodeAlbumDeleted:                    "Álbum removido.",
+	CodeAlbumDeleteFailed:               "Erro ao remover o álbum",
+
+	CodeImportFileSkipped:        "Arquivo ignorado: oculto, de sistema ou com extensão não suportada.",
+	CodeInvalidImportReportID:    "ID de relatório de importação inválido.",
+	CodeImportReportNotFound:     "Relatório de importação não encontrado.",
+	CodeImportReportsFetchFailed: "Erro ao buscar os relatórios de importação",
+
+	CodeStorageDriftScanFailed:       "Não foi possível verificar o armazenamento",
+	CodeStorageDriftReconcileInvalid: "Corpo inválido: use {\"relink\": bool, \"adopt\": bool, \"dry_run\": bool}.",
+	CodeStorageDriftReconcileFailed:  "Não foi possível reconciliar o armazenamento",
+
+	CodeInvalidPlanID:     "ID de plano inválido",
+	CodePlanNotFound:      "Plano não encontrado",
+	CodePlanNotPending:    "O plano já foi executado ou descartado.",
+	CodePlanExpired:       "O plano foi feito há mais de %d horas; faça uma nova simulação.",
+	CodePlansFetchFailed:  "Erro ao buscar os planos",
+	CodePlanCreateFailed:  "Não foi possível gravar o plano",
+	CodePlanExecuteFailed: "Não foi possível executar o plano",
+	CodePlanPhotoChanged:  "A foto foi alterada ou removida desde o plano.",
+
+	CodeDuplicatePolicyNotFound:        "Política de duplicatas não encontrada.",
+	CodeDuplicatePoliciesFailed:        "Erro ao buscar as políticas de duplicatas",
+	CodeDuplicatePolicySaveFailed:      "Erro ao gravar a política de duplicatas",
+	CodeInvalidDuplicatePolicyID:       "ID de política de duplicatas inválido.",
+	CodeDuplicatePolicyRemoved:         "Política de duplicatas removida com sucesso.",
+	CodeSourceDuplicatePolicyInvalid:   "Política de duplicatas inválida: '%s' (use %s).",
+	CodeDuplicatePolicyAlbumNotAllowed: "'album_id' só é aceito na política link.",
+	CodePhotoDuplicateSkipped:          "foto duplicada ignorada pela política da origem",
+
+	CodeAnnotationNotFound:      "Anotação não encontrada.",
+	CodeInvalidAnnotationID:     "ID de anotação inválido.",
+	CodeAnnotationRegionInvalid: "Região inválida: x, y, width e height são frações da imagem (0 a 1), com largura e altura maiores que zero e a região dentro da imagem.",
+	CodeAnnotationTextRequired:  "O texto da anotação é obrigatório (até %d caracteres).",
+	CodeAnnotationFailed:        "Erro ao salvar a anotação",
+	CodeAnnotationsFetchFailed:  "Erro ao buscar as anotações da foto",
+	CodeAnnotationRemoved:       "Anotação removida.",
+
+	CodeAlbumTemplateNotFound:        "Modelo de álbum não encontrado.",
+	CodeInvalidAlbumTemplateID:       "ID de modelo de álbum inválido.",
+	CodeAlbumTemplateInvalid:         "Modelo de álbum inválido: 'name' e 'name_pattern' são obrigatórios e a cota não pode ser negativa.",
+	CodeAlbumTemplateExists:          "Já existe um modelo de álbum chamado '%s'.",
+	CodeAlbumTemplateVariableMissing: "Variável sem valor no padrão do modelo: '{{%s}}'. Informe-a em 'variables'.",
+	CodeAlbumTemplatesFailed:         "Erro ao buscar os modelos de álbum",
+	CodeAlbumTemplateSaveFailed:      "Erro ao gravar o modelo de álbum",
+	CodeAlbumTemplateRemoved:         "Modelo de álbum removido.",
+	CodeAlbumFromTemplateFailed:      "Erro ao criar o álbum a partir do modelo",
+
+	CodeAlbumTemplateDateInvalid: "Data inválida: '%s'. Use o formato AAAA-MM-DD.",
+
+	CodeDownloadSelectionEmpty: "Nenhuma foto selecionada para download; informe 'photo_ids' ou um filtro.",
+	CodeDownloadEstimateFailed: "Não foi possível estimar o download",
+	CodeDownloadStartFailed:    "Não foi possível iniciar o download",
+	CodeInvalidDownloadID:      "ID de download inválido.",
+	CodeDownloadNotFound:       "Download não encontrado.",
+	CodeDownloadFetchFailed:    "Erro ao buscar download",
+	CodeDownloadNotReady:       "O download ainda está sendo preparado; consulte o status e tente novamente.",
+	CodeDownloadArchiveMissing: "O arquivo deste download não está mais disponível; peça um novo com POST /downloads.",
+
+	CodeConfigReloadFailed: "Configuração inválida, recarga ignorada",
+
+	CodeSelectionNotFound:      "Seleção não encontrada ou expirada.",
+	CodeSelectionEmpty:         "A seleção não tem fotos.",
+	CodeSelectionPhotosInvalid: "Informe 'photo_ids' ou um filtro.",
+	CodeSelectionFetchFailed:   "Erro ao buscar a seleção",
+	CodeSelectionUpdateFailed:  "Não foi possível alterar a seleção",
+	CodeSelectionActionFailed:  "Não foi possível executar a ação na seleção",
+	CodeSelectionAlbumTarget:   "Informe 'album_id' (álbum existente) ou 'name' (novo álbum).",
+	CodeSelectionRemoved:       "Seleção descartada.",
+	CodeSelectionPhotosTagged:  "%d foto(s) receberam novas tags.",
+
+	CodeCircaDateInvalid: "Data aproximada inválida. Use AAAA-MM (mês), AAAA (ano) ou AAA0s (década, ex: 1980s).",
+
+	CodeMirrorShareURLInvalid: "informe em 'url' o link de compartilhamento do álbum na outra instância (ex: https://fotos.exemplo.com/s/<token>)",
+
+	CodeGeotagTrackRequired:   "envie a trilha GPS (arquivo GPX ou KML) no campo 'track'",
+	CodeGeotagTrackInvalid:    "trilha GPS inválida: %s",
+	CodeGeotagTimeZoneInvalid: "fuso da câmera inválido: use um nome IANA (ex: Europe/Lisbon) ou um deslocamento (ex: +02:00)",
+	CodeGeotagOptionsInvalid:  "parâmetros inválidos: 'photo_ids' são IDs de fotos, 'clock_offset' é em segundos e 'max_gap_minutes' vai de 1 a 1440",
+	CodeGeotagFailed:          "não foi possível geolocalizar as fotos",
+
+	CodeAlbumMetadataInvalid:      "informe os campos em 'metadata', ex: {\"metadata\": {\"Cliente\": \"Maria\", \"Local\": \"Praia\"}}",
+	CodeAlbumMetadataKeyInvalid:   "nome de campo inválido: '%s' (de 1 a %d caracteres, sem ':')",
+	CodeAlbumMetadataTooLarge:     "os álbuns aceitam até %d campos, com valores de até %d caracteres",
+	CodeAlbumMetadataDuplicateKey: "campos repetidos: '%s' e '%s' (os nomes não diferenciam maiúsculas de minúsculas)",
+	CodeAlbumMetadataUpdateFailed: "não foi possível atualizar os campos do álbum",
+
+	CodeAPIVersionUnsupported: "versão da API não suportada: '%s' (disponíveis: %s)",
+
+	CodeSourceChangesInvalid:     "parâmetros inválidos: informe photo_ids (opcional) e verify_hash",
+	CodeSourceChangesNotExternal: "a foto %d não é indexada no local",
+	CodeSourceChangesFailed:      "não foi possível verificar os arquivos das fotos",
+
+	CodeAnnotationKindInvalid: "Tipo de anotação inválido: use note, face ou pet.",
+	CodeAnnotationXMPInvalid:  "Não foi possível ler as regiões do XMP: %s",
+	CodeAnnotationXMPFailed:   "Erro ao importar ou exportar as regiões XMP",
+
+	CodeDateFixInvalid: "correções inválidas: informe fixes com photo_id, date (RFC 3339, entre 1826 e hoje) e time_zone_offset opcional (minutos, -840 a 840)",
+	CodeDateFixFailed:  "não foi possível verificar ou corrigir as datas das fotos",
+
+	CodeTranscodingPending: "Vídeo em transcodificação; tente novamente em instantes.",
+}
+
+// english é o catálogo em inglês.
+var english = map[string]string{
+	CodeBadRequest:         "Invalid request",
+	CodeInternalError:      "Internal error",
+	CodeAdminRequired:      "Operation restricted to the administrator.",
+	CodeInvalidRequestBody: "Invalid request body.",
+	CodeRequestTooLarge:    "Request body larger than the %d MB limit.",
+	CodeFieldRequired:      "The '%s' field is required.",
+	CodeFieldsRequired:     "Missing or invalid required fields: %s.",
+	CodeInvalidParam:       "Invalid '%s' parameter: %s.",
+	CodeParamRequired:      "is required",
+	CodeParamMin:           "must be at least %s",
+	CodeParamMax:           "must be at most %s",
+	CodeParamOneOf:         "use one of: %s",
+	CodeParamPhotoOrder:    "use 'field', 'field ASC' or 'field DESC' with one of the fields: %s",
+	CodeParamFormat:        "malformed value",
+	CodeParamInvalid:       "invalid value",
+	CodeInvalidAlbumID:     "Invalid album ID.",
+	CodeInvalidPhotoID:     "Invalid photo ID.",
+	CodeInvalidPolicyID:    "Invalid policy ID.",
+	CodeInvalidImportID:    "Invalid import ID.",
+	CodeInvalidVolumeID:    "Invalid volume ID.",
+	CodeInvalidEventID:     "Invalid event ID.",
+	CodeInvalidStartDate:   "Invalid start date. Use the YYYY-MM-DD format.",
+	CodeInvalidEndDate:     "Invalid end date. Use the YYYY-MM-DD format.",
+	CodeGraphQLQueryEmpty:  "Provide the GraphQL query in the 'query' field.",
+
+	CodePhotoNotFound:         "Photo not found.",
+	CodePhotosNotFound:        "one or more photos do not exist",
+	CodePhotoLocked:           "The photo is locked. Unlock it before changing or removing it.",
+	CodePhotoDuplicate:        "duplicate photo detected",
+	CodePhotoQuarantined:      "photo is quarantined",
+	CodePhotoNotImage:         "the file is not an image",
+	CodePhotoNotVideo:         "The photo is not a video.",
+	CodePhotosFetchFailed:     "Error fetching photos",
+	CodePhotoFetchFailed:      "Error fetching photo",
+	CodePhotoUpdateFailed:     "Error updating photo",
+	CodePhotoDeleteFailed:     "Error removing photo",
+	CodePhotoDeleted:          "Photo removed successfully.",
+	CodePhotoFileMissing:      "Photo file not found in storage.",
+	CodeOriginalNotStored:     "The original of this photo was not kept.",
+	CodeRandomPhotosFailed:    "Error fetching random photos",
+	CodePopularPhotosFailed:   "Error fetching popular photos",
+	CodeTimelineFailed:        "Error fetching timeline",
+	CodeThumbnailUnavailable:  "Unable to generate a thumbnail for this photo",
+	CodeThumbnailFailed:       "Error getting thumbnail",
+	CodeThumbnailBusy:         "Thumbnail is being generated; try again shortly.",
+	CodeVideoUnsupported:      "The video format is not supported by the browser and transcoding is disabled.",
+	CodeTranscodingDisabled:   "Video transcoding is disabled.",
+	CodeTranscodingFailed:     "Error transcoding video",
+	CodeInvalidHLSName:        "Invalid HLS file name.",
+	CodeSegmentNotFound:       "Segment not found.",
+	CodeMultipartInvalid:      "Unable to read the multipart form",
+	CodeUploadNoFiles:         "No 'photos' file found in the form.",
+	CodeChecksumHeaderSingle:  "The X-Content-SHA256 header can only be used with a single file; for batches, use the 'sha256' fields.",
+	CodeChecksumCountMismatch: "%d 'sha256' fields were provided for %d files.",
+	CodeChecksumInvalid:       "Invalid SHA-256: '%s' (expected 64 hexadecimal characters).",
+	CodeUploadInterrupted:     "Upload interrupted",
+	CodeUploadPartial:         "Some photos were processed with errors.",
+	CodeUploadSucceeded:       "Uploads processed successfully!",
+
+	CodeFileTooLarge:       "File size exceeds the %dMB limit",
+	CodeUnsupportedType:    "File type not allowed (%s). Only %s",
+	CodeUnreadableImage:    "Unable to read the image header: %v",
+	CodeDimensionsTooLarge: "Image dimensions (%dx%d) exceed the %dpx limit",
+	CodeDecompressionBomb:  "The image declares %d pixels, above the %d limit",
+	CodeMalwareDetected:    "File rejected by the antivirus: %s",
+	CodeChecksumMismatch:   "SHA-256 of the received file (%s) differs from the provided one (%s)",
+	CodeHookRejected:       "File rejected by the pre-ingest hook: %s",
+	CodeHookFailed:         "Pre-ingest hook unavailable: %v",
+
+	CodeStorageUnavailable:   "No storage space available",
+	CodeNoVolumeAvailable:    "no storage volume available",
+	CodeInsufficientStorage:  "insufficient disk space",
+	CodeVolumeOffline:        "Storage volume '%s' is offline.",
+	CodeVolumesFetchFailed:   "Error fetching volumes",
+	CodeVolumeFieldsRequired: "volume name and path are required",
+	CodeVolumeDateRange:      "the end of the date range must be after its start",
+	CodeVolumeRegisterFailed: "Unable to register the volume",
+	CodeVolumeNotFound:       "Volume not found.",
+	CodeVolumeDefault:        "the default volume cannot be removed",
+	CodeVolumeNotEmpty:       "volume '%s' still contains %d photos",
+	CodeVolumeDeleteFailed:   "Unable to remove the volume",
+	CodeVolumeDeleted:        "Volume removed successfully.",
+
+	CodeAlbumNotFound:          "Album or photo not found.",
+	CodeAlbumLocked:            "The album is locked. Unlock it before changing it.",
+	CodeAlbumNameRequired:      "the album name is required",
+	CodeAlbumsFetchFailed:      "Error fetching albums",
+	CodeAlbumPhotosFetchFailed: "Error fetching album photos",
+	CodeAlbumCreateFailed:      "Unable to create the album",
+	CodeAlbumCreatedFetch:      "Error fetching the created album",
+	CodeAlbumFilterFailed:      "Error applying the filter",
+	CodeAlbumPhotoIDsEmpty:     "The 'photo_ids' field must contain at least one ID.",
+	CodeAlbumPhotosAdded:       "%d photo(s) added to the album.",
+	CodeAlbumPhotoRemoved:      "Photo removed from the album.",
+
+	CodePolicyNotFound:      "Policy not found.",
+	CodePolicyNotConfirmed:  "the policy has not been confirmed yet; review the preview and confirm it before running it",
+	CodePolicyNameRequired:  "the policy name is required",
+	CodePolicyInvalidAction: "invalid action: '%s' (use '%s' or '%s')",
+	CodePolicyInvalidAge:    "the minimum photo age must be at least 1 day",
+	CodePolicyPreviewFailed: "Error computing the preview",
+	CodePolicyExecuteFailed: "Error running the policy",
+	CodePolicyRemoved:       "Policy removed.",
+	CodeSoftQuotaInvalid:    "The 'soft_quota_mb' field is required and cannot be negative.",
+
+	CodeTagsFetchFailed:         "Error fetching tags",
+	CodeTagsInvalid:             "The 'tags' field must be a list of strings.",
+	CodeTagsUpdateFailed:        "Error updating tags",
+	CodeEventsFailed:            "Error detecting events",
+	CodeEventNotFound:           "Event not found.",
+	CodeShareNotFound:           "Share link not found.",
+	CodeShareFetchFailed:        "Error fetching the shared album",
+	CodeImportStartFailed:       "Unable to start the import",
+	CodeImportNotFound:          "Import not found.",
+	CodeImportFetchFailed:       "Error fetching the import",
+	CodeImportNotDirectory:      "source path '%s' is not a directory",
+	CodeDuplicatesFailed:        "Error fetching duplicates",
+	CodeDuplicatesReclaimFailed: "Error removing duplicates",
+	CodeDuplicatePolicyInvalid:  "Invalid policy '%s': use '%s', '%s' or '%s'.",
+	CodeDuplicateThreshold:      "Invalid 'threshold' value: use 0 to %d.",
+	CodeDuplicateNearInvalid:    "Invalid 'near' value.",
+	CodeStatsFailed:             "Error computing statistics",
+	CodeCameraStatsFailed:       "Error computing camera statistics",
+	CodeLensStatsFailed:         "Error computing lens statistics",
+
+	CodeInvalidMirrorID:             "Invalid mirror source ID.",
+	CodeMirrorNotFound:              "Mirror source not found.",
+	CodeMirrorsFetchFailed:          "Error fetching mirror sources",
+	CodeMirrorCreateFailed:          "Could not register the mirror source",
+	CodeMirrorDeleteFailed:          "Could not remove the mirror source",
+	CodeMirrorDeleted:               "Mirror source removed. Imported photos were kept.",
+	CodeMirrorRunning:               "the source is already being synchronized",
+	CodeMirrorNameRequired:          "the source name is required",
+	CodeMirrorKindInvalid:           "invalid source kind: '%s' (use '%s', '%s' or '%s')",
+	CodeMirrorIntervalInvalid:       "the sync interval cannot be negative",
+	CodeMirrorPathRequired:          "the source directory is required",
+	CodeMirrorBucketRequired:        "the source bucket is required",
+	CodeMirrorCredentialsIncomplete: "provide access_key and secret_key together (or neither, for public buckets)",
+
+	CodePrivacyFieldsInvalid: "Invalid privacy field: '%s' (use gps, serial or owner).",
+	CodePrivacyAuditFailed:   "Error auditing the library",
+	CodePrivacyPhotoExternal: "the photo is indexed in place; the original file is never modified",
+
+	CodeThumbnailBackfillStarted: "Generation of missing thumbnails started.",
+
+	CodeUploadSourceInvalid: "Invalid source: '%s' (use %s).",
+	CodeUploadDeviceTooLong: "Device name too long (maximum %d characters).",
+	CodePhotoSourcesFailed:  "Failed to list photo sources",
+
+	CodeInvalidTagID:    "Invalid tag ID.",
+	CodeTagNotFound:     "Tag not found.",
+	CodeTagNameInvalid:  "Provide a non-empty tag name in 'name'.",
+	CodeTagNameConflict: "Tag '%s' already exists (id %d). Use POST /tags/merge to merge them.",
+	CodeTagMergeInvalid: "Provide 'source_ids' (tags to merge) and 'target_id' (tag to keep), distinct from each other.",
+	CodeTagPhotosLocked: "%d photo(s) with the tag are locked. Unlock them before renaming or merging the tag.",
+	CodeTagRenameFailed: "Error renaming tag",
+	CodeTagMergeFailed:  "Error merging tags",
+
+	CodeSearchUnknownKey:          "unknown filter '%s' (use: %s)",
+	CodeSearchInvalidValue:        "invalid value in '%s'",
+	CodeSearchNegationUnsupported: "the filter '%s' cannot be negated with '-'",
+	CodeSearchUnclosedQuote:       "unclosed quote in the query",
+	CodeSearchMonthNeedsYear:      "the 'month' filter also requires 'year'",
+
+	CodeAlbumHealthFailed:   "Failed to diagnose albums",
+	CodeAlbumCleanupFailed:  "Failed to clean up albums",
+	CodeAlbumCleanupInvalid: "Invalid body: use {\"orphan_links\": bool, \"empty_albums\": bool, \"empty_older_than_days\": non-negative integer, \"dry_run\": bool}.",
+
+	CodePhotoExifVideo:  "Videos have no EXIF metadata.",
+	CodePhotoExifFailed: "Failed to read the photo metadata",
+
+	CodeHashInvalid:      "Invalid hash: '%s' (use the file MD5, 32 hex digits, or the pixel SHA-256, 64).",
+	CodeHashesInvalid:    "Invalid body: use {\"hashes\": [...]} with 1 to %d hashes.",
+	CodeHashNotFound:     "No photo with this hash.",
+	CodeHashLookupFailed: "Failed to look up photos by hash",
+
+	CodeRulesInvalid: "Invalid organization rules: %s",
+
+	CodeFilenameTaken:       "A photo named '%s' already exists.",
+	CodeUploadPreviewFailed: "Error while previewing the upload",
+
+	CodeSourceAlbumNotFound:   "Default album not found.",
+	CodeSourceAlbumsFailed:    "Error fetching default albums",
+	CodeSourceAlbumSaveFailed: "Error saving the default album",
+
+	CodeInvalidSourceAlbumID: "Invalid default album ID.",
+
+	CodeSourceAlbumRemoved: "Default album removed successfully.",
+
+	CodeZipInvalid:        "Invalid or corrupted ZIP archive",
+	CodeZipTooManyEntries: "The ZIP archive has more than %d entries.",
+	CodeZipRequired:       "Send a ZIP archive in the 'archive' field.",
+	CodeZipImportFailed:   "Error importing the ZIP archive",
+
+	CodePhotoCompareFailed: "Error comparing the photos",
+
+	CodeImportFormatInvalid:  "unknown import format '%s' (use photoprism or immich)",
+	CodeImportLibraryInvalid: "'%s' does not contain a library in the given format: %s",
+
+	CodeInvalidExportID:      "Invalid export ID.",
+	CodeExportNotFound:       "Export not found.",
+	CodeExportFetchFailed:    "Error fetching the export",
+	CodeExportStartFailed:    "Unable to start the export",
+	CodeExportNotReady:       "The export has not finished yet; check its status and try again.",
+	CodeExportArchiveMissing: "This export's archive is no longer available; start a new one with GET /me/export?new=true.",
+
+	CodeFieldsUnknown: "unknown fields: %s (available: %s)",
+
+	CodeReactionKindInvalid:    "Invalid reaction kind (use thumbs_up or heart).",
+	CodeReactionVoterInvalid:   "Enter your name (up to 64 characters) to react.",
+	CodeReactionPhotoNotShared: "The photo is not part of the shared album.",
+	CodeReactionFailed:         "Error recording the reaction",
+	CodeReactionListFailed:     "Error fetching the top-voted photos",
+
+	CodePrintSizeInvalid:       "Invalid print size: '%s' (use WIDTHxHEIGHT in cm, e.g. 10x15, or with a unit: 4x6in, 130x180mm).",
+	CodePrintCheckNoDimensions: "Could not determine the image dimensions.",
+
+	CodeTextReplaceInvalid: "Invalid replacement: %s.",
+	CodeTextReplaceFailed:  "Could not run the replacement.",
+
+	CodeRateLimited:      "Too many requests. Try again shortly.",
+	CodeHotlinkForbidden: "Gallery images cannot be embedded on other sites.",
+
+	CodeImportQueueFull: "Too many imports are queued. Wait for one to finish and try again.",
+
+	CodeDatabaseMaintenanceBusy:   "Another database maintenance operation is running. Try again when it finishes.",
+	CodeDatabaseBackupNotFound:    "Database backup not found.",
+	CodeDatabaseBackupFailed:      "Unable to back up the database",
+	CodeDatabaseMaintenanceFailed: "Database maintenance failed",
+	CodeDatabaseStatsFailed:       "Unable to read the database statistics",
+
+	CodeReconcileInvalid:      "Invalid reconciliation: %s.",
+	CodeReconcileFailed:       "Unable to reconcile the metadata",
+	CodeMetadataChangesFailed: "Unable to fetch the change history",
+
+	CodeRelationKindInvalid: "Invalid relation kind: '%s' (use %s).",
+	CodeRelationSelf:        "A photo cannot be related to itself.",
+	CodeRelationExists:      "The photos already have this relation.",
+	CodeRelationNotFound:    "Relation not found.",
+	CodeRelationFailed:      "Error saving the photo relation",
+	CodeRelationListFailed:  "Error fetching the photo relations",
+	CodeRelationRemoved:     "Relation removed.",
+
+	CodeProjectionDetectFailed: "Error detecting panoramas",
+
+	CodeAuxiliaryDetectFailed: "Error detecting auxiliary images",
+
+	CodeInvalidTimeZone: "Invalid time zone: use an IANA name (e.g. America/Sao_Paulo), local or photo",
+
+	CodePhotoVersionConflict: "The photo was changed by someone else since the given version (current: %s). Review the current state and try again.",
+
+	CodeAlbumDeleteConfirmationRequired: "Deleting the album would delete %d photo(s) from the library. Confirm with confirm=<count> to proceed.",
+	CodeAlbumDeleted:                    "Album deleted.",
+	CodeAlbumDeleteFailed:               "Error deleting the album",
+
+	CodeImportFileSkipped:        "File skipped: hidden, system or unsupported extension.",
+	CodeInvalidImportReportID:    "Invalid import report ID.",
+	CodeImportReportNotFound:     "Import report not found.",
+	CodeImportReportsFetchFailed: "Error fetching import reports",
+
+	CodeStorageDriftScanFailed:       "Failed to scan the storage",
+	CodeStorageDriftReconcileInvalid: "Invalid body: use {\"relink\": bool, \"adopt\": bool, \"dry_run\": bool}.",
+	CodeStorageDriftReconcileFailed:  "Failed to reconcile the storage",
+
+	CodeInvalidPlanID:     "Invalid plan ID",
+	CodePlanNotFound:      "Plan not found",
+	CodePlanNotPending:    "The plan has already been executed or discarded.",
+	CodePlanExpired:       "The plan is older than %d hours; run a new dry run.",
+	CodePlansFetchFailed:  "Failed to fetch plans",
+	CodePlanCreateFailed:  "Failed to save the plan",
+	CodePlanExecuteFailed: "Failed to execute the plan",
+	CodePlanPhotoChanged:  "The photo was changed or removed since the plan was made.",
+
+	CodeDuplicatePolicyNotFound:        "Duplicate policy not found.",
+	CodeDuplicatePoliciesFailed:        "Error fetching duplicate policies",
+	CodeDuplicatePolicySaveFailed:      "Error saving the duplicate policy",
+	CodeInvalidDuplicatePolicyID:       "Invalid duplicate policy ID.",
+	CodeDuplicatePolicyRemoved:         "Duplicate policy removed successfully.",
+	CodeSourceDuplicatePolicyInvalid:   "Invalid duplicate policy: '%s' (use %s).",
+	CodeDuplicatePolicyAlbumNotAllowed: "'album_id' is only accepted by the link policy.",
+	CodePhotoDuplicateSkipped:          "duplicate photo skipped by the source policy",
+
+	CodeAnnotationNotFound:      "Annotation not found.",
+	CodeInvalidAnnotationID:     "Invalid annotation ID.",
+	CodeAnnotationRegionInvalid: "Invalid region: x, y, width and height are fractions of the image (0 to 1), with width and height greater than zero and the region inside the image.",
+	CodeAnnotationTextRequired:  "The annotation text is required (up to %d characters).",
+	CodeAnnotationFailed:        "Error saving the annotation",
+	CodeAnnotationsFetchFailed:  "Error fetching the photo annotations",
+	CodeAnnotationRemoved:       "Annotation removed.",
+
+	CodeAlbumTemplateNotFound:        "Album template not found.",
+	CodeInvalidAlbumTemplateID:       "Invalid album template ID.",
+	CodeAlbumTemplateInvalid:         "Invalid album template: 'name' and 'name_pattern' are required and the quota cannot be negative.",
+	CodeAlbumTemplateExists:          "An album template named '%s' already exists.",
+	CodeAlbumTemplateVariableMissing: "Variable without a value in the template pattern: '{{%s}}'. Provide it in 'variables'.",
+	CodeAlbumTemplatesFailed:         "Error fetching album templates",
+	CodeAlbumTemplateSaveFailed:      "Error saving the album template",
+	CodeAlbumTemplateRemoved:         "Album template removed.",
+	CodeAlbumFromTemplateFailed:      "Error creating the album from the template",
+
+	CodeAlbumTemplateDateInvalid: "Invalid date: '%s'. Use the YYYY-MM-DD format.",
+
+	CodeDownloadSelectionEmpty: "No photos selected for download; provide 'photo_ids' or a filter.",
+	CodeDownloadEstimateFailed: "Unable to estimate the download",
+	CodeDownloadStartFailed:    "Unable to start the download",
+	CodeInvalidDownloadID:      "Invalid download ID.",
+	CodeDownloadNotFound:       "Download not found.",
+	CodeDownloadFetchFailed:    "Error fetching the download",
+	CodeDownloadNotReady:       "The download is still being prepared; check its status and try again.",
+	CodeDownloadArchiveMissing: "This download's archive is no longer available; request a new one with POST /downloads.",
+
+	CodeConfigReloadFailed: "Invalid configuration, reload ignored",
+
+	CodeSelectionNotFound:      "Selection not found or expired.",
+	CodeSelectionEmpty:         "The selection has no photos.",
+	CodeSelectionPhotosInvalid: "Provide 'photo_ids' or a filter.",
+	CodeSelectionFetchFailed:   "Error fetching the selection",
+	CodeSelectionUpdateFailed:  "Could not update the selection",
+	CodeSelectionActionFailed:  "Could not run the action on the selection",
+	CodeSelectionAlbumTarget:   "Provide 'album_id' (existing album) or 'name' (new album).",
+	CodeSelectionRemoved:       "Selection discarded.",
+	CodeSelectionPhotosTagged:  "%d photo(s) received new tags.",
+
+	CodeCircaDateInvalid: "Invalid approximate date. Use YYYY-MM (month), YYYY (year) or YYY0s (decade, e.g. 1980s).",
+
+	CodeMirrorShareURLInvalid: "set 'url' to the album share link on the other instance (e.g. https://photos.example.com/s/<token>)",
+
+	CodeGeotagTrackRequired:   "send the GPS track (GPX or KML file) in the 'track' field",
+	CodeGeotagTrackInvalid:    "invalid GPS track: %s",
+	CodeGeotagTimeZoneInvalid: "invalid camera time zone: use an IANA name (e.g. Europe/Lisbon) or an offset (e.g. +02:00)",
+	CodeGeotagOptionsInvalid:  "invalid parameters: 'photo_ids' are photo IDs, 'clock_offset' is in seconds and 'max_gap_minutes' ranges from 1 to 1440",
+	CodeGeotagFailed:          "could not geotag the photos",
+
+	CodeAlbumMetadataInvalid:      "send the fields in 'metadata', e.g. {\"metadata\": {\"Client\": \"Maria\", \"Location\": \"Beach\"}}",
+	CodeAlbumMetadataKeyInvalid:   "invalid field name: '%s' (1 to %d characters, without ':')",
+	CodeAlbumMetadataTooLarge:     "albums accept up to %d fields, with values of up to %d characters",
+	CodeAlbumMetadataDuplicateKey: "repeated fields: '%s' and '%s' (names are case-insensitive)",
+	CodeAlbumMetadataUpdateFailed: "could not update the album fields",
+
+	CodeAPIVersionUnsupported: "unsupported API version: '%s' (available: %s)",
+
+	CodeSourceChangesInvalid:     "invalid parameters: provide photo_ids (optional) and verify_hash",
+	CodeSourceChangesNotExternal: "photo %d is not indexed in place",
+	CodeSourceChangesFailed:      "could not check the photo files",
+
+	CodeAnnotationKindInvalid: "Invalid annotation kind: use note, face or pet.",
+	CodeAnnotationXMPInvalid:  "Could not read the XMP regions: %s",
+	CodeAnnotationXMPFailed:   "Error importing or exporting the XMP regions",
+
+	CodeDateFixInvalid: "invalid fixes: provide fixes with photo_id, date (RFC 3339, between 1826 and today) and an optional time_zone_offset (minutes, -840 to 840)",
+	CodeDateFixFailed:  "could not check or fix the photo dates",
+
+	CodeTranscodingPending: "Video is being transcoded; try again shortly.",
+}
@@ -0,0 +1,55 @@
+package ledger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"photo-manager/internal/database"
+	"strconv"
+	"strings"
+)
+
+// GenesisHash é o hash anterior da primeira entrada do livro-razão.
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// Ações registradas no livro-razão.
+const (
+	ActionIngest   = "ingest"   // A foto foi adicionada à biblioteca
+	ActionUpdate   = "update"   // O arquivo da foto foi alterado pela aplicação (ex: remoção de campos EXIF)
+	ActionDelete   = "delete"   // A foto foi removida da biblioteca
+	ActionBaseline = "baseline" // Foto já existente registrada ao ativar o livro-razão
+)
+
+// EntryHash calcula o hash de uma entrada: SHA-256 dos campos da entrada, incluindo o hash da entrada
+// anterior. Alterar, remover ou reordenar qualquer entrada quebra o encadeamento das seguintes.
+func EntryHash(entry database.LedgerEntry) string {
+	fields := []string{
+		strconv.FormatUint(entry.Seq, 10),
+		strconv.FormatUint(uint64(entry.PhotoID), 10),
+		entry.Action,
+		entry.Filename,
+		entry.FileHash,
+		entry.ContentSHA256,
+		strconv.FormatInt(entry.RecordedAt.UnixNano(), 10),
+		entry.PrevHash,
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// FileSHA256 calcula o SHA-256 do conteúdo de um arquivo.
+func FileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("não foi possível abrir o arquivo para hash: %w", err)
+	}
+	defer file.Close()
+
+	digest := sha256.New()
+	if _, err := io.Copy(digest, file); err != nil {
+		return "", fmt.Errorf("não foi possível calcular o hash do arquivo: %w", err)
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
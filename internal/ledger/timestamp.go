@@ -0,0 +1,211 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+// Identificadores ASN.1 usados nos carimbos de tempo (RFC 3161 e RFC 5652).
+var (
+	oidSHA256     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+)
+
+// maxResponseSize limita o tamanho da resposta da autoridade de carimbo de tempo.
+const maxResponseSize = 1 << 20
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type messageImprint struct {
+	HashAlgorithm algorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int
+	CertReq        bool
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString asn1.RawValue  `asn1:"optional"`
+	FailInfo     asn1.BitString `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status         pkiStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type encapsulatedContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo encapsulatedContentInfo
+}
+
+// TokenInfo são os dados atestados por um carimbo de tempo.
+type TokenInfo struct {
+	Digest []byte    // Resumo SHA-256 carimbado
+	Time   time.Time // Data atestada pela autoridade
+	Nonce  *big.Int  // Valor aleatório da requisição (nil se a autoridade não o devolveu)
+}
+
+// TimestampClient obtém carimbos de tempo RFC 3161 de uma autoridade externa (TSA), provando que um
+// resumo já existia na data atestada.
+type TimestampClient struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewTimestampClient cria um cliente para a autoridade no endereço informado.
+func NewTimestampClient(url string) *TimestampClient {
+	return &TimestampClient{URL: url, HTTPClient: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Timestamp solicita o carimbo de tempo de um resumo SHA-256 e devolve o token (ContentInfo DER), que pode
+// ser verificado com ferramentas padrão, ex: openssl ts -verify -digest <hex> -in token.tsr -token_in
+// -CAfile tsa.pem.
+func (c *TimestampClient) Timestamp(ctx context.Context, digest []byte) ([]byte, error) {
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível gerar o nonce: %w", err)
+	}
+	body, err := asn1.Marshal(timeStampReq{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: algorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+			HashedMessage: digest,
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível codificar a requisição de carimbo de tempo: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/timestamp-query")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao contatar a autoridade de carimbo de tempo: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("autoridade de carimbo de tempo respondeu com status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler a resposta da autoridade de carimbo de tempo: %w", err)
+	}
+
+	var tsResp timeStampResp
+	if _, err := asn1.Unmarshal(data, &tsResp); err != nil {
+		return nil, fmt.Errorf("resposta de carimbo de tempo inválida: %w", err)
+	}
+	// 0 = concedido, 1 = concedido com modificações
+	if tsResp.Status.Status > 1 {
+		return nil, fmt.Errorf("carimbo de tempo recusado pela autoridade (status %d)", tsResp.Status.Status)
+	}
+	token := tsResp.TimeStampToken.FullBytes
+	if len(token) == 0 {
+		return nil, errors.New("a autoridade não devolveu o carimbo de tempo")
+	}
+
+	info, err := ParseToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(info.Digest, digest) {
+		return nil, errors.New("o carimbo de tempo devolvido não corresponde ao resumo enviado")
+	}
+	if info.Nonce != nil && info.Nonce.Cmp(nonce) != 0 {
+		return nil, errors.New("o carimbo de tempo devolvido não corresponde à requisição (nonce divergente)")
+	}
+	return token, nil
+}
+
+// ParseToken extrai de um carimbo de tempo o resumo e a data atestados. A assinatura da autoridade não é
+// verificada aqui; use as ferramentas da própria autoridade (ou openssl ts -verify) com o certificado dela.
+func ParseToken(token []byte) (*TokenInfo, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(token, &ci); err != nil {
+		return nil, fmt.Errorf("carimbo de tempo inválido: %w", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, errors.New("carimbo de tempo inválido: conteúdo não é SignedData")
+	}
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("carimbo de tempo inválido: %w", err)
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+		return nil, errors.New("carimbo de tempo inválido: conteúdo não é TSTInfo")
+	}
+
+	// TSTInfo: version, policy, messageImprint, serialNumber, genTime e campos opcionais (accuracy,
+	// ordering, nonce, tsa, extensions), dos quais apenas o nonce (único INTEGER restante) interessa
+	var tstInfo asn1.RawValue
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent, &tstInfo); err != nil {
+		return nil, fmt.Errorf("carimbo de tempo inválido: %w", err)
+	}
+	info := &TokenInfo{}
+	rest := tstInfo.Bytes
+	for i := 0; len(rest) > 0; i++ {
+		var element asn1.RawValue
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &element); err != nil {
+			return nil, fmt.Errorf("carimbo de tempo inválido: %w", err)
+		}
+		switch {
+		case i == 2:
+			var imprint messageImprint
+			if _, err := asn1.Unmarshal(element.FullBytes, &imprint); err != nil {
+				return nil, fmt.Errorf("carimbo de tempo inválido: %w", err)
+			}
+			if !imprint.HashAlgorithm.Algorithm.Equal(oidSHA256) {
+				return nil, errors.New("carimbo de tempo com algoritmo de resumo não suportado")
+			}
+			info.Digest = imprint.HashedMessage
+		case i == 4:
+			if _, err := asn1.UnmarshalWithParams(element.FullBytes, &info.Time, "generalized"); err != nil {
+				return nil, fmt.Errorf("carimbo de tempo inválido: %w", err)
+			}
+		case i > 4 && element.Class == asn1.ClassUniversal && element.Tag == asn1.TagInteger:
+			info.Nonce = new(big.Int)
+			if _, err := asn1.Unmarshal(element.FullBytes, &info.Nonce); err != nil {
+				return nil, fmt.Errorf("carimbo de tempo inválido: %w", err)
+			}
+		}
+	}
+	if info.Digest == nil || info.Time.IsZero() {
+		return nil, errors.New("carimbo de tempo inválido: TSTInfo incompleto")
+	}
+	return info, nil
+}
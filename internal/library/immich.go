@@ -0,0 +1,228 @@
+package library
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// immichAsset são os campos lidos de um arquivo (asset) na API do Immich.
+type immichAsset struct {
+	ID           string `json:"id"`
+	OriginalPath string `json:"originalPath"` // Caminho do arquivo no armazenamento do Immich
+	Checksum     string `json:"checksum"`     // SHA-1 do arquivo, em base64
+	IsFavorite   bool   `json:"isFavorite"`
+	IsArchived   bool   `json:"isArchived"`
+	ExifInfo     *struct {
+		Description string `json:"description"`
+	} `json:"exifInfo"`
+	People []struct {
+		Name string `json:"name"`
+	} `json:"people"`
+	Tags []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"` // Caminho completo das tags hierárquicas (ex: viagens/2023)
+	} `json:"tags"`
+}
+
+// immichAlbum é um álbum como retornado por GET /api/albums/{id}.
+type immichAlbum struct {
+	AlbumName string        `json:"albumName"`
+	Assets    []immichAsset `json:"assets"`
+}
+
+// immichEntry são os metadados combinados de um arquivo do Immich e os álbuns que o contêm.
+type immichEntry struct {
+	asset  immichAsset
+	albums []string
+}
+
+// ReadImmich lê uma biblioteca do Immich: root é o diretório com os arquivos originais (ex: upload/library)
+// e manifest, opcional, um JSON com os metadados obtidos da API do Immich, em um dos formatos:
+//
+//	[{"albumName": "...", "assets": [...]}, ...]       (respostas de GET /api/albums/{id})
+//	{"albums": [...], "assets": [...]}                 (álbuns e arquivos avulsos)
+//
+// Cada arquivo do manifesto é associado ao arquivo em disco pelo final do originalPath ou, se houver
+// ambiguidade, pelo checksum SHA-1. Sem manifesto, os arquivos são importados sem metadados extras.
+func ReadImmich(root, manifest string) ([]Item, error) {
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("diretório da biblioteca '%s' não encontrado", root)
+	}
+
+	byName := make(map[string][]*immichEntry)
+	if manifest != "" {
+		entries, err := readImmichManifest(manifest)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := path.Base(immichPath(entry.asset.OriginalPath))
+			byName[name] = append(byName[name], entry)
+		}
+	}
+
+	var items []Item
+	err := walkOriginals(root, func(p, rel string) error {
+		item := Item{Path: p, Rel: rel}
+		if entry := matchImmichEntry(byName[path.Base(rel)], p, rel); entry != nil {
+			asset := entry.asset
+			item.Favorite = asset.IsFavorite
+			item.Hidden = asset.IsArchived
+			if asset.ExifInfo != nil {
+				item.Description = strings.TrimSpace(asset.ExifInfo.Description)
+			}
+			for _, tag := range asset.Tags {
+				name := tag.Value
+				if name == "" {
+					name = tag.Name
+				}
+				item.Tags = mergeNames(item.Tags, name)
+			}
+			for _, person := range asset.People {
+				item.Tags = mergeNames(item.Tags, person.Name) // Pessoas sem nome são ignoradas
+			}
+			item.Albums = sortedNames(append([]string(nil), entry.albums...))
+		}
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao percorrer a biblioteca do Immich: %w", err)
+	}
+	return items, nil
+}
+
+// readImmichManifest lê o manifesto e combina as ocorrências de cada arquivo (pelo ID do Immich).
+func readImmichManifest(file string) ([]*immichEntry, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível ler o manifesto do Immich: %w", err)
+	}
+
+	var albums []immichAlbum
+	var assets []immichAsset
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(data, &albums); err != nil {
+			return nil, fmt.Errorf("manifesto do Immich inválido: %w", err)
+		}
+	} else {
+		var doc struct {
+			Albums []immichAlbum   `json:"albums"`
+			Assets json.RawMessage `json:"assets"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("manifesto do Immich inválido: %w", err)
+		}
+		albums = doc.Albums
+		if len(doc.Assets) > 0 {
+			// Aceita tanto uma lista quanto a resposta paginada da busca ({"items": [...]})
+			if err := json.Unmarshal(doc.Assets, &assets); err != nil {
+				var page struct {
+					Items []immichAsset `json:"items"`
+				}
+				if err := json.Unmarshal(doc.Assets, &page); err != nil {
+					return nil, fmt.Errorf("manifesto do Immich inválido: %w", err)
+				}
+				assets = page.Items
+			}
+		}
+	}
+
+	var entries []*immichEntry
+	byID := make(map[string]*immichEntry)
+	add := func(asset immichAsset, album string) {
+		entry := byID[asset.ID]
+		if entry == nil || asset.ID == "" {
+			entry = &immichEntry{asset: asset}
+			entries = append(entries, entry)
+			if asset.ID != "" {
+				byID[asset.ID] = entry
+			}
+		} else {
+			// Respostas diferentes podem trazer campos diferentes (ex: álbuns sem pessoas)
+			entry.asset.IsFavorite = entry.asset.IsFavorite || asset.IsFavorite
+			entry.asset.IsArchived = entry.asset.IsArchived || asset.IsArchived
+			if len(entry.asset.People) == 0 {
+				entry.asset.People = asset.People
+			}
+			if len(entry.asset.Tags) == 0 {
+				entry.asset.Tags = asset.Tags
+			}
+			if entry.asset.ExifInfo == nil {
+				entry.asset.ExifInfo = asset.ExifInfo
+			}
+		}
+		if album != "" {
+			entry.albums = mergeNames(entry.albums, album)
+		}
+	}
+	for _, asset := range assets {
+		add(asset, "")
+	}
+	for _, album := range albums {
+		for _, asset := range album.Assets {
+			add(asset, strings.TrimSpace(album.AlbumName))
+		}
+	}
+	return entries, nil
+}
+
+// matchImmichEntry escolhe, entre os arquivos do manifesto com o mesmo nome, o que corresponde ao arquivo
+// em disco: pelo final do caminho original e, se nenhum ou vários corresponderem, pelo checksum.
+func matchImmichEntry(candidates []*immichEntry, file, rel string) *immichEntry {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var byPath []*immichEntry
+	for _, entry := range candidates {
+		original := immichPath(entry.asset.OriginalPath)
+		if original == rel || strings.HasSuffix(original, "/"+rel) {
+			byPath = append(byPath, entry)
+		}
+	}
+	if len(byPath) == 1 {
+		return byPath[0]
+	}
+	if len(byPath) > 1 {
+		candidates = byPath
+	}
+
+	checksum, err := immichChecksum(file)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range candidates {
+		if entry.asset.Checksum == checksum {
+			return entry
+		}
+	}
+	return nil
+}
+
+// immichPath normaliza o originalPath do Immich para comparação com caminhos relativos.
+func immichPath(p string) string {
+	return strings.TrimPrefix(path.Clean(strings.ReplaceAll(p, "\\", "/")), "./")
+}
+
+// immichChecksum calcula o checksum de um arquivo no formato do Immich (SHA-1 em base64).
+func immichChecksum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha1.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
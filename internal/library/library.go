@@ -0,0 +1,93 @@
+// Package library lê bibliotecas exportadas por outros gerenciadores de fotos (PhotoPrism, Immich),
+// associando cada arquivo original aos metadados que devem ser preservados na importação.
+package library
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Formatos de biblioteca suportados.
+const (
+	FormatPhotoPrism = "photoprism"
+	FormatImmich     = "immich"
+)
+
+// Item é um arquivo original da biblioteca com os metadados encontrados na origem.
+type Item struct {
+	Path        string   // Caminho absoluto do arquivo original
+	Rel         string   // Caminho relativo à raiz da biblioteca, com "/" como separador (ordem e checkpoint da importação)
+	Description string   // Descrição ou título da foto
+	Favorite    bool     // Marcada como favorita na origem
+	Hidden      bool     // Privada ou arquivada na origem
+	Albums      []string // Nomes dos álbuns (criados manualmente) que contêm a foto
+	Tags        []string // Palavras-chave, tags e nomes das pessoas reconhecidas
+}
+
+// Read lê a biblioteca no formato informado. Para o Immich, manifest é o JSON opcional com os álbuns
+// e os dados dos arquivos; para o PhotoPrism é ignorado.
+// Os itens são retornados na ordem do filepath.WalkDir sobre o diretório dos originais.
+func Read(format, root, manifest string) ([]Item, error) {
+	switch format {
+	case FormatPhotoPrism:
+		return ReadPhotoPrism(root)
+	case FormatImmich:
+		return ReadImmich(root, manifest)
+	default:
+		return nil, fmt.Errorf("formato de biblioteca desconhecido: %s", format)
+	}
+}
+
+// walkOriginals lista os arquivos regulares sob dir, ignorando arquivos e diretórios ocultos.
+// rel é relativo a dir, com "/" como separador.
+func walkOriginals(dir string, fn func(path, rel string) error) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		return fn(path, filepath.ToSlash(rel))
+	})
+}
+
+// mergeNames acrescenta a names os nomes ainda ausentes (sem diferenciar maiúsculas), descartando vazios.
+func mergeNames(names []string, extra ...string) []string {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		seen[strings.ToLower(name)] = true
+	}
+	for _, name := range extra {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// sortedNames retorna os nomes em ordem alfabética, para resultados estáveis entre execuções.
+func sortedNames(names []string) []string {
+	sort.Strings(names)
+	return names
+}
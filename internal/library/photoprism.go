@@ -0,0 +1,149 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// photoPrismSidecar são os campos lidos dos arquivos YAML de backup do PhotoPrism (storage/sidecar).
+type photoPrismSidecar struct {
+	UID         string `yaml:"UID"`
+	Title       string `yaml:"Title"`
+	Description string `yaml:"Description"`
+	Favorite    bool   `yaml:"Favorite"`
+	Private     bool   `yaml:"Private"`
+	DeletedAt   string `yaml:"DeletedAt"` // Preenchido nas fotos arquivadas
+	Details     struct {
+		Keywords string `yaml:"Keywords"`
+	} `yaml:"Details"`
+}
+
+// photoPrismAlbum são os campos lidos dos arquivos YAML de backup dos álbuns (storage/albums/album).
+type photoPrismAlbum struct {
+	Title     string `yaml:"Title"`
+	DeletedAt string `yaml:"DeletedAt"`
+	Photos    []struct {
+		UID    string `yaml:"UID"`
+		Hidden bool   `yaml:"Hidden"` // Foto removida do álbum
+	} `yaml:"Photos"`
+}
+
+// ReadPhotoPrism lê uma biblioteca do PhotoPrism a partir do seu diretório de dados, que contém
+// originals/ (os arquivos) e storage/ (os backups YAML gerados pelo PhotoPrism com "Backup → YAML").
+// Título, descrição, favorito, privacidade e palavras-chave vêm dos arquivos laterais em storage/sidecar;
+// os álbuns criados manualmente vêm de storage/albums/album. Pessoas reconhecidas não constam nos
+// backups YAML e, portanto, não são importadas.
+func ReadPhotoPrism(root string) ([]Item, error) {
+	originals := filepath.Join(root, "originals")
+	if info, err := os.Stat(originals); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("diretório originals não encontrado em '%s'", root)
+	}
+	sidecarDir := filepath.Join(root, "storage", "sidecar")
+
+	var items []Item
+	byUID := make(map[string][]int)
+	err := walkOriginals(originals, func(p, rel string) error {
+		item := Item{Path: p, Rel: rel}
+
+		// O arquivo lateral tem o nome do original sem a extensão (compartilhado por RAW e JPEG da mesma foto)
+		base := strings.TrimSuffix(rel, path.Ext(rel))
+		sidecar, err := readPhotoPrismSidecar(filepath.Join(sidecarDir, filepath.FromSlash(base)+".yml"))
+		if err != nil {
+			log.Printf("Aviso: arquivo lateral do PhotoPrism ignorado para '%s': %v\n", rel, err)
+		}
+		if sidecar != nil {
+			item.Description = sidecar.Description
+			if item.Description == "" {
+				item.Description = sidecar.Title
+			}
+			item.Favorite = sidecar.Favorite
+			item.Hidden = sidecar.Private || sidecar.DeletedAt != ""
+			item.Tags = mergeNames(nil, strings.Split(sidecar.Details.Keywords, ",")...)
+			if sidecar.UID != "" {
+				byUID[sidecar.UID] = append(byUID[sidecar.UID], len(items))
+			}
+		}
+
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao percorrer os originais do PhotoPrism: %w", err)
+	}
+
+	if err := readPhotoPrismAlbums(filepath.Join(root, "storage", "albums", "album"), items, byUID); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// readPhotoPrismSidecar lê um arquivo lateral; retorna nil sem erro se ele não existir.
+func readPhotoPrismSidecar(file string) (*photoPrismSidecar, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sidecar photoPrismSidecar
+	if err := yaml.Unmarshal(data, &sidecar); err != nil {
+		return nil, err
+	}
+	return &sidecar, nil
+}
+
+// readPhotoPrismAlbums associa os itens aos álbuns cujos backups YAML estão em dir.
+// Álbuns automáticos (pastas, momentos, meses, estados) não são importados.
+func readPhotoPrismAlbums(dir string, items []Item, byUID map[string][]int) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao listar os álbuns do PhotoPrism: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yml" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("erro ao ler o álbum '%s': %w", entry.Name(), err)
+		}
+
+		var album photoPrismAlbum
+		if err := yaml.Unmarshal(data, &album); err != nil {
+			log.Printf("Aviso: álbum do PhotoPrism ignorado (%s): %v\n", entry.Name(), err)
+			continue
+		}
+		title := strings.TrimSpace(album.Title)
+		if title == "" || album.DeletedAt != "" {
+			continue
+		}
+
+		for _, photo := range album.Photos {
+			if photo.Hidden {
+				continue
+			}
+			for _, i := range byUID[photo.UID] {
+				items[i].Albums = mergeNames(items[i].Albums, title)
+			}
+		}
+	}
+
+	for i := range items {
+		items[i].Albums = sortedNames(items[i].Albums)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// Package logger guarda o nível de log da aplicação (LOG_LEVEL), que pode ser alterado sem reiniciar o
+// servidor. As mensagens da aplicação continuam no pacote log padrão; o nível controla o log das requisições
+// HTTP e das consultas SQL.
+package logger
+
+import "sync/atomic"
+
+// Níveis aceitos em LOG_LEVEL, do mais ao menos detalhado.
+const (
+	LevelDebug = "debug" // Todas as consultas SQL, além do nível info
+	LevelInfo  = "info"  // Cada requisição HTTP, consultas lentas e erros do banco (padrão)
+	LevelWarn  = "warn"  // Consultas lentas e erros do banco, sem o log das requisições
+	LevelError = "error" // Apenas os erros do banco
+)
+
+// Levels lista os níveis aceitos, do mais ao menos detalhado.
+var Levels = []string{LevelDebug, LevelInfo, LevelWarn, LevelError}
+
+// current é a posição do nível atual em Levels.
+var current atomic.Int32
+
+func init() {
+	current.Store(int32(indexOf(LevelInfo)))
+}
+
+// Valid indica se level é um nível aceito.
+func Valid(level string) bool {
+	return indexOf(level) >= 0
+}
+
+// SetLevel altera o nível atual; níveis inválidos são ignorados.
+func SetLevel(level string) {
+	if i := indexOf(level); i >= 0 {
+		current.Store(int32(i))
+	}
+}
+
+// Level retorna o nível atual.
+func Level() string {
+	return Levels[current.Load()]
+}
+
+// Enabled indica se as mensagens do nível informado devem ser registradas.
+func Enabled(level string) bool {
+	i := indexOf(level)
+	return i >= 0 && int32(i) >= current.Load()
+}
+
+func indexOf(level string) int {
+	for i, l := range Levels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,71 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Object é um arquivo listado numa origem espelhada.
+type Object struct {
+	Key     string    // Caminho do objeto relativo à origem, com "/" como separador
+	ETag    string    // Identifica a versão do conteúdo; muda quando o objeto é substituído
+	Size    int64     // Tamanho em bytes
+	ModTime time.Time // Data de modificação informada pela origem
+}
+
+// Source é uma origem remota listada e baixada pelo espelhamento (somente leitura: nada é alterado na origem).
+type Source interface {
+	// List chama fn para cada objeto da origem; um erro devolvido por fn interrompe a listagem.
+	List(ctx context.Context, fn func(Object) error) error
+	// Open abre o conteúdo do objeto para leitura.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// DirSource espelha um diretório local. Compartilhamentos SMB/NFS e servidores FTP são espelhados montando-os
+// no sistema de arquivos (ex: mount -t cifs, curlftpfs) e apontando Root para o ponto de montagem.
+type DirSource struct {
+	Root string
+}
+
+// List percorre o diretório recursivamente, em ordem lexical. Diretórios não oferecem ETag; a versão do
+// arquivo é identificada pelo tamanho e pela data de modificação.
+func (d DirSource) List(ctx context.Context, fn func(Object) error) error {
+	return filepath.WalkDir(d.Root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == d.Root {
+				return err
+			}
+			return nil // Subdiretório inacessível: segue com os demais
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil // Removido durante a listagem
+		}
+		rel, err := filepath.Rel(d.Root, path)
+		if err != nil {
+			return err
+		}
+		return fn(Object{
+			Key:     filepath.ToSlash(rel),
+			ETag:    fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano()),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	})
+}
+
+// Open abre o arquivo do objeto.
+func (d DirSource) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(d.Root, filepath.FromSlash(key)))
+}
@@ -0,0 +1,246 @@
+package mirror
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// emptyPayloadHash é o SHA-256 do corpo vazio das requisições GET, exigido na assinatura.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// httpClient é usado nas requisições ao S3; o prazo de cada listagem ou download vem do contexto.
+var httpClient = &http.Client{}
+
+// S3Source espelha um bucket S3 ou de um serviço compatível (MinIO, Backblaze B2, Wasabi...), usando a API
+// REST com assinatura AWS Signature Version 4. Sem credenciais, as requisições são anônimas (buckets públicos).
+type S3Source struct {
+	Endpoint  string // URL do serviço compatível, ex: http://minio:9000 (vazio: AWS, https://<bucket>.s3.<region>.amazonaws.com)
+	Region    string // Região usada na assinatura (padrão: us-east-1)
+	Bucket    string
+	Prefix    string // Apenas objetos com este prefixo (opcional)
+	AccessKey string
+	SecretKey string
+
+	now func() time.Time // Relógio da assinatura (substituível para verificação)
+}
+
+// s3ListResult é a resposta do ListObjectsV2.
+type s3ListResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		ETag         string    `xml:"ETag"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+}
+
+// s3Error é o corpo das respostas de erro do S3.
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// List lista os objetos do bucket (sob o prefixo) com ListObjectsV2, página por página.
+func (s *S3Source) List(ctx context.Context, fn func(Object) error) error {
+	token := ""
+	for {
+		query := neturl.Values{"list-type": {"2"}}
+		if s.Prefix != "" {
+			query.Set("prefix", s.Prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.get(ctx, "", query)
+		if err != nil {
+			return err
+		}
+		var page s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("resposta inválida do ListObjectsV2: %w", err)
+		}
+
+		for _, item := range page.Contents {
+			if strings.HasSuffix(item.Key, "/") {
+				continue // Marcadores de "pasta" criados por alguns clientes
+			}
+			err := fn(Object{
+				Key:     item.Key,
+				ETag:    strings.Trim(item.ETag, `"`),
+				Size:    item.Size,
+				ModTime: item.LastModified,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// Open baixa o objeto com GetObject.
+func (s *S3Source) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.get(ctx, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// get executa uma requisição GET assinada sobre o bucket (key vazia) ou um objeto.
+func (s *S3Source) get(ctx context.Context, key string, query neturl.Values) (*http.Response, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, u)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		var apiErr s3Error
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if xml.Unmarshal(body, &apiErr) == nil && apiErr.Code != "" {
+			return nil, fmt.Errorf("S3 respondeu %d (%s): %s", resp.StatusCode, apiErr.Code, apiErr.Message)
+		}
+		return nil, fmt.Errorf("S3 respondeu %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// objectURL monta a URL do bucket ou objeto: estilo virtual-host na AWS e estilo caminho (endpoint/bucket/key)
+// nos serviços compatíveis, que nem sempre têm DNS por bucket.
+func (s *S3Source) objectURL(key string) (*neturl.URL, error) {
+	var u *neturl.URL
+	var err error
+	path := "/" + key
+	if s.Endpoint == "" {
+		u, err = neturl.Parse(fmt.Sprintf("https://%s.s3.%s.amazonaws.com", s.Bucket, s.region()))
+	} else {
+		u, err = neturl.Parse(strings.TrimSuffix(s.Endpoint, "/"))
+		path = "/" + s.Bucket + path
+	}
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("endpoint S3 inválido: '%s'", s.Endpoint)
+	}
+	// RawPath garante a codificação exigida pela assinatura, que difere da padrão do net/url em alguns caracteres
+	u.Path = path
+	u.RawPath = uriEncode(path, false)
+	return u, nil
+}
+
+func (s *S3Source) region() string {
+	if s.Region == "" {
+		return "us-east-1"
+	}
+	return s.Region
+}
+
+// sign assina a requisição (AWS Signature Version 4, cabeçalho Authorization).
+func (s *S3Source) sign(req *http.Request, u *neturl.URL) {
+	if s.AccessKey == "" || s.SecretKey == "" {
+		return
+	}
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	t := now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", emptyPayloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		u.EscapedPath(),
+		u.RawQuery,
+		"host:" + u.Host + "\n" + "x-amz-content-sha256:" + emptyPayloadHash + "\n" + "x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region() + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	key = hmacSHA256(key, s.region())
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery codifica a query string como exigido pela assinatura: parâmetros em ordem e valores
+// codificados conforme a RFC 3986 (espaço como %20).
+func canonicalQuery(query neturl.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode codifica conforme a RFC 3986, preservando apenas A-Z, a-z, 0-9, '-', '.', '_' e '~'
+// (e '/', se encodeSlash for false).
+func uriEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(value) {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
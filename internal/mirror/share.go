@@ -0,0 +1,102 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// ShareSource assina um álbum compartilhado por outra instância do photo-manager, a partir do link
+// (ex: https://fotos.familia.com/s/<token>). A listagem vem do JSON do link, e cada foto é baixada pela rota
+// do arquivo, /s/<token>/photos/<id>/image.
+type ShareSource struct {
+	URL string
+}
+
+// sharedAlbum é a resposta JSON de GET /s/:token.
+type sharedAlbum struct {
+	Data struct {
+		Photos []struct {
+			ID       string    `json:"id"` // Identificador público da foto
+			Filename string    `json:"filename"`
+			Hash     string    `json:"hash"`
+			FileSize int64     `json:"file_size"`
+			Date     time.Time `json:"date"`
+			ImageURL string    `json:"image_url"`
+		} `json:"photos"`
+	} `json:"data"`
+}
+
+// List lista as fotos do álbum compartilhado. A chave de cada objeto é "<id público>/<nome do arquivo>", e o
+// ETag é o hash do arquivo na outra instância.
+func (s ShareSource) List(ctx context.Context, fn func(Object) error) error {
+	resp, err := s.get(ctx, s.URL, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var album sharedAlbum
+	if err := json.NewDecoder(resp.Body).Decode(&album); err != nil {
+		return fmt.Errorf("resposta inválida do link de compartilhamento: %w", err)
+	}
+	for _, photo := range album.Data.Photos {
+		if photo.ImageURL == "" || photo.Filename == "" {
+			return fmt.Errorf("o link não oferece os arquivos das fotos; a outra instância precisa ser atualizada")
+		}
+		err := fn(Object{
+			Key:     photo.ID + "/" + path.Base(photo.Filename),
+			ETag:    photo.Hash,
+			Size:    photo.FileSize,
+			ModTime: photo.Date,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open baixa o arquivo da foto indicada pela chave.
+func (s ShareSource) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	id, _, _ := strings.Cut(key, "/")
+	u, err := neturl.Parse(s.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, "photos", id, "image")
+	u.RawQuery = ""
+	resp, err := s.get(ctx, u.String(), "*/*")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// get executa uma requisição GET ao link, tratando as respostas de erro.
+func (s ShareSource) get(ctx context.Context, url, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("link de compartilhamento não encontrado (%s): pode ter sido revogado", url)
+		}
+		return nil, fmt.Errorf("o link de compartilhamento respondeu %s", resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,196 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sendTimeout é o tempo máximo de envio de cada notificação.
+const sendTimeout = 15 * time.Second
+
+// DefaultEvents são os tipos de evento notificados quando o provedor não define uma lista própria.
+var DefaultEvents = []string{
+	events.TypeImportFinished,
+	events.TypeIntegrityFailure,
+	events.TypePhotoQuarantined,
+	events.TypeStorageLowSpace,
+	events.TypeDownloadReady,
+}
+
+// Message é uma notificação pronta para envio.
+type Message struct {
+	Title  string
+	Body   string
+	Urgent bool // Notificações urgentes usam a prioridade mais alta do provedor
+}
+
+// Notifier envia notificações por um provedor (Telegram, ntfy, Gotify...).
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, msg Message) error
+}
+
+// route associa um provedor aos tipos de evento que ele deve receber.
+type route struct {
+	notifier Notifier
+	events   map[string]bool
+}
+
+// Dispatcher encaminha os eventos do barramento para os provedores configurados para cada tipo de evento.
+type Dispatcher struct {
+	routes []route
+}
+
+// NewDispatcher cria o Dispatcher com os provedores configurados nas opções.
+// Provedores sem as credenciais obrigatórias são ignorados.
+func NewDispatcher(opts Options) *Dispatcher {
+	d := &Dispatcher{}
+	if opts.TelegramToken != "" && opts.TelegramChatID != "" {
+		d.Add(NewTelegram(opts.TelegramToken, opts.TelegramChatID), opts.TelegramEvents)
+	}
+	if opts.NtfyURL != "" {
+		d.Add(NewNtfy(opts.NtfyURL, opts.NtfyToken), opts.NtfyEvents)
+	}
+	if opts.GotifyURL != "" && opts.GotifyToken != "" {
+		d.Add(NewGotify(opts.GotifyURL, opts.GotifyToken), opts.GotifyEvents)
+	}
+	return d
+}
+
+// Add registra um provedor para os tipos de evento informados (vazio usa DefaultEvents).
+func (d *Dispatcher) Add(n Notifier, eventTypes []string) {
+	if len(eventTypes) == 0 {
+		eventTypes = DefaultEvents
+	}
+	r := route{notifier: n, events: make(map[string]bool)}
+	for _, eventType := range eventTypes {
+		r.events[eventType] = true
+	}
+	d.routes = append(d.routes, r)
+	log.Printf("Notificações via %s habilitadas para: %s\n", n.Name(), strings.Join(eventTypes, ", "))
+}
+
+// Enabled indica se há algum provedor configurado.
+func (d *Dispatcher) Enabled() bool {
+	return len(d.routes) > 0
+}
+
+// Subscribe registra o Dispatcher no barramento de eventos.
+func (d *Dispatcher) Subscribe(bus *events.Bus) {
+	bus.Subscribe(d.Handle)
+}
+
+// Handle envia o evento aos provedores interessados. O envio é feito em background, pois o barramento
+// é síncrono e não deve esperar a rede; falhas são apenas registradas no log.
+func (d *Dispatcher) Handle(e events.Event) {
+	var msg *Message
+	for _, r := range d.routes {
+		if !r.events[e.Type] {
+			continue
+		}
+		if msg == nil {
+			formatted := FormatEvent(e)
+			msg = &formatted
+		}
+
+		go func(n Notifier, msg Message) {
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			defer cancel()
+			if err := n.Notify(ctx, msg); err != nil {
+				log.Printf("Erro ao enviar notificação via %s (%s): %v\n", n.Name(), e.Type, err)
+			}
+		}(r.notifier, *msg)
+	}
+}
+
+// FormatEvent monta o texto da notificação de um evento.
+func FormatEvent(e events.Event) Message {
+	switch e.Type {
+	case events.TypeImportFinished:
+		if e.Data["status"] == database.ImportStatusFailed {
+			return Message{
+				Title:  "Importação falhou",
+				Body:   fmt.Sprintf("O job de importação %v (%v) falhou: %v", e.Data["job_id"], e.Data["source_path"], e.Data["error"]),
+				Urgent: true,
+			}
+		}
+		return Message{
+			Title: "Importação concluída",
+			Body: fmt.Sprintf("Job %v (%v): %v importadas, %v atualizadas, %v ignoradas, %v com falha, %v ausentes.",
+				e.Data["job_id"], e.Data["source_path"], e.Data["imported"], e.Data["updated"], e.Data["skipped"], e.Data["failed"], e.Data["missing"]),
+		}
+	case events.TypeMirrorSynced:
+		if e.Data["error"] != nil {
+			return Message{
+				Title:  "Espelhamento interrompido",
+				Body:   fmt.Sprintf("A sincronização da origem '%v' foi interrompida: %v", e.Data["name"], e.Data["error"]),
+				Urgent: true,
+			}
+		}
+		return Message{
+			Title: "Espelhamento sincronizado",
+			Body: fmt.Sprintf("Origem '%v': %v importadas, %v duplicadas, %v com falha.",
+				e.Data["name"], e.Data["imported"], e.Data["duplicates"], e.Data["failed"]),
+		}
+	case events.TypeDownloadReady:
+		if e.Data["status"] == database.ExportStatusFailed {
+			return Message{
+				Title: "Download falhou",
+				Body:  fmt.Sprintf("Não foi possível preparar o download %v: %v", e.Data["job_id"], e.Data["error"]),
+			}
+		}
+		body := fmt.Sprintf("O download %v está pronto: %v foto(s) em %v.", e.Data["job_id"], e.Data["archived"], e.Data["url"])
+		if size, ok := e.Data["size"].(int64); ok {
+			body = fmt.Sprintf("O download %v está pronto: %v foto(s), %d MB, em %v.", e.Data["job_id"], e.Data["archived"], size>>20, e.Data["url"])
+		}
+		return Message{Title: "Download pronto", Body: body}
+	case events.TypeIntegrityFailure:
+		return Message{Title: "Falha de integridade", Body: fmt.Sprint(e.Data["message"]), Urgent: true}
+	case events.TypePhotoQuarantined:
+		return Message{
+			Title:  "Foto em quarentena",
+			Body:   fmt.Sprintf("A foto %v foi colocada em quarentena: %v", e.Data["photo_id"], e.Data["reason"]),
+			Urgent: true,
+		}
+	case events.TypeStorageLowSpace:
+		body := fmt.Sprintf("O volume %v (%v) está com pouco espaço livre.", e.Data["volume"], e.Data["path"])
+		if free, ok := e.Data["free_bytes"].(uint64); ok {
+			body = fmt.Sprintf("O volume %v (%v) está com %d MB livres.", e.Data["volume"], e.Data["path"], free>>20)
+		}
+		return Message{Title: "Pouco espaço em disco", Body: body, Urgent: true}
+	case events.TypeAlbumQuotaExceeded:
+		body := fmt.Sprintf("O álbum '%v' ultrapassou a cota.", e.Data["name"])
+		total, okTotal := e.Data["total_bytes"].(int64)
+		quota, okQuota := e.Data["quota_bytes"].(int64)
+		if okTotal && okQuota {
+			body = fmt.Sprintf("O álbum '%v' ocupa %d MB, acima da cota de %d MB.", e.Data["name"], total>>20, quota>>20)
+		}
+		return Message{Title: "Cota de álbum ultrapassada", Body: body}
+	case events.TypeAlbumPolicyExecuted:
+		return Message{
+			Title: "Política de álbum executada",
+			Body: fmt.Sprintf("A política '%v' (%v) do álbum %v afetou %v foto(s).",
+				e.Data["name"], e.Data["action"], e.Data["album_id"], e.Data["affected"]),
+		}
+	case events.TypePhotoCreated:
+		return Message{Title: "Nova foto", Body: fmt.Sprintf("%v (foto %v) foi adicionada à biblioteca.", e.Data["filename"], e.Data["photo_id"])}
+	default:
+		return Message{Title: e.Type, Body: formatData(e.Data)}
+	}
+}
+
+// formatData formata os dados de um evento como "chave: valor", um por linha.
+func formatData(data map[string]interface{}) string {
+	lines := make([]string, 0, len(data))
+	for key, value := range data {
+		lines = append(lines, fmt.Sprintf("%s: %v", key, value))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,146 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// Options contém a configuração dos provedores de notificação. Cada provedor tem sua própria lista de
+// tipos de evento (vazia usa DefaultEvents).
+type Options struct {
+	TelegramToken  string   // Token do bot (NOTIFY_TELEGRAM_TOKEN)
+	TelegramChatID string   // Chat que recebe as mensagens (NOTIFY_TELEGRAM_CHAT_ID)
+	TelegramEvents []string // NOTIFY_TELEGRAM_EVENTS
+
+	NtfyURL    string   // URL completa do tópico, ex: https://ntfy.sh/minhas-fotos (NOTIFY_NTFY_URL)
+	NtfyToken  string   // Token de acesso opcional (NOTIFY_NTFY_TOKEN)
+	NtfyEvents []string // NOTIFY_NTFY_EVENTS
+
+	GotifyURL    string   // URL do servidor Gotify (NOTIFY_GOTIFY_URL)
+	GotifyToken  string   // Token da aplicação (NOTIFY_GOTIFY_TOKEN)
+	GotifyEvents []string // NOTIFY_GOTIFY_EVENTS
+}
+
+// httpClient é compartilhado pelos provedores; o prazo de cada envio vem do contexto.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// Telegram envia notificações por um bot do Telegram.
+type Telegram struct {
+	Token   string
+	ChatID  string
+	BaseURL string // Padrão: https://api.telegram.org
+}
+
+// NewTelegram cria uma nova instância de Telegram.
+func NewTelegram(token, chatID string) *Telegram {
+	return &Telegram{Token: token, ChatID: chatID, BaseURL: "https://api.telegram.org"}
+}
+
+// Name retorna o nome do provedor.
+func (t *Telegram) Name() string { return "telegram" }
+
+// Notify envia a mensagem ao chat configurado.
+func (t *Telegram) Notify(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id": t.ChatID,
+		"text":    msg.Title + "\n" + msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimSuffix(t.BaseURL, "/"), t.Token), "application/json", body, nil)
+}
+
+// Ntfy publica notificações em um tópico do ntfy (ntfy.sh ou servidor próprio).
+type Ntfy struct {
+	URL   string
+	Token string
+}
+
+// NewNtfy cria uma nova instância de Ntfy.
+func NewNtfy(url, token string) *Ntfy {
+	return &Ntfy{URL: url, Token: token}
+}
+
+// Name retorna o nome do provedor.
+func (n *Ntfy) Name() string { return "ntfy" }
+
+// Notify publica a mensagem no tópico.
+func (n *Ntfy) Notify(ctx context.Context, msg Message) error {
+	headers := map[string]string{"Title": msg.Title}
+	if msg.Urgent {
+		headers["Priority"] = "high"
+	}
+	if n.Token != "" {
+		headers["Authorization"] = "Bearer " + n.Token
+	}
+	return post(ctx, n.URL, "text/plain; charset=utf-8", []byte(msg.Body), headers)
+}
+
+// Gotify envia notificações para um servidor Gotify.
+type Gotify struct {
+	URL   string
+	Token string
+}
+
+// NewGotify cria uma nova instância de Gotify.
+func NewGotify(url, token string) *Gotify {
+	return &Gotify{URL: url, Token: token}
+}
+
+// Name retorna o nome do provedor.
+func (g *Gotify) Name() string { return "gotify" }
+
+// Notify envia a mensagem. O token vai no cabeçalho, para não aparecer em logs de acesso.
+func (g *Gotify) Notify(ctx context.Context, msg Message) error {
+	priority := 5
+	if msg.Urgent {
+		priority = 8
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"title":    msg.Title,
+		"message":  msg.Body,
+		"priority": priority,
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, strings.TrimSuffix(g.URL, "/")+"/message", "application/json", body, map[string]string{"X-Gotify-Key": g.Token})
+}
+
+// post envia a requisição e trata respostas fora da faixa 2xx como erro.
+func post(ctx context.Context, url, contentType string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		// A URL não entra na mensagem: no Telegram ela contém o token do bot
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("resposta inesperada (%s): %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
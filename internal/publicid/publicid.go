@@ -0,0 +1,61 @@
+// Package publicid gera os identificadores públicos de fotos e álbuns: ULIDs imutáveis, usados nas URLs
+// compartilhadas e na API pública no lugar dos IDs sequenciais do banco, que são enumeráveis e mudam quando
+// a biblioteca é reimportada em outro banco.
+package publicid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"strings"
+	"time"
+)
+
+// Length é o tamanho de um identificador público.
+const Length = 26
+
+// alphabet é a base32 de Crockford, sem as letras confundíveis I, L, O e U.
+const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// New gera um ULID: 48 bits do instante da criação, em milissegundos, seguidos de 80 bits aleatórios,
+// codificados em 26 caracteres. Os identificadores ficam em ordem de criação e não podem ser adivinhados.
+func New() string {
+	var id [16]byte
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(id[:6], ms[2:])
+	if _, err := rand.Read(id[6:]); err != nil {
+		panic("publicid: gerador aleatório indisponível: " + err.Error())
+	}
+
+	// Os 128 bits viram 26 grupos de 5 bits (130 bits, com 2 bits zero à esquerda)
+	out := make([]byte, Length)
+	for i := range out {
+		var value byte
+		for bit := i*5 - 2; bit < i*5+3; bit++ {
+			value <<= 1
+			if bit >= 0 {
+				value |= id[bit/8] >> (7 - bit%8) & 1
+			}
+		}
+		out[i] = alphabet[value]
+	}
+	return string(out)
+}
+
+// Normalize converte um identificador informado pelo cliente para a forma gravada (maiúsculas). Retorna
+// false se ele não tiver o formato de um identificador público.
+func Normalize(id string) (string, bool) {
+	if len(id) != Length {
+		return "", false
+	}
+	id = strings.ToUpper(id)
+	for i := 0; i < len(id); i++ {
+		if strings.IndexByte(alphabet, id[i]) < 0 {
+			return "", false
+		}
+	}
+	if id[0] > '7' { // O primeiro caractere carrega só 3 bits
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,342 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Tipos de token.
+const (
+	tokIdent = iota
+	tokString
+	tokNumber
+	tokSymbol
+	tokEnd
+)
+
+// token é um elemento léxico de uma regra.
+type token struct {
+	kind int
+	text string
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokEnd:
+		return "o fim da regra"
+	case tokString:
+		return fmt.Sprintf("\"%s\"", t.text)
+	}
+	return fmt.Sprintf("'%s'", t.text)
+}
+
+// tokenize separa uma regra em tokens. Textos vão entre aspas duplas, com \" e \\ como escapes.
+func tokenize(text string) ([]token, error) {
+	var tokens []token
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\r':
+			i++
+		case r == '"':
+			var value strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+					i++
+				}
+				value.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("aspas não fechadas")
+			}
+			i++
+			tokens = append(tokens, token{kind: tokString, text: value.String()})
+		case r >= '0' && r <= '9' || r == '-' && i+1 < len(runes) && runes[i+1] >= '0' && runes[i+1] <= '9':
+			start := i
+			for i++; i < len(runes) && (runes[i] >= '0' && runes[i] <= '9' || runes[i] == '.'); i++ {
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: string(runes[start:i])})
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+			start := i
+			for ; i < len(runes) && (runes[i] == '_' || runes[i] >= 'a' && runes[i] <= 'z' || runes[i] >= 'A' && runes[i] <= 'Z' || runes[i] >= '0' && runes[i] <= '9'); i++ {
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[start:i])})
+		default:
+			symbol := string(r)
+			if i+1 < len(runes) {
+				if pair := string(runes[i : i+2]); pair == "==" || pair == "!=" || pair == "<=" || pair == ">=" {
+					symbol = pair
+				}
+			}
+			if !symbols[symbol] {
+				return nil, fmt.Errorf("caractere inesperado '%s'", symbol)
+			}
+			tokens = append(tokens, token{kind: tokSymbol, text: symbol})
+			i += len([]rune(symbol))
+		}
+	}
+	return tokens, nil
+}
+
+// symbols são os operadores e separadores aceitos.
+var symbols = map[string]bool{"==": true, "!=": true, "<=": true, ">=": true, "<": true, ">": true, "(": true, ")": true, ",": true, ";": true}
+
+// comparisons são os operadores de comparação simbólicos.
+var comparisons = map[string]bool{"==": true, "!=": true, "<=": true, ">=": true, "<": true, ">": true}
+
+// parser percorre os tokens de uma regra.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{kind: tokEnd}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.peek()
+	if tok.kind != tokEnd {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) done() bool {
+	return p.peek().kind == tokEnd
+}
+
+func (p *parser) describe() string {
+	return p.peek().describe()
+}
+
+// keyword consome a palavra-chave, se for a próxima.
+func (p *parser) keyword(word string) bool {
+	if tok := p.peek(); tok.kind == tokIdent && strings.EqualFold(tok.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// symbol consome o símbolo, se for o próximo.
+func (p *parser) symbol(symbol string) bool {
+	if tok := p.peek(); tok.kind == tokSymbol && tok.text == symbol {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// node é um nó da árvore de uma condição.
+type node interface {
+	eval(facts Facts) interface{}
+}
+
+type orNode struct{ left, right node }
+type andNode struct{ left, right node }
+type notNode struct{ operand node }
+type fieldNode struct{ name string }
+type compareNode struct {
+	op      string
+	field   fieldNode
+	value   interface{}
+	pattern *regexp.Regexp
+}
+
+func (n orNode) eval(f Facts) interface{}    { return truthy(n.left.eval(f)) || truthy(n.right.eval(f)) }
+func (n andNode) eval(f Facts) interface{}   { return truthy(n.left.eval(f)) && truthy(n.right.eval(f)) }
+func (n notNode) eval(f Facts) interface{}   { return !truthy(n.operand.eval(f)) }
+func (n fieldNode) eval(f Facts) interface{} { return f[n.name] }
+
+func (n compareNode) eval(f Facts) interface{} {
+	switch actual := f[n.field.name].(type) {
+	case string:
+		expected, _ := n.value.(string)
+		switch n.op {
+		case "==":
+			return strings.EqualFold(actual, expected)
+		case "!=":
+			return !strings.EqualFold(actual, expected)
+		case "contains":
+			return strings.Contains(strings.ToLower(actual), strings.ToLower(expected))
+		case "matches":
+			return n.pattern.MatchString(actual)
+		}
+	case float64:
+		expected, _ := n.value.(float64)
+		switch n.op {
+		case "==":
+			return actual == expected
+		case "!=":
+			return actual != expected
+		case "<":
+			return actual < expected
+		case "<=":
+			return actual <= expected
+		case ">":
+			return actual > expected
+		case ">=":
+			return actual >= expected
+		}
+	case bool:
+		expected, _ := n.value.(bool)
+		if n.op == "!=" {
+			return actual != expected
+		}
+		return actual == expected
+	}
+	return false
+}
+
+// truthy interpreta o valor de um nó como condição.
+func truthy(value interface{}) bool {
+	b, _ := value.(bool)
+	return b
+}
+
+// conditionKind retorna o tipo do valor produzido por um nó.
+func conditionKind(n node) string {
+	if field, ok := n.(fieldNode); ok {
+		return Fields[field.name]
+	}
+	return kindBool
+}
+
+// parseOr interpreta: and ("or" and)*.
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+// parseAnd interpreta: unary ("and" unary)*.
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+// parseUnary interpreta: "not" unary | "(" or ")" | comparação | campo booleano.
+func (p *parser) parseUnary() (node, error) {
+	if p.keyword("not") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if kind := conditionKind(operand); kind != kindBool {
+			return nil, fmt.Errorf("'not' requer uma condição, não %s", kind)
+		}
+		return notNode{operand}, nil
+	}
+	if p.symbol("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.symbol(")") {
+			return nil, fmt.Errorf("esperado ')' em vez de %s", p.describe())
+		}
+		return inner, nil
+	}
+
+	tok := p.next()
+	if tok.kind != tokIdent {
+		return nil, fmt.Errorf("esperado um campo em vez de %s", tok.describe())
+	}
+	name := strings.ToLower(tok.text)
+	kind, ok := Fields[name]
+	if !ok {
+		return nil, fmt.Errorf("campo desconhecido '%s' (use %s)", tok.text, fieldNames())
+	}
+	field := fieldNode{name: name}
+
+	op := p.peek()
+	isOperator := op.kind == tokSymbol && comparisons[op.text] ||
+		op.kind == tokIdent && (strings.EqualFold(op.text, "contains") || strings.EqualFold(op.text, "matches"))
+	if !isOperator {
+		if kind != kindBool {
+			return nil, fmt.Errorf("o campo '%s' (%s) deve ser comparado a um valor", name, kind)
+		}
+		return field, nil
+	}
+	p.pos++
+	return parseComparison(field, kind, strings.ToLower(op.text), p.next())
+}
+
+// parseComparison monta a comparação de um campo com um valor literal, verificando os tipos.
+func parseComparison(field fieldNode, kind, op string, value token) (node, error) {
+	n := compareNode{op: op, field: field}
+	switch kind {
+	case kindString:
+		if value.kind != tokString {
+			return nil, fmt.Errorf("o campo '%s' deve ser comparado a um texto entre aspas, não %s", field.name, value.describe())
+		}
+		switch op {
+		case "==", "!=", "contains":
+		case "matches":
+			pattern, err := regexp.Compile(value.text)
+			if err != nil {
+				return nil, fmt.Errorf("expressão regular inválida \"%s\": %v", value.text, err)
+			}
+			n.pattern = pattern
+		default:
+			return nil, fmt.Errorf("o operador '%s' não se aplica ao campo de texto '%s'", op, field.name)
+		}
+		n.value = value.text
+	case kindNumber:
+		number, err := strconv.ParseFloat(value.text, 64)
+		if value.kind != tokNumber || err != nil {
+			return nil, fmt.Errorf("o campo '%s' deve ser comparado a um número, não %s", field.name, value.describe())
+		}
+		if op == "contains" || op == "matches" {
+			return nil, fmt.Errorf("o operador '%s' não se aplica ao campo numérico '%s'", op, field.name)
+		}
+		n.value = number
+	case kindBool:
+		if value.kind != tokIdent || (!strings.EqualFold(value.text, "true") && !strings.EqualFold(value.text, "false")) {
+			return nil, fmt.Errorf("o campo '%s' deve ser comparado a true ou false, não %s", field.name, value.describe())
+		}
+		if op != "==" && op != "!=" {
+			return nil, fmt.Errorf("o operador '%s' não se aplica ao campo booleano '%s'", op, field.name)
+		}
+		n.value = strings.EqualFold(value.text, "true")
+	}
+	return n, nil
+}
+
+// fieldNames lista os campos disponíveis, em ordem alfabética.
+func fieldNames() string {
+	names := make([]string, 0, len(Fields))
+	for name := range Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
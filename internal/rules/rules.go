@@ -0,0 +1,302 @@
+// Package rules implementa a linguagem de regras de organização avaliada na ingestão de cada foto.
+//
+// Cada regra ocupa uma linha, no formato "if <condição> then <ação>, <ação>...". Linhas vazias e
+// iniciadas por "#" são ignoradas. Exemplo:
+//
+//	# Fotos de drone vão para o álbum "Drone", numa pasta própria
+//	if make == "DJI" then album "Drone", folder "drone/{year}"
+//	if camera contains "iphone" and year >= 2020 then tag "celular"
+//	if not gps and source == "upload" then tag "sem-local"
+//	if filename matches "^IMG_\d+\.jpg$" then tag "camera"; stop
+//
+// Condições comparam campos da foto (ver Fields) com textos entre aspas, números ou true/false, usando
+// ==, != (textos sem diferenciar maiúsculas), <, <=, >, >= (números), contains e matches (expressão
+// regular) para textos. Campos booleanos podem ser usados sozinhos (ex: "gps"). Combine com and, or, not
+// e parênteses.
+//
+// Ações: tag "<nome>", album "<nome>" (criado se não existir), folder "<modelo>" (pasta de armazenamento,
+// com {year}, {month}, {day}, {make}, {model}, {camera}, {lens}, {source}, {device} e {ext}) e stop, que
+// encerra a avaliação das regras seguintes. Todas as regras verdadeiras são aplicadas, em ordem; a pasta
+// é a da primeira regra verdadeira que definir uma.
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Tipos dos campos.
+const (
+	kindString = "texto"
+	kindNumber = "número"
+	kindBool   = "booleano"
+)
+
+// Fields são os campos disponíveis nas condições, com o tipo de cada um.
+var Fields = map[string]string{
+	"make":         kindString, // Fabricante da câmera
+	"model":        kindString, // Modelo da câmera
+	"camera":       kindString, // Fabricante e modelo
+	"lens":         kindString, // Modelo da lente
+	"filename":     kindString, // Nome original do arquivo
+	"ext":          kindString, // Extensão do arquivo, sem o ponto e em minúsculas
+	"mime":         kindString, // Tipo detectado pelo conteúdo (ex: image/jpeg)
+	"source":       kindString, // Canal de entrada (upload, import, webdav...)
+	"device":       kindString, // Dispositivo informado pelo cliente
+	"year":         kindNumber, // Ano da data EXIF (0 sem data)
+	"month":        kindNumber,
+	"day":          kindNumber,
+	"hour":         kindNumber,
+	"width":        kindNumber,
+	"height":       kindNumber,
+	"megapixels":   kindNumber,
+	"size_mb":      kindNumber, // Tamanho do arquivo em MB
+	"iso":          kindNumber, // 0 se ausente
+	"focal_length": kindNumber, // Em mm; 0 se ausente
+	"gps":          kindBool,   // Tem coordenadas GPS
+	"dated":        kindBool,   // Tem data EXIF
+	"video":        kindBool,
+}
+
+// folderPlaceholders são os campos aceitos nos modelos de pasta.
+var folderPlaceholders = []string{"year", "month", "day", "make", "model", "camera", "lens", "source", "device", "ext"}
+
+// placeholderPattern encontra os campos de um modelo de pasta.
+var placeholderPattern = regexp.MustCompile(`\{([^}]*)\}`)
+
+// Facts são os valores dos campos de uma foto: string, float64 ou bool conforme o tipo em Fields.
+type Facts map[string]interface{}
+
+// Result são as ações das regras verdadeiras para uma foto.
+type Result struct {
+	Tags    []string // Tags a aplicar, sem repetições
+	Albums  []string // Álbuns onde incluir a foto, sem repetições
+	Folder  string   // Pasta de armazenamento relativa ao volume (vazio mantém o layout configurado)
+	Matched []int    // Linhas das regras verdadeiras
+}
+
+// Empty indica se nenhuma ação foi produzida.
+func (r Result) Empty() bool {
+	return len(r.Tags) == 0 && len(r.Albums) == 0 && r.Folder == ""
+}
+
+// Set é um conjunto de regras já interpretado.
+type Set struct {
+	rules []rule
+}
+
+// rule é uma linha do script.
+type rule struct {
+	line    int
+	cond    node
+	actions []action
+}
+
+// action é uma ação de uma regra.
+type action struct {
+	kind  string // tag, album, folder ou stop
+	value string
+}
+
+// Len retorna a quantidade de regras.
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.rules)
+}
+
+// Load lê e interpreta o arquivo de regras.
+func Load(path string) (*Set, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível ler o arquivo de regras: %w", err)
+	}
+	return Parse(string(data))
+}
+
+// Parse interpreta um script de regras. O erro indica a linha e o trecho inválido.
+func Parse(script string) (*Set, error) {
+	set := &Set{}
+	for i, text := range strings.Split(script, "\n") {
+		text = strings.TrimSpace(text)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		r, err := parseRule(text)
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: %w", i+1, err)
+		}
+		r.line = i + 1
+		set.rules = append(set.rules, *r)
+	}
+	return set, nil
+}
+
+// Evaluate aplica as regras, em ordem, aos campos da foto.
+func (s *Set) Evaluate(facts Facts) Result {
+	result := Result{Tags: []string{}, Albums: []string{}, Matched: []int{}}
+	if s == nil {
+		return result
+	}
+	seenTags, seenAlbums := map[string]bool{}, map[string]bool{}
+	for _, r := range s.rules {
+		if !truthy(r.cond.eval(facts)) {
+			continue
+		}
+		result.Matched = append(result.Matched, r.line)
+		stop := false
+		for _, a := range r.actions {
+			switch a.kind {
+			case "tag":
+				if key := strings.ToLower(a.value); !seenTags[key] {
+					seenTags[key] = true
+					result.Tags = append(result.Tags, a.value)
+				}
+			case "album":
+				if key := strings.ToLower(a.value); !seenAlbums[key] {
+					seenAlbums[key] = true
+					result.Albums = append(result.Albums, a.value)
+				}
+			case "folder":
+				if result.Folder == "" {
+					result.Folder = expandFolder(a.value, facts)
+				}
+			case "stop":
+				stop = true
+			}
+		}
+		if stop {
+			break
+		}
+	}
+	return result
+}
+
+// expandFolder preenche o modelo de pasta. Valores vazios viram "desconhecido" e separadores de caminho
+// nos valores são substituídos, de modo que a pasta fique sempre dentro do volume.
+func expandFolder(template string, facts Facts) string {
+	expanded := template
+	for _, name := range folderPlaceholders {
+		placeholder := "{" + name + "}"
+		if !strings.Contains(expanded, placeholder) {
+			continue
+		}
+		expanded = strings.ReplaceAll(expanded, placeholder, folderValue(name, facts[name]))
+	}
+
+	var parts []string
+	for _, part := range strings.Split(filepath.ToSlash(expanded), "/") {
+		part = strings.TrimSpace(part)
+		if part == "" || part == "." || part == ".." {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	return filepath.Join(parts...)
+}
+
+// folderValue formata um campo para uso em um nome de pasta.
+func folderValue(name string, value interface{}) string {
+	var text string
+	switch v := value.(type) {
+	case float64:
+		if v > 0 {
+			text = strconv.Itoa(int(v))
+			if name == "month" || name == "day" {
+				text = fmt.Sprintf("%02d", int(v))
+			}
+		}
+	case string:
+		text = strings.TrimSpace(v)
+	}
+	text = strings.NewReplacer("/", "-", "\\", "-", "..", "_").Replace(text)
+	if text == "" {
+		return "desconhecido"
+	}
+	return text
+}
+
+// parseRule interpreta uma regra: if <condição> then <ações>.
+func parseRule(text string) (*rule, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if !p.keyword("if") {
+		return nil, fmt.Errorf("a regra deve começar com 'if'")
+	}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.keyword("then") {
+		return nil, fmt.Errorf("esperado 'then' em vez de %s", p.describe())
+	}
+	if kind := conditionKind(cond); kind != kindBool {
+		return nil, fmt.Errorf("a condição deve ser verdadeira ou falsa, não %s", kind)
+	}
+
+	r := &rule{cond: cond}
+	for {
+		a, err := p.parseAction()
+		if err != nil {
+			return nil, err
+		}
+		r.actions = append(r.actions, a)
+		if p.done() {
+			return r, nil
+		}
+		if !p.symbol(",") && !p.symbol(";") {
+			return nil, fmt.Errorf("esperado ',' entre as ações em vez de %s", p.describe())
+		}
+	}
+}
+
+// parseAction interpreta uma ação.
+func (p *parser) parseAction() (action, error) {
+	tok := p.next()
+	if tok.kind != tokIdent {
+		return action{}, fmt.Errorf("esperada uma ação (tag, album, folder ou stop) em vez de %s", tok.describe())
+	}
+	kind := strings.ToLower(tok.text)
+	switch kind {
+	case "stop":
+		return action{kind: kind}, nil
+	case "tag", "album", "folder":
+		value := p.next()
+		if value.kind != tokString || strings.TrimSpace(value.text) == "" {
+			return action{}, fmt.Errorf("a ação '%s' requer um texto entre aspas", kind)
+		}
+		text := strings.TrimSpace(value.text)
+		if kind == "tag" && strings.Contains(text, ",") {
+			return action{}, fmt.Errorf("tags não podem conter vírgulas: \"%s\"", text)
+		}
+		if kind == "folder" {
+			if err := checkFolderTemplate(text); err != nil {
+				return action{}, err
+			}
+		}
+		return action{kind: kind, value: text}, nil
+	}
+	return action{}, fmt.Errorf("ação desconhecida '%s' (use tag, album, folder ou stop)", tok.text)
+}
+
+// checkFolderTemplate verifica os campos usados em um modelo de pasta.
+func checkFolderTemplate(template string) error {
+	for _, match := range placeholderPattern.FindAllStringSubmatch(template, -1) {
+		known := false
+		for _, name := range folderPlaceholders {
+			known = known || match[1] == name
+		}
+		if !known {
+			return fmt.Errorf("campo desconhecido na pasta: {%s} (use %s)", match[1], strings.Join(folderPlaceholders, ", "))
+		}
+	}
+	return nil
+}
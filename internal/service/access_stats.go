@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"photo-manager/internal/database"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AccessCounts são os acessos de uma foto.
+type AccessCounts struct {
+	Views     int64
+	Downloads int64
+}
+
+// AccessStatsService contabiliza visualizações e downloads das fotos. Os acessos são acumulados em memória
+// e gravados em lote (um UPDATE incremental por foto a cada Flush), evitando uma escrita por requisição na
+// mesma linha quando muitos clientes abrem a mesma foto.
+type AccessStatsService struct {
+	DB *gorm.DB
+
+	mu      sync.Mutex
+	pending map[uint]AccessCounts
+}
+
+// NewAccessStatsService cria uma nova instância de AccessStatsService.
+func NewAccessStatsService(db *gorm.DB) *AccessStatsService {
+	return &AccessStatsService{
+		DB:      db,
+		pending: make(map[uint]AccessCounts),
+	}
+}
+
+// RecordView registra uma visualização da foto. Seguro para chamar com o serviço nil.
+func (s *AccessStatsService) RecordView(photoID uint) {
+	s.record(photoID, AccessCounts{Views: 1})
+}
+
+// RecordDownload registra um download do arquivo da foto. Seguro para chamar com o serviço nil.
+func (s *AccessStatsService) RecordDownload(photoID uint) {
+	s.record(photoID, AccessCounts{Downloads: 1})
+}
+
+func (s *AccessStatsService) record(photoID uint, delta AccessCounts) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := s.pending[photoID]
+	counts.Views += delta.Views
+	counts.Downloads += delta.Downloads
+	s.pending[photoID] = counts
+}
+
+// ApplyPending soma aos contadores da foto os acessos ainda não gravados, para que o detalhe da foto
+// reflita os acessos mais recentes.
+func (s *AccessStatsService) ApplyPending(photo *database.Photo) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	counts := s.pending[photo.ID]
+	s.mu.Unlock()
+	photo.ViewCount += counts.Views
+	photo.DownloadCount += counts.Downloads
+}
+
+// Flush grava os acessos acumulados. Em caso de erro, os acessos não gravados voltam para o acumulador
+// e são gravados no próximo Flush.
+func (s *AccessStatsService) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = make(map[uint]AccessCounts)
+	s.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for photoID, counts := range batch {
+			// UpdateColumns não altera updated_at: um acesso não é uma modificação da foto
+			result := tx.Model(&database.Photo{}).Where("id = ?", photoID).UpdateColumns(map[string]interface{}{
+				"view_count":     gorm.Expr("view_count + ?", counts.Views),
+				"download_count": gorm.Expr("download_count + ?", counts.Downloads),
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.mu.Lock()
+		for photoID, counts := range batch {
+			current := s.pending[photoID]
+			current.Views += counts.Views
+			current.Downloads += counts.Downloads
+			s.pending[photoID] = current
+		}
+		s.mu.Unlock()
+		return fmt.Errorf("erro ao gravar estatísticas de acesso: %w", err)
+	}
+	return nil
+}
+
+// Start grava os acessos acumulados periodicamente, até o contexto ser cancelado.
+func (s *AccessStatsService) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Flush(ctx); err != nil {
+					log.Printf("%v\n", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// planMaxAge é a idade máxima de um plano para ser confirmado: depois disso a biblioteca pode ter mudado
+// demais, e uma nova simulação é exigida.
+const planMaxAge = 24 * time.Hour
+
+// ErrPlanNotPending indica a confirmação ou o descarte de um plano já executado ou descartado.
+var ErrPlanNotPending = i18n.NewError(i18n.CodePlanNotPending)
+
+// ErrPlanExpired indica a confirmação de um plano mais antigo que planMaxAge.
+var ErrPlanExpired = i18n.NewError(i18n.CodePlanExpired, int(planMaxAge/time.Hour))
+
+// AdminPlanService consulta, descarta e executa os planos de ação gravados pelas simulações das operações
+// administrativas destrutivas (ver DuplicateService.PlanReclaim e AlbumPolicyService.PlanPolicy).
+type AdminPlanService struct {
+	DB         *gorm.DB
+	Duplicates *DuplicateService
+	Policies   *AlbumPolicyService
+}
+
+// NewAdminPlanService cria uma nova instância de AdminPlanService.
+func NewAdminPlanService(db *gorm.DB, ds *DuplicateService, ps *AlbumPolicyService) *AdminPlanService {
+	return &AdminPlanService{
+		DB:         db,
+		Duplicates: ds,
+		Policies:   ps,
+	}
+}
+
+// createPlan grava um plano pendente com suas ações. params são os parâmetros da operação, guardados para
+// a revisão.
+func createPlan(db *gorm.DB, operation string, params interface{}, actions []database.AdminPlanAction) (*database.AdminPlan, error) {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível codificar os parâmetros do plano: %w", err)
+	}
+	plan := &database.AdminPlan{Operation: operation, Params: string(encoded), Status: database.PlanStatusPending, ActionCount: len(actions)}
+	for _, action := range actions {
+		plan.Bytes += action.Bytes
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(plan).Error; err != nil {
+			return err
+		}
+		if len(actions) == 0 {
+			return nil
+		}
+		for i := range actions {
+			actions[i].PlanID = plan.ID
+		}
+		return tx.CreateInBatches(actions, 500).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível gravar o plano: %w", err)
+	}
+	return plan, nil
+}
+
+// AdminPlanFilter são os critérios da listagem de planos.
+type AdminPlanFilter struct {
+	Operation string // Operação (vazio = todas)
+	Status    string // Situação (vazio = todas)
+	Limit     int
+	Offset    int
+}
+
+// ListPlans retorna os planos mais recentes primeiro, com o total para paginação.
+func (s *AdminPlanService) ListPlans(filter AdminPlanFilter) ([]database.AdminPlan, int64, error) {
+	query := s.DB.Model(&database.AdminPlan{})
+	if filter.Operation != "" {
+		query = query.Where("operation = ?", filter.Operation)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("erro ao contar os planos: %w", err)
+	}
+	var plans []database.AdminPlan
+	if err := query.Order("id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&plans).Error; err != nil {
+		return nil, 0, fmt.Errorf("erro ao buscar os planos: %w", err)
+	}
+	return plans, total, nil
+}
+
+// GetPlan retorna um plano com todas as suas ações.
+func (s *AdminPlanService) GetPlan(id uint) (*database.AdminPlan, []database.AdminPlanAction, error) {
+	var plan database.AdminPlan
+	if err := s.DB.First(&plan, id).Error; err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar o plano: %w", err)
+	}
+	actions := []database.AdminPlanAction{}
+	if err := s.DB.Where("plan_id = ?", id).Order("id").Find(&actions).Error; err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar as ações do plano: %w", err)
+	}
+	return &plan, actions, nil
+}
+
+// Discard descarta um plano pendente, que não pode mais ser executado.
+func (s *AdminPlanService) Discard(id uint) (*database.AdminPlan, error) {
+	if _, err := s.claim(id, database.PlanStatusDiscarded); err != nil {
+		return nil, err
+	}
+	plan, _, err := s.GetPlan(id)
+	return plan, err
+}
+
+// Execute confirma um plano pendente e aplica exatamente as ações listadas nele. Cada ação é conferida
+// antes: se a foto foi removida ou o arquivo mudou desde o plano, a ação é ignorada. Falhas em ações
+// individuais são registradas na própria ação e não interrompem a execução.
+func (s *AdminPlanService) Execute(ctx context.Context, id uint) (*database.AdminPlan, []database.AdminPlanAction, error) {
+	plan, err := s.claim(id, database.PlanStatusExecuting)
+	if err != nil {
+		return nil, nil, err
+	}
+	var actions []database.AdminPlanAction
+	if err := s.DB.Where("plan_id = ?", id).Order("id").Find(&actions).Error; err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar as ações do plano: %w", err)
+	}
+
+	done, skipped, failed := 0, 0, 0
+	for i := range actions {
+		action := &actions[i]
+		if ctx.Err() != nil {
+			break // As ações restantes continuam sem situação: não foram aplicadas
+		}
+		err := s.apply(ctx, action)
+		switch {
+		case err == nil:
+			action.Status = database.PlanActionDone
+			done++
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			action.Status, action.Error = database.PlanActionSkipped, i18n.Message(i18n.DefaultLocale, i18n.CodePlanPhotoChanged)
+			skipped++
+		default:
+			action.Status, action.Error = database.PlanActionFailed, err.Error()
+			failed++
+			log.Printf("Plano %d: não foi possível aplicar a ação %d (%s na foto %d): %v\n", plan.ID, action.ID, action.Action, action.PhotoID, err)
+		}
+		if err := s.DB.Model(action).Updates(map[string]interface{}{"status": action.Status, "error": action.Error}).Error; err != nil {
+			log.Printf("Aviso: não foi possível registrar a ação %d do plano %d: %v\n", action.ID, plan.ID, err)
+		}
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"status": database.PlanStatusExecuted, "executed_at": now, "done": done, "skipped": skipped, "failed": failed}
+	if err := s.DB.Model(plan).Updates(updates).Error; err != nil {
+		return nil, nil, fmt.Errorf("não foi possível registrar a execução do plano: %w", err)
+	}
+	plan.Status, plan.ExecutedAt, plan.Done, plan.Skipped, plan.Failed = database.PlanStatusExecuted, &now, done, skipped, failed
+
+	if plan.Operation == database.PlanOperationAlbumPolicy {
+		var params PolicyPlanParams
+		if err := json.Unmarshal([]byte(plan.Params), &params); err == nil {
+			if policy, err := s.Policies.GetPolicy(params.AlbumID, params.PolicyID); err == nil {
+				s.Policies.recordRun(policy, done)
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return plan, actions, ctx.Err()
+	}
+	return plan, actions, nil
+}
+
+// claim passa um plano pendente para a situação informada. A troca é atômica: duas confirmações
+// simultâneas não executam o mesmo plano duas vezes.
+func (s *AdminPlanService) claim(id uint, status string) (*database.AdminPlan, error) {
+	var plan database.AdminPlan
+	if err := s.DB.First(&plan, id).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar o plano: %w", err)
+	}
+	if plan.Status != database.PlanStatusPending {
+		return nil, ErrPlanNotPending
+	}
+	if status == database.PlanStatusExecuting && time.Since(plan.CreatedAt) > planMaxAge {
+		return nil, ErrPlanExpired
+	}
+	result := s.DB.Model(&database.AdminPlan{}).Where("id = ? AND status = ?", id, database.PlanStatusPending).Update("status", status)
+	if result.Error != nil {
+		return nil, fmt.Errorf("não foi possível atualizar o plano: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrPlanNotPending
+	}
+	plan.Status = status
+	return &plan, nil
+}
+
+// apply aplica uma ação do plano, se a foto ainda for a mesma. Retorna gorm.ErrRecordNotFound se a foto
+// mudou ou foi removida, se a foto mantida no lugar de uma duplicata foi removida ou, ao retirar do álbum,
+// se a foto já não está nele.
+func (s *AdminPlanService) apply(ctx context.Context, action *database.AdminPlanAction) error {
+	var photo database.Photo
+	result := s.DB.WithContext(ctx).Select("id", "stored_path", "file_size").Where("id = ?", action.PhotoID).Limit(1).Find(&photo)
+	if result.Error != nil {
+		return fmt.Errorf("erro ao buscar foto: %w", result.Error)
+	}
+	if result.RowsAffected == 0 || photo.StoredPath != action.StoredPath || photo.FileSize != action.FileSize {
+		return gorm.ErrRecordNotFound
+	}
+
+	switch action.Action {
+	case database.PlanActionDeletePhoto:
+		if action.KeepPhotoID != 0 {
+			// A cópia só é apagada se a foto mantida no lugar dela ainda existir
+			var kept int64
+			if err := s.DB.WithContext(ctx).Model(&database.Photo{}).Where("id = ?", action.KeepPhotoID).Count(&kept).Error; err != nil {
+				return fmt.Errorf("erro ao buscar a foto mantida: %w", err)
+			}
+			if kept == 0 {
+				return gorm.ErrRecordNotFound
+			}
+			return s.Duplicates.removeDuplicate(ctx, action.KeepPhotoID, action.PhotoID)
+		}
+		return s.Policies.PhotoService.DeletePhoto(ctx, action.PhotoID, false)
+	case database.PlanActionRemoveFromAlbum:
+		return s.Policies.AlbumService.RemovePhotoFromAlbum(action.AlbumID, action.PhotoID)
+	}
+	return fmt.Errorf("ação de plano desconhecida: '%s'", action.Action)
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"fmt"
+	"photo-manager/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// albumDateRangeSQL calcula o intervalo de datas das fotos de cada álbum. A data efetiva de uma foto é a
+// do EXIF ou, na falta, a do upload, a mesma usada na organização das pastas.
+const albumDateRangeSQL = `UPDATE albums SET
+	start_date = (SELECT MIN(COALESCE(photos.exif_date, photos.upload_date)) FROM album_photos
+		JOIN photos ON photos.id = album_photos.photo_id AND photos.deleted_at IS NULL
+		WHERE album_photos.album_id = albums.id AND album_photos.deleted_at IS NULL),
+	end_date = (SELECT MAX(COALESCE(photos.exif_date, photos.upload_date)) FROM album_photos
+		JOIN photos ON photos.id = album_photos.photo_id AND photos.deleted_at IS NULL
+		WHERE album_photos.album_id = albums.id AND album_photos.deleted_at IS NULL)`
+
+// refreshAlbumDates recalcula StartDate e EndDate dos álbuns informados. Deve ser chamada, na mesma
+// transação, sempre que fotos entram ou saem de um álbum ou têm a data alterada.
+func refreshAlbumDates(tx *gorm.DB, albumIDs []uint) error {
+	if len(albumIDs) == 0 {
+		return nil
+	}
+	if err := tx.Exec(albumDateRangeSQL+" WHERE id IN ?", uniqueIDs(albumIDs)).Error; err != nil {
+		return fmt.Errorf("não foi possível atualizar o período dos álbuns: %w", err)
+	}
+	return nil
+}
+
+// refreshPhotoAlbumDates recalcula o período dos álbuns que contêm as fotos informadas.
+func refreshPhotoAlbumDates(tx *gorm.DB, photoIDs []uint) error {
+	albumIDs, err := albumIDsForPhotos(tx, photoIDs)
+	if err != nil {
+		return err
+	}
+	return refreshAlbumDates(tx, albumIDs)
+}
+
+// albumIDsForPhotos retorna os IDs dos álbuns que contêm alguma das fotos informadas.
+func albumIDsForPhotos(tx *gorm.DB, photoIDs []uint) ([]uint, error) {
+	var albumIDs []uint
+	if len(photoIDs) == 0 {
+		return albumIDs, nil
+	}
+	err := tx.Model(&database.AlbumPhoto{}).Where("photo_id IN ?", photoIDs).Distinct().Pluck("album_id", &albumIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar os álbuns das fotos: %w", err)
+	}
+	return albumIDs, nil
+}
+
+// BackfillDateRanges calcula o período dos álbuns que ainda não o têm (ex: criados antes de o campo
+// existir). Álbuns vazios continuam sem período.
+func (s *AlbumService) BackfillDateRanges() error {
+	result := s.DB.Exec(albumDateRangeSQL + " WHERE start_date IS NULL AND deleted_at IS NULL")
+	if result.Error != nil {
+		return fmt.Errorf("não foi possível calcular o período dos álbuns: %w", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+
+	"gorm.io/gorm"
+)
+
+// AlbumDeleteOptions escolhe o que acontece com as fotos de um álbum removido. Por padrão elas são apenas
+// desassociadas e continuam na biblioteca.
+type AlbumDeleteOptions struct {
+	DeletePhotos bool // Apaga também as fotos que só estão neste álbum
+	// Quantidade de fotos a apagar, conferida dentro da transação (obrigatória com DeletePhotos): o cliente
+	// mostra a prévia ao usuário e confirma exatamente o que viu.
+	Confirm     *int
+	DeleteFiles bool // Apaga também o arquivo original das fotos indexadas no local (ver DeletePhoto)
+}
+
+// AlbumDeleteReport é o resultado da remoção de um álbum.
+type AlbumDeleteReport struct {
+	AlbumID         uint
+	DetachedPhotos  int    // Fotos retiradas do álbum (inclusive as apagadas)
+	DeletedPhotoIDs []uint // Fotos apagadas da biblioteca
+	KeptPhotoIDs    []uint // Fotos mantidas mesmo com DeletePhotos: estão em outros álbuns ou bloqueadas
+}
+
+// AlbumDeleteConfirmationError indica que a remoção das fotos não foi confirmada com a quantidade correta.
+// Nada é alterado; o erro traz a prévia para o cliente confirmar.
+type AlbumDeleteConfirmationError struct {
+	PhotosToDelete int
+	KeptPhotoIDs   []uint
+}
+
+func (e *AlbumDeleteConfirmationError) Error() string {
+	return e.Localize(i18n.DefaultLocale)
+}
+
+// MessageCode retorna o código do erro.
+func (e *AlbumDeleteConfirmationError) MessageCode() string {
+	return i18n.CodeAlbumDeleteConfirmationRequired
+}
+
+// Localize retorna a mensagem no idioma informado.
+func (e *AlbumDeleteConfirmationError) Localize(locale string) string {
+	return i18n.Message(locale, i18n.CodeAlbumDeleteConfirmationRequired, e.PhotosToDelete)
+}
+
+// DeleteAlbum remove (logicamente) um álbum. Numa única transação, as associações às fotos são removidas,
+// assim como os links de compartilhamento, as reações recebidas por eles, as políticas e os canais de entrada
+// que usavam o álbum como destino. As fotos nunca são apagadas, a menos que opts.DeletePhotos seja true:
+// nesse caso são apagadas as fotos que só estão neste álbum e não estão bloqueadas, desde que opts.Confirm
+// seja a quantidade delas (caso contrário retorna *AlbumDeleteConfirmationError). Álbuns bloqueados não
+// podem ser removidos.
+func (s *PhotoService) DeleteAlbum(ctx context.Context, albumID uint, opts AlbumDeleteOptions) (*AlbumDeleteReport, error) {
+	report := &AlbumDeleteReport{AlbumID: albumID, DeletedPhotoIDs: []uint{}, KeptPhotoIDs: []uint{}}
+	var deleted []database.Photo
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var album database.Album
+		if err := tx.First(&album, albumID).Error; err != nil {
+			return fmt.Errorf("erro ao buscar álbum: %w", err)
+		}
+		if album.Locked {
+			return ErrAlbumLocked
+		}
+
+		var photoIDs []uint
+		if err := validAlbumPhotos(tx).Where("album_photos.album_id = ?", albumID).Distinct("album_photos.photo_id").Pluck("album_photos.photo_id", &photoIDs).Error; err != nil {
+			return fmt.Errorf("erro ao buscar as fotos do álbum: %w", err)
+		}
+		report.DetachedPhotos = len(photoIDs)
+
+		if opts.DeletePhotos && len(photoIDs) > 0 {
+			var err error
+			if deleted, err = albumOnlyPhotos(tx, albumID, photoIDs, report); err != nil {
+				return err
+			}
+			if opts.Confirm == nil || *opts.Confirm != len(deleted) {
+				return &AlbumDeleteConfirmationError{PhotosToDelete: len(deleted), KeptPhotoIDs: report.KeptPhotoIDs}
+			}
+		}
+
+		if err := detachAlbums(tx, []uint{albumID}); err != nil {
+			return err
+		}
+		if len(deleted) > 0 {
+			ids := make([]uint, 0, len(deleted))
+			for _, photo := range deleted {
+				ids = append(ids, photo.ID)
+			}
+			if err := deletePhotoRecords(tx, ids); err != nil {
+				return err
+			}
+			report.DeletedPhotoIDs = ids
+		}
+		if err := tx.Delete(&album).Error; err != nil {
+			return fmt.Errorf("não foi possível remover o álbum: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Os arquivos só são apagados depois da transação confirmada; uma falha aqui deixa apenas arquivos soltos
+	for i := range deleted {
+		if err := s.removePhotoFiles(&deleted[i], opts.DeleteFiles); err != nil {
+			log.Printf("Aviso: %v\n", err)
+		}
+		s.Events.Publish(events.TypePhotoDeleted, map[string]interface{}{"photo_id": deleted[i].ID})
+	}
+	s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": albumID, "removed": true})
+	return report, nil
+}
+
+// detachAlbums remove, na transação, tudo o que referencia os álbuns: as associações às fotos, as reações
+// recebidas pelos links de compartilhamento, as políticas, os links e os canais de entrada que usavam o álbum
+// como destino (que voltam a usar o álbum padrão do canal). Usado ao remover um álbum e na limpeza dos vazios.
+func detachAlbums(tx *gorm.DB, albumIDs []uint) error {
+	for _, model := range []interface{}{&database.AlbumPhoto{}, &database.PhotoReaction{}, &database.AlbumPolicy{}, &database.ShareLink{}, &database.SourceAlbum{}} {
+		if err := tx.Unscoped().Where("album_id IN ?", albumIDs).Delete(model).Error; err != nil {
+			return fmt.Errorf("não foi possível desassociar o álbum: %w", err)
+		}
+	}
+	return nil
+}
+
+// albumOnlyPhotos retorna as fotos do álbum que podem ser apagadas com ele: as que não estão em nenhum outro
+// álbum e não estão bloqueadas. As demais são anotadas em report.KeptPhotoIDs.
+func albumOnlyPhotos(tx *gorm.DB, albumID uint, photoIDs []uint, report *AlbumDeleteReport) ([]database.Photo, error) {
+	var shared []uint
+	err := validAlbumPhotos(tx).Where("album_photos.photo_id IN ? AND album_photos.album_id <> ?", photoIDs, albumID).
+		Joins("JOIN albums ON albums.id = album_photos.album_id AND albums.deleted_at IS NULL").
+		Distinct("album_photos.photo_id").Pluck("album_photos.photo_id", &shared).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar as fotos em outros álbuns: %w", err)
+	}
+	locked, err := lockedPhotoIDs(tx, photoIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	inOtherAlbum := make(map[uint]bool, len(shared))
+	for _, id := range shared {
+		inOtherAlbum[id] = true
+	}
+	candidates := make([]uint, 0, len(photoIDs))
+	for _, id := range photoIDs {
+		if inOtherAlbum[id] || locked[id] {
+			report.KeptPhotoIDs = append(report.KeptPhotoIDs, id)
+			continue
+		}
+		candidates = append(candidates, id)
+	}
+
+	var photos []database.Photo
+	if len(candidates) == 0 {
+		return photos, nil
+	}
+	if err := tx.Where("id IN ?", candidates).Order("id").Find(&photos).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar as fotos do álbum: %w", err)
+	}
+	return photos, nil
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/storage"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxZipEntries limita a quantidade de entradas de um arquivo ZIP importado.
+const maxZipEntries = 20000
+
+// AlbumImportService importa arquivos ZIP enviados pelo cliente, criando um álbum por pasta.
+type AlbumImportService struct {
+	PhotoService *PhotoService
+	AlbumService *AlbumService
+
+	Reports *ImportReportService // Relatório de cada importação, com o resultado por arquivo (opcional)
+}
+
+// NewAlbumImportService cria uma nova instância de AlbumImportService.
+func NewAlbumImportService(ps *PhotoService, as *AlbumService) *AlbumImportService {
+	return &AlbumImportService{
+		PhotoService: ps,
+		AlbumService: as,
+	}
+}
+
+// ZipImportReport é o resultado da importação de um arquivo ZIP.
+type ZipImportReport struct {
+	Albums     []ZipImportAlbum   // Álbuns criados ou completados, na ordem das pastas
+	Imported   int                // Fotos novas
+	Duplicates int                // Fotos que já estavam na biblioteca (incluídas no álbum mesmo assim)
+	Skipped    []string           // Entradas ignoradas: arquivos ocultos, de sistema ou com extensão não suportada
+	Failed     []ZipImportFailure // Entradas rejeitadas ou com erro
+
+	ReportID uint // Relatório persistido da importação (0 sem o serviço de relatórios)
+}
+
+// ZipImportAlbum é um álbum preenchido pela importação.
+type ZipImportAlbum struct {
+	ID      uint
+	Name    string
+	Created bool // O álbum foi criado pela importação (false: já existia)
+	Added   int  // Fotos incluídas no álbum
+}
+
+// ZipImportFailure é uma entrada do ZIP que não foi importada.
+type ZipImportFailure struct {
+	Entry string
+	Err   error // *validation.Error, i18n.Error ou erro de armazenamento
+}
+
+// zipAlbum agrupa as entradas de uma pasta do ZIP.
+type zipAlbum struct {
+	name    string
+	entries []*zip.File
+}
+
+// ImportZip expande no servidor o arquivo ZIP e ingere as imagens pelo pipeline normal. Cada pasta
+// de primeiro nível vira um álbum com o seu nome (incluindo as subpastas); imagens na raiz vão para o álbum
+// defaultAlbum (em geral o nome do arquivo ZIP). Com singleAlbum=true, todas as imagens vão para
+// defaultAlbum. Álbuns existentes com o mesmo nome são completados; duplicatas são incluídas no álbum.
+func (s *AlbumImportService) ImportZip(ctx context.Context, archive io.ReaderAt, size int64, archiveName, defaultAlbum string, singleAlbum bool, origin PhotoOrigin) (*ZipImportReport, error) {
+	reader, err := zip.NewReader(archive, size)
+	if err != nil {
+		return nil, i18n.WrapError(err, i18n.CodeZipInvalid)
+	}
+	if len(reader.File) > maxZipEntries {
+		return nil, i18n.NewError(i18n.CodeZipTooManyEntries, maxZipEntries)
+	}
+	if origin.Source == "" {
+		origin.Source = database.SourceUpload
+	}
+
+	report := &ZipImportReport{Skipped: []string{}, Failed: []ZipImportFailure{}, Albums: []ZipImportAlbum{}}
+	groups, sizes := groupZipEntries(reader.File, defaultAlbum, singleAlbum, report)
+
+	// Verifica antes de expandir se os volumes comportam o conteúdo descompactado
+	if err := s.PhotoService.FileManager.CheckBatchCapacity(sizes); err != nil {
+		return nil, err
+	}
+
+	recorder := s.Reports.Begin(database.ImportKindZip, archiveName)
+	report.ReportID = recorder.ReportID()
+	for _, name := range report.Skipped {
+		recorder.Skip(name)
+	}
+	err = s.importZipGroups(ctx, groups, archiveName, origin, report, recorder)
+	recorder.Finish(err)
+	return report, err
+}
+
+// importZipGroups ingere as entradas de cada grupo e preenche os álbuns, registrando cada entrada no
+// relatório. Retorna o erro que interrompeu a importação, se houver.
+func (s *AlbumImportService) importZipGroups(ctx context.Context, groups []*zipAlbum, archiveName string, origin PhotoOrigin, report *ZipImportReport, recorder *ImportRecorder) error {
+	for _, group := range groups {
+		var photoIDs []uint
+		for _, entry := range group.entries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			photo, err := s.importZipEntry(ctx, entry, archiveName, origin)
+			if errors.Is(err, storage.ErrInsufficientStorage) || errors.Is(err, storage.ErrNoVolumeAvailable) {
+				return err // Sem espaço não adianta tentar as entradas restantes
+			}
+			recorder.Record(entry.Name, photo, err)
+			switch {
+			case errors.Is(err, ErrDuplicatePhoto) && photo != nil:
+				report.Duplicates++
+				photoIDs = append(photoIDs, photo.ID)
+			case err != nil:
+				report.Failed = append(report.Failed, ZipImportFailure{Entry: entry.Name, Err: err})
+			default:
+				report.Imported++
+				photoIDs = append(photoIDs, photo.ID)
+			}
+		}
+		if len(photoIDs) == 0 {
+			continue
+		}
+		album, err := s.fillAlbum(group.name, photoIDs)
+		if err != nil {
+			return fmt.Errorf("não foi possível preencher o álbum '%s': %w", group.name, err)
+		}
+		report.Albums = append(report.Albums, *album)
+	}
+	return nil
+}
+
+// groupZipEntries separa as imagens do ZIP por álbum, na ordem dos nomes, e retorna também o tamanho
+// descompactado de cada uma. As entradas ignoradas são registradas no relatório.
+func groupZipEntries(files []*zip.File, defaultAlbum string, singleAlbum bool, report *ZipImportReport) ([]*zipAlbum, []int64) {
+	sorted := append([]*zip.File(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var groups []*zipAlbum
+	byName := map[string]*zipAlbum{}
+	var sizes []int64
+	for _, entry := range sorted {
+		name := strings.ReplaceAll(entry.Name, "\\", "/")
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		base := path.Base(name)
+		if strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(base, ".") || !supportedImportExtensions[strings.ToLower(path.Ext(base))] {
+			report.Skipped = append(report.Skipped, entry.Name)
+			continue
+		}
+
+		albumName := defaultAlbum
+		if folder, _, nested := strings.Cut(strings.TrimPrefix(name, "/"), "/"); nested && !singleAlbum && strings.TrimSpace(folder) != "" {
+			albumName = strings.TrimSpace(folder)
+		}
+		group, ok := byName[strings.ToLower(albumName)]
+		if !ok {
+			group = &zipAlbum{name: albumName}
+			byName[strings.ToLower(albumName)] = group
+			groups = append(groups, group)
+		}
+		group.entries = append(group.entries, entry)
+		sizes = append(sizes, int64(entry.UncompressedSize64))
+	}
+	return groups, sizes
+}
+
+// importZipEntry expande uma entrada do ZIP em um arquivo temporário e a ingere. Em duplicatas, retorna a
+// foto existente junto com ErrDuplicatePhoto.
+func (s *AlbumImportService) importZipEntry(ctx context.Context, entry *zip.File, archiveName string, origin PhotoOrigin) (*database.Photo, error) {
+	src, err := entry.Open()
+	if err != nil {
+		return nil, i18n.WrapError(err, i18n.CodeZipInvalid)
+	}
+	defer src.Close()
+
+	tempFile, err := os.CreateTemp("", "photo-zip-*"+path.Ext(entry.Name))
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível criar arquivo temporário: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+
+	// O leitor do ZIP recusa conteúdo além do tamanho declarado e verifica o CRC ao final
+	size, err := io.Copy(tempFile, contextReader{ctx: ctx, r: src})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, i18n.WrapError(err, i18n.CodeZipInvalid)
+	}
+	if err := tempFile.Close(); err != nil {
+		return nil, fmt.Errorf("não foi possível gravar o arquivo temporário: %w", err)
+	}
+
+	origin.Detail = archiveName + "/" + entry.Name
+	return s.PhotoService.ingestPhoto(ctx, ingestRequest{
+		SourcePath: tempFile.Name(),
+		Filename:   path.Base(strings.ReplaceAll(entry.Name, "\\", "/")),
+		FileSize:   size,
+		UploadDate: time.Now(),
+		Origin:     origin,
+	})
+}
+
+// fillAlbum inclui as fotos no álbum com o nome informado, criando-o se não existir.
+func (s *AlbumImportService) fillAlbum(name string, photoIDs []uint) (*ZipImportAlbum, error) {
+	var existing database.Album
+	found := s.AlbumService.DB.Where("LOWER(name) = LOWER(?)", name).Limit(1).Find(&existing)
+	if found.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar o álbum: %w", found.Error)
+	}
+	if found.RowsAffected == 0 {
+		album, err := s.AlbumService.CreateAlbumWithPhotos(name, "", photoIDs)
+		if err != nil {
+			return nil, err
+		}
+		return &ZipImportAlbum{ID: album.ID, Name: album.Name, Created: true, Added: len(uniqueIDs(photoIDs))}, nil
+	}
+
+	added, err := s.AlbumService.AddPhotosToAlbum(existing.ID, photoIDs)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Importação de ZIP: %d foto(s) incluída(s) no álbum existente '%s'\n", added, existing.Name)
+	return &ZipImportAlbum{ID: existing.ID, Name: existing.Name, Added: added}, nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Motivos pelos quais uma associação entre álbum e foto é considerada órfã.
+const (
+	OrphanLinkSoftDeleted  = "soft_deleted"  // Associação removida logicamente, mas ainda na tabela
+	OrphanLinkAlbumMissing = "album_missing" // O álbum não existe mais
+	OrphanLinkPhotoMissing = "photo_missing" // A foto não existe mais
+	OrphanLinkDuplicate    = "duplicate"     // A mesma foto associada mais de uma vez ao mesmo álbum
+)
+
+// OrphanAlbumLink é uma linha de AlbumPhoto que não representa mais uma foto visível em um álbum.
+type OrphanAlbumLink struct {
+	ID      uint
+	AlbumID uint
+	PhotoID uint
+	Reason  string // Ver constantes OrphanLink*
+}
+
+// EmptyAlbum é um álbum sem nenhuma foto.
+type EmptyAlbum struct {
+	ID        uint
+	Name      string
+	CreatedAt time.Time
+	Pinned    bool
+	Locked    bool
+}
+
+// Protected indica se o álbum nunca é removido pela limpeza (fixado ou bloqueado).
+func (a EmptyAlbum) Protected() bool {
+	return a.Pinned || a.Locked
+}
+
+// AlbumHealthReport é o diagnóstico da tabela de junção e dos álbuns, com o resultado da limpeza quando
+// houver uma.
+type AlbumHealthReport struct {
+	OrphanLinks     []OrphanAlbumLink
+	EmptyAlbums     []EmptyAlbum
+	PhotosInNoAlbum int64 // Fotos fora de qualquer álbum (listadas em GET /photos?not_in_album=true)
+	DryRun          bool
+	RemovedLinks    int
+	RemovedAlbumIDs []uint
+	SkippedAlbumIDs []uint // Álbuns vazios mantidos: fixados, bloqueados ou mais novos que o limite
+}
+
+// AlbumCleanupOptions escolhe o que a limpeza remove.
+type AlbumCleanupOptions struct {
+	OrphanLinks    bool          // Remove as associações órfãs
+	EmptyAlbums    bool          // Remove os álbuns vazios (exceto fixados e bloqueados)
+	EmptyOlderThan time.Duration // Só remove álbuns vazios criados há mais tempo que isso (0 = qualquer idade)
+	DryRun         bool          // Apenas informa o que seria removido
+}
+
+// AlbumHealth diagnostica associações órfãs, álbuns vazios e fotos fora de qualquer álbum, sem alterar nada.
+func (s *AlbumService) AlbumHealth(ctx context.Context) (*AlbumHealthReport, error) {
+	report := &AlbumHealthReport{DryRun: true}
+	db := s.DB.WithContext(ctx)
+
+	// A primeira associação ativa de cada par álbum/foto é a válida; as demais são repetições
+	err := db.Raw(`
+		SELECT ap.id, ap.album_id, ap.photo_id,
+			CASE
+				WHEN ap.deleted_at IS NOT NULL THEN ?
+				WHEN a.id IS NULL THEN ?
+				WHEN p.id IS NULL THEN ?
+				ELSE ?
+			END AS reason
+		FROM album_photos ap
+		LEFT JOIN albums a ON a.id = ap.album_id AND a.deleted_at IS NULL
+		LEFT JOIN photos p ON p.id = ap.photo_id AND p.deleted_at IS NULL
+		WHERE ap.deleted_at IS NOT NULL OR a.id IS NULL OR p.id IS NULL
+			OR ap.id NOT IN (SELECT MIN(id) FROM album_photos WHERE deleted_at IS NULL GROUP BY album_id, photo_id)
+		ORDER BY ap.id`,
+		OrphanLinkSoftDeleted, OrphanLinkAlbumMissing, OrphanLinkPhotoMissing, OrphanLinkDuplicate,
+	).Scan(&report.OrphanLinks).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar associações órfãs: %w", err)
+	}
+
+	err = db.Model(&database.Album{}).
+		Select("id, name, created_at, pinned, locked").
+		Where("NOT EXISTS (?)", validAlbumPhotos(db).Where("album_photos.album_id = albums.id")).
+		Order("id").
+		Scan(&report.EmptyAlbums).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar álbuns vazios: %w", err)
+	}
+
+	err = db.Model(&database.Photo{}).
+		Where("id NOT IN (?)", validAlbumPhotos(db).Select("album_photos.photo_id")).
+		Count(&report.PhotosInNoAlbum).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao contar as fotos fora de álbuns: %w", err)
+	}
+	return report, nil
+}
+
+// CleanupAlbums remove as associações órfãs e, se solicitado, os álbuns vazios com tudo o que os referencia
+// (ver detachAlbums). Álbuns fixados ou bloqueados nunca são removidos. Retorna o diagnóstico anterior à
+// limpeza com o que foi (ou, na simulação, seria) removido.
+func (s *AlbumService) CleanupAlbums(ctx context.Context, opts AlbumCleanupOptions) (*AlbumHealthReport, error) {
+	report, err := s.AlbumHealth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.DryRun = opts.DryRun
+
+	var linkIDs []uint
+	if opts.OrphanLinks {
+		for _, link := range report.OrphanLinks {
+			linkIDs = append(linkIDs, link.ID)
+		}
+	}
+	var albumIDs []uint
+	if opts.EmptyAlbums {
+		cutoff := time.Now().Add(-opts.EmptyOlderThan)
+		for _, album := range report.EmptyAlbums {
+			if album.Protected() || (opts.EmptyOlderThan > 0 && album.CreatedAt.After(cutoff)) {
+				report.SkippedAlbumIDs = append(report.SkippedAlbumIDs, album.ID)
+				continue
+			}
+			albumIDs = append(albumIDs, album.ID)
+		}
+	}
+
+	report.RemovedLinks = len(linkIDs)
+	report.RemovedAlbumIDs = albumIDs
+	if opts.DryRun || (len(linkIDs) == 0 && len(albumIDs) == 0) {
+		return report, nil
+	}
+
+	err = s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(linkIDs) > 0 {
+			if err := tx.Unscoped().Delete(&database.AlbumPhoto{}, linkIDs).Error; err != nil {
+				return fmt.Errorf("não foi possível remover as associações órfãs: %w", err)
+			}
+		}
+		if len(albumIDs) == 0 {
+			return nil
+		}
+		// Um álbum vazio ainda pode ter associações a fotos removidas, já contadas como órfãs
+		if err := detachAlbums(tx, albumIDs); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&database.Album{}, albumIDs).Error; err != nil {
+			return fmt.Errorf("não foi possível remover os álbuns vazios: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, albumID := range albumIDs {
+		s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": albumID, "removed": true})
+	}
+	if len(linkIDs) > 0 {
+		s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"orphan_links_removed": len(linkIDs)})
+	}
+	return report, nil
+}
+
+// StartOrphanCleanup remove periodicamente as associações órfãs, até o contexto ser cancelado. Álbuns
+// vazios nunca são removidos automaticamente.
+func (s *AlbumService) StartOrphanCleanup(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := s.CleanupAlbums(ctx, AlbumCleanupOptions{OrphanLinks: true})
+				if err != nil {
+					log.Printf("Erro na limpeza de associações órfãs dos álbuns: %v\n", err)
+					continue
+				}
+				if report.RemovedLinks > 0 {
+					log.Printf("Limpeza de álbuns: %d associação(ões) órfã(s) removida(s)\n", report.RemovedLinks)
+				}
+			}
+		}
+	}()
+}
+
+// validAlbumPhotos retorna a consulta das associações ativas cuja foto existe.
+func validAlbumPhotos(db *gorm.DB) *gorm.DB {
+	return db.Table("album_photos").
+		Select("1").
+		Joins("JOIN photos ON photos.id = album_photos.photo_id AND photos.deleted_at IS NULL").
+		Where("album_photos.deleted_at IS NULL")
+}
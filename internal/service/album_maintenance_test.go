@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"photo-manager/internal/database"
+	"testing"
+)
+
+// TestCleanupAlbumsRemovesSourceMapping remove um álbum vazio que era o destino de um dispositivo: o
+// mapeamento sai junto e as fotos do dispositivo voltam a ir para o álbum padrão do canal.
+func TestCleanupAlbumsRemovesSourceMapping(t *testing.T) {
+	ps := newTestPhotoService(t)
+	s := NewAlbumService(ps.DB, nil)
+
+	camera := database.Album{Name: "Portaria"}
+	fallback := database.Album{Name: "Uploads"}
+	photo := database.Photo{Filename: "a.jpg", StoredPath: "/fotos/a.jpg", Hash: "abc"}
+	for _, record := range []interface{}{&camera, &fallback, &photo} {
+		if err := ps.DB.Create(record).Error; err != nil {
+			t.Fatalf("criar %T: %v", record, err)
+		}
+	}
+	records := []interface{}{
+		&database.AlbumPhoto{AlbumID: fallback.ID, PhotoID: photo.ID},
+		&database.SourceAlbum{Source: database.SourceUpload, Device: "porta", AlbumID: camera.ID},
+		&database.SourceAlbum{Source: database.SourceUpload, AlbumID: fallback.ID},
+		&database.PhotoReaction{AlbumID: camera.ID, PhotoID: photo.ID, Voter: "Ana", Kind: database.ReactionHeart},
+	}
+	for _, record := range records {
+		if err := ps.DB.Create(record).Error; err != nil {
+			t.Fatalf("criar %T: %v", record, err)
+		}
+	}
+
+	report, err := s.CleanupAlbums(context.Background(), AlbumCleanupOptions{EmptyAlbums: true})
+	if err != nil {
+		t.Fatalf("limpar álbuns: %v", err)
+	}
+	if len(report.RemovedAlbumIDs) != 1 || report.RemovedAlbumIDs[0] != camera.ID {
+		t.Fatalf("álbuns removidos: %v, esperado [%d]", report.RemovedAlbumIDs, camera.ID)
+	}
+
+	for _, model := range []interface{}{&database.SourceAlbum{}, &database.PhotoReaction{}} {
+		var count int64
+		if err := ps.DB.Unscoped().Model(model).Where("album_id = ?", camera.ID).Count(&count).Error; err != nil || count != 0 {
+			t.Fatalf("%T do álbum removido: %d (%v), esperado 0", model, count, err)
+		}
+	}
+	album, err := findSourceAlbum(ps.DB, database.SourceUpload, "porta")
+	if err != nil {
+		t.Fatalf("buscar álbum padrão: %v", err)
+	}
+	if album == nil || album.ID != fallback.ID {
+		t.Fatalf("álbum padrão do dispositivo: %+v, esperado o álbum do canal (%d)", album, fallback.ID)
+	}
+}
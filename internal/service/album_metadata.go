@@ -0,0 +1,127 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limites dos campos personalizados dos álbuns.
+const (
+	maxAlbumMetadataFields   = 50
+	maxAlbumMetadataKeyLen   = 64
+	maxAlbumMetadataValueLen = 2000
+)
+
+// AlbumMetadata retorna os campos personalizados do álbum (ex: Cliente, Local, Cachê). Nunca é nil.
+func AlbumMetadata(album database.Album) map[string]string {
+	fields := map[string]string{}
+	if album.Metadata != "" {
+		_ = json.Unmarshal([]byte(album.Metadata), &fields)
+	}
+	return fields
+}
+
+// SetAlbumMetadata grava os campos personalizados do álbum. Sem merge, os campos informados substituem todos os
+// anteriores; com merge, são combinados com eles, e um valor vazio remove o campo. Os nomes não diferenciam
+// maiúsculas de minúsculas: "cliente" atualiza o campo "Cliente". Álbuns bloqueados também aceitam a alteração,
+// que não mexe nas fotos.
+func (s *AlbumService) SetAlbumMetadata(id uint, fields map[string]string, merge bool) (*AlbumSummary, error) {
+	album, err := s.GetAlbum(id)
+	if err != nil {
+		return nil, err
+	}
+
+	next := map[string]string{}
+	if merge {
+		next = AlbumMetadata(album.Album)
+	}
+	informed := make(map[string]string, len(fields)) // Nome em minúsculas -> nome informado
+	for key, value := range fields {
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if key == "" || strings.Contains(key, ":") || utf8.RuneCountInString(key) > maxAlbumMetadataKeyLen {
+			return nil, i18n.NewError(i18n.CodeAlbumMetadataKeyInvalid, key, maxAlbumMetadataKeyLen)
+		}
+		if utf8.RuneCountInString(value) > maxAlbumMetadataValueLen {
+			return nil, i18n.NewError(i18n.CodeAlbumMetadataTooLarge, maxAlbumMetadataFields, maxAlbumMetadataValueLen)
+		}
+		if other, ok := informed[strings.ToLower(key)]; ok {
+			return nil, i18n.NewError(i18n.CodeAlbumMetadataDuplicateKey, other, key)
+		}
+		informed[strings.ToLower(key)] = key
+
+		for existing := range next {
+			if strings.EqualFold(existing, key) {
+				delete(next, existing)
+			}
+		}
+		if value != "" {
+			next[key] = value
+		}
+	}
+	if len(next) > maxAlbumMetadataFields {
+		return nil, i18n.NewError(i18n.CodeAlbumMetadataTooLarge, maxAlbumMetadataFields, maxAlbumMetadataValueLen)
+	}
+
+	encoded := ""
+	if len(next) > 0 {
+		data, err := json.Marshal(next)
+		if err != nil {
+			return nil, err
+		}
+		encoded = string(data)
+	}
+	if err := s.DB.Model(&database.Album{}).Where("id = ?", id).Update("metadata", encoded).Error; err != nil {
+		return nil, fmt.Errorf("não foi possível atualizar os campos do álbum: %w", err)
+	}
+
+	s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": id})
+	return s.GetAlbum(id)
+}
+
+// AlbumMetadataCondition é um filtro pelos campos personalizados: o campo Key preenchido ou, com HasValue, com
+// o valor Value. A comparação não diferencia maiúsculas de minúsculas.
+type AlbumMetadataCondition struct {
+	Key      string
+	Value    string
+	HasValue bool
+}
+
+// ParseAlbumMetadataConditions interpreta os filtros "Cliente" (campo preenchido) e "Cliente:Maria" (valor).
+func ParseAlbumMetadataConditions(values []string) []AlbumMetadataCondition {
+	var conditions []AlbumMetadataCondition
+	for _, value := range values {
+		key, expected, hasValue := strings.Cut(value, ":")
+		conditions = append(conditions, AlbumMetadataCondition{
+			Key:      strings.TrimSpace(key),
+			Value:    strings.TrimSpace(expected),
+			HasValue: hasValue,
+		})
+	}
+	return conditions
+}
+
+// MatchAlbumMetadata indica se o álbum atende a todas as condições.
+func MatchAlbumMetadata(album database.Album, conditions []AlbumMetadataCondition) bool {
+	if len(conditions) == 0 {
+		return true
+	}
+	fields := AlbumMetadata(album)
+	for _, condition := range conditions {
+		found := false
+		for key, value := range fields {
+			if strings.EqualFold(key, condition.Key) && (!condition.HasValue || strings.EqualFold(value, condition.Value)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
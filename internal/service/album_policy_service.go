@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrPolicyNotConfirmed indica uma tentativa de executar uma política cuja prévia ainda não foi confirmada.
+var ErrPolicyNotConfirmed = i18n.NewError(i18n.CodePolicyNotConfirmed)
+
+// screenshotPatterns identificam capturas de tela pelo nome do arquivo (em minúsculas).
+var screenshotPatterns = []string{"screenshot", "screen shot", "screen_shot", "captura de tela", "screencap"}
+
+// AlbumPolicyService gerencia as políticas de ciclo de vida e as cotas flexíveis dos álbuns.
+type AlbumPolicyService struct {
+	DB           *gorm.DB
+	PhotoService *PhotoService
+	AlbumService *AlbumService
+	Events       *events.Bus
+}
+
+// NewAlbumPolicyService cria uma nova instância de AlbumPolicyService.
+func NewAlbumPolicyService(db *gorm.DB, ps *PhotoService, as *AlbumService, bus *events.Bus) *AlbumPolicyService {
+	return &AlbumPolicyService{
+		DB:           db,
+		PhotoService: ps,
+		AlbumService: as,
+		Events:       bus,
+	}
+}
+
+// PolicyPreview lista as fotos que uma política afetaria se fosse executada agora.
+type PolicyPreview struct {
+	PhotoIDs  []uint // Fotos que seriam apagadas ou retiradas do álbum
+	Bytes     int64  // Soma do tamanho dos arquivos dessas fotos
+	LockedIDs []uint // Fotos que atendem aos critérios, mas estão bloqueadas e serão preservadas
+}
+
+// ListPolicies retorna as políticas do álbum.
+func (s *AlbumPolicyService) ListPolicies(albumID uint) ([]database.AlbumPolicy, error) {
+	if _, err := s.AlbumService.GetAlbum(albumID); err != nil {
+		return nil, err
+	}
+
+	var policies []database.AlbumPolicy
+	if result := s.DB.Where("album_id = ?", albumID).Order("id ASC").Find(&policies); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar políticas do álbum: %w", result.Error)
+	}
+	return policies, nil
+}
+
+// CreatePolicy cria uma política no álbum. A política nasce não confirmada: nada é executado até a confirmação.
+func (s *AlbumPolicyService) CreatePolicy(policy *database.AlbumPolicy) error {
+	if err := validatePolicy(policy); err != nil {
+		return err
+	}
+	if _, err := s.AlbumService.GetAlbum(policy.AlbumID); err != nil {
+		return err
+	}
+
+	policy.Confirmed = false
+	if result := s.DB.Create(policy); result.Error != nil {
+		return fmt.Errorf("não foi possível criar a política: %w", result.Error)
+	}
+	return nil
+}
+
+// DeletePolicy remove uma política do álbum.
+func (s *AlbumPolicyService) DeletePolicy(albumID, policyID uint) error {
+	result := s.DB.Unscoped().Where("id = ? AND album_id = ?", policyID, albumID).Delete(&database.AlbumPolicy{})
+	if result.Error != nil {
+		return fmt.Errorf("não foi possível remover a política: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("erro ao buscar política: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// GetPolicy retorna uma política do álbum.
+func (s *AlbumPolicyService) GetPolicy(albumID, policyID uint) (*database.AlbumPolicy, error) {
+	var policy database.AlbumPolicy
+	if result := s.DB.Where("id = ? AND album_id = ?", policyID, albumID).First(&policy); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar política: %w", result.Error)
+	}
+	return &policy, nil
+}
+
+// Preview calcula as fotos que a política afetaria agora, sem alterar nada.
+func (s *AlbumPolicyService) Preview(ctx context.Context, policy *database.AlbumPolicy) (*PolicyPreview, error) {
+	var photos []database.Photo
+	query := s.DB.WithContext(ctx).Model(&database.Photo{}).
+		Select("photos.id", "photos.filename", "photos.file_size").
+		Joins("JOIN album_photos ON album_photos.photo_id = photos.id AND album_photos.deleted_at IS NULL").
+		Where("album_photos.album_id = ?", policy.AlbumID).
+		Where("COALESCE(photos.exif_date, photos.upload_date) < ?", time.Now().AddDate(0, 0, -policy.OlderThanDays))
+	if policy.ExceptFavorites {
+		query = query.Where("photos.favorite = ?", false)
+	}
+	if result := query.Order("photos.id ASC").Find(&photos); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar fotos da política: %w", result.Error)
+	}
+
+	preview := &PolicyPreview{PhotoIDs: []uint{}, LockedIDs: []uint{}}
+	var candidates []database.Photo
+	var ids []uint
+	for _, photo := range photos {
+		if policy.ScreenshotsOnly && !isScreenshot(photo.Filename) {
+			continue
+		}
+		candidates = append(candidates, photo)
+		ids = append(ids, photo.ID)
+	}
+
+	// Retirar do álbum só é bloqueado pelo bloqueio do próprio álbum (verificado na execução);
+	// apagar respeita o bloqueio de cada foto
+	locked := map[uint]bool{}
+	if policy.Action == database.AlbumPolicyActionDelete {
+		var err error
+		if locked, err = lockedPhotoIDs(s.DB.WithContext(ctx), ids); err != nil {
+			return nil, err
+		}
+	}
+	for _, photo := range candidates {
+		if locked[photo.ID] {
+			preview.LockedIDs = append(preview.LockedIDs, photo.ID)
+			continue
+		}
+		preview.PhotoIDs = append(preview.PhotoIDs, photo.ID)
+		preview.Bytes += photo.FileSize
+	}
+	return preview, nil
+}
+
+// Confirm libera a política para execução pelo agendador, após a revisão da prévia.
+func (s *AlbumPolicyService) Confirm(albumID, policyID uint) (*database.AlbumPolicy, error) {
+	policy, err := s.GetPolicy(albumID, policyID)
+	if err != nil {
+		return nil, err
+	}
+	if result := s.DB.Model(policy).Update("confirmed", true); result.Error != nil {
+		return nil, fmt.Errorf("não foi possível confirmar a política: %w", result.Error)
+	}
+	policy.Confirmed = true
+	return policy, nil
+}
+
+// Execute aplica a política às fotos selecionadas. Falhas em fotos individuais são registradas no log e
+// não interrompem a execução. Retorna quantas fotos foram afetadas.
+func (s *AlbumPolicyService) Execute(ctx context.Context, policy *database.AlbumPolicy) (int, error) {
+	if !policy.Confirmed {
+		return 0, ErrPolicyNotConfirmed
+	}
+
+	preview, err := s.Preview(ctx, policy)
+	if err != nil {
+		return 0, err
+	}
+
+	affected := 0
+	for _, id := range preview.PhotoIDs {
+		if err := ctx.Err(); err != nil {
+			return affected, err
+		}
+
+		var err error
+		if policy.Action == database.AlbumPolicyActionDelete {
+			err = s.PhotoService.DeletePhoto(ctx, id, false)
+		} else {
+			err = s.AlbumService.RemovePhotoFromAlbum(policy.AlbumID, id)
+		}
+		if err != nil {
+			if errors.Is(err, ErrAlbumLocked) {
+				log.Printf("Política %d ignorada: o álbum %d está bloqueado\n", policy.ID, policy.AlbumID)
+				break
+			}
+			log.Printf("Política %d: não foi possível processar a foto %d: %v\n", policy.ID, id, err)
+			continue
+		}
+		affected++
+	}
+
+	s.recordRun(policy, affected)
+	return affected, nil
+}
+
+// recordRun registra a execução da política (pelo agendador ou pela confirmação de um plano) e, se alguma
+// foto foi afetada, emite o evento album.policy_executed.
+func (s *AlbumPolicyService) recordRun(policy *database.AlbumPolicy, affected int) {
+	now := time.Now()
+	s.DB.Model(policy).Updates(map[string]interface{}{"last_run_at": now, "last_run_affected": affected})
+	policy.LastRunAt, policy.LastRunAffected = &now, affected
+
+	if affected > 0 {
+		log.Printf("Política %d (%s) do álbum %d executada: %d foto(s)\n", policy.ID, policy.Name, policy.AlbumID, affected)
+		s.Events.Publish(events.TypeAlbumPolicyExecuted, map[string]interface{}{
+			"album_id":  policy.AlbumID,
+			"policy_id": policy.ID,
+			"name":      policy.Name,
+			"action":    policy.Action,
+			"affected":  affected,
+		})
+	}
+}
+
+// PolicyPlanParams são os parâmetros gravados no plano de uma política.
+type PolicyPlanParams struct {
+	AlbumID  uint   `json:"album_id"`
+	PolicyID uint   `json:"policy_id"`
+	Name     string `json:"name"`
+	Action   string `json:"action"`
+}
+
+// PlanPolicy simula a execução da política e grava o plano de ação para revisão: cada foto a apagar (com o
+// arquivo e os bytes liberados) ou a retirar do álbum. Ao contrário de Confirm, que libera a política para
+// o agendador, a confirmação do plano (AdminPlanService.Execute) aplica uma única vez exatamente as ações
+// listadas, mesmo que a política não esteja confirmada.
+func (s *AlbumPolicyService) PlanPolicy(ctx context.Context, policy *database.AlbumPolicy) (*database.AdminPlan, error) {
+	preview, err := s.Preview(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	var photos []database.Photo
+	if len(preview.PhotoIDs) > 0 {
+		err := s.DB.WithContext(ctx).Select("id", "stored_path", "file_size", "managed_externally").
+			Where("id IN ?", preview.PhotoIDs).Order("id").Find(&photos).Error
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar fotos da política: %w", err)
+		}
+	}
+
+	actions := make([]database.AdminPlanAction, 0, len(photos))
+	for _, photo := range photos {
+		action := database.AdminPlanAction{
+			Action:     database.PlanActionRemoveFromAlbum,
+			PhotoID:    photo.ID,
+			AlbumID:    policy.AlbumID,
+			StoredPath: photo.StoredPath,
+			FileSize:   photo.FileSize,
+		}
+		if policy.Action == database.AlbumPolicyActionDelete {
+			action.Action, action.AlbumID = database.PlanActionDeletePhoto, 0
+			if !photo.ManagedExternally {
+				action.Bytes = photo.FileSize
+			}
+		}
+		actions = append(actions, action)
+	}
+	params := PolicyPlanParams{AlbumID: policy.AlbumID, PolicyID: policy.ID, Name: policy.Name, Action: policy.Action}
+	return createPlan(s.DB.WithContext(ctx), database.PlanOperationAlbumPolicy, params, actions)
+}
+
+// SetSoftQuota define a cota flexível do álbum, em bytes (0 remove a cota).
+func (s *AlbumPolicyService) SetSoftQuota(albumID uint, quotaBytes int64) (*AlbumSummary, error) {
+	result := s.DB.Model(&database.Album{}).Where("id = ?", albumID).Update("soft_quota_bytes", quotaBytes)
+	if result.Error != nil {
+		return nil, fmt.Errorf("não foi possível atualizar o álbum: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("erro ao buscar álbum: %w", gorm.ErrRecordNotFound)
+	}
+
+	s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": albumID})
+	if err := s.CheckQuotas(); err != nil {
+		return nil, err
+	}
+	return s.AlbumService.GetAlbum(albumID)
+}
+
+// CheckQuotas verifica as cotas flexíveis e emite um aviso para cada álbum que passou a ultrapassá-la.
+// Álbuns que continuam acima da cota não geram avisos repetidos.
+func (s *AlbumPolicyService) CheckQuotas() error {
+	albums, err := s.AlbumService.ListAlbums()
+	if err != nil {
+		return err
+	}
+
+	for _, album := range albums {
+		exceeded := album.SoftQuotaBytes > 0 && album.TotalBytes > album.SoftQuotaBytes
+		if exceeded == album.QuotaExceeded {
+			continue
+		}
+		if result := s.DB.Model(&database.Album{}).Where("id = ?", album.ID).Update("quota_exceeded", exceeded); result.Error != nil {
+			return fmt.Errorf("não foi possível atualizar a cota do álbum %d: %w", album.ID, result.Error)
+		}
+		s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": album.ID})
+		if exceeded {
+			log.Printf("Aviso: o álbum '%s' ultrapassou a cota (%d de %d bytes)\n", album.Name, album.TotalBytes, album.SoftQuotaBytes)
+			s.Events.Publish(events.TypeAlbumQuotaExceeded, map[string]interface{}{
+				"album_id":    album.ID,
+				"name":        album.Name,
+				"total_bytes": album.TotalBytes,
+				"quota_bytes": album.SoftQuotaBytes,
+			})
+		}
+	}
+	return nil
+}
+
+// RunOnce executa as políticas confirmadas e verifica as cotas.
+func (s *AlbumPolicyService) RunOnce(ctx context.Context) {
+	var policies []database.AlbumPolicy
+	if result := s.DB.WithContext(ctx).Where("confirmed = ?", true).Order("id ASC").Find(&policies); result.Error != nil {
+		log.Printf("Erro ao buscar políticas de álbuns: %v\n", result.Error)
+		return
+	}
+	for i := range policies {
+		if _, err := s.Execute(ctx, &policies[i]); err != nil {
+			log.Printf("Erro ao executar a política %d: %v\n", policies[i].ID, err)
+		}
+	}
+
+	if err := s.CheckQuotas(); err != nil {
+		log.Printf("Erro ao verificar cotas dos álbuns: %v\n", err)
+	}
+}
+
+// StartScheduler executa RunOnce periodicamente, até o contexto ser cancelado.
+func (s *AlbumPolicyService) StartScheduler(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.RunOnce(ctx)
+			}
+		}
+	}()
+}
+
+// validatePolicy verifica os campos da política.
+func validatePolicy(policy *database.AlbumPolicy) error {
+	policy.Name = strings.TrimSpace(policy.Name)
+	if policy.Name == "" {
+		return i18n.NewError(i18n.CodePolicyNameRequired)
+	}
+	if policy.Action != database.AlbumPolicyActionDelete && policy.Action != database.AlbumPolicyActionRemove {
+		return i18n.NewError(i18n.CodePolicyInvalidAction, policy.Action, database.AlbumPolicyActionDelete, database.AlbumPolicyActionRemove)
+	}
+	if policy.OlderThanDays < 1 {
+		return i18n.NewError(i18n.CodePolicyInvalidAge)
+	}
+	return nil
+}
+
+// isScreenshot indica se o nome do arquivo corresponde a uma captura de tela.
+func isScreenshot(filename string) bool {
+	name := strings.ToLower(filename)
+	for _, pattern := range screenshotPatterns {
+		if strings.Contains(name, pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,408 @@
+package service
+
+import (
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// AlbumService gerencia álbuns e suas fotos.
+type AlbumService struct {
+	DB     *gorm.DB
+	Events *events.Bus
+
+	summaries summaryCache[AlbumSummary] // Contagens por álbum, invalidadas por eventos
+}
+
+// AlbumSummary combina um álbum com as agregações de suas fotos.
+type AlbumSummary struct {
+	database.Album
+	PhotoCount int64 // Quantidade de fotos no álbum
+	TotalBytes int64 // Soma do tamanho dos arquivos das fotos do álbum
+}
+
+// NewAlbumService cria uma nova instância de AlbumService.
+func NewAlbumService(db *gorm.DB, bus *events.Bus) *AlbumService {
+	s := &AlbumService{
+		DB:     db,
+		Events: bus,
+	}
+	s.summaries.invalidateOn(bus, events.TypeAlbumChanged, events.TypePhotoUpdated, events.TypePhotoDeleted)
+	return s
+}
+
+// ListAlbums retorna todos os álbuns com a contagem de fotos e o total de bytes, calculados via SQL.
+// Álbuns fixados vêm primeiro; dentro de cada grupo, os mais recentes (pela data da última foto) vêm
+// antes, e álbuns vazios ficam no fim, em ordem alfabética.
+// O resultado fica em cache até que um álbum ou foto seja alterado.
+func (s *AlbumService) ListAlbums() ([]AlbumSummary, error) {
+	cached, ok, generation := s.summaries.Get()
+	if ok {
+		return cached, nil
+	}
+
+	var summaries []AlbumSummary
+	result := s.albumSummaryQuery().Order("albums.pinned DESC").Order("albums.end_date IS NULL").Order("albums.end_date DESC").Order("albums.name ASC").Scan(&summaries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar álbuns: %w", result.Error)
+	}
+
+	s.summaries.Set(summaries, generation)
+	return summaries, nil
+}
+
+// GetAlbum retorna um álbum com suas agregações.
+func (s *AlbumService) GetAlbum(id uint) (*AlbumSummary, error) {
+	var summary AlbumSummary
+	result := s.albumSummaryQuery().Where("albums.id = ?", id).Scan(&summary)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar álbum: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("erro ao buscar álbum: %w", gorm.ErrRecordNotFound)
+	}
+	return &summary, nil
+}
+
+// GetAlbumSummaries retorna, em uma única consulta, as agregações dos álbuns informados, indexadas pelo ID.
+func (s *AlbumService) GetAlbumSummaries(ids []uint) (map[uint]AlbumSummary, error) {
+	byID := make(map[uint]AlbumSummary, len(ids))
+	if len(ids) == 0 {
+		return byID, nil
+	}
+
+	var summaries []AlbumSummary
+	if result := s.albumSummaryQuery().Where("albums.id IN ?", ids).Scan(&summaries); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar álbuns: %w", result.Error)
+	}
+	for _, summary := range summaries {
+		byID[summary.ID] = summary
+	}
+	return byID, nil
+}
+
+// GetAlbumPhotos retorna as fotos de um álbum, da mais recente para a mais antiga.
+func (s *AlbumService) GetAlbumPhotos(albumID uint) ([]database.Photo, error) {
+	var photos []database.Photo
+	result := s.DB.
+		Joins("JOIN album_photos ON album_photos.photo_id = photos.id AND album_photos.deleted_at IS NULL").
+		Where("album_photos.album_id = ?", albumID).
+		Order("photos.exif_date DESC").Order("photos.upload_date DESC").
+		Find(&photos)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar fotos do álbum: %w", result.Error)
+	}
+	return photos, nil
+}
+
+// GetPhotosForAlbums retorna até limit fotos de cada álbum informado (todas, se limit <= 0), da mais
+// recente para a mais antiga. Usa duas consultas no total, independentemente da quantidade de álbuns:
+// uma para as associações (numeradas por álbum) e outra para as fotos.
+func (s *AlbumService) GetPhotosForAlbums(albumIDs []uint, limit int) (map[uint][]database.Photo, error) {
+	photosByAlbum := make(map[uint][]database.Photo, len(albumIDs))
+	if len(albumIDs) == 0 {
+		return photosByAlbum, nil
+	}
+
+	ranked := s.DB.Model(&database.AlbumPhoto{}).
+		Select("album_photos.album_id, album_photos.photo_id, "+
+			"ROW_NUMBER() OVER (PARTITION BY album_photos.album_id ORDER BY photos.exif_date DESC, photos.upload_date DESC) AS position").
+		Joins("JOIN photos ON photos.id = album_photos.photo_id AND photos.deleted_at IS NULL").
+		Where("album_photos.album_id IN ?", albumIDs)
+
+	query := s.DB.Table("(?) AS ranked", ranked).Select("album_id, photo_id")
+	if limit > 0 {
+		query = query.Where("position <= ?", limit)
+	}
+
+	var links []struct {
+		AlbumID uint
+		PhotoID uint
+	}
+	if result := query.Order("album_id ASC").Order("position ASC").Scan(&links); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar fotos dos álbuns: %w", result.Error)
+	}
+
+	photoIDs := make([]uint, 0, len(links))
+	for _, link := range links {
+		photoIDs = append(photoIDs, link.PhotoID)
+	}
+	photosByID, err := s.photosByID(uniqueIDs(photoIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, link := range links {
+		if photo, ok := photosByID[link.PhotoID]; ok {
+			photosByAlbum[link.AlbumID] = append(photosByAlbum[link.AlbumID], photo)
+		}
+	}
+	return photosByAlbum, nil
+}
+
+// GetTagsForAlbums retorna, em uma única consulta, as tags usadas pelas fotos de cada álbum informado,
+// com a contagem de fotos e o total de bytes restritos ao álbum.
+func (s *AlbumService) GetTagsForAlbums(albumIDs []uint) (map[uint][]TagSummary, error) {
+	tagsByAlbum := make(map[uint][]TagSummary, len(albumIDs))
+	if len(albumIDs) == 0 {
+		return tagsByAlbum, nil
+	}
+
+	var rows []struct {
+		AlbumID    uint
+		TagID      uint
+		Name       string
+		PhotoCount int64
+		TotalBytes int64
+	}
+	result := s.DB.Model(&database.Tag{}).
+		Select("album_photos.album_id, tags.id AS tag_id, tags.name, COUNT(photos.id) AS photo_count, COALESCE(SUM(photos.file_size), 0) AS total_bytes").
+		Joins("JOIN photo_tags ON photo_tags.tag_id = tags.id AND photo_tags.deleted_at IS NULL").
+		Joins("JOIN photos ON photos.id = photo_tags.photo_id AND photos.deleted_at IS NULL").
+		Joins("JOIN album_photos ON album_photos.photo_id = photos.id AND album_photos.deleted_at IS NULL").
+		Where("album_photos.album_id IN ?", albumIDs).
+		Group("album_photos.album_id, tags.id").
+		Order("album_photos.album_id ASC").Order("tags.name ASC").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar tags dos álbuns: %w", result.Error)
+	}
+
+	for _, row := range rows {
+		tag := database.Tag{Name: row.Name}
+		tag.ID = row.TagID
+		tagsByAlbum[row.AlbumID] = append(tagsByAlbum[row.AlbumID], TagSummary{Tag: tag, PhotoCount: row.PhotoCount, TotalBytes: row.TotalBytes})
+	}
+	return tagsByAlbum, nil
+}
+
+// GetAlbumsForPhotos retorna os álbuns que contêm cada foto informada, usando duas consultas no total.
+func (s *AlbumService) GetAlbumsForPhotos(photoIDs []uint) (map[uint][]database.Album, error) {
+	albumsByPhoto := make(map[uint][]database.Album, len(photoIDs))
+	if len(photoIDs) == 0 {
+		return albumsByPhoto, nil
+	}
+
+	var links []database.AlbumPhoto
+	if result := s.DB.Where("photo_id IN ?", photoIDs).Find(&links); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar álbuns das fotos: %w", result.Error)
+	}
+	if len(links) == 0 {
+		return albumsByPhoto, nil
+	}
+
+	albumIDs := make([]uint, 0, len(links))
+	for _, link := range links {
+		albumIDs = append(albumIDs, link.AlbumID)
+	}
+	var albums []database.Album
+	if result := s.DB.Where("id IN ?", uniqueIDs(albumIDs)).Order("pinned DESC").Order("name ASC").Find(&albums); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar álbuns das fotos: %w", result.Error)
+	}
+
+	photosInAlbum := make(map[uint][]uint, len(albums))
+	for _, link := range links {
+		photosInAlbum[link.AlbumID] = append(photosInAlbum[link.AlbumID], link.PhotoID)
+	}
+	for _, album := range albums {
+		for _, photoID := range photosInAlbum[album.ID] {
+			albumsByPhoto[photoID] = append(albumsByPhoto[photoID], album)
+		}
+	}
+	return albumsByPhoto, nil
+}
+
+// CreateAlbum cria um novo álbum.
+func (s *AlbumService) CreateAlbum(name, description string) (*database.Album, error) {
+	if name == "" {
+		return nil, i18n.NewError(i18n.CodeAlbumNameRequired)
+	}
+
+	album := database.Album{Name: name, Description: description}
+	if result := s.DB.Create(&album); result.Error != nil {
+		return nil, fmt.Errorf("não foi possível criar o álbum: %w", result.Error)
+	}
+
+	s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": album.ID})
+	return &album, nil
+}
+
+// CreateAlbumWithPhotos cria um álbum já contendo as fotos informadas, em uma única transação.
+// É usado para "congelar" o resultado de uma busca: as fotos são copiadas no momento da criação e o
+// álbum não acompanha mudanças posteriores no filtro.
+func (s *AlbumService) CreateAlbumWithPhotos(name, description string, photoIDs []uint) (*database.Album, error) {
+	if name == "" {
+		return nil, i18n.NewError(i18n.CodeAlbumNameRequired)
+	}
+
+	album := database.Album{Name: name, Description: description}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&album).Error; err != nil {
+			return fmt.Errorf("não foi possível criar o álbum: %w", err)
+		}
+
+		ids := uniqueIDs(photoIDs)
+		if len(ids) == 0 {
+			return nil
+		}
+		albumPhotos := make([]database.AlbumPhoto, 0, len(ids))
+		for _, photoID := range ids {
+			albumPhotos = append(albumPhotos, database.AlbumPhoto{AlbumID: album.ID, PhotoID: photoID})
+		}
+		if err := tx.CreateInBatches(albumPhotos, 500).Error; err != nil {
+			return fmt.Errorf("não foi possível adicionar as fotos ao álbum: %w", err)
+		}
+		return refreshAlbumDates(tx, []uint{album.ID})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": album.ID})
+	return &album, nil
+}
+
+// AddPhotosToAlbum adiciona fotos a um álbum. Fotos que já estão no álbum são ignoradas; as adicionadas
+// recebem as tags padrão do álbum. Retorna a quantidade de fotos efetivamente adicionadas.
+func (s *AlbumService) AddPhotosToAlbum(albumID uint, photoIDs []uint) (int, error) {
+	added := 0
+	var tagged []uint
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var album database.Album
+		if err := tx.First(&album, albumID).Error; err != nil {
+			return fmt.Errorf("erro ao buscar álbum: %w", err)
+		}
+		if album.Locked {
+			return ErrAlbumLocked
+		}
+
+		var existingPhotos int64
+		if err := tx.Model(&database.Photo{}).Where("id IN ?", photoIDs).Count(&existingPhotos).Error; err != nil {
+			return fmt.Errorf("erro ao verificar fotos: %w", err)
+		}
+		if int(existingPhotos) != len(uniqueIDs(photoIDs)) {
+			return i18n.NewError(i18n.CodePhotosNotFound)
+		}
+
+		var memberIDs []uint
+		if err := tx.Model(&database.AlbumPhoto{}).Where("album_id = ? AND photo_id IN ?", albumID, photoIDs).Pluck("photo_id", &memberIDs).Error; err != nil {
+			return fmt.Errorf("erro ao verificar fotos do álbum: %w", err)
+		}
+		isMember := make(map[uint]bool, len(memberIDs))
+		for _, id := range memberIDs {
+			isMember[id] = true
+		}
+
+		var addedIDs []uint
+		for _, photoID := range uniqueIDs(photoIDs) {
+			if isMember[photoID] {
+				continue
+			}
+			if err := tx.Create(&database.AlbumPhoto{AlbumID: albumID, PhotoID: photoID}).Error; err != nil {
+				return fmt.Errorf("não foi possível adicionar a foto %d ao álbum: %w", photoID, err)
+			}
+			addedIDs = append(addedIDs, photoID)
+		}
+		added = len(addedIDs)
+		if added == 0 {
+			return nil
+		}
+		var err error
+		if tagged, err = addPhotoTags(tx, addedIDs, strings.Split(album.DefaultTags, ",")); err != nil {
+			return err
+		}
+		return refreshAlbumDates(tx, []uint{albumID})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if added > 0 {
+		s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": albumID})
+	}
+	for _, photoID := range tagged {
+		s.Events.Publish(events.TypeTagsChanged, map[string]interface{}{"photo_id": photoID})
+	}
+	return added, nil
+}
+
+// SetPinned fixa ou desafixa um álbum no topo das listagens.
+func (s *AlbumService) SetPinned(id uint, pinned bool) (*AlbumSummary, error) {
+	result := s.DB.Model(&database.Album{}).Where("id = ?", id).Update("pinned", pinned)
+	if result.Error != nil {
+		return nil, fmt.Errorf("não foi possível atualizar o álbum: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("erro ao buscar álbum: %w", gorm.ErrRecordNotFound)
+	}
+
+	s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": id})
+	return s.GetAlbum(id)
+}
+
+// RemovePhotoFromAlbum remove uma foto de um álbum (a foto em si não é apagada).
+func (s *AlbumService) RemovePhotoFromAlbum(albumID, photoID uint) error {
+	if err := checkAlbumUnlocked(s.DB, albumID); err != nil {
+		return err
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("album_id = ? AND photo_id = ?", albumID, photoID).Delete(&database.AlbumPhoto{})
+		if result.Error != nil {
+			return fmt.Errorf("não foi possível remover a foto do álbum: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("a foto não pertence ao álbum: %w", gorm.ErrRecordNotFound)
+		}
+		return refreshAlbumDates(tx, []uint{albumID})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": albumID})
+	return nil
+}
+
+// albumSummaryQuery monta a consulta de álbuns com contagem de fotos e soma de bytes.
+func (s *AlbumService) albumSummaryQuery() *gorm.DB {
+	return s.DB.Model(&database.Album{}).
+		Select("albums.*, COUNT(photos.id) AS photo_count, COALESCE(SUM(photos.file_size), 0) AS total_bytes").
+		Joins("LEFT JOIN album_photos ON album_photos.album_id = albums.id AND album_photos.deleted_at IS NULL").
+		Joins("LEFT JOIN photos ON photos.id = album_photos.photo_id AND photos.deleted_at IS NULL").
+		Group("albums.id")
+}
+
+// photosByID busca as fotos informadas, indexadas pelo ID.
+func (s *AlbumService) photosByID(ids []uint) (map[uint]database.Photo, error) {
+	byID := make(map[uint]database.Photo, len(ids))
+	if len(ids) == 0 {
+		return byID, nil
+	}
+
+	var photos []database.Photo
+	if result := s.DB.Where("id IN ?", ids).Find(&photos); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar fotos: %w", result.Error)
+	}
+	for _, photo := range photos {
+		byID[photo.ID] = photo
+	}
+	return byID, nil
+}
+
+// uniqueIDs remove IDs repetidos, preservando a ordem.
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
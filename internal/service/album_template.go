@@ -0,0 +1,287 @@
+package service
+
+import (
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrAlbumTemplateNotFound indica que o modelo de álbum informado não existe.
+var ErrAlbumTemplateNotFound = i18n.NewError(i18n.CodeAlbumTemplateNotFound)
+
+// templateVariable localiza as variáveis {{nome}} dos padrões de nome e descrição.
+var templateVariable = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_]+)\s*\}\}`)
+
+// monthNames são os nomes dos meses usados na variável {{month_name}}, por idioma.
+var monthNames = map[string][12]string{
+	i18n.Portuguese: {"Janeiro", "Fevereiro", "Março", "Abril", "Maio", "Junho", "Julho", "Agosto", "Setembro", "Outubro", "Novembro", "Dezembro"},
+	i18n.English:    {"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+}
+
+// AlbumTemplateService gerencia os modelos de álbum e cria álbuns a partir deles.
+type AlbumTemplateService struct {
+	DB           *gorm.DB
+	AlbumService *AlbumService
+	Events       *events.Bus
+}
+
+// NewAlbumTemplateService cria uma nova instância de AlbumTemplateService.
+func NewAlbumTemplateService(db *gorm.DB, as *AlbumService, bus *events.Bus) *AlbumTemplateService {
+	return &AlbumTemplateService{
+		DB:           db,
+		AlbumService: as,
+		Events:       bus,
+	}
+}
+
+// ListTemplates retorna os modelos de álbum, com as políticas, em ordem alfabética.
+func (s *AlbumTemplateService) ListTemplates() ([]database.AlbumTemplate, error) {
+	templates := []database.AlbumTemplate{}
+	if err := s.DB.Preload("Policies").Order("name ASC").Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar os modelos de álbum: %w", err)
+	}
+	return templates, nil
+}
+
+// GetTemplate retorna um modelo de álbum com as políticas.
+func (s *AlbumTemplateService) GetTemplate(id uint) (*database.AlbumTemplate, error) {
+	var template database.AlbumTemplate
+	result := s.DB.Preload("Policies").Where("id = ?", id).Limit(1).Find(&template)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar o modelo de álbum: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrAlbumTemplateNotFound
+	}
+	return &template, nil
+}
+
+// CreateTemplate valida e grava um novo modelo de álbum com suas políticas.
+func (s *AlbumTemplateService) CreateTemplate(template *database.AlbumTemplate) error {
+	template.Name = strings.TrimSpace(template.Name)
+	template.NamePattern = strings.TrimSpace(template.NamePattern)
+	if template.Name == "" || template.NamePattern == "" {
+		return i18n.NewError(i18n.CodeAlbumTemplateInvalid)
+	}
+	if template.SoftQuotaBytes < 0 {
+		return i18n.NewError(i18n.CodeAlbumTemplateInvalid)
+	}
+	template.DefaultTags = strings.Join(normalizeTagNames(strings.Split(template.DefaultTags, ",")), ",")
+	for i := range template.Policies {
+		policy := template.Policies[i]
+		candidate := database.AlbumPolicy{Name: policy.Name, Action: policy.Action, OlderThanDays: policy.OlderThanDays}
+		if err := validatePolicy(&candidate); err != nil {
+			return err
+		}
+		template.Policies[i].Name = candidate.Name
+	}
+
+	var taken int64
+	if err := s.DB.Model(&database.AlbumTemplate{}).Where("LOWER(name) = LOWER(?)", template.Name).Count(&taken).Error; err != nil {
+		return fmt.Errorf("erro ao verificar o nome do modelo de álbum: %w", err)
+	}
+	if taken > 0 {
+		return i18n.NewError(i18n.CodeAlbumTemplateExists, template.Name)
+	}
+	if err := s.DB.Create(template).Error; err != nil {
+		return fmt.Errorf("não foi possível gravar o modelo de álbum: %w", err)
+	}
+	return nil
+}
+
+// DeleteTemplate remove um modelo de álbum; os álbuns já criados a partir dele não mudam.
+func (s *AlbumTemplateService) DeleteTemplate(id uint) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Delete(&database.AlbumTemplate{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("não foi possível remover o modelo de álbum: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrAlbumTemplateNotFound
+		}
+		if err := tx.Where("template_id = ?", id).Delete(&database.AlbumTemplatePolicy{}).Error; err != nil {
+			return fmt.Errorf("não foi possível remover as políticas do modelo de álbum: %w", err)
+		}
+		return nil
+	})
+}
+
+// AlbumFromTemplateRequest descreve a criação de um álbum a partir de um modelo.
+type AlbumFromTemplateRequest struct {
+	TemplateID uint
+	Date       time.Time         // Data das variáveis de data ({{year}}, {{month}}...)
+	Variables  map[string]string // Variáveis adicionais dos padrões; não substituem as de data
+	Locale     string            // Idioma de {{month_name}}
+	PhotoIDs   []uint            // Fotos incluídas no álbum criado (recebem as tags padrão)
+}
+
+// AlbumFromTemplate é o resultado da criação de um álbum a partir de um modelo.
+type AlbumFromTemplate struct {
+	Album    AlbumSummary
+	Created  bool                   // false se já existia um álbum com o nome gerado (nada foi alterado)
+	Share    *database.ShareLink    // Link criado pelo modelo, se houver
+	Policies []database.AlbumPolicy // Políticas criadas pelo modelo (não confirmadas)
+	Added    int                    // Fotos incluídas no álbum
+}
+
+// CreateAlbum cria um álbum a partir do modelo: o nome e a descrição vêm dos padrões com as variáveis
+// substituídas, e o álbum recebe as tags padrão, a fixação, a cota, o link de compartilhamento e as
+// políticas do modelo, em uma única transação. Se já existir um álbum com o nome gerado, ele é retornado
+// sem alterações (Created false), para que automações possam repetir a chamada com segurança.
+func (s *AlbumTemplateService) CreateAlbum(req AlbumFromTemplateRequest) (*AlbumFromTemplate, error) {
+	template, err := s.GetTemplate(req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	variables := templateVariables(req.Date, req.Locale, req.Variables)
+	name, err := renderTemplatePattern(template.NamePattern, variables)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, i18n.NewError(i18n.CodeAlbumNameRequired)
+	}
+	description, err := renderTemplatePattern(template.DescriptionPattern, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing database.Album
+	found := s.DB.Where("name = ?", name).Limit(1).Find(&existing)
+	if found.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar o álbum '%s': %w", name, found.Error)
+	}
+	if found.RowsAffected > 0 {
+		summary, err := s.AlbumService.GetAlbum(existing.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &AlbumFromTemplate{Album: *summary}, nil
+	}
+
+	result := &AlbumFromTemplate{Created: true}
+	album := database.Album{
+		Name:           name,
+		Description:    description,
+		Pinned:         template.Pinned,
+		SoftQuotaBytes: template.SoftQuotaBytes,
+		DefaultTags:    template.DefaultTags,
+	}
+	var tagged []uint
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&album).Error; err != nil {
+			return fmt.Errorf("não foi possível criar o álbum: %w", err)
+		}
+		albumID := album.ID
+
+		for _, templatePolicy := range template.Policies {
+			policy := database.AlbumPolicy{
+				AlbumID:         albumID,
+				Name:            templatePolicy.Name,
+				Action:          templatePolicy.Action,
+				OlderThanDays:   templatePolicy.OlderThanDays,
+				ScreenshotsOnly: templatePolicy.ScreenshotsOnly,
+				ExceptFavorites: templatePolicy.ExceptFavorites,
+			}
+			if err := tx.Create(&policy).Error; err != nil {
+				return fmt.Errorf("não foi possível criar a política '%s': %w", policy.Name, err)
+			}
+			result.Policies = append(result.Policies, policy)
+		}
+
+		if template.Share {
+			token, err := newShareToken()
+			if err != nil {
+				return err
+			}
+			link := database.ShareLink{Token: token, AlbumID: albumID}
+			if err := tx.Create(&link).Error; err != nil {
+				return fmt.Errorf("não foi possível criar o link de compartilhamento: %w", err)
+			}
+			result.Share = &link
+		}
+
+		ids := uniqueIDs(req.PhotoIDs)
+		if len(ids) == 0 {
+			return nil
+		}
+		var count int64
+		if err := tx.Model(&database.Photo{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+			return fmt.Errorf("erro ao verificar fotos: %w", err)
+		}
+		if int(count) != len(ids) {
+			return i18n.NewError(i18n.CodePhotosNotFound)
+		}
+		albumPhotos := make([]database.AlbumPhoto, 0, len(ids))
+		for _, photoID := range ids {
+			albumPhotos = append(albumPhotos, database.AlbumPhoto{AlbumID: albumID, PhotoID: photoID})
+		}
+		if err := tx.CreateInBatches(albumPhotos, 500).Error; err != nil {
+			return fmt.Errorf("não foi possível adicionar as fotos ao álbum: %w", err)
+		}
+		result.Added = len(ids)
+		if tagged, err = addPhotoTags(tx, ids, strings.Split(template.DefaultTags, ",")); err != nil {
+			return err
+		}
+		return refreshAlbumDates(tx, []uint{albumID})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": album.ID})
+	for _, photoID := range tagged {
+		s.Events.Publish(events.TypeTagsChanged, map[string]interface{}{"photo_id": photoID})
+	}
+	summary, err := s.AlbumService.GetAlbum(album.ID)
+	if err != nil {
+		return nil, err
+	}
+	result.Album = *summary
+	return result, nil
+}
+
+// templateVariables monta as variáveis dos padrões: as informadas e as de data, que têm prioridade.
+func templateVariables(date time.Time, locale string, extra map[string]string) map[string]string {
+	variables := make(map[string]string, len(extra)+7)
+	for name, value := range extra {
+		variables[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+	names, ok := monthNames[locale]
+	if !ok {
+		names = monthNames[i18n.DefaultLocale]
+	}
+	_, week := date.ISOWeek()
+	variables["year"] = strconv.Itoa(date.Year())
+	variables["month"] = fmt.Sprintf("%02d", int(date.Month()))
+	variables["month_name"] = names[date.Month()-1]
+	variables["day"] = fmt.Sprintf("%02d", date.Day())
+	variables["week"] = fmt.Sprintf("%02d", week)
+	variables["date"] = date.Format("2006-01-02")
+	return variables
+}
+
+// renderTemplatePattern substitui as variáveis {{nome}} do padrão. Variáveis sem valor são rejeitadas, para
+// que nenhum álbum seja criado com "{{...}}" no nome.
+func renderTemplatePattern(pattern string, variables map[string]string) (string, error) {
+	var missing error
+	rendered := templateVariable.ReplaceAllStringFunc(pattern, func(match string) string {
+		name := strings.ToLower(templateVariable.FindStringSubmatch(match)[1])
+		value, ok := variables[name]
+		if !ok && missing == nil {
+			missing = i18n.NewError(i18n.CodeAlbumTemplateVariableMissing, name)
+		}
+		return value
+	})
+	if missing != nil {
+		return "", missing
+	}
+	return strings.Join(strings.Fields(rendered), " "), nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"photo-manager/internal/database"
+	"photo-manager/internal/exif"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/video"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// regionTolerance é a diferença máxima, em fração da imagem, para considerar a mesma região uma anotação
+// existente com o mesmo tipo e o mesmo texto (os programas arredondam as coordenadas de formas diferentes).
+const regionTolerance = 0.02
+
+// RegionImport resume a importação das regiões XMP de uma foto.
+type RegionImport struct {
+	Imported    int // Regiões gravadas como anotações
+	Skipped     int // Regiões que já eram anotações da foto
+	Annotations []database.PhotoAnnotation
+}
+
+// ImportRegions importa como anotações as regiões nomeadas (rostos, animais e outras) do pacote XMP no esquema
+// MWG ou Microsoft Photo, como gravado por Picasa, digiKam e Lightroom. Sem pacote (xmp nil), as regiões são
+// lidas do XMP lateral ao lado do arquivo da foto ou do XMP embutido nele. Regiões que já são anotações da foto
+// são ignoradas, então a importação pode ser repetida.
+func (s *PhotoAnnotationService) ImportRegions(ctx context.Context, photoID uint, xmp []byte) (*RegionImport, error) {
+	var photo database.Photo
+	if err := s.DB.WithContext(ctx).Select("id", "stored_path").First(&photo, photoID).Error; err != nil {
+		return nil, err
+	}
+	if err := checkPhotoUnlocked(s.DB.WithContext(ctx), photoID); err != nil {
+		return nil, err
+	}
+
+	var regions []exif.Region
+	var err error
+	if xmp != nil {
+		if regions, err = exif.ParseRegions(xmp); err != nil {
+			return nil, i18n.NewError(i18n.CodeAnnotationXMPInvalid, err.Error())
+		}
+	} else if regions, err = exif.ReadRegions(photo.StoredPath); err != nil {
+		return nil, err
+	}
+
+	report := &RegionImport{}
+	err = s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		imported, err := createRegionAnnotations(tx, photoID, regions)
+		report.Imported, report.Skipped = imported, len(regions)-imported
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if report.Imported > 0 {
+		s.publish(photoID)
+	}
+
+	if report.Annotations, err = s.ListAnnotations(ctx, photoID); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// ExportRegions gera o XMP lateral com as anotações da foto como regiões MWG (e, os rostos, também no esquema
+// Microsoft Photo), para levar os nomes atribuídos aqui de volta a outros programas. Retorna também a foto,
+// para o nome do arquivo.
+func (s *PhotoAnnotationService) ExportRegions(ctx context.Context, photoID uint) ([]byte, *database.Photo, error) {
+	var photo database.Photo
+	if err := s.DB.WithContext(ctx).Select("id", "filename", "width", "height").First(&photo, photoID).Error; err != nil {
+		return nil, nil, err
+	}
+	annotations, err := s.ListAnnotations(ctx, photoID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	regions := make([]exif.Region, 0, len(annotations))
+	for _, annotation := range annotations {
+		regions = append(regions, exif.Region{
+			Name:   annotation.Text,
+			Type:   regionType(annotation.Kind),
+			X:      annotation.X,
+			Y:      annotation.Y,
+			Width:  annotation.Width,
+			Height: annotation.Height,
+		})
+	}
+	return exif.EncodeRegionsXMP(regions, photo.Width, photo.Height), &photo, nil
+}
+
+// readIngestRegions lê as regiões XMP de um arquivo na ingestão ou na adoção de uma nova versão. As regiões
+// são opcionais: falhas são apenas registradas no log.
+func readIngestRegions(path, filename, mimeType string) []exif.Region {
+	if video.IsVideo(mimeType) {
+		return nil
+	}
+	regions, err := exif.ReadRegions(path)
+	if err != nil {
+		log.Printf("Aviso: regiões XMP de '%s' ignoradas: %v\n", filename, err)
+		return nil
+	}
+	return regions
+}
+
+// createRegionAnnotations grava as regiões como anotações da foto, exceto as que já existem (mesmo tipo, mesmo
+// texto e a mesma região, com tolerância). Regiões com nome longo demais são ignoradas. Retorna quantas foram
+// gravadas.
+func createRegionAnnotations(tx *gorm.DB, photoID uint, regions []exif.Region) (int, error) {
+	if len(regions) == 0 {
+		return 0, nil
+	}
+	var existing []database.PhotoAnnotation
+	if err := tx.Where("photo_id = ?", photoID).Find(&existing).Error; err != nil {
+		return 0, fmt.Errorf("erro ao buscar as anotações da foto: %w", err)
+	}
+
+	created := 0
+	for _, region := range regions {
+		text, err := annotationText(region.Name)
+		if err != nil {
+			continue
+		}
+		annotation := database.PhotoAnnotation{
+			PhotoID: photoID,
+			X:       region.X,
+			Y:       region.Y,
+			Width:   region.Width,
+			Height:  region.Height,
+			Text:    text,
+			Kind:    annotationKind(region.Type),
+		}
+		if containsRegion(existing, annotation) {
+			continue
+		}
+		if err := tx.Create(&annotation).Error; err != nil {
+			return created, fmt.Errorf("não foi possível gravar a anotação: %w", err)
+		}
+		existing = append(existing, annotation)
+		created++
+	}
+	return created, nil
+}
+
+// containsRegion indica se a anotação já está na lista.
+func containsRegion(annotations []database.PhotoAnnotation, candidate database.PhotoAnnotation) bool {
+	for _, annotation := range annotations {
+		if annotation.Kind == candidate.Kind && strings.EqualFold(annotation.Text, candidate.Text) &&
+			math.Abs(annotation.X-candidate.X) <= regionTolerance && math.Abs(annotation.Y-candidate.Y) <= regionTolerance &&
+			math.Abs(annotation.Width-candidate.Width) <= regionTolerance && math.Abs(annotation.Height-candidate.Height) <= regionTolerance {
+			return true
+		}
+	}
+	return false
+}
+
+// annotationKind converte o tipo de uma região MWG no tipo da anotação. Tipos sem equivalente (ex: BarCode)
+// viram notas.
+func annotationKind(regionType string) string {
+	switch {
+	case strings.EqualFold(regionType, exif.RegionTypeFace):
+		return database.AnnotationKindFace
+	case strings.EqualFold(regionType, exif.RegionTypePet):
+		return database.AnnotationKindPet
+	}
+	return database.AnnotationKindNote
+}
+
+// regionType converte o tipo da anotação no tipo da região MWG; notas não têm tipo.
+func regionType(kind string) string {
+	switch kind {
+	case database.AnnotationKindFace:
+		return exif.RegionTypeFace
+	case database.AnnotationKindPet:
+		return exif.RegionTypePet
+	}
+	return ""
+}
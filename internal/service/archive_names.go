@@ -0,0 +1,72 @@
+package service
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"strings"
+)
+
+// archiveNamer escolhe nomes únicos para as fotos dentro de um ZIP. Entradas com o mesmo caminho se
+// sobrescrevem ao extrair, e a maioria dos sistemas de arquivos (Windows, macOS) não diferencia
+// maiúsculas: sem a desambiguação, fotos distintas com o mesmo nome original se perderiam em silêncio.
+type archiveNamer struct {
+	used map[string]bool // Caminhos já usados, em minúsculas
+}
+
+func newArchiveNamer() *archiveNamer {
+	return &archiveNamer{used: make(map[string]bool)}
+}
+
+// Name retorna o caminho da foto em dir, mantendo o nome original sempre que possível. Em caso de
+// colisão, acrescenta a data da foto (IMG_0001_20230501-143000.jpg) e, se ainda colidir, o início do
+// hash. renamed indica que o nome foi alterado.
+func (n *archiveNamer) Name(dir string, photo database.Photo) (name string, renamed bool) {
+	base := filepath.Base(filepath.ToSlash(photo.Filename))
+	if base == "." || base == "/" || base == "" {
+		base = fmt.Sprintf("photo-%d", photo.ID)
+	}
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	date := photo.UploadDate
+	if photo.ExifDate != nil {
+		date = *photo.ExifDate
+	}
+	hash := photo.Hash
+	if len(hash) > 8 {
+		hash = hash[:8]
+	}
+
+	candidates := []string{
+		base,
+		fmt.Sprintf("%s_%s%s", stem, date.Format("20060102-150405"), ext),
+		fmt.Sprintf("%s_%s%s", stem, hash, ext),
+	}
+	for i, candidate := range candidates {
+		if n.claim(path.Join(dir, candidate)) {
+			return path.Join(dir, candidate), i > 0
+		}
+	}
+	// Mesmo nome, mesma data e mesmo início de hash: o ID da foto é único
+	for i := 0; ; i++ {
+		candidate := fmt.Sprintf("%s_%d%s", stem, photo.ID, ext)
+		if i > 0 {
+			candidate = fmt.Sprintf("%s_%d-%d%s", stem, photo.ID, i, ext)
+		}
+		if n.claim(path.Join(dir, candidate)) {
+			return path.Join(dir, candidate), true
+		}
+	}
+}
+
+// claim reserva o caminho, se ainda estiver livre.
+func (n *archiveNamer) claim(name string) bool {
+	key := strings.ToLower(name)
+	if n.used[key] {
+		return false
+	}
+	n.used[key] = true
+	return true
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/exif"
+	"photo-manager/internal/video"
+
+	"gorm.io/gorm"
+)
+
+// detectAuxiliaryAssets marca na foto as imagens auxiliares (mapas de profundidade e de ganho, máscaras de
+// retrato) encontradas no arquivo em path. Falhas de leitura são apenas registradas no log.
+func detectAuxiliaryAssets(path string, photo *database.Photo) {
+	photo.HasDepthMap, photo.HasGainMap, photo.HasPortraitMatte = false, false, false
+	if video.IsVideo(photo.MimeType) {
+		return
+	}
+	assets, err := exif.ExtractAuxiliaryAssets(path)
+	if err != nil {
+		log.Printf("Aviso: não foi possível procurar imagens auxiliares em '%s': %v\n", photo.Filename, err)
+		return
+	}
+	photo.HasDepthMap, photo.HasGainMap, photo.HasPortraitMatte = assets.DepthMap, assets.GainMap, assets.PortraitMatte
+}
+
+// auxiliaryColumns são as colunas gravadas por detectAuxiliaryAssets, para atualizar fotos já cadastradas.
+func auxiliaryColumns(photo *database.Photo) map[string]interface{} {
+	return map[string]interface{}{
+		"has_depth_map":      photo.HasDepthMap,
+		"has_gain_map":       photo.HasGainMap,
+		"has_portrait_matte": photo.HasPortraitMatte,
+	}
+}
+
+// AuxiliaryAssetsReport é o resultado da procura de imagens auxiliares nas fotos já cadastradas.
+type AuxiliaryAssetsReport struct {
+	Scanned int    // Fotos cujo arquivo foi lido
+	Updated []uint // Fotos com as imagens auxiliares alteradas
+	Missing []uint // Fotos sem arquivo acessível
+}
+
+// DetectAuxiliaryAssets procura as imagens auxiliares nas fotos já cadastradas (anteriores à detecção ou
+// alteradas fora da aplicação), lendo o original completo quando ele foi guardado.
+func (s *PhotoService) DetectAuxiliaryAssets(ctx context.Context) (*AuxiliaryAssetsReport, error) {
+	report := &AuxiliaryAssetsReport{Updated: []uint{}, Missing: []uint{}}
+	var photos []database.Photo
+	err := s.DB.WithContext(ctx).Model(&database.Photo{}).Where("mime_type NOT LIKE ?", "video/%").
+		FindInBatches(&photos, 200, func(tx *gorm.DB, batch int) error {
+			for i := range photos {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if err := s.redetectAuxiliaryAssets(ctx, &photos[i], report); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao procurar imagens auxiliares: %w", err)
+	}
+	return report, nil
+}
+
+// redetectAuxiliaryAssets procura as imagens auxiliares de uma foto, gravando-as se mudaram.
+func (s *PhotoService) redetectAuxiliaryAssets(ctx context.Context, photo *database.Photo, report *AuxiliaryAssetsReport) error {
+	path := photo.StoredPath
+	if photo.OriginalPath != "" {
+		if _, err := os.Stat(photo.OriginalPath); err == nil {
+			path = photo.OriginalPath
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		report.Missing = append(report.Missing, photo.ID)
+		return nil
+	}
+	report.Scanned++
+
+	detected := *photo
+	detectAuxiliaryAssets(path, &detected)
+	if detected.HasDepthMap == photo.HasDepthMap && detected.HasGainMap == photo.HasGainMap && detected.HasPortraitMatte == photo.HasPortraitMatte {
+		return nil
+	}
+	if err := s.DB.WithContext(ctx).Model(photo).Updates(auxiliaryColumns(&detected)).Error; err != nil {
+		return fmt.Errorf("não foi possível gravar as imagens auxiliares da foto %d: %w", photo.ID, err)
+	}
+	report.Updated = append(report.Updated, photo.ID)
+	s.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": photo.ID})
+	return nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCircaDateInvalid indica uma data aproximada em formato desconhecido.
+var ErrCircaDateInvalid = i18n.NewError(i18n.CodeCircaDateInvalid)
+
+// ParseCircaDate interpreta uma data aproximada: "1985-03" (mês), "1985" (ano) ou "1980s" (década, a partir
+// de um ano terminado em 0). Retorna o início do período, à meia-noite UTC, e a precisão.
+func ParseCircaDate(value string) (time.Time, string, error) {
+	value = strings.TrimSpace(value)
+	if decade, ok := strings.CutSuffix(value, "s"); ok {
+		year, err := strconv.Atoi(decade)
+		if err != nil || len(decade) != 4 || year%10 != 0 {
+			return time.Time{}, "", ErrCircaDateInvalid
+		}
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), database.CircaPrecisionDecade, nil
+	}
+	if start, err := time.Parse("2006-01", value); err == nil {
+		return start, database.CircaPrecisionMonth, nil
+	}
+	if start, err := time.Parse("2006", value); err == nil {
+		return start, database.CircaPrecisionYear, nil
+	}
+	return time.Time{}, "", ErrCircaDateInvalid
+}
+
+// FormatCircaDate formata a data aproximada da foto no formato aceito por ParseCircaDate ("" se não houver).
+func FormatCircaDate(photo database.Photo) string {
+	if photo.CircaDate == nil {
+		return ""
+	}
+	start := photo.CircaDate.UTC()
+	switch photo.CircaPrecision {
+	case database.CircaPrecisionDecade:
+		return fmt.Sprintf("%04ds", start.Year())
+	case database.CircaPrecisionYear:
+		return start.Format("2006")
+	default:
+		return start.Format("2006-01")
+	}
+}
+
+// circaEndSQL é o último dia do período da data aproximada, em SQL (SQLite).
+const circaEndSQL = "date(circa_date, CASE circa_precision WHEN 'decade' THEN '+10 years' WHEN 'year' THEN '+1 years' ELSE '+1 months' END, '-1 days')"
+
+// SetCircaDate grava a data aproximada das fotos informadas (ver ParseCircaDate); value vazio a remove,
+// e as fotos voltam a usar as datas EXIF e de upload. Fotos bloqueadas são ignoradas. Retorna a quantidade
+// de fotos alteradas e a de bloqueadas.
+func (s *PhotoService) SetCircaDate(ctx context.Context, ids []uint, value string) (int, int, error) {
+	var start *time.Time
+	precision := ""
+	if strings.TrimSpace(value) != "" {
+		parsed, parsedPrecision, err := ParseCircaDate(value)
+		if err != nil {
+			return 0, 0, err
+		}
+		start, precision = &parsed, parsedPrecision
+	}
+
+	ids = uniqueIDs(ids)
+	db := s.DB.WithContext(ctx)
+	var existing int64
+	if err := db.Model(&database.Photo{}).Where("id IN ?", ids).Count(&existing).Error; err != nil {
+		return 0, 0, fmt.Errorf("erro ao verificar fotos: %w", err)
+	}
+	if int(existing) != len(ids) {
+		return 0, 0, i18n.NewError(i18n.CodePhotosNotFound)
+	}
+	locked, err := lockedPhotoIDs(db, ids)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var changed []uint
+	for _, id := range ids {
+		if !locked[id] {
+			changed = append(changed, id)
+		}
+	}
+	if len(changed) > 0 {
+		err := db.Model(&database.Photo{}).Where("id IN ?", changed).
+			Updates(map[string]interface{}{"circa_date": start, "circa_precision": precision}).Error
+		if err != nil {
+			return 0, 0, fmt.Errorf("não foi possível atualizar as fotos: %w", err)
+		}
+	}
+
+	for _, id := range changed {
+		s.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": id})
+	}
+	return len(changed), len(ids) - len(changed), nil
+}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Motivos para uma data de foto ser considerada suspeita.
+const (
+	DateSuspectEpoch         = "epoch"          // Data EXIF em 1970 (ou antes): relógio zerado ou data gravada como 0
+	DateSuspectCameraDefault = "camera_default" // Data EXIF no dia padrão de câmeras sem relógio acertado (2000-01-01, 1980-01-01)
+	DateSuspectMissing       = "missing"        // Sem data EXIF (usa a de upload), entre fotos vizinhas com data EXIF
+)
+
+// Fontes da data sugerida.
+const (
+	DateBasisFilename     = "filename_date"     // Data no nome do arquivo (ex: IMG_20190704_123456.jpg, IMG-20190704-WA0001.jpg)
+	DateBasisFileSequence = "filename_sequence" // Posição na numeração dos arquivos (ex: entre DSC_0041 e DSC_0045)
+	DateBasisImportOrder  = "import_order"      // Posição na ordem de importação, entre fotos importadas em sequência
+)
+
+// Confiança da data sugerida.
+const (
+	DateConfidenceHigh   = "high"   // Data com horário no nome do arquivo, ou entre vizinhas do mesmo dia
+	DateConfidenceMedium = "medium" // Só a data no nome do arquivo, ou entre vizinhas da mesma semana
+	DateConfidenceLow    = "low"    // Uma só vizinha, ou vizinhas distantes no tempo
+)
+
+// Parâmetros das heurísticas de datas.
+const (
+	dateFixPreviewLimit = 500 // Fotos listadas no relatório; os totais são sempre completos
+	dateFixMaxNumberGap = 100 // Maior diferença de numeração entre arquivos vizinhos na mesma sequência
+	dateFixImportWindow = 10  // Quantas fotos antes e depois, na ordem de importação, são consideradas vizinhas
+
+	// Maior intervalo entre as datas das vizinhas para a interpolação ter confiança média
+	dateFixMaxNeighborSpan = 7 * 24 * time.Hour
+)
+
+// ErrDateFixInvalid indica uma data confirmada inválida (fora do intervalo aceitável ou sem foto).
+var ErrDateFixInvalid = i18n.NewError(i18n.CodeDateFixInvalid)
+
+// dateFixMinYear é o ano mais antigo aceito na confirmação (fotos digitalizadas podem ser bem antigas).
+const dateFixMinYear = 1826
+
+// filenameDatePattern reconhece uma data (e, opcionalmente, o horário) no nome do arquivo, como gravado por
+// celulares e aplicativos: 20190704_123456, 2019-07-04 12.34.56, IMG-20190704-WA0001.
+var filenameDatePattern = regexp.MustCompile(`(?:^|\D)((?:19|20)\d{2})[-_.]?(0[1-9]|1[0-2])[-_.]?(0[1-9]|[12]\d|3[01])(?:[ _T-]?([01]\d|2[0-3])[-_.:]?([0-5]\d)[-_.:]?([0-5]\d))?(?:\D|$)`)
+
+// filenameNumberPattern separa o nome do arquivo (sem extensão) em prefixo e número final (ex: DSC_0042).
+var filenameNumberPattern = regexp.MustCompile(`^(.*?)(\d+)(\D*)$`)
+
+// DateFixSuggestion é uma foto com a data suspeita e, se possível, a data sugerida.
+type DateFixSuggestion struct {
+	PhotoID        uint
+	Filename       string
+	Reason         string     // Ver constantes DateSuspect*
+	CurrentDate    *time.Time // Data EXIF atual (nil se não houver)
+	UploadDate     time.Time
+	SuggestedDate  *time.Time // nil se nenhuma heurística encontrou uma data
+	TimeZoneOffset *int       // Fuso sugerido, em minutos (o da vizinha, se conhecido)
+	Basis          string     // Ver constantes DateBasis*
+	Confidence     string     // Ver constantes DateConfidence*
+	PreviousID     uint       // Vizinha anterior usada na sugestão (0 se nenhuma)
+	NextID         uint       // Vizinha seguinte usada na sugestão
+	Locked         bool       // Foto bloqueada: a correção não é aplicada
+}
+
+// DateFixReport resume a verificação das datas.
+type DateFixReport struct {
+	Checked   int // Fotos verificadas
+	Flagged   int // Fotos com a data suspeita
+	Suggested int // Fotos com data sugerida
+	Photos    []DateFixSuggestion
+	Truncated bool
+}
+
+// datePhoto é a foto com os campos usados nas heurísticas de datas.
+type datePhoto struct {
+	database.Photo
+	prefix string // Prefixo do nome do arquivo numerado, em minúsculas (vazio se o nome não termina em número)
+	number int    // Número do arquivo na sequência
+}
+
+// trusted indica se a data EXIF da foto é confiável para servir de referência às vizinhas.
+func (p *datePhoto) trusted() bool {
+	return p.ExifDate != nil && p.CircaDate == nil && suspiciousDate(p.Photo) == ""
+}
+
+// SuggestDateFixes procura fotos com datas suspeitas (1970, a data padrão de câmeras sem relógio acertado e
+// fotos sem data EXIF entre vizinhas com data) e sugere a data correta: pela data no nome do arquivo ou,
+// sem ela, pela posição da foto entre as vizinhas na numeração dos arquivos ou na ordem de importação. ids
+// vazio verifica a biblioteca inteira; as vizinhas são sempre procuradas na biblioteca inteira. Fotos com
+// data aproximada são ignoradas. Nada é alterado: as correções são aplicadas por ApplyDateFixes.
+func (s *PhotoService) SuggestDateFixes(ctx context.Context, ids []uint) (*DateFixReport, error) {
+	db := s.DB.WithContext(ctx)
+	var rows []database.Photo
+	err := db.Select("id", "filename", "exif_date", "time_zone_offset", "circa_date", "upload_date").Order("id").Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar fotos: %w", err)
+	}
+
+	photos := make([]*datePhoto, len(rows))
+	byID := make(map[uint]int, len(rows))
+	for i, row := range rows {
+		photos[i] = &datePhoto{Photo: row, number: -1}
+		base := strings.TrimSuffix(row.Filename, filepath.Ext(row.Filename))
+		if match := filenameNumberPattern.FindStringSubmatch(base); match != nil {
+			if number, err := strconv.Atoi(match[2]); err == nil {
+				photos[i].prefix, photos[i].number = strings.ToLower(match[1]), number
+			}
+		}
+		byID[row.ID] = i
+	}
+	selected := make(map[uint]bool, len(ids))
+	for _, id := range uniqueIDs(ids) {
+		if _, ok := byID[id]; !ok {
+			return nil, i18n.NewError(i18n.CodePhotosNotFound)
+		}
+		selected[id] = true
+	}
+	sequences := filenameSequences(photos)
+
+	report := &DateFixReport{}
+	var suggestions []DateFixSuggestion
+	for i, photo := range photos {
+		if len(selected) > 0 && !selected[photo.ID] {
+			continue
+		}
+		report.Checked++
+		if photo.CircaDate != nil {
+			continue
+		}
+		suggestion := DateFixSuggestion{
+			PhotoID:     photo.ID,
+			Filename:    photo.Filename,
+			Reason:      suspiciousDate(photo.Photo),
+			CurrentDate: photo.ExifDate,
+			UploadDate:  photo.UploadDate,
+		}
+		fromSequence := sequenceSuggestion(photo, sequences[photo.prefix])
+		fromImport := importOrderSuggestion(photos, i)
+		if suggestion.Reason == "" && photo.ExifDate == nil &&
+			(fromSequence.PreviousID != 0 && fromSequence.NextID != 0 || fromImport.PreviousID != 0 && fromImport.NextID != 0) {
+			suggestion.Reason = DateSuspectMissing
+		}
+		if suggestion.Reason == "" {
+			continue
+		}
+
+		for _, candidate := range []DateFixSuggestion{filenameSuggestion(photo.Filename), fromSequence, fromImport} {
+			if candidate.SuggestedDate != nil {
+				suggestion.SuggestedDate, suggestion.TimeZoneOffset = candidate.SuggestedDate, candidate.TimeZoneOffset
+				suggestion.Basis, suggestion.Confidence = candidate.Basis, candidate.Confidence
+				suggestion.PreviousID, suggestion.NextID = candidate.PreviousID, candidate.NextID
+				break
+			}
+		}
+		report.Flagged++
+		if suggestion.SuggestedDate != nil {
+			report.Suggested++
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	if len(suggestions) > dateFixPreviewLimit {
+		suggestions, report.Truncated = suggestions[:dateFixPreviewLimit], true
+	}
+	flagged := make([]uint, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		flagged = append(flagged, suggestion.PhotoID)
+	}
+	locked, err := lockedPhotoIDs(db, flagged)
+	if err != nil {
+		return nil, err
+	}
+	for i := range suggestions {
+		suggestions[i].Locked = locked[suggestions[i].PhotoID]
+	}
+	report.Photos = suggestions
+	return report, nil
+}
+
+// suspiciousDate retorna o motivo de a data EXIF da foto ser suspeita, ou "" se não for (ou não houver data).
+// A data é avaliada na hora local da captura, como a câmera a gravou.
+func suspiciousDate(photo database.Photo) string {
+	if photo.ExifDate == nil {
+		return ""
+	}
+	wall := photo.ExifDate.In(time.Local)
+	if photo.TimeZoneOffset != nil {
+		wall = photo.ExifDate.In(time.FixedZone("", *photo.TimeZoneOffset*60))
+	}
+	year, month, day := wall.Date()
+	switch {
+	case year <= 1970:
+		return DateSuspectEpoch
+	case month == time.January && day == 1 && (year == 2000 || year == 1980):
+		return DateSuspectCameraDefault
+	}
+	return ""
+}
+
+// filenameSuggestion sugere a data contida no nome do arquivo, se houver uma data válida e não futura.
+func filenameSuggestion(filename string) DateFixSuggestion {
+	match := filenameDatePattern.FindStringSubmatch(filename)
+	if match == nil {
+		return DateFixSuggestion{}
+	}
+	parts := make([]int, 6)
+	for i, value := range match[1:7] {
+		parts[i], _ = strconv.Atoi(value)
+	}
+	date := time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], 0, time.Local)
+	if date.Day() != parts[2] || date.After(time.Now()) {
+		return DateFixSuggestion{} // Ex: 31 de fevereiro, ou um número que só parece uma data
+	}
+	confidence := DateConfidenceMedium
+	if match[4] != "" {
+		confidence = DateConfidenceHigh
+	}
+	return DateFixSuggestion{SuggestedDate: &date, Basis: DateBasisFilename, Confidence: confidence}
+}
+
+// filenameSequences agrupa as fotos com nome numerado pelo prefixo (ex: "dsc_"), em ordem de numeração.
+func filenameSequences(photos []*datePhoto) map[string][]*datePhoto {
+	sequences := make(map[string][]*datePhoto)
+	for _, photo := range photos {
+		if photo.number >= 0 {
+			sequences[photo.prefix] = append(sequences[photo.prefix], photo)
+		}
+	}
+	for _, sequence := range sequences {
+		sort.SliceStable(sequence, func(i, j int) bool { return sequence[i].number < sequence[j].number })
+	}
+	return sequences
+}
+
+// sequenceSuggestion sugere a data pela posição da foto na numeração dos arquivos com o mesmo prefixo,
+// interpolando entre as vizinhas com data confiável mais próximas.
+func sequenceSuggestion(photo *datePhoto, sequence []*datePhoto) DateFixSuggestion {
+	if photo.number < 0 {
+		return DateFixSuggestion{}
+	}
+	var previous, next *datePhoto
+	i := sort.Search(len(sequence), func(i int) bool { return sequence[i].number >= photo.number })
+	for j := i - 1; j >= 0 && photo.number-sequence[j].number <= dateFixMaxNumberGap; j-- {
+		if sequence[j].trusted() {
+			previous = sequence[j]
+			break
+		}
+	}
+	for j := i; j < len(sequence) && sequence[j].number-photo.number <= dateFixMaxNumberGap; j++ {
+		if sequence[j].number > photo.number && sequence[j].trusted() {
+			next = sequence[j]
+			break
+		}
+	}
+	return neighborSuggestion(previous, next, float64(photo.number), func(p *datePhoto) float64 { return float64(p.number) }, DateBasisFileSequence)
+}
+
+// importOrderSuggestion sugere a data pela posição da foto na ordem de importação (IDs), entre as fotos
+// importadas logo antes e logo depois com data confiável.
+func importOrderSuggestion(photos []*datePhoto, index int) DateFixSuggestion {
+	var previous, next *datePhoto
+	for i := index - 1; i >= 0 && i >= index-dateFixImportWindow; i-- {
+		if photos[i].trusted() {
+			previous = photos[i]
+			break
+		}
+	}
+	for i := index + 1; i < len(photos) && i <= index+dateFixImportWindow; i++ {
+		if photos[i].trusted() {
+			next = photos[i]
+			break
+		}
+	}
+	return neighborSuggestion(previous, next, float64(photos[index].ID), func(p *datePhoto) float64 { return float64(p.ID) }, DateBasisImportOrder)
+}
+
+// neighborSuggestion interpola a data entre as vizinhas pela posição (numeração ou ID); com uma só vizinha,
+// a data dela é sugerida com confiança baixa. O fuso sugerido é o da vizinha.
+func neighborSuggestion(previous, next *datePhoto, position float64, positionOf func(*datePhoto) float64, basis string) DateFixSuggestion {
+	suggestion := DateFixSuggestion{Basis: basis, Confidence: DateConfidenceLow}
+	switch {
+	case previous != nil && next != nil:
+		suggestion.PreviousID, suggestion.NextID = previous.ID, next.ID
+		span := next.ExifDate.Sub(*previous.ExifDate)
+		fraction := (position - positionOf(previous)) / (positionOf(next) - positionOf(previous))
+		date := previous.ExifDate.Add(time.Duration(float64(span) * fraction)).Truncate(time.Second)
+		suggestion.SuggestedDate, suggestion.TimeZoneOffset = &date, previous.TimeZoneOffset
+		switch {
+		case span >= 0 && span <= 24*time.Hour:
+			suggestion.Confidence = DateConfidenceHigh
+		case span >= 0 && span <= dateFixMaxNeighborSpan:
+			suggestion.Confidence = DateConfidenceMedium
+		}
+	case previous != nil:
+		suggestion.PreviousID = previous.ID
+		suggestion.SuggestedDate, suggestion.TimeZoneOffset = previous.ExifDate, previous.TimeZoneOffset
+	case next != nil:
+		suggestion.NextID = next.ID
+		suggestion.SuggestedDate, suggestion.TimeZoneOffset = next.ExifDate, next.TimeZoneOffset
+	}
+	return suggestion
+}
+
+// DateFix é a correção confirmada da data de uma foto.
+type DateFix struct {
+	PhotoID        uint
+	Date           time.Time
+	TimeZoneOffset *int // Fuso da captura em minutos (nil se desconhecido)
+}
+
+// dateFixChangeSource identifica as datas corrigidas pelo assistente no histórico de alterações.
+const dateFixChangeSource = "date_fix"
+
+// ApplyDateFixes grava as datas confirmadas como data EXIF das fotos (a data aproximada, se houver, é removida),
+// registrando as anteriores no histórico de alterações. Fotos bloqueadas são ignoradas. Retorna a quantidade de
+// fotos alteradas e a de bloqueadas.
+func (s *PhotoService) ApplyDateFixes(ctx context.Context, fixes []DateFix) (int, int, error) {
+	byPhoto := make(map[uint]DateFix, len(fixes))
+	ids := make([]uint, 0, len(fixes))
+	latest := time.Now().Add(24 * time.Hour)
+	for _, fix := range fixes {
+		if fix.PhotoID == 0 || fix.Date.Year() < dateFixMinYear || fix.Date.After(latest) ||
+			fix.TimeZoneOffset != nil && (*fix.TimeZoneOffset < -14*60 || *fix.TimeZoneOffset > 14*60) {
+			return 0, 0, ErrDateFixInvalid
+		}
+		if _, ok := byPhoto[fix.PhotoID]; !ok {
+			ids = append(ids, fix.PhotoID)
+		}
+		byPhoto[fix.PhotoID] = fix // Vale a última correção de cada foto
+	}
+
+	db := s.DB.WithContext(ctx)
+	var photos []database.Photo
+	if err := db.Select("id", "exif_date", "time_zone_offset").Where("id IN ?", ids).Order("id").Find(&photos).Error; err != nil {
+		return 0, 0, fmt.Errorf("erro ao buscar fotos: %w", err)
+	}
+	if len(photos) != len(ids) {
+		return 0, 0, i18n.NewError(i18n.CodePhotosNotFound)
+	}
+	locked, err := lockedPhotoIDs(db, ids)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var changed []uint
+	now := time.Now()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, photo := range photos {
+			if locked[photo.ID] {
+				continue
+			}
+			fix := byPhoto[photo.ID]
+			date := fix.Date
+			updates := map[string]interface{}{
+				"exif_date":        date,
+				"time_zone_offset": fix.TimeZoneOffset,
+				"circa_date":       nil,
+				"circa_precision":  "",
+			}
+			if err := tx.Model(&database.Photo{}).Where("id = ?", photo.ID).Updates(updates).Error; err != nil {
+				return fmt.Errorf("não foi possível atualizar a data da foto %d: %w", photo.ID, err)
+			}
+			change := database.MetadataChange{
+				PhotoID:   photo.ID,
+				Field:     "exif_date",
+				OldValue:  timeValue(photo.ExifDate).text,
+				NewValue:  timeValue(&date).text,
+				Source:    dateFixChangeSource,
+				AppliedAt: now,
+			}
+			if err := tx.Create(&change).Error; err != nil {
+				return fmt.Errorf("não foi possível registrar a alteração da foto %d: %w", photo.ID, err)
+			}
+			changed = append(changed, photo.ID)
+		}
+		return refreshPhotoAlbumDates(tx, changed)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, id := range changed {
+		s.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": id})
+	}
+	return len(changed), len(ids) - len(changed), nil
+}
@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// backupPrefix e backupExt compõem o nome das cópias de segurança: photo_manager-AAAAMMDD-HHMMSS.db.
+const (
+	backupPrefix = "photo_manager-"
+	backupExt    = ".db"
+)
+
+// vacuumFreeRatio é a fração de páginas livres a partir da qual a manutenção programada executa o VACUUM.
+// Abaixo disso, o ganho de espaço não compensa reescrever o banco inteiro.
+const vacuumFreeRatio = 0.1
+
+// Erros da manutenção do banco.
+var (
+	ErrMaintenanceRunning = i18n.NewError(i18n.CodeDatabaseMaintenanceBusy)
+	ErrBackupNotFound     = i18n.NewError(i18n.CodeDatabaseBackupNotFound)
+)
+
+// DatabaseBackup é uma cópia de segurança do banco.
+type DatabaseBackup struct {
+	Name      string
+	Path      string
+	SizeBytes int64
+	CreatedAt time.Time
+	Duration  time.Duration // Tempo da cópia (só nas cópias recém-criadas)
+}
+
+// DatabaseStats descreve o arquivo do banco, suas páginas, tabelas e índices.
+type DatabaseStats struct {
+	Path          string
+	SizeBytes     int64 // Arquivo principal
+	WALSizeBytes  int64 // Arquivo -wal, se o banco usar WAL
+	SQLiteVersion string
+	SQLiteDriver  string // Implementação do SQLite compilada no binário (ver database.Driver)
+	JournalMode   string
+	PageSize      int64
+	PageCount     int64
+	FreePages     int64 // Páginas livres, recuperáveis com VACUUM
+	FreeBytes     int64
+	Analyzed      bool // ANALYZE já foi executado (o planejador usa as estatísticas dos índices)
+	Tables        []TableStats
+	Indexes       []IndexStats
+	LastBackup    *DatabaseBackup
+}
+
+// TableStats são as linhas de uma tabela.
+type TableStats struct {
+	Name string
+	Rows int64
+}
+
+// IndexStats descreve um índice e as estatísticas coletadas pelo ANALYZE. O SQLite não registra quantas
+// vezes cada índice é usado; Redundant e LowSelectivity apontam os índices que provavelmente só custam
+// espaço e escrita.
+type IndexStats struct {
+	Name           string
+	Table          string
+	Columns        []string
+	Unique         bool
+	Rows           int64   // Linhas indexadas (do ANALYZE; 0 se não analisado)
+	RowsPerKey     float64 // Média de linhas por valor da primeira coluna (do ANALYZE); quanto menor, mais seletivo
+	Redundant      string  // Índice que já cobre as mesmas colunas iniciais, se houver
+	LowSelectivity bool    // Cada valor corresponde a mais de 10% das linhas: o planejador tende a ignorá-lo
+}
+
+// MaintenanceResult é o resultado de um VACUUM ou ANALYZE.
+type MaintenanceResult struct {
+	Operation   string
+	Duration    time.Duration
+	SizeBefore  int64
+	SizeAfter   int64
+	FreedBytes  int64
+	CompletedAt time.Time
+}
+
+// DatabaseMaintenanceService faz cópias de segurança consistentes do banco SQLite com ele em uso (VACUUM
+// INTO), executa VACUUM e ANALYZE e relata o tamanho e o estado dos índices. As operações são
+// serializadas: enquanto uma roda, as demais recebem ErrMaintenanceRunning.
+type DatabaseMaintenanceService struct {
+	DB         *gorm.DB
+	Path       string // Arquivo do banco
+	BackupDir  string // Diretório das cópias de segurança
+	BackupKeep int    // Cópias mantidas; as mais antigas são apagadas após cada nova cópia (0 = todas)
+
+	running sync.Mutex
+}
+
+// NewDatabaseMaintenanceService cria uma nova instância de DatabaseMaintenanceService.
+func NewDatabaseMaintenanceService(db *gorm.DB, path, backupDir string, backupKeep int) *DatabaseMaintenanceService {
+	return &DatabaseMaintenanceService{
+		DB:         db,
+		Path:       path,
+		BackupDir:  backupDir,
+		BackupKeep: backupKeep,
+	}
+}
+
+// Backup grava uma cópia consistente do banco em BackupDir sem interromper o uso: o VACUUM INTO lê uma
+// fotografia do banco em uma transação de leitura, então as escritas feitas durante a cópia não a
+// corrompem. A cópia é gravada com outro nome e renomeada ao final, então uma cópia pela metade nunca
+// aparece na lista.
+func (s *DatabaseMaintenanceService) Backup(ctx context.Context) (*DatabaseBackup, error) {
+	if !s.running.TryLock() {
+		return nil, ErrMaintenanceRunning
+	}
+	defer s.running.Unlock()
+
+	if err := os.MkdirAll(s.BackupDir, 0755); err != nil {
+		return nil, fmt.Errorf("não foi possível criar o diretório das cópias: %w", err)
+	}
+	start := time.Now()
+	name := backupPrefix + start.Format("20060102-150405") + backupExt
+	path := filepath.Join(s.BackupDir, name)
+	tmpPath := path + ".tmp"
+	os.Remove(tmpPath) // Sobra de uma cópia interrompida
+
+	// A cópia de um banco grande passa facilmente do limite das consultas comuns (DB_QUERY_TIMEOUT_SECONDS)
+	if err := database.WithoutQueryTimeout(s.DB.WithContext(ctx)).Exec("VACUUM INTO ?", tmpPath).Error; err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("falha na cópia do banco: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("não foi possível gravar a cópia do banco: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível ler a cópia do banco: %w", err)
+	}
+
+	s.pruneBackups()
+	return &DatabaseBackup{Name: name, Path: path, SizeBytes: info.Size(), CreatedAt: start, Duration: time.Since(start)}, nil
+}
+
+// pruneBackups apaga as cópias além das BackupKeep mais recentes.
+func (s *DatabaseMaintenanceService) pruneBackups() {
+	if s.BackupKeep <= 0 {
+		return
+	}
+	backups, err := s.ListBackups()
+	if err != nil || len(backups) <= s.BackupKeep {
+		return
+	}
+	for _, backup := range backups[s.BackupKeep:] {
+		if err := os.Remove(backup.Path); err != nil {
+			log.Printf("Não foi possível apagar a cópia antiga do banco '%s': %v\n", backup.Name, err)
+		}
+	}
+}
+
+// ListBackups retorna as cópias de segurança, da mais recente para a mais antiga.
+func (s *DatabaseMaintenanceService) ListBackups() ([]DatabaseBackup, error) {
+	entries, err := os.ReadDir(s.BackupDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []DatabaseBackup{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível listar as cópias do banco: %w", err)
+	}
+
+	backups := []DatabaseBackup{}
+	for _, entry := range entries {
+		if !validBackupName(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, DatabaseBackup{
+			Name:      entry.Name(),
+			Path:      filepath.Join(s.BackupDir, entry.Name()),
+			SizeBytes: info.Size(),
+			CreatedAt: info.ModTime(),
+		})
+	}
+	// O nome contém a data, então a ordem alfabética inversa é a cronológica inversa
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Name > backups[j].Name })
+	return backups, nil
+}
+
+// BackupPath retorna o caminho da cópia com o nome informado.
+func (s *DatabaseMaintenanceService) BackupPath(name string) (string, error) {
+	if !validBackupName(name) {
+		return "", ErrBackupNotFound
+	}
+	path := filepath.Join(s.BackupDir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", ErrBackupNotFound
+	}
+	return path, nil
+}
+
+// validBackupName aceita apenas nomes gerados por Backup, sem separadores de diretório.
+func validBackupName(name string) bool {
+	return strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, backupExt) && filepath.Base(name) == name
+}
+
+// Vacuum reescreve o banco, devolvendo ao sistema as páginas livres e desfragmentando tabelas e índices.
+// Durante o VACUUM as escritas ficam bloqueadas, por isso ele é indicado para a janela de manutenção.
+func (s *DatabaseMaintenanceService) Vacuum(ctx context.Context) (*MaintenanceResult, error) {
+	return s.maintain(ctx, "vacuum", "VACUUM")
+}
+
+// Analyze atualiza as estatísticas dos índices usadas pelo planejador de consultas.
+func (s *DatabaseMaintenanceService) Analyze(ctx context.Context) (*MaintenanceResult, error) {
+	return s.maintain(ctx, "analyze", "ANALYZE")
+}
+
+// maintain executa um comando de manutenção, medindo o arquivo antes e depois.
+func (s *DatabaseMaintenanceService) maintain(ctx context.Context, operation, statement string) (*MaintenanceResult, error) {
+	if !s.running.TryLock() {
+		return nil, ErrMaintenanceRunning
+	}
+	defer s.running.Unlock()
+
+	start := time.Now()
+	before := fileSize(s.Path)
+	if err := database.WithoutQueryTimeout(s.DB.WithContext(ctx)).Exec(statement).Error; err != nil {
+		return nil, fmt.Errorf("falha ao executar %s: %w", statement, err)
+	}
+	after := fileSize(s.Path)
+	return &MaintenanceResult{
+		Operation:   operation,
+		Duration:    time.Since(start),
+		SizeBefore:  before,
+		SizeAfter:   after,
+		FreedBytes:  max(0, before-after),
+		CompletedAt: time.Now(),
+	}, nil
+}
+
+// Stats relata o tamanho do banco, as páginas livres, as linhas de cada tabela e o estado dos índices.
+func (s *DatabaseMaintenanceService) Stats(ctx context.Context) (*DatabaseStats, error) {
+	db := s.DB.WithContext(ctx)
+	stats := &DatabaseStats{
+		Path:         s.Path,
+		SizeBytes:    fileSize(s.Path),
+		WALSizeBytes: fileSize(s.Path + "-wal"),
+		SQLiteDriver: database.Driver,
+	}
+
+	pragmas := []struct {
+		query string
+		dest  interface{}
+	}{
+		{"SELECT sqlite_version()", &stats.SQLiteVersion},
+		{"PRAGMA journal_mode", &stats.JournalMode},
+		{"PRAGMA page_size", &stats.PageSize},
+		{"PRAGMA page_count", &stats.PageCount},
+		{"PRAGMA freelist_count", &stats.FreePages},
+	}
+	for _, pragma := range pragmas {
+		if err := db.Raw(pragma.query).Scan(pragma.dest).Error; err != nil {
+			return nil, fmt.Errorf("erro ao ler '%s': %w", pragma.query, err)
+		}
+	}
+	stats.FreeBytes = stats.FreePages * stats.PageSize
+
+	var tables []string
+	if err := db.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name").Scan(&tables).Error; err != nil {
+		return nil, fmt.Errorf("erro ao listar as tabelas: %w", err)
+	}
+	for _, table := range tables {
+		var rows int64
+		if err := db.Table(table).Count(&rows).Error; err != nil {
+			return nil, fmt.Errorf("erro ao contar as linhas de '%s': %w", table, err)
+		}
+		stats.Tables = append(stats.Tables, TableStats{Name: table, Rows: rows})
+	}
+
+	indexes, analyzed, err := s.indexStats(db)
+	if err != nil {
+		return nil, err
+	}
+	stats.Indexes, stats.Analyzed = indexes, analyzed
+
+	backups, err := s.ListBackups()
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) > 0 {
+		stats.LastBackup = &backups[0]
+	}
+	return stats, nil
+}
+
+// indexStats lista os índices com as colunas, as estatísticas do ANALYZE (sqlite_stat1) e os indícios de
+// índices inúteis.
+func (s *DatabaseMaintenanceService) indexStats(db *gorm.DB) ([]IndexStats, bool, error) {
+	var rows []struct {
+		Name     string
+		TblName  string
+		IsUnique bool
+	}
+	err := db.Raw(`SELECT m.name, m.tbl_name,
+			EXISTS (SELECT 1 FROM pragma_index_list(m.tbl_name) l WHERE l.name = m.name AND l."unique") AS is_unique
+		FROM sqlite_master m WHERE m.type = 'index' AND m.name NOT LIKE 'sqlite_%' ORDER BY m.tbl_name, m.name`).Scan(&rows).Error
+	if err != nil {
+		return nil, false, fmt.Errorf("erro ao listar os índices: %w", err)
+	}
+
+	// sqlite_stat1 só existe depois do primeiro ANALYZE
+	var statTables int64
+	if err := db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'sqlite_stat1'").Scan(&statTables).Error; err != nil {
+		return nil, false, fmt.Errorf("erro ao verificar as estatísticas dos índices: %w", err)
+	}
+	analyzed := statTables > 0
+	stat := map[string]string{}
+	if analyzed {
+		var entries []struct {
+			Idx  string
+			Stat string
+		}
+		if err := db.Raw("SELECT idx, stat FROM sqlite_stat1 WHERE idx IS NOT NULL").Scan(&entries).Error; err != nil {
+			return nil, false, fmt.Errorf("erro ao ler as estatísticas dos índices: %w", err)
+		}
+		for _, entry := range entries {
+			stat[entry.Idx] = entry.Stat
+		}
+	}
+
+	indexes := make([]IndexStats, 0, len(rows))
+	for _, row := range rows {
+		var columns []string
+		if err := db.Raw("SELECT name FROM pragma_index_info(?) ORDER BY seqno", row.Name).Scan(&columns).Error; err != nil {
+			return nil, false, fmt.Errorf("erro ao ler as colunas do índice '%s': %w", row.Name, err)
+		}
+		index := IndexStats{Name: row.Name, Table: row.TblName, Columns: columns, Unique: row.IsUnique}
+		// stat: "<linhas> <linhas por valor da 1ª coluna> <linhas por valor das 2 primeiras> ..."
+		if fields := strings.Fields(stat[row.Name]); len(fields) >= 2 {
+			fmt.Sscan(fields[0], &index.Rows)
+			fmt.Sscan(fields[1], &index.RowsPerKey)
+			index.LowSelectivity = !index.Unique && index.Rows >= 100 && index.RowsPerKey > float64(index.Rows)/10
+		}
+		indexes = append(indexes, index)
+	}
+
+	// Um índice cujas colunas são o início de outro índice da mesma tabela é redundante (salvo se único)
+	for i := range indexes {
+		for j := range indexes {
+			a, b := &indexes[i], &indexes[j]
+			if i == j || a.Unique || a.Table != b.Table || len(a.Columns) == 0 || len(a.Columns) > len(b.Columns) {
+				continue
+			}
+			if slices.Equal(a.Columns, b.Columns[:len(a.Columns)]) && (len(a.Columns) < len(b.Columns) || a.Name > b.Name) {
+				a.Redundant = b.Name
+				break
+			}
+		}
+	}
+	return indexes, analyzed, nil
+}
+
+// RunMaintenance executa a rotina da janela de manutenção: cópia de segurança, ANALYZE e, se as páginas
+// livres passarem de 10% do arquivo, VACUUM.
+func (s *DatabaseMaintenanceService) RunMaintenance(ctx context.Context) {
+	backup, err := s.Backup(ctx)
+	if err != nil {
+		log.Printf("Manutenção do banco: falha na cópia de segurança: %v\n", err)
+		return // Sem cópia recente, não arrisca reescrever o banco
+	}
+	log.Printf("Manutenção do banco: cópia de segurança '%s' (%d bytes) em %s\n", backup.Name, backup.SizeBytes, backup.Duration.Round(time.Millisecond))
+
+	if _, err := s.Analyze(ctx); err != nil {
+		log.Printf("Manutenção do banco: falha no ANALYZE: %v\n", err)
+	}
+
+	var pageCount, freePages int64
+	s.DB.WithContext(ctx).Raw("PRAGMA page_count").Scan(&pageCount)
+	s.DB.WithContext(ctx).Raw("PRAGMA freelist_count").Scan(&freePages)
+	if pageCount == 0 || float64(freePages)/float64(pageCount) < vacuumFreeRatio {
+		return
+	}
+	result, err := s.Vacuum(ctx)
+	if err != nil {
+		log.Printf("Manutenção do banco: falha no VACUUM: %v\n", err)
+		return
+	}
+	log.Printf("Manutenção do banco: VACUUM liberou %d bytes em %s\n", result.FreedBytes, result.Duration.Round(time.Millisecond))
+}
+
+// StartScheduler executa RunMaintenance todos os dias no horário informado (0 a 23, horário local), até o
+// contexto ser cancelado.
+func (s *DatabaseMaintenanceService) StartScheduler(ctx context.Context, hour int) {
+	go func() {
+		for {
+			timer := time.NewTimer(time.Until(nextRunAt(time.Now(), hour)))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				s.RunMaintenance(ctx)
+			}
+		}
+	}()
+}
+
+// fileSize retorna o tamanho do arquivo (0 se não existir).
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
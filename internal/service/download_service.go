@@ -0,0 +1,339 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// downloadRetention é por quanto tempo o ZIP preparado de uma seleção fica disponível para download.
+const downloadRetention = 24 * time.Hour
+
+// zipEntryOverhead é o espaço aproximado que cada arquivo ocupa no ZIP além do conteúdo e de duas cópias do
+// nome (cabeçalho local, descritor de dados e entrada do diretório central).
+const zipEntryOverhead = 30 + 16 + 46
+
+// ErrDownloadSelectionEmpty indica um pedido de download sem nenhuma foto.
+var ErrDownloadSelectionEmpty = i18n.NewError(i18n.CodeDownloadSelectionEmpty)
+
+// DownloadService gera o ZIP dos originais de uma seleção de fotos. Seleções pequenas são enviadas
+// diretamente na resposta; as que passam de PrepareThreshold são preparadas em background, e o evento
+// download.ready avisa quando o arquivo pode ser baixado.
+type DownloadService struct {
+	DB               *gorm.DB
+	Dir              string      // Diretório dos arquivos preparados
+	PrepareThreshold int64       // Seleções acima deste total de bytes são preparadas em background (0 desativa)
+	Events           *events.Bus // Publica download.ready ao terminar uma preparação
+
+	mu      sync.Mutex
+	running map[uint]bool // Jobs em execução neste processo, evita execuções duplicadas
+}
+
+// NewDownloadService cria uma nova instância de DownloadService.
+func NewDownloadService(db *gorm.DB, dir string, prepareThreshold int64, bus *events.Bus) *DownloadService {
+	return &DownloadService{
+		DB:               db,
+		Dir:              dir,
+		PrepareThreshold: prepareThreshold,
+		Events:           bus,
+		running:          make(map[uint]bool),
+	}
+}
+
+// DownloadEstimate é o tamanho estimado do ZIP de uma seleção de fotos.
+type DownloadEstimate struct {
+	Files        int   // Arquivos que serão incluídos no ZIP
+	Missing      int   // Fotos selecionadas cujo arquivo não pôde ser lido (ficam de fora do ZIP)
+	TotalBytes   int64 // Soma dos arquivos incluídos
+	ArchiveBytes int64 // Tamanho aproximado do ZIP (os arquivos são armazenados sem compressão)
+	Prepare      bool  // true se a seleção passa do limite e será preparada em background
+}
+
+// EstimateDownload calcula o tamanho do ZIP da seleção a partir dos arquivos em disco (o original em
+// resolução completa, quando a foto foi reduzida na ingestão).
+func (s *DownloadService) EstimateDownload(ctx context.Context, photoIDs []uint) (*DownloadEstimate, error) {
+	photos, err := s.selectedPhotos(ctx, photoIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &DownloadEstimate{ArchiveBytes: 22} // Registro final do diretório central
+	for _, photo := range photos {
+		info, err := os.Stat(downloadSource(photo))
+		if err != nil {
+			estimate.Missing++
+			continue
+		}
+		// Nome dentro do ZIP: photos/<ano>/<mês>/<arquivo>
+		nameLength := int64(len("photos/2006/01/") + len(filepath.Base(photo.Filename)))
+		estimate.Files++
+		estimate.TotalBytes += info.Size()
+		estimate.ArchiveBytes += info.Size() + zipEntryOverhead + 2*nameLength
+	}
+	estimate.Prepare = s.PrepareThreshold > 0 && estimate.TotalBytes > s.PrepareThreshold
+	return estimate, nil
+}
+
+// WriteDownload grava diretamente em w o ZIP dos arquivos da seleção. Arquivos que não puderem ser lidos são
+// ignorados; skipped informa quantos.
+func (s *DownloadService) WriteDownload(ctx context.Context, w io.Writer, photoIDs []uint) (archived, skipped int, err error) {
+	photos, err := s.selectedPhotos(ctx, photoIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	zw := zip.NewWriter(w)
+	archived, skipped, err = writeDownloadPhotos(ctx, zw, photos)
+	if err != nil {
+		return archived, skipped, err
+	}
+	return archived, skipped, zw.Close()
+}
+
+// PrepareDownload cria o job que prepara o ZIP da seleção em background.
+func (s *DownloadService) PrepareDownload(photoIDs []uint, estimate *DownloadEstimate) (*database.DownloadJob, error) {
+	ids := uniqueIDs(photoIDs)
+	encoded, err := json.Marshal(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &database.DownloadJob{
+		Status:         database.ExportStatusPending,
+		PhotoIDs:       string(encoded),
+		TotalPhotos:    len(ids),
+		EstimatedBytes: estimate.TotalBytes,
+	}
+	if err := s.DB.Create(job).Error; err != nil {
+		return nil, fmt.Errorf("não foi possível criar o job de download: %w", err)
+	}
+
+	s.launch(job.ID)
+	return job, nil
+}
+
+// GetDownloadJob retorna o estado atual de um job de download.
+func (s *DownloadService) GetDownloadJob(id uint) (*database.DownloadJob, error) {
+	var job database.DownloadJob
+	if result := s.DB.First(&job, id); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar job de download: %w", result.Error)
+	}
+	return &job, nil
+}
+
+// ResumePendingDownloads refaz as preparações que não terminaram (ex: interrompidas por um reinício do
+// servidor), desde o início.
+func (s *DownloadService) ResumePendingDownloads() error {
+	var jobs []database.DownloadJob
+	result := s.DB.Where("status IN ?", []string{database.ExportStatusPending, database.ExportStatusRunning}).Find(&jobs)
+	if result.Error != nil {
+		return fmt.Errorf("erro ao buscar downloads pendentes: %w", result.Error)
+	}
+
+	for _, job := range jobs {
+		log.Printf("Refazendo a preparação do download %d interrompida\n", job.ID)
+		s.launch(job.ID)
+	}
+	return nil
+}
+
+// launch executa o job em uma goroutine, garantindo que o mesmo job não rode duas vezes em paralelo.
+func (s *DownloadService) launch(jobID uint) {
+	s.mu.Lock()
+	if s.running[jobID] {
+		s.mu.Unlock()
+		return
+	}
+	s.running[jobID] = true
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, jobID)
+			s.mu.Unlock()
+		}()
+		if err := s.runDownload(jobID); err != nil {
+			log.Printf("Erro no job de download %d: %v\n", jobID, err)
+		}
+	}()
+}
+
+// runDownload gera o ZIP da seleção em um arquivo temporário, o renomeia ao final e remove os arquivos
+// preparados que já expiraram.
+func (s *DownloadService) runDownload(jobID uint) error {
+	job, err := s.GetDownloadJob(jobID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if result := s.DB.Model(job).Updates(map[string]interface{}{
+		"status":          database.ExportStatusRunning,
+		"started_at":      now,
+		"archived_photos": 0,
+		"skipped_photos":  0,
+	}); result.Error != nil {
+		return fmt.Errorf("não foi possível atualizar o status do job: %w", result.Error)
+	}
+
+	archivePath := filepath.Join(s.Dir, fmt.Sprintf("download-%d.zip", job.ID))
+	size, err := s.writeArchive(job, archivePath)
+	finishedAt := time.Now()
+	if err != nil {
+		s.DB.Model(job).Updates(map[string]interface{}{
+			"status":      database.ExportStatusFailed,
+			"last_error":  err.Error(),
+			"finished_at": finishedAt,
+		})
+		s.Events.Publish(events.TypeDownloadReady, map[string]interface{}{
+			"job_id": job.ID,
+			"status": database.ExportStatusFailed,
+			"error":  err.Error(),
+		})
+		return err
+	}
+
+	if result := s.DB.Model(job).Updates(map[string]interface{}{
+		"status":          database.ExportStatusCompleted,
+		"archive_path":    archivePath,
+		"size_bytes":      size,
+		"archived_photos": job.ArchivedPhotos,
+		"skipped_photos":  job.SkippedPhotos,
+		"finished_at":     finishedAt,
+	}); result.Error != nil {
+		return fmt.Errorf("não foi possível finalizar o job de download: %w", result.Error)
+	}
+	s.removeExpiredArchives()
+
+	log.Printf("Download %d preparado: %d foto(s), %d ignorada(s), %d bytes\n",
+		job.ID, job.ArchivedPhotos, job.SkippedPhotos, size)
+	s.Events.Publish(events.TypeDownloadReady, map[string]interface{}{
+		"job_id":   job.ID,
+		"status":   database.ExportStatusCompleted,
+		"archived": job.ArchivedPhotos,
+		"skipped":  job.SkippedPhotos,
+		"size":     size,
+		"url":      fmt.Sprintf("/downloads/%d/download", job.ID),
+	})
+	return nil
+}
+
+// writeArchive grava o ZIP da seleção do job em archivePath, retornando o seu tamanho.
+func (s *DownloadService) writeArchive(job *database.DownloadJob, archivePath string) (int64, error) {
+	var ids []uint
+	if err := json.Unmarshal([]byte(job.PhotoIDs), &ids); err != nil {
+		return 0, fmt.Errorf("seleção do download inválida: %w", err)
+	}
+	photos, err := s.selectedPhotos(context.Background(), ids)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return 0, fmt.Errorf("não foi possível criar o diretório de downloads: %w", err)
+	}
+
+	tmpPath := archivePath + ".part"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, fmt.Errorf("não foi possível criar o arquivo do download: %w", err)
+	}
+	defer os.Remove(tmpPath) // Sem efeito após o rename
+
+	zw := zip.NewWriter(out)
+	job.ArchivedPhotos, job.SkippedPhotos, err = writeDownloadPhotos(context.Background(), zw, photos)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("erro ao gravar o arquivo do download: %w", err)
+	}
+
+	info, err := os.Stat(tmpPath)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao verificar o arquivo do download: %w", err)
+	}
+	if err := os.Rename(tmpPath, archivePath); err != nil {
+		return 0, fmt.Errorf("não foi possível finalizar o arquivo do download: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// removeExpiredArchives apaga os arquivos preparados há mais de downloadRetention.
+func (s *DownloadService) removeExpiredArchives() {
+	var jobs []database.DownloadJob
+	cutoff := time.Now().Add(-downloadRetention)
+	if err := s.DB.Where("finished_at < ? AND archive_path <> ''", cutoff).Find(&jobs).Error; err != nil {
+		log.Printf("Aviso: não foi possível listar downloads expirados: %v\n", err)
+		return
+	}
+	for _, job := range jobs {
+		if err := os.Remove(job.ArchivePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Aviso: não foi possível remover o download %d: %v\n", job.ID, err)
+			continue
+		}
+		s.DB.Model(&job).Update("archive_path", "")
+	}
+}
+
+// selectedPhotos carrega as fotos da seleção, em ordem de ID. Retorna ErrDownloadSelectionEmpty para uma
+// seleção vazia e CodePhotosNotFound se alguma foto não existir.
+func (s *DownloadService) selectedPhotos(ctx context.Context, photoIDs []uint) ([]database.Photo, error) {
+	ids := uniqueIDs(photoIDs)
+	if len(ids) == 0 {
+		return nil, ErrDownloadSelectionEmpty
+	}
+	var photos []database.Photo
+	if err := s.DB.WithContext(ctx).Where("id IN ?", ids).Order("id").Find(&photos).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar as fotos selecionadas: %w", err)
+	}
+	if len(photos) != len(ids) {
+		return nil, i18n.NewError(i18n.CodePhotosNotFound)
+	}
+	return photos, nil
+}
+
+// writeDownloadPhotos copia os arquivos das fotos para o ZIP, ignorando os que não puderem ser lidos.
+func writeDownloadPhotos(ctx context.Context, zw *zip.Writer, photos []database.Photo) (archived, skipped int, err error) {
+	names := newArchiveNamer()
+	for _, photo := range photos {
+		if err := ctx.Err(); err != nil {
+			return archived, skipped, err
+		}
+		if _, err := writeExportPhoto(zw, names, photo); err != nil {
+			log.Printf("Download: arquivo da foto %d ignorado: %v\n", photo.ID, err)
+			skipped++
+			continue
+		}
+		archived++
+	}
+	return archived, skipped, nil
+}
+
+// downloadSource é o arquivo incluído no ZIP para a foto: o original em resolução completa, quando a foto
+// foi reduzida na ingestão e o original foi guardado.
+func downloadSource(photo database.Photo) string {
+	if photo.Downscaled && fileExists(photo.OriginalPath) {
+		return photo.OriginalPath
+	}
+	return photo.StoredPath
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"photo-manager/internal/storage"
+	"photo-manager/internal/thumbnail"
+	"time"
+)
+
+// DownscalePolicy reduz, na ingestão, imagens acima de MaxPixels (ex: fotos de 100 MP armazenadas em 12 MP),
+// economizando espaço. Fotos indexadas no local nunca são alteradas.
+type DownscalePolicy struct {
+	MaxPixels int64                // Limite de pixels da versão armazenada (0 desativa a redução)
+	Originals *storage.FileManager // Armazenamento dos originais em resolução completa (nil = original descartado)
+}
+
+// applies indica se a imagem deve ser reduzida antes de ser armazenada.
+func (p DownscalePolicy) applies(req ingestRequest, width, height int) bool {
+	if p.MaxPixels <= 0 || req.ManagedExternally {
+		return false
+	}
+	if req.MimeType != "image/jpeg" && req.MimeType != "image/png" {
+		return false
+	}
+	return int64(width)*int64(height) > p.MaxPixels
+}
+
+// downscaledFile é a versão reduzida de uma imagem, gravada em um arquivo temporário.
+type downscaledFile struct {
+	Path          string // Arquivo temporário; removido pelo chamador após o armazenamento
+	Size          int64
+	Width, Height int
+	OriginalPath  string // Onde o original foi guardado (vazio se descartado)
+}
+
+// downscaleForStorage gera a versão reduzida da imagem e, se houver armazenamento de originais,
+// guarda nele uma cópia do arquivo em resolução completa.
+func (s *PhotoService) downscaleForStorage(ctx context.Context, req ingestRequest, hash string, photoDate time.Time) (*downscaledFile, error) {
+	tmp, err := os.CreateTemp("", "photo-manager-downscale-*")
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível criar o arquivo temporário da redução: %w", err)
+	}
+	tmp.Close()
+
+	width, height, err := thumbnail.Downscale(req.SourcePath, tmp.Name(), s.Downscale.MaxPixels)
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("não foi possível reduzir a imagem: %w", err)
+	}
+	info, err := os.Stat(tmp.Name())
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("não foi possível ler a imagem reduzida: %w", err)
+	}
+
+	reduced := &downscaledFile{Path: tmp.Name(), Size: info.Size(), Width: width, Height: height}
+	if s.Downscale.Originals == nil {
+		return reduced, nil
+	}
+
+	src, err := os.Open(req.SourcePath)
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("não foi possível abrir o original: %w", err)
+	}
+	defer src.Close()
+
+	reduced.OriginalPath, _, err = s.Downscale.Originals.SaveFromReader(contextReader{ctx: ctx, r: src}, req.Filename, hash, req.FileSize, photoDate)
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("não foi possível guardar o original: %w", err)
+	}
+	return reduced, nil
+}
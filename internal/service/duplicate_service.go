@@ -0,0 +1,446 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/bits"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/thumbnail"
+	"photo-manager/internal/video"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Políticas de escolha da foto mantida em cada grupo de duplicatas.
+const (
+	DedupeKeepOldest  = "keep-oldest"  // Mantém a foto mais antiga (data EXIF, ou de upload)
+	DedupeKeepLargest = "keep-largest" // Mantém a foto com mais pixels (e, no empate, o maior arquivo)
+	DedupeKeepRaw     = "keep-raw"     // Mantém o arquivo RAW; sem RAW no grupo, segue keep-largest
+)
+
+// Tipos de grupo de duplicatas.
+const (
+	DuplicateExact = "exact" // Imagens idênticas pixel a pixel (ex: mesma foto com metadados diferentes)
+	DuplicateNear  = "near"  // Imagens visualmente parecidas (recompressão, redimensionamento) ou par RAW+JPEG
+)
+
+// Limites da distância de Hamming entre hashes perceptuais para considerar duas fotos quase idênticas.
+const (
+	DefaultNearDuplicateThreshold = 4
+	MaxNearDuplicateThreshold     = 16
+)
+
+// rawExtensions lista as extensões de arquivos RAW reconhecidas pela política keep-raw.
+var rawExtensions = map[string]bool{
+	".dng": true, ".cr2": true, ".cr3": true, ".nef": true, ".arw": true,
+	".raf": true, ".orf": true, ".rw2": true, ".pef": true, ".srw": true,
+}
+
+// ValidDedupePolicy indica se a política de deduplicação é suportada.
+func ValidDedupePolicy(policy string) bool {
+	return policy == DedupeKeepOldest || policy == DedupeKeepLargest || policy == DedupeKeepRaw
+}
+
+// DuplicateService localiza fotos duplicadas em toda a biblioteca e remove as cópias redundantes.
+type DuplicateService struct {
+	DB           *gorm.DB
+	PhotoService *PhotoService
+	AlbumService *AlbumService
+}
+
+// NewDuplicateService cria uma nova instância de DuplicateService.
+func NewDuplicateService(db *gorm.DB, ps *PhotoService, as *AlbumService) *DuplicateService {
+	return &DuplicateService{
+		DB:           db,
+		PhotoService: ps,
+		AlbumService: as,
+	}
+}
+
+// DuplicateOptions controla a detecção de duplicatas.
+type DuplicateOptions struct {
+	Policy      string // Política de escolha da foto mantida (DedupeKeep*)
+	Threshold   int    // Distância de Hamming máxima entre hashes perceptuais de fotos quase idênticas
+	IncludeNear bool   // Inclui grupos de fotos quase idênticas, além das idênticas
+}
+
+// DuplicateGroup é um conjunto de fotos consideradas duplicatas entre si.
+type DuplicateGroup struct {
+	Kind             string // DuplicateExact ou DuplicateNear
+	PhotoIDs         []uint // Todas as fotos do grupo
+	KeepID           uint   // Foto mantida pela política
+	RemoveIDs        []uint // Fotos que seriam removidas
+	LockedIDs        []uint // Cópias bloqueadas, mantidas mesmo não sendo a foto escolhida
+	ReclaimableBytes int64  // Espaço liberado ao remover as cópias (arquivos indexados no local não são apagados)
+}
+
+// DuplicateReport é o resultado da detecção (e, fora do modo de simulação, da remoção) de duplicatas.
+type DuplicateReport struct {
+	Policy           string
+	DryRun           bool
+	Groups           []DuplicateGroup
+	ReclaimableBytes int64 // Total de bytes liberados pela remoção de todas as cópias
+	Fingerprinted    int   // Fotos cujas impressões digitais foram calculadas nesta execução
+	Unreadable       int   // Fotos que não puderam ser decodificadas e ficaram fora da comparação visual
+	Removed          int   // Fotos removidas (0 no modo de simulação)
+	Failed           int   // Fotos cuja remoção falhou
+}
+
+// FindDuplicates calcula as impressões digitais pendentes e agrupa as fotos duplicadas. Nada é removido.
+func (s *DuplicateService) FindDuplicates(ctx context.Context, opts DuplicateOptions) (*DuplicateReport, error) {
+	if !ValidDedupePolicy(opts.Policy) {
+		return nil, fmt.Errorf("política de deduplicação inválida: '%s'", opts.Policy)
+	}
+	if opts.Threshold < 0 || opts.Threshold > MaxNearDuplicateThreshold {
+		return nil, fmt.Errorf("limite de similaridade inválido: use de 0 a %d", MaxNearDuplicateThreshold)
+	}
+
+	report := &DuplicateReport{Policy: opts.Policy, DryRun: true}
+	var err error
+	report.Fingerprinted, report.Unreadable, err = s.updateFingerprints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var photos []database.Photo
+	result := s.DB.WithContext(ctx).
+		Select("id", "filename", "file_size", "mime_type", "width", "height", "exif_date", "upload_date",
+			"pixel_hash", "perceptual_hash", "managed_externally").
+		Where("quarantined = ?", false).
+		Order("id ASC").
+		Find(&photos)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar fotos para detecção de duplicatas: %w", result.Error)
+	}
+
+	groups := groupDuplicates(photos, opts)
+	var groupedIDs []uint
+	for _, members := range groups {
+		for _, photo := range members {
+			groupedIDs = append(groupedIDs, photo.ID)
+		}
+	}
+	locked, err := lockedPhotoIDs(s.DB.WithContext(ctx), groupedIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, members := range groups {
+		group := DuplicateGroup{Kind: DuplicateExact, RemoveIDs: []uint{}, LockedIDs: []uint{}}
+		for _, photo := range members {
+			group.PhotoIDs = append(group.PhotoIDs, photo.ID)
+			if photo.PixelHash == "" || photo.PixelHash != members[0].PixelHash {
+				group.Kind = DuplicateNear
+			}
+		}
+
+		keep := chooseKeeper(members, opts.Policy)
+		group.KeepID = keep.ID
+		for _, photo := range members {
+			if photo.ID == keep.ID {
+				continue
+			}
+			if locked[photo.ID] {
+				group.LockedIDs = append(group.LockedIDs, photo.ID)
+				continue
+			}
+			group.RemoveIDs = append(group.RemoveIDs, photo.ID)
+			if !photo.ManagedExternally {
+				group.ReclaimableBytes += photo.FileSize
+			}
+		}
+		report.Groups = append(report.Groups, group)
+		report.ReclaimableBytes += group.ReclaimableBytes
+	}
+
+	// Maiores ganhos primeiro
+	sort.SliceStable(report.Groups, func(i, j int) bool {
+		return report.Groups[i].ReclaimableBytes > report.Groups[j].ReclaimableBytes
+	})
+	return report, nil
+}
+
+// Reclaim remove as cópias redundantes de cada grupo, mantendo a foto escolhida pela política.
+// Antes da remoção, a foto mantida é adicionada aos álbuns das cópias, para que nenhum álbum perca a foto.
+// Com dryRun=true apenas retorna o relatório do que seria feito; revise-o antes de executar.
+func (s *DuplicateService) Reclaim(ctx context.Context, opts DuplicateOptions, dryRun bool) (*DuplicateReport, error) {
+	report, err := s.FindDuplicates(ctx, opts)
+	if err != nil || dryRun {
+		return report, err
+	}
+	report.DryRun = false
+
+	for _, group := range report.Groups {
+		for _, id := range group.RemoveIDs {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			if err := s.removeDuplicate(ctx, group.KeepID, id); err != nil {
+				log.Printf("Erro ao remover a duplicata %d (mantida: %d): %v\n", id, group.KeepID, err)
+				report.Failed++
+				continue
+			}
+			report.Removed++
+		}
+	}
+	return report, nil
+}
+
+// PlanReclaim simula Reclaim e grava o plano de ação para revisão: cada cópia a apagar, com o arquivo, os
+// bytes liberados e a foto mantida no lugar dela. Nada é removido até a confirmação do plano
+// (AdminPlanService.Execute). Retorna o relatório da simulação com o plano gravado.
+func (s *DuplicateService) PlanReclaim(ctx context.Context, opts DuplicateOptions) (*DuplicateReport, *database.AdminPlan, error) {
+	report, err := s.FindDuplicates(ctx, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keepers := make(map[uint]uint)
+	var removeIDs []uint
+	for _, group := range report.Groups {
+		for _, id := range group.RemoveIDs {
+			keepers[id] = group.KeepID
+			removeIDs = append(removeIDs, id)
+		}
+	}
+	var photos []database.Photo
+	if len(removeIDs) > 0 {
+		err := s.DB.WithContext(ctx).Select("id", "stored_path", "file_size", "managed_externally").
+			Where("id IN ?", removeIDs).Order("id").Find(&photos).Error
+		if err != nil {
+			return nil, nil, fmt.Errorf("erro ao buscar as cópias a remover: %w", err)
+		}
+	}
+
+	actions := make([]database.AdminPlanAction, 0, len(photos))
+	for _, photo := range photos {
+		action := database.AdminPlanAction{
+			Action:      database.PlanActionDeletePhoto,
+			PhotoID:     photo.ID,
+			KeepPhotoID: keepers[photo.ID],
+			StoredPath:  photo.StoredPath,
+			FileSize:    photo.FileSize,
+		}
+		if !photo.ManagedExternally {
+			action.Bytes = photo.FileSize
+		}
+		actions = append(actions, action)
+	}
+	params := map[string]interface{}{"policy": opts.Policy, "threshold": opts.Threshold, "near": opts.IncludeNear}
+	plan, err := createPlan(s.DB.WithContext(ctx), database.PlanOperationDedupeReclaim, params, actions)
+	if err != nil {
+		return nil, nil, err
+	}
+	return report, plan, nil
+}
+
+// removeDuplicate transfere os álbuns da cópia para a foto mantida e remove a cópia.
+// Arquivos indexados no local são apenas retirados do índice, nunca apagados.
+func (s *DuplicateService) removeDuplicate(ctx context.Context, keepID, removeID uint) error {
+	var albumIDs []uint
+	if err := s.DB.WithContext(ctx).Model(&database.AlbumPhoto{}).Where("photo_id = ?", removeID).Pluck("album_id", &albumIDs).Error; err != nil {
+		return fmt.Errorf("erro ao buscar álbuns da foto: %w", err)
+	}
+	for _, albumID := range albumIDs {
+		if _, err := s.AlbumService.AddPhotosToAlbum(albumID, []uint{keepID}); err != nil {
+			return fmt.Errorf("não foi possível transferir o álbum %d para a foto mantida: %w", albumID, err)
+		}
+	}
+	return s.PhotoService.DeletePhoto(ctx, removeID, false)
+}
+
+// updateFingerprints calcula as impressões digitais das fotos que ainda não as têm ou cujo arquivo mudou.
+// Retorna quantas foram calculadas e quantas não puderam ser decodificadas.
+func (s *DuplicateService) updateFingerprints(ctx context.Context) (computed, unreadable int, err error) {
+	var photos []database.Photo
+	result := s.DB.WithContext(ctx).
+		Select("id", "stored_path", "mime_type", "hash", "pixel_hash").
+		Where("quarantined = ? AND (fingerprint_of IS NULL OR fingerprint_of <> hash)", false).
+		Find(&photos)
+	if result.Error != nil {
+		return 0, 0, fmt.Errorf("erro ao buscar fotos sem impressão digital: %w", result.Error)
+	}
+
+	var maxPixels int64
+	if s.PhotoService.Thumbnails != nil {
+		maxPixels = s.PhotoService.Thumbnails.MaxPixels
+	}
+
+	for _, photo := range photos {
+		if err := ctx.Err(); err != nil {
+			return computed, unreadable, err
+		}
+
+		var fingerprint thumbnail.Fingerprint
+		if !video.IsVideo(photo.MimeType) {
+			img, _, err := thumbnail.Decode(photo.StoredPath, maxPixels)
+			if err != nil {
+				log.Printf("Aviso: impressão digital da foto %d não calculada: %v\n", photo.ID, err)
+			} else {
+				fingerprint = thumbnail.ComputeFingerprint(img)
+			}
+		}
+		if fingerprint.PixelHash == "" {
+			unreadable++
+		}
+
+		// Fotos ilegíveis também são marcadas, para não serem decodificadas de novo até o arquivo mudar
+		update := s.DB.WithContext(ctx).Model(&database.Photo{}).Where("id = ?", photo.ID).UpdateColumns(map[string]interface{}{
+			"pixel_hash":      fingerprint.PixelHash,
+			"perceptual_hash": fingerprint.PerceptualHash,
+			"fingerprint_of":  photo.Hash,
+		})
+		if update.Error != nil {
+			return computed, unreadable, fmt.Errorf("não foi possível gravar a impressão digital da foto %d: %w", photo.ID, update.Error)
+		}
+		computed++
+	}
+
+	// Ilegíveis de execuções anteriores continuam fora da comparação visual
+	var previouslyUnreadable int64
+	if err := s.DB.WithContext(ctx).Model(&database.Photo{}).
+		Where("quarantined = ? AND fingerprint_of = hash AND pixel_hash = ''", false).
+		Count(&previouslyUnreadable).Error; err != nil {
+		return computed, unreadable, fmt.Errorf("erro ao contar fotos ilegíveis: %w", err)
+	}
+	return computed, int(previouslyUnreadable), nil
+}
+
+// groupDuplicates une as fotos idênticas (mesmo PixelHash) e, se habilitado, as quase idênticas (hashes
+// perceptuais próximos, ou mesmo nome base e data EXIF, como pares RAW+JPEG). Retorna apenas grupos com
+// mais de uma foto, cada um ordenado por ID.
+func groupDuplicates(photos []database.Photo, opts DuplicateOptions) [][]database.Photo {
+	parent := make([]int, len(photos))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(a, b int) {
+		if ra, rb := find(a), find(b); ra != rb {
+			parent[rb] = ra
+		}
+	}
+
+	byPixels := make(map[string]int)
+	for i, photo := range photos {
+		if photo.PixelHash == "" {
+			continue
+		}
+		if j, ok := byPixels[photo.PixelHash]; ok {
+			union(j, i)
+		} else {
+			byPixels[photo.PixelHash] = i
+		}
+	}
+
+	if opts.IncludeNear {
+		type hashed struct {
+			index int
+			hash  uint64
+		}
+		var perceptual []hashed
+		for i, photo := range photos {
+			if hash, err := strconv.ParseUint(photo.PerceptualHash, 16, 64); err == nil {
+				perceptual = append(perceptual, hashed{i, hash})
+			}
+		}
+		for a := 0; a < len(perceptual); a++ {
+			for b := a + 1; b < len(perceptual); b++ {
+				if bits.OnesCount64(perceptual[a].hash^perceptual[b].hash) <= opts.Threshold {
+					union(perceptual[a].index, perceptual[b].index)
+				}
+			}
+		}
+
+		byCapture := make(map[string]int)
+		for i, photo := range photos {
+			if photo.ExifDate == nil {
+				continue
+			}
+			stem := strings.ToLower(strings.TrimSuffix(photo.Filename, filepath.Ext(photo.Filename)))
+			key := stem + "|" + photo.ExifDate.UTC().Format("20060102150405")
+			if j, ok := byCapture[key]; ok {
+				union(j, i)
+			} else {
+				byCapture[key] = i
+			}
+		}
+	}
+
+	members := make(map[int][]database.Photo)
+	var roots []int
+	for i := range photos {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], photos[i])
+	}
+
+	var groups [][]database.Photo
+	for _, root := range roots {
+		if len(members[root]) > 1 {
+			groups = append(groups, members[root])
+		}
+	}
+	return groups
+}
+
+// chooseKeeper escolhe a foto mantida no grupo conforme a política. Empates ficam com o menor ID.
+func chooseKeeper(photos []database.Photo, policy string) database.Photo {
+	better := func(a, b database.Photo) bool {
+		switch policy {
+		case DedupeKeepOldest:
+			if ta, tb := photoTakenAt(a), photoTakenAt(b); !ta.Equal(tb) {
+				return ta.Before(tb)
+			}
+		case DedupeKeepRaw:
+			if ra, rb := isRawPhoto(a), isRawPhoto(b); ra != rb {
+				return ra
+			}
+			fallthrough
+		case DedupeKeepLargest:
+			if pa, pb := a.Width*a.Height, b.Width*b.Height; pa != pb {
+				return pa > pb
+			}
+			if a.FileSize != b.FileSize {
+				return a.FileSize > b.FileSize
+			}
+		}
+		return a.ID < b.ID
+	}
+
+	keep := photos[0]
+	for _, photo := range photos[1:] {
+		if better(photo, keep) {
+			keep = photo
+		}
+	}
+	return keep
+}
+
+// isRawPhoto indica se a foto é um arquivo RAW de câmera.
+func isRawPhoto(photo database.Photo) bool {
+	return rawExtensions[strings.ToLower(filepath.Ext(photo.Filename))] ||
+		strings.Contains(photo.MimeType, "raw") || photo.MimeType == "image/x-adobe-dng"
+}
+
+// photoTakenAt retorna a data da foto (EXIF, ou a data de upload na falta dela).
+func photoTakenAt(photo database.Photo) time.Time {
+	if photo.ExifDate != nil {
+		return *photo.ExifDate
+	}
+	return photo.UploadDate
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/exif"
+	"photo-manager/internal/i18n"
+	"slices"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Políticas da reconciliação: qual lado prevalece quando o arquivo e o banco divergem.
+const (
+	ReconcilePolicyFile  = "file"  // O arquivo prevalece sempre que tiver o campo
+	ReconcilePolicyFill  = "fill"  // O arquivo só preenche campos vazios no banco
+	ReconcilePolicyNewer = "newer" // O arquivo prevalece se foi modificado depois da última alteração da foto no banco
+)
+
+// ReconcileFields são os campos comparados na reconciliação, na ordem do relatório.
+var ReconcileFields = []string{"exif_date", "latitude", "longitude", "camera_make", "camera_model", "lens_model", "focal_length", "iso"}
+
+// Motivos para manter o valor do banco em uma divergência.
+const (
+	ReconcileKeptFileEmpty = "file_empty" // O arquivo não tem o campo (ex: data definida pela API)
+	ReconcileKeptFilled    = "filled"     // Política fill: o banco já tem um valor
+	ReconcileKeptOlder     = "older"      // Política newer: o arquivo é mais antigo que a última alteração
+	ReconcileKeptLocked    = "locked"     // Foto bloqueada
+)
+
+// metadataChangeSource identifica as alterações feitas pela reconciliação no histórico.
+const metadataChangeSource = "exif_reconcile"
+
+// reconcilePreviewLimit é a quantidade máxima de fotos listadas no relatório; os totais são sempre completos.
+const reconcilePreviewLimit = 500
+
+// ExifReconcileService relê o EXIF dos arquivos armazenados e compara com os metadados do banco, para
+// quando as datas (ou outros campos) foram corrigidas em outro programa diretamente nos arquivos. As
+// alterações aplicadas ficam registradas em MetadataChange.
+type ExifReconcileService struct {
+	DB     *gorm.DB
+	Events *events.Bus
+}
+
+// NewExifReconcileService cria uma nova instância de ExifReconcileService.
+func NewExifReconcileService(db *gorm.DB, bus *events.Bus) *ExifReconcileService {
+	return &ExifReconcileService{DB: db, Events: bus}
+}
+
+// ReconcileOptions descreve uma reconciliação. Fields vazio compara todos os ReconcileFields; PhotoIDs
+// vazio percorre a biblioteca inteira (exceto vídeos).
+type ReconcileOptions struct {
+	Policy   string
+	Fields   []string
+	PhotoIDs []uint
+	DryRun   bool
+}
+
+// FieldDifference é um campo divergente entre o banco e o arquivo.
+type FieldDifference struct {
+	Field    string
+	Database string // Valor no banco, formatado (vazio se nulo)
+	File     string // Valor no arquivo, formatado (vazio se ausente)
+	Apply    bool   // O valor do arquivo prevalece (ou prevaleceria, na simulação)
+	Kept     string // Motivo de manter o valor do banco, se Apply for false
+}
+
+// PhotoDifferences são as divergências de uma foto.
+type PhotoDifferences struct {
+	PhotoID     uint
+	Filename    string
+	Differences []FieldDifference
+}
+
+// ReconcileReport resume uma reconciliação. Sem DryRun, as divergências com Apply foram gravadas.
+type ReconcileReport struct {
+	DryRun        bool
+	Policy        string
+	Scanned       int    // Fotos comparadas
+	Differing     int    // Fotos com alguma divergência
+	Updated       int    // Fotos alteradas (ou a alterar)
+	FieldsChanged int    // Campos alterados (ou a alterar)
+	Missing       []uint // Fotos cujo arquivo não foi encontrado
+	Unreadable    []uint // Fotos cujo EXIF não pôde ser lido
+	Photos        []PhotoDifferences
+	Truncated     bool
+}
+
+// reconcileValue é o valor de um campo, formatado para comparação e para o histórico, e o valor gravado.
+type reconcileValue struct {
+	text  string
+	value interface{}
+}
+
+// Reconcile compara o EXIF dos arquivos com o banco e, sem DryRun, aplica as divergências em que o arquivo
+// prevalece pela política, foto a foto (cada foto em uma transação, com o registro no histórico).
+func (s *ExifReconcileService) Reconcile(ctx context.Context, opts ReconcileOptions) (*ReconcileReport, error) {
+	if opts.Policy == "" {
+		opts.Policy = ReconcilePolicyFile
+	}
+	if !slices.Contains([]string{ReconcilePolicyFile, ReconcilePolicyFill, ReconcilePolicyNewer}, opts.Policy) {
+		return nil, i18n.NewError(i18n.CodeReconcileInvalid, fmt.Sprintf("policy '%s' (%s, %s, %s)", opts.Policy, ReconcilePolicyFile, ReconcilePolicyFill, ReconcilePolicyNewer))
+	}
+	fields := opts.Fields
+	if len(fields) == 0 {
+		fields = ReconcileFields
+	}
+	for _, field := range fields {
+		if !slices.Contains(ReconcileFields, field) {
+			return nil, i18n.NewError(i18n.CodeReconcileInvalid, fmt.Sprintf("field '%s'", field))
+		}
+	}
+
+	report := &ReconcileReport{DryRun: opts.DryRun, Policy: opts.Policy, Missing: []uint{}, Unreadable: []uint{}, Photos: []PhotoDifferences{}}
+	query := s.DB.WithContext(ctx).Model(&database.Photo{}).Where("mime_type NOT LIKE ?", "video/%")
+	if len(opts.PhotoIDs) > 0 {
+		query = query.Where("id IN ?", opts.PhotoIDs)
+	}
+
+	var photos []database.Photo
+	err := query.FindInBatches(&photos, 200, func(tx *gorm.DB, batch int) error {
+		for i := range photos {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := s.reconcilePhoto(ctx, &photos[i], fields, opts, report); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao reconciliar os metadados: %w", err)
+	}
+	return report, nil
+}
+
+// reconcilePhoto compara uma foto com o seu arquivo e aplica as divergências que a política manda aplicar.
+func (s *ExifReconcileService) reconcilePhoto(ctx context.Context, photo *database.Photo, fields []string, opts ReconcileOptions, report *ReconcileReport) error {
+	path := photo.StoredPath
+	if photo.OriginalPath != "" {
+		if _, err := os.Stat(photo.OriginalPath); err == nil {
+			path = photo.OriginalPath // O original completo tem o EXIF intacto
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		report.Missing = append(report.Missing, photo.ID)
+		return nil
+	}
+	report.Scanned++
+	exifData, err := exif.ExtractExifData(path)
+	if err != nil {
+		report.Unreadable = append(report.Unreadable, photo.ID)
+		return nil
+	}
+	if exifData == nil {
+		exifData = &exif.ExifData{}
+	}
+
+	current, fromFile := photoReconcileValues(photo), exifReconcileValues(exifData)
+	entry := PhotoDifferences{PhotoID: photo.ID, Filename: photo.Filename}
+	updates := map[string]interface{}{}
+	for _, field := range fields {
+		dbValue, fileValue := current[field], fromFile[field]
+		if dbValue.text == fileValue.text {
+			continue
+		}
+		difference := FieldDifference{Field: field, Database: dbValue.text, File: fileValue.text}
+		switch {
+		case fileValue.text == "":
+			difference.Kept = ReconcileKeptFileEmpty
+		case photo.Locked:
+			difference.Kept = ReconcileKeptLocked
+		case opts.Policy == ReconcilePolicyFill && dbValue.text != "":
+			difference.Kept = ReconcileKeptFilled
+		case opts.Policy == ReconcilePolicyNewer && !info.ModTime().After(photo.UpdatedAt):
+			difference.Kept = ReconcileKeptOlder
+		default:
+			difference.Apply = true
+			updates[field] = fileValue.value
+		}
+		entry.Differences = append(entry.Differences, difference)
+	}
+	if len(entry.Differences) == 0 {
+		return nil
+	}
+
+	report.Differing++
+	if len(updates) > 0 {
+		report.Updated++
+		report.FieldsChanged += len(updates)
+	}
+	if len(report.Photos) < reconcilePreviewLimit {
+		report.Photos = append(report.Photos, entry)
+	} else {
+		report.Truncated = true
+	}
+	if opts.DryRun || len(updates) == 0 {
+		return nil
+	}
+	if _, ok := updates["exif_date"]; ok {
+		updates["time_zone_offset"] = exifData.TimeZoneOffset // O fuso acompanha a data adotada do arquivo
+	}
+	return s.apply(ctx, photo, entry, updates, opts.Policy)
+}
+
+// apply grava os campos da foto e o histórico das alterações em uma transação.
+func (s *ExifReconcileService) apply(ctx context.Context, photo *database.Photo, entry PhotoDifferences, updates map[string]interface{}, policy string) error {
+	now := time.Now()
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(photo).Updates(updates).Error; err != nil {
+			return fmt.Errorf("não foi possível atualizar a foto %d: %w", photo.ID, err)
+		}
+		for _, difference := range entry.Differences {
+			if !difference.Apply {
+				continue
+			}
+			change := database.MetadataChange{
+				PhotoID:   photo.ID,
+				Field:     difference.Field,
+				OldValue:  difference.Database,
+				NewValue:  difference.File,
+				Source:    metadataChangeSource,
+				Policy:    policy,
+				AppliedAt: now,
+			}
+			if err := tx.Create(&change).Error; err != nil {
+				return fmt.Errorf("não foi possível registrar a alteração da foto %d: %w", photo.ID, err)
+			}
+		}
+		if _, ok := updates["exif_date"]; ok {
+			return refreshPhotoAlbumDates(tx, []uint{photo.ID})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": photo.ID})
+	return nil
+}
+
+// ListChanges retorna o histórico de alterações de metadados, das mais recentes para as mais antigas.
+// photoID 0 lista as de todas as fotos.
+func (s *ExifReconcileService) ListChanges(photoID uint, limit int) ([]database.MetadataChange, error) {
+	query := s.DB.Order("id DESC").Limit(limit)
+	if photoID > 0 {
+		query = query.Where("photo_id = ?", photoID)
+	}
+	changes := []database.MetadataChange{}
+	if err := query.Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar o histórico de alterações: %w", err)
+	}
+	return changes, nil
+}
+
+// photoReconcileValues formata os campos comparáveis da foto.
+func photoReconcileValues(photo *database.Photo) map[string]reconcileValue {
+	return map[string]reconcileValue{
+		"exif_date":    timeValue(photo.ExifDate),
+		"latitude":     floatValue(photo.Latitude),
+		"longitude":    floatValue(photo.Longitude),
+		"camera_make":  {photo.CameraMake, photo.CameraMake},
+		"camera_model": {photo.CameraModel, photo.CameraModel},
+		"lens_model":   {photo.LensModel, photo.LensModel},
+		"focal_length": floatValue(photo.FocalLength),
+		"iso":          intValue(photo.ISO),
+	}
+}
+
+// exifReconcileValues formata os campos lidos do arquivo.
+func exifReconcileValues(data *exif.ExifData) map[string]reconcileValue {
+	return map[string]reconcileValue{
+		"exif_date":    timeValue(data.DateTime),
+		"latitude":     floatValue(data.Latitude),
+		"longitude":    floatValue(data.Longitude),
+		"camera_make":  {data.CameraMake, data.CameraMake},
+		"camera_model": {data.CameraModel, data.CameraModel},
+		"lens_model":   {data.LensModel, data.LensModel},
+		"focal_length": floatValue(data.FocalLength),
+		"iso":          intValue(data.ISO),
+	}
+}
+
+// timeValue formata uma data com precisão de segundos (a do EXIF), no instante absoluto: a mesma hora
+// gravada com outro fuso não é uma divergência.
+func timeValue(t *time.Time) reconcileValue {
+	if t == nil {
+		return reconcileValue{"", nil}
+	}
+	return reconcileValue{t.UTC().Format(time.RFC3339), *t}
+}
+
+// floatValue formata um número com 6 casas decimais (cerca de 10 cm nas coordenadas), ignorando
+// diferenças de arredondamento.
+func floatValue(f *float64) reconcileValue {
+	if f == nil || math.IsNaN(*f) {
+		return reconcileValue{"", nil}
+	}
+	return reconcileValue{strconv.FormatFloat(*f, 'f', 6, 64), *f}
+}
+
+// intValue formata um inteiro.
+func intValue(n *int) reconcileValue {
+	if n == nil {
+		return reconcileValue{"", nil}
+	}
+	return reconcileValue{strconv.Itoa(*n), *n}
+}
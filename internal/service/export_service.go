@@ -0,0 +1,524 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExportManifestVersion é a versão do formato do manifesto (metadata.json) das exportações.
+const ExportManifestVersion = 2
+
+// exportProgressInterval é a quantidade de fotos entre as gravações do progresso da exportação.
+const exportProgressInterval = 50
+
+// ExportService gera exportações completas da biblioteca: um ZIP com os originais e um manifesto JSON
+// com álbuns, tags, compartilhamentos e os metadados de cada foto, legível por outras ferramentas.
+type ExportService struct {
+	DB     *gorm.DB
+	Dir    string      // Diretório dos arquivos gerados
+	Events *events.Bus // Publica export.finished ao terminar
+
+	mu      sync.Mutex
+	running map[uint]bool // Jobs em execução neste processo, evita execuções duplicadas
+}
+
+// NewExportService cria uma nova instância de ExportService.
+func NewExportService(db *gorm.DB, dir string, bus *events.Bus) *ExportService {
+	return &ExportService{
+		DB:      db,
+		Dir:     dir,
+		Events:  bus,
+		running: make(map[uint]bool),
+	}
+}
+
+// ExportManifest é o conteúdo de metadata.json, na raiz do ZIP exportado.
+type ExportManifest struct {
+	Version    int                 `json:"version"`
+	ExportedAt time.Time           `json:"exported_at"`
+	Photos     []ExportPhoto       `json:"photos"`
+	Albums     []ExportAlbum       `json:"albums"`
+	Tags       []ExportTag         `json:"tags"`
+	Shares     []ExportShare       `json:"shares"`
+	Sources    []ExportSourceAlbum `json:"source_albums"`
+}
+
+// ExportPhoto são os metadados de uma foto no manifesto. File é o caminho do arquivo dentro do ZIP
+// (vazio se o arquivo não pôde ser lido); Renamed indica que o nome difere do original (Filename) porque
+// outra foto do mesmo mês tem o mesmo nome.
+type ExportPhoto struct {
+	ID           uint       `json:"id"`
+	PublicID     string     `json:"public_id"` // Identificador público, a preservar numa reimportação
+	File         string     `json:"file"`
+	Filename     string     `json:"filename"`
+	Hash         string     `json:"hash"`
+	FileSize     int64      `json:"file_size"`
+	MimeType     string     `json:"mime_type"`
+	Width        int        `json:"width"`
+	Height       int        `json:"height"`
+	TakenAt      *time.Time `json:"taken_at,omitempty"`
+	UploadedAt   time.Time  `json:"uploaded_at"`
+	Description  string     `json:"description,omitempty"`
+	Tags         []string   `json:"tags"`
+	AlbumIDs     []uint     `json:"album_ids"`
+	Favorite     bool       `json:"favorite"`
+	Hidden       bool       `json:"hidden"`
+	HideTimeline bool       `json:"hide_from_timeline,omitempty"`
+	Locked       bool       `json:"locked"`
+	Latitude     *float64   `json:"latitude,omitempty"`
+	Longitude    *float64   `json:"longitude,omitempty"`
+	CameraMake   string     `json:"camera_make,omitempty"`
+	CameraModel  string     `json:"camera_model,omitempty"`
+	LensModel    string     `json:"lens_model,omitempty"`
+	FocalLength  *float64   `json:"focal_length,omitempty"`
+	ISO          *int       `json:"iso,omitempty"`
+	Source       string     `json:"source,omitempty"`
+	SourceDevice string     `json:"source_device,omitempty"`
+	Downscaled   bool       `json:"downscaled"` // true se o ZIP contém a cópia reduzida (o original não foi guardado)
+	Renamed      bool       `json:"renamed,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// ExportAlbum é um álbum no manifesto, com as fotos na ordem em que foram incluídas.
+type ExportAlbum struct {
+	ID          uint      `json:"id"`
+	PublicID    string    `json:"public_id"` // Identificador público, a preservar numa reimportação
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Pinned      bool      `json:"pinned"`
+	Locked      bool      `json:"locked"`
+	CreatedAt   time.Time `json:"created_at"`
+	PhotoIDs    []uint    `json:"photo_ids"`
+
+	Metadata map[string]string `json:"metadata,omitempty"` // Campos personalizados
+}
+
+// ExportTag é uma tag no manifesto.
+type ExportTag struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	PhotoCount int64  `json:"photo_count"`
+}
+
+// ExportShare é um link público de compartilhamento no manifesto.
+type ExportShare struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"` // Caminho relativo ao servidor
+	AlbumID   uint      `json:"album_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportSourceAlbum é um álbum padrão de canal de entrada ou dispositivo no manifesto.
+type ExportSourceAlbum struct {
+	Source  string `json:"source"`
+	Device  string `json:"device,omitempty"`
+	AlbumID uint   `json:"album_id"`
+}
+
+// StartExport inicia uma exportação em background. Uma exportação pendente ou em andamento é reaproveitada,
+// assim como a última concluída cujo arquivo ainda existe, a menos que force seja true.
+// created indica que um novo job foi criado.
+func (s *ExportService) StartExport(force bool) (job *database.ExportJob, created bool, err error) {
+	var latest database.ExportJob
+	found := s.DB.Order("id DESC").Limit(1).Find(&latest)
+	if found.Error != nil {
+		return nil, false, fmt.Errorf("erro ao buscar a última exportação: %w", found.Error)
+	}
+	if found.RowsAffected > 0 {
+		switch latest.Status {
+		case database.ExportStatusPending, database.ExportStatusRunning:
+			return &latest, false, nil
+		case database.ExportStatusCompleted:
+			if _, err := os.Stat(latest.ArchivePath); !force && latest.ArchivePath != "" && err == nil {
+				return &latest, false, nil
+			}
+		}
+	}
+
+	job = &database.ExportJob{Status: database.ExportStatusPending}
+	if result := s.DB.Create(job); result.Error != nil {
+		return nil, false, fmt.Errorf("não foi possível criar o job de exportação: %w", result.Error)
+	}
+
+	s.launch(job.ID)
+	return job, true, nil
+}
+
+// GetExportJob retorna o estado atual de um job de exportação.
+func (s *ExportService) GetExportJob(id uint) (*database.ExportJob, error) {
+	var job database.ExportJob
+	if result := s.DB.First(&job, id); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar job de exportação: %w", result.Error)
+	}
+	return &job, nil
+}
+
+// ResumePendingExports refaz as exportações que não terminaram (ex: interrompidas por um reinício do
+// servidor). O ZIP não pode ser continuado, então é gerado novamente desde o início.
+func (s *ExportService) ResumePendingExports() error {
+	var jobs []database.ExportJob
+	result := s.DB.Where("status IN ?", []string{database.ExportStatusPending, database.ExportStatusRunning}).Find(&jobs)
+	if result.Error != nil {
+		return fmt.Errorf("erro ao buscar exportações pendentes: %w", result.Error)
+	}
+
+	for _, job := range jobs {
+		log.Printf("Refazendo a exportação %d interrompida\n", job.ID)
+		s.launch(job.ID)
+	}
+	return nil
+}
+
+// launch executa o job em uma goroutine, garantindo que o mesmo job não rode duas vezes em paralelo.
+func (s *ExportService) launch(jobID uint) {
+	s.mu.Lock()
+	if s.running[jobID] {
+		s.mu.Unlock()
+		return
+	}
+	s.running[jobID] = true
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, jobID)
+			s.mu.Unlock()
+		}()
+
+		if err := s.runExport(jobID); err != nil {
+			log.Printf("Erro no job de exportação %d: %v\n", jobID, err)
+		}
+	}()
+}
+
+// runExport gera o ZIP em um arquivo temporário e, ao final, o renomeia e remove os arquivos das
+// exportações anteriores (só a mais recente é mantida em disco).
+func (s *ExportService) runExport(jobID uint) error {
+	job, err := s.GetExportJob(jobID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	job.Status = database.ExportStatusRunning
+	job.StartedAt = &now
+	job.ExportedPhotos, job.SkippedPhotos = 0, 0
+	if result := s.DB.Model(job).Updates(map[string]interface{}{
+		"status":          job.Status,
+		"started_at":      now,
+		"exported_photos": 0,
+		"skipped_photos":  0,
+	}); result.Error != nil {
+		return fmt.Errorf("não foi possível atualizar o status do job: %w", result.Error)
+	}
+
+	archivePath := filepath.Join(s.Dir, fmt.Sprintf("export-%d.zip", job.ID))
+	size, err := s.writeArchive(job, archivePath)
+	finishedAt := time.Now()
+	if err != nil {
+		s.DB.Model(job).Updates(map[string]interface{}{
+			"status":      database.ExportStatusFailed,
+			"last_error":  err.Error(),
+			"finished_at": finishedAt,
+		})
+		s.Events.Publish(events.TypeExportFinished, map[string]interface{}{
+			"job_id": job.ID,
+			"status": database.ExportStatusFailed,
+			"error":  err.Error(),
+		})
+		return err
+	}
+
+	if result := s.DB.Model(job).Updates(map[string]interface{}{
+		"status":          database.ExportStatusCompleted,
+		"archive_path":    archivePath,
+		"size_bytes":      size,
+		"exported_photos": job.ExportedPhotos,
+		"skipped_photos":  job.SkippedPhotos,
+		"last_error":      job.LastError,
+		"finished_at":     finishedAt,
+	}); result.Error != nil {
+		return fmt.Errorf("não foi possível finalizar o job de exportação: %w", result.Error)
+	}
+	s.removeOlderArchives(job.ID)
+
+	log.Printf("Exportação %d finalizada: %d foto(s) exportada(s), %d ignorada(s), %d bytes\n",
+		job.ID, job.ExportedPhotos, job.SkippedPhotos, size)
+	s.Events.Publish(events.TypeExportFinished, map[string]interface{}{
+		"job_id":   job.ID,
+		"status":   database.ExportStatusCompleted,
+		"exported": job.ExportedPhotos,
+		"skipped":  job.SkippedPhotos,
+		"size":     size,
+	})
+	return nil
+}
+
+// writeArchive grava o ZIP completo em archivePath, retornando o seu tamanho.
+func (s *ExportService) writeArchive(job *database.ExportJob, archivePath string) (int64, error) {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return 0, fmt.Errorf("não foi possível criar o diretório de exportação: %w", err)
+	}
+
+	manifest, photos, err := s.buildManifest()
+	if err != nil {
+		return 0, err
+	}
+	job.TotalPhotos = len(photos)
+	s.DB.Model(job).Update("total_photos", job.TotalPhotos)
+
+	tmpPath := archivePath + ".part"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, fmt.Errorf("não foi possível criar o arquivo de exportação: %w", err)
+	}
+	defer os.Remove(tmpPath) // Sem efeito após o rename
+
+	zw := zip.NewWriter(out)
+	names := newArchiveNamer()
+	for i := range photos {
+		entry := &manifest.Photos[i]
+		name, err := writeExportPhoto(zw, names, photos[i])
+		if err != nil {
+			// Ex: arquivo ausente em um volume desmontado; a foto fica registrada só no manifesto
+			entry.Error = err.Error()
+			job.SkippedPhotos++
+			job.LastError = fmt.Sprintf("foto %d: %v", photos[i].ID, err)
+			log.Printf("Exportação %d: arquivo da foto %d ignorado: %v\n", job.ID, photos[i].ID, err)
+		} else {
+			entry.File = name
+			entry.Renamed = path.Base(name) != filepath.Base(photos[i].Filename)
+			job.ExportedPhotos++
+		}
+
+		if (i+1)%exportProgressInterval == 0 {
+			s.DB.Model(job).Updates(map[string]interface{}{
+				"exported_photos": job.ExportedPhotos,
+				"skipped_photos":  job.SkippedPhotos,
+			})
+		}
+	}
+
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "metadata.json", Method: zip.Deflate, Modified: manifest.ExportedAt})
+	if err == nil {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(manifest)
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("erro ao gravar o arquivo de exportação: %w", err)
+	}
+
+	info, err := os.Stat(tmpPath)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao verificar o arquivo de exportação: %w", err)
+	}
+	if err := os.Rename(tmpPath, archivePath); err != nil {
+		return 0, fmt.Errorf("não foi possível finalizar o arquivo de exportação: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// buildManifest carrega as fotos, álbuns, tags e compartilhamentos da biblioteca. Os arquivos das fotos
+// ainda não foram gravados: os campos File e Error são preenchidos durante a escrita do ZIP.
+func (s *ExportService) buildManifest() (*ExportManifest, []database.Photo, error) {
+	manifest := &ExportManifest{Version: ExportManifestVersion, ExportedAt: time.Now()}
+
+	var photos []database.Photo
+	if err := s.DB.Order("id").Find(&photos).Error; err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar as fotos: %w", err)
+	}
+
+	var albums []database.Album
+	if err := s.DB.Order("id").Find(&albums).Error; err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar os álbuns: %w", err)
+	}
+	var members []database.AlbumPhoto
+	if err := s.DB.Order("id").Find(&members).Error; err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar as fotos dos álbuns: %w", err)
+	}
+	albumPhotos := make(map[uint][]uint)
+	photoAlbums := make(map[uint][]uint)
+	for _, m := range members {
+		albumPhotos[m.AlbumID] = append(albumPhotos[m.AlbumID], m.PhotoID)
+		photoAlbums[m.PhotoID] = append(photoAlbums[m.PhotoID], m.AlbumID)
+	}
+	for _, album := range albums {
+		manifest.Albums = append(manifest.Albums, ExportAlbum{
+			ID:          album.ID,
+			PublicID:    album.PublicID,
+			Name:        album.Name,
+			Description: album.Description,
+			Pinned:      album.Pinned,
+			Locked:      album.Locked,
+			CreatedAt:   album.CreatedAt,
+			PhotoIDs:    nonNilIDs(albumPhotos[album.ID]),
+			Metadata:    AlbumMetadata(album),
+		})
+	}
+
+	var tags []TagSummary
+	err := s.DB.Model(&database.Tag{}).
+		Select("tags.id, tags.name, COUNT(photo_tags.id) AS photo_count").
+		Joins("LEFT JOIN photo_tags ON photo_tags.tag_id = tags.id AND photo_tags.deleted_at IS NULL").
+		Group("tags.id").
+		Order("tags.name").
+		Scan(&tags).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar as tags: %w", err)
+	}
+	for _, tag := range tags {
+		manifest.Tags = append(manifest.Tags, ExportTag{ID: tag.ID, Name: tag.Name, PhotoCount: tag.PhotoCount})
+	}
+
+	var shares []database.ShareLink
+	if err := s.DB.Order("id").Find(&shares).Error; err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar os compartilhamentos: %w", err)
+	}
+	for _, share := range shares {
+		manifest.Shares = append(manifest.Shares, ExportShare{
+			Token:     share.Token,
+			URL:       "/s/" + share.Token,
+			AlbumID:   share.AlbumID,
+			CreatedAt: share.CreatedAt,
+		})
+	}
+
+	var sourceAlbums []database.SourceAlbum
+	if err := s.DB.Order("id").Find(&sourceAlbums).Error; err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar os álbuns padrão das origens: %w", err)
+	}
+	for _, sa := range sourceAlbums {
+		manifest.Sources = append(manifest.Sources, ExportSourceAlbum{Source: sa.Source, Device: sa.Device, AlbumID: sa.AlbumID})
+	}
+
+	for _, photo := range photos {
+		tags := []string{}
+		if photo.Tags != "" {
+			tags = strings.Split(photo.Tags, ",")
+		}
+		manifest.Photos = append(manifest.Photos, ExportPhoto{
+			ID:           photo.ID,
+			PublicID:     photo.PublicID,
+			Filename:     photo.Filename,
+			Hash:         photo.Hash,
+			FileSize:     photo.FileSize,
+			MimeType:     photo.MimeType,
+			Width:        photo.Width,
+			Height:       photo.Height,
+			TakenAt:      photo.ExifDate,
+			UploadedAt:   photo.UploadDate,
+			Description:  photo.Description,
+			Tags:         tags,
+			AlbumIDs:     nonNilIDs(photoAlbums[photo.ID]),
+			Favorite:     photo.Favorite,
+			Hidden:       photo.Hidden,
+			HideTimeline: photo.HideFromTimeline,
+			Locked:       photo.Locked,
+			Latitude:     photo.Latitude,
+			Longitude:    photo.Longitude,
+			CameraMake:   photo.CameraMake,
+			CameraModel:  photo.CameraModel,
+			LensModel:    photo.LensModel,
+			FocalLength:  photo.FocalLength,
+			ISO:          photo.ISO,
+			Source:       photo.Source,
+			SourceDevice: photo.SourceDevice,
+			Downscaled:   photo.Downscaled && !fileExists(photo.OriginalPath),
+		})
+	}
+	return manifest, photos, nil
+}
+
+// writeExportPhoto copia o arquivo da foto para o ZIP em photos/<ano>/<mês>/<nome>, usando o original
+// em resolução completa quando a foto foi reduzida na ingestão. Nomes repetidos no mesmo mês são
+// desambiguados por names. Retorna o caminho dentro do ZIP.
+func writeExportPhoto(zw *zip.Writer, names *archiveNamer, photo database.Photo) (string, error) {
+	source := photo.StoredPath
+	if photo.Downscaled && fileExists(photo.OriginalPath) {
+		source = photo.OriginalPath
+	}
+
+	f, err := os.Open(source)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	date := photo.UploadDate
+	if photo.ExifDate != nil {
+		date = *photo.ExifDate
+	}
+	name, _ := names.Name(path.Join("photos", date.Format("2006"), date.Format("01")), photo)
+
+	// Fotos e vídeos já são comprimidos: armazenar sem compressão poupa CPU sem aumentar o arquivo
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: info.ModTime()})
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(w, f); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// removeOlderArchives apaga os arquivos das exportações anteriores a keepID.
+func (s *ExportService) removeOlderArchives(keepID uint) {
+	var jobs []database.ExportJob
+	if err := s.DB.Where("id < ? AND archive_path <> ''", keepID).Find(&jobs).Error; err != nil {
+		log.Printf("Aviso: não foi possível listar exportações anteriores: %v\n", err)
+		return
+	}
+	for _, job := range jobs {
+		if err := os.Remove(job.ArchivePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Aviso: não foi possível remover a exportação %d: %v\n", job.ID, err)
+			continue
+		}
+		s.DB.Model(&job).Update("archive_path", "")
+	}
+}
+
+// fileExists indica se path é um arquivo existente (false para um caminho vazio).
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// nonNilIDs garante que listas vazias sejam serializadas como [] em vez de null.
+func nonNilIDs(ids []uint) []uint {
+	if ids == nil {
+		return []uint{}
+	}
+	return ids
+}
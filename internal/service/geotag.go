@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/geotrack"
+	"photo-manager/internal/i18n"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// geotagPreviewLimit é a quantidade máxima de fotos listadas no relatório; os totais são sempre completos.
+const geotagPreviewLimit = 500
+
+// Motivos para não geolocalizar uma foto pela trilha.
+const (
+	GeotagSkippedLocked      = "locked"       // Foto bloqueada
+	GeotagSkippedNoDate      = "no_date"      // Sem data EXIF (ou com data aproximada): não há horário para correlacionar
+	GeotagSkippedHasLocation = "has_location" // A foto já tem localização e a substituição não foi pedida
+	GeotagSkippedOutOfTrack  = "out_of_track" // Horário fora da trilha, ou longe demais de qualquer ponto
+)
+
+// ErrCameraZoneInvalid indica um fuso da câmera desconhecido.
+var ErrCameraZoneInvalid = i18n.NewError(i18n.CodeGeotagTimeZoneInvalid)
+
+// cameraOffsetPattern reconhece um fuso no formato "+02:00" / "-03:00".
+var cameraOffsetPattern = regexp.MustCompile(`^([+-])(\d{2}):(\d{2})$`)
+
+// ParseCameraZone interpreta o fuso do relógio da câmera: um nome IANA (ex: Europe/Lisbon) ou um deslocamento
+// fixo ("+02:00"). O nome vazio retorna nil (a data EXIF é usada como foi gravada).
+func ParseCameraZone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil
+	}
+	if match := cameraOffsetPattern.FindStringSubmatch(name); match != nil {
+		hours, _ := strconv.Atoi(match[2])
+		minutes, _ := strconv.Atoi(match[3])
+		offset := hours*60 + minutes
+		if offset > 14*60 || minutes >= 60 {
+			return nil, ErrCameraZoneInvalid
+		}
+		if match[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(name, offset*60), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrCameraZoneInvalid
+	}
+	return loc, nil
+}
+
+// GeotagOptions ajusta a correlação entre os horários das fotos e a trilha.
+type GeotagOptions struct {
+	// Fuso em que o relógio da câmera estava acertado (nil = a data EXIF como foi gravada: o instante correto
+	// para fotos com fuso conhecido, e a hora da câmera no fuso do servidor para as demais)
+	CameraZone  *time.Location
+	ClockOffset time.Duration // Correção do relógio da câmera, somada ao horário das fotos (ex: câmera 2 min atrasada, +2m)
+	MaxGap      time.Duration // Maior intervalo entre pontos para interpolar, e maior distância até o ponto mais próximo
+	Overwrite   bool          // Substitui a localização das fotos que já têm uma
+	DryRun      bool
+}
+
+// GeotagMatch é o resultado da correlação de uma foto com a trilha.
+type GeotagMatch struct {
+	PhotoID     uint
+	CaptureTime *time.Time // Instante da foto usado na correlação, em UTC (nil se a foto não tem data)
+	Latitude    *float64   // Posição encontrada na trilha (nil se nenhuma)
+	Longitude   *float64
+	Skipped     string // Motivo de a foto não ser geolocalizada (vazio se aplicável)
+}
+
+// GeotagReport resume a geolocalização por trilha. Sem DryRun, as fotos sem Skipped foram atualizadas.
+type GeotagReport struct {
+	DryRun      bool
+	TrackPoints int
+	TrackStart  time.Time
+	TrackEnd    time.Time
+	Photos      []GeotagMatch
+	Matched     int // Fotos geolocalizadas (ou a geolocalizar), além das listadas
+	Skipped     int
+	Truncated   bool
+}
+
+// GeotagFromTrack estima a localização das fotos informadas pela trilha GPS (GPX ou KML), correlacionando o
+// horário de cada foto com os pontos da trilha. Com DryRun nada é gravado e o relatório serve de prévia.
+// A localização é gravada apenas na biblioteca: os arquivos não são alterados.
+func (s *PhotoService) GeotagFromTrack(ctx context.Context, track *geotrack.Track, ids []uint, opts GeotagOptions) (*GeotagReport, error) {
+	ids = uniqueIDs(ids)
+	db := s.DB.WithContext(ctx)
+	var photos []database.Photo
+	err := db.Select("id", "exif_date", "time_zone_offset", "circa_date", "latitude", "longitude").
+		Where("id IN ?", ids).Order("id").Find(&photos).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar fotos: %w", err)
+	}
+	if len(photos) != len(ids) {
+		return nil, i18n.NewError(i18n.CodePhotosNotFound)
+	}
+	locked, err := lockedPhotoIDs(db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &GeotagReport{
+		DryRun:      opts.DryRun,
+		TrackPoints: len(track.Points),
+		TrackStart:  track.Start(),
+		TrackEnd:    track.End(),
+	}
+	var matches []GeotagMatch
+	for _, photo := range photos {
+		match := GeotagMatch{PhotoID: photo.ID}
+		if photo.ExifDate != nil && photo.CircaDate == nil {
+			taken := geotagCaptureTime(photo, opts.CameraZone).Add(opts.ClockOffset)
+			match.CaptureTime = &taken
+			if point, ok := track.Locate(taken, opts.MaxGap); ok {
+				match.Latitude, match.Longitude = &point.Latitude, &point.Longitude
+			}
+		}
+
+		switch {
+		case locked[photo.ID]:
+			match.Skipped = GeotagSkippedLocked
+		case match.CaptureTime == nil:
+			match.Skipped = GeotagSkippedNoDate
+		case photo.Latitude != nil && !opts.Overwrite:
+			match.Skipped = GeotagSkippedHasLocation
+		case match.Latitude == nil:
+			match.Skipped = GeotagSkippedOutOfTrack
+		}
+		if match.Skipped != "" {
+			report.Skipped++
+		} else {
+			report.Matched++
+		}
+		matches = append(matches, match)
+	}
+	report.Photos = matches
+	if len(matches) > geotagPreviewLimit {
+		report.Photos, report.Truncated = matches[:geotagPreviewLimit], true
+	}
+	if opts.DryRun || report.Matched == 0 {
+		return report, nil
+	}
+
+	var updated []uint
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, match := range matches {
+			if match.Skipped != "" {
+				continue
+			}
+			err := tx.Model(&database.Photo{}).Where("id = ?", match.PhotoID).
+				Updates(map[string]interface{}{"latitude": *match.Latitude, "longitude": *match.Longitude}).Error
+			if err != nil {
+				return fmt.Errorf("não foi possível atualizar a localização da foto %d: %w", match.PhotoID, err)
+			}
+			updated = append(updated, match.PhotoID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range updated {
+		s.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": id})
+	}
+	return report, nil
+}
+
+// geotagCaptureTime retorna o instante da foto para a correlação. Com o fuso da câmera informado, a hora
+// marcada pela câmera (a data EXIF na hora local da captura) é situada nesse fuso.
+func geotagCaptureTime(photo database.Photo, cameraZone *time.Location) time.Time {
+	taken := *photo.ExifDate
+	if cameraZone == nil {
+		return taken.UTC()
+	}
+	// Sem fuso conhecido, a hora da câmera foi lida no fuso do servidor
+	wall := taken.In(time.Local)
+	if photo.TimeZoneOffset != nil {
+		wall = taken.In(time.FixedZone("", *photo.TimeZoneOffset*60))
+	}
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(),
+		wall.Nanosecond(), cameraZone).UTC()
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/validation"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ImportReportService grava e consulta os relatórios dos lotes de importação e de upload, para que o
+// resultado de cada arquivo ("487 de 500 importadas") possa ser explicado depois.
+type ImportReportService struct {
+	DB *gorm.DB
+}
+
+// NewImportReportService cria uma nova instância de ImportReportService.
+func NewImportReportService(db *gorm.DB) *ImportReportService {
+	return &ImportReportService{DB: db}
+}
+
+// ImportRecorder registra no relatório de um lote o resultado de cada arquivo. Os métodos são seguros para
+// chamar com o gravador nil (serviço de relatórios não configurado); falhas de gravação são apenas
+// registradas no log, nunca interrompem a importação.
+type ImportRecorder struct {
+	db       *gorm.DB
+	reportID uint
+}
+
+// outcomeColumns são as colunas de contagem de ImportReport para cada resultado.
+var outcomeColumns = map[string]string{
+	database.ImportOutcomeImported:  "imported",
+	database.ImportOutcomeUpdated:   "updated",
+	database.ImportOutcomeUnchanged: "unchanged",
+	database.ImportOutcomeDuplicate: "duplicates",
+	database.ImportOutcomeSkipped:   "skipped",
+	database.ImportOutcomeFailed:    "failed",
+}
+
+// Begin cria o relatório de um novo lote. source identifica o lote para o usuário (canal de entrada do
+// upload, nome do arquivo ZIP).
+func (s *ImportReportService) Begin(kind, source string) *ImportRecorder {
+	if s == nil {
+		return nil
+	}
+	report := database.ImportReport{Kind: kind, Source: source}
+	if err := s.DB.Create(&report).Error; err != nil {
+		log.Printf("Aviso: não foi possível criar o relatório de importação: %v\n", err)
+		return nil
+	}
+	return &ImportRecorder{db: s.DB, reportID: report.ID}
+}
+
+// ForJob retorna o gravador do relatório de um job de importação, criando-o na primeira execução. Um job
+// retomado após um reinício continua no mesmo relatório.
+func (s *ImportReportService) ForJob(job *database.ImportJob) *ImportRecorder {
+	if s == nil {
+		return nil
+	}
+	var report database.ImportReport
+	result := s.DB.Where("import_job_id = ?", job.ID).Limit(1).Find(&report)
+	if result.Error != nil {
+		log.Printf("Aviso: não foi possível buscar o relatório do job de importação %d: %v\n", job.ID, result.Error)
+		return nil
+	}
+	if result.RowsAffected == 0 {
+		report = database.ImportReport{Kind: database.ImportKindDirectory, Source: job.SourcePath, ImportJobID: &job.ID}
+		if err := s.DB.Create(&report).Error; err != nil {
+			log.Printf("Aviso: não foi possível criar o relatório do job de importação %d: %v\n", job.ID, err)
+			return nil
+		}
+	}
+	return &ImportRecorder{db: s.DB, reportID: report.ID}
+}
+
+// ReportID retorna o ID do relatório (0 com o gravador nil).
+func (r *ImportRecorder) ReportID() uint {
+	if r == nil {
+		return 0
+	}
+	return r.reportID
+}
+
+// Record registra o resultado da ingestão de um arquivo: importado (err nil), duplicata (photo é a foto
+// existente) ou falha.
+func (r *ImportRecorder) Record(name string, photo *database.Photo, err error) {
+	switch {
+	case err == nil:
+		r.add(database.ImportReportEntry{Name: name, Outcome: database.ImportOutcomeImported})
+	case errors.Is(err, ErrDuplicatePhoto):
+		entry := database.ImportReportEntry{Name: name, Outcome: database.ImportOutcomeDuplicate}
+		if photo != nil {
+			entry.PhotoID = &photo.ID
+		}
+		r.add(entry)
+	case errors.Is(err, errPhotoUnchanged):
+		r.add(database.ImportReportEntry{Name: name, Outcome: database.ImportOutcomeUnchanged})
+	default:
+		entry := database.ImportReportEntry{Name: name, Outcome: database.ImportOutcomeFailed, Code: i18n.Code(err), Error: err.Error()}
+		if validationErr, ok := validation.AsError(err); ok {
+			entry.Code, entry.Validator, entry.Error = validationErr.Code, validationErr.Validator, validationErr.Localize(i18n.DefaultLocale)
+		}
+		r.add(entry)
+	}
+}
+
+// Updated registra uma foto indexada no local cujo arquivo mudou.
+func (r *ImportRecorder) Updated(name string) {
+	r.add(database.ImportReportEntry{Name: name, Outcome: database.ImportOutcomeUpdated})
+}
+
+// Skip registra um arquivo ignorado (oculto, de sistema ou com extensão não suportada).
+func (r *ImportRecorder) Skip(name string) {
+	r.add(database.ImportReportEntry{
+		Name:    name,
+		Outcome: database.ImportOutcomeSkipped,
+		Code:    i18n.CodeImportFileSkipped,
+		Error:   i18n.Message(i18n.DefaultLocale, i18n.CodeImportFileSkipped),
+	})
+}
+
+// add soma o resultado ao relatório e, para os arquivos não importados, grava o detalhe.
+func (r *ImportRecorder) add(entry database.ImportReportEntry) {
+	if r == nil {
+		return
+	}
+	column := outcomeColumns[entry.Outcome]
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&database.ImportReport{}).Where("id = ?", r.reportID).UpdateColumns(map[string]interface{}{
+			"total": gorm.Expr("total + 1"),
+			column:  gorm.Expr(column + " + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		switch entry.Outcome {
+		case database.ImportOutcomeDuplicate, database.ImportOutcomeSkipped, database.ImportOutcomeFailed:
+			entry.ReportID = r.reportID
+			return tx.Create(&entry).Error
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Aviso: não foi possível registrar '%s' no relatório de importação %d: %v\n", entry.Name, r.reportID, err)
+	}
+}
+
+// Finish encerra o relatório. interruption é o motivo de o lote ter parado antes do fim (nil se completo).
+func (r *ImportRecorder) Finish(interruption error) {
+	if r == nil {
+		return
+	}
+	updates := map[string]interface{}{"finished_at": time.Now(), "interrupted": ""}
+	if interruption != nil {
+		updates["interrupted"] = interruption.Error()
+	}
+	if err := r.db.Model(&database.ImportReport{}).Where("id = ?", r.reportID).UpdateColumns(updates).Error; err != nil {
+		log.Printf("Aviso: não foi possível encerrar o relatório de importação %d: %v\n", r.reportID, err)
+	}
+}
+
+// ImportReportFilter são os critérios da listagem de relatórios.
+type ImportReportFilter struct {
+	Kind   string // Tipo do lote (vazio = todos)
+	Limit  int
+	Offset int
+}
+
+// ListReports retorna os relatórios mais recentes primeiro, com o total para paginação.
+func (s *ImportReportService) ListReports(filter ImportReportFilter) ([]database.ImportReport, int64, error) {
+	query := s.DB.Model(&database.ImportReport{})
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("erro ao contar os relatórios de importação: %w", err)
+	}
+	var reports []database.ImportReport
+	if err := query.Order("id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&reports).Error; err != nil {
+		return nil, 0, fmt.Errorf("erro ao buscar os relatórios de importação: %w", err)
+	}
+	return reports, total, nil
+}
+
+// GetReport retorna um relatório com os arquivos não importados, opcionalmente só os de um resultado.
+func (s *ImportReportService) GetReport(id uint, outcome string) (*database.ImportReport, []database.ImportReportEntry, error) {
+	var report database.ImportReport
+	if err := s.DB.First(&report, id).Error; err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar o relatório de importação: %w", err)
+	}
+	query := s.DB.Where("report_id = ?", id)
+	if outcome != "" {
+		query = query.Where("outcome = ?", outcome)
+	}
+	entries := []database.ImportReportEntry{}
+	if err := query.Order("id").Find(&entries).Error; err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar os arquivos do relatório de importação: %w", err)
+	}
+	return &report, entries, nil
+}
+
+// JobReportID retorna o ID do relatório de um job de importação (0 se o job ainda não começou).
+func (s *ImportReportService) JobReportID(jobID uint) uint {
+	if s == nil {
+		return 0
+	}
+	var ids []uint
+	if err := s.DB.Model(&database.ImportReport{}).Where("import_job_id = ?", jobID).Limit(1).Pluck("id", &ids).Error; err != nil || len(ids) == 0 {
+		return 0
+	}
+	return ids[0]
+}
@@ -0,0 +1,450 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/library"
+	"photo-manager/internal/storage"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// ImportService gerencia jobs de importação de diretórios locais com progresso persistido.
+type ImportService struct {
+	DB           *gorm.DB
+	PhotoService *PhotoService
+	AlbumService *AlbumService // Álbuns preservados nas importações de bibliotecas (PhotoPrism, Immich)
+
+	Reports *ImportReportService // Relatório de cada job, com o resultado por arquivo (opcional)
+
+	pool    *workerPool
+	mu      sync.Mutex
+	running map[uint]bool // Jobs na fila ou em execução neste processo, evita execuções duplicadas
+}
+
+// ErrImportQueueFull indica que a fila de importações está cheia; o job não foi criado.
+var ErrImportQueueFull = i18n.NewError(i18n.CodeImportQueueFull)
+
+// NewImportService cria uma nova instância de ImportService, executando até workers jobs simultâneos e
+// mantendo até queueSize jobs aguardando. Os workers começam após Start.
+func NewImportService(db *gorm.DB, ps *PhotoService, as *AlbumService, workers, queueSize int) *ImportService {
+	return &ImportService{
+		DB:           db,
+		PhotoService: ps,
+		AlbumService: as,
+		pool:         newWorkerPool("imports", workers, queueSize),
+		running:      make(map[uint]bool),
+	}
+}
+
+// Start inicia os workers das importações, até o contexto ser cancelado.
+func (s *ImportService) Start(ctx context.Context) {
+	s.pool.start(ctx)
+}
+
+// SetWorkers altera o número de importações simultâneas sem interromper as que estão em andamento.
+func (s *ImportService) SetWorkers(workers int) {
+	s.pool.resize(workers)
+}
+
+// PoolMetrics retorna as métricas dos workers de importação.
+func (s *ImportService) PoolMetrics() []PoolMetrics {
+	return []PoolMetrics{s.pool.metrics()}
+}
+
+// supportedImportExtensions lista as extensões consideradas durante a varredura do diretório.
+var supportedImportExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+}
+
+// StartImport cria um novo job de importação para o diretório informado e o executa em background.
+// Com inPlace=true as fotos são indexadas no local (sem cópia); executar novamente um job no local
+// sobre o mesmo diretório funciona como reindexação, detectando arquivos alterados ou removidos.
+// format indica uma biblioteca de outro gerenciador (library.FormatPhotoPrism ou library.FormatImmich) cujos
+// álbuns, favoritos e tags são preservados; manifestPath é o JSON de metadados opcional do Immich.
+func (s *ImportService) StartImport(sourcePath string, inPlace bool, format, manifestPath string) (*database.ImportJob, error) {
+	if s.pool.full() {
+		s.pool.refuse()
+		return nil, ErrImportQueueFull
+	}
+	absPath, err := filepath.Abs(sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("caminho de origem inválido: %w", err)
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível acessar o diretório de origem '%s': %w", absPath, err)
+	}
+	if !info.IsDir() {
+		return nil, i18n.NewError(i18n.CodeImportNotDirectory, absPath)
+	}
+
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "":
+		manifestPath = ""
+	case library.FormatPhotoPrism, library.FormatImmich:
+		if manifestPath != "" {
+			if manifestPath, err = filepath.Abs(manifestPath); err != nil {
+				return nil, fmt.Errorf("caminho do manifesto inválido: %w", err)
+			}
+		}
+		// Lê a biblioteca uma vez para rejeitar logo estruturas ou manifestos inválidos
+		if _, err := library.Read(format, absPath, manifestPath); err != nil {
+			return nil, i18n.NewError(i18n.CodeImportLibraryInvalid, absPath, err)
+		}
+	default:
+		return nil, i18n.NewError(i18n.CodeImportFormatInvalid, format)
+	}
+
+	job := database.ImportJob{
+		SourcePath:   absPath,
+		InPlace:      inPlace,
+		Format:       format,
+		ManifestPath: manifestPath,
+		Status:       database.ImportStatusPending,
+	}
+	if result := s.DB.Create(&job); result.Error != nil {
+		return nil, fmt.Errorf("não foi possível criar o job de importação: %w", result.Error)
+	}
+
+	if !s.launch(job.ID) {
+		// A fila encheu enquanto o job era criado: ele fica pendente e é retomado na próxima inicialização
+		log.Printf("Importação %d aguardando: fila de importações cheia\n", job.ID)
+	}
+	return &job, nil
+}
+
+// GetImportJob retorna o estado atual de um job de importação.
+func (s *ImportService) GetImportJob(id uint) (*database.ImportJob, error) {
+	var job database.ImportJob
+	if result := s.DB.First(&job, id); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar job de importação: %w", result.Error)
+	}
+	return &job, nil
+}
+
+// ResumePendingImports retoma os jobs que não terminaram (ex: interrompidos por um reinício do servidor).
+// Deve ser chamado uma vez na inicialização da aplicação. Jobs além da capacidade da fila continuam
+// pendentes até a próxima inicialização.
+func (s *ImportService) ResumePendingImports() error {
+	var jobs []database.ImportJob
+	result := s.DB.Where("status IN ?", []string{database.ImportStatusPending, database.ImportStatusRunning}).Find(&jobs)
+	if result.Error != nil {
+		return fmt.Errorf("erro ao buscar jobs de importação pendentes: %w", result.Error)
+	}
+
+	for _, job := range jobs {
+		log.Printf("Retomando job de importação %d (%s) a partir de '%s'\n", job.ID, job.SourcePath, job.Cursor)
+		if !s.launch(job.ID) {
+			log.Printf("Importação %d continua pendente: fila de importações cheia\n", job.ID)
+		}
+	}
+	return nil
+}
+
+// launch enfileira o job nos workers de importação, garantindo que o mesmo job não rode duas vezes em
+// paralelo. Retorna false se a fila estiver cheia.
+func (s *ImportService) launch(jobID uint) bool {
+	s.mu.Lock()
+	if s.running[jobID] {
+		s.mu.Unlock()
+		return true
+	}
+	s.running[jobID] = true
+	s.mu.Unlock()
+
+	done := func() {
+		s.mu.Lock()
+		delete(s.running, jobID)
+		s.mu.Unlock()
+	}
+	queued := s.pool.submit(func(context.Context) {
+		defer done()
+		if err := s.runImport(jobID); err != nil {
+			log.Printf("Erro no job de importação %d: %v\n", jobID, err)
+		}
+	})
+	if !queued {
+		done()
+	}
+	return queued
+}
+
+// runImport executa o job, retomando-o a partir do cursor e persistindo o progresso após cada arquivo.
+func (s *ImportService) runImport(jobID uint) error {
+	job, err := s.GetImportJob(jobID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"status": database.ImportStatusRunning}
+	if job.StartedAt == nil {
+		updates["started_at"] = now
+	}
+	if result := s.DB.Model(job).Updates(updates); result.Error != nil {
+		return fmt.Errorf("não foi possível atualizar o status do job: %w", result.Error)
+	}
+
+	recorder := s.Reports.ForJob(job)
+	var walkErr error
+	if job.Format != "" {
+		walkErr = s.importLibrary(job, recorder)
+	} else {
+		walkErr = s.importDirectory(job, recorder)
+	}
+	recorder.Finish(walkErr)
+
+	if walkErr == nil && job.InPlace {
+		if err := s.countMissingFiles(job); err != nil {
+			log.Printf("Aviso: não foi possível verificar arquivos removidos do job %d: %v\n", job.ID, err)
+		} else if job.MissingFiles > 0 {
+			s.PhotoService.Events.Publish(events.TypeIntegrityFailure, map[string]interface{}{
+				"import_job_id": job.ID,
+				"missing_files": job.MissingFiles,
+				"message":       fmt.Sprintf("%d arquivo(s) indexado(s) em '%s' não existem mais.", job.MissingFiles, job.SourcePath),
+			})
+		}
+	}
+
+	finishedAt := time.Now()
+	if walkErr != nil {
+		s.DB.Model(job).Updates(map[string]interface{}{
+			"status":      database.ImportStatusFailed,
+			"last_error":  walkErr.Error(),
+			"finished_at": finishedAt,
+		})
+		s.PhotoService.Events.Publish(events.TypeImportFinished, map[string]interface{}{
+			"job_id":      job.ID,
+			"source_path": job.SourcePath,
+			"status":      database.ImportStatusFailed,
+			"error":       walkErr.Error(),
+		})
+		return fmt.Errorf("falha ao percorrer o diretório de origem: %w", walkErr)
+	}
+
+	if result := s.DB.Model(job).Updates(map[string]interface{}{
+		"status":      database.ImportStatusCompleted,
+		"finished_at": finishedAt,
+	}); result.Error != nil {
+		return fmt.Errorf("não foi possível finalizar o job de importação: %w", result.Error)
+	}
+
+	log.Printf("Job de importação %d finalizado: %d importadas, %d atualizadas, %d ignoradas, %d com falha, %d ausentes\n",
+		job.ID, job.ImportedFiles, job.UpdatedFiles, job.SkippedFiles, job.FailedFiles, job.MissingFiles)
+	s.PhotoService.Events.Publish(events.TypeImportFinished, map[string]interface{}{
+		"job_id":      job.ID,
+		"source_path": job.SourcePath,
+		"status":      database.ImportStatusCompleted,
+		"imported":    job.ImportedFiles,
+		"updated":     job.UpdatedFiles,
+		"skipped":     job.SkippedFiles,
+		"failed":      job.FailedFiles,
+		"missing":     job.MissingFiles,
+	})
+	return nil
+}
+
+// importDirectory percorre o diretório de origem em ordem lexical, pulando tudo que já foi processado (até o cursor).
+func (s *ImportService) importDirectory(job *database.ImportJob, recorder *ImportRecorder) error {
+	cursor := splitImportPath(job.Cursor)
+
+	return filepath.WalkDir(job.SourcePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == job.SourcePath {
+				return err // Diretório raiz inacessível: não há como continuar
+			}
+			log.Printf("Aviso: não foi possível acessar '%s' durante a importação: %v\n", path, err)
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(job.SourcePath, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		parts := splitImportPath(rel)
+
+		if d.IsDir() {
+			// Pula diretórios inteiros já processados (anteriores ao cursor e que não o contêm)
+			if len(cursor) > 0 && !isPathPrefix(parts, cursor) && compareImportPaths(parts, cursor) < 0 {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if len(cursor) > 0 && compareImportPaths(parts, cursor) <= 0 {
+			return nil // Já processado em uma execução anterior
+		}
+		if !supportedImportExtensions[strings.ToLower(filepath.Ext(path))] {
+			recorder.Skip(filepath.ToSlash(rel))
+			return nil
+		}
+
+		return s.processImportFile(job, path, rel, nil, recorder)
+	})
+}
+
+// processImportFile importa (ou reindexa, no modo no local) um arquivo e grava o checkpoint do job.
+// Nas importações de bibliotecas, item traz os metadados da origem, aplicados também às duplicatas.
+// O resultado é registrado no relatório do job. Erros de arquivos individuais não interrompem a importação;
+// apenas erros de persistência do progresso.
+func (s *ImportService) processImportFile(job *database.ImportJob, path, rel string, item *library.Item, recorder *ImportRecorder) error {
+	photo, updated, err := s.importFile(job, path)
+	if errors.Is(err, storage.ErrInsufficientStorage) || errors.Is(err, storage.ErrNoVolumeAvailable) {
+		// Sem espaço não adianta continuar; o cursor não avança para que o arquivo seja reprocessado
+		return fmt.Errorf("importação interrompida em '%s': %w", rel, err)
+	}
+
+	if updated {
+		recorder.Updated(filepath.ToSlash(rel))
+	} else {
+		recorder.Record(filepath.ToSlash(rel), photo, err)
+	}
+	switch {
+	case err == nil && updated:
+		job.UpdatedFiles++
+	case err == nil:
+		job.ImportedFiles++
+	case errors.Is(err, ErrDuplicatePhoto), errors.Is(err, errPhotoUnchanged):
+		job.SkippedFiles++
+	default:
+		job.FailedFiles++
+		job.LastError = fmt.Sprintf("%s: %v", rel, err)
+		log.Printf("Erro ao importar '%s': %v\n", path, err)
+	}
+
+	if item != nil && photo != nil && (err == nil || errors.Is(err, ErrDuplicatePhoto) || errors.Is(err, errPhotoUnchanged)) {
+		if err := s.applyLibraryItem(photo, item); err != nil {
+			job.LastError = fmt.Sprintf("%s: %v", rel, err)
+			log.Printf("Aviso: metadados da biblioteca não aplicados a '%s': %v\n", path, err)
+		}
+	}
+
+	job.ProcessedFiles++
+	job.Cursor = filepath.ToSlash(rel)
+
+	result := s.DB.Model(job).Updates(map[string]interface{}{
+		"cursor":          job.Cursor,
+		"processed_files": job.ProcessedFiles,
+		"imported_files":  job.ImportedFiles,
+		"updated_files":   job.UpdatedFiles,
+		"skipped_files":   job.SkippedFiles,
+		"failed_files":    job.FailedFiles,
+		"last_error":      job.LastError,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("não foi possível salvar o progresso da importação: %w", result.Error)
+	}
+	return nil
+}
+
+// errPhotoUnchanged indica que uma foto indexada no local não mudou desde a última varredura.
+var errPhotoUnchanged = errors.New("foto sem alterações")
+
+// importFile importa um arquivo. No modo no local, arquivos já indexados são verificados quanto a
+// alterações em vez de reimportados; updated indica que uma foto existente foi atualizada.
+// A foto retornada é a importada ou a já existente (duplicata ou arquivo sem alterações).
+func (s *ImportService) importFile(job *database.ImportJob, path string) (photo *database.Photo, updated bool, err error) {
+	// O job roda em segundo plano e não deve ser cancelado quando a requisição que o criou termina
+	ctx := context.Background()
+
+	if job.InPlace {
+		var existing database.Photo
+		result := s.DB.Where("stored_path = ? AND managed_externally = ?", path, true).First(&existing)
+		if result.Error == nil {
+			changed, err := s.PhotoService.RefreshExternalPhoto(ctx, &existing)
+			if err != nil {
+				return nil, false, err
+			}
+			if !changed {
+				return &existing, false, errPhotoUnchanged
+			}
+			return &existing, true, nil
+		} else if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, false, fmt.Errorf("erro ao verificar o índice: %w", result.Error)
+		}
+	}
+
+	photo, err = s.PhotoService.ImportPhotoFromPath(ctx, path, job.InPlace, PhotoOrigin{})
+	return photo, false, err
+}
+
+// countMissingFiles conta as fotos indexadas no local sob o diretório do job cujo arquivo não existe mais.
+// As fotos não são removidas do índice automaticamente; apenas reportadas no job.
+// O prefixo é comparado com substr em vez de LIKE: "_" e "%" são comuns em nomes de pastas e LIKE ignora
+// maiúsculas, o que incluiria pastas vizinhas como "Fotos-2023" na verificação de "fotos_2023".
+func (s *ImportService) countMissingFiles(job *database.ImportJob) error {
+	var photos []database.Photo
+	prefix := strings.TrimSuffix(job.SourcePath, string(filepath.Separator)) + string(filepath.Separator)
+	result := s.DB.Select("id", "stored_path").
+		Where("managed_externally = ? AND substr(stored_path, 1, ?) = ?", true, utf8.RuneCountInString(prefix), prefix).
+		Find(&photos)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	missing := 0
+	for _, photo := range photos {
+		if _, err := os.Stat(photo.StoredPath); os.IsNotExist(err) {
+			log.Printf("Aviso: arquivo da foto %d não encontrado: %s\n", photo.ID, photo.StoredPath)
+			missing++
+		}
+	}
+
+	job.MissingFiles = missing
+	return s.DB.Model(job).Update("missing_files", missing).Error
+}
+
+// splitImportPath divide um caminho relativo em seus componentes.
+func splitImportPath(rel string) []string {
+	if rel == "" {
+		return nil
+	}
+	return strings.Split(filepath.ToSlash(rel), "/")
+}
+
+// compareImportPaths compara dois caminhos componente a componente, reproduzindo a ordem do filepath.WalkDir
+// (uma comparação direta de strings falharia, pois '/' é ordenado depois de '.').
+func compareImportPaths(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := strings.Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
+
+// isPathPrefix indica se prefix é um diretório ancestral (ou igual) de path.
+func isPathPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
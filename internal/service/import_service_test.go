@@ -0,0 +1,71 @@
+package service
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"testing"
+)
+
+// newTestImportJob cria um job de importação para a pasta e o executa até o fim, sem passar pela fila.
+func newTestImportJob(t *testing.T, s *ImportService, sourcePath string, inPlace bool) *database.ImportJob {
+	t.Helper()
+	job := database.ImportJob{SourcePath: sourcePath, InPlace: inPlace, Status: database.ImportStatusPending}
+	if err := s.DB.Create(&job).Error; err != nil {
+		t.Fatalf("criar job de importação: %v", err)
+	}
+	if err := s.runImport(job.ID); err != nil {
+		t.Fatalf("executar importação de '%s': %v", sourcePath, err)
+	}
+	loaded, err := s.GetImportJob(job.ID)
+	if err != nil {
+		t.Fatalf("ler job de importação: %v", err)
+	}
+	return loaded
+}
+
+// TestInPlaceImportSameFilenameInSubfolders indexa no local duas fotos com o mesmo nome em subpastas
+// diferentes e confere que a verificação de arquivos removidos não inclui pastas vizinhas de nome parecido.
+func TestInPlaceImportSameFilenameInSubfolders(t *testing.T) {
+	ps := newTestPhotoService(t)
+	s := NewImportService(ps.DB, ps, nil, 1, 1)
+	root := t.TempDir()
+
+	library := filepath.Join(root, "fotos_2023")
+	writeTestJPEG(t, filepath.Join(library, "2023", "IMG_0001.jpg"), color.RGBA{R: 200, A: 255})
+	writeTestJPEG(t, filepath.Join(library, "2024", "IMG_0001.jpg"), color.RGBA{G: 200, A: 255})
+
+	job := newTestImportJob(t, s, library, true)
+	if job.ImportedFiles != 2 || job.FailedFiles != 0 {
+		t.Fatalf("importadas %d, com falha %d (%s), esperado 2 e 0", job.ImportedFiles, job.FailedFiles, job.LastError)
+	}
+	var photos []database.Photo
+	if err := ps.DB.Order("id").Find(&photos).Error; err != nil || len(photos) != 2 {
+		t.Fatalf("fotos no banco: %d (%v), esperado 2", len(photos), err)
+	}
+	if photos[0].Filename == photos[1].Filename {
+		t.Fatalf("as duas fotos ficaram com o nome %q", photos[0].Filename)
+	}
+	for _, photo := range photos {
+		if !photo.ManagedExternally || filepath.Dir(filepath.Dir(photo.StoredPath)) != library {
+			t.Fatalf("foto %d não indexada no local: %s", photo.ID, photo.StoredPath)
+		}
+	}
+
+	// "FOTOS-2023" casaria com LIKE '.../fotos_2023/%' (o "_" é curinga e LIKE ignora maiúsculas)
+	neighbour := filepath.Join(root, "FOTOS-2023")
+	stray := filepath.Join(neighbour, "IMG_0002.jpg")
+	writeTestJPEG(t, stray, color.RGBA{B: 200, A: 255})
+	newTestImportJob(t, s, neighbour, true)
+	if err := os.Remove(stray); err != nil {
+		t.Fatalf("remover %s: %v", stray, err)
+	}
+
+	if err := s.countMissingFiles(job); err != nil {
+		t.Fatalf("verificar arquivos removidos: %v", err)
+	}
+	if job.MissingFiles != 0 {
+		t.Fatalf("arquivos ausentes em '%s': %d, esperado 0", library, job.MissingFiles)
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/hooks"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ingestHookBackoff é a espera antes da primeira nova tentativa; dobra a cada tentativa seguinte.
+const ingestHookBackoff = 2 * time.Second
+
+// IngestHookService avisa um comando ou URL externo de cada foto ingerida (ex: copiar para outro sistema).
+// A execução é feita em background, com novas tentativas após falhas; a ingestão nunca espera o hook.
+// As execuções dividem um conjunto limitado de workers; com a fila cheia (ex: importação grande com o
+// destino fora do ar), as fotos excedentes não são enviadas ao hook e entram na contagem de recusas.
+type IngestHookService struct {
+	DB      *gorm.DB
+	Hook    *hooks.Hook
+	Retries int // Novas tentativas após uma falha (rejeições do hook não são repetidas)
+
+	pool *workerPool
+}
+
+// NewIngestHookService cria uma nova instância de IngestHookService, com workers execuções simultâneas
+// e até queueSize fotos aguardando.
+func NewIngestHookService(db *gorm.DB, hook *hooks.Hook, retries, workers, queueSize int) *IngestHookService {
+	return &IngestHookService{DB: db, Hook: hook, Retries: retries, pool: newWorkerPool("ingest_hooks", workers, queueSize)}
+}
+
+// Start inicia os workers, até o contexto ser cancelado.
+func (s *IngestHookService) Start(ctx context.Context) {
+	s.pool.start(ctx)
+}
+
+// SetWorkers altera o número de execuções simultâneas do hook sem interromper as que estão em andamento.
+func (s *IngestHookService) SetWorkers(workers int) {
+	s.pool.resize(workers)
+}
+
+// PoolMetrics retorna as métricas dos workers do hook.
+func (s *IngestHookService) PoolMetrics() []PoolMetrics {
+	return []PoolMetrics{s.pool.metrics()}
+}
+
+// Subscribe executa o hook para cada foto criada.
+func (s *IngestHookService) Subscribe(bus *events.Bus) {
+	bus.Subscribe(func(e events.Event) {
+		if e.Type != events.TypePhotoCreated {
+			return
+		}
+		photoID, ok := e.Data["photo_id"].(uint)
+		if !ok {
+			return
+		}
+		if !s.pool.submit(func(ctx context.Context) { s.run(ctx, photoID) }) {
+			log.Printf("Hook de pós-ingestão: fila cheia, foto %d não enviada\n", photoID)
+		}
+	})
+}
+
+// run executa o hook para a foto, repetindo as falhas com espera crescente.
+func (s *IngestHookService) run(ctx context.Context, photoID uint) {
+	var photo database.Photo
+	result := s.DB.WithContext(ctx).Where("id = ?", photoID).Limit(1).Find(&photo)
+	if result.Error != nil || result.RowsAffected == 0 {
+		log.Printf("Hook de pós-ingestão: foto %d indisponível: %v\n", photoID, result.Error)
+		return
+	}
+	payload := ingestHookPayload(&photo)
+
+	backoff := ingestHookBackoff
+	for attempt := 0; ; attempt++ {
+		err := s.Hook.Run(ctx, payload)
+		if err == nil {
+			return
+		}
+		var rejection *hooks.Rejection
+		if errors.As(err, &rejection) || attempt >= s.Retries {
+			log.Printf("Hook de pós-ingestão falhou para a foto %d após %d tentativa(s): %v\n", photoID, attempt+1, err)
+			return
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff *= 2
+	}
+}
+
+// ingestHookPayload monta o payload de pós-ingestão da foto.
+func ingestHookPayload(photo *database.Photo) hooks.Payload {
+	return hooks.Payload{
+		Phase:    hooks.PhasePostIngest,
+		Path:     photo.StoredPath,
+		Filename: photo.Filename,
+		Size:     photo.FileSize,
+		MimeType: photo.MimeType,
+		Width:    photo.Width,
+		Height:   photo.Height,
+		Photo: &hooks.PhotoInfo{
+			ID:          photo.ID,
+			StoredPath:  photo.StoredPath,
+			Hash:        photo.Hash,
+			ExifDate:    photo.ExifDate,
+			Latitude:    photo.Latitude,
+			Longitude:   photo.Longitude,
+			CameraMake:  photo.CameraMake,
+			CameraModel: photo.CameraModel,
+			Source:      photo.Source,
+			Device:      photo.SourceDevice,
+		},
+	}
+}
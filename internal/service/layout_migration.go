@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/storage"
+	"strings"
+)
+
+// LayoutMigrationResult resume uma migração de layout de armazenamento.
+type LayoutMigrationResult struct {
+	Moved   int // Arquivos movidos para o novo layout (ou que seriam movidos, em simulação)
+	Skipped int // Arquivos já no layout de destino, indexados no local ou fora de um volume
+	Failed  int // Arquivos que não puderam ser movidos (ex: destino já existe, volume offline)
+}
+
+// MigrateLayout move os arquivos das fotos do armazenamento gerenciado para o layout informado
+// (storage.LayoutDate ou storage.LayoutHash), atualizando o caminho de cada foto no banco.
+// Fotos indexadas no local, em quarentena ou fora dos volumes registrados não são alteradas.
+// Com dryRun=true apenas contabiliza o que seria movido.
+func (s *PhotoService) MigrateLayout(ctx context.Context, layout string, dryRun bool) (LayoutMigrationResult, error) {
+	var result LayoutMigrationResult
+	if !storage.ValidLayout(layout) {
+		return result, fmt.Errorf("layout de armazenamento inválido: '%s'", layout)
+	}
+
+	volumePaths := make(map[string]string)
+	for _, volume := range s.FileManager.Volumes() {
+		volumePaths[volume.Name] = volume.Path
+	}
+
+	var photos []database.Photo
+	query := s.DB.WithContext(ctx).Where("managed_externally = ? AND quarantined = ?", false, false).Order("id ASC")
+	if err := query.Find(&photos).Error; err != nil {
+		return result, fmt.Errorf("erro ao buscar fotos para migração: %w", err)
+	}
+
+	for _, photo := range photos {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		volumeName := photo.Volume
+		if volumeName == "" {
+			volumeName = storage.DefaultVolumeName
+		}
+		root, ok := volumePaths[volumeName]
+		if !ok || !isWithin(root, photo.StoredPath) {
+			result.Skipped++
+			continue
+		}
+
+		photoDate := photo.UploadDate
+		if photo.ExifDate != nil {
+			photoDate = *photo.ExifDate
+		}
+		target := filepath.Join(root, storage.LayoutPath(layout, photo.Filename, photo.Hash, photoDate))
+		if filepath.Clean(target) == filepath.Clean(photo.StoredPath) {
+			result.Skipped++
+			continue
+		}
+		if dryRun {
+			result.Moved++
+			continue
+		}
+
+		if err := s.moveStoredFile(ctx, &photo, root, target); err != nil {
+			log.Printf("Erro ao migrar a foto %d: %v\n", photo.ID, err)
+			result.Failed++
+			continue
+		}
+		result.Moved++
+	}
+	return result, nil
+}
+
+// moveStoredFile move o arquivo da foto para target e atualiza o banco. Se a atualização falhar, o arquivo
+// volta ao lugar de origem. Diretórios que ficarem vazios são removidos, até a raiz do volume.
+func (s *PhotoService) moveStoredFile(ctx context.Context, photo *database.Photo, root, target string) error {
+	if _, err := os.Stat(target); err == nil {
+		return fmt.Errorf("o destino '%s' já existe", target)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("não foi possível verificar o destino '%s': %w", target, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("não foi possível criar o diretório de destino: %w", err)
+	}
+	source := photo.StoredPath
+	if err := os.Rename(source, target); err != nil {
+		return fmt.Errorf("não foi possível mover '%s': %w", source, err)
+	}
+
+	if err := s.DB.WithContext(ctx).Model(photo).Update("stored_path", target).Error; err != nil {
+		if rollbackErr := os.Rename(target, source); rollbackErr != nil {
+			log.Printf("Erro ao desfazer a migração da foto %d (arquivo em '%s'): %v\n", photo.ID, target, rollbackErr)
+		}
+		return fmt.Errorf("não foi possível atualizar o caminho da foto: %w", err)
+	}
+
+	removeEmptyDirs(filepath.Dir(source), root)
+	return nil
+}
+
+// isWithin indica se path está dentro do diretório root. Os caminhos são comparados como absolutos, já que
+// fotos antigas podem ter sido gravadas com o caminho relativo de PHOTO_STORAGE_PATH.
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(driftKey(root), driftKey(path))
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+}
+
+// removeEmptyDirs remove dir e seus pais enquanto estiverem vazios, sem remover root.
+func removeEmptyDirs(dir, root string) {
+	for isWithin(root, dir) {
+		if err := os.Remove(dir); err != nil {
+			return // Não está vazio (ou não pôde ser removido)
+		}
+		dir = filepath.Dir(dir)
+	}
+}
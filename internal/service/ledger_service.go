@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/ledger"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tipos de problema encontrados na verificação do livro-razão.
+const (
+	LedgerBrokenChain      = "broken_chain"      // Entrada alterada, removida ou fora de ordem
+	LedgerFileModified     = "file_modified"     // O arquivo não confere com o hash registrado
+	LedgerFileMissing      = "file_missing"      // O arquivo registrado não existe mais
+	LedgerPhotoMissing     = "photo_missing"     // A foto saiu do banco sem uma entrada de remoção
+	LedgerTimestampInvalid = "timestamp_invalid" // O carimbo de tempo não corresponde à entrada carimbada
+)
+
+// LedgerProblem é uma divergência encontrada na verificação.
+type LedgerProblem struct {
+	Kind    string // Tipo do problema (ver constantes Ledger*)
+	Seq     uint64 // Entrada envolvida (0 se não se aplica)
+	PhotoID uint   // Foto envolvida (0 se não se aplica)
+	Detail  string
+}
+
+// LedgerReport é o resultado da verificação do livro-razão.
+type LedgerReport struct {
+	Entries    int             // Entradas verificadas
+	Photos     int             // Fotos com arquivo conferido
+	Timestamps int             // Carimbos de tempo conferidos
+	Updated    []uint          // Fotos cujo arquivo foi alterado pela aplicação depois da ingestão (registrado na cadeia)
+	Unrecorded []uint          // Fotos da biblioteca ainda sem entrada (anteriores à ativação; ver RecordMissing)
+	Problems   []LedgerProblem // Divergências: qualquer uma indica adulteração ou perda
+}
+
+// Intact indica se a verificação não encontrou divergências.
+func (r *LedgerReport) Intact() bool {
+	return len(r.Problems) == 0
+}
+
+// LedgerService mantém o livro-razão de integridade: cada foto ingerida tem o SHA-256 do arquivo registrado
+// em uma cadeia de hashes somente de inclusão, e a cabeça da cadeia pode ser carimbada por uma autoridade
+// de carimbo de tempo externa. Verify prova que nem os arquivos nem o histórico foram alterados desde o registro.
+type LedgerService struct {
+	DB         *gorm.DB
+	Timestamps *ledger.TimestampClient // Autoridade de carimbo de tempo (nil desativa os carimbos)
+
+	mu     sync.Mutex    // Serializa as inclusões na cadeia
+	anchor chan struct{} // Sinaliza que a cabeça da cadeia mudou e deve ser carimbada
+}
+
+// NewLedgerService cria uma nova instância de LedgerService.
+func NewLedgerService(db *gorm.DB, timestamps *ledger.TimestampClient) *LedgerService {
+	return &LedgerService{
+		DB:         db,
+		Timestamps: timestamps,
+		anchor:     make(chan struct{}, 1),
+	}
+}
+
+// Subscribe registra no livro-razão as fotos criadas, as alterações de arquivo e as remoções.
+func (s *LedgerService) Subscribe(bus *events.Bus) {
+	bus.Subscribe(func(e events.Event) {
+		photoID, ok := e.Data["photo_id"].(uint)
+		if !ok {
+			return
+		}
+		ctx := context.Background()
+		var err error
+		switch e.Type {
+		case events.TypePhotoCreated:
+			err = s.recordPhoto(ctx, photoID, ledger.ActionIngest, false)
+		case events.TypePhotoUpdated:
+			err = s.recordPhoto(ctx, photoID, ledger.ActionUpdate, true)
+		case events.TypePhotoDeleted:
+			err = s.recordDeletion(ctx, photoID)
+		default:
+			return
+		}
+		if err != nil {
+			log.Printf("Livro-razão: falha ao registrar a foto %d (%s): %v\n", photoID, e.Type, err)
+		}
+	})
+}
+
+// recordPhoto registra o arquivo atual da foto. Com onlyIfChanged, a foto só é registrada se já estiver na
+// cadeia e o hash do arquivo tiver mudado desde a última entrada (alterações de metadados são ignoradas).
+func (s *LedgerService) recordPhoto(ctx context.Context, photoID uint, action string, onlyIfChanged bool) error {
+	var photo database.Photo
+	if err := s.DB.WithContext(ctx).Unscoped().First(&photo, photoID).Error; err != nil {
+		return err
+	}
+	if onlyIfChanged {
+		last, err := s.lastEntryForPhoto(ctx, photoID)
+		if err != nil || last == nil || last.FileHash == photo.Hash {
+			return err
+		}
+	}
+	_, err := s.Record(ctx, &photo, action)
+	return err
+}
+
+// recordDeletion registra a remoção da foto, se ela estiver na cadeia.
+func (s *LedgerService) recordDeletion(ctx context.Context, photoID uint) error {
+	last, err := s.lastEntryForPhoto(ctx, photoID)
+	if err != nil || last == nil || last.Action == ledger.ActionDelete {
+		return err
+	}
+	_, err = s.append(ctx, database.LedgerEntry{
+		PhotoID:  photoID,
+		Action:   ledger.ActionDelete,
+		Filename: last.Filename,
+		FileHash: last.FileHash,
+	})
+	return err
+}
+
+// Record calcula o SHA-256 do arquivo da foto e o inclui na cadeia.
+func (s *LedgerService) Record(ctx context.Context, photo *database.Photo, action string) (*database.LedgerEntry, error) {
+	contentHash, err := ledger.FileSHA256(photo.StoredPath)
+	if err != nil {
+		return nil, err
+	}
+	return s.append(ctx, database.LedgerEntry{
+		PhotoID:       photo.ID,
+		Action:        action,
+		Filename:      photo.Filename,
+		FileHash:      photo.Hash,
+		ContentSHA256: contentHash,
+	})
+}
+
+// RecordMissing inclui na cadeia, como entradas de base, as fotos da biblioteca ainda sem registro
+// (ex: adicionadas antes da ativação do livro-razão). Retorna quantas foram registradas.
+func (s *LedgerService) RecordMissing(ctx context.Context) (int, error) {
+	var photos []database.Photo
+	recorded := 0
+	var failures []error
+	err := s.DB.WithContext(ctx).Unscoped().
+		Where("id NOT IN (?)", s.DB.Model(&database.LedgerEntry{}).Select("photo_id")).
+		FindInBatches(&photos, 200, func(tx *gorm.DB, batch int) error {
+			for i := range photos {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if _, err := s.Record(ctx, &photos[i], ledger.ActionBaseline); err != nil {
+					failures = append(failures, fmt.Errorf("foto %d: %w", photos[i].ID, err))
+					continue
+				}
+				recorded++
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return recorded, fmt.Errorf("erro ao registrar as fotos existentes: %w", err)
+	}
+	return recorded, errors.Join(failures...)
+}
+
+// append inclui a entrada no fim da cadeia, preenchendo a posição, a data e os hashes.
+func (s *LedgerService) append(ctx context.Context, entry database.LedgerEntry) (*database.LedgerEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var head database.LedgerEntry
+		result := tx.Order("seq DESC").Limit(1).Find(&head)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			entry.Seq, entry.PrevHash = 1, ledger.GenesisHash
+		} else {
+			entry.Seq, entry.PrevHash = head.Seq+1, head.EntryHash
+		}
+		// Microssegundos: a precisão preservada pelo banco, para que o hash possa ser recalculado
+		entry.RecordedAt = time.Now().UTC().Truncate(time.Microsecond)
+		entry.EntryHash = ledger.EntryHash(entry)
+		// A posição é a chave primária: uma inclusão concorrente (ex: de outro processo) falha em vez de bifurcar a cadeia
+		return tx.Create(&entry).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao incluir entrada no livro-razão: %w", err)
+	}
+
+	select {
+	case s.anchor <- struct{}{}:
+	default:
+	}
+	return &entry, nil
+}
+
+// lastEntryForPhoto retorna a entrada mais recente da foto, ou nil se a foto não estiver na cadeia.
+func (s *LedgerService) lastEntryForPhoto(ctx context.Context, photoID uint) (*database.LedgerEntry, error) {
+	var entry database.LedgerEntry
+	result := s.DB.WithContext(ctx).Where("photo_id = ?", photoID).Order("seq DESC").Limit(1).Find(&entry)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &entry, nil
+}
+
+// Start carimba a cabeça da cadeia na autoridade de carimbo de tempo sempre que ela muda, até o contexto
+// ser cancelado. Inclusões em sequência geram um único carimbo, da entrada mais recente. Sem autoridade
+// configurada, não faz nada.
+func (s *LedgerService) Start(ctx context.Context) {
+	if s.Timestamps == nil {
+		return
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.anchor:
+				if _, err := s.AnchorHead(ctx); err != nil {
+					log.Printf("Livro-razão: falha ao carimbar a cadeia: %v\n", err)
+				}
+			}
+		}
+	}()
+}
+
+// AnchorHead obtém o carimbo de tempo da entrada mais recente, se ela ainda não tiver um.
+// Retorna nil quando não há nada a carimbar.
+func (s *LedgerService) AnchorHead(ctx context.Context) (*database.LedgerTimestamp, error) {
+	if s.Timestamps == nil {
+		return nil, nil
+	}
+	var head database.LedgerEntry
+	result := s.DB.WithContext(ctx).Order("seq DESC").Limit(1).Find(&head)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	var existing int64
+	if err := s.DB.WithContext(ctx).Model(&database.LedgerTimestamp{}).Where("seq = ?", head.Seq).Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, nil
+	}
+
+	digest, err := hex.DecodeString(head.EntryHash)
+	if err != nil {
+		return nil, fmt.Errorf("hash da entrada %d inválido: %w", head.Seq, err)
+	}
+	token, err := s.Timestamps.Timestamp(ctx, digest)
+	if err != nil {
+		return nil, err
+	}
+	timestamp := database.LedgerTimestamp{
+		Seq:       head.Seq,
+		EntryHash: head.EntryHash,
+		Authority: s.Timestamps.URL,
+		Token:     token,
+	}
+	if err := s.DB.WithContext(ctx).Create(&timestamp).Error; err != nil {
+		return nil, fmt.Errorf("erro ao salvar o carimbo de tempo: %w", err)
+	}
+	return &timestamp, nil
+}
+
+// Verify confere a cadeia inteira: o encadeamento e o hash de cada entrada, o arquivo atual de cada foto
+// contra o SHA-256 da entrada mais recente dela, as fotos removidas sem registro e a correspondência entre
+// os carimbos de tempo e as entradas carimbadas. A assinatura dos carimbos não é verificada aqui (ver
+// ledger.ParseToken).
+func (s *LedgerService) Verify(ctx context.Context) (*LedgerReport, error) {
+	report := &LedgerReport{}
+	latest := make(map[uint]database.LedgerEntry)
+	ingested := make(map[uint]string) // SHA-256 registrado na primeira entrada de cada foto
+
+	expectedSeq, prevHash := uint64(1), ledger.GenesisHash
+	var entries []database.LedgerEntry
+	err := s.DB.WithContext(ctx).Order("seq").FindInBatches(&entries, 1000, func(tx *gorm.DB, batch int) error {
+		for _, entry := range entries {
+			report.Entries++
+			switch {
+			case entry.Seq != expectedSeq:
+				report.Problems = append(report.Problems, LedgerProblem{Kind: LedgerBrokenChain, Seq: entry.Seq, PhotoID: entry.PhotoID,
+					Detail: fmt.Sprintf("posição esperada %d: entradas removidas ou inseridas", expectedSeq)})
+			case entry.PrevHash != prevHash:
+				report.Problems = append(report.Problems, LedgerProblem{Kind: LedgerBrokenChain, Seq: entry.Seq, PhotoID: entry.PhotoID,
+					Detail: "o hash anterior não corresponde à entrada precedente"})
+			case ledger.EntryHash(entry) != entry.EntryHash:
+				report.Problems = append(report.Problems, LedgerProblem{Kind: LedgerBrokenChain, Seq: entry.Seq, PhotoID: entry.PhotoID,
+					Detail: "o conteúdo da entrada foi alterado"})
+			}
+			expectedSeq, prevHash = entry.Seq+1, entry.EntryHash
+
+			latest[entry.PhotoID] = entry
+			if _, ok := ingested[entry.PhotoID]; !ok {
+				ingested[entry.PhotoID] = entry.ContentSHA256
+			}
+		}
+		return ctx.Err()
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o livro-razão: %w", err)
+	}
+
+	photoIDs := make([]uint, 0, len(latest))
+	for photoID := range latest {
+		photoIDs = append(photoIDs, photoID)
+	}
+	sort.Slice(photoIDs, func(i, j int) bool { return photoIDs[i] < photoIDs[j] })
+
+	for _, photoID := range photoIDs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		entry := latest[photoID]
+		if entry.Action == ledger.ActionDelete {
+			continue
+		}
+		if entry.ContentSHA256 != ingested[photoID] {
+			report.Updated = append(report.Updated, photoID)
+		}
+
+		var photo database.Photo
+		result := s.DB.WithContext(ctx).Unscoped().Select("id", "stored_path").Where("id = ?", photoID).Limit(1).Find(&photo)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			report.Problems = append(report.Problems, LedgerProblem{Kind: LedgerPhotoMissing, Seq: entry.Seq, PhotoID: photoID,
+				Detail: fmt.Sprintf("'%s' não está mais no banco e não há registro de remoção", entry.Filename)})
+			continue
+		}
+
+		contentHash, err := ledger.FileSHA256(photo.StoredPath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			report.Problems = append(report.Problems, LedgerProblem{Kind: LedgerFileMissing, Seq: entry.Seq, PhotoID: photoID,
+				Detail: fmt.Sprintf("arquivo '%s' não encontrado", photo.StoredPath)})
+		case err != nil:
+			return nil, err
+		case contentHash != entry.ContentSHA256:
+			report.Problems = append(report.Problems, LedgerProblem{Kind: LedgerFileModified, Seq: entry.Seq, PhotoID: photoID,
+				Detail: fmt.Sprintf("SHA-256 atual %s, registrado %s", contentHash, entry.ContentSHA256)})
+		default:
+			report.Photos++
+		}
+	}
+
+	var unrecorded []uint
+	if err := s.DB.WithContext(ctx).Unscoped().Model(&database.Photo{}).Order("id").Pluck("id", &unrecorded).Error; err != nil {
+		return nil, err
+	}
+	for _, photoID := range unrecorded {
+		if _, ok := latest[photoID]; !ok {
+			report.Unrecorded = append(report.Unrecorded, photoID)
+		}
+	}
+
+	var timestamps []database.LedgerTimestamp
+	if err := s.DB.WithContext(ctx).Order("seq").Find(&timestamps).Error; err != nil {
+		return nil, err
+	}
+	for _, timestamp := range timestamps {
+		report.Timestamps++
+		if detail := s.checkTimestamp(ctx, timestamp); detail != "" {
+			report.Problems = append(report.Problems, LedgerProblem{Kind: LedgerTimestampInvalid, Seq: timestamp.Seq, Detail: detail})
+		}
+	}
+	return report, nil
+}
+
+// checkTimestamp confere se o carimbo atesta o hash atual da entrada carimbada. Retorna a divergência, ou "".
+func (s *LedgerService) checkTimestamp(ctx context.Context, timestamp database.LedgerTimestamp) string {
+	var entry database.LedgerEntry
+	result := s.DB.WithContext(ctx).Where("seq = ?", timestamp.Seq).Limit(1).Find(&entry)
+	if result.Error != nil {
+		return result.Error.Error()
+	}
+	if result.RowsAffected == 0 {
+		return "entrada carimbada não encontrada"
+	}
+	if entry.EntryHash != timestamp.EntryHash {
+		return "o hash da entrada mudou depois do carimbo"
+	}
+	info, err := ledger.ParseToken(timestamp.Token)
+	if err != nil {
+		return err.Error()
+	}
+	if hex.EncodeToString(info.Digest) != entry.EntryHash {
+		return "o token não atesta o hash da entrada"
+	}
+	return ""
+}
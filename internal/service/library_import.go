@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/library"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// importLibrary importa os originais de uma biblioteca de outro gerenciador (PhotoPrism, Immich) na ordem
+// do filepath.WalkDir, pulando os já processados (até o cursor) e preservando os metadados da origem.
+func (s *ImportService) importLibrary(job *database.ImportJob, recorder *ImportRecorder) error {
+	items, err := library.Read(job.Format, job.SourcePath, job.ManifestPath)
+	if err != nil {
+		return err
+	}
+
+	cursor := splitImportPath(job.Cursor)
+	for i := range items {
+		item := &items[i]
+		if len(cursor) > 0 && compareImportPaths(splitImportPath(item.Rel), cursor) <= 0 {
+			continue // Já processado em uma execução anterior
+		}
+		if !supportedImportExtensions[strings.ToLower(filepath.Ext(item.Path))] {
+			recorder.Skip(filepath.ToSlash(item.Rel))
+			continue
+		}
+		if err := s.processImportFile(job, item.Path, item.Rel, item, recorder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyLibraryItem aplica à foto os metadados da biblioteca de origem. Só acrescenta informação:
+// favoritos e fotos ocultas não são desmarcados, descrições existentes são mantidas e as tags são
+// somadas às atuais. Os álbuns são buscados pelo nome (sem diferenciar maiúsculas) ou criados.
+func (s *ImportService) applyLibraryItem(photo *database.Photo, item *library.Item) error {
+	if err := checkPhotoUnlocked(s.DB, photo.ID); err != nil {
+		return err
+	}
+
+	updates := make(map[string]interface{})
+	if item.Favorite && !photo.Favorite {
+		updates["favorite"] = true
+	}
+	if item.Hidden && !photo.Hidden {
+		updates["hidden"] = true
+	}
+	if item.Description != "" && photo.Description == "" {
+		updates["description"] = item.Description
+	}
+	tags := normalizeTagNames(item.Tags)
+
+	if len(updates) > 0 || len(tags) > 0 {
+		err := s.DB.Transaction(func(tx *gorm.DB) error {
+			if len(updates) > 0 {
+				if err := tx.Model(photo).Updates(updates).Error; err != nil {
+					return fmt.Errorf("não foi possível atualizar a foto: %w", err)
+				}
+			}
+			if len(tags) == 0 {
+				return nil
+			}
+			for _, name := range tags {
+				tag, err := findOrCreateTag(tx, name)
+				if err != nil {
+					return err
+				}
+				var existing int64
+				if err := tx.Model(&database.PhotoTag{}).Where("photo_id = ? AND tag_id = ?", photo.ID, tag.ID).Count(&existing).Error; err != nil {
+					return fmt.Errorf("erro ao verificar as tags da foto: %w", err)
+				}
+				if existing > 0 {
+					continue
+				}
+				if err := tx.Create(&database.PhotoTag{PhotoID: photo.ID, TagID: tag.ID}).Error; err != nil {
+					return fmt.Errorf("não foi possível associar a tag '%s': %w", tag.Name, err)
+				}
+			}
+			return refreshPhotoTagNames(tx, []uint{photo.ID})
+		})
+		if err != nil {
+			return err
+		}
+		if len(updates) > 0 {
+			s.PhotoService.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": photo.ID})
+		}
+		if len(tags) > 0 {
+			s.PhotoService.Events.Publish(events.TypeTagsChanged, map[string]interface{}{"photo_id": photo.ID})
+		}
+	}
+
+	for _, name := range item.Albums {
+		if err := s.addToLibraryAlbum(name, photo.ID); err != nil {
+			return fmt.Errorf("álbum '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+// addToLibraryAlbum inclui a foto no álbum com o nome informado, criando-o se não existir.
+func (s *ImportService) addToLibraryAlbum(name string, photoID uint) error {
+	var album database.Album
+	found := s.DB.Where("LOWER(name) = LOWER(?)", name).Limit(1).Find(&album)
+	if found.Error != nil {
+		return fmt.Errorf("erro ao buscar o álbum: %w", found.Error)
+	}
+	if found.RowsAffected == 0 {
+		created, err := s.AlbumService.CreateAlbumWithPhotos(name, "", []uint{photoID})
+		if err != nil {
+			return err
+		}
+		log.Printf("Importação de biblioteca: álbum '%s' criado\n", created.Name)
+		return nil
+	}
+
+	_, err := s.AlbumService.AddPhotosToAlbum(album.ID, []uint{photoID})
+	return err
+}
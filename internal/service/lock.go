@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+
+	"gorm.io/gorm"
+)
+
+// Erros de bloqueio. Fotos bloqueadas (diretamente ou por estarem em um álbum bloqueado) não podem ter o
+// conteúdo ou as tags alterados nem ser removidas; álbuns bloqueados não podem ter fotos adicionadas ou removidas.
+// Favorita, oculta e fixado são preferências de exibição e continuam editáveis.
+var (
+	ErrPhotoLocked = i18n.NewError(i18n.CodePhotoLocked)
+	ErrAlbumLocked = i18n.NewError(i18n.CodeAlbumLocked)
+)
+
+// SetLocked bloqueia ou desbloqueia uma foto.
+func (s *PhotoService) SetLocked(ctx context.Context, id uint, locked bool) (*database.Photo, error) {
+	photo, err := s.GetPhotoByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if result := s.DB.WithContext(ctx).Model(photo).Update("locked", locked); result.Error != nil {
+		return nil, fmt.Errorf("não foi possível atualizar a foto: %w", result.Error)
+	}
+	photo.Locked = locked
+
+	s.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": photo.ID})
+	return photo, nil
+}
+
+// SetLocked bloqueia ou desbloqueia um álbum; o bloqueio se estende às fotos do álbum.
+func (s *AlbumService) SetLocked(id uint, locked bool) (*AlbumSummary, error) {
+	result := s.DB.Model(&database.Album{}).Where("id = ?", id).Update("locked", locked)
+	if result.Error != nil {
+		return nil, fmt.Errorf("não foi possível atualizar o álbum: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("erro ao buscar álbum: %w", gorm.ErrRecordNotFound)
+	}
+
+	s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": id})
+	return s.GetAlbum(id)
+}
+
+// checkPhotoUnlocked retorna ErrPhotoLocked se a foto estiver bloqueada ou pertencer a um álbum bloqueado.
+func checkPhotoUnlocked(db *gorm.DB, photoID uint) error {
+	locked, err := lockedPhotoIDs(db, []uint{photoID})
+	if err != nil {
+		return err
+	}
+	if locked[photoID] {
+		return ErrPhotoLocked
+	}
+	return nil
+}
+
+// checkAlbumUnlocked retorna ErrAlbumLocked se o álbum estiver bloqueado.
+func checkAlbumUnlocked(db *gorm.DB, albumID uint) error {
+	var count int64
+	if err := db.Model(&database.Album{}).Where("id = ? AND locked = ?", albumID, true).Count(&count).Error; err != nil {
+		return fmt.Errorf("erro ao verificar bloqueio do álbum: %w", err)
+	}
+	if count > 0 {
+		return ErrAlbumLocked
+	}
+	return nil
+}
+
+// lockedPhotoIDs retorna quais das fotos estão bloqueadas, diretamente ou por um álbum bloqueado.
+func lockedPhotoIDs(db *gorm.DB, photoIDs []uint) (map[uint]bool, error) {
+	locked := make(map[uint]bool)
+	if len(photoIDs) == 0 {
+		return locked, nil
+	}
+
+	var ids []uint
+	err := db.Model(&database.Photo{}).
+		Where("id IN ?", photoIDs).
+		Where("locked = ? OR id IN (?)", true,
+			db.Model(&database.AlbumPhoto{}).Select("album_photos.photo_id").
+				Joins("JOIN albums ON albums.id = album_photos.album_id AND albums.deleted_at IS NULL").
+				Where("albums.locked = ?", true)).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar bloqueio das fotos: %w", err)
+	}
+	for _, id := range ids {
+		locked[id] = true
+	}
+	return locked, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"photo-manager/internal/database"
+	"photo-manager/internal/exif"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/video"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// ErrMetadataVideo indica que a foto é um vídeo, cujos metadados não são EXIF.
+var ErrMetadataVideo = i18n.NewError(i18n.CodePhotoExifVideo)
+
+// PhotoMetadata são todos os metadados EXIF e IPTC de uma foto, com a procedência da leitura.
+type PhotoMetadata struct {
+	exif.Dump
+	FromOriginal bool      // Lidos do original guardado, e não da cópia reduzida
+	Cached       bool      // Servidos do cache, sem reler o arquivo
+	ParsedAt     time.Time // Data da leitura do arquivo
+}
+
+// PhotoMetadata retorna todos os metadados EXIF e IPTC da foto, lidos do original quando ele foi guardado
+// (fotos reduzidas na ingestão) e, caso contrário, da cópia armazenada. A leitura fica em cache no banco até
+// o arquivo mudar.
+func (s *PhotoService) PhotoMetadata(ctx context.Context, photo *database.Photo) (*PhotoMetadata, error) {
+	if video.IsVideo(photo.MimeType) {
+		return nil, ErrMetadataVideo
+	}
+
+	path, fromOriginal := photo.StoredPath, false
+	if photo.OriginalPath != "" {
+		if _, err := os.Stat(photo.OriginalPath); err == nil {
+			path, fromOriginal = photo.OriginalPath, true
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("arquivo da foto indisponível: %w", err)
+	}
+	key := fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano())
+
+	var cached database.MetadataDump
+	result := s.DB.WithContext(ctx).Where("photo_id = ?", photo.ID).Limit(1).Find(&cached)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar os metadados em cache: %w", result.Error)
+	}
+	if result.RowsAffected > 0 && cached.SourcePath == path && cached.SourceKey == key {
+		metadata := &PhotoMetadata{FromOriginal: fromOriginal, Cached: true, ParsedAt: cached.ParsedAt}
+		if err := json.Unmarshal([]byte(cached.Data), &metadata.Dump); err == nil {
+			return metadata, nil
+		}
+		// Cache ilegível: relê o arquivo
+	}
+
+	dump, err := exif.DumpMetadata(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(dump)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível codificar os metadados: %w", err)
+	}
+
+	entry := database.MetadataDump{
+		PhotoID:    photo.ID,
+		SourcePath: path,
+		SourceKey:  key,
+		Data:       string(data),
+		ParsedAt:   time.Now(),
+	}
+	err = s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "photo_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"source_path", "source_key", "data", "parsed_at"}),
+	}).Create(&entry).Error
+	if err != nil && !errors.Is(err, context.Canceled) {
+		// Falhar ao gravar o cache não impede a resposta; a próxima consulta tenta de novo
+		log.Printf("Aviso: não foi possível guardar os metadados da foto %d em cache: %v\n", photo.ID, err)
+	}
+	return &PhotoMetadata{Dump: *dump, FromOriginal: fromOriginal, ParsedAt: entry.ParsedAt}, nil
+}
@@ -0,0 +1,496 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/mirror"
+	"photo-manager/internal/storage"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrMirrorRunning indica que a origem já está sendo sincronizada.
+var ErrMirrorRunning = i18n.NewError(i18n.CodeMirrorRunning)
+
+// MirrorSyncResult resume uma sincronização.
+type MirrorSyncResult struct {
+	Listed     int // Objetos de tipos suportados listados na origem
+	Unchanged  int // Objetos já processados em sincronizações anteriores (mesmo ETag)
+	Imported   int // Objetos importados como novas fotos
+	Duplicates int // Objetos cujo conteúdo já estava na biblioteca
+	Failed     int // Objetos que falharam ao baixar ou importar
+}
+
+// MirrorService espelha origens remotas (buckets S3, diretórios montados) para a biblioteca, importando os
+// objetos ainda não vistos. Cada objeto processado é registrado pela chave e pelo ETag, de modo que as
+// sincronizações seguintes baixam apenas objetos novos ou substituídos.
+type MirrorService struct {
+	DB           *gorm.DB
+	PhotoService *PhotoService
+	Events       *events.Bus
+
+	mu      sync.Mutex
+	running map[uint]bool // Origens em sincronização neste processo
+}
+
+// NewMirrorService cria uma nova instância de MirrorService.
+func NewMirrorService(db *gorm.DB, ps *PhotoService, bus *events.Bus) *MirrorService {
+	return &MirrorService{
+		DB:           db,
+		PhotoService: ps,
+		Events:       bus,
+		running:      make(map[uint]bool),
+	}
+}
+
+// CreateSource valida e registra uma origem de espelhamento. A assinatura de um álbum compartilhado (tipo
+// share) cria também o álbum local, bloqueado, com o nome da origem.
+func (s *MirrorService) CreateSource(source *database.MirrorSource) error {
+	if err := validateMirrorSource(source); err != nil {
+		return err
+	}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if source.Kind == database.MirrorKindShare {
+			album := database.Album{Name: source.Name, Description: "Assinatura de " + source.URL, Locked: true}
+			if err := tx.Create(&album).Error; err != nil {
+				return fmt.Errorf("não foi possível criar o álbum da assinatura: %w", err)
+			}
+			source.AlbumID = &album.ID
+		}
+		return tx.Create(source).Error
+	})
+	if err != nil {
+		return fmt.Errorf("não foi possível registrar a origem: %w", err)
+	}
+	if source.AlbumID != nil {
+		s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": *source.AlbumID})
+	}
+	return nil
+}
+
+// ListSources lista as origens registradas.
+func (s *MirrorService) ListSources() ([]database.MirrorSource, error) {
+	var sources []database.MirrorSource
+	if result := s.DB.Order("name").Find(&sources); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar origens: %w", result.Error)
+	}
+	return sources, nil
+}
+
+// GetSource retorna uma origem.
+func (s *MirrorService) GetSource(id uint) (*database.MirrorSource, error) {
+	var source database.MirrorSource
+	if result := s.DB.First(&source, id); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar origem: %w", result.Error)
+	}
+	return &source, nil
+}
+
+// ObjectCounts conta os objetos processados da origem por situação (ver constantes MirrorObject*).
+func (s *MirrorService) ObjectCounts(id uint) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	result := s.DB.Model(&database.MirrorObject{}).Select("status, COUNT(*) AS count").
+		Where("source_id = ?", id).Group("status").Scan(&rows)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao contar objetos espelhados: %w", result.Error)
+	}
+	counts := map[string]int64{
+		database.MirrorObjectImported:  0,
+		database.MirrorObjectDuplicate: 0,
+		database.MirrorObjectFailed:    0,
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// DeleteSource remove a origem e o registro dos objetos processados. As fotos importadas permanecem; o álbum
+// de uma assinatura também, desbloqueado.
+func (s *MirrorService) DeleteSource(id uint) error {
+	source, err := s.GetSource(id)
+	if err != nil {
+		return err
+	}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("source_id = ?", id).Delete(&database.MirrorObject{}).Error; err != nil {
+			return err
+		}
+		if source.AlbumID != nil {
+			if err := tx.Model(&database.Album{}).Where("id = ?", *source.AlbumID).Update("locked", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(&database.MirrorSource{}, id).Error
+	})
+	if err != nil {
+		return err
+	}
+	if source.AlbumID != nil {
+		s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": *source.AlbumID})
+	}
+	return nil
+}
+
+// StartSync inicia a sincronização da origem em background. Retorna ErrMirrorRunning se ela já estiver em andamento.
+func (s *MirrorService) StartSync(id uint) (*database.MirrorSource, error) {
+	source, err := s.GetSource(id)
+	if err != nil {
+		return nil, err
+	}
+	if !s.launch(*source) {
+		return nil, ErrMirrorRunning
+	}
+	return source, nil
+}
+
+// RunDue inicia a sincronização das origens cujo intervalo já passou desde a última sincronização.
+func (s *MirrorService) RunDue() {
+	sources, err := s.ListSources()
+	if err != nil {
+		log.Printf("Espelhamento: %v\n", err)
+		return
+	}
+	now := time.Now()
+	for _, source := range sources {
+		if source.IntervalMinutes <= 0 {
+			continue
+		}
+		if source.LastSyncAt == nil || now.Sub(*source.LastSyncAt) >= time.Duration(source.IntervalMinutes)*time.Minute {
+			s.launch(source)
+		}
+	}
+}
+
+// StartScheduler verifica periodicamente as origens com sincronização pendente, até o contexto ser cancelado.
+func (s *MirrorService) StartScheduler(ctx context.Context, interval time.Duration) {
+	go func() {
+		s.RunDue()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.RunDue()
+			}
+		}
+	}()
+}
+
+// launch sincroniza a origem em uma goroutine, garantindo uma única sincronização por origem.
+func (s *MirrorService) launch(source database.MirrorSource) bool {
+	s.mu.Lock()
+	if s.running[source.ID] {
+		s.mu.Unlock()
+		return false
+	}
+	s.running[source.ID] = true
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, source.ID)
+			s.mu.Unlock()
+		}()
+
+		// A sincronização roda em segundo plano e não deve ser cancelada quando a requisição que a iniciou termina
+		if _, err := s.Sync(context.Background(), &source); err != nil {
+			log.Printf("Erro ao sincronizar a origem '%s': %v\n", source.Name, err)
+		}
+	}()
+	return true
+}
+
+// Sync lista a origem e importa os objetos novos ou substituídos desde a última sincronização. Nas
+// assinaturas de álbuns compartilhados, o álbum local acompanha as fotos listadas.
+// Falhas de objetos individuais são registradas e não interrompem a sincronização; falta de espaço
+// em disco, sim (os objetos restantes são processados na próxima sincronização).
+func (s *MirrorService) Sync(ctx context.Context, source *database.MirrorSource) (MirrorSyncResult, error) {
+	var result MirrorSyncResult
+	connector, err := mirrorConnector(source)
+	if err != nil {
+		return result, err
+	}
+
+	tempDir, err := os.MkdirTemp("", "photo-manager-mirror-")
+	if err != nil {
+		return result, fmt.Errorf("não foi possível criar diretório temporário: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	lastError := ""
+	listed := make(map[string]bool)
+	albumChanged := false
+	syncErr := connector.List(ctx, func(obj mirror.Object) error {
+		if !supportedImportExtensions[strings.ToLower(path.Ext(obj.Key))] {
+			return nil
+		}
+		result.Listed++
+		listed[obj.Key] = true
+
+		var seen database.MirrorObject
+		lookup := s.DB.WithContext(ctx).Where("source_id = ? AND key = ?", source.ID, obj.Key).Limit(1).Find(&seen)
+		if lookup.Error != nil {
+			return fmt.Errorf("erro ao consultar objetos espelhados: %w", lookup.Error)
+		}
+		if lookup.RowsAffected > 0 && seen.ETag == obj.ETag {
+			result.Unchanged++
+			return nil
+		}
+
+		record := database.MirrorObject{SourceID: source.ID, Key: obj.Key, ETag: obj.ETag, SyncedAt: time.Now()}
+		photo, err := s.importObject(ctx, connector, obj, tempDir, source.Name)
+		switch {
+		case errors.Is(err, storage.ErrInsufficientStorage), errors.Is(err, storage.ErrNoVolumeAvailable), ctx.Err() != nil:
+			// O objeto não é registrado, para ser processado na próxima sincronização
+			return fmt.Errorf("sincronização interrompida em '%s': %w", obj.Key, err)
+		case err == nil:
+			record.Status = database.MirrorObjectImported
+			result.Imported++
+		case errors.Is(err, ErrDuplicatePhoto):
+			record.Status = database.MirrorObjectDuplicate
+			result.Duplicates++
+		default:
+			record.Status = database.MirrorObjectFailed
+			record.Error = err.Error()
+			lastError = fmt.Sprintf("%s: %v", obj.Key, err)
+			result.Failed++
+			log.Printf("Espelhamento '%s': erro ao importar '%s': %v\n", source.Name, obj.Key, err)
+		}
+		if photo != nil {
+			record.PhotoID = &photo.ID
+			if source.AlbumID != nil {
+				added, err := addToMirrorAlbum(s.DB.WithContext(ctx), *source.AlbumID, photo.ID)
+				if err != nil {
+					return err
+				}
+				albumChanged = albumChanged || added
+			}
+		}
+
+		upsert := s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "source_id"}, {Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"e_tag", "status", "photo_id", "error", "synced_at"}),
+		}).Create(&record)
+		if upsert.Error != nil {
+			return fmt.Errorf("não foi possível registrar o objeto '%s': %w", obj.Key, upsert.Error)
+		}
+		return nil
+	})
+
+	if syncErr == nil && source.AlbumID != nil {
+		// Só com a listagem completa se sabe quais fotos saíram do álbum compartilhado
+		removed, err := s.pruneMirrorAlbum(ctx, source, listed)
+		if err != nil {
+			syncErr = err
+		}
+		albumChanged = albumChanged || removed > 0
+	}
+	if albumChanged {
+		if err := refreshAlbumDates(s.DB.WithContext(ctx), []uint{*source.AlbumID}); err != nil {
+			log.Printf("Espelhamento '%s': %v\n", source.Name, err)
+		}
+		s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": *source.AlbumID})
+	}
+
+	if syncErr != nil {
+		lastError = syncErr.Error()
+	}
+	finishedAt := time.Now()
+	s.DB.Model(source).Updates(map[string]interface{}{
+		"last_sync_at":  finishedAt,
+		"last_imported": result.Imported,
+		"last_skipped":  result.Duplicates,
+		"last_failed":   result.Failed,
+		"last_error":    lastError,
+	})
+
+	if syncErr != nil || result.Imported > 0 || result.Failed > 0 {
+		data := map[string]interface{}{
+			"mirror_id":  source.ID,
+			"name":       source.Name,
+			"imported":   result.Imported,
+			"duplicates": result.Duplicates,
+			"failed":     result.Failed,
+		}
+		if syncErr != nil {
+			data["error"] = syncErr.Error()
+		}
+		s.Events.Publish(events.TypeMirrorSynced, data)
+	}
+
+	log.Printf("Espelhamento '%s': %d listados, %d sem alterações, %d importados, %d duplicados, %d com falha\n",
+		source.Name, result.Listed, result.Unchanged, result.Imported, result.Duplicates, result.Failed)
+	return result, syncErr
+}
+
+// addToMirrorAlbum coloca a foto no álbum da assinatura, se ainda não estiver nele. O álbum é bloqueado para
+// os usuários, mas acompanha a origem.
+func addToMirrorAlbum(db *gorm.DB, albumID, photoID uint) (bool, error) {
+	var count int64
+	if err := db.Model(&database.AlbumPhoto{}).Where("album_id = ? AND photo_id = ?", albumID, photoID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("erro ao verificar as fotos do álbum da assinatura: %w", err)
+	}
+	if count > 0 {
+		return false, nil
+	}
+	if err := db.Create(&database.AlbumPhoto{AlbumID: albumID, PhotoID: photoID}).Error; err != nil {
+		return false, fmt.Errorf("não foi possível adicionar a foto %d ao álbum da assinatura: %w", photoID, err)
+	}
+	return true, nil
+}
+
+// pruneMirrorAlbum tira do álbum da assinatura as fotos cujos objetos não foram listados (removidos do
+// álbum compartilhado), esquecendo os objetos: se voltarem, são processados de novo. As fotos continuam na
+// biblioteca. Retorna quantas fotos saíram do álbum.
+func (s *MirrorService) pruneMirrorAlbum(ctx context.Context, source *database.MirrorSource, listed map[string]bool) (int, error) {
+	var objects []database.MirrorObject
+	if err := s.DB.WithContext(ctx).Where("source_id = ?", source.ID).Find(&objects).Error; err != nil {
+		return 0, fmt.Errorf("erro ao consultar objetos espelhados: %w", err)
+	}
+
+	var goneIDs, photoIDs []uint
+	for _, obj := range objects {
+		if listed[obj.Key] {
+			continue
+		}
+		goneIDs = append(goneIDs, obj.ID)
+		if obj.PhotoID != nil {
+			photoIDs = append(photoIDs, *obj.PhotoID)
+		}
+	}
+	if len(goneIDs) == 0 {
+		return 0, nil
+	}
+
+	removed := 0
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(photoIDs) > 0 {
+			result := tx.Unscoped().Where("album_id = ? AND photo_id IN ?", *source.AlbumID, photoIDs).Delete(&database.AlbumPhoto{})
+			if result.Error != nil {
+				return fmt.Errorf("não foi possível remover fotos do álbum da assinatura: %w", result.Error)
+			}
+			removed = int(result.RowsAffected)
+		}
+		return tx.Delete(&database.MirrorObject{}, goneIDs).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	if removed > 0 {
+		log.Printf("Espelhamento '%s': %d foto(s) retirada(s) do álbum compartilhado também saíram do álbum local\n", source.Name, removed)
+	}
+	return removed, nil
+}
+
+// importObject baixa o objeto para o diretório temporário e o importa com o nome original, registrando a
+// origem espelhada e a chave do objeto como procedência.
+func (s *MirrorService) importObject(ctx context.Context, connector mirror.Source, obj mirror.Object, tempDir, sourceName string) (*database.Photo, error) {
+	if err := s.PhotoService.FileManager.CheckCapacity(obj.Size); err != nil {
+		return nil, err
+	}
+
+	reader, err := connector.Open(ctx, obj.Key)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível baixar o objeto: %w", err)
+	}
+	defer reader.Close()
+
+	tempPath := filepath.Join(tempDir, path.Base(obj.Key))
+	dst, err := os.Create(tempPath)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível criar arquivo temporário: %w", err)
+	}
+	defer os.Remove(tempPath)
+
+	_, err = io.Copy(dst, contextReader{ctx: ctx, r: reader})
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível baixar o objeto: %w", err)
+	}
+
+	origin := PhotoOrigin{Source: database.SourceMirror, Device: sourceName, Detail: obj.Key}
+	return s.PhotoService.ImportPhotoFromPath(ctx, tempPath, false, origin)
+}
+
+// mirrorConnector cria o conector da origem.
+func mirrorConnector(source *database.MirrorSource) (mirror.Source, error) {
+	switch source.Kind {
+	case database.MirrorKindDir:
+		return mirror.DirSource{Root: source.Path}, nil
+	case database.MirrorKindS3:
+		return &mirror.S3Source{
+			Endpoint:  source.Endpoint,
+			Region:    source.Region,
+			Bucket:    source.Bucket,
+			Prefix:    source.Prefix,
+			AccessKey: source.AccessKey,
+			SecretKey: source.SecretKey,
+		}, nil
+	case database.MirrorKindShare:
+		return mirror.ShareSource{URL: source.URL}, nil
+	default:
+		return nil, i18n.NewError(i18n.CodeMirrorKindInvalid, source.Kind, database.MirrorKindS3, database.MirrorKindDir, database.MirrorKindShare)
+	}
+}
+
+// validateMirrorSource verifica os campos obrigatórios de cada tipo de origem.
+func validateMirrorSource(source *database.MirrorSource) error {
+	source.Name = strings.TrimSpace(source.Name)
+	if source.Name == "" {
+		return i18n.NewError(i18n.CodeMirrorNameRequired)
+	}
+	if source.IntervalMinutes < 0 {
+		return i18n.NewError(i18n.CodeMirrorIntervalInvalid)
+	}
+	switch source.Kind {
+	case database.MirrorKindDir:
+		absPath, err := filepath.Abs(source.Path)
+		if source.Path == "" || err != nil {
+			return i18n.NewError(i18n.CodeMirrorPathRequired)
+		}
+		info, err := os.Stat(absPath)
+		if err != nil || !info.IsDir() {
+			return i18n.NewError(i18n.CodeImportNotDirectory, absPath)
+		}
+		source.Path = absPath
+	case database.MirrorKindS3:
+		if source.Bucket == "" {
+			return i18n.NewError(i18n.CodeMirrorBucketRequired)
+		}
+		if (source.AccessKey == "") != (source.SecretKey == "") {
+			return i18n.NewError(i18n.CodeMirrorCredentialsIncomplete)
+		}
+	case database.MirrorKindShare:
+		source.URL = strings.TrimRight(strings.TrimSpace(source.URL), "/")
+		u, err := url.Parse(source.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || !strings.Contains(u.Path, "/s/") {
+			return i18n.NewError(i18n.CodeMirrorShareURLInvalid)
+		}
+	default:
+		return i18n.NewError(i18n.CodeMirrorKindInvalid, source.Kind, database.MirrorKindS3, database.MirrorKindDir, database.MirrorKindShare)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/rules"
+	"photo-manager/internal/video"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// photoRuleFacts monta os campos das regras de organização a partir dos metadados da foto.
+func photoRuleFacts(photo *database.Photo) rules.Facts {
+	facts := rules.Facts{
+		"make":         photo.CameraMake,
+		"model":        photo.CameraModel,
+		"camera":       strings.TrimSpace(photo.CameraMake + " " + photo.CameraModel),
+		"lens":         photo.LensModel,
+		"filename":     photo.Filename,
+		"ext":          strings.ToLower(strings.TrimPrefix(filepath.Ext(photo.Filename), ".")),
+		"mime":         photo.MimeType,
+		"source":       photo.Source,
+		"device":       photo.SourceDevice,
+		"year":         0.0,
+		"month":        0.0,
+		"day":          0.0,
+		"hour":         0.0,
+		"width":        float64(photo.Width),
+		"height":       float64(photo.Height),
+		"megapixels":   float64(photo.Width) * float64(photo.Height) / 1_000_000,
+		"size_mb":      float64(photo.FileSize) / (1 << 20),
+		"iso":          0.0,
+		"focal_length": 0.0,
+		"gps":          photo.Latitude != nil && photo.Longitude != nil,
+		"dated":        photo.ExifDate != nil,
+		"video":        video.IsVideo(photo.MimeType),
+	}
+	if photo.ExifDate != nil {
+		facts["year"] = float64(photo.ExifDate.Year())
+		facts["month"] = float64(photo.ExifDate.Month())
+		facts["day"] = float64(photo.ExifDate.Day())
+		facts["hour"] = float64(photo.ExifDate.Hour())
+	}
+	if photo.ISO != nil {
+		facts["iso"] = float64(*photo.ISO)
+	}
+	if photo.FocalLength != nil {
+		facts["focal_length"] = *photo.FocalLength
+	}
+	return facts
+}
+
+// applyRuleActions aplica à foto recém-criada as tags e os álbuns definidos pelas regras, na transação da
+// ingestão. Álbuns inexistentes são criados; álbuns bloqueados são ignorados. Retorna os álbuns alterados.
+func applyRuleActions(tx *gorm.DB, photo *database.Photo, result rules.Result) ([]uint, error) {
+	if len(result.Tags) > 0 {
+		names := make([]string, 0, len(result.Tags))
+		for _, name := range normalizeTagNames(result.Tags) {
+			tag, err := findOrCreateTag(tx, name)
+			if err != nil {
+				return nil, err
+			}
+			if err := tx.Create(&database.PhotoTag{PhotoID: photo.ID, TagID: tag.ID}).Error; err != nil {
+				return nil, fmt.Errorf("não foi possível associar a tag '%s': %w", tag.Name, err)
+			}
+			names = append(names, tag.Name)
+		}
+		photo.Tags = strings.Join(names, ",")
+		if err := tx.Model(photo).UpdateColumn("tags", photo.Tags).Error; err != nil {
+			return nil, fmt.Errorf("não foi possível gravar as tags da foto: %w", err)
+		}
+	}
+
+	var albumIDs []uint
+	for _, name := range result.Albums {
+		var album database.Album
+		found := tx.Where("LOWER(name) = LOWER(?)", name).Limit(1).Find(&album)
+		if found.Error != nil {
+			return nil, fmt.Errorf("erro ao buscar o álbum '%s': %w", name, found.Error)
+		}
+		if found.RowsAffected == 0 {
+			album = database.Album{Name: name}
+			if err := tx.Create(&album).Error; err != nil {
+				return nil, fmt.Errorf("não foi possível criar o álbum '%s': %w", name, err)
+			}
+		} else if album.Locked {
+			log.Printf("Regras de organização: álbum '%s' bloqueado; foto '%s' não incluída\n", album.Name, photo.Filename)
+			continue
+		}
+		if err := tx.Create(&database.AlbumPhoto{AlbumID: album.ID, PhotoID: photo.ID}).Error; err != nil {
+			return nil, fmt.Errorf("não foi possível incluir a foto no álbum '%s': %w", album.Name, err)
+		}
+		albumIDs = append(albumIDs, album.ID)
+	}
+	return albumIDs, nil
+}
+
+// PreviewRules avalia as regras para uma foto já existente, sem aplicar nenhuma ação. Permite testar um
+// script antes de configurá-lo em RULES_FILE.
+func (s *PhotoService) PreviewRules(ctx context.Context, set *rules.Set, photoID uint) (*rules.Result, error) {
+	photo, err := s.GetPhotoByID(ctx, photoID)
+	if err != nil {
+		return nil, err
+	}
+	result := set.Evaluate(photoRuleFacts(photo))
+	return &result, nil
+}
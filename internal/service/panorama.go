@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/exif"
+	"photo-manager/internal/thumbnail"
+	"photo-manager/internal/video"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// panoramaMinAspect é a proporção (largura/altura) a partir da qual uma foto sem GPano é tratada como
+	// panorama plano. Fotos comuns vão até 16:9 (1,78); os panoramas dos celulares passam de 3:1.
+	panoramaMinAspect = 2.5
+	// equirectangularMinWidth é a largura mínima de uma foto 2:1 sem GPano para ser tratada como 360°:
+	// câmeras 360 gravam de 5760x2880 para cima, e recortes 2:1 comuns raramente são tão largos.
+	equirectangularMinWidth = 4096
+	// panoramaThumbnailAspect é a proporção máxima da miniatura de um panorama plano.
+	panoramaThumbnailAspect = 2.0
+	// sphereThumbnailAspect é a proporção da miniatura de uma foto 360°, recortada da faixa do horizonte.
+	sphereThumbnailAspect = 16.0 / 9.0
+)
+
+// ProjectionAny é o valor de PhotoFilter.Projection que seleciona qualquer panorama.
+const ProjectionAny = "any"
+
+// gpanoProjections são as projeções GPano reconhecidas; as demais são tratadas pela proporção.
+var gpanoProjections = map[string]bool{
+	database.ProjectionEquirectangular: true,
+	database.ProjectionCylindrical:     true,
+}
+
+// detectProjection preenche a projeção e as medidas panorâmicas da foto a partir do arquivo em path: os
+// metadados GPano do XMP, quando presentes, ou a proporção de Width x Height. Falhas de leitura do XMP
+// apenas caem na detecção pela proporção.
+func detectProjection(path string, photo *database.Photo) {
+	photo.Projection = ""
+	photo.PanoFullWidth, photo.PanoFullHeight = 0, 0
+	photo.PanoCroppedWidth, photo.PanoCroppedHeight = 0, 0
+	photo.PanoCroppedLeft, photo.PanoCroppedTop = 0, 0
+	photo.PanoInitialHeading = nil
+	if video.IsVideo(photo.MimeType) {
+		return
+	}
+
+	xmp, err := exif.ReadXMP(path)
+	if err != nil {
+		log.Printf("Aviso: não foi possível ler o XMP de '%s': %v\n", photo.Filename, err)
+	}
+	if pano := exif.ParsePanorama(xmp); pano != nil && gpanoProjections[pano.ProjectionType] {
+		photo.Projection = pano.ProjectionType
+		photo.PanoFullWidth, photo.PanoFullHeight = pano.FullWidth, pano.FullHeight
+		photo.PanoCroppedWidth, photo.PanoCroppedHeight = pano.CroppedWidth, pano.CroppedHeight
+		photo.PanoCroppedLeft, photo.PanoCroppedTop = pano.CroppedLeft, pano.CroppedTop
+		photo.PanoInitialHeading = pano.InitialHeading
+		return
+	}
+
+	if photo.Width <= 0 || photo.Height <= 0 {
+		return
+	}
+	aspect := float64(photo.Width) / float64(photo.Height)
+	switch {
+	case math.Abs(aspect-2) < 0.01 && photo.Width >= equirectangularMinWidth:
+		photo.Projection = database.ProjectionEquirectangular
+	case aspect >= panoramaMinAspect:
+		photo.Projection = database.ProjectionPanorama
+	}
+}
+
+// projectionColumns são as colunas gravadas por detectProjection, para atualizar fotos já cadastradas.
+func projectionColumns(photo *database.Photo) map[string]interface{} {
+	return map[string]interface{}{
+		"projection":           photo.Projection,
+		"pano_full_width":      photo.PanoFullWidth,
+		"pano_full_height":     photo.PanoFullHeight,
+		"pano_cropped_width":   photo.PanoCroppedWidth,
+		"pano_cropped_height":  photo.PanoCroppedHeight,
+		"pano_cropped_left":    photo.PanoCroppedLeft,
+		"pano_cropped_top":     photo.PanoCroppedTop,
+		"pano_initial_heading": photo.PanoInitialHeading,
+	}
+}
+
+// thumbnailCrop retorna o recorte da miniatura de um panorama, ou nil para fotos comuns. A miniatura de uma
+// foto 360° mostra a faixa do horizonte, sem os polos distorcidos; a de um panorama plano, o centro.
+func thumbnailCrop(photo *database.Photo) *thumbnail.Crop {
+	switch photo.Projection {
+	case database.ProjectionEquirectangular:
+		return &thumbnail.Crop{Aspect: sphereThumbnailAspect, Band: 0.5}
+	case database.ProjectionCylindrical, database.ProjectionPanorama:
+		return &thumbnail.Crop{Aspect: panoramaThumbnailAspect}
+	}
+	return nil
+}
+
+// ProjectionReport é o resultado da detecção de panoramas nas fotos já cadastradas.
+type ProjectionReport struct {
+	Scanned int    // Fotos cujo arquivo foi lido
+	Updated []uint // Fotos com a projeção alterada (as miniaturas delas são geradas de novo)
+	Missing []uint // Fotos sem arquivo acessível
+}
+
+// DetectProjections reavalia a projeção das fotos já cadastradas (anteriores à detecção de panoramas ou
+// alteradas fora da aplicação). As fotos que mudam têm a miniatura descartada, para ser gerada de novo com
+// o recorte adequado.
+func (s *PhotoService) DetectProjections(ctx context.Context) (*ProjectionReport, error) {
+	report := &ProjectionReport{Updated: []uint{}, Missing: []uint{}}
+	var photos []database.Photo
+	err := s.DB.WithContext(ctx).Model(&database.Photo{}).Where("mime_type NOT LIKE ?", "video/%").
+		FindInBatches(&photos, 200, func(tx *gorm.DB, batch int) error {
+			for i := range photos {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if err := s.redetectProjection(ctx, &photos[i], report); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao detectar panoramas: %w", err)
+	}
+	return report, nil
+}
+
+// redetectProjection reavalia a projeção de uma foto, gravando-a se mudou.
+func (s *PhotoService) redetectProjection(ctx context.Context, photo *database.Photo, report *ProjectionReport) error {
+	path := photo.StoredPath
+	if photo.OriginalPath != "" {
+		if _, err := os.Stat(photo.OriginalPath); err == nil {
+			path = photo.OriginalPath // O original completo tem o XMP intacto
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		report.Missing = append(report.Missing, photo.ID)
+		return nil
+	}
+	report.Scanned++
+
+	detected := *photo
+	detectProjection(path, &detected)
+	if sameProjection(photo, &detected) {
+		return nil
+	}
+
+	updates := projectionColumns(&detected)
+	updates["thumbnail_path"] = ""
+	if err := s.DB.WithContext(ctx).Model(photo).Updates(updates).Error; err != nil {
+		return fmt.Errorf("não foi possível gravar a projeção da foto %d: %w", photo.ID, err)
+	}
+	if photo.ThumbnailPath != "" {
+		os.Remove(photo.ThumbnailPath)
+	}
+	report.Updated = append(report.Updated, photo.ID)
+	s.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": photo.ID})
+	return nil
+}
+
+// sameProjection indica se as duas fotos têm a mesma projeção e as mesmas medidas panorâmicas.
+func sameProjection(a, b *database.Photo) bool {
+	sameHeading := (a.PanoInitialHeading == nil) == (b.PanoInitialHeading == nil) &&
+		(a.PanoInitialHeading == nil || *a.PanoInitialHeading == *b.PanoInitialHeading)
+	return sameHeading && a.Projection == b.Projection &&
+		a.PanoFullWidth == b.PanoFullWidth && a.PanoFullHeight == b.PanoFullHeight &&
+		a.PanoCroppedWidth == b.PanoCroppedWidth && a.PanoCroppedHeight == b.PanoCroppedHeight &&
+		a.PanoCroppedLeft == b.PanoCroppedLeft && a.PanoCroppedTop == b.PanoCroppedTop
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// maxAnnotationTextLength limita o texto de uma anotação.
+const maxAnnotationTextLength = 1000
+
+// ErrAnnotationNotFound indica uma anotação inexistente (ou de outra foto).
+var ErrAnnotationNotFound = i18n.NewError(i18n.CodeAnnotationNotFound)
+
+// ErrAnnotationRegionInvalid indica coordenadas fora da imagem ou uma região vazia.
+var ErrAnnotationRegionInvalid = i18n.NewError(i18n.CodeAnnotationRegionInvalid)
+
+// ErrAnnotationTextRequired indica uma anotação sem texto ou com texto longo demais.
+var ErrAnnotationTextRequired = i18n.NewError(i18n.CodeAnnotationTextRequired, maxAnnotationTextLength)
+
+// ErrAnnotationKindInvalid indica um tipo de anotação desconhecido.
+var ErrAnnotationKindInvalid = i18n.NewError(i18n.CodeAnnotationKindInvalid)
+
+// AnnotationRegion é a região retangular de uma anotação, em frações da largura e da altura da imagem.
+type AnnotationRegion struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+// validate verifica se a região não é vazia e está dentro da imagem.
+func (r AnnotationRegion) validate() error {
+	if r.X < 0 || r.Y < 0 || r.Width <= 0 || r.Height <= 0 || r.X+r.Width > 1 || r.Y+r.Height > 1 {
+		return ErrAnnotationRegionInvalid
+	}
+	return nil
+}
+
+// PhotoAnnotationService gerencia as anotações de regiões das fotos.
+type PhotoAnnotationService struct {
+	DB     *gorm.DB
+	Events *events.Bus
+}
+
+// NewPhotoAnnotationService cria uma nova instância de PhotoAnnotationService.
+func NewPhotoAnnotationService(db *gorm.DB, bus *events.Bus) *PhotoAnnotationService {
+	return &PhotoAnnotationService{
+		DB:     db,
+		Events: bus,
+	}
+}
+
+// ListAnnotations retorna as anotações de uma foto, na ordem em que foram criadas.
+func (s *PhotoAnnotationService) ListAnnotations(ctx context.Context, photoID uint) ([]database.PhotoAnnotation, error) {
+	if err := s.ensurePhoto(ctx, photoID); err != nil {
+		return nil, err
+	}
+	annotations := []database.PhotoAnnotation{}
+	if err := s.DB.WithContext(ctx).Where("photo_id = ?", photoID).Order("id").Find(&annotations).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar as anotações da foto: %w", err)
+	}
+	return annotations, nil
+}
+
+// CreateAnnotation anota uma região da foto; o tipo vazio cria uma nota. Fotos bloqueadas não aceitam
+// anotações.
+func (s *PhotoAnnotationService) CreateAnnotation(ctx context.Context, photoID uint, region AnnotationRegion, text, kind string) (*database.PhotoAnnotation, error) {
+	text, err := annotationText(text)
+	if err != nil {
+		return nil, err
+	}
+	if kind == "" {
+		kind = database.AnnotationKindNote
+	}
+	if !validAnnotationKind(kind) {
+		return nil, ErrAnnotationKindInvalid
+	}
+	if err := region.validate(); err != nil {
+		return nil, err
+	}
+	if err := s.ensureEditable(ctx, photoID); err != nil {
+		return nil, err
+	}
+
+	annotation := database.PhotoAnnotation{PhotoID: photoID, X: region.X, Y: region.Y, Width: region.Width, Height: region.Height, Text: text, Kind: kind}
+	if err := s.DB.WithContext(ctx).Create(&annotation).Error; err != nil {
+		return nil, fmt.Errorf("não foi possível gravar a anotação: %w", err)
+	}
+	s.publish(photoID)
+	return &annotation, nil
+}
+
+// UpdateAnnotation substitui a região e o texto de uma anotação da foto; o tipo vazio mantém o anterior.
+func (s *PhotoAnnotationService) UpdateAnnotation(ctx context.Context, photoID, annotationID uint, region AnnotationRegion, text, kind string) (*database.PhotoAnnotation, error) {
+	text, err := annotationText(text)
+	if err != nil {
+		return nil, err
+	}
+	if kind != "" && !validAnnotationKind(kind) {
+		return nil, ErrAnnotationKindInvalid
+	}
+	if err := region.validate(); err != nil {
+		return nil, err
+	}
+	annotation, err := s.findAnnotation(ctx, photoID, annotationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureEditable(ctx, photoID); err != nil {
+		return nil, err
+	}
+
+	annotation.X, annotation.Y, annotation.Width, annotation.Height, annotation.Text = region.X, region.Y, region.Width, region.Height, text
+	if kind != "" {
+		annotation.Kind = kind
+	}
+	if err := s.DB.WithContext(ctx).Save(annotation).Error; err != nil {
+		return nil, fmt.Errorf("não foi possível gravar a anotação: %w", err)
+	}
+	s.publish(photoID)
+	return annotation, nil
+}
+
+// DeleteAnnotation remove uma anotação da foto.
+func (s *PhotoAnnotationService) DeleteAnnotation(ctx context.Context, photoID, annotationID uint) error {
+	annotation, err := s.findAnnotation(ctx, photoID, annotationID)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureEditable(ctx, photoID); err != nil {
+		return err
+	}
+	if err := s.DB.WithContext(ctx).Unscoped().Delete(annotation).Error; err != nil {
+		return fmt.Errorf("não foi possível remover a anotação: %w", err)
+	}
+	s.publish(photoID)
+	return nil
+}
+
+// findAnnotation busca uma anotação da foto, retornando ErrAnnotationNotFound se ela não existir ou for de
+// outra foto.
+func (s *PhotoAnnotationService) findAnnotation(ctx context.Context, photoID, annotationID uint) (*database.PhotoAnnotation, error) {
+	var annotation database.PhotoAnnotation
+	result := s.DB.WithContext(ctx).Where("id = ? AND photo_id = ?", annotationID, photoID).Limit(1).Find(&annotation)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar a anotação: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrAnnotationNotFound
+	}
+	return &annotation, nil
+}
+
+// ensurePhoto verifica se a foto existe, retornando gorm.ErrRecordNotFound caso contrário.
+func (s *PhotoAnnotationService) ensurePhoto(ctx context.Context, photoID uint) error {
+	var count int64
+	if err := s.DB.WithContext(ctx).Model(&database.Photo{}).Where("id = ?", photoID).Count(&count).Error; err != nil {
+		return fmt.Errorf("erro ao buscar foto: %w", err)
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ensureEditable verifica se a foto existe e não está bloqueada.
+func (s *PhotoAnnotationService) ensureEditable(ctx context.Context, photoID uint) error {
+	if err := s.ensurePhoto(ctx, photoID); err != nil {
+		return err
+	}
+	return checkPhotoUnlocked(s.DB.WithContext(ctx), photoID)
+}
+
+// publish avisa que as anotações da foto mudaram.
+func (s *PhotoAnnotationService) publish(photoID uint) {
+	s.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": photoID, "annotations": true})
+}
+
+// annotationText normaliza o texto de uma anotação, que é obrigatório.
+func annotationText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || len([]rune(text)) > maxAnnotationTextLength {
+		return "", ErrAnnotationTextRequired
+	}
+	return text, nil
+}
+
+// validAnnotationKind indica se o tipo de anotação é conhecido.
+func validAnnotationKind(kind string) bool {
+	switch kind {
+	case database.AnnotationKindNote, database.AnnotationKindFace, database.AnnotationKindPet:
+		return true
+	}
+	return false
+}
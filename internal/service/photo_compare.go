@@ -0,0 +1,98 @@
+package service
+
+import (
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/thumbnail"
+	"reflect"
+	"time"
+)
+
+// FieldDiff compara um campo de duas fotos.
+type FieldDiff struct {
+	Field string
+	A, B  interface{}
+	Same  bool
+}
+
+// PhotoComparison é a comparação lado a lado de duas fotos, para ajudar a decidir qual cópia manter.
+type PhotoComparison struct {
+	A, B      *database.Photo
+	Fields    []FieldDiff
+	Visual    thumbnail.Comparison
+	KeepID    uint   // Foto que a política de deduplicação manteria
+	KeepBy    string // Política usada na sugestão (DedupeKeep*)
+	Identical bool   // Mesmo conteúdo de arquivo (hash igual)
+}
+
+// ComparePhotos compara os metadados e as imagens de duas fotos. A comparação visual usa as miniaturas
+// (thumbA e thumbB), suficientes para medir a semelhança e alinhar as imagens sem decodificar os originais.
+func (s *PhotoService) ComparePhotos(a, b *database.Photo, thumbA, thumbB, policy string) (*PhotoComparison, error) {
+	imgA, _, err := thumbnail.Decode(thumbA, 0)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível ler a miniatura da foto %d: %w", a.ID, err)
+	}
+	imgB, _, err := thumbnail.Decode(thumbB, 0)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível ler a miniatura da foto %d: %w", b.ID, err)
+	}
+
+	return &PhotoComparison{
+		A:         a,
+		B:         b,
+		Fields:    photoFieldDiffs(a, b),
+		Visual:    thumbnail.Compare(imgA, imgB),
+		KeepID:    chooseKeeper([]database.Photo{*a, *b}, policy).ID,
+		KeepBy:    policy,
+		Identical: a.Hash == b.Hash,
+	}, nil
+}
+
+// photoFieldDiffs lista os metadados relevantes para escolher entre duas cópias.
+func photoFieldDiffs(a, b *database.Photo) []FieldDiff {
+	fields := []FieldDiff{
+		{Field: "filename", A: a.Filename, B: b.Filename},
+		{Field: "file_size", A: a.FileSize, B: b.FileSize},
+		{Field: "mime_type", A: a.MimeType, B: b.MimeType},
+		{Field: "width", A: a.Width, B: b.Width},
+		{Field: "height", A: a.Height, B: b.Height},
+		{Field: "megapixels", A: megapixels(a), B: megapixels(b)},
+		{Field: "exif_date", A: formatTime(a.ExifDate), B: formatTime(b.ExifDate)},
+		{Field: "upload_date", A: a.UploadDate.Format(time.RFC3339), B: b.UploadDate.Format(time.RFC3339)},
+		{Field: "camera_make", A: a.CameraMake, B: b.CameraMake},
+		{Field: "camera_model", A: a.CameraModel, B: b.CameraModel},
+		{Field: "lens_model", A: a.LensModel, B: b.LensModel},
+		{Field: "focal_length", A: a.FocalLength, B: b.FocalLength},
+		{Field: "iso", A: a.ISO, B: b.ISO},
+		{Field: "latitude", A: a.Latitude, B: b.Latitude},
+		{Field: "longitude", A: a.Longitude, B: b.Longitude},
+		{Field: "hash", A: a.Hash, B: b.Hash},
+		{Field: "pixel_hash", A: a.PixelHash, B: b.PixelHash},
+		{Field: "perceptual_hash", A: a.PerceptualHash, B: b.PerceptualHash},
+		{Field: "raw", A: isRawPhoto(*a), B: isRawPhoto(*b)},
+		{Field: "downscaled", A: a.Downscaled, B: b.Downscaled},
+		{Field: "favorite", A: a.Favorite, B: b.Favorite},
+		{Field: "tags", A: a.Tags, B: b.Tags},
+		{Field: "description", A: a.Description, B: b.Description},
+		{Field: "source", A: a.Source, B: b.Source},
+		{Field: "source_device", A: a.SourceDevice, B: b.SourceDevice},
+		{Field: "view_count", A: a.ViewCount, B: b.ViewCount},
+	}
+	for i := range fields {
+		fields[i].Same = reflect.DeepEqual(fields[i].A, fields[i].B)
+	}
+	return fields
+}
+
+// megapixels retorna a resolução da foto em megapixels, com uma casa decimal.
+func megapixels(photo *database.Photo) float64 {
+	return float64(photo.Width*photo.Height/100_000) / 10
+}
+
+// formatTime formata uma data opcional em RFC 3339 ("" se nula).
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
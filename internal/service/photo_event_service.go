@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"photo-manager/internal/database"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Critérios padrão para separar eventos (viagens, festas, passeios).
+const (
+	DefaultEventMaxGap      = 8 * time.Hour // Intervalo sem fotos que inicia um novo evento
+	DefaultEventMaxDistance = 100.0         // Deslocamento entre fotos consecutivas (km) que inicia um novo evento
+)
+
+// PhotoEventService agrupa as fotos em eventos sugeridos, com base no intervalo de tempo e na distância
+// entre fotos consecutivas. Os eventos são calculados a cada consulta e não são persistidos.
+type PhotoEventService struct {
+	DB           *gorm.DB
+	AlbumService *AlbumService
+
+	MaxGap      time.Duration // Intervalo máximo entre fotos do mesmo evento
+	MaxDistance float64       // Distância máxima (km) entre fotos consecutivas com GPS do mesmo evento
+}
+
+// NewPhotoEventService cria uma nova instância de PhotoEventService com os critérios padrão.
+func NewPhotoEventService(db *gorm.DB, albums *AlbumService) *PhotoEventService {
+	return &PhotoEventService{
+		DB:           db,
+		AlbumService: albums,
+		MaxGap:       DefaultEventMaxGap,
+		MaxDistance:  DefaultEventMaxDistance,
+	}
+}
+
+// PhotoEvent é um grupo de fotos consecutivas sugerido como evento.
+type PhotoEvent struct {
+	ID        uint      // ID da primeira foto do evento; estável enquanto o início do evento não mudar
+	Name      string    // Nome sugerido: local e período
+	Place     string    // Local aproximado (coordenadas médias), vazio se nenhuma foto tiver GPS
+	Start     time.Time // Data da primeira foto
+	End       time.Time // Data da última foto
+	PhotoIDs  []uint    // Fotos do evento, em ordem cronológica
+	Latitude  *float64  // Latitude média das fotos com GPS
+	Longitude *float64  // Longitude média das fotos com GPS
+}
+
+// eventPhoto contém apenas as colunas usadas no agrupamento.
+type eventPhoto struct {
+	ID         uint
+	ExifDate   *time.Time
+	UploadDate time.Time
+	Latitude   *float64
+	Longitude  *float64
+}
+
+// takenAt retorna a data da foto (EXIF, ou a data de upload na falta dela).
+func (p eventPhoto) takenAt() time.Time {
+	if p.ExifDate != nil {
+		return *p.ExifDate
+	}
+	return p.UploadDate
+}
+
+// DetectEvents agrupa as fotos visíveis em eventos, do mais recente para o mais antigo.
+// Um novo evento começa quando o intervalo entre fotos consecutivas excede MaxGap ou quando a distância
+// entre a foto e a última foto com GPS do evento excede MaxDistance. Eventos com menos de minPhotos fotos são omitidos.
+func (s *PhotoEventService) DetectEvents(ctx context.Context, minPhotos int) ([]PhotoEvent, error) {
+	var photos []eventPhoto
+	result := s.DB.WithContext(ctx).Model(&database.Photo{}).
+		Select("id, exif_date, upload_date, latitude, longitude").
+		Where("hidden = ? AND hide_from_timeline = ?", false, false).
+		Order("COALESCE(exif_date, upload_date) ASC").Order("id ASC").
+		Scan(&photos)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar fotos para detecção de eventos: %w", result.Error)
+	}
+
+	var events []PhotoEvent
+	var current []eventPhoto
+	var lastWithGPS *eventPhoto
+	flush := func() {
+		if len(current) > 0 && len(current) >= minPhotos {
+			events = append(events, newPhotoEvent(current))
+		}
+		current, lastWithGPS = nil, nil
+	}
+
+	for i := range photos {
+		photo := photos[i]
+		if len(current) > 0 {
+			gap := photo.takenAt().Sub(current[len(current)-1].takenAt())
+			jumped := lastWithGPS != nil && photo.Latitude != nil && photo.Longitude != nil &&
+				haversineKm(*lastWithGPS.Latitude, *lastWithGPS.Longitude, *photo.Latitude, *photo.Longitude) > s.MaxDistance
+			if gap > s.MaxGap || jumped {
+				flush()
+			}
+		}
+
+		current = append(current, photo)
+		if photo.Latitude != nil && photo.Longitude != nil {
+			lastWithGPS = &photos[i]
+		}
+	}
+	flush()
+
+	// Mais recente primeiro, como na linha do tempo
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, nil
+}
+
+// GetEvent retorna o evento identificado por id (ID da sua primeira foto).
+func (s *PhotoEventService) GetEvent(ctx context.Context, id uint) (*PhotoEvent, error) {
+	events, err := s.DetectEvents(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	for i := range events {
+		if events[i].ID == id {
+			return &events[i], nil
+		}
+	}
+	return nil, fmt.Errorf("erro ao buscar evento: %w", gorm.ErrRecordNotFound)
+}
+
+// PromoteEvent cria um álbum com as fotos do evento. Sem nome, usa o nome sugerido do evento.
+func (s *PhotoEventService) PromoteEvent(ctx context.Context, id uint, name, description string) (*database.Album, error) {
+	event, err := s.GetEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = event.Name
+	}
+	return s.AlbumService.CreateAlbumWithPhotos(name, description, event.PhotoIDs)
+}
+
+// newPhotoEvent monta o evento a partir das suas fotos, em ordem cronológica.
+func newPhotoEvent(photos []eventPhoto) PhotoEvent {
+	event := PhotoEvent{
+		ID:       photos[0].ID,
+		Start:    photos[0].takenAt(),
+		End:      photos[len(photos)-1].takenAt(),
+		PhotoIDs: make([]uint, 0, len(photos)),
+	}
+
+	var latSum, longSum float64
+	located := 0
+	for _, photo := range photos {
+		event.PhotoIDs = append(event.PhotoIDs, photo.ID)
+		if photo.Latitude != nil && photo.Longitude != nil {
+			latSum += *photo.Latitude
+			longSum += *photo.Longitude
+			located++
+		}
+	}
+	if located > 0 {
+		lat, long := latSum/float64(located), longSum/float64(located)
+		event.Latitude, event.Longitude = &lat, &long
+		event.Place = fmt.Sprintf("%.2f, %.2f", lat, long)
+	}
+
+	event.Name = formatDateRange(event.Start, event.End)
+	if event.Place != "" {
+		event.Name = fmt.Sprintf("%s · %s", event.Place, event.Name)
+	}
+	return event
+}
+
+// formatDateRange formata um período no padrão brasileiro (ex: "12/03/2024" ou "12/03/2024 – 15/03/2024").
+func formatDateRange(start, end time.Time) string {
+	const layout = "02/01/2006"
+	if start.Format(layout) == end.Format(layout) {
+		return start.Format(layout)
+	}
+	return fmt.Sprintf("%s – %s", start.Format(layout), end.Format(layout))
+}
+
+// haversineKm calcula a distância, em quilômetros, entre dois pontos da superfície terrestre.
+func haversineKm(lat1, long1, lat2, long2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLong := toRad(long2 - long1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLong/2)*math.Sin(dLong/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxRelationGraphPhotos limita quantas fotos a navegação por relações percorre, para que uma cadeia
+	// muito longa (ou um erro de ligação em massa) não carregue a biblioteca inteira.
+	maxRelationGraphPhotos = 500
+	// maxRelationNoteLength limita a observação de uma relação.
+	maxRelationNoteLength = 500
+)
+
+// ErrRelationSelf indica uma tentativa de relacionar uma foto a ela mesma.
+var ErrRelationSelf = i18n.NewError(i18n.CodeRelationSelf)
+
+// ErrRelationExists indica que as fotos já têm a relação informada.
+var ErrRelationExists = i18n.NewError(i18n.CodeRelationExists)
+
+// ErrRelationNotFound indica uma relação inexistente (ou que não envolve a foto informada).
+var ErrRelationNotFound = i18n.NewError(i18n.CodeRelationNotFound)
+
+// relationKinds são os tipos de relação aceitos, com o nome da relação vista a partir da foto de destino.
+var relationKinds = map[string]string{
+	database.RelationEditedFrom:   "edits",
+	database.RelationScanOf:       "scans",
+	database.RelationPanoramaPart: "panorama_parts",
+	database.RelationRelated:      "related",
+	database.RelationVersionOf:    "versions",
+}
+
+// relationKindNames lista os tipos de relação aceitos, em ordem alfabética, para as mensagens de erro.
+func relationKindNames() string {
+	names := make([]string, 0, len(relationKinds))
+	for kind := range relationKinds {
+		names = append(names, kind)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// PhotoRelationService gerencia as relações entre fotos (edição e original, quadros de um panorama,
+// digitalizações da mesma foto física).
+type PhotoRelationService struct {
+	DB     *gorm.DB
+	Events *events.Bus
+}
+
+// NewPhotoRelationService cria uma nova instância de PhotoRelationService.
+func NewPhotoRelationService(db *gorm.DB, bus *events.Bus) *PhotoRelationService {
+	return &PhotoRelationService{
+		DB:     db,
+		Events: bus,
+	}
+}
+
+// RelatedPhoto é uma relação vista a partir de uma foto: Kind é o tipo armazenado e Label o nome da
+// relação nessa direção (ex: a foto editada vê "edited_from", o RAW vê "edits").
+type RelatedPhoto struct {
+	RelationID uint
+	Kind       string
+	Label      string
+	Outgoing   bool // A foto consultada é a origem (FromPhotoID) da relação
+	Note       string
+	Photo      database.Photo
+}
+
+// RelationGraph são as fotos alcançáveis a partir de uma foto seguindo as relações em qualquer direção
+// (ex: todos os quadros de um panorama a partir de um deles), com as relações entre elas.
+type RelationGraph struct {
+	Photos    []database.Photo
+	Relations []database.PhotoRelation
+	Truncated bool // A navegação parou em maxRelationGraphPhotos fotos
+}
+
+// CreateRelation liga a foto fromID à foto toID com o tipo informado.
+func (s *PhotoRelationService) CreateRelation(ctx context.Context, fromID, toID uint, kind, note string) (*database.PhotoRelation, error) {
+	kind = strings.TrimSpace(strings.ToLower(kind))
+	if _, ok := relationKinds[kind]; !ok {
+		return nil, i18n.NewError(i18n.CodeRelationKindInvalid, kind, relationKindNames())
+	}
+	if fromID == toID {
+		return nil, ErrRelationSelf
+	}
+	note = strings.TrimSpace(note)
+	if len([]rune(note)) > maxRelationNoteLength {
+		note = string([]rune(note)[:maxRelationNoteLength])
+	}
+
+	db := s.DB.WithContext(ctx)
+	var count int64
+	if err := db.Model(&database.Photo{}).Where("id IN ?", []uint{fromID, toID}).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("erro ao verificar as fotos: %w", err)
+	}
+	if count != 2 {
+		return nil, i18n.NewError(i18n.CodePhotosNotFound)
+	}
+
+	// A relação "related" não tem direção: a ligação inversa já existente também conta como duplicada.
+	query := db.Model(&database.PhotoRelation{}).Where("kind = ?", kind)
+	if kind == database.RelationRelated {
+		query = query.Where("(from_photo_id = ? AND to_photo_id = ?) OR (from_photo_id = ? AND to_photo_id = ?)", fromID, toID, toID, fromID)
+	} else {
+		query = query.Where("from_photo_id = ? AND to_photo_id = ?", fromID, toID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("erro ao verificar as relações existentes: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrRelationExists
+	}
+
+	relation := database.PhotoRelation{FromPhotoID: fromID, ToPhotoID: toID, Kind: kind, Note: note}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&relation)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao salvar a relação: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrRelationExists
+	}
+
+	s.publish(relation)
+	return &relation, nil
+}
+
+// DeleteRelation remove uma relação da foto photoID (como origem ou destino).
+func (s *PhotoRelationService) DeleteRelation(ctx context.Context, photoID, relationID uint) error {
+	var relation database.PhotoRelation
+	result := s.DB.WithContext(ctx).
+		Where("id = ? AND (from_photo_id = ? OR to_photo_id = ?)", relationID, photoID, photoID).
+		Limit(1).Find(&relation)
+	if result.Error != nil {
+		return fmt.Errorf("erro ao buscar a relação: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrRelationNotFound
+	}
+	if err := s.DB.WithContext(ctx).Unscoped().Delete(&relation).Error; err != nil {
+		return fmt.Errorf("erro ao remover a relação: %w", err)
+	}
+
+	s.publish(relation)
+	return nil
+}
+
+// ListRelations retorna as relações diretas de uma foto, nas duas direções, opcionalmente de um só tipo.
+func (s *PhotoRelationService) ListRelations(ctx context.Context, photoID uint, kind string) ([]RelatedPhoto, error) {
+	if err := s.ensurePhoto(ctx, photoID); err != nil {
+		return nil, err
+	}
+	kind, err := relationKindFilter(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.DB.WithContext(ctx).Where("from_photo_id = ? OR to_photo_id = ?", photoID, photoID)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	var relations []database.PhotoRelation
+	if err := query.Order("id").Find(&relations).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar as relações: %w", err)
+	}
+
+	otherIDs := make([]uint, 0, len(relations))
+	for _, relation := range relations {
+		otherIDs = append(otherIDs, relatedPhotoID(relation, photoID))
+	}
+	photos, err := s.photosByID(ctx, otherIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	related := make([]RelatedPhoto, 0, len(relations))
+	for _, relation := range relations {
+		photo, ok := photos[relatedPhotoID(relation, photoID)]
+		if !ok {
+			continue
+		}
+		outgoing := relation.FromPhotoID == photoID
+		label := relation.Kind
+		if !outgoing {
+			label = relationKinds[relation.Kind]
+		}
+		related = append(related, RelatedPhoto{
+			RelationID: relation.ID,
+			Kind:       relation.Kind,
+			Label:      label,
+			Outgoing:   outgoing,
+			Note:       relation.Note,
+			Photo:      photo,
+		})
+	}
+	return related, nil
+}
+
+// RelationGraph percorre as relações a partir de uma foto, nas duas direções, e retorna o grupo de fotos
+// ligadas (ex: o RAW, suas edições e as digitalizações). Com kind, segue apenas esse tipo de relação
+// (ex: panorama_part reúne os quadros e o panorama montado).
+func (s *PhotoRelationService) RelationGraph(ctx context.Context, photoID uint, kind string) (*RelationGraph, error) {
+	if err := s.ensurePhoto(ctx, photoID); err != nil {
+		return nil, err
+	}
+	kind, err := relationKindFilter(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &RelationGraph{}
+	visited := map[uint]bool{photoID: true}
+	seenRelations := make(map[uint]bool)
+	order := []uint{photoID}
+	frontier := []uint{photoID}
+	for len(frontier) > 0 && !graph.Truncated {
+		query := s.DB.WithContext(ctx).Where("from_photo_id IN ? OR to_photo_id IN ?", frontier, frontier)
+		if kind != "" {
+			query = query.Where("kind = ?", kind)
+		}
+		var relations []database.PhotoRelation
+		if err := query.Order("id").Find(&relations).Error; err != nil {
+			return nil, fmt.Errorf("erro ao buscar as relações: %w", err)
+		}
+
+		var next []uint
+		for _, relation := range relations {
+			if seenRelations[relation.ID] {
+				continue
+			}
+			for _, id := range []uint{relation.FromPhotoID, relation.ToPhotoID} {
+				if visited[id] {
+					continue
+				}
+				if len(order) >= maxRelationGraphPhotos {
+					graph.Truncated = true
+					continue
+				}
+				visited[id] = true
+				order = append(order, id)
+				next = append(next, id)
+			}
+			// Só entram no grafo as relações cujas duas pontas foram incluídas.
+			if visited[relation.FromPhotoID] && visited[relation.ToPhotoID] {
+				seenRelations[relation.ID] = true
+				graph.Relations = append(graph.Relations, relation)
+			}
+		}
+		frontier = next
+	}
+
+	photos, err := s.photosByID(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range order {
+		if photo, ok := photos[id]; ok {
+			graph.Photos = append(graph.Photos, photo)
+		}
+	}
+	return graph, nil
+}
+
+// ensurePhoto verifica se a foto existe, retornando gorm.ErrRecordNotFound caso contrário.
+func (s *PhotoRelationService) ensurePhoto(ctx context.Context, photoID uint) error {
+	var count int64
+	if err := s.DB.WithContext(ctx).Model(&database.Photo{}).Where("id = ?", photoID).Count(&count).Error; err != nil {
+		return fmt.Errorf("erro ao buscar foto: %w", err)
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// photosByID carrega as fotos informadas, indexadas pelo ID.
+func (s *PhotoRelationService) photosByID(ctx context.Context, ids []uint) (map[uint]database.Photo, error) {
+	photos := make(map[uint]database.Photo, len(ids))
+	if len(ids) == 0 {
+		return photos, nil
+	}
+	var list []database.Photo
+	if err := s.DB.WithContext(ctx).Where("id IN ?", ids).Find(&list).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar as fotos relacionadas: %w", err)
+	}
+	for _, photo := range list {
+		photos[photo.ID] = photo
+	}
+	return photos, nil
+}
+
+// publish avisa que as relações das fotos envolvidas mudaram.
+func (s *PhotoRelationService) publish(relation database.PhotoRelation) {
+	for _, id := range []uint{relation.FromPhotoID, relation.ToPhotoID} {
+		s.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": id, "relations": true})
+	}
+}
+
+// relationKindFilter valida o filtro opcional de tipo de relação.
+func relationKindFilter(kind string) (string, error) {
+	kind = strings.TrimSpace(strings.ToLower(kind))
+	if kind == "" {
+		return "", nil
+	}
+	if _, ok := relationKinds[kind]; !ok {
+		return "", i18n.NewError(i18n.CodeRelationKindInvalid, kind, relationKindNames())
+	}
+	return kind, nil
+}
+
+// relatedPhotoID retorna a outra ponta da relação, vista a partir de photoID.
+func relatedPhotoID(relation database.PhotoRelation, photoID uint) uint {
+	if relation.FromPhotoID == photoID {
+		return relation.ToPhotoID
+	}
+	return relation.FromPhotoID
+}
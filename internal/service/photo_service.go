@@ -1,25 +1,63 @@
 package service
 
 import (
+	"context"
 	"crypto/md5" // Ou sha256, para um hash mais robusto
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"mime/multipart"
 	"os"
 	"path/filepath"
 	"photo-manager/internal/database"
+	"photo-manager/internal/events"
 	"photo-manager/internal/exif"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/rules"
 	"photo-manager/internal/storage"
+	"photo-manager/internal/thumbnail"
+	"photo-manager/internal/tracing"
+	"photo-manager/internal/validation"
+	"photo-manager/internal/video"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// ErrDuplicatePhoto indica que já existe uma foto com o mesmo hash na biblioteca.
+var ErrDuplicatePhoto = i18n.NewError(i18n.CodePhotoDuplicate)
+
+// ErrPhotoQuarantined indica que a foto está em quarentena e sua imagem não é mais processada.
+var ErrPhotoQuarantined = i18n.NewError(i18n.CodePhotoQuarantined)
+
+// ErrNotVideo indica uma operação de vídeo (ex: streaming) sobre um arquivo que não é vídeo.
+var ErrNotVideo = i18n.NewError(i18n.CodePhotoNotVideo)
+
+// ErrTranscodingDisabled indica que o vídeo precisa ser transcodificado, mas nenhum transcodificador foi configurado.
+var ErrTranscodingDisabled = i18n.NewError(i18n.CodeTranscodingDisabled)
+
+// ErrNotImage indica uma operação de imagem (ex: miniatura) sobre um arquivo que não é imagem, como um vídeo.
+var ErrNotImage = i18n.NewError(i18n.CodePhotoNotImage)
+
 // PhotoService define a interface para operações de foto.
 type PhotoService struct {
 	DB          *gorm.DB
 	FileManager *storage.FileManager
+	Events      *events.Bus          // Barramento para eventos de fotos (opcional)
+	Thumbnails  *thumbnail.Generator // Gerador de miniaturas
+	Validators  validation.Chain     // Validações aplicadas a todo arquivo antes da ingestão (upload, importação e reindexação)
+
+	QuarantineDir string            // Diretório para onde arquivos gerenciados em quarentena são movidos
+	Transcoder    *video.Transcoder // Transcodificação de vídeos para HLS (opcional; nil desativa)
+	Downscale     DownscalePolicy   // Redução de imagens muito grandes na ingestão (desativada por padrão)
+	Rules         *rules.Set        // Regras de organização avaliadas na ingestão (opcional; nil desativa)
+	Timeline      TimelineZone      // Fuso padrão da linha do tempo e dos filtros por ano e mês (TIMELINE_TIMEZONE)
 }
 
 // NewPhotoService cria uma nova instância de PhotoService.
@@ -27,104 +65,834 @@ func NewPhotoService(db *gorm.DB, fm *storage.FileManager) *PhotoService {
 	return &PhotoService{
 		DB:          db,
 		FileManager: fm,
+		Validators:  validation.NewChain(validation.DefaultOptions()),
 	}
 }
 
+// PhotoOrigin é a procedência de uma foto, gravada nos campos Source* da foto.
+type PhotoOrigin struct {
+	Source string // Canal de entrada (ver constantes database.Source*)
+	Device string // Dispositivo ou origem nomeada (opcional)
+	Detail string // Cliente, caminho ou chave de origem (opcional)
+}
+
 // UploadPhoto processa o upload de uma foto, extrai metadados e a salva.
-func (s *PhotoService) UploadPhoto(file *multipart.FileHeader) (*database.Photo, error) {
+// Se expectedSHA256 (hexadecimal) for informado, o SHA-256 do conteúdo recebido é comparado a ele antes da
+// ingestão; divergências (arquivo corrompido no caminho) são rejeitadas com o código checksum_mismatch.
+// Sem canal informado na procedência, a foto é registrada como upload.
+func (s *PhotoService) UploadPhoto(ctx context.Context, file *multipart.FileHeader, expectedSHA256 string, origin PhotoOrigin) (*database.Photo, error) {
 	uploadDate := time.Now()
+	if origin.Source == "" {
+		origin.Source = database.SourceUpload
+	}
+
+	// 0. Verifica se há espaço em disco antes de gravar qualquer coisa
+	if err := s.FileManager.CheckCapacity(file.Size); err != nil {
+		return nil, err
+	}
+
+	tempFilePath, err := s.receiveUpload(ctx, file, expectedSHA256)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tempFilePath) // Garante que o arquivo temporário seja removido
 
+	return s.ingestPhoto(ctx, ingestRequest{
+		SourcePath: tempFilePath,
+		Filename:   file.Filename,
+		FileSize:   file.Size,
+		UploadDate: uploadDate,
+		Origin:     origin,
+	})
+}
+
+// receiveUpload grava o arquivo enviado em um arquivo temporário, para extração EXIF e hash, conferindo o
+// SHA-256 informado pelo cliente. O chamador remove o arquivo retornado.
+func (s *PhotoService) receiveUpload(ctx context.Context, file *multipart.FileHeader, expectedSHA256 string) (string, error) {
 	// 1. Salva o arquivo temporariamente para extração EXIF e hash
 	tempDir := filepath.Join(os.TempDir(), "photo-manager-temp")
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return nil, fmt.Errorf("não foi possível criar diretório temporário: %w", err)
+		return "", fmt.Errorf("não foi possível criar diretório temporário: %w", err)
 	}
 
 	tempFilePath := filepath.Join(tempDir, file.Filename)
 	src, err := file.Open()
 	if err != nil {
-		return nil, fmt.Errorf("não foi possível abrir o arquivo enviado para processamento: %w", err)
+		return "", fmt.Errorf("não foi possível abrir o arquivo enviado para processamento: %w", err)
 	}
 	defer src.Close()
 
 	dstTemp, err := os.Create(tempFilePath)
 	if err != nil {
-		return nil, fmt.Errorf("não foi possível criar arquivo temporário: %w", err)
+		return "", fmt.Errorf("não foi possível criar arquivo temporário: %w", err)
 	}
 	defer dstTemp.Close()
-	defer os.Remove(tempFilePath) // Garante que o arquivo temporário seja removido
 
-	_, err = io.Copy(dstTemp, src)
+	// A cópia é interrompida se a requisição for cancelada (ex: o cliente desconectou).
+	// O SHA-256 é calculado durante a cópia, sem reler o arquivo.
+	digest := sha256.New()
+	_, err = io.Copy(io.MultiWriter(dstTemp, digest), contextReader{ctx: ctx, r: src})
 	if err != nil {
-		return nil, fmt.Errorf("não foi possível copiar o arquivo para o temporário: %w", err)
+		os.Remove(tempFilePath)
+		return "", fmt.Errorf("não foi possível copiar o arquivo para o temporário: %w", err)
 	}
 	dstTemp.Close() // Fecha o arquivo para garantir que todos os dados foram gravados antes de ler
 
-	// 2. Extrai metadados EXIF
-	exifData, err := exif.ExtractExifData(tempFilePath)
+	if expectedSHA256 != "" {
+		if actual := hex.EncodeToString(digest.Sum(nil)); !strings.EqualFold(actual, expectedSHA256) {
+			os.Remove(tempFilePath)
+			validationErr := validation.NewError("checksum", validation.CodeChecksumMismatch, actual, strings.ToLower(expectedSHA256))
+			s.Events.Publish(events.TypeIntegrityFailure, map[string]interface{}{
+				"filename": file.Filename,
+				"message":  fmt.Sprintf("Upload de '%s' rejeitado: %s.", file.Filename, validationErr.Message),
+			})
+			return "", validationErr
+		}
+	}
+	return tempFilePath, nil
+}
+
+// ImportPhotoFromPath importa uma foto que já está no sistema de arquivos local (ex: jobs de importação).
+// Com inPlace=false uma cópia é salva no armazenamento gerenciado; com inPlace=true a foto é apenas
+// indexada e continua sendo servida (somente leitura) a partir do local original.
+// Em nenhum dos casos o arquivo de origem é alterado. Sem canal informado na procedência, a foto é
+// registrada como importação, com o caminho importado como detalhe.
+func (s *PhotoService) ImportPhotoFromPath(ctx context.Context, filePath string, inPlace bool, origin PhotoOrigin) (*database.Photo, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("caminho inválido '%s': %w", filePath, err)
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível acessar o arquivo '%s': %w", absPath, err)
+	}
+
+	if origin.Source == "" {
+		origin = PhotoOrigin{Source: database.SourceImport, Detail: absPath}
+	}
+
+	modTime := info.ModTime()
+	return s.ingestPhoto(ctx, ingestRequest{
+		SourcePath:        absPath,
+		Filename:          filepath.Base(absPath),
+		FileSize:          info.Size(),
+		UploadDate:        time.Now(),
+		ManagedExternally: inPlace,
+		SourceModTime:     &modTime,
+		Origin:            origin,
+	})
+}
+
+// RefreshExternalPhoto verifica se o arquivo original de uma foto indexada no local mudou (tamanho ou data
+// de modificação) e, nesse caso, reextrai os metadados e atualiza o índice, registrando os valores anteriores
+// no histórico de alterações. Retorna true se a foto foi atualizada.
+func (s *PhotoService) RefreshExternalPhoto(ctx context.Context, photo *database.Photo) (bool, error) {
+	if !photo.ManagedExternally {
+		return false, fmt.Errorf("a foto %d não é indexada no local", photo.ID)
+	}
+
+	info, err := os.Stat(photo.StoredPath)
+	if err != nil {
+		return false, fmt.Errorf("não foi possível acessar o arquivo original '%s': %w", photo.StoredPath, err)
+	}
+
+	if photo.FileSize == info.Size() && photo.SourceModTime != nil && photo.SourceModTime.Equal(info.ModTime()) {
+		return false, nil
+	}
+	if err := s.adoptSourceFile(ctx, photo, info, metadataChangeReindex); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// adoptSourceFile adota a versão atual do arquivo original de uma foto indexada no local: reextrai os
+// metadados, descarta a miniatura e a prévia (geradas de novo a partir do arquivo) e registra os valores
+// anteriores em MetadataChange, com a origem informada.
+func (s *PhotoService) adoptSourceFile(ctx context.Context, photo *database.Photo, info os.FileInfo, source string) error {
+	modTime := info.ModTime()
+
+	// O arquivo alterado passa pelas mesmas validações de uma importação
+	file := &validation.File{Path: photo.StoredPath, Filename: photo.Filename, Size: info.Size()}
+	if err := s.Validators.Validate(ctx, file); err != nil {
+		return err
+	}
+
+	hash, err := calculateMD5Hash(photo.StoredPath)
+	if err != nil {
+		return fmt.Errorf("não foi possível calcular o hash da foto: %w", err)
+	}
+
+	var exifData *exif.ExifData
+	if !video.IsVideo(file.MimeType) {
+		if exifData, err = exif.ExtractExifData(photo.StoredPath); err != nil {
+			return fmt.Errorf("erro ao extrair dados EXIF: %w", err)
+		}
+	}
+	var exifDateTime *time.Time
+	var latitude, longitude *float64
+	var camera exif.ExifData // Campos de câmera e captura; vazios se não houver EXIF
+	if exifData != nil {
+		exifDateTime = exifData.DateTime
+		latitude, longitude = exifData.Latitude, exifData.Longitude
+		camera = *exifData
+	}
+
+	width, height := file.Width, file.Height
+	if width == 0 || height == 0 {
+		width, height, _ = thumbnail.Dimensions(photo.StoredPath)
+	}
+	if photo.ThumbnailPath != "" {
+		os.Remove(photo.ThumbnailPath)
+	}
+	if photo.PreviewPath != "" {
+		os.Remove(photo.PreviewPath)
+	}
+
+	updates := map[string]interface{}{
+		"hash":            hash,
+		"file_size":       info.Size(),
+		"exif_date":       exifDateTime,
+		"latitude":        latitude,
+		"longitude":       longitude,
+		"camera_make":     camera.CameraMake,
+		"camera_model":    camera.CameraModel,
+		"lens_model":      camera.LensModel,
+		"focal_length":    camera.FocalLength,
+		"iso":             camera.ISO,
+		"source_mod_time": modTime,
+		"width":           width,
+		"height":          height,
+		"thumbnail_path":  "", // A miniatura e a prévia antigas não correspondem mais ao arquivo
+		"preview_path":    "",
+
+		// O arquivo mudou e passou pela validação: sai da quarentena, se estava nela
+		"processing_failures": 0,
+		"quarantined":         false,
+		"quarantine_reason":   "",
+
+		// Fuso da captura, que acompanha a data EXIF
+		"time_zone_offset": camera.TimeZoneOffset,
+	}
+	// O arquivo novo pode ser (ou deixar de ser) um panorama, ou ter outras imagens auxiliares
+	detected := database.Photo{Filename: photo.Filename, MimeType: file.MimeType, Width: width, Height: height}
+	detectProjection(photo.StoredPath, &detected)
+	detectAuxiliaryAssets(photo.StoredPath, &detected)
+	for column, value := range projectionColumns(&detected) {
+		updates[column] = value
+	}
+	for column, value := range auxiliaryColumns(&detected) {
+		updates[column] = value
+	}
+	// Rostos nomeados em outro programa desde a versão anterior viram anotações; as existentes são mantidas
+	regions := readIngestRegions(photo.StoredPath, photo.Filename, file.MimeType)
+	previous := sourceVersionValues(photo)
+	err = s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(photo).Updates(updates).Error; err != nil {
+			return fmt.Errorf("não foi possível atualizar os metadados da foto: %w", err)
+		}
+		if _, err := createRegionAnnotations(tx, photo.ID, regions); err != nil {
+			return err
+		}
+		return recordSourceVersion(tx, photo, previous, source)
+	})
+	if err != nil {
+		return err
+	}
+	if err := refreshPhotoAlbumDates(s.DB.WithContext(ctx), []uint{photo.ID}); err != nil {
+		log.Printf("Aviso: %v\n", err)
+	}
+	s.savePreview(ctx, photo, camera.Thumbnail)
+
+	s.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": photo.ID})
+	return nil
+}
+
+// savePreview grava a miniatura embutida no EXIF como prévia da foto, disponível imediatamente, enquanto a
+// miniatura completa não é gerada. A prévia é opcional: falhas são apenas registradas no log.
+func (s *PhotoService) savePreview(ctx context.Context, photo *database.Photo, data []byte) {
+	if len(data) == 0 || s.Thumbnails == nil {
+		return
+	}
+	previewPath, err := s.Thumbnails.SavePreview(photo.ID, data)
+	if err != nil {
+		log.Printf("Aviso: prévia EXIF da foto %d ignorada: %v\n", photo.ID, err)
+		return
+	}
+	if err := s.DB.WithContext(ctx).Model(photo).UpdateColumn("preview_path", previewPath).Error; err != nil {
+		os.Remove(previewPath)
+		log.Printf("Erro ao salvar o caminho da prévia da foto %d: %v\n", photo.ID, err)
+		return
+	}
+	photo.PreviewPath = previewPath
+}
+
+// GetThumbnailPath retorna o caminho da miniatura da foto, gerando-a na primeira solicitação.
+// Falhas de decodificação são contabilizadas; após falhas repetidas a foto entra em quarentena.
+func (s *PhotoService) GetThumbnailPath(ctx context.Context, photo *database.Photo) (string, error) {
+	if photo.ThumbnailPath != "" {
+		if _, err := os.Stat(photo.ThumbnailPath); err == nil {
+			return photo.ThumbnailPath, nil
+		}
+	}
+	if photo.Quarantined {
+		return "", fmt.Errorf("%w: %s", ErrPhotoQuarantined, photo.QuarantineReason)
+	}
+	if video.IsVideo(photo.MimeType) {
+		return "", ErrNotImage
+	}
+
+	_, span := tracing.Start(ctx, "thumbnail.generate", tracing.Int("photo.id", int64(photo.ID)))
+	thumbPath, err := s.Thumbnails.GenerateCropped(photo.StoredPath, photo.ID, thumbnailCrop(photo))
+	span.Finish(err)
+	if errors.Is(err, thumbnail.ErrUnsupportedFormat) && photo.PreviewPath != "" {
+		// HEIC, TIFF ou RAW sem conversor externo: a prévia embutida pela câmera faz as vezes de miniatura
+		if _, statErr := os.Stat(photo.PreviewPath); statErr == nil {
+			return photo.PreviewPath, nil
+		}
+	}
+	if err != nil {
+		if errors.Is(err, thumbnail.ErrInvalidImage) {
+			if recordErr := s.recordProcessingFailure(ctx, photo, err); recordErr != nil {
+				log.Printf("Erro ao registrar falha de processamento da foto %d: %v\n", photo.ID, recordErr)
+			}
+		}
+		return "", fmt.Errorf("não foi possível gerar a miniatura: %w", err)
+	}
+
+	updates := map[string]interface{}{"thumbnail_path": thumbPath}
+	if photo.ProcessingFailures > 0 {
+		updates["processing_failures"] = 0
+	}
+	if result := s.DB.WithContext(ctx).Model(photo).Updates(updates); result.Error != nil {
+		return "", fmt.Errorf("não foi possível salvar o caminho da miniatura: %w", result.Error)
+	}
+	return thumbPath, nil
+}
+
+// GetRendition retorna uma versão da foto que os navegadores exibem (thumbnail.RenditionJPEG ou
+// thumbnail.RenditionWebP), para originais em HEIC, TIFF ou RAW. A conversão é feita na primeira
+// solicitação e guardada por hash, então um original alterado é convertido de novo. Sem conversor
+// externo (THUMBNAIL_RESIZER=vips), retorna thumbnail.ErrUnsupportedFormat.
+func (s *PhotoService) GetRendition(ctx context.Context, photo *database.Photo, format string) (string, error) {
+	if photo.Quarantined {
+		return "", fmt.Errorf("%w: %s", ErrPhotoQuarantined, photo.QuarantineReason)
+	}
+	if s.Thumbnails == nil {
+		return "", thumbnail.ErrUnsupportedFormat
+	}
+	ctx, span := tracing.Start(ctx, "thumbnail.rendition", tracing.Int("photo.id", int64(photo.ID)), tracing.String("rendition.format", format))
+	path, err := s.Thumbnails.Rendition(ctx, photo.StoredPath, renditionKey(photo), format)
+	span.Finish(err)
+	return path, err
+}
+
+// GetResizedImage retorna uma cópia JPEG da foto com o maior lado limitado a maxSize, gerada na primeira
+// solicitação e guardada por hash, como as versões convertidas.
+func (s *PhotoService) GetResizedImage(ctx context.Context, photo *database.Photo, maxSize int) (string, error) {
+	if photo.Quarantined {
+		return "", fmt.Errorf("%w: %s", ErrPhotoQuarantined, photo.QuarantineReason)
+	}
+	if video.IsVideo(photo.MimeType) {
+		return "", thumbnail.ErrUnsupportedFormat
+	}
+	if s.Thumbnails == nil {
+		return "", thumbnail.ErrUnsupportedFormat
+	}
+	ctx, span := tracing.Start(ctx, "thumbnail.resize", tracing.Int("photo.id", int64(photo.ID)), tracing.Int("image.max_size", int64(maxSize)))
+	path, err := s.Thumbnails.Resized(ctx, photo.StoredPath, renditionKey(photo), maxSize)
+	span.Finish(err)
+	return path, err
+}
+
+// renditionKey identifica a versão do arquivo da foto nos caches de versões convertidas e reduzidas.
+func renditionKey(photo *database.Photo) string {
+	if len(photo.Hash) > 16 {
+		return fmt.Sprintf("%d-%s", photo.ID, photo.Hash[:16])
+	}
+	return fmt.Sprintf("%d-%s", photo.ID, photo.Hash)
+}
+
+// GetStreamFile retorna o caminho de um arquivo HLS (playlist ou segmento) do vídeo, transcodificando-o
+// para H.264 na primeira solicitação (video.ErrTranscodePending enquanto a transcodificação não termina). O
+// cache é separado por hash, então um original alterado é retranscodificado.
+func (s *PhotoService) GetStreamFile(ctx context.Context, photo *database.Photo, name string) (string, error) {
+	if !video.IsVideo(photo.MimeType) {
+		return "", ErrNotVideo
+	}
+	if s.Transcoder == nil {
+		return "", ErrTranscodingDisabled
+	}
+
+	dir, err := s.Transcoder.HLS(ctx, fmt.Sprintf("%d_%s", photo.ID, photo.Hash), photo.StoredPath)
+	if err != nil {
+		return "", fmt.Errorf("não foi possível transcodificar o vídeo: %w", err)
+	}
+	return video.FilePath(dir, name)
+}
+
+// GetPhotoByID busca uma foto pelo seu ID.
+func (s *PhotoService) GetPhotoByID(ctx context.Context, id uint) (*database.Photo, error) {
+	var photo database.Photo
+	if result := s.DB.WithContext(ctx).First(&photo, id); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar foto: %w", result.Error)
+	}
+	return &photo, nil
+}
+
+// DeletePhoto remove uma foto da biblioteca.
+// Fotos no armazenamento gerenciado sempre têm o arquivo removido, pois a cópia pertence à aplicação.
+// Fotos indexadas no local (ManagedExternally) são apenas removidas do índice, a menos que deleteFile
+// seja true, caso em que o arquivo original também é apagado.
+func (s *PhotoService) DeletePhoto(ctx context.Context, id uint, deleteFile bool) error {
+	photo, err := s.GetPhotoByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := checkPhotoUnlocked(s.DB.WithContext(ctx), photo.ID); err != nil {
+		return err
+	}
+
+	err = s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return deletePhotoRecords(tx, []uint{photo.ID})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("erro ao extrair dados EXIF: %w", err)
+		return err
+	}
+
+	if err := s.removePhotoFiles(photo, deleteFile); err != nil {
+		return err
+	}
+	s.Events.Publish(events.TypePhotoDeleted, map[string]interface{}{"photo_id": photo.ID})
+	return nil
+}
+
+// deletePhotoRecords remove as fotos do banco de dados, com suas associações a álbuns, tags, metadados,
+// reações, relações, anotações, seleções e o histórico de alterações, e atualiza o período dos álbuns em que
+// estavam.
+func deletePhotoRecords(tx *gorm.DB, photoIDs []uint) error {
+	albumIDs, err := albumIDsForPhotos(tx, photoIDs)
+	if err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("photo_id IN ?", photoIDs).Delete(&database.AlbumPhoto{}).Error; err != nil {
+		return fmt.Errorf("não foi possível remover a foto dos álbuns: %w", err)
+	}
+	if err := tx.Unscoped().Where("photo_id IN ?", photoIDs).Delete(&database.PhotoTag{}).Error; err != nil {
+		return fmt.Errorf("não foi possível remover as tags da foto: %w", err)
+	}
+	if err := tx.Where("photo_id IN ?", photoIDs).Delete(&database.MetadataDump{}).Error; err != nil {
+		return fmt.Errorf("não foi possível remover os metadados da foto: %w", err)
+	}
+	if err := tx.Unscoped().Where("photo_id IN ?", photoIDs).Delete(&database.PhotoReaction{}).Error; err != nil {
+		return fmt.Errorf("não foi possível remover as reações à foto: %w", err)
+	}
+	if err := tx.Unscoped().Where("from_photo_id IN ? OR to_photo_id IN ?", photoIDs, photoIDs).Delete(&database.PhotoRelation{}).Error; err != nil {
+		return fmt.Errorf("não foi possível remover as relações da foto: %w", err)
+	}
+	if err := tx.Unscoped().Where("photo_id IN ?", photoIDs).Delete(&database.PhotoAnnotation{}).Error; err != nil {
+		return fmt.Errorf("não foi possível remover as anotações da foto: %w", err)
+	}
+	if err := tx.Where("photo_id IN ?", photoIDs).Delete(&database.SelectionPhoto{}).Error; err != nil {
+		return fmt.Errorf("não foi possível remover a foto das seleções: %w", err)
+	}
+	if err := tx.Where("photo_id IN ?", photoIDs).Delete(&database.MetadataChange{}).Error; err != nil {
+		return fmt.Errorf("não foi possível remover o histórico de alterações da foto: %w", err)
+	}
+	if err := tx.Unscoped().Delete(&database.Photo{}, photoIDs).Error; err != nil {
+		return fmt.Errorf("não foi possível remover a foto do banco de dados: %w", err)
+	}
+	return refreshAlbumDates(tx, albumIDs)
+}
+
+// removePhotoFiles apaga os arquivos de uma foto já removida do banco de dados: o arquivo armazenado
+// (o original de fotos indexadas no local só com deleteFile), a miniatura, a prévia, o original guardado na
+// redução e as versões derivadas.
+func (s *PhotoService) removePhotoFiles(photo *database.Photo, deleteFile bool) error {
+	if !photo.ManagedExternally || deleteFile {
+		if err := os.Remove(photo.StoredPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("foto removida do índice, mas não foi possível apagar o arquivo '%s': %w", photo.StoredPath, err)
+		}
+	}
+	if photo.ThumbnailPath != "" {
+		os.Remove(photo.ThumbnailPath)
+	}
+	if photo.PreviewPath != "" {
+		os.Remove(photo.PreviewPath)
+	}
+	if photo.OriginalPath != "" {
+		os.Remove(photo.OriginalPath)
+	}
+	if s.Thumbnails != nil {
+		s.Thumbnails.RemoveRenditions(photo.ID)
+	}
+	return nil
+}
+
+// ingestRequest descreve um arquivo local a ser ingerido na biblioteca.
+type ingestRequest struct {
+	SourcePath        string      // Caminho local do arquivo a ser processado
+	Filename          string      // Nome original do arquivo
+	FileSize          int64       // Tamanho em bytes
+	MimeType          string      // Tipo MIME do arquivo (detectado pelo conteúdo durante a validação, se vazio)
+	UploadDate        time.Time   // Data/hora do upload ou importação
+	ManagedExternally bool        // Se true, indexa o arquivo no local em vez de copiá-lo para o armazenamento
+	SourceModTime     *time.Time  // Data de modificação do arquivo original (usada na detecção de mudanças)
+	Origin            PhotoOrigin // Procedência da foto
+}
+
+// ingestPlan é o resultado das etapas da ingestão que não gravam nada: validação, EXIF, hash, verificação
+// de duplicatas e regras de organização.
+type ingestPlan struct {
+	Photo        database.Photo  // Metadados da foto, ainda sem caminho armazenado nem volume
+	OrganizeDate time.Time       // Data usada na organização do armazenamento (EXIF ou upload)
+	Organized    rules.Result    // Ações das regras de organização
+	Duplicate    *database.Photo // Foto existente com o mesmo hash, se houver e a política da origem não gravar a cópia
+	Preview      []byte          // Miniatura embutida no EXIF, gravada como prévia após a ingestão
+	Regions      []exif.Region   // Regiões XMP nomeadas (ex: rostos), gravadas como anotações
+
+	Policy    *database.SourceDuplicatePolicy // Política de duplicatas da origem, consultada se o hash já existe
+	VersionOf *database.Photo                 // Foto existente de que esta é uma cópia (política version)
+}
+
+// planIngest executa as etapas da ingestão que não gravam nada a partir de um arquivo local já disponível
+// em req.SourcePath.
+func (s *PhotoService) planIngest(ctx context.Context, req ingestRequest) (*ingestPlan, error) {
+	// 1. Valida o arquivo (tamanho, tipo pelo conteúdo, dimensões, antivírus)
+	file := &validation.File{Path: req.SourcePath, Filename: req.Filename, Size: req.FileSize}
+	_, span := tracing.Start(ctx, "photo.validate", tracing.Int("file.size", req.FileSize))
+	err := s.Validators.Validate(ctx, file)
+	span.Finish(err)
+	if err != nil {
+		return nil, err
+	}
+	if file.MimeType != "" {
+		req.MimeType = file.MimeType
+	}
+	if req.MimeType == "" {
+		mimeType, err := detectMimeType(req.SourcePath)
+		if err != nil {
+			return nil, err
+		}
+		req.MimeType = mimeType
+	}
+
+	// 2. Extrai metadados EXIF (vídeos não têm EXIF)
+	var exifData *exif.ExifData
+	if !video.IsVideo(req.MimeType) {
+		_, span = tracing.Start(ctx, "photo.exif")
+		exifData, err = exif.ExtractExifData(req.SourcePath)
+		span.Finish(err)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao extrair dados EXIF: %w", err)
+		}
 	}
 
 	// === CORREÇÃO AQUI: Priorizar data EXIF para organização e metadados ===
 	var photoOrganizeDate time.Time // Data usada para organizar no sistema de arquivos
 	var exifDateTime *time.Time     // Data para ser salva no banco de dados (pode ser nil)
 
+	var latitude, longitude *float64
+	var camera exif.ExifData // Campos de câmera e captura; vazios se não houver EXIF
+	if exifData != nil {
+		latitude, longitude = exifData.Latitude, exifData.Longitude
+		camera = *exifData
+	}
+
 	if exifData != nil && exifData.DateTime != nil {
 		photoOrganizeDate = *exifData.DateTime // Usa a data EXIF para organização
 		exifDateTime = exifData.DateTime       // Salva a data EXIF para o DB
 	} else {
-		photoOrganizeDate = uploadDate // Se não houver EXIF, usa a data de upload para organização
-		exifDateTime = nil             // Garante que o campo EXIF no DB seja nil
+		photoOrganizeDate = req.UploadDate // Se não houver EXIF, usa a data de upload para organização
+		exifDateTime = nil                 // Garante que o campo EXIF no DB seja nil
 	}
 	// =====================================================================
 
 	// 3. Calcula o hash da foto (MD5 por simplicidade, SHA256 é mais robusto)
-	hash, err := calculateMD5Hash(tempFilePath)
+	_, span = tracing.Start(ctx, "photo.hash")
+	hash, err := calculateMD5Hash(req.SourcePath)
+	span.Finish(err)
 	if err != nil {
 		return nil, fmt.Errorf("não foi possível calcular o hash da foto: %w", err)
 	}
 
-	// 4. Verifica duplicatas (futura funcionalidade - por enquanto, apenas um log)
+	// 4. Verifica duplicatas; a política da origem decide se a cópia é gravada mesmo assim
 	var existingPhoto database.Photo
-	result := s.DB.Where("hash = ?", hash).First(&existingPhoto)
-	if result.Error == nil {
-		// Foto duplicada encontrada
-		return &existingPhoto, fmt.Errorf("foto duplicada detectada (hash: %s, caminho existente: %s)", hash, existingPhoto.StoredPath)
-	} else if result.Error != gorm.ErrRecordNotFound {
-		// Erro real do banco de dados
+	result := s.DB.WithContext(ctx).Where("hash = ?", hash).Order("copy_index").Limit(1).Find(&existingPhoto)
+	if result.Error != nil {
 		return nil, fmt.Errorf("erro ao verificar duplicatas: %w", result.Error)
 	}
+	var policy *database.SourceDuplicatePolicy
+	copyIndex := 0
+	if result.RowsAffected > 0 {
+		if policy, err = findDuplicatePolicy(s.DB.WithContext(ctx), req.Origin.Source, req.Origin.Device); err != nil {
+			return nil, err
+		}
+		if policy.Policy != database.DuplicatePolicyVersion {
+			return &ingestPlan{Photo: database.Photo{Filename: req.Filename, Hash: hash}, Duplicate: &existingPhoto, Policy: policy}, nil
+		}
+		if copyIndex, err = nextCopyIndex(s.DB.WithContext(ctx), hash); err != nil {
+			return nil, err
+		}
+		req.Filename = copyFilename(req.Filename, copyIndex)
+	}
 
-	// 5. Salva a foto no sistema de arquivos na estrutura ano/mês
-	// Agora `photoOrganizeDate` tem a lógica correta (EXIF ou upload)
-	storedPath, err := s.FileManager.SavePhoto(file, photoOrganizeDate)
-	if err != nil {
-		return nil, fmt.Errorf("não foi possível salvar a foto no armazenamento: %w", err)
+	// 5. Lê as dimensões apenas do cabeçalho; falhas não impedem a ingestão
+	width, height := file.Width, file.Height
+	if width == 0 || height == 0 {
+		if width, height, err = thumbnail.Dimensions(req.SourcePath); err != nil {
+			log.Printf("Aviso: não foi possível ler as dimensões de '%s': %v\n", req.Filename, err)
+		}
 	}
 
 	// 6. Preenche os metadados da foto
 	photo := database.Photo{
-		Filename:   file.Filename,
-		StoredPath: storedPath,
-		UploadDate: uploadDate,   // Data de upload sempre será a data real do upload
-		ExifDate:   exifDateTime, // Data EXIF, pode ser nil
-		Hash:       hash,
-		FileSize:   file.Size,
-		MimeType:   file.Header.Get("Content-Type"),
-		Width:      0, // A ser preenchido por uma biblioteca de processamento de imagem
-		Height:     0, // A ser preenchido por uma biblioteca de processamento de imagem
+		Filename:          req.Filename,
+		UploadDate:        req.UploadDate, // Data de upload sempre será a data real do upload
+		ExifDate:          exifDateTime,   // Data EXIF, pode ser nil
+		TimeZoneOffset:    camera.TimeZoneOffset,
+		Latitude:          latitude,
+		Longitude:         longitude,
+		CameraMake:        camera.CameraMake,
+		CameraModel:       camera.CameraModel,
+		LensModel:         camera.LensModel,
+		FocalLength:       camera.FocalLength,
+		ISO:               camera.ISO,
+		Hash:              hash,
+		CopyIndex:         copyIndex,
+		FileSize:          req.FileSize,
+		MimeType:          req.MimeType,
+		Width:             width,
+		Height:            height,
+		ManagedExternally: req.ManagedExternally,
+		SourceModTime:     req.SourceModTime,
+		Source:            req.Origin.Source,
+		SourceDevice:      req.Origin.Device,
+		SourceDetail:      req.Origin.Detail,
 	}
+	detectProjection(req.SourcePath, &photo)
+	detectAuxiliaryAssets(req.SourcePath, &photo)
 
-	// 7. Salva os metadados da foto no banco de dados
-	if result := s.DB.Create(&photo); result.Error != nil {
-		os.Remove(storedPath)
-		return nil, fmt.Errorf("não foi possível salvar os metadados da foto no banco de dados: %w", result.Error)
+	// 7. Regras de organização: tags, álbuns e pasta de armazenamento calculados a partir dos metadados
+	plan := &ingestPlan{
+		Photo:        photo,
+		OrganizeDate: photoOrganizeDate,
+		Organized:    s.Rules.Evaluate(photoRuleFacts(&photo)),
+		Preview:      camera.Thumbnail,
+		Regions:      readIngestRegions(req.SourcePath, req.Filename, photo.MimeType),
+		Policy:       policy,
 	}
+	if copyIndex > 0 {
+		plan.VersionOf = &existingPhoto
+	}
+	return plan, nil
+}
 
+// ingestPhoto executa o pipeline comum de ingestão (EXIF, hash, duplicatas, armazenamento e banco)
+// a partir de um arquivo local já disponível em sourcePath.
+func (s *PhotoService) ingestPhoto(ctx context.Context, req ingestRequest) (_ *database.Photo, err error) {
+	ctx, span := tracing.Start(ctx, "photo.ingest",
+		tracing.String("photo.filename", req.Filename),
+		tracing.String("photo.source", req.Origin.Source),
+		tracing.Int("file.size", req.FileSize),
+	)
+	defer func() { span.Finish(err) }()
+
+	plan, err := s.planIngest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Duplicate != nil {
+		return s.handleDuplicate(ctx, plan)
+	}
+	// Não grava nada se a requisição foi cancelada durante a leitura do arquivo
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	photo, organized, photoOrganizeDate := plan.Photo, plan.Organized, plan.OrganizeDate
+	hash := copyStorageKey(photo.Hash, photo.CopyIndex)
+
+	// O nome é único na biblioteca, mas se repete entre pastas (ex: IMG_0001.jpg de câmeras ou anos
+	// diferentes): fotos distintas com o mesmo nome recebem "IMG_0001 (2).jpg"
+	baseFilename := photo.Filename
+	nameIndex := 1
+	if photo.Filename, nameIndex, err = availableFilename(s.DB.WithContext(ctx), baseFilename, nameIndex); err != nil {
+		return nil, err
+	}
+	req.MimeType, req.Filename = photo.MimeType, photo.Filename
+
+	// 8. Reduz imagens acima do limite da política de redução, guardando o original se configurado
+	storeSource := req.SourcePath
+	if s.Downscale.applies(req, photo.Width, photo.Height) {
+		reduced, err := s.downscaleForStorage(ctx, req, hash, photoOrganizeDate)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(reduced.Path)
+		storeSource = reduced.Path
+		photo.FileSize, photo.Width, photo.Height = reduced.Size, reduced.Width, reduced.Height
+		photo.Downscaled, photo.OriginalPath = true, reduced.OriginalPath
+	}
+
+	// 9. Salva a foto no sistema de arquivos na estrutura ano/mês (ou na pasta definida pelas regras)
+	// Agora `photoOrganizeDate` tem a lógica correta (EXIF ou upload)
+	// Fotos indexadas no local não são copiadas: o caminho original é o caminho armazenado.
+	storedPath := req.SourcePath
+	volume := ""
+	if !req.ManagedExternally {
+		src, err := os.Open(storeSource)
+		if err != nil {
+			return nil, fmt.Errorf("não foi possível abrir o arquivo para armazenamento: %w", err)
+		}
+		defer src.Close()
+
+		_, saveSpan := tracing.StartKind(ctx, "storage.save", tracing.KindClient, tracing.Int("file.size", photo.FileSize))
+		for attempt := 1; ; attempt++ {
+			storedPath, volume, err = s.FileManager.SaveFromReaderIn(contextReader{ctx: ctx, r: src}, organized.Folder, req.Filename, hash, photo.FileSize, photoOrganizeDate)
+			// Um arquivo nunca é sobrescrito: se o caminho já existe (ex: arquivo sem registro no banco), tenta
+			// o próximo nome livre. No layout por hash o caminho não depende do nome, então não adianta
+			if !errors.Is(err, storage.ErrFileExists) || s.FileManager.Layout == storage.LayoutHash || attempt >= maxFilenameAttempts {
+				break
+			}
+			if photo.Filename, nameIndex, err = availableFilename(s.DB.WithContext(ctx), baseFilename, nameIndex+1); err != nil {
+				break
+			}
+			req.Filename = photo.Filename
+			if _, err = src.Seek(0, io.SeekStart); err != nil {
+				break
+			}
+		}
+		saveSpan.SetAttr(tracing.String("storage.volume", volume))
+		saveSpan.Finish(err)
+		if err != nil {
+			if photo.OriginalPath != "" {
+				os.Remove(photo.OriginalPath)
+			}
+			return nil, fmt.Errorf("não foi possível salvar a foto no armazenamento: %w", err)
+		}
+	}
+
+	photo.StoredPath, photo.Volume = storedPath, volume
+
+	// 10. Salva os metadados da foto no banco de dados
+	var albumIDs []uint
+	dbCtx, dbSpan := tracing.Start(ctx, "photo.save_metadata")
+	err = s.DB.WithContext(dbCtx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&photo).Error; err != nil {
+			return err
+		}
+		ids, err := applyRuleActions(tx, &photo, organized)
+		if err != nil {
+			return err
+		}
+		if _, err := createRegionAnnotations(tx, photo.ID, plan.Regions); err != nil {
+			return err
+		}
+		if albumIDs, err = applySourceAlbum(tx, &photo, ids); err != nil {
+			return err
+		}
+		if plan.VersionOf != nil {
+			if err := tx.Create(&database.PhotoRelation{FromPhotoID: photo.ID, ToPhotoID: plan.VersionOf.ID, Kind: database.RelationVersionOf}).Error; err != nil {
+				return err
+			}
+		}
+		return refreshAlbumDates(tx, albumIDs)
+	})
+	dbSpan.Finish(err)
+	if err != nil {
+		// storedPath foi criado por esta chamada (a gravação nunca sobrescreve um arquivo existente), então
+		// removê-lo não afeta outras fotos. O arquivo original de uma foto indexada no local nunca é apagado
+		if !req.ManagedExternally {
+			os.Remove(storedPath)
+		}
+		if photo.OriginalPath != "" {
+			os.Remove(photo.OriginalPath)
+		}
+		return nil, fmt.Errorf("não foi possível salvar os metadados da foto no banco de dados: %w", err)
+	}
+	s.savePreview(ctx, &photo, plan.Preview)
+
+	s.Events.Publish(events.TypePhotoCreated, map[string]interface{}{"photo_id": photo.ID, "filename": photo.Filename})
+	for _, albumID := range albumIDs {
+		s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": albumID})
+	}
 	return &photo, nil
 }
 
+// maxFilenameAttempts limita as tentativas de gravar a foto com um nome livre.
+const maxFilenameAttempts = 100
+
+// availableFilename retorna o primeiro nome ainda não usado por outra foto, começando pela variante index
+// (1 é o próprio nome; n > 1, "nome (n).ext"), e a variante escolhida. Fotos removidas também contam, pois
+// continuam no índice único até serem apagadas definitivamente.
+func availableFilename(db *gorm.DB, filename string, index int) (string, int, error) {
+	for ; ; index++ {
+		candidate := filename
+		if index > 1 {
+			candidate = suffixedFilename(filename, strconv.Itoa(index))
+		}
+		var count int64
+		if err := db.Unscoped().Model(&database.Photo{}).Where("filename = ?", candidate).Count(&count).Error; err != nil {
+			return "", 0, fmt.Errorf("erro ao verificar o nome da foto: %w", err)
+		}
+		if count == 0 {
+			return candidate, index, nil
+		}
+	}
+}
+
+// handleDuplicate aplica a política de duplicatas da origem a um arquivo cujo conteúdo já está na biblioteca,
+// retornando a foto existente. A política reject (padrão) rejeita o arquivo com ErrDuplicatePhoto; skip e
+// link o ignoram com ErrDuplicateSkipped, e link inclui a foto existente no álbum da política.
+func (s *PhotoService) handleDuplicate(ctx context.Context, plan *ingestPlan) (*database.Photo, error) {
+	existing := plan.Duplicate
+	switch plan.Policy.Policy {
+	case database.DuplicatePolicySkip:
+		return existing, ErrDuplicateSkipped
+	case database.DuplicatePolicyLink:
+		albumID, err := linkDuplicate(s.DB.WithContext(ctx), plan.Policy, existing)
+		if err != nil {
+			return nil, err
+		}
+		if albumID != 0 {
+			s.Events.Publish(events.TypeAlbumChanged, map[string]interface{}{"album_id": albumID})
+		}
+		return existing, ErrDuplicateSkipped
+	}
+	return existing, fmt.Errorf("%w (hash: %s, caminho existente: %s)", ErrDuplicatePhoto, existing.Hash, existing.StoredPath)
+}
+
+// detectMimeType identifica o tipo MIME de um arquivo a partir dos seus primeiros bytes.
+func detectMimeType(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("não foi possível abrir o arquivo para detectar o tipo: %w", err)
+	}
+	defer file.Close()
+
+	buf := make([]byte, 512)
+	n, err := file.Read(buf)
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("não foi possível ler o arquivo para detectar o tipo: %w", err)
+	}
+
+	return thumbnail.DetectContentType(buf[:n]), nil
+}
+
+// contextReader interrompe a leitura quando o contexto é cancelado, evitando que uploads abortados
+// continuem sendo copiados para o armazenamento.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
 // calculateMD5Hash calcula o hash MD5 de um arquivo.
 func calculateMD5Hash(filePath string) (string, error) {
 	file, err := os.Open(filePath)
@@ -142,25 +910,239 @@ func calculateMD5Hash(filePath string) (string, error) {
 }
 
 type PhotoFilter struct {
-	Year     int
-	Month    int
-	Filename string
-	Tag      string
-	Offset   int
-	Limit    int
-	OrderBy  string // Campo para ordenação (ex: "exif_date DESC", "upload_date ASC")
+	Year            int
+	Month           int
+	TimeZone        string // Fuso de Year e Month: nome IANA, local ou photo (vazio = fuso padrão do serviço)
+	Filename        string
+	Tag             string
+	AlbumID         uint     // Apenas fotos do álbum informado
+	FavoritesOnly   bool     // Apenas fotos marcadas como favoritas
+	QuarantinedOnly bool     // Apenas fotos em quarentena
+	IncludeHidden   bool     // Inclui as fotos ocultas, excluídas por padrão
+	Source          string   // Apenas fotos do canal de entrada informado (ver constantes database.Source*)
+	Device          string   // Apenas fotos do dispositivo informado (sem diferenciar maiúsculas)
+	Tags            []string // Apenas fotos com todas estas tags (nome exato, sem diferenciar maiúsculas)
+	ExcludeTags     []string // Exclui as fotos com qualquer uma destas tags (nome exato, sem diferenciar maiúsculas)
+	Camera          string   // Apenas fotos cuja câmera (fabricante e modelo) contém o texto
+	Lens            string   // Apenas fotos cuja lente contém o texto
+	GPS             *bool    // Apenas fotos com (true) ou sem (false) coordenadas GPS; nil = indiferente
+	Tagged          *bool    // Apenas fotos com (true) ou sem (false) tags
+	Dated           *bool    // Apenas fotos com (true) ou sem (false) data EXIF
+	InAlbum         *bool    // Apenas fotos que estão (true) ou não (false) em algum álbum
+	ExcludeAlbumID  uint     // Exclui as fotos do álbum informado
+	ExcludeSource   string   // Exclui as fotos do canal de entrada informado
+	Projection      string   // Apenas panoramas com a projeção informada; ProjectionAny = qualquer panorama
+	DepthMap        *bool    // Apenas fotos com (true) ou sem (false) mapa de profundidade
+	GainMap         *bool    // Apenas fotos com (true) ou sem (false) mapa de ganho HDR
+	Offset          int
+	Limit           int
+	OrderBy         string // Campo para ordenação (ex: "exif_date DESC", "upload_date ASC")
+}
+
+// PhotoOrderColumns são as colunas aceitas na ordenação de fotos (PhotoFilter.OrderBy).
+var PhotoOrderColumns = []string{"exif_date", "upload_date", "filename", "file_size", "width", "height", "id", "views", "downloads"}
+
+// counterColumns associa as ordenações por estatísticas de acesso às colunas correspondentes. Sem direção
+// explícita, essas ordenações são decrescentes (mais acessadas primeiro).
+var counterColumns = map[string]string{
+	"views":     "view_count",
+	"downloads": "download_count",
+}
+
+// ValidPhotoOrder verifica se a ordenação é vazia (ordem padrão) ou "coluna", "coluna ASC" ou "coluna DESC"
+// com uma das PhotoOrderColumns. Evita que o valor, repassado ao ORDER BY, contenha SQL arbitrário.
+func ValidPhotoOrder(orderBy string) bool {
+	if orderBy == "" {
+		return true
+	}
+	parts := strings.Fields(orderBy)
+	if len(parts) > 2 || !slices.Contains(PhotoOrderColumns, strings.ToLower(parts[0])) {
+		return false
+	}
+	return len(parts) == 1 || strings.EqualFold(parts[1], "ASC") || strings.EqualFold(parts[1], "DESC")
 }
 
 // GetPhotos busca fotos com base nos filtros fornecidos.
-func (s *PhotoService) GetPhotos(filter PhotoFilter) ([]database.Photo, error) {
-	query := s.DB.Model(&database.Photo{})
+func (s *PhotoService) GetPhotos(ctx context.Context, filter PhotoFilter) ([]database.Photo, error) {
+	query, err := s.orderedPhotosQuery(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var photos []database.Photo
+	if result := query.Find(&photos); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar fotos: %w", result.Error)
+	}
+
+	return photos, nil
+}
+
+// StreamPhotos percorre as fotos que respeitam o filtro, na mesma ordem de GetPhotos, lendo uma linha por vez
+// de um cursor do banco em vez de carregar o resultado inteiro em memória. A iteração para no primeiro erro
+// devolvido por fn ou quando o contexto é cancelado.
+func (s *PhotoService) StreamPhotos(ctx context.Context, filter PhotoFilter, fn func(database.Photo) error) error {
+	query, err := s.orderedPhotosQuery(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return fmt.Errorf("erro ao buscar fotos: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var photo database.Photo
+		if err := s.DB.ScanRows(rows, &photo); err != nil {
+			return fmt.Errorf("erro ao ler foto: %w", err)
+		}
+		if err := fn(photo); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao buscar fotos: %w", err)
+	}
+	return nil
+}
+
+// AlbumNamesByPhoto retorna os nomes dos álbuns de cada foto (em ordem alfabética), para exportações que
+// listam a participação em álbuns sem uma consulta por foto.
+func (s *PhotoService) AlbumNamesByPhoto(ctx context.Context) (map[uint][]string, error) {
+	var rows []struct {
+		PhotoID uint
+		Name    string
+	}
+	err := s.DB.WithContext(ctx).Table("album_photos").
+		Select("album_photos.photo_id, albums.name").
+		Joins("JOIN albums ON albums.id = album_photos.album_id AND albums.deleted_at IS NULL").
+		Where("album_photos.deleted_at IS NULL").
+		Order("albums.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar os álbuns das fotos: %w", err)
+	}
+
+	names := make(map[uint][]string)
+	for _, row := range rows {
+		names[row.PhotoID] = append(names[row.PhotoID], row.Name)
+	}
+	return names, nil
+}
+
+// orderedPhotosQuery monta a consulta de fotos com filtro, ordenação e paginação.
+func (s *PhotoService) orderedPhotosQuery(ctx context.Context, filter PhotoFilter) (*gorm.DB, error) {
+	query, err := s.filteredPhotosQuery(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ordenação
+	if !ValidPhotoOrder(filter.OrderBy) {
+		return nil, fmt.Errorf("ordenação inválida: '%s'", filter.OrderBy)
+	}
+	if filter.OrderBy != "" {
+		query = query.Order(photoOrderClause(filter.OrderBy))
+	} else {
+		// Ordem padrão: mais recente primeiro, priorizando EXIF, depois UploadDate
+		query = query.Order("exif_date DESC").Order("upload_date DESC")
+	}
+
+	// Paginação
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	return query, nil
+}
+
+// photoOrderClause converte uma ordenação já validada por ValidPhotoOrder na cláusula ORDER BY.
+func photoOrderClause(orderBy string) string {
+	parts := strings.Fields(orderBy)
+	column, ok := counterColumns[strings.ToLower(parts[0])]
+	if !ok {
+		return orderBy
+	}
+	direction := "DESC"
+	if len(parts) == 2 {
+		direction = strings.ToUpper(parts[1])
+	}
+	return column + " " + direction + ", id"
+}
+
+// PopularPhotos retorna as fotos que respeitam o filtro e já foram acessadas, das mais para as menos
+// acessadas; by é "views" (visualizações) ou "downloads". Filter.OrderBy é ignorado.
+func (s *PhotoService) PopularPhotos(ctx context.Context, filter PhotoFilter, by string) ([]database.Photo, error) {
+	column, ok := counterColumns[by]
+	if !ok {
+		return nil, fmt.Errorf("ranking inválido: '%s'", by)
+	}
+	filter.OrderBy = by
+	query, err := s.orderedPhotosQuery(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var photos []database.Photo
+	if result := query.Where(column + " > 0").Find(&photos); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar fotos populares: %w", result.Error)
+	}
+	return photos, nil
+}
+
+// PhotoSourceCount é a quantidade de fotos de um canal de entrada e dispositivo.
+type PhotoSourceCount struct {
+	Source string
+	Device string
+	Count  int64
+}
+
+// SourceCounts retorna as procedências registradas (canal e dispositivo), das com mais para as com menos fotos.
+func (s *PhotoService) SourceCounts(ctx context.Context) ([]PhotoSourceCount, error) {
+	var counts []PhotoSourceCount
+	result := s.DB.WithContext(ctx).Model(&database.Photo{}).
+		Select("source, source_device AS device, COUNT(*) AS count").
+		Group("source, source_device").
+		Order("count DESC, source, source_device").
+		Scan(&counts)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao contar as procedências das fotos: %w", result.Error)
+	}
+	return counts, nil
+}
+
+// GetPhotoIDs retorna apenas os IDs das fotos que respeitam o filtro (sem paginação), na ordem de ID.
+func (s *PhotoService) GetPhotoIDs(ctx context.Context, filter PhotoFilter) ([]uint, error) {
+	query, err := s.filteredPhotosQuery(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint
+	if result := query.Order("id ASC").Pluck("id", &ids); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar fotos: %w", result.Error)
+	}
+	return ids, nil
+}
 
+// filteredPhotosQuery monta a consulta de fotos com os critérios de filtro (sem ordenação nem paginação).
+func (s *PhotoService) filteredPhotosQuery(ctx context.Context, filter PhotoFilter) (*gorm.DB, error) {
+	query := s.DB.WithContext(ctx).Model(&database.Photo{})
+
+	// Os limites de ano e mês são datas locais do fuso do usuário (ou da foto), não de UTC
+	zone, err := ParseTimelineZone(filter.TimeZone, s.Timeline)
+	if err != nil {
+		return nil, err
+	}
 	if filter.Year != 0 {
 		// Filtra por ano (tanto EXIF quanto UploadDate)
 		// SQLite não tem funções DATE_PART, então usamos BETWEEN para o início e fim do ano.
 		startDate := time.Date(filter.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
 		endDate := startDate.AddDate(1, 0, 0).Add(-time.Nanosecond) // Fim do ano
-		query = query.Where("(exif_date BETWEEN ? AND ?) OR (upload_date BETWEEN ? AND ?)", startDate, endDate, startDate, endDate)
+		query = zone.dateRangeQuery(query, startDate, endDate)
 	}
 
 	if filter.Month != 0 {
@@ -175,7 +1157,7 @@ func (s *PhotoService) GetPhotos(filter PhotoFilter) ([]database.Photo, error) {
 		// Filtra por mês (assumindo que o ano já foi filtrado ou será)
 		startDate := time.Date(filter.Year, time.Month(filter.Month), 1, 0, 0, 0, 0, time.UTC)
 		endDate := startDate.AddDate(0, 1, 0).Add(-time.Nanosecond) // Fim do mês
-		query = query.Where("(exif_date BETWEEN ? AND ?) OR (upload_date BETWEEN ? AND ?)", startDate, endDate, startDate, endDate)
+		query = zone.dateRangeQuery(query, startDate, endDate)
 	}
 
 	if filter.Filename != "" {
@@ -187,33 +1169,171 @@ func (s *PhotoService) GetPhotos(filter PhotoFilter) ([]database.Photo, error) {
 		query = query.Where("tags LIKE ?", "%"+filter.Tag+"%")
 	}
 
-	// Ordenação
-	if filter.OrderBy != "" {
-		query = query.Order(filter.OrderBy)
-	} else {
-		// Ordem padrão: mais recente primeiro, priorizando EXIF, depois UploadDate
-		query = query.Order("exif_date DESC").Order("upload_date DESC")
+	if filter.AlbumID != 0 {
+		query = query.Where("id IN (?)", s.DB.Model(&database.AlbumPhoto{}).Select("photo_id").Where("album_id = ?", filter.AlbumID))
 	}
 
-	// Paginação
-	if filter.Limit > 0 {
-		query = query.Limit(filter.Limit)
+	if filter.FavoritesOnly {
+		query = query.Where("favorite = ?", true)
 	}
-	if filter.Offset > 0 {
-		query = query.Offset(filter.Offset)
+
+	if filter.QuarantinedOnly {
+		query = query.Where("quarantined = ?", true)
 	}
 
-	var photos []database.Photo
-	if result := query.Find(&photos); result.Error != nil {
-		return nil, fmt.Errorf("erro ao buscar fotos: %w", result.Error)
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
 	}
 
-	return photos, nil
+	if filter.Device != "" {
+		query = query.Where("LOWER(source_device) = LOWER(?)", filter.Device)
+	}
+
+	for _, tag := range filter.Tags {
+		query = query.Where("id IN (?)", s.photoIDsWithTag(tag))
+	}
+
+	for _, tag := range filter.ExcludeTags {
+		query = query.Where("id NOT IN (?)", s.photoIDsWithTag(tag))
+	}
+
+	if filter.ExcludeAlbumID != 0 {
+		query = query.Where("id NOT IN (?)", s.DB.Model(&database.AlbumPhoto{}).Select("photo_id").Where("album_id = ?", filter.ExcludeAlbumID))
+	}
+
+	if filter.ExcludeSource != "" {
+		query = query.Where("source <> ?", filter.ExcludeSource)
+	}
+
+	if filter.Camera != "" {
+		query = query.Where("LOWER(camera_make || ' ' || camera_model) LIKE LOWER(?)", "%"+filter.Camera+"%")
+	}
+
+	if filter.Lens != "" {
+		query = query.Where("LOWER(lens_model) LIKE LOWER(?)", "%"+filter.Lens+"%")
+	}
+
+	if filter.GPS != nil {
+		query = query.Where(presenceCondition("latitude IS NOT NULL AND longitude IS NOT NULL", *filter.GPS))
+	}
+
+	if filter.Tagged != nil {
+		query = query.Where(presenceCondition("id IN (SELECT photo_id FROM photo_tags WHERE deleted_at IS NULL)", *filter.Tagged))
+	}
+
+	if filter.Dated != nil {
+		query = query.Where(presenceCondition("exif_date IS NOT NULL", *filter.Dated))
+	}
+
+	if filter.InAlbum != nil {
+		query = query.Where(presenceCondition("id IN (SELECT photo_id FROM album_photos WHERE deleted_at IS NULL)", *filter.InAlbum))
+	}
+
+	if filter.DepthMap != nil {
+		query = query.Where("has_depth_map = ?", *filter.DepthMap)
+	}
+
+	if filter.GainMap != nil {
+		query = query.Where("has_gain_map = ?", *filter.GainMap)
+	}
+
+	if filter.Projection == ProjectionAny {
+		query = query.Where("projection <> ''")
+	} else if filter.Projection != "" {
+		query = query.Where("projection = ?", filter.Projection)
+	}
+
+	if !filter.IncludeHidden {
+		query = query.Where("hidden = ?", false)
+	}
+
+	return query, nil
+}
+
+// photoIDsWithTag retorna a subconsulta dos IDs das fotos com a tag (nome exato, sem diferenciar maiúsculas).
+func (s *PhotoService) photoIDsWithTag(tag string) *gorm.DB {
+	return s.DB.Model(&database.PhotoTag{}).
+		Select("photo_tags.photo_id").
+		Joins("JOIN tags ON tags.id = photo_tags.tag_id").
+		Where("LOWER(tags.name) = LOWER(?)", tag)
+}
+
+// presenceCondition retorna a condição, ou sua negação quando present é false.
+func presenceCondition(condition string, present bool) string {
+	if present {
+		return condition
+	}
+	return "NOT (" + condition + ")"
+}
+
+// SetFavorite marca ou desmarca uma foto como favorita. Com version não vazio, a alteração só é feita se a
+// foto ainda estiver nessa versão (ver PhotoVersion); caso contrário retorna *PhotoVersionConflictError.
+func (s *PhotoService) SetFavorite(ctx context.Context, id uint, favorite bool, version string) (*database.Photo, error) {
+	return s.setPhotoFlag(ctx, id, "favorite", favorite, version)
+}
+
+// SetHidden oculta ou volta a exibir uma foto na linha do tempo e nas buscas. version funciona como em
+// SetFavorite.
+func (s *PhotoService) SetHidden(ctx context.Context, id uint, hidden bool, version string) (*database.Photo, error) {
+	return s.setPhotoFlag(ctx, id, "hidden", hidden, version)
+}
+
+// setPhotoFlag grava uma coluna booleana da foto, conferindo antes a versão esperada.
+func (s *PhotoService) setPhotoFlag(ctx context.Context, id uint, column string, value bool, version string) (*database.Photo, error) {
+	var photo *database.Photo
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if photo, err = checkPhotoVersion(tx, id, version); err != nil {
+			return err
+		}
+		if err := tx.Model(photo).Update(column, value).Error; err != nil {
+			return fmt.Errorf("não foi possível atualizar a foto: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": photo.ID})
+	return photo, nil
+}
+
+// SetTimelineHidden tira as fotos informadas da linha do tempo (ou as devolve a ela), sem afetar os
+// álbuns e as buscas. Retorna a quantidade de fotos alteradas.
+func (s *PhotoService) SetTimelineHidden(ctx context.Context, ids []uint, hidden bool) (int64, error) {
+	ids = uniqueIDs(ids)
+	var existing int64
+	if err := s.DB.WithContext(ctx).Model(&database.Photo{}).Where("id IN ?", ids).Count(&existing).Error; err != nil {
+		return 0, fmt.Errorf("erro ao verificar fotos: %w", err)
+	}
+	if int(existing) != len(ids) {
+		return 0, i18n.NewError(i18n.CodePhotosNotFound)
+	}
+
+	var changed []uint
+	if err := s.DB.WithContext(ctx).Model(&database.Photo{}).Where("id IN ? AND hide_from_timeline = ?", ids, !hidden).Pluck("id", &changed).Error; err != nil {
+		return 0, fmt.Errorf("erro ao verificar fotos: %w", err)
+	}
+	if len(changed) == 0 {
+		return 0, nil
+	}
+	if err := s.DB.WithContext(ctx).Model(&database.Photo{}).Where("id IN ?", changed).Update("hide_from_timeline", hidden).Error; err != nil {
+		return 0, fmt.Errorf("não foi possível atualizar as fotos: %w", err)
+	}
+
+	for _, id := range changed {
+		s.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": id})
+	}
+	return int64(len(changed)), nil
 }
 
 // GetPhotosByTimeline retorna fotos agrupadas por ano e mês para exibição em linha do tempo.
+// Fotos ocultas e as tiradas da linha do tempo (HideFromTimeline) só são incluídas se includeHidden for true.
+// O ano e o mês de cada foto são os da sua data no fuso zone (ver TimelineZone.LocalDate). As fotos com data
+// aproximada de ano ou década ficam no mês 0 do ano (ou do primeiro ano da década).
 // Esta função pode ser otimizada para buscar apenas os anos/meses existentes primeiro.
-func (s *PhotoService) GetPhotosByTimeline(limitPerMonth int) (map[int]map[int][]database.Photo, error) {
+func (s *PhotoService) GetPhotosByTimeline(ctx context.Context, limitPerMonth int, includeHidden bool, zone TimelineZone) (map[int]map[int][]database.Photo, error) {
 	// Poderíamos buscar todos os anos/meses distintos e depois buscar as fotos para cada um,
 	// mas para simplicidade inicial, vamos buscar as fotos e agrupá-las em memória.
 	// Para grandes volumes, seria melhor uma abordagem de paginação/streaming ou buscar apenas as fotos do "mês ativo".
@@ -221,7 +1341,11 @@ func (s *PhotoService) GetPhotosByTimeline(limitPerMonth int) (map[int]map[int][
 	var photos []database.Photo
 	// Pega todas as fotos, ordenadas para facilitar o agrupamento
 	// A ordem preferencial é pela data EXIF, e depois pela data de upload
-	result := s.DB.Order("exif_date DESC").Order("upload_date DESC").Find(&photos)
+	query := s.DB.WithContext(ctx)
+	if !includeHidden {
+		query = query.Where("hidden = ? AND hide_from_timeline = ?", false, false)
+	}
+	result := query.Order("exif_date DESC").Order("upload_date DESC").Find(&photos)
 	if result.Error != nil {
 		return nil, fmt.Errorf("erro ao buscar fotos para linha do tempo: %w", result.Error)
 	}
@@ -229,15 +1353,13 @@ func (s *PhotoService) GetPhotosByTimeline(limitPerMonth int) (map[int]map[int][
 	timeline := make(map[int]map[int][]database.Photo) // year -> month -> []Photo
 
 	for _, photo := range photos {
-		var dateToUse time.Time
-		if photo.ExifDate != nil {
-			dateToUse = *photo.ExifDate
-		} else {
-			dateToUse = photo.UploadDate
-		}
+		dateToUse := zone.LocalDate(photo)
 
 		year := dateToUse.Year()
 		month := int(dateToUse.Month())
+		if photo.CircaDate != nil && photo.CircaPrecision != database.CircaPrecisionMonth {
+			month = 0 // Mês desconhecido
+		}
 
 		if _, ok := timeline[year]; !ok {
 			timeline[year] = make(map[int][]database.Photo)
@@ -254,3 +1376,71 @@ func (s *PhotoService) GetPhotosByTimeline(limitPerMonth int) (map[int]map[int][
 
 	return timeline, nil
 }
+
+// Tipos de hash aceitos na busca por hash (FindByHashes).
+const (
+	HashMatchFile  = "file"  // MD5 do arquivo (Photo.Hash), 32 dígitos hexadecimais
+	HashMatchPixel = "pixel" // SHA-256 dos pixels decodificados (Photo.PixelHash), 64 dígitos hexadecimais
+)
+
+// MaxHashLookup limita a quantidade de hashes de uma busca em lote.
+const MaxHashLookup = 1000
+
+// HashMatch é uma foto encontrada pela busca por hash.
+type HashMatch struct {
+	Photo database.Photo
+	Kind  string // Hash que coincidiu (ver constantes HashMatch*)
+}
+
+// HashKind identifica o tipo de um hash pelo tamanho (já normalizado em minúsculas), ou "" se não for um
+// hash hexadecimal aceito.
+func HashKind(hash string) string {
+	for _, r := range hash {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return ""
+		}
+	}
+	switch len(hash) {
+	case 32:
+		return HashMatchFile
+	case 64:
+		return HashMatchPixel
+	}
+	return ""
+}
+
+// FindByHashes busca as fotos cujo hash do arquivo (MD5) ou dos pixels (SHA-256) está entre os informados,
+// reconhecendo o tipo pelo tamanho. Os hashes devem estar normalizados (minúsculas) e ser válidos
+// (HashKind). Retorna as fotos encontradas por hash; uma mesma imagem pode estar em mais de um arquivo,
+// por isso o hash dos pixels pode coincidir com várias fotos.
+func (s *PhotoService) FindByHashes(ctx context.Context, hashes []string) (map[string][]HashMatch, error) {
+	var fileHashes, pixelHashes []string
+	for _, hash := range hashes {
+		if HashKind(hash) == HashMatchFile {
+			fileHashes = append(fileHashes, hash)
+		} else {
+			pixelHashes = append(pixelHashes, hash)
+		}
+	}
+
+	matches := make(map[string][]HashMatch)
+	if len(fileHashes) > 0 {
+		var photos []database.Photo
+		if result := s.DB.WithContext(ctx).Where("hash IN ?", fileHashes).Order("id").Find(&photos); result.Error != nil {
+			return nil, fmt.Errorf("erro ao buscar fotos por hash: %w", result.Error)
+		}
+		for _, photo := range photos {
+			matches[photo.Hash] = append(matches[photo.Hash], HashMatch{Photo: photo, Kind: HashMatchFile})
+		}
+	}
+	if len(pixelHashes) > 0 {
+		var photos []database.Photo
+		if result := s.DB.WithContext(ctx).Where("pixel_hash IN ?", pixelHashes).Order("id").Find(&photos); result.Error != nil {
+			return nil, fmt.Errorf("erro ao buscar fotos por hash: %w", result.Error)
+		}
+		for _, photo := range photos {
+			matches[photo.PixelHash] = append(matches[photo.PixelHash], HashMatch{Photo: photo, Kind: HashMatchPixel})
+		}
+	}
+	return matches, nil
+}
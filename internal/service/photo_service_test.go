@@ -0,0 +1,92 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/storage"
+	"testing"
+	"time"
+)
+
+// newTestPhotoService abre um banco novo e um armazenamento vazio, ambos em pastas temporárias do teste.
+func newTestPhotoService(t *testing.T) *PhotoService {
+	t.Helper()
+	database.InitDB(filepath.Join(t.TempDir(), "photo_manager.db"), 5*time.Second)
+	t.Cleanup(func() {
+		if sqlDB, err := database.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return NewPhotoService(database.DB, storage.NewFileManager(t.TempDir()))
+}
+
+// writeTestJPEG grava um JPEG de cor única no caminho informado, criando as pastas. Cores diferentes geram
+// conteúdos (e hashes) diferentes.
+func writeTestJPEG(t *testing.T, path string, c color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("gerar JPEG: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("criar pasta: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("gravar JPEG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestImportSameFilenameFromDifferentFolders importa duas fotos diferentes com o mesmo nome, vindas de pastas
+// diferentes: nenhuma pode sobrescrever o arquivo ou o registro da outra.
+func TestImportSameFilenameFromDifferentFolders(t *testing.T) {
+	s := newTestPhotoService(t)
+	src := t.TempDir()
+	first := writeTestJPEG(t, filepath.Join(src, "a", "IMG_0001.jpg"), color.RGBA{R: 200, A: 255})
+	second := writeTestJPEG(t, filepath.Join(src, "b", "IMG_0001.jpg"), color.RGBA{B: 200, A: 255})
+
+	photoA, err := s.ImportPhotoFromPath(context.Background(), filepath.Join(src, "a", "IMG_0001.jpg"), false, PhotoOrigin{})
+	if err != nil {
+		t.Fatalf("importar a/IMG_0001.jpg: %v", err)
+	}
+	photoB, err := s.ImportPhotoFromPath(context.Background(), filepath.Join(src, "b", "IMG_0001.jpg"), false, PhotoOrigin{})
+	if err != nil {
+		t.Fatalf("importar b/IMG_0001.jpg: %v", err)
+	}
+
+	if photoA.Filename != "IMG_0001.jpg" || photoB.Filename != "IMG_0001 (2).jpg" {
+		t.Fatalf("nomes das fotos: %q e %q, esperado \"IMG_0001.jpg\" e \"IMG_0001 (2).jpg\"", photoA.Filename, photoB.Filename)
+	}
+	if photoA.StoredPath == photoB.StoredPath {
+		t.Fatalf("as duas fotos foram gravadas no mesmo caminho: %s", photoA.StoredPath)
+	}
+
+	var count int64
+	if err := database.DB.Model(&database.Photo{}).Where("id IN ?", []uint{photoA.ID, photoB.ID}).Count(&count).Error; err != nil || count != 2 {
+		t.Fatalf("fotos no banco: %d (%v), esperado 2", count, err)
+	}
+	for _, c := range []struct {
+		photo *database.Photo
+		want  []byte
+	}{{photoA, first}, {photoB, second}} {
+		got, err := os.ReadFile(c.photo.StoredPath)
+		if err != nil {
+			t.Fatalf("ler %s: %v", c.photo.StoredPath, err)
+		}
+		if !bytes.Equal(got, c.want) {
+			t.Fatalf("conteúdo de %s difere do arquivo importado", c.photo.StoredPath)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// PhotoVersion retorna a versão da foto, derivada de UpdatedAt: muda a cada alteração gravada. Os clientes a
+// devolvem (If-Match ou campo "version") para que uma edição feita sobre um estado antigo não sobrescreva
+// silenciosamente a de outro cliente.
+func PhotoVersion(photo database.Photo) string {
+	return strconv.FormatInt(photo.UpdatedAt.UnixNano(), 36)
+}
+
+// PhotoVersionConflictError indica que a foto mudou desde a versão informada pelo cliente. Current é o
+// estado atual, para o cliente mesclar as alterações antes de tentar de novo.
+type PhotoVersionConflictError struct {
+	Current database.Photo
+}
+
+func (e *PhotoVersionConflictError) Error() string {
+	return e.Localize(i18n.DefaultLocale)
+}
+
+// MessageCode retorna o código do erro.
+func (e *PhotoVersionConflictError) MessageCode() string {
+	return i18n.CodePhotoVersionConflict
+}
+
+// Localize retorna a mensagem no idioma informado.
+func (e *PhotoVersionConflictError) Localize(locale string) string {
+	return i18n.Message(locale, i18n.CodePhotoVersionConflict, PhotoVersion(e.Current))
+}
+
+// checkPhotoVersion carrega a foto na transação e, se expected não for vazio, confirma que ela ainda está
+// nessa versão. Antes da leitura, uma escrita sem efeito reserva o banco para a transação: nenhuma outra
+// escrita altera a foto entre a comparação e a gravação feita em seguida.
+func checkPhotoVersion(tx *gorm.DB, photoID uint, expected string) (*database.Photo, error) {
+	if expected != "" {
+		if err := tx.Exec("UPDATE photos SET id = id WHERE id = ?", photoID).Error; err != nil {
+			return nil, fmt.Errorf("erro ao reservar a foto: %w", err)
+		}
+	}
+	var photo database.Photo
+	if err := tx.First(&photo, photoID).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar foto: %w", err)
+	}
+	if expected != "" && PhotoVersion(photo) != expected {
+		return nil, &PhotoVersionConflictError{Current: photo}
+	}
+	return &photo, nil
+}
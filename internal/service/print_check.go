@@ -0,0 +1,166 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/thumbnail"
+	"photo-manager/internal/video"
+	"strconv"
+	"strings"
+)
+
+// Resoluções de referência para impressão, em pontos por polegada.
+const (
+	PrintDPIExcellent  = 300 // Qualidade de laboratório fotográfico
+	PrintDPIGood       = 200 // Sem perda visível à distância normal de visualização
+	PrintDPIAcceptable = 150 // Perda de nitidez perceptível de perto
+)
+
+// Classificação da qualidade de impressão, pela resolução efetiva.
+const (
+	PrintQualityExcellent  = "excellent"
+	PrintQualityGood       = "good"
+	PrintQualityAcceptable = "acceptable"
+	PrintQualityPoor       = "poor"
+)
+
+// ErrPrintCheckNoDimensions indica uma foto sem largura e altura conhecidas.
+var ErrPrintCheckNoDimensions = i18n.NewError(i18n.CodePrintCheckNoDimensions)
+
+// PrintSize é um tamanho de impressão em centímetros.
+type PrintSize struct {
+	WidthCM  float64
+	HeightCM float64
+}
+
+// printUnits converte as unidades aceitas em ParsePrintSize para centímetros.
+var printUnits = map[string]float64{"": 1, "cm": 1, "mm": 0.1, "in": 2.54}
+
+// ParsePrintSize interpreta um tamanho no formato LARGURAxALTURA, em centímetros por padrão (ex: 10x15,
+// 13x18) ou com a unidade no final (ex: 4x6in, 130x180mm). Aceita decimais com ponto ou vírgula.
+func ParsePrintSize(value string) (PrintSize, error) {
+	invalid := i18n.NewError(i18n.CodePrintSizeInvalid, value)
+	spec := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(value), " ", ""))
+
+	unit := ""
+	for _, suffix := range []string{"cm", "mm", "in"} {
+		if strings.HasSuffix(spec, suffix) {
+			unit, spec = suffix, strings.TrimSuffix(spec, suffix)
+			break
+		}
+	}
+	width, height, ok := strings.Cut(strings.ReplaceAll(spec, "×", "x"), "x")
+	if !ok {
+		return PrintSize{}, invalid
+	}
+	w, errW := strconv.ParseFloat(strings.ReplaceAll(width, ",", "."), 64)
+	h, errH := strconv.ParseFloat(strings.ReplaceAll(height, ",", "."), 64)
+	if errW != nil || errH != nil || w <= 0 || h <= 0 || math.IsInf(w, 0) || math.IsInf(h, 0) {
+		return PrintSize{}, invalid
+	}
+	factor := printUnits[unit]
+	return PrintSize{WidthCM: w * factor, HeightCM: h * factor}, nil
+}
+
+// CropRect é um recorte da imagem, em pixels a partir do canto superior esquerdo.
+type CropRect struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// PrintCheck é o resultado da verificação de uma foto para um tamanho de impressão.
+type PrintCheck struct {
+	Size        PrintSize // Tamanho pedido, girado para a orientação da foto
+	Landscape   bool      // A impressão sai na horizontal
+	ImageWidth  int
+	ImageHeight int
+	Crop        CropRect  // Maior recorte centralizado com a proporção do papel
+	CroppedPct  float64   // Porcentagem da imagem descartada pelo recorte
+	DPI         float64   // Resolução efetiva do recorte no tamanho pedido
+	Quality     string    // Classificação pela resolução (ver constantes PrintQuality*)
+	MaxSizeCM   PrintSize // Maior tamanho com a proporção pedida que ainda atinge PrintDPIExcellent
+	MinDPI      float64   // Resolução mínima exigida
+	Printable   bool      // DPI atinge MinDPI
+}
+
+// CheckPrint verifica se a foto tem resolução suficiente para ser impressa no tamanho informado e sugere
+// o recorte centralizado que preenche o papel sem bordas. O papel é girado para a orientação da foto
+// (uma foto vertical em 10x15 é impressa em 10 de largura por 15 de altura). minDPI <= 0 usa
+// PrintDPIAcceptable. Quando as dimensões não foram registradas na ingestão, lê o cabeçalho do arquivo.
+func (s *PhotoService) CheckPrint(photo *database.Photo, size PrintSize, minDPI float64) (*PrintCheck, error) {
+	if video.IsVideo(photo.MimeType) {
+		return nil, ErrNotImage
+	}
+	width, height := photo.Width, photo.Height
+	if width <= 0 || height <= 0 {
+		var err error
+		if width, height, err = thumbnail.Dimensions(photo.StoredPath); err != nil || width <= 0 || height <= 0 {
+			return nil, ErrPrintCheckNoDimensions
+		}
+	}
+	if minDPI <= 0 {
+		minDPI = PrintDPIAcceptable
+	}
+
+	// Alinha a orientação do papel à da foto; fotos quadradas seguem o tamanho como informado
+	landscape := width > height
+	if (landscape && size.WidthCM < size.HeightCM) || (width < height && size.WidthCM > size.HeightCM) {
+		size.WidthCM, size.HeightCM = size.HeightCM, size.WidthCM
+	}
+	landscape = size.WidthCM > size.HeightCM
+
+	// Maior retângulo centralizado com a proporção do papel
+	ratio := size.WidthCM / size.HeightCM
+	crop := CropRect{Width: width, Height: height}
+	if float64(width)/float64(height) > ratio {
+		crop.Width = int(math.Round(float64(height) * ratio))
+	} else {
+		crop.Height = int(math.Round(float64(width) / ratio))
+	}
+	crop.Width, crop.Height = min(max(crop.Width, 1), width), min(max(crop.Height, 1), height)
+	crop.X, crop.Y = (width-crop.Width)/2, (height-crop.Height)/2
+
+	// Após o recorte a proporção é a do papel; a menor resolução entre os dois eixos é a que limita
+	dpi := math.Min(float64(crop.Width)/(size.WidthCM/2.54), float64(crop.Height)/(size.HeightCM/2.54))
+
+	check := &PrintCheck{
+		Size:        size,
+		Landscape:   landscape,
+		ImageWidth:  width,
+		ImageHeight: height,
+		Crop:        crop,
+		CroppedPct:  100 * (1 - float64(crop.Width*crop.Height)/float64(width*height)),
+		DPI:         dpi,
+		Quality:     printQuality(dpi),
+		MinDPI:      minDPI,
+		Printable:   dpi >= minDPI,
+		MaxSizeCM: PrintSize{
+			WidthCM:  float64(crop.Width) / PrintDPIExcellent * 2.54,
+			HeightCM: float64(crop.Height) / PrintDPIExcellent * 2.54,
+		},
+	}
+	return check, nil
+}
+
+// printQuality classifica a resolução efetiva de uma impressão.
+func printQuality(dpi float64) string {
+	switch {
+	case dpi >= PrintDPIExcellent:
+		return PrintQualityExcellent
+	case dpi >= PrintDPIGood:
+		return PrintQualityGood
+	case dpi >= PrintDPIAcceptable:
+		return PrintQualityAcceptable
+	}
+	return PrintQualityPoor
+}
+
+// String formata o tamanho como LARGURAxALTURA em centímetros (ex: "10x15").
+func (p PrintSize) String() string {
+	format := func(v float64) string { return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64) }
+	return fmt.Sprintf("%sx%s", format(p.WidthCM), format(p.HeightCM))
+}
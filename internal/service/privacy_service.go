@@ -0,0 +1,173 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/exif"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/video"
+	"slices"
+)
+
+// ErrPhotoExternal indica que a foto é indexada no local e seu arquivo não pode ser alterado.
+var ErrPhotoExternal = i18n.NewError(i18n.CodePrivacyPhotoExternal)
+
+// PrivacyFinding é uma foto com campos sensíveis no EXIF.
+type PrivacyFinding struct {
+	Photo  database.Photo
+	Report exif.PrivacyReport
+}
+
+// PrivacyStripResult é o resultado da remoção de campos sensíveis de uma foto.
+type PrivacyStripResult struct {
+	PhotoID  uint
+	Stripped []string // Grupos removidos (vazio se a foto não os tinha)
+	Err      error
+}
+
+// AuditPrivacy percorre as fotos que respeitam o filtro (incluindo as ocultas) e retorna as que têm no EXIF
+// algum dos grupos de campos informados (vazio = todos). Vídeos e fotos em quarentena são ignorados.
+// Para fotos reduzidas na ingestão, o original guardado também é verificado. Retorna também o total de
+// fotos verificadas.
+func (s *PhotoService) AuditPrivacy(ctx context.Context, filter PhotoFilter, fields []string) ([]PrivacyFinding, int, error) {
+	if len(fields) == 0 {
+		fields = exif.PrivacyFields
+	}
+	filter.IncludeHidden = true
+
+	findings := []PrivacyFinding{}
+	scanned := 0
+	err := s.StreamPhotos(ctx, filter, func(photo database.Photo) error {
+		if photo.Quarantined || video.IsVideo(photo.MimeType) {
+			return nil
+		}
+		scanned++
+
+		report, err := auditPhotoFiles(photo)
+		if err != nil {
+			log.Printf("Auditoria de privacidade: foto %d ignorada: %v\n", photo.ID, err)
+			return nil
+		}
+		report.Fields = slices.DeleteFunc(report.Fields, func(field string) bool { return !slices.Contains(fields, field) })
+		if len(report.Fields) > 0 {
+			findings = append(findings, PrivacyFinding{Photo: photo, Report: *report})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, scanned, err
+	}
+	return findings, scanned, nil
+}
+
+// auditPhotoFiles audita o arquivo armazenado e, se houver, o original guardado, unindo os resultados.
+func auditPhotoFiles(photo database.Photo) (*exif.PrivacyReport, error) {
+	report, err := exif.AuditPrivacy(photo.StoredPath)
+	if err != nil {
+		return nil, err
+	}
+	if photo.OriginalPath == "" {
+		return report, nil
+	}
+	original, err := exif.AuditPrivacy(photo.OriginalPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range original.Fields {
+		if !slices.Contains(report.Fields, field) {
+			report.Fields = append(report.Fields, field)
+		}
+	}
+	report.HasGPS = report.HasGPS || original.HasGPS
+	report.BodySerial = cmp.Or(report.BodySerial, original.BodySerial)
+	report.LensSerial = cmp.Or(report.LensSerial, original.LensSerial)
+	report.CameraOwnerName = cmp.Or(report.CameraOwnerName, original.CameraOwnerName)
+	report.Artist = cmp.Or(report.Artist, original.Artist)
+	// Mantém a ordem de exif.PrivacyFields
+	slices.SortFunc(report.Fields, func(a, b string) int {
+		return slices.Index(exif.PrivacyFields, a) - slices.Index(exif.PrivacyFields, b)
+	})
+	return report, nil
+}
+
+// StripPrivacyFields remove os grupos de campos informados do EXIF das cópias armazenadas das fotos (e dos
+// originais guardados das fotos reduzidas). Fotos indexadas no local não são alteradas, e fotos bloqueadas
+// são recusadas. Como o conteúdo muda, o hash e o tamanho das fotos são atualizados; ao remover o GPS, a
+// localização também é apagada do banco. Falhas de uma foto não interrompem as demais.
+func (s *PhotoService) StripPrivacyFields(ctx context.Context, photoIDs []uint, fields []string) []PrivacyStripResult {
+	results := make([]PrivacyStripResult, 0, len(photoIDs))
+	for _, id := range photoIDs {
+		if ctx.Err() != nil {
+			results = append(results, PrivacyStripResult{PhotoID: id, Err: ctx.Err()})
+			continue
+		}
+		stripped, err := s.stripPhotoPrivacy(ctx, id, fields)
+		if err != nil {
+			log.Printf("Erro ao remover campos sensíveis da foto %d: %v\n", id, err)
+		}
+		results = append(results, PrivacyStripResult{PhotoID: id, Stripped: stripped, Err: err})
+	}
+	return results
+}
+
+// stripPhotoPrivacy remove os campos sensíveis de uma foto.
+func (s *PhotoService) stripPhotoPrivacy(ctx context.Context, id uint, fields []string) ([]string, error) {
+	photo, err := s.GetPhotoByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if photo.ManagedExternally {
+		return nil, ErrPhotoExternal
+	}
+	if err := checkPhotoUnlocked(s.DB.WithContext(ctx), photo.ID); err != nil {
+		return nil, err
+	}
+	if video.IsVideo(photo.MimeType) {
+		return nil, nil
+	}
+
+	stripped, err := exif.StripPrivacyFields(photo.StoredPath, fields)
+	if err != nil {
+		return nil, err
+	}
+	if photo.OriginalPath != "" {
+		strippedOriginal, err := exif.StripPrivacyFields(photo.OriginalPath, fields)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return stripped, fmt.Errorf("cópia armazenada alterada, mas o original falhou: %w", err)
+		}
+		for _, field := range strippedOriginal {
+			if !slices.Contains(stripped, field) {
+				stripped = append(stripped, field)
+			}
+		}
+	}
+	if len(stripped) == 0 {
+		return nil, nil
+	}
+
+	hash, err := calculateMD5Hash(photo.StoredPath)
+	if err != nil {
+		return stripped, err
+	}
+	info, err := os.Stat(photo.StoredPath)
+	if err != nil {
+		return stripped, err
+	}
+	updates := map[string]interface{}{"hash": hash, "file_size": info.Size()}
+	if slices.Contains(stripped, exif.PrivacyGPS) {
+		updates["latitude"] = nil
+		updates["longitude"] = nil
+	}
+	if result := s.DB.WithContext(ctx).Model(photo).Updates(updates); result.Error != nil {
+		return stripped, fmt.Errorf("campos removidos do arquivo, mas não foi possível atualizar a foto: %w", result.Error)
+	}
+
+	s.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": photo.ID})
+	return stripped, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"fmt"
+	"photo-manager/internal/database"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// PublicGalleryService expõe, somente para leitura, os álbuns publicados na galeria pública (portfólio).
+// Fotos ocultas, em quarentena e vídeos nunca aparecem na galeria.
+type PublicGalleryService struct {
+	DB           *gorm.DB
+	AlbumService *AlbumService
+	AlbumIDs     []uint // Álbuns publicados, na ordem de exibição
+}
+
+// NewPublicGalleryService cria uma nova instância de PublicGalleryService.
+func NewPublicGalleryService(db *gorm.DB, as *AlbumService, albumIDs []uint) *PublicGalleryService {
+	return &PublicGalleryService{
+		DB:           db,
+		AlbumService: as,
+		AlbumIDs:     uniqueIDs(albumIDs),
+	}
+}
+
+// Enabled indica se há álbuns publicados.
+func (s *PublicGalleryService) Enabled() bool {
+	return len(s.AlbumIDs) > 0
+}
+
+// PublicAlbum é um álbum publicado, com a contagem e a capa restritas às fotos visíveis.
+type PublicAlbum struct {
+	AlbumSummary
+	Cover *database.Photo // Foto mais recente (nil se o álbum não tem fotos visíveis)
+}
+
+// Albums retorna os álbuns publicados que existem, na ordem da configuração.
+func (s *PublicGalleryService) Albums() ([]PublicAlbum, error) {
+	byID, err := s.AlbumService.GetAlbumSummaries(s.AlbumIDs)
+	if err != nil {
+		return nil, err
+	}
+	albums := make([]PublicAlbum, 0, len(byID))
+	for _, id := range s.AlbumIDs {
+		summary, ok := byID[id]
+		if !ok {
+			continue
+		}
+		album := PublicAlbum{AlbumSummary: summary}
+		if err := s.visiblePhotos(id).Count(&album.PhotoCount).Error; err != nil {
+			return nil, fmt.Errorf("erro ao contar as fotos do álbum: %w", err)
+		}
+		var covers []database.Photo
+		if err := s.visiblePhotos(id).Order("COALESCE(photos.exif_date, photos.upload_date) DESC").Limit(1).Find(&covers).Error; err != nil {
+			return nil, fmt.Errorf("erro ao buscar a capa do álbum: %w", err)
+		}
+		if len(covers) > 0 {
+			album.Cover = &covers[0]
+		}
+		albums = append(albums, album)
+	}
+	return albums, nil
+}
+
+// Album retorna um álbum publicado e as suas fotos visíveis, da mais recente para a mais antiga (PhotoCount
+// conta apenas as visíveis). O álbum é indicado pelo identificador público (ou pelo ID, nos links antigos).
+// Álbuns não publicados resultam em gorm.ErrRecordNotFound, como se não existissem.
+func (s *PublicGalleryService) Album(ref string) (*AlbumSummary, []database.Photo, error) {
+	id, err := s.publishedAlbumID(ref)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar álbum: %w", err)
+	}
+	album, err := s.AlbumService.GetAlbum(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var photos []database.Photo
+	result := s.visiblePhotos(id).
+		Order("COALESCE(photos.exif_date, photos.upload_date) DESC").Order("photos.id DESC").
+		Find(&photos)
+	if result.Error != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar fotos do álbum: %w", result.Error)
+	}
+	album.PhotoCount = int64(len(photos))
+	return album, photos, nil
+}
+
+// Photo retorna uma foto visível de um álbum publicado, ou gorm.ErrRecordNotFound. O álbum e a foto são
+// indicados pelos identificadores públicos (ou pelos IDs, nos links antigos).
+func (s *PublicGalleryService) Photo(albumRef, photoRef string) (*database.Photo, error) {
+	albumID, err := s.publishedAlbumID(albumRef)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar foto: %w", err)
+	}
+	photoID, err := PhotoIDByRef(s.DB, photoRef)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar foto: %w", err)
+	}
+	var photo database.Photo
+	if err := s.visiblePhotos(albumID).Where("photos.id = ?", photoID).First(&photo).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar foto: %w", err)
+	}
+	return &photo, nil
+}
+
+// publishedAlbumID resolve a referência a um álbum publicado; os demais resultam em gorm.ErrRecordNotFound.
+func (s *PublicGalleryService) publishedAlbumID(ref string) (uint, error) {
+	id, err := AlbumIDByRef(s.DB, ref)
+	if err != nil {
+		return 0, err
+	}
+	if !slices.Contains(s.AlbumIDs, id) {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return id, nil
+}
+
+// visiblePhotos monta a consulta das fotos de um álbum que podem ser exibidas publicamente.
+func (s *PublicGalleryService) visiblePhotos(albumID uint) *gorm.DB {
+	return s.DB.Model(&database.Photo{}).
+		Joins("JOIN album_photos ON album_photos.photo_id = photos.id AND album_photos.deleted_at IS NULL").
+		Where("album_photos.album_id = ?", albumID).
+		Where("photos.hidden = ? AND photos.quarantined = ? AND photos.mime_type NOT LIKE ?", false, false, "video/%")
+}
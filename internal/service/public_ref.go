@@ -0,0 +1,40 @@
+package service
+
+import (
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/publicid"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// PhotoIDByRef resolve a referência a uma foto vinda de uma URL pública: o identificador público ou, nos
+// links criados antes dele, o ID numérico. Referências desconhecidas resultam em gorm.ErrRecordNotFound.
+func PhotoIDByRef(db *gorm.DB, ref string) (uint, error) {
+	return idByRef(db, &database.Photo{}, ref)
+}
+
+// AlbumIDByRef resolve a referência a um álbum vinda de uma URL pública, como PhotoIDByRef.
+func AlbumIDByRef(db *gorm.DB, ref string) (uint, error) {
+	return idByRef(db, &database.Album{}, ref)
+}
+
+// idByRef busca o ID do registro de model com o identificador público (ou ID numérico) ref.
+func idByRef(db *gorm.DB, model interface{}, ref string) (uint, error) {
+	if id, err := strconv.ParseUint(ref, 10, 32); err == nil && id > 0 {
+		return uint(id), nil
+	}
+	publicID, ok := publicid.Normalize(ref)
+	if !ok {
+		return 0, fmt.Errorf("identificador inválido '%s': %w", ref, gorm.ErrRecordNotFound)
+	}
+	var ids []uint
+	if err := db.Model(model).Where("public_id = ?", publicID).Limit(1).Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("erro ao buscar o identificador público: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("identificador público '%s' não encontrado: %w", publicID, gorm.ErrRecordNotFound)
+	}
+	return ids[0], nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+)
+
+// maxProcessingFailures é a quantidade de falhas consecutivas de decodificação após a qual
+// a foto é colocada em quarentena.
+const maxProcessingFailures = 3
+
+// recordProcessingFailure contabiliza uma falha ao decodificar a imagem da foto e, ao atingir
+// maxProcessingFailures, coloca a foto em quarentena.
+func (s *PhotoService) recordProcessingFailure(ctx context.Context, photo *database.Photo, cause error) error {
+	photo.ProcessingFailures++
+	if photo.ProcessingFailures >= maxProcessingFailures {
+		return s.quarantinePhoto(ctx, photo, cause.Error())
+	}
+
+	if result := s.DB.WithContext(ctx).Model(photo).Update("processing_failures", photo.ProcessingFailures); result.Error != nil {
+		return fmt.Errorf("não foi possível registrar a falha de processamento: %w", result.Error)
+	}
+	return nil
+}
+
+// quarantinePhoto isola uma foto que falha repetidamente no processamento: ela deixa de ser decodificada
+// (miniaturas, etc.) e, se o arquivo pertence ao armazenamento gerenciado, ele é movido para QuarantineDir.
+// Originais indexados no local nunca são movidos.
+func (s *PhotoService) quarantinePhoto(ctx context.Context, photo *database.Photo, reason string) error {
+	storedPath := photo.StoredPath
+	if !photo.ManagedExternally && s.QuarantineDir != "" {
+		if err := os.MkdirAll(s.QuarantineDir, 0755); err != nil {
+			return fmt.Errorf("não foi possível criar o diretório de quarentena '%s': %w", s.QuarantineDir, err)
+		}
+
+		target := filepath.Join(s.QuarantineDir, fmt.Sprintf("%d_%s", photo.ID, filepath.Base(photo.StoredPath)))
+		if err := os.Rename(photo.StoredPath, target); err != nil {
+			// Ex: o volume da foto está em outro sistema de arquivos; a foto fica apenas marcada
+			log.Printf("Aviso: não foi possível mover a foto %d para a quarentena: %v\n", photo.ID, err)
+		} else {
+			storedPath = target
+		}
+	}
+
+	result := s.DB.WithContext(ctx).Model(photo).Updates(map[string]interface{}{
+		"processing_failures": photo.ProcessingFailures,
+		"quarantined":         true,
+		"quarantine_reason":   reason,
+		"stored_path":         storedPath,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("não foi possível colocar a foto em quarentena: %w", result.Error)
+	}
+
+	log.Printf("Foto %d colocada em quarentena após %d falhas: %s\n", photo.ID, photo.ProcessingFailures, reason)
+	s.Events.Publish(events.TypePhotoQuarantined, map[string]interface{}{"photo_id": photo.ID, "reason": reason})
+	return nil
+}
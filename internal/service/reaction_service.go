@@ -0,0 +1,229 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxVoterNameLength limita o nome informado por quem reage a uma foto.
+const maxVoterNameLength = 64
+
+// ErrReactionKindInvalid indica um tipo de reação desconhecido.
+var ErrReactionKindInvalid = i18n.NewError(i18n.CodeReactionKindInvalid)
+
+// ErrReactionVoterInvalid indica um nome de visitante vazio ou longo demais.
+var ErrReactionVoterInvalid = i18n.NewError(i18n.CodeReactionVoterInvalid)
+
+// ErrReactionPhotoNotShared indica uma reação a uma foto que não está no álbum compartilhado.
+var ErrReactionPhotoNotShared = i18n.NewError(i18n.CodeReactionPhotoNotShared)
+
+// reactionKinds são os tipos de reação aceitos.
+var reactionKinds = map[string]bool{
+	database.ReactionThumbsUp: true,
+	database.ReactionHeart:    true,
+}
+
+// ReactionService gerencia as reações (votos) às fotos de álbuns compartilhados.
+type ReactionService struct {
+	DB           *gorm.DB
+	ShareService *ShareService
+}
+
+// NewReactionService cria uma nova instância de ReactionService.
+func NewReactionService(db *gorm.DB, shares *ShareService) *ReactionService {
+	return &ReactionService{
+		DB:           db,
+		ShareService: shares,
+	}
+}
+
+// PhotoReactions resume as reações de uma foto em um álbum.
+type PhotoReactions struct {
+	PhotoID uint
+	Counts  map[string]int // Quantidade de reações por tipo
+	Total   int
+	Voters  []string // Quem reagiu, em ordem alfabética
+}
+
+// ReactedPhoto é uma foto do álbum com o resumo das suas reações.
+type ReactedPhoto struct {
+	Photo     database.Photo
+	Reactions PhotoReactions
+}
+
+// React registra a reação de um visitante do link de compartilhamento a uma foto do álbum. Reagir de novo
+// com o mesmo nome e tipo não tem efeito. Retorna o resumo atualizado das reações da foto.
+func (s *ReactionService) React(token, photoRef, voter, kind string) (*PhotoReactions, error) {
+	link, photoID, voter, err := s.prepare(token, photoRef, voter, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	reaction := database.PhotoReaction{AlbumID: link.AlbumID, PhotoID: photoID, Voter: voter, Kind: kind}
+	if err := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&reaction).Error; err != nil {
+		return nil, fmt.Errorf("não foi possível registrar a reação: %w", err)
+	}
+	return s.photoReactions(link.AlbumID, photoID)
+}
+
+// Unreact remove a reação de um visitante a uma foto do álbum (sem erro se ela não existir).
+func (s *ReactionService) Unreact(token, photoRef, voter, kind string) (*PhotoReactions, error) {
+	link, photoID, voter, err := s.prepare(token, photoRef, voter, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.DB.Unscoped().
+		Where("album_id = ? AND photo_id = ? AND voter = ? AND kind = ?", link.AlbumID, photoID, voter, kind).
+		Delete(&database.PhotoReaction{})
+	if result.Error != nil {
+		return nil, fmt.Errorf("não foi possível remover a reação: %w", result.Error)
+	}
+	return s.photoReactions(link.AlbumID, photoID)
+}
+
+// TopSharedPhotos retorna as fotos mais votadas do álbum de um link de compartilhamento.
+func (s *ReactionService) TopSharedPhotos(token, kind string, limit int) ([]ReactedPhoto, error) {
+	link, err := s.ShareService.GetShareLink(token)
+	if err != nil {
+		return nil, err
+	}
+	return s.TopPhotos(link.AlbumID, kind, limit)
+}
+
+// TopPhotos retorna as fotos do álbum com pelo menos uma reação, da mais votada para a menos votada
+// (empates pela ordem de inclusão no álbum). Com kind, conta apenas as reações desse tipo; limit <= 0
+// retorna todas. Fotos que saíram do álbum deixam de ser listadas, mas os votos são mantidos.
+func (s *ReactionService) TopPhotos(albumID uint, kind string, limit int) ([]ReactedPhoto, error) {
+	if kind != "" && !reactionKinds[kind] {
+		return nil, ErrReactionKindInvalid
+	}
+	if _, err := s.ShareService.AlbumService.GetAlbum(albumID); err != nil {
+		return nil, err
+	}
+
+	query := s.DB.Model(&database.PhotoReaction{}).
+		Joins("JOIN album_photos ON album_photos.photo_id = photo_reactions.photo_id AND album_photos.album_id = photo_reactions.album_id AND album_photos.deleted_at IS NULL").
+		Where("photo_reactions.album_id = ?", albumID).
+		Order("album_photos.id").Order("photo_reactions.id")
+	if kind != "" {
+		query = query.Where("photo_reactions.kind = ?", kind)
+	}
+	var reactions []database.PhotoReaction
+	if err := query.Select("photo_reactions.*").Find(&reactions).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar as reações do álbum: %w", err)
+	}
+
+	summaries := summarizeReactions(reactions)
+	sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].Total > summaries[j].Total })
+	if limit > 0 && len(summaries) > limit {
+		summaries = summaries[:limit]
+	}
+
+	ids := make([]uint, len(summaries))
+	for i, summary := range summaries {
+		ids[i] = summary.PhotoID
+	}
+	var photos []database.Photo
+	if len(ids) > 0 {
+		if err := s.DB.Where("id IN ?", ids).Find(&photos).Error; err != nil {
+			return nil, fmt.Errorf("erro ao buscar as fotos mais votadas: %w", err)
+		}
+	}
+	photosByID := make(map[uint]database.Photo, len(photos))
+	for _, photo := range photos {
+		photosByID[photo.ID] = photo
+	}
+
+	top := make([]ReactedPhoto, 0, len(summaries))
+	for _, summary := range summaries {
+		if photo, ok := photosByID[summary.PhotoID]; ok {
+			top = append(top, ReactedPhoto{Photo: photo, Reactions: summary})
+		}
+	}
+	return top, nil
+}
+
+// prepare valida a reação e retorna o link de compartilhamento, o ID da foto (indicada pelo identificador
+// público ou pelo ID) e o nome normalizado do visitante.
+func (s *ReactionService) prepare(token, photoRef, voter, kind string) (*database.ShareLink, uint, string, error) {
+	if !reactionKinds[kind] {
+		return nil, 0, "", ErrReactionKindInvalid
+	}
+	voter = strings.Join(strings.Fields(voter), " ")
+	if voter == "" || utf8.RuneCountInString(voter) > maxVoterNameLength {
+		return nil, 0, "", ErrReactionVoterInvalid
+	}
+
+	link, err := s.ShareService.GetShareLink(token)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	photoID, err := PhotoIDByRef(s.DB, photoRef)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, 0, "", ErrReactionPhotoNotShared
+	}
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	var count int64
+	err = s.DB.Model(&database.AlbumPhoto{}).Where("album_id = ? AND photo_id = ?", link.AlbumID, photoID).Count(&count).Error
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("erro ao verificar as fotos do álbum: %w", err)
+	}
+	if count == 0 {
+		return nil, 0, "", ErrReactionPhotoNotShared
+	}
+	return link, photoID, voter, nil
+}
+
+// photoReactions retorna o resumo das reações de uma foto no álbum.
+func (s *ReactionService) photoReactions(albumID, photoID uint) (*PhotoReactions, error) {
+	var reactions []database.PhotoReaction
+	if err := s.DB.Where("album_id = ? AND photo_id = ?", albumID, photoID).Find(&reactions).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar as reações da foto: %w", err)
+	}
+
+	summary := PhotoReactions{PhotoID: photoID, Counts: map[string]int{}, Voters: []string{}}
+	if summaries := summarizeReactions(reactions); len(summaries) > 0 {
+		summary = summaries[0]
+	}
+	return &summary, nil
+}
+
+// summarizeReactions agrupa as reações por foto, na ordem em que cada foto aparece pela primeira vez.
+func summarizeReactions(reactions []database.PhotoReaction) []PhotoReactions {
+	var summaries []PhotoReactions
+	index := make(map[uint]int)
+	voters := make(map[uint]map[string]bool)
+	for _, reaction := range reactions {
+		i, ok := index[reaction.PhotoID]
+		if !ok {
+			i = len(summaries)
+			index[reaction.PhotoID] = i
+			voters[reaction.PhotoID] = make(map[string]bool)
+			summaries = append(summaries, PhotoReactions{PhotoID: reaction.PhotoID, Counts: map[string]int{}})
+		}
+		summaries[i].Counts[reaction.Kind]++
+		summaries[i].Total++
+		voters[reaction.PhotoID][reaction.Voter] = true
+	}
+	for i := range summaries {
+		names := make([]string, 0, len(voters[summaries[i].PhotoID]))
+		for name := range voters[summaries[i].PhotoID] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		summaries[i].Voters = names
+	}
+	return summaries
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SearchKeys são os filtros aceitos na linguagem de busca (ver ApplySearchQuery).
+var SearchKeys = []string{"tag", "year", "month", "camera", "lens", "filename", "album", "source", "device", "is", "has"}
+
+// negatableSearchKeys são os filtros que aceitam o prefixo "-" (exclusão).
+var negatableSearchKeys = []string{"tag", "has", "album", "source"}
+
+// searchTerm é um termo da busca: chave:valor (key vazia para texto livre), possivelmente negado.
+type searchTerm struct {
+	raw     string
+	negated bool
+	key     string
+	value   string
+}
+
+// ApplySearchQuery interpreta a busca compacta q e acrescenta seus critérios ao filtro. Os termos são
+// separados por espaços e combinados com E; valores com espaços vão entre aspas. Exemplo:
+//
+//	tag:praia year:2023 camera:"Canon EOS" has:gps -tag:screenshots
+//
+// Filtros: tag:<nome> (repetível), year:<ano>, month:<mês> (requer o ano), camera:<texto>, lens:<texto>,
+// filename:<texto>, album:<id>, source:<canal>, device:<nome>, is:favorite|quarantined|panorama|360 e
+// has:gps|tags|date|album|depth|hdr. Apenas tag, has, album e source aceitam "-" (ex: -tag:x, -has:gps, -album:3).
+// Texto sem chave é procurado no nome do arquivo. Erros são i18n.Error, com o termo inválido na mensagem.
+func ApplySearchQuery(filter *PhotoFilter, q string) error {
+	terms, err := tokenizeSearchQuery(q)
+	if err != nil {
+		return err
+	}
+
+	var words []string
+	for _, term := range terms {
+		if term.key == "" {
+			if term.value != "" {
+				words = append(words, term.value)
+			}
+			continue
+		}
+		if !slices.Contains(SearchKeys, term.key) {
+			return i18n.NewError(i18n.CodeSearchUnknownKey, term.key, strings.Join(SearchKeys, ", "))
+		}
+		if term.negated && !slices.Contains(negatableSearchKeys, term.key) {
+			return i18n.NewError(i18n.CodeSearchNegationUnsupported, term.key)
+		}
+		if term.value == "" {
+			return i18n.NewError(i18n.CodeSearchInvalidValue, term.raw)
+		}
+		if !applySearchTerm(filter, term) {
+			return i18n.NewError(i18n.CodeSearchInvalidValue, term.raw)
+		}
+	}
+
+	if len(words) > 0 {
+		filter.Filename = strings.Join(words, " ")
+	}
+	if filter.Month != 0 && filter.Year == 0 {
+		return i18n.NewError(i18n.CodeSearchMonthNeedsYear)
+	}
+	return nil
+}
+
+// applySearchTerm aplica um termo com chave conhecida ao filtro. Retorna false se o valor for inválido.
+func applySearchTerm(filter *PhotoFilter, term searchTerm) bool {
+	value := term.value
+	switch term.key {
+	case "tag":
+		if term.negated {
+			filter.ExcludeTags = append(filter.ExcludeTags, value)
+		} else {
+			filter.Tags = append(filter.Tags, value)
+		}
+	case "year":
+		year, err := strconv.Atoi(value)
+		if err != nil || year < 1 || year > 9999 {
+			return false
+		}
+		filter.Year = year
+	case "month":
+		month, err := strconv.Atoi(value)
+		if err != nil || month < 1 || month > 12 {
+			return false
+		}
+		filter.Month = month
+	case "camera":
+		filter.Camera = value
+	case "lens":
+		filter.Lens = value
+	case "filename":
+		filter.Filename = value
+	case "album":
+		albumID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || albumID == 0 {
+			return false
+		}
+		if term.negated {
+			filter.ExcludeAlbumID = uint(albumID)
+		} else {
+			filter.AlbumID = uint(albumID)
+		}
+	case "source":
+		if !slices.Contains(slices.Concat(database.ClientSources, []string{database.SourceImport, database.SourceMirror}), value) {
+			return false
+		}
+		if term.negated {
+			filter.ExcludeSource = value
+		} else {
+			filter.Source = value
+		}
+	case "device":
+		filter.Device = value
+	case "is":
+		switch strings.ToLower(value) {
+		case "favorite":
+			filter.FavoritesOnly = true
+		case "quarantined":
+			filter.QuarantinedOnly = true
+		case "panorama":
+			filter.Projection = ProjectionAny
+		case "360":
+			filter.Projection = database.ProjectionEquirectangular
+		default:
+			return false
+		}
+	case "has":
+		present := !term.negated
+		switch strings.ToLower(value) {
+		case "gps":
+			filter.GPS = &present
+		case "tags":
+			filter.Tagged = &present
+		case "date":
+			filter.Dated = &present
+		case "album":
+			filter.InAlbum = &present
+		case "depth":
+			filter.DepthMap = &present
+		case "hdr":
+			filter.GainMap = &present
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// tokenizeSearchQuery separa a busca em termos. Aspas duplas agrupam espaços tanto no valor
+// (camera:"Canon EOS") quanto no texto livre ("férias 2023").
+func tokenizeSearchQuery(q string) ([]searchTerm, error) {
+	var terms []searchTerm
+	var current strings.Builder
+	inQuote, started := false, false
+
+	flush := func() {
+		if started {
+			terms = append(terms, parseSearchTerm(current.String()))
+		}
+		current.Reset()
+		started = false
+	}
+
+	for _, r := range q {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			started = true
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if inQuote {
+		return nil, i18n.NewError(i18n.CodeSearchUnclosedQuote)
+	}
+	flush()
+	return terms, nil
+}
+
+// parseSearchTerm interpreta um termo já sem aspas: "-" inicial nega o termo e o texto até o primeiro ":"
+// é a chave. Termos sem ":" (ou com ":" no início) são texto livre.
+func parseSearchTerm(raw string) searchTerm {
+	term := searchTerm{raw: raw, value: raw}
+	text := raw
+	if strings.HasPrefix(text, "-") && len(text) > 1 {
+		term.negated = true
+		text = text[1:]
+	}
+	key, value, ok := strings.Cut(text, ":")
+	if !ok || key == "" {
+		term.negated = false
+		return term
+	}
+	term.key = strings.ToLower(key)
+	term.value = value
+	return term
+}
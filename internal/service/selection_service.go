@@ -0,0 +1,281 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// selectionTTL é o tempo sem uso após o qual uma seleção expira.
+const selectionTTL = 24 * time.Hour
+
+// ErrSelectionNotFound indica uma seleção inexistente, removida ou expirada.
+var ErrSelectionNotFound = i18n.NewError(i18n.CodeSelectionNotFound)
+
+// ErrSelectionEmpty indica uma ação sobre uma seleção sem fotos.
+var ErrSelectionEmpty = i18n.NewError(i18n.CodeSelectionEmpty)
+
+// SelectionService gerencia as seleções temporárias de fotos (cestas) e as ações em lote sobre elas.
+type SelectionService struct {
+	DB     *gorm.DB
+	Albums *AlbumService
+	Tags   *TagService
+	Shares *ShareService
+}
+
+// NewSelectionService cria uma nova instância de SelectionService.
+func NewSelectionService(db *gorm.DB, albums *AlbumService, tags *TagService, shares *ShareService) *SelectionService {
+	return &SelectionService{
+		DB:     db,
+		Albums: albums,
+		Tags:   tags,
+		Shares: shares,
+	}
+}
+
+// SelectionSummary combina uma seleção com as suas fotos.
+type SelectionSummary struct {
+	database.Selection
+	PhotoIDs   []uint // Na ordem em que entraram na seleção
+	TotalBytes int64  // Soma do tamanho dos arquivos das fotos
+}
+
+// CreateSelection cria uma seleção, opcionalmente já com fotos. As seleções expiradas são removidas.
+func (s *SelectionService) CreateSelection(photoIDs []uint) (*SelectionSummary, error) {
+	if err := s.removeExpired(); err != nil {
+		return nil, err
+	}
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+
+	selection := database.Selection{Token: token, ExpiresAt: time.Now().Add(selectionTTL)}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&selection).Error; err != nil {
+			return fmt.Errorf("não foi possível criar a seleção: %w", err)
+		}
+		_, err := addSelectionPhotos(tx, selection.ID, photoIDs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.summary(selection)
+}
+
+// GetSelection retorna uma seleção com as suas fotos, renovando a validade.
+func (s *SelectionService) GetSelection(token string) (*SelectionSummary, error) {
+	selection, err := touchSelection(s.DB, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.summary(*selection)
+}
+
+// AddPhotos acrescenta fotos à seleção; as que já estão nela são ignoradas. Retorna quantas entraram.
+func (s *SelectionService) AddPhotos(token string, photoIDs []uint) (int, *SelectionSummary, error) {
+	var selection *database.Selection
+	added := 0
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if selection, err = touchSelection(tx, token); err != nil {
+			return err
+		}
+		added, err = addSelectionPhotos(tx, selection.ID, photoIDs)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	summary, err := s.summary(*selection)
+	return added, summary, err
+}
+
+// RemovePhotos retira fotos da seleção, sem alterar as fotos. Retorna quantas saíram.
+func (s *SelectionService) RemovePhotos(token string, photoIDs []uint) (int, *SelectionSummary, error) {
+	selection, err := touchSelection(s.DB, token)
+	if err != nil {
+		return 0, nil, err
+	}
+	removed := 0
+	if len(photoIDs) > 0 {
+		result := s.DB.Where("selection_id = ? AND photo_id IN ?", selection.ID, uniqueIDs(photoIDs)).Delete(&database.SelectionPhoto{})
+		if result.Error != nil {
+			return 0, nil, fmt.Errorf("não foi possível retirar as fotos da seleção: %w", result.Error)
+		}
+		removed = int(result.RowsAffected)
+	}
+	summary, err := s.summary(*selection)
+	return removed, summary, err
+}
+
+// DeleteSelection descarta uma seleção. As fotos não são alteradas.
+func (s *SelectionService) DeleteSelection(token string) error {
+	selection, err := findSelection(s.DB, token)
+	if err != nil {
+		return err
+	}
+	return deleteSelections(s.DB, []uint{selection.ID})
+}
+
+// PhotoIDs retorna as fotos da seleção que ainda existem, renovando a validade. Uma seleção vazia resulta em
+// ErrSelectionEmpty.
+func (s *SelectionService) PhotoIDs(token string) ([]uint, error) {
+	summary, err := s.GetSelection(token)
+	if err != nil {
+		return nil, err
+	}
+	if len(summary.PhotoIDs) == 0 {
+		return nil, ErrSelectionEmpty
+	}
+	return summary.PhotoIDs, nil
+}
+
+// AddToAlbum adiciona as fotos da seleção a um álbum existente. Retorna quantas foram adicionadas.
+func (s *SelectionService) AddToAlbum(token string, albumID uint) (int, error) {
+	photoIDs, err := s.PhotoIDs(token)
+	if err != nil {
+		return 0, err
+	}
+	return s.Albums.AddPhotosToAlbum(albumID, photoIDs)
+}
+
+// CreateAlbum cria um álbum com as fotos da seleção.
+func (s *SelectionService) CreateAlbum(token, name, description string) (*database.Album, error) {
+	photoIDs, err := s.PhotoIDs(token)
+	if err != nil {
+		return nil, err
+	}
+	return s.Albums.CreateAlbumWithPhotos(name, description, photoIDs)
+}
+
+// AddTags acrescenta tags às fotos da seleção, mantendo as que elas já têm. Fotos bloqueadas são ignoradas.
+// Retorna quantas fotos receberam alguma tag nova.
+func (s *SelectionService) AddTags(token string, names []string) (int, error) {
+	photoIDs, err := s.PhotoIDs(token)
+	if err != nil {
+		return 0, err
+	}
+	return s.Tags.AddTags(photoIDs, names)
+}
+
+// Share cria um álbum com as fotos da seleção e um link de compartilhamento para ele.
+func (s *SelectionService) Share(token, name, description string) (*database.Album, *database.ShareLink, error) {
+	album, err := s.CreateAlbum(token, name, description)
+	if err != nil {
+		return nil, nil, err
+	}
+	link, err := s.Shares.CreateAlbumShare(album.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return album, link, nil
+}
+
+// summary carrega as fotos de uma seleção, ignorando as que foram removidas da biblioteca.
+func (s *SelectionService) summary(selection database.Selection) (*SelectionSummary, error) {
+	var rows []struct {
+		ID       uint
+		FileSize int64
+	}
+	err := s.DB.Model(&database.SelectionPhoto{}).
+		Select("photos.id, photos.file_size").
+		Joins("JOIN photos ON photos.id = selection_photos.photo_id AND photos.deleted_at IS NULL").
+		Where("selection_photos.selection_id = ?", selection.ID).
+		Order("selection_photos.created_at, selection_photos.photo_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar as fotos da seleção: %w", err)
+	}
+
+	summary := &SelectionSummary{Selection: selection, PhotoIDs: make([]uint, 0, len(rows))}
+	for _, row := range rows {
+		summary.PhotoIDs = append(summary.PhotoIDs, row.ID)
+		summary.TotalBytes += row.FileSize
+	}
+	return summary, nil
+}
+
+// removeExpired apaga as seleções expiradas e as suas fotos.
+func (s *SelectionService) removeExpired() error {
+	var ids []uint
+	if err := s.DB.Model(&database.Selection{}).Where("expires_at <= ?", time.Now()).Pluck("id", &ids).Error; err != nil {
+		return fmt.Errorf("erro ao buscar as seleções expiradas: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return deleteSelections(s.DB, ids)
+}
+
+// findSelection busca uma seleção válida pelo token.
+func findSelection(db *gorm.DB, token string) (*database.Selection, error) {
+	var selection database.Selection
+	err := db.Where("token = ? AND expires_at > ?", token, time.Now()).First(&selection).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSelectionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar a seleção: %w", err)
+	}
+	return &selection, nil
+}
+
+// touchSelection busca uma seleção válida pelo token e renova a sua validade.
+func touchSelection(db *gorm.DB, token string) (*database.Selection, error) {
+	selection, err := findSelection(db, token)
+	if err != nil {
+		return nil, err
+	}
+	selection.ExpiresAt = time.Now().Add(selectionTTL)
+	if err := db.Model(selection).Update("expires_at", selection.ExpiresAt).Error; err != nil {
+		return nil, fmt.Errorf("não foi possível renovar a seleção: %w", err)
+	}
+	return selection, nil
+}
+
+// addSelectionPhotos acrescenta fotos existentes a uma seleção, ignorando as que já estão nela. Fotos
+// inexistentes rejeitam a operação inteira.
+func addSelectionPhotos(tx *gorm.DB, selectionID uint, photoIDs []uint) (int, error) {
+	ids := uniqueIDs(photoIDs)
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	var existing int64
+	if err := tx.Model(&database.Photo{}).Where("id IN ?", ids).Count(&existing).Error; err != nil {
+		return 0, fmt.Errorf("erro ao verificar fotos: %w", err)
+	}
+	if int(existing) != len(ids) {
+		return 0, i18n.NewError(i18n.CodePhotosNotFound)
+	}
+
+	now := time.Now()
+	rows := make([]database.SelectionPhoto, 0, len(ids))
+	for _, photoID := range ids {
+		rows = append(rows, database.SelectionPhoto{SelectionID: selectionID, PhotoID: photoID, CreatedAt: now})
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(rows, 500)
+	if result.Error != nil {
+		return 0, fmt.Errorf("não foi possível adicionar as fotos à seleção: %w", result.Error)
+	}
+	return int(result.RowsAffected), nil
+}
+
+// deleteSelections apaga definitivamente as seleções e as suas fotos.
+func deleteSelections(db *gorm.DB, ids []uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("selection_id IN ?", ids).Delete(&database.SelectionPhoto{}).Error; err != nil {
+			return fmt.Errorf("não foi possível remover as fotos da seleção: %w", err)
+		}
+		if err := tx.Unscoped().Delete(&database.Selection{}, ids).Error; err != nil {
+			return fmt.Errorf("não foi possível remover a seleção: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"photo-manager/internal/database"
+	"slices"
+	"testing"
+)
+
+// TestDeletePhotoLeavesSelections apaga uma foto que está numa seleção: ela sai da seleção junto, e a
+// seleção continua com as demais.
+func TestDeletePhotoLeavesSelections(t *testing.T) {
+	ps := newTestPhotoService(t)
+	s := NewSelectionService(ps.DB, nil, nil, nil)
+
+	kept := database.Photo{Filename: "a.jpg", StoredPath: "/fotos/a.jpg", Hash: "a"}
+	removed := database.Photo{Filename: "b.jpg", StoredPath: "/fotos/b.jpg", Hash: "b"}
+	for _, photo := range []*database.Photo{&kept, &removed} {
+		if err := ps.DB.Create(photo).Error; err != nil {
+			t.Fatalf("criar foto: %v", err)
+		}
+	}
+	selection, err := s.CreateSelection([]uint{kept.ID, removed.ID})
+	if err != nil {
+		t.Fatalf("criar seleção: %v", err)
+	}
+	if len(selection.PhotoIDs) != 2 {
+		t.Fatalf("fotos na seleção: %v, esperado 2", selection.PhotoIDs)
+	}
+
+	if err := ps.DeletePhoto(context.Background(), removed.ID, false); err != nil {
+		t.Fatalf("apagar foto: %v", err)
+	}
+
+	var count int64
+	if err := ps.DB.Model(&database.SelectionPhoto{}).Where("photo_id = ?", removed.ID).Count(&count).Error; err != nil || count != 0 {
+		t.Fatalf("seleções com a foto apagada: %d (%v), esperado 0", count, err)
+	}
+	summary, err := s.GetSelection(selection.Token)
+	if err != nil {
+		t.Fatalf("ler seleção: %v", err)
+	}
+	if !slices.Equal(summary.PhotoIDs, []uint{kept.ID}) {
+		t.Fatalf("fotos na seleção: %v, esperado [%d]", summary.PhotoIDs, kept.ID)
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"photo-manager/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// ShareService gerencia links públicos de compartilhamento de álbuns.
+type ShareService struct {
+	DB           *gorm.DB
+	AlbumService *AlbumService
+}
+
+// NewShareService cria uma nova instância de ShareService.
+func NewShareService(db *gorm.DB, albums *AlbumService) *ShareService {
+	return &ShareService{
+		DB:           db,
+		AlbumService: albums,
+	}
+}
+
+// SharedAlbum é o conteúdo exibido por um link de compartilhamento.
+type SharedAlbum struct {
+	Link   database.ShareLink
+	Album  AlbumSummary
+	Photos []database.Photo // Da mais recente para a mais antiga; a primeira é usada como capa
+}
+
+// CreateAlbumShare cria um link de compartilhamento para o álbum.
+func (s *ShareService) CreateAlbumShare(albumID uint) (*database.ShareLink, error) {
+	if _, err := s.AlbumService.GetAlbum(albumID); err != nil {
+		return nil, err
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+
+	link := database.ShareLink{Token: token, AlbumID: albumID}
+	if result := s.DB.Create(&link); result.Error != nil {
+		return nil, fmt.Errorf("não foi possível criar o link de compartilhamento: %w", result.Error)
+	}
+	return &link, nil
+}
+
+// GetShareLink retorna o link de compartilhamento com o token informado.
+func (s *ShareService) GetShareLink(token string) (*database.ShareLink, error) {
+	var link database.ShareLink
+	if result := s.DB.Where("token = ?", token).First(&link); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar link de compartilhamento: %w", result.Error)
+	}
+	return &link, nil
+}
+
+// GetSharedAlbum retorna o álbum e as fotos de um link de compartilhamento.
+func (s *ShareService) GetSharedAlbum(token string) (*SharedAlbum, error) {
+	link, err := s.GetShareLink(token)
+	if err != nil {
+		return nil, err
+	}
+
+	album, err := s.AlbumService.GetAlbum(link.AlbumID)
+	if err != nil {
+		return nil, err
+	}
+	photos, err := s.AlbumService.GetAlbumPhotos(link.AlbumID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SharedAlbum{Link: *link, Album: *album, Photos: photos}, nil
+}
+
+// SharedPhoto retorna uma foto do álbum de um link de compartilhamento, indicada pelo identificador público
+// (ou pelo ID). Fotos de fora do álbum resultam em gorm.ErrRecordNotFound.
+func (s *ShareService) SharedPhoto(token, photoRef string) (*database.Photo, error) {
+	link, err := s.GetShareLink(token)
+	if err != nil {
+		return nil, err
+	}
+	photoID, err := PhotoIDByRef(s.DB, photoRef)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar foto: %w", err)
+	}
+	var photo database.Photo
+	result := s.DB.
+		Joins("JOIN album_photos ON album_photos.photo_id = photos.id AND album_photos.deleted_at IS NULL").
+		Where("album_photos.album_id = ? AND photos.id = ?", link.AlbumID, photoID).
+		First(&photo)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar foto: %w", result.Error)
+	}
+	return &photo, nil
+}
+
+// newShareToken gera um token aleatório de 128 bits, seguro para uso em URLs.
+func newShareToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("não foi possível gerar o token de compartilhamento: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
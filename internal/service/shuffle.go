@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"math/rand/v2"
+	"photo-manager/internal/database"
+	"strconv"
+	"strings"
+)
+
+// ShuffleCursor guarda o estado de um embaralhamento determinístico: a semente da permutação,
+// quantas fotos já foram entregues, o total de fotos no momento em que o embaralhamento começou e a
+// impressão digital da lista de IDs nesse momento.
+// Enquanto a biblioteca não muda, percorrer o cursor entrega cada foto exatamente uma vez.
+type ShuffleCursor struct {
+	Seed        uint64
+	Position    uint64
+	Total       uint64
+	Fingerprint uint64 // Ver shuffleFingerprint; 0 em cursores antigos, que recomeçam o embaralhamento
+}
+
+// NewShuffleCursor cria um cursor com uma semente aleatória.
+func NewShuffleCursor() *ShuffleCursor {
+	return &ShuffleCursor{Seed: rand.Uint64()}
+}
+
+// Encode serializa o cursor em um token opaco para ser devolvido ao cliente.
+func (c *ShuffleCursor) Encode() string {
+	raw := fmt.Sprintf("%x:%d:%d:%x", c.Seed, c.Position, c.Total, c.Fingerprint)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseShuffleCursor lê um cursor gerado por Encode. Cursores sem a impressão digital (gerados antes dela
+// existir) continuam aceitos.
+func ParseShuffleCursor(token string) (*ShuffleCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("cursor inválido")
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 && len(parts) != 4 {
+		return nil, fmt.Errorf("cursor inválido")
+	}
+	seed, err1 := strconv.ParseUint(parts[0], 16, 64)
+	position, err2 := strconv.ParseUint(parts[1], 10, 64)
+	total, err3 := strconv.ParseUint(parts[2], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return nil, fmt.Errorf("cursor inválido")
+	}
+	cursor := &ShuffleCursor{Seed: seed, Position: position, Total: total}
+	if len(parts) == 4 {
+		if cursor.Fingerprint, err = strconv.ParseUint(parts[3], 16, 64); err != nil {
+			return nil, fmt.Errorf("cursor inválido")
+		}
+	}
+	return cursor, nil
+}
+
+// GetRandomPhotos retorna até count fotos em ordem aleatória que respeitam o filtro.
+// A ordem é definida por uma permutação pseudoaleatória derivada da semente do cursor sobre os IDs das fotos
+// filtradas (uma única consulta por requisição), evitando ORDER BY RANDOM() sobre a tabela inteira.
+// O cursor retornado continua o embaralhamento; ele é nil quando todas as fotos já foram entregues. Se as
+// fotos filtradas mudaram desde o início do embaralhamento (inclusive uma foto removida e outra adicionada,
+// que mantêm a quantidade) ou o cursor foi adulterado, as posições não correspondem mais às mesmas fotos e o
+// embaralhamento recomeça com a mesma semente.
+func (s *PhotoService) GetRandomPhotos(ctx context.Context, filter PhotoFilter, count int, cursor *ShuffleCursor) ([]database.Photo, *ShuffleCursor, error) {
+	ids, err := s.GetPhotoIDs(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	total, fingerprint := uint64(len(ids)), shuffleFingerprint(ids)
+
+	if cursor == nil {
+		cursor = NewShuffleCursor()
+	}
+	if cursor.Position == 0 || cursor.Total != total || cursor.Fingerprint != fingerprint {
+		cursor.Position, cursor.Total, cursor.Fingerprint = 0, total, fingerprint
+	}
+
+	// Cada posição corresponde a uma foto, então o laço escolhe no máximo count fotos
+	picked := make([]uint, 0, count)
+	for len(picked) < count && cursor.Position < cursor.Total {
+		picked = append(picked, ids[shufflePosition(cursor.Position, cursor.Total, cursor.Seed)])
+		cursor.Position++
+	}
+
+	photos := []database.Photo{}
+	if len(picked) > 0 {
+		var found []database.Photo
+		if err := s.DB.WithContext(ctx).Where("id IN ?", picked).Find(&found).Error; err != nil {
+			return nil, nil, fmt.Errorf("erro ao buscar fotos aleatórias: %w", err)
+		}
+		byID := make(map[uint]database.Photo, len(found))
+		for _, photo := range found {
+			byID[photo.ID] = photo
+		}
+		// Mantém a ordem do embaralhamento; fotos removidas entre as duas consultas são puladas
+		for _, id := range picked {
+			if photo, ok := byID[id]; ok {
+				photos = append(photos, photo)
+			}
+		}
+	}
+
+	if cursor.Position >= cursor.Total {
+		return photos, nil, nil
+	}
+	return photos, cursor, nil
+}
+
+// shuffleFingerprint resume a lista ordenada de IDs (FNV-1a de 64 bits): qualquer foto removida ou
+// adicionada muda o resultado, mesmo que a quantidade continue a mesma.
+func shuffleFingerprint(ids []uint) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, id := range ids {
+		binary.LittleEndian.PutUint64(buf[:], uint64(id))
+		h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+// shufflePosition aplica uma permutação pseudoaleatória de [0, n) definida pela semente: índices distintos
+// sempre resultam em posições distintas. Usa uma rede de Feistel sobre a menor potência de 4 >= n,
+// com "cycle walking" para descartar valores fora do intervalo.
+func shufflePosition(index, n, seed uint64) uint64 {
+	if n <= 1 {
+		return 0
+	}
+
+	halfBits := (bits.Len64(n-1) + 1) / 2
+	mask := uint64(1)<<halfBits - 1
+
+	x := index
+	for {
+		left, right := x>>halfBits, x&mask
+		for round := uint64(0); round < 4; round++ {
+			left, right = right, left^(splitmix64(right^seed^(round*0x9e3779b97f4a7c15))&mask)
+		}
+		x = left<<halfBits | right
+		if x < n {
+			return x
+		}
+	}
+}
+
+// splitmix64 é uma função de mistura rápida usada como função de rodada da rede de Feistel.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"photo-manager/internal/database"
+	"testing"
+)
+
+// TestShuffleRestartsWhenPhotosChange remove uma foto e adiciona outra entre duas páginas do embaralhamento:
+// a quantidade continua a mesma, mas as posições do cursor não valem mais e o embaralhamento recomeça.
+func TestShuffleRestartsWhenPhotosChange(t *testing.T) {
+	s := newTestPhotoService(t)
+	ctx := context.Background()
+
+	createPhoto := func(name string) database.Photo {
+		photo := database.Photo{Filename: name, StoredPath: "/fotos/" + name, Hash: name}
+		if err := s.DB.Create(&photo).Error; err != nil {
+			t.Fatalf("criar foto: %v", err)
+		}
+		return photo
+	}
+	for i := 0; i < 4; i++ {
+		createPhoto(fmt.Sprintf("%d.jpg", i))
+	}
+
+	first, cursor, err := s.GetRandomPhotos(ctx, PhotoFilter{}, 2, &ShuffleCursor{Seed: 42})
+	if err != nil {
+		t.Fatalf("primeira página: %v", err)
+	}
+	if len(first) != 2 || cursor == nil {
+		t.Fatalf("primeira página: %d fotos, cursor %v; esperado 2 fotos e um cursor", len(first), cursor)
+	}
+	cursor, err = ParseShuffleCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("ler cursor: %v", err)
+	}
+
+	if err := s.DeletePhoto(ctx, first[0].ID, false); err != nil {
+		t.Fatalf("apagar foto: %v", err)
+	}
+	createPhoto("novo.jpg")
+
+	second, next, err := s.GetRandomPhotos(ctx, PhotoFilter{}, 2, cursor)
+	if err != nil {
+		t.Fatalf("segunda página: %v", err)
+	}
+	// Sem recomeçar, o cursor estaria no fim (posição 4 de 4) e não haveria próxima página
+	if next == nil || next.Position != 2 {
+		t.Fatalf("cursor após a mudança: %+v, esperado recomeçar (posição 2)", next)
+	}
+	for _, photo := range second {
+		if photo.ID == first[0].ID {
+			t.Fatalf("foto apagada %d entregue", photo.ID)
+		}
+	}
+
+	// Percorrer o novo embaralhamento até o fim entrega cada foto atual exatamente uma vez
+	seen := map[uint]int{}
+	for _, photo := range second {
+		seen[photo.ID]++
+	}
+	for next != nil {
+		var page []database.Photo
+		if page, next, err = s.GetRandomPhotos(ctx, PhotoFilter{}, 2, next); err != nil {
+			t.Fatalf("página seguinte: %v", err)
+		}
+		for _, photo := range page {
+			seen[photo.ID]++
+		}
+	}
+	ids, err := s.GetPhotoIDs(ctx, PhotoFilter{})
+	if err != nil {
+		t.Fatalf("listar fotos: %v", err)
+	}
+	for _, id := range ids {
+		if seen[id] != 1 {
+			t.Fatalf("foto %d entregue %d vez(es), esperado 1 (entregues: %v)", id, seen[id], seen)
+		}
+	}
+	if len(seen) != len(ids) {
+		t.Fatalf("fotos entregues: %v, esperado %v", seen, ids)
+	}
+}
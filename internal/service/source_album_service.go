@@ -0,0 +1,109 @@
+package service
+
+import (
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrSourceAlbumNotFound indica que o álbum padrão informado não existe.
+var ErrSourceAlbumNotFound = i18n.NewError(i18n.CodeSourceAlbumNotFound)
+
+// SourceAlbumService gerencia os álbuns padrão por canal de entrada e dispositivo.
+type SourceAlbumService struct {
+	DB           *gorm.DB
+	AlbumService *AlbumService
+}
+
+// NewSourceAlbumService cria uma nova instância de SourceAlbumService.
+func NewSourceAlbumService(db *gorm.DB, as *AlbumService) *SourceAlbumService {
+	return &SourceAlbumService{
+		DB:           db,
+		AlbumService: as,
+	}
+}
+
+// ListSourceAlbums retorna os álbuns padrão configurados, por canal e dispositivo.
+func (s *SourceAlbumService) ListSourceAlbums() ([]database.SourceAlbum, error) {
+	var sourceAlbums []database.SourceAlbum
+	if result := s.DB.Preload("Album").Order("source ASC, device ASC").Find(&sourceAlbums); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar os álbuns padrão: %w", result.Error)
+	}
+	return sourceAlbums, nil
+}
+
+// SetSourceAlbum define o álbum padrão das fotos recebidas do canal e, opcionalmente, do dispositivo,
+// substituindo o anterior. Sem dispositivo, vale para todo o canal; a configuração de um dispositivo tem
+// prioridade sobre a do canal.
+func (s *SourceAlbumService) SetSourceAlbum(source, device string, albumID uint) (*database.SourceAlbum, error) {
+	source, device = strings.TrimSpace(source), strings.TrimSpace(device)
+	if !slices.Contains(database.Sources, source) {
+		return nil, i18n.NewError(i18n.CodeUploadSourceInvalid, source, strings.Join(database.Sources, ", "))
+	}
+	if _, err := s.AlbumService.GetAlbum(albumID); err != nil {
+		return nil, err
+	}
+
+	var sourceAlbum database.SourceAlbum
+	found := s.DB.Where("source = ? AND device = ?", source, device).Limit(1).Find(&sourceAlbum)
+	if found.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar o álbum padrão: %w", found.Error)
+	}
+	sourceAlbum.Source, sourceAlbum.Device, sourceAlbum.AlbumID = source, device, albumID
+	if err := s.DB.Omit("Album").Save(&sourceAlbum).Error; err != nil {
+		return nil, fmt.Errorf("não foi possível gravar o álbum padrão: %w", err)
+	}
+	if err := s.DB.Preload("Album").First(&sourceAlbum, sourceAlbum.ID).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar o álbum padrão: %w", err)
+	}
+	return &sourceAlbum, nil
+}
+
+// DeleteSourceAlbum remove um álbum padrão; as fotos já incluídas permanecem no álbum.
+func (s *SourceAlbumService) DeleteSourceAlbum(id uint) error {
+	// Remoção definitiva, para que o canal e o dispositivo possam ser configurados de novo
+	result := s.DB.Unscoped().Delete(&database.SourceAlbum{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("não foi possível remover o álbum padrão: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrSourceAlbumNotFound
+	}
+	return nil
+}
+
+// findSourceAlbum retorna o álbum padrão para a origem da foto: o do dispositivo ou, na falta dele, o do
+// canal. Retorna nil se não houver álbum padrão configurado.
+func findSourceAlbum(db *gorm.DB, source, device string) (*database.Album, error) {
+	var sourceAlbum database.SourceAlbum
+	found := db.Where("source = ? AND device IN ?", source, []string{device, ""}).
+		Order("device DESC"). // O do dispositivo (não vazio) vem primeiro
+		Preload("Album").Limit(1).Find(&sourceAlbum)
+	if found.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar o álbum padrão: %w", found.Error)
+	}
+	if found.RowsAffected == 0 || sourceAlbum.Album.ID == 0 {
+		return nil, nil
+	}
+	return &sourceAlbum.Album, nil
+}
+
+// applySourceAlbum inclui a foto recém-criada no álbum padrão da sua origem, na transação da ingestão.
+// Álbuns bloqueados ou que já receberam a foto pelas regras de organização são ignorados.
+func applySourceAlbum(tx *gorm.DB, photo *database.Photo, albumIDs []uint) ([]uint, error) {
+	album, err := findSourceAlbum(tx, photo.Source, photo.SourceDevice)
+	if err != nil || album == nil || slices.Contains(albumIDs, album.ID) {
+		return albumIDs, err
+	}
+	if album.Locked {
+		return albumIDs, nil
+	}
+	if err := tx.Create(&database.AlbumPhoto{AlbumID: album.ID, PhotoID: photo.ID}).Error; err != nil {
+		return nil, fmt.Errorf("não foi possível incluir a foto no álbum padrão '%s': %w", album.Name, err)
+	}
+	return append(albumIDs, album.ID), nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"slices"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Motivos para considerar alterado o arquivo original de uma foto indexada no local.
+const (
+	SourceChangedSize    = "size"     // Tamanho diferente do indexado
+	SourceChangedModTime = "mod_time" // Data de modificação diferente da indexada
+	SourceChangedContent = "content"  // Mesmo tamanho e data, mas conteúdo (hash) diferente: só com a verificação do hash
+	SourceChangedMissing = "missing"  // O arquivo não existe mais (não há versão a adotar)
+)
+
+// Origens, no histórico de alterações, das novas versões adotadas dos arquivos indexados no local.
+const (
+	metadataChangeReindex = "library_reindex" // Adotada automaticamente pela reindexação da biblioteca
+	metadataChangeAdopt   = "source_adopt"    // Adotada a pedido (por foto ou em lote)
+)
+
+// sourceChangesPreviewLimit é a quantidade máxima de fotos listadas no relatório; os totais são sempre completos.
+const sourceChangesPreviewLimit = 500
+
+// SourceChangesOptions descreve uma verificação dos arquivos das fotos indexadas no local.
+type SourceChangesOptions struct {
+	PhotoIDs   []uint // Fotos verificadas (vazio = todas as fotos indexadas no local)
+	VerifyHash bool   // Compara também o conteúdo dos arquivos com o mesmo tamanho e data (lê cada arquivo inteiro)
+	Adopt      bool   // Adota a nova versão dos arquivos alterados
+}
+
+// SourceChange é uma foto cujo arquivo original mudou desde a indexação.
+type SourceChange struct {
+	PhotoID     uint
+	Filename    string
+	Path        string
+	Reasons     []string   // Ver constantes SourceChanged*
+	IndexedSize int64      // Tamanho indexado
+	IndexedMod  *time.Time // Data de modificação indexada
+	Size        int64      // Tamanho atual (0 se o arquivo não existe)
+	ModTime     *time.Time // Data de modificação atual
+	Locked      bool       // Foto bloqueada: a nova versão não é adotada
+	Adopted     bool
+	Error       string
+	missing     bool
+	info        os.FileInfo
+}
+
+// SourceChangesReport resume a verificação. Com Adopt, as fotos com Adopted tiveram a nova versão adotada.
+type SourceChangesReport struct {
+	Checked    int // Fotos verificadas
+	Changed    int // Fotos com o arquivo alterado
+	Missing    int // Fotos com o arquivo ausente
+	Adopted    int
+	Failed     int
+	AdoptedIDs []uint // Todas as fotos adotadas, mesmo além das listadas
+	Photos     []SourceChange
+	Truncated  bool
+}
+
+// CheckSourceChanges compara os arquivos originais das fotos indexadas no local com o índice (tamanho, data de
+// modificação e, com VerifyHash, o conteúdo) e lista as fotos alteradas. Com Adopt, adota a nova versão de cada
+// uma: os metadados são reextraídos do arquivo, a miniatura e a prévia são geradas de novo e os valores
+// anteriores ficam no histórico de alterações (MetadataChange). Fotos bloqueadas e arquivos ausentes são
+// apenas reportados.
+func (s *PhotoService) CheckSourceChanges(ctx context.Context, opts SourceChangesOptions) (*SourceChangesReport, error) {
+	ids := uniqueIDs(opts.PhotoIDs)
+	db := s.DB.WithContext(ctx)
+	query := db.Select("id", "filename", "stored_path", "file_size", "source_mod_time", "hash", "managed_externally").Order("id")
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	} else {
+		query = query.Where("managed_externally = ?", true)
+	}
+	var photos []database.Photo
+	if err := query.Find(&photos).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar fotos: %w", err)
+	}
+	if len(photos) != len(ids) && len(ids) > 0 {
+		return nil, i18n.NewError(i18n.CodePhotosNotFound)
+	}
+
+	report := &SourceChangesReport{}
+	var changes []SourceChange
+	var changedIDs []uint
+	for _, photo := range photos {
+		if !photo.ManagedExternally {
+			return nil, i18n.NewError(i18n.CodeSourceChangesNotExternal, photo.ID)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		report.Checked++
+		change, ok := detectSourceChange(photo, opts.VerifyHash)
+		if !ok {
+			continue
+		}
+		if change.missing {
+			report.Missing++
+		} else {
+			report.Changed++
+		}
+		changes = append(changes, change)
+		changedIDs = append(changedIDs, photo.ID)
+	}
+
+	locked, err := lockedPhotoIDs(db, changedIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range changes {
+		change := &changes[i]
+		change.Locked = locked[change.PhotoID]
+		if change.Error != "" {
+			report.Failed++
+		}
+		if !opts.Adopt || change.missing || change.Locked || change.Error != "" {
+			continue
+		}
+		if err := s.adoptSourceChange(ctx, change); err != nil {
+			change.Error = err.Error()
+			report.Failed++
+			continue
+		}
+		change.Adopted = true
+		report.Adopted++
+		report.AdoptedIDs = append(report.AdoptedIDs, change.PhotoID)
+	}
+
+	report.Photos = changes
+	if len(changes) > sourceChangesPreviewLimit {
+		report.Photos, report.Truncated = changes[:sourceChangesPreviewLimit], true
+	}
+	return report, nil
+}
+
+// detectSourceChange compara o arquivo da foto com o índice. O segundo retorno indica se o arquivo mudou (ou
+// não pôde ser lido).
+func detectSourceChange(photo database.Photo, verifyHash bool) (SourceChange, bool) {
+	change := SourceChange{
+		PhotoID:     photo.ID,
+		Filename:    photo.Filename,
+		Path:        photo.StoredPath,
+		IndexedSize: photo.FileSize,
+		IndexedMod:  photo.SourceModTime,
+	}
+	info, err := os.Stat(photo.StoredPath)
+	if os.IsNotExist(err) {
+		change.Reasons, change.missing = []string{SourceChangedMissing}, true
+		return change, true
+	}
+	if err != nil {
+		change.Error = err.Error()
+		return change, true
+	}
+
+	modTime := info.ModTime()
+	change.info, change.Size, change.ModTime = info, info.Size(), &modTime
+	if info.Size() != photo.FileSize {
+		change.Reasons = append(change.Reasons, SourceChangedSize)
+	}
+	if photo.SourceModTime == nil || !photo.SourceModTime.Equal(modTime) {
+		change.Reasons = append(change.Reasons, SourceChangedModTime)
+	}
+	if len(change.Reasons) == 0 && verifyHash {
+		hash, err := calculateMD5Hash(photo.StoredPath)
+		if err != nil {
+			change.Error = err.Error()
+			return change, true
+		}
+		if hash != photo.Hash {
+			change.Reasons = append(change.Reasons, SourceChangedContent)
+		}
+	}
+	return change, len(change.Reasons) > 0
+}
+
+// adoptSourceChange adota a nova versão do arquivo de uma foto alterada.
+func (s *PhotoService) adoptSourceChange(ctx context.Context, change *SourceChange) error {
+	var photo database.Photo
+	if err := s.DB.WithContext(ctx).First(&photo, change.PhotoID).Error; err != nil {
+		return fmt.Errorf("erro ao buscar a foto %d: %w", change.PhotoID, err)
+	}
+	return s.adoptSourceFile(ctx, &photo, change.info, metadataChangeAdopt)
+}
+
+// sourceVersionFields são os campos registrados no histórico quando uma nova versão do arquivo é adotada.
+var sourceVersionFields = slices.Concat([]string{"hash", "file_size", "source_mod_time", "width", "height"}, ReconcileFields, []string{"time_zone_offset"})
+
+// sourceVersionValues formata os campos da foto que dependem do arquivo original.
+func sourceVersionValues(photo *database.Photo) map[string]string {
+	values := map[string]string{
+		"hash":             photo.Hash,
+		"file_size":        strconv.FormatInt(photo.FileSize, 10),
+		"source_mod_time":  timeValue(photo.SourceModTime).text,
+		"width":            strconv.Itoa(photo.Width),
+		"height":           strconv.Itoa(photo.Height),
+		"time_zone_offset": intValue(photo.TimeZoneOffset).text,
+	}
+	for field, value := range photoReconcileValues(photo) {
+		values[field] = value.text
+	}
+	return values
+}
+
+// recordSourceVersion registra no histórico de alterações os campos da foto que mudaram com a nova versão do
+// arquivo, com os valores anteriores (previous), para auditoria e eventual reversão manual.
+func recordSourceVersion(tx *gorm.DB, photo *database.Photo, previous map[string]string, source string) error {
+	var current database.Photo
+	if err := tx.First(&current, photo.ID).Error; err != nil {
+		return fmt.Errorf("erro ao buscar a foto %d: %w", photo.ID, err)
+	}
+	values := sourceVersionValues(&current)
+	now := time.Now()
+	for _, field := range sourceVersionFields {
+		if values[field] == previous[field] {
+			continue
+		}
+		change := database.MetadataChange{
+			PhotoID:   photo.ID,
+			Field:     field,
+			OldValue:  previous[field],
+			NewValue:  values[field],
+			Source:    source,
+			AppliedAt: now,
+		}
+		if err := tx.Create(&change).Error; err != nil {
+			return fmt.Errorf("não foi possível registrar a alteração da foto %d: %w", photo.ID, err)
+		}
+	}
+	return nil
+}
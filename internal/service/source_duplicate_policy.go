@@ -0,0 +1,179 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrDuplicatePolicyNotFound indica que a política de duplicatas informada não existe.
+var ErrDuplicatePolicyNotFound = i18n.NewError(i18n.CodeDuplicatePolicyNotFound)
+
+// ErrDuplicateSkipped indica uma duplicata ignorada pela política skip ou link da origem. Continua sendo
+// um ErrDuplicatePhoto para quem só distingue duplicatas de falhas (importações, relatórios).
+var ErrDuplicateSkipped = i18n.WrapError(ErrDuplicatePhoto, i18n.CodePhotoDuplicateSkipped)
+
+// SourceDuplicatePolicyService gerencia as políticas de duplicatas por canal de entrada e dispositivo.
+type SourceDuplicatePolicyService struct {
+	DB           *gorm.DB
+	AlbumService *AlbumService
+}
+
+// NewSourceDuplicatePolicyService cria uma nova instância de SourceDuplicatePolicyService.
+func NewSourceDuplicatePolicyService(db *gorm.DB, as *AlbumService) *SourceDuplicatePolicyService {
+	return &SourceDuplicatePolicyService{
+		DB:           db,
+		AlbumService: as,
+	}
+}
+
+// ListPolicies retorna as políticas de duplicatas configuradas, por canal e dispositivo.
+func (s *SourceDuplicatePolicyService) ListPolicies() ([]database.SourceDuplicatePolicy, error) {
+	var policies []database.SourceDuplicatePolicy
+	if result := s.DB.Preload("Album").Order("source ASC, device ASC").Find(&policies); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar as políticas de duplicatas: %w", result.Error)
+	}
+	return policies, nil
+}
+
+// SetPolicy define a política de duplicatas do canal e, opcionalmente, do dispositivo, substituindo a
+// anterior. Sem dispositivo, vale para todo o canal; a configuração de um dispositivo tem prioridade sobre a
+// do canal. albumID só é aceito na política link; sem ele, a foto existente vai para o álbum padrão da origem.
+func (s *SourceDuplicatePolicyService) SetPolicy(source, device, policy string, albumID *uint) (*database.SourceDuplicatePolicy, error) {
+	source, device = strings.TrimSpace(source), strings.TrimSpace(device)
+	if !slices.Contains(database.Sources, source) {
+		return nil, i18n.NewError(i18n.CodeUploadSourceInvalid, source, strings.Join(database.Sources, ", "))
+	}
+	if !slices.Contains(database.DuplicatePolicies, policy) {
+		return nil, i18n.NewError(i18n.CodeSourceDuplicatePolicyInvalid, policy, strings.Join(database.DuplicatePolicies, ", "))
+	}
+	if albumID != nil {
+		if policy != database.DuplicatePolicyLink {
+			return nil, i18n.NewError(i18n.CodeDuplicatePolicyAlbumNotAllowed)
+		}
+		if _, err := s.AlbumService.GetAlbum(*albumID); err != nil {
+			return nil, err
+		}
+	}
+
+	var sourcePolicy database.SourceDuplicatePolicy
+	found := s.DB.Where("source = ? AND device = ?", source, device).Limit(1).Find(&sourcePolicy)
+	if found.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar a política de duplicatas: %w", found.Error)
+	}
+	sourcePolicy.Source, sourcePolicy.Device, sourcePolicy.Policy, sourcePolicy.AlbumID = source, device, policy, albumID
+	if err := s.DB.Omit("Album").Save(&sourcePolicy).Error; err != nil {
+		return nil, fmt.Errorf("não foi possível gravar a política de duplicatas: %w", err)
+	}
+	if err := s.DB.Preload("Album").First(&sourcePolicy, sourcePolicy.ID).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar a política de duplicatas: %w", err)
+	}
+	return &sourcePolicy, nil
+}
+
+// DeletePolicy remove uma política de duplicatas; a origem volta a rejeitar as duplicatas.
+func (s *SourceDuplicatePolicyService) DeletePolicy(id uint) error {
+	// Remoção definitiva, para que o canal e o dispositivo possam ser configurados de novo
+	result := s.DB.Unscoped().Delete(&database.SourceDuplicatePolicy{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("não foi possível remover a política de duplicatas: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrDuplicatePolicyNotFound
+	}
+	return nil
+}
+
+// findDuplicatePolicy retorna a política de duplicatas para a origem da foto: a do dispositivo ou, na falta
+// dela, a do canal. Sem política configurada, as duplicatas são rejeitadas.
+func findDuplicatePolicy(db *gorm.DB, source, device string) (*database.SourceDuplicatePolicy, error) {
+	var policy database.SourceDuplicatePolicy
+	found := db.Where("source = ? AND device IN ?", source, []string{device, ""}).
+		Order("device DESC"). // A do dispositivo (não vazio) vem primeiro
+		Limit(1).Find(&policy)
+	if found.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar a política de duplicatas: %w", found.Error)
+	}
+	if found.RowsAffected == 0 {
+		return &database.SourceDuplicatePolicy{Source: source, Device: device, Policy: database.DuplicatePolicyReject}, nil
+	}
+	return &policy, nil
+}
+
+// linkDuplicate inclui a foto existente no álbum da política link: o da própria política ou, sem ele, o
+// álbum padrão da origem. Retorna o ID do álbum alterado, ou 0 se não houver álbum, se ele estiver
+// bloqueado ou se a foto já estiver nele.
+func linkDuplicate(db *gorm.DB, policy *database.SourceDuplicatePolicy, photo *database.Photo) (uint, error) {
+	var album *database.Album
+	if policy.AlbumID != nil {
+		var policyAlbum database.Album
+		found := db.Where("id = ?", *policy.AlbumID).Limit(1).Find(&policyAlbum)
+		if found.Error != nil {
+			return 0, fmt.Errorf("erro ao buscar o álbum da política de duplicatas: %w", found.Error)
+		}
+		if found.RowsAffected > 0 {
+			album = &policyAlbum
+		}
+	} else {
+		var err error
+		if album, err = findSourceAlbum(db, policy.Source, policy.Device); err != nil {
+			return 0, err
+		}
+	}
+	if album == nil || album.Locked {
+		return 0, nil
+	}
+
+	var linked int64
+	if err := db.Model(&database.AlbumPhoto{}).Where("album_id = ? AND photo_id = ?", album.ID, photo.ID).Count(&linked).Error; err != nil {
+		return 0, fmt.Errorf("erro ao verificar o álbum '%s': %w", album.Name, err)
+	}
+	if linked > 0 {
+		return 0, nil
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&database.AlbumPhoto{AlbumID: album.ID, PhotoID: photo.ID}).Error; err != nil {
+			return err
+		}
+		return refreshAlbumDates(tx, []uint{album.ID})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("não foi possível incluir a foto no álbum '%s': %w", album.Name, err)
+	}
+	return album.ID, nil
+}
+
+// nextCopyIndex retorna o índice da próxima cópia de um conteúdo gravada pela política version.
+func nextCopyIndex(db *gorm.DB, hash string) (int, error) {
+	var last int
+	if err := db.Model(&database.Photo{}).Unscoped().Where("hash = ?", hash).Select("COALESCE(MAX(copy_index), 0)").Scan(&last).Error; err != nil {
+		return 0, fmt.Errorf("erro ao buscar as cópias da foto: %w", err)
+	}
+	return last + 1, nil
+}
+
+// copyFilename é o nome de arquivo de uma cópia gravada pela política version ("IMG_0001 (v1).jpg"), já que
+// o nome de arquivo é único na biblioteca.
+func copyFilename(filename string, copyIndex int) string {
+	return suffixedFilename(filename, fmt.Sprintf("v%d", copyIndex))
+}
+
+// suffixedFilename acrescenta o sufixo entre parênteses ao nome, antes da extensão ("IMG_0001 (2).jpg").
+func suffixedFilename(filename, suffix string) string {
+	ext := filepath.Ext(filename)
+	return fmt.Sprintf("%s (%s)%s", strings.TrimSuffix(filename, ext), suffix, ext)
+}
+
+// copyStorageKey é a chave usada no nome do arquivo armazenado de uma cópia, que no layout por hash
+// colidiria com o arquivo da foto original.
+func copyStorageKey(hash string, copyIndex int) string {
+	if copyIndex == 0 {
+		return hash
+	}
+	return fmt.Sprintf("%s-v%d", hash, copyIndex)
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/storage"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// StatsService calcula estatísticas da biblioteca e do armazenamento.
+type StatsService struct {
+	DB          *gorm.DB
+	FileManager *storage.FileManager
+	Events      *events.Bus
+}
+
+// NewStatsService cria uma nova instância de StatsService.
+func NewStatsService(db *gorm.DB, fm *storage.FileManager, bus *events.Bus) *StatsService {
+	return &StatsService{
+		DB:          db,
+		FileManager: fm,
+		Events:      bus,
+	}
+}
+
+// LibraryStats resume a biblioteca de fotos.
+type LibraryStats struct {
+	PhotoCount  int64
+	TotalBytes  int64
+	Volumes     []VolumeStats
+	EventCounts map[string]int64 // Eventos emitidos desde a inicialização, por tipo (ex: storage.low_space)
+}
+
+// VolumeStats combina o estado de um volume com as fotos armazenadas nele.
+type VolumeStats struct {
+	storage.VolumeStatus
+	LowSpace   bool // Espaço livre abaixo do limite de aviso
+	PhotoCount int64
+	PhotoBytes int64
+}
+
+// GetStats retorna as estatísticas gerais da biblioteca, incluindo o espaço livre de cada volume.
+func (s *StatsService) GetStats() (*LibraryStats, error) {
+	var stats LibraryStats
+
+	var totals struct {
+		Count int64
+		Bytes int64
+	}
+	result := s.DB.Model(&database.Photo{}).Select("COUNT(*) AS count, COALESCE(SUM(file_size), 0) AS bytes").Scan(&totals)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao calcular estatísticas das fotos: %w", result.Error)
+	}
+	stats.PhotoCount = totals.Count
+	stats.TotalBytes = totals.Bytes
+
+	// Fotos indexadas no local não ocupam espaço nos volumes gerenciados
+	var perVolume []struct {
+		Volume string
+		Count  int64
+		Bytes  int64
+	}
+	result = s.DB.Model(&database.Photo{}).
+		Select("volume, COUNT(*) AS count, COALESCE(SUM(file_size), 0) AS bytes").
+		Where("managed_externally = ?", false).
+		Group("volume").
+		Scan(&perVolume)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao calcular estatísticas por volume: %w", result.Error)
+	}
+
+	usage := make(map[string]VolumeStats)
+	for _, v := range perVolume {
+		name := v.Volume
+		if name == "" {
+			name = storage.DefaultVolumeName
+		}
+		entry := usage[name]
+		entry.PhotoCount += v.Count
+		entry.PhotoBytes += v.Bytes
+		usage[name] = entry
+	}
+
+	for _, status := range s.FileManager.VolumeStatuses() {
+		entry := usage[status.Name]
+		entry.VolumeStatus = status
+		entry.LowSpace = s.FileManager.IsLowSpace(status)
+		stats.Volumes = append(stats.Volumes, entry)
+	}
+
+	stats.EventCounts = s.Events.Counts()
+
+	return &stats, nil
+}
+
+// EquipmentStats agrega as fotos de uma câmera (Make e Model) ou de uma lente (Lens), a partir do EXIF.
+type EquipmentStats struct {
+	Make         string
+	Model        string
+	Lens         string
+	PhotoCount   int64
+	FocalLengths []FocalLengthCount // Distribuição das distâncias focais, em mm inteiros, em ordem crescente
+	ISOHistogram []ISOBucket        // Histograma de ISO por faixas de um stop, apenas faixas com fotos
+}
+
+// FocalLengthCount é a quantidade de fotos feitas com uma distância focal.
+type FocalLengthCount struct {
+	FocalLength int // mm, arredondado
+	Count       int64
+}
+
+// ISOBucket é uma faixa do histograma de ISO: Min <= ISO < Max (Max 0 = sem limite superior).
+type ISOBucket struct {
+	Min   int
+	Max   int
+	Count int64
+}
+
+// isoStops são os limites das faixas do histograma de ISO.
+var isoStops = []int{100, 200, 400, 800, 1600, 3200, 6400, 12800}
+
+// CameraStats retorna as estatísticas por câmera, das mais usadas para as menos usadas.
+func (s *StatsService) CameraStats() ([]EquipmentStats, error) {
+	return s.equipmentStats([]string{"camera_make", "camera_model"}, "camera_make <> '' OR camera_model <> ''")
+}
+
+// LensStats retorna as estatísticas por lente, das mais usadas para as menos usadas.
+func (s *StatsService) LensStats() ([]EquipmentStats, error) {
+	return s.equipmentStats([]string{"lens_model"}, "lens_model <> ''")
+}
+
+// equipmentStats agrupa as fotos pelas colunas informadas e calcula, para cada grupo, a contagem,
+// a distribuição de distâncias focais e o histograma de ISO.
+func (s *StatsService) equipmentStats(columns []string, filter string) ([]EquipmentStats, error) {
+	group := strings.Join(columns, ", ")
+	type row struct {
+		CameraMake  string
+		CameraModel string
+		LensModel   string
+		Value       int
+		Count       int64
+	}
+	key := func(r row) string { return r.CameraMake + "\x00" + r.CameraModel + "\x00" + r.LensModel }
+
+	var totals []row
+	result := s.DB.Model(&database.Photo{}).
+		Select(group + ", COUNT(*) AS count").
+		Where(filter).
+		Group(group).
+		Order("count DESC, " + group).
+		Scan(&totals)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao agrupar fotos por equipamento: %w", result.Error)
+	}
+
+	var focals []row
+	result = s.DB.Model(&database.Photo{}).
+		Select(group + ", CAST(ROUND(focal_length) AS INTEGER) AS value, COUNT(*) AS count").
+		Where(filter).
+		Where("focal_length IS NOT NULL").
+		Group(group + ", value").
+		Order("value").
+		Scan(&focals)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao calcular a distribuição de distâncias focais: %w", result.Error)
+	}
+
+	var isos []row
+	result = s.DB.Model(&database.Photo{}).
+		Select(group + ", iso AS value, COUNT(*) AS count").
+		Where(filter).
+		Where("iso IS NOT NULL").
+		Group(group + ", iso").
+		Order("value").
+		Scan(&isos)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao calcular o histograma de ISO: %w", result.Error)
+	}
+
+	stats := make([]EquipmentStats, len(totals))
+	index := make(map[string]int, len(totals))
+	for i, t := range totals {
+		stats[i] = EquipmentStats{
+			Make:         t.CameraMake,
+			Model:        t.CameraModel,
+			Lens:         t.LensModel,
+			PhotoCount:   t.Count,
+			FocalLengths: []FocalLengthCount{},
+			ISOHistogram: []ISOBucket{},
+		}
+		index[key(t)] = i
+	}
+	for _, f := range focals {
+		if i, ok := index[key(f)]; ok {
+			stats[i].FocalLengths = append(stats[i].FocalLengths, FocalLengthCount{FocalLength: f.Value, Count: f.Count})
+		}
+	}
+	for _, iso := range isos {
+		i, ok := index[key(iso)]
+		if !ok {
+			continue
+		}
+		bucket := isoBucketFor(iso.Value)
+		histogram := stats[i].ISOHistogram
+		if n := len(histogram); n > 0 && histogram[n-1].Min == bucket.Min {
+			histogram[n-1].Count += iso.Count
+		} else {
+			bucket.Count = iso.Count
+			stats[i].ISOHistogram = append(histogram, bucket)
+		}
+	}
+	return stats, nil
+}
+
+// isoBucketFor retorna a faixa do histograma que contém o valor de ISO.
+func isoBucketFor(iso int) ISOBucket {
+	lower := 0
+	for _, stop := range isoStops {
+		if iso < stop {
+			return ISOBucket{Min: lower, Max: stop}
+		}
+		lower = stop
+	}
+	return ISOBucket{Min: lower}
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/storage"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tipos de sugestão da verificação de divergências do armazenamento, que também nomeiam a ação sugerida.
+const (
+	DriftAdopt        = "adopt"         // Arquivo no volume sem foto cadastrada: importar para a biblioteca
+	DriftRelink       = "relink"        // Arquivo de uma foto movido ou renomeado: apontar a foto para o novo caminho (mesmo hash)
+	DriftDuplicate    = "duplicate"     // Cópia solta de uma foto já cadastrada: pode ser apagada manualmente
+	DriftSizeMismatch = "size_mismatch" // O arquivo da foto tem outro tamanho: alterado fora do sistema ou corrompido
+	DriftMissing      = "missing"       // O arquivo da foto não existe e nenhum arquivo com o mesmo hash foi encontrado
+)
+
+// driftMinFileAge é a idade mínima de um arquivo sem foto para ser considerado: um arquivo recente pode
+// estar sendo gravado por uma ingestão em andamento, ainda sem o registro no banco.
+const driftMinFileAge = 10 * time.Minute
+
+// StorageDriftSuggestion é uma divergência encontrada entre os volumes e o banco, com a ação sugerida.
+type StorageDriftSuggestion struct {
+	Kind         string // Ver constantes Drift*
+	Path         string // Arquivo encontrado no volume (vazio em DriftMissing)
+	PhotoID      uint   // Foto envolvida (0 em DriftAdopt)
+	StoredPath   string // Caminho registrado da foto
+	Size         int64  // Tamanho do arquivo encontrado
+	ExpectedSize int64  // Tamanho registrado da foto
+	Applied      bool   // A ação foi aplicada pela reconciliação
+	Error        string // Motivo de a ação não ter sido aplicada
+}
+
+// StorageDriftReport é o resultado de uma verificação dos volumes, com o que foi aplicado quando houver
+// reconciliação.
+type StorageDriftReport struct {
+	ScannedFiles   int      // Arquivos percorridos nos volumes online
+	CheckedPhotos  int      // Fotos do armazenamento gerenciado conferidas
+	OfflineVolumes []string // Volumes não verificados; suas fotos não são dadas como ausentes
+	Suggestions    []StorageDriftSuggestion
+	DryRun         bool
+	Relinked       int
+	Adopted        int
+}
+
+// Count retorna a quantidade de sugestões do tipo informado.
+func (r *StorageDriftReport) Count(kind string) int {
+	count := 0
+	for _, suggestion := range r.Suggestions {
+		if suggestion.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// StorageDriftOptions escolhe as ações aplicadas pela reconciliação. Divergências de tamanho, cópias soltas
+// e arquivos ausentes nunca são corrigidos automaticamente: apenas sinalizados.
+type StorageDriftOptions struct {
+	Relink bool // Aponta as fotos para os arquivos movidos ou renomeados
+	Adopt  bool // Importa os arquivos sem foto cadastrada
+	DryRun bool // Apenas informa o que seria aplicado
+}
+
+// StorageDriftService compara os arquivos dos volumes com as fotos do banco, para detectar alterações
+// feitas diretamente no armazenamento: arquivos adicionados, movidos ou renomeados, alterados e removidos.
+// As fotos indexadas no local não fazem parte do armazenamento gerenciado e são ignoradas.
+type StorageDriftService struct {
+	Photos *PhotoService
+}
+
+// NewStorageDriftService cria uma nova instância de StorageDriftService.
+func NewStorageDriftService(photos *PhotoService) *StorageDriftService {
+	return &StorageDriftService{Photos: photos}
+}
+
+// driftPhoto são os campos da foto usados na comparação.
+type driftPhoto struct {
+	ID         uint
+	StoredPath string
+	Volume     string
+	FileSize   int64
+	Hash       string
+}
+
+// driftFile é um arquivo do volume sem foto cadastrada.
+type driftFile struct {
+	Path string
+	Size int64
+}
+
+// Scan percorre os volumes online e compara com o banco, sem alterar nada. Os arquivos sem foto têm o hash
+// calculado: se ele for o de uma foto cujo arquivo sumiu, o arquivo foi movido ou renomeado.
+func (s *StorageDriftService) Scan(ctx context.Context) (*StorageDriftReport, error) {
+	report := &StorageDriftReport{DryRun: true, OfflineVolumes: []string{}, Suggestions: []StorageDriftSuggestion{}}
+
+	var photos []driftPhoto
+	err := s.Photos.DB.WithContext(ctx).Model(&database.Photo{}).
+		Select("id, stored_path, volume, file_size, hash").
+		Where("managed_externally = ?", false).
+		Order("id").
+		Scan(&photos).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar as fotos do armazenamento: %w", err)
+	}
+	byPath := make(map[string]*driftPhoto, len(photos))
+	for i := range photos {
+		byPath[driftKey(photos[i].StoredPath)] = &photos[i]
+	}
+
+	offline := make(map[string]bool)
+	var untracked []driftFile
+	for _, volume := range s.Photos.FileManager.Volumes() {
+		if !s.Photos.FileManager.IsVolumeOnline(volume.Name) {
+			offline[volume.Name] = true
+			report.OfflineVolumes = append(report.OfflineVolumes, volume.Name)
+			continue
+		}
+		err := filepath.WalkDir(volume.Path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == volume.Path {
+					return err
+				}
+				log.Printf("Aviso: não foi possível acessar '%s' durante a verificação do armazenamento: %v\n", path, err)
+				if d != nil && d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// Arquivos e pastas ocultos (ex: .DS_Store, pastas temporárias da adoção) não são fotos
+			if path != volume.Path && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil // Removido durante a verificação
+			}
+			report.ScannedFiles++
+
+			photo, ok := byPath[driftKey(path)]
+			if !ok {
+				if time.Since(info.ModTime()) >= driftMinFileAge {
+					untracked = append(untracked, driftFile{Path: path, Size: info.Size()})
+				}
+				return nil
+			}
+			delete(byPath, driftKey(path))
+			report.CheckedPhotos++
+			if info.Size() != photo.FileSize {
+				report.Suggestions = append(report.Suggestions, StorageDriftSuggestion{
+					Kind: DriftSizeMismatch, Path: path, PhotoID: photo.ID, StoredPath: photo.StoredPath,
+					Size: info.Size(), ExpectedSize: photo.FileSize,
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("erro ao percorrer o volume '%s': %w", volume.Name, err)
+		}
+	}
+
+	// Fotos não encontradas na varredura: fora dos volumes (ex: quarentena) ou de fato ausentes
+	missingByHash := make(map[string]*driftPhoto)
+	for _, photo := range photos {
+		if _, pending := byPath[driftKey(photo.StoredPath)]; !pending {
+			continue
+		}
+		volume := photo.Volume
+		if volume == "" {
+			volume = storage.DefaultVolumeName
+		}
+		if offline[volume] {
+			continue
+		}
+		report.CheckedPhotos++
+		if info, err := os.Stat(photo.StoredPath); err == nil {
+			if info.Size() != photo.FileSize {
+				report.Suggestions = append(report.Suggestions, StorageDriftSuggestion{
+					Kind: DriftSizeMismatch, Path: photo.StoredPath, PhotoID: photo.ID, StoredPath: photo.StoredPath,
+					Size: info.Size(), ExpectedSize: photo.FileSize,
+				})
+			}
+			continue
+		}
+		missingByHash[photo.Hash] = &photo
+	}
+
+	for _, file := range untracked {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		hash, err := calculateMD5Hash(file.Path)
+		if err != nil {
+			log.Printf("Aviso: %v\n", err)
+			continue
+		}
+		if photo, ok := missingByHash[hash]; ok {
+			delete(missingByHash, hash)
+			report.Suggestions = append(report.Suggestions, StorageDriftSuggestion{
+				Kind: DriftRelink, Path: file.Path, PhotoID: photo.ID, StoredPath: photo.StoredPath,
+				Size: file.Size, ExpectedSize: photo.FileSize,
+			})
+			continue
+		}
+		var existing driftPhoto
+		result := s.Photos.DB.WithContext(ctx).Model(&database.Photo{}).Select("id, stored_path, file_size").Where("hash = ?", hash).Limit(1).Scan(&existing)
+		if result.Error != nil {
+			return nil, fmt.Errorf("erro ao buscar foto pelo hash: %w", result.Error)
+		}
+		if result.RowsAffected > 0 {
+			report.Suggestions = append(report.Suggestions, StorageDriftSuggestion{
+				Kind: DriftDuplicate, Path: file.Path, PhotoID: existing.ID, StoredPath: existing.StoredPath,
+				Size: file.Size, ExpectedSize: existing.FileSize,
+			})
+			continue
+		}
+		report.Suggestions = append(report.Suggestions, StorageDriftSuggestion{Kind: DriftAdopt, Path: file.Path, Size: file.Size})
+	}
+
+	for _, photo := range missingByHash {
+		report.Suggestions = append(report.Suggestions, StorageDriftSuggestion{
+			Kind: DriftMissing, PhotoID: photo.ID, StoredPath: photo.StoredPath, ExpectedSize: photo.FileSize,
+		})
+	}
+	sort.SliceStable(report.Suggestions, func(i, j int) bool {
+		a, b := report.Suggestions[i], report.Suggestions[j]
+		if a.Kind != b.Kind {
+			return driftKindOrder(a.Kind) < driftKindOrder(b.Kind)
+		}
+		return a.PhotoID < b.PhotoID || (a.PhotoID == b.PhotoID && a.Path < b.Path)
+	})
+	return report, nil
+}
+
+// Reconcile verifica os volumes e aplica as ações escolhidas. Retorna o relatório da verificação com o
+// resultado de cada ação (na simulação, o que seria aplicado).
+func (s *StorageDriftService) Reconcile(ctx context.Context, opts StorageDriftOptions) (*StorageDriftReport, error) {
+	report, err := s.Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.DryRun = opts.DryRun
+
+	for i := range report.Suggestions {
+		suggestion := &report.Suggestions[i]
+		switch {
+		case suggestion.Kind == DriftRelink && opts.Relink:
+			if !opts.DryRun {
+				if err := s.relink(ctx, suggestion); err != nil {
+					suggestion.Error = err.Error()
+					continue
+				}
+			}
+			suggestion.Applied = true
+			report.Relinked++
+		case suggestion.Kind == DriftAdopt && opts.Adopt:
+			if !opts.DryRun {
+				photo, err := s.adopt(ctx, suggestion.Path)
+				if err != nil {
+					suggestion.Error = err.Error()
+					continue
+				}
+				suggestion.PhotoID = photo.ID
+			}
+			suggestion.Applied = true
+			report.Adopted++
+		}
+	}
+	return report, nil
+}
+
+// relink aponta a foto para o arquivo encontrado, no volume onde ele está.
+func (s *StorageDriftService) relink(ctx context.Context, suggestion *StorageDriftSuggestion) error {
+	volume := ""
+	for _, v := range s.Photos.FileManager.Volumes() {
+		if rel, err := filepath.Rel(v.Path, suggestion.Path); err == nil && filepath.IsLocal(rel) {
+			volume = v.Name
+			break
+		}
+	}
+	result := s.Photos.DB.WithContext(ctx).Model(&database.Photo{}).
+		Where("id = ? AND stored_path = ?", suggestion.PhotoID, suggestion.StoredPath).
+		Updates(map[string]interface{}{"stored_path": suggestion.Path, "volume": volume})
+	if result.Error != nil {
+		return fmt.Errorf("não foi possível atualizar o caminho da foto %d: %w", suggestion.PhotoID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("a foto %d foi alterada ou removida durante a verificação", suggestion.PhotoID)
+	}
+	s.Photos.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": suggestion.PhotoID})
+	return nil
+}
+
+// adopt importa um arquivo sem foto cadastrada. O arquivo é antes movido para uma pasta oculta ao lado
+// dele, para que a cópia gravada pela ingestão nunca o sobrescreva (o layout pode escolher o mesmo
+// caminho); com a importação concluída, a pasta é removida, e em caso de falha o arquivo volta ao lugar.
+func (s *StorageDriftService) adopt(ctx context.Context, path string) (*database.Photo, error) {
+	tempDir, err := os.MkdirTemp(filepath.Dir(path), ".drift-adopt-")
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível preparar a adoção de '%s': %w", path, err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	staged := filepath.Join(tempDir, filepath.Base(path))
+	if err := os.Rename(path, staged); err != nil {
+		return nil, fmt.Errorf("não foi possível preparar a adoção de '%s': %w", path, err)
+	}
+	photo, err := s.Photos.ImportPhotoFromPath(ctx, staged, false, PhotoOrigin{Source: database.SourceImport, Detail: path})
+	if err != nil {
+		if restoreErr := os.Rename(staged, path); restoreErr != nil {
+			log.Printf("Aviso: não foi possível devolver '%s' ao lugar após a falha na adoção: %v\n", path, restoreErr)
+		}
+		return nil, err
+	}
+	return photo, nil
+}
+
+// StartScheduler verifica periodicamente os volumes, até o contexto ser cancelado. Nada é aplicado
+// automaticamente: as divergências são registradas no log e, se houver fotos ausentes ou alteradas,
+// notificadas como falha de integridade; a reconciliação é feita em POST /admin/storage/drift/reconcile.
+func (s *StorageDriftService) StartScheduler(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := s.Scan(ctx)
+				if err != nil {
+					log.Printf("Erro na verificação de divergências do armazenamento: %v\n", err)
+					continue
+				}
+				if len(report.Suggestions) == 0 {
+					continue
+				}
+				adopt, relink, duplicates := report.Count(DriftAdopt), report.Count(DriftRelink), report.Count(DriftDuplicate)
+				sizeMismatch, missing := report.Count(DriftSizeMismatch), report.Count(DriftMissing)
+				log.Printf("Verificação do armazenamento: %d arquivo(s) novo(s), %d movido(s), %d cópia(s) solta(s), %d com tamanho divergente e %d ausente(s)\n",
+					adopt, relink, duplicates, sizeMismatch, missing)
+				if sizeMismatch > 0 || missing > 0 {
+					s.Photos.Events.Publish(events.TypeIntegrityFailure, map[string]interface{}{
+						"size_mismatch": sizeMismatch,
+						"missing_files": missing,
+						"message": fmt.Sprintf("Verificação do armazenamento: %d arquivo(s) com tamanho divergente e %d ausente(s); veja GET /admin/storage/drift.",
+							sizeMismatch, missing),
+					})
+				}
+			}
+		}
+	}()
+}
+
+// driftKey normaliza um caminho para a comparação entre o disco e o banco, que pode guardar caminhos
+// relativos ao diretório de trabalho.
+func driftKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// driftKindOrder ordena as sugestões no relatório: primeiro as que exigem atenção.
+func driftKindOrder(kind string) int {
+	switch kind {
+	case DriftMissing:
+		return 0
+	case DriftSizeMismatch:
+		return 1
+	case DriftRelink:
+		return 2
+	case DriftAdopt:
+		return 3
+	}
+	return 4
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"photo-manager/internal/events"
+	"sync"
+)
+
+// summaryCache guarda o resultado de uma agregação cara até que seja invalidado por um evento.
+type summaryCache[T any] struct {
+	mu         sync.RWMutex
+	value      []T
+	valid      bool
+	generation uint64 // Incrementada a cada invalidação
+}
+
+// Get retorna o valor em cache, se houver, e a geração atual, a ser informada em Set ao armazenar o valor
+// calculado na falta dele.
+func (c *summaryCache[T]) Get() ([]T, bool, uint64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.value, c.valid, c.generation
+}
+
+// Set armazena um novo valor, calculado a partir da geração informada. Se o cache foi invalidado durante o
+// cálculo, o valor pode não refletir a alteração e é descartado.
+func (c *summaryCache[T]) Set(value []T, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.value = value
+	c.valid = true
+}
+
+// Invalidate descarta o valor em cache.
+func (c *summaryCache[T]) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value = nil
+	c.valid = false
+	c.generation++
+}
+
+// invalidateOn registra o cache no barramento para ser invalidado quando um dos tipos de evento for publicado.
+func (c *summaryCache[T]) invalidateOn(bus *events.Bus, eventTypes ...string) {
+	if bus == nil {
+		return
+	}
+
+	watched := make(map[string]bool, len(eventTypes))
+	for _, t := range eventTypes {
+		watched[t] = true
+	}
+	bus.Subscribe(func(e events.Event) {
+		if watched[e.Type] {
+			c.Invalidate()
+		}
+	})
+}
@@ -0,0 +1,399 @@
+package service
+
+import (
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// TagService gerencia as tags das fotos.
+type TagService struct {
+	DB     *gorm.DB
+	Events *events.Bus
+
+	summaries summaryCache[TagSummary] // Contagens por tag, invalidadas por eventos
+}
+
+// TagSummary combina uma tag com as agregações das fotos marcadas com ela.
+type TagSummary struct {
+	database.Tag
+	PhotoCount int64 // Quantidade de fotos com a tag
+	TotalBytes int64 // Soma do tamanho dos arquivos das fotos com a tag
+}
+
+// NewTagService cria uma nova instância de TagService.
+func NewTagService(db *gorm.DB, bus *events.Bus) *TagService {
+	s := &TagService{
+		DB:     db,
+		Events: bus,
+	}
+	s.summaries.invalidateOn(bus, events.TypeTagsChanged, events.TypePhotoUpdated, events.TypePhotoDeleted)
+	return s
+}
+
+// ListTags retorna todas as tags com a contagem de fotos e o total de bytes, calculados via SQL.
+// O resultado fica em cache até que tags ou fotos sejam alteradas.
+func (s *TagService) ListTags() ([]TagSummary, error) {
+	cached, ok, generation := s.summaries.Get()
+	if ok {
+		return cached, nil
+	}
+
+	var summaries []TagSummary
+	result := s.DB.Model(&database.Tag{}).
+		Select("tags.*, COUNT(photos.id) AS photo_count, COALESCE(SUM(photos.file_size), 0) AS total_bytes").
+		Joins("LEFT JOIN photo_tags ON photo_tags.tag_id = tags.id AND photo_tags.deleted_at IS NULL").
+		Joins("LEFT JOIN photos ON photos.id = photo_tags.photo_id AND photos.deleted_at IS NULL").
+		Group("tags.id").
+		Order("tags.name ASC").
+		Scan(&summaries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar tags: %w", result.Error)
+	}
+
+	s.summaries.Set(summaries, generation)
+	return summaries, nil
+}
+
+// SetPhotoTags substitui as tags de uma foto. Tags existentes são reutilizadas sem diferenciar
+// maiúsculas de minúsculas; o campo Photo.Tags é atualizado com a lista separada por vírgulas. Com version
+// não vazio, as tags só são trocadas se a foto ainda estiver nessa versão (ver PhotoVersion); caso contrário
+// retorna *PhotoVersionConflictError.
+func (s *TagService) SetPhotoTags(photoID uint, names []string, version string) (*database.Photo, error) {
+	names = normalizeTagNames(names)
+
+	var photo database.Photo
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		current, err := checkPhotoVersion(tx, photoID, version)
+		if err != nil {
+			return err
+		}
+		photo = *current
+		if err := checkPhotoUnlocked(tx, photoID); err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("photo_id = ?", photoID).Delete(&database.PhotoTag{}).Error; err != nil {
+			return fmt.Errorf("não foi possível remover as tags anteriores: %w", err)
+		}
+
+		storedNames := make([]string, 0, len(names))
+		for _, name := range names {
+			tag, err := findOrCreateTag(tx, name)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(&database.PhotoTag{PhotoID: photoID, TagID: tag.ID}).Error; err != nil {
+				return fmt.Errorf("não foi possível associar a tag '%s': %w", tag.Name, err)
+			}
+			storedNames = append(storedNames, tag.Name)
+		}
+
+		photo.Tags = strings.Join(storedNames, ",")
+		if err := tx.Model(&photo).Update("tags", photo.Tags).Error; err != nil {
+			return fmt.Errorf("não foi possível atualizar as tags da foto: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Events.Publish(events.TypeTagsChanged, map[string]interface{}{"photo_id": photoID})
+	return &photo, nil
+}
+
+// AddTags acrescenta tags às fotos, mantendo as que elas já têm. Fotos bloqueadas são ignoradas. Retorna
+// quantas fotos receberam alguma tag nova.
+func (s *TagService) AddTags(photoIDs []uint, names []string) (int, error) {
+	if len(normalizeTagNames(names)) == 0 {
+		return 0, ErrTagNameInvalid
+	}
+
+	var tagged []uint
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		tagged, err = addPhotoTags(tx, uniqueIDs(photoIDs), names)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, photoID := range tagged {
+		s.Events.Publish(events.TypeTagsChanged, map[string]interface{}{"photo_id": photoID})
+	}
+	return len(tagged), nil
+}
+
+// ErrTagNameInvalid indica um nome de tag vazio (ou só com separadores).
+var ErrTagNameInvalid = i18n.NewError(i18n.CodeTagNameInvalid)
+
+// ErrTagMergeInvalid indica uma mesclagem sem tags de origem ou com a tag de destino entre as de origem.
+var ErrTagMergeInvalid = i18n.NewError(i18n.CodeTagMergeInvalid)
+
+// TagPhotosLockedError indica que fotos afetadas por uma renomeação ou mesclagem estão bloqueadas.
+// Como a operação é atômica, nenhuma foto é alterada.
+type TagPhotosLockedError struct {
+	PhotoIDs []uint
+}
+
+func (e *TagPhotosLockedError) Error() string {
+	return e.Localize(i18n.DefaultLocale)
+}
+
+// MessageCode retorna o código do erro.
+func (e *TagPhotosLockedError) MessageCode() string {
+	return i18n.CodeTagPhotosLocked
+}
+
+// Localize retorna a mensagem no idioma informado.
+func (e *TagPhotosLockedError) Localize(locale string) string {
+	return i18n.Message(locale, i18n.CodeTagPhotosLocked, len(e.PhotoIDs))
+}
+
+// RenameTag renomeia a tag, atualizando a lista de tags (Photo.Tags) de todas as fotos marcadas com ela.
+// Mudar apenas maiúsculas e minúsculas é permitido; um nome já usado por outra tag (sem diferenciar
+// maiúsculas) resulta em erro de conflito, pois o caso é de mesclagem. Retorna a tag e a quantidade de fotos.
+func (s *TagService) RenameTag(id uint, name string) (*database.Tag, int, error) {
+	names := normalizeTagNames([]string{name})
+	if len(names) == 0 {
+		return nil, 0, ErrTagNameInvalid
+	}
+	name = names[0]
+
+	var tag database.Tag
+	var photoIDs []uint
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&tag, id).Error; err != nil {
+			return fmt.Errorf("erro ao buscar tag: %w", err)
+		}
+
+		var existing database.Tag
+		result := tx.Where("LOWER(name) = LOWER(?) AND id <> ?", name, id).Limit(1).Find(&existing)
+		if result.Error != nil {
+			return fmt.Errorf("erro ao buscar tag '%s': %w", name, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			return i18n.NewError(i18n.CodeTagNameConflict, existing.Name, existing.ID)
+		}
+
+		var err error
+		if photoIDs, err = taggedPhotoIDs(tx, []uint{id}); err != nil {
+			return err
+		}
+		if err := checkTagPhotosUnlocked(tx, photoIDs); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&tag).Update("name", name).Error; err != nil {
+			return fmt.Errorf("não foi possível renomear a tag: %w", err)
+		}
+		return refreshPhotoTagNames(tx, photoIDs)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.Events.Publish(events.TypeTagsChanged, map[string]interface{}{"tag_id": tag.ID, "name": tag.Name, "photo_count": len(photoIDs)})
+	return &tag, len(photoIDs), nil
+}
+
+// MergeTags mescla as tags de origem na tag de destino: as fotos marcadas com qualquer uma delas passam a
+// ter a tag de destino (uma única vez, na posição da primeira tag mesclada) e as tags de origem são
+// removidas. Tudo acontece em uma única transação. Retorna a tag de destino e a quantidade de fotos afetadas.
+func (s *TagService) MergeTags(sourceIDs []uint, targetID uint) (*database.Tag, int, error) {
+	if len(sourceIDs) == 0 || slices.Contains(sourceIDs, targetID) {
+		return nil, 0, ErrTagMergeInvalid
+	}
+	slices.Sort(sourceIDs)
+	sourceIDs = slices.Compact(sourceIDs)
+
+	var target database.Tag
+	var photoIDs []uint
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&target, targetID).Error; err != nil {
+			return fmt.Errorf("erro ao buscar tag: %w", err)
+		}
+		var found int64
+		if err := tx.Model(&database.Tag{}).Where("id IN ?", sourceIDs).Count(&found).Error; err != nil {
+			return fmt.Errorf("erro ao buscar tags: %w", err)
+		}
+		if int(found) != len(sourceIDs) {
+			return fmt.Errorf("erro ao buscar tags: %w", gorm.ErrRecordNotFound)
+		}
+
+		var err error
+		if photoIDs, err = taggedPhotoIDs(tx, sourceIDs); err != nil {
+			return err
+		}
+		if err := checkTagPhotosUnlocked(tx, photoIDs); err != nil {
+			return err
+		}
+
+		mergedIDs := append([]uint{targetID}, sourceIDs...)
+		for _, photoID := range photoIDs {
+			var links []database.PhotoTag
+			if err := tx.Where("photo_id = ? AND tag_id IN ?", photoID, mergedIDs).Order("id").Find(&links).Error; err != nil {
+				return fmt.Errorf("erro ao buscar as tags da foto %d: %w", photoID, err)
+			}
+			// A primeira associação passa a apontar para o destino; as demais seriam repetições
+			if err := tx.Model(&links[0]).Update("tag_id", targetID).Error; err != nil {
+				return fmt.Errorf("não foi possível associar a tag à foto %d: %w", photoID, err)
+			}
+			for _, link := range links[1:] {
+				if err := tx.Unscoped().Delete(&link).Error; err != nil {
+					return fmt.Errorf("não foi possível remover a tag repetida da foto %d: %w", photoID, err)
+				}
+			}
+		}
+
+		if err := tx.Unscoped().Where("tag_id IN ?", sourceIDs).Delete(&database.PhotoTag{}).Error; err != nil {
+			return fmt.Errorf("não foi possível remover as associações das tags mescladas: %w", err)
+		}
+		if err := tx.Unscoped().Delete(&database.Tag{}, sourceIDs).Error; err != nil {
+			return fmt.Errorf("não foi possível remover as tags mescladas: %w", err)
+		}
+		return refreshPhotoTagNames(tx, photoIDs)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.Events.Publish(events.TypeTagsChanged, map[string]interface{}{"tag_id": target.ID, "merged_ids": sourceIDs, "photo_count": len(photoIDs)})
+	return &target, len(photoIDs), nil
+}
+
+// taggedPhotoIDs retorna as fotos marcadas com qualquer uma das tags, em ordem de ID.
+func taggedPhotoIDs(tx *gorm.DB, tagIDs []uint) ([]uint, error) {
+	var photoIDs []uint
+	err := tx.Model(&database.PhotoTag{}).Distinct("photo_id").Where("tag_id IN ?", tagIDs).Order("photo_id").Pluck("photo_id", &photoIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar as fotos da tag: %w", err)
+	}
+	return photoIDs, nil
+}
+
+// checkTagPhotosUnlocked retorna TagPhotosLockedError se alguma das fotos estiver bloqueada.
+func checkTagPhotosUnlocked(tx *gorm.DB, photoIDs []uint) error {
+	locked, err := lockedPhotoIDs(tx, photoIDs)
+	if err != nil {
+		return err
+	}
+	if len(locked) == 0 {
+		return nil
+	}
+	lockedErr := &TagPhotosLockedError{}
+	for _, photoID := range photoIDs {
+		if locked[photoID] {
+			lockedErr.PhotoIDs = append(lockedErr.PhotoIDs, photoID)
+		}
+	}
+	return lockedErr
+}
+
+// refreshPhotoTagNames regrava Photo.Tags das fotos a partir das associações, na ordem em que foram feitas.
+func refreshPhotoTagNames(tx *gorm.DB, photoIDs []uint) error {
+	for _, photoID := range photoIDs {
+		var names []string
+		err := tx.Model(&database.PhotoTag{}).
+			Joins("JOIN tags ON tags.id = photo_tags.tag_id").
+			Where("photo_tags.photo_id = ?", photoID).
+			Order("photo_tags.id").
+			Pluck("tags.name", &names).Error
+		if err != nil {
+			return fmt.Errorf("erro ao buscar as tags da foto %d: %w", photoID, err)
+		}
+		// UpdateColumn: a lista muda por causa da tag, não de uma edição da foto
+		if err := tx.Model(&database.Photo{}).Where("id = ?", photoID).UpdateColumn("tags", strings.Join(names, ",")).Error; err != nil {
+			return fmt.Errorf("não foi possível atualizar as tags da foto %d: %w", photoID, err)
+		}
+	}
+	return nil
+}
+
+// addPhotoTags acrescenta as tags às fotos, preservando as que elas já têm. Fotos bloqueadas são ignoradas.
+// Retorna as fotos que receberam alguma tag.
+func addPhotoTags(tx *gorm.DB, photoIDs []uint, names []string) ([]uint, error) {
+	names = normalizeTagNames(names)
+	if len(names) == 0 || len(photoIDs) == 0 {
+		return nil, nil
+	}
+	locked, err := lockedPhotoIDs(tx, photoIDs)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]*database.Tag, 0, len(names))
+	for _, name := range names {
+		tag, err := findOrCreateTag(tx, name)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	var tagged []uint
+	for _, photoID := range photoIDs {
+		if locked[photoID] {
+			continue
+		}
+		var current []uint
+		if err := tx.Model(&database.PhotoTag{}).Where("photo_id = ?", photoID).Pluck("tag_id", &current).Error; err != nil {
+			return nil, fmt.Errorf("erro ao buscar as tags da foto %d: %w", photoID, err)
+		}
+		added := false
+		for _, tag := range tags {
+			if slices.Contains(current, tag.ID) {
+				continue
+			}
+			if err := tx.Create(&database.PhotoTag{PhotoID: photoID, TagID: tag.ID}).Error; err != nil {
+				return nil, fmt.Errorf("não foi possível associar a tag '%s': %w", tag.Name, err)
+			}
+			added = true
+		}
+		if added {
+			tagged = append(tagged, photoID)
+		}
+	}
+	return tagged, refreshPhotoTagNames(tx, tagged)
+}
+
+// findOrCreateTag busca uma tag pelo nome (sem diferenciar maiúsculas) ou a cria.
+func findOrCreateTag(tx *gorm.DB, name string) (*database.Tag, error) {
+	var tag database.Tag
+	result := tx.Where("LOWER(name) = LOWER(?)", name).Limit(1).Find(&tag)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar tag '%s': %w", name, result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return &tag, nil
+	}
+
+	tag = database.Tag{Name: name}
+	if err := tx.Create(&tag).Error; err != nil {
+		return nil, fmt.Errorf("não foi possível criar a tag '%s': %w", name, err)
+	}
+	return &tag, nil
+}
+
+// normalizeTagNames remove espaços, nomes vazios e repetições (sem diferenciar maiúsculas).
+// Vírgulas não são permitidas, pois são o separador de Photo.Tags.
+func normalizeTagNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(strings.ReplaceAll(name, ",", " "))
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
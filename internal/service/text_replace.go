@@ -0,0 +1,268 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Campos aceitos na substituição em lote.
+const (
+	ReplaceFieldDescription = "description"
+	ReplaceFieldTags        = "tags"
+)
+
+// replacePreviewLimit é a quantidade máxima de alterações listadas no relatório; os totais são sempre completos.
+const replacePreviewLimit = 500
+
+// TextReplaceService faz buscas e substituições em lote nas descrições das fotos e nos nomes das tags,
+// para limpar bibliotecas importadas de outras ferramentas.
+type TextReplaceService struct {
+	DB     *gorm.DB
+	Events *events.Bus
+}
+
+// NewTextReplaceService cria uma nova instância de TextReplaceService.
+func NewTextReplaceService(db *gorm.DB, bus *events.Bus) *TextReplaceService {
+	return &TextReplaceService{DB: db, Events: bus}
+}
+
+// TextReplaceOptions descreve uma substituição. Find é um texto literal, ou uma expressão regular com
+// Regex (nesse caso Replace aceita referências como $1). Fields vazio equivale a descrições e tags.
+type TextReplaceOptions struct {
+	Find       string
+	Replace    string
+	Regex      bool
+	IgnoreCase bool
+	Fields     []string
+	DryRun     bool
+}
+
+// TextChange é uma alteração de texto: a descrição de uma foto ou o nome de uma tag.
+type TextChange struct {
+	ID         uint // ID da foto ou da tag
+	Before     string
+	After      string
+	PhotoCount int    // Tags: fotos marcadas com a tag
+	Skipped    string // Motivo de a alteração não ser aplicada (vazio se aplicável)
+}
+
+// TextReplaceReport resume uma substituição. Sem DryRun, as alterações sem Skipped foram aplicadas.
+type TextReplaceReport struct {
+	DryRun             bool
+	Descriptions       []TextChange
+	DescriptionMatches int // Total de descrições alteradas (ou a alterar), além das listadas
+	Tags               []TextChange
+	TagMatches         int
+	Skipped            int // Alterações ignoradas (fotos bloqueadas, nomes vazios ou em conflito)
+	Truncated          bool
+}
+
+// Motivos para ignorar uma alteração.
+const (
+	ReplaceSkippedLocked   = "locked"   // Foto bloqueada (ou, nas tags, alguma foto marcada com ela)
+	ReplaceSkippedEmpty    = "empty"    // O nome da tag ficaria vazio
+	ReplaceSkippedConflict = "conflict" // O novo nome já pertence a outra tag; use a mesclagem de tags
+)
+
+// Replace executa a busca e substituição. Com DryRun nada é gravado e o relatório serve de prévia; sem
+// ele, todas as alterações aplicáveis são gravadas em uma única transação.
+func (s *TextReplaceService) Replace(opts TextReplaceOptions) (*TextReplaceReport, error) {
+	replace, err := compileReplacement(opts)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]bool{}
+	for _, field := range opts.Fields {
+		if field != ReplaceFieldDescription && field != ReplaceFieldTags {
+			return nil, i18n.NewError(i18n.CodeTextReplaceInvalid, field)
+		}
+		fields[field] = true
+	}
+	if len(fields) == 0 {
+		fields[ReplaceFieldDescription], fields[ReplaceFieldTags] = true, true
+	}
+
+	report := &TextReplaceReport{DryRun: opts.DryRun}
+	var updatedPhotos, taggedPhotos []uint
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		var descriptions, tags []TextChange
+		var err error
+		if fields[ReplaceFieldDescription] {
+			if descriptions, err = replaceDescriptions(tx, replace); err != nil {
+				return err
+			}
+		}
+		if fields[ReplaceFieldTags] {
+			if tags, err = replaceTagNames(tx, replace); err != nil {
+				return err
+			}
+		}
+
+		report.DescriptionMatches, report.TagMatches = len(descriptions), len(tags)
+		for _, change := range append(append([]TextChange{}, descriptions...), tags...) {
+			if change.Skipped != "" {
+				report.Skipped++
+			}
+		}
+		report.Descriptions, report.Tags = descriptions, tags
+		if len(descriptions)+len(tags) > replacePreviewLimit {
+			report.Truncated = true
+			report.Descriptions = descriptions[:min(len(descriptions), replacePreviewLimit)]
+			report.Tags = tags[:min(len(tags), replacePreviewLimit-len(report.Descriptions))]
+		}
+		if opts.DryRun {
+			return nil
+		}
+
+		for _, change := range descriptions {
+			if change.Skipped != "" {
+				continue
+			}
+			if err := tx.Model(&database.Photo{}).Where("id = ?", change.ID).Update("description", change.After).Error; err != nil {
+				return fmt.Errorf("não foi possível atualizar a descrição da foto %d: %w", change.ID, err)
+			}
+			updatedPhotos = append(updatedPhotos, change.ID)
+		}
+		var renamed []uint
+		for _, change := range tags {
+			if change.Skipped != "" {
+				continue
+			}
+			if err := tx.Model(&database.Tag{}).Where("id = ?", change.ID).Update("name", change.After).Error; err != nil {
+				return fmt.Errorf("não foi possível renomear a tag '%s': %w", change.Before, err)
+			}
+			renamed = append(renamed, change.ID)
+		}
+		if len(renamed) == 0 {
+			return nil
+		}
+		if taggedPhotos, err = taggedPhotoIDs(tx, renamed); err != nil {
+			return err
+		}
+		return refreshPhotoTagNames(tx, taggedPhotos)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, photoID := range updatedPhotos {
+		s.Events.Publish(events.TypePhotoUpdated, map[string]interface{}{"photo_id": photoID})
+	}
+	if len(taggedPhotos) > 0 {
+		s.Events.Publish(events.TypeTagsChanged, map[string]interface{}{"photo_count": len(taggedPhotos)})
+	}
+	return report, nil
+}
+
+// compileReplacement monta a função de substituição, validando o padrão.
+func compileReplacement(opts TextReplaceOptions) (func(string) string, error) {
+	if opts.Find == "" {
+		return nil, i18n.NewError(i18n.CodeTextReplaceInvalid, "find")
+	}
+	pattern := opts.Find
+	if !opts.Regex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if opts.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, i18n.NewError(i18n.CodeTextReplaceInvalid, err.Error())
+	}
+	if opts.Regex {
+		return func(text string) string { return re.ReplaceAllString(text, opts.Replace) }, nil
+	}
+	return func(text string) string { return re.ReplaceAllLiteralString(text, opts.Replace) }, nil
+}
+
+// replaceDescriptions calcula as novas descrições das fotos, em ordem de ID.
+func replaceDescriptions(tx *gorm.DB, replace func(string) string) ([]TextChange, error) {
+	var changes []TextChange
+	var batch []database.Photo
+	err := tx.Model(&database.Photo{}).Select("id", "description").Where("description <> ''").Order("id").
+		FindInBatches(&batch, 1000, func(*gorm.DB, int) error {
+			for _, photo := range batch {
+				if replaced := replace(photo.Description); replaced != photo.Description {
+					changes = append(changes, TextChange{ID: photo.ID, Before: photo.Description, After: strings.TrimSpace(replaced)})
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar as descrições: %w", err)
+	}
+
+	photoIDs := make([]uint, 0, len(changes))
+	for _, change := range changes {
+		photoIDs = append(photoIDs, change.ID)
+	}
+	locked, err := lockedPhotoIDs(tx, photoIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range changes {
+		if locked[changes[i].ID] {
+			changes[i].Skipped = ReplaceSkippedLocked
+		}
+	}
+	return changes, nil
+}
+
+// replaceTagNames calcula os novos nomes das tags, em ordem alfabética. Nomes que ficariam vazios ou que
+// coincidem (sem diferenciar maiúsculas) com outra tag são ignorados, assim como tags de fotos bloqueadas.
+func replaceTagNames(tx *gorm.DB, replace func(string) string) ([]TextChange, error) {
+	var tags []database.Tag
+	if err := tx.Order("name").Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("erro ao buscar tags: %w", err)
+	}
+	owner := make(map[string]uint, len(tags)) // Nome em minúsculas -> tag que o terá após as alterações
+	for _, tag := range tags {
+		owner[strings.ToLower(tag.Name)] = tag.ID
+	}
+
+	var changes []TextChange
+	for _, tag := range tags {
+		after := replace(tag.Name)
+		if after == tag.Name {
+			continue
+		}
+		change := TextChange{ID: tag.ID, Before: tag.Name}
+		if names := normalizeTagNames([]string{after}); len(names) > 0 {
+			change.After = names[0]
+		}
+
+		photoIDs, err := taggedPhotoIDs(tx, []uint{tag.ID})
+		if err != nil {
+			return nil, err
+		}
+		change.PhotoCount = len(photoIDs)
+
+		key := strings.ToLower(change.After)
+		switch {
+		case change.After == "":
+			change.Skipped = ReplaceSkippedEmpty
+		case owner[key] != 0 && owner[key] != tag.ID:
+			change.Skipped = ReplaceSkippedConflict
+		default:
+			var lockedErr *TagPhotosLockedError
+			if err := checkTagPhotosUnlocked(tx, photoIDs); errors.As(err, &lockedErr) {
+				change.Skipped = ReplaceSkippedLocked
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			delete(owner, strings.ToLower(tag.Name))
+			owner[key] = tag.ID
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
@@ -0,0 +1,406 @@
+package service
+
+import (
+	"context"
+	"log"
+	"os"
+	"photo-manager/internal/database"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/thumbnail"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrThumbnailBusy indica que a geração da miniatura não começou dentro do tempo de espera, por excesso
+// de gerações simultâneas. A geração continua na fila: basta tentar de novo em instantes.
+var ErrThumbnailBusy = i18n.NewError(i18n.CodeThumbnailBusy)
+
+// ThumbnailMetrics são as métricas da geração de miniaturas, para ajuste da política e dos workers.
+type ThumbnailMetrics struct {
+	Policy             string
+	Workers            int
+	QueueDepth         int           // Fotos aguardando na fila
+	QueueCapacity      int           // Capacidade da fila
+	InFlight           int           // Gerações em andamento ou aguardando um worker
+	RenderWorkers      int           // Máximo de gerações simultâneas (sob demanda e da fila)
+	Rendering          int           // Gerações ocupando um worker agora
+	QueueTimeout       time.Duration // Espera máxima de uma solicitação pelo início da geração
+	Busy               int64         // Solicitações respondidas com "tente novamente" por sobrecarga
+	Generated          int64         // Miniaturas geradas desde a inicialização
+	Failed             int64         // Gerações com falha desde a inicialização
+	Shared             int64         // Solicitações atendidas por uma geração já em andamento da mesma foto
+	Dropped            int64         // Fotos não enfileiradas por fila cheia
+	AvgLatency         time.Duration // Duração média de uma geração
+	MaxLatency         time.Duration // Maior duração de uma geração
+	LastBackfillAt     *time.Time    // Início do último lote
+	LastBackfillQueued int           // Fotos enfileiradas no último lote
+}
+
+// thumbnailCall é uma geração em andamento, compartilhada por todas as solicitações da mesma foto.
+type thumbnailCall struct {
+	started chan struct{} // Fechado quando a geração obtém um worker
+	done    chan struct{}
+	path    string
+	err     error
+}
+
+// ThumbnailService gera as miniaturas conforme a política configurada: sob demanda, logo após a ingestão
+// ou em lote no horário programado. Em todas as políticas uma miniatura ausente é gerada na primeira
+// solicitação, e solicitações simultâneas da mesma foto compartilham uma única geração, evitando que uma
+// foto recém-publicada dispare dezenas de decodificações iguais. As gerações (sob demanda e da fila)
+// dividem um conjunto limitado de workers; sob sobrecarga, quem espera mais que QueueTimeout recebe
+// ErrThumbnailBusy, e a geração segue pendente para a próxima tentativa.
+type ThumbnailService struct {
+	DB           *gorm.DB
+	PhotoService *PhotoService
+	Policy       string        // Ver constantes thumbnail.Policy*
+	Workers      int           // Gerações simultâneas da fila
+	QueueTimeout time.Duration // Espera máxima pelo início de uma geração sob demanda; 0 = sem limite
+
+	// Com a fila cheia, as fotos excedentes ficam para a geração sob demanda (ou para o próximo lote)
+	queue   chan uint
+	renders chan struct{} // Um token por worker de geração
+	stop    chan struct{} // Cada valor recebido encerra um worker da fila (ver SetWorkers)
+	ctx     context.Context
+	// Fotos diferentes aguardando um worker de geração. Acima disso, novas solicitações recebem
+	// ErrThumbnailBusy na hora, sem ocupar memória com mais gerações pendentes
+	maxPending int
+
+	mu           sync.Mutex
+	inflight     map[uint]*thumbnailCall
+	working      int   // Workers da fila ocupados
+	processed    int64 // Fotos da fila processadas
+	generated    int64
+	failed       int64
+	shared       int64
+	dropped      int64
+	busy         int64
+	totalLatency time.Duration
+	maxLatency   time.Duration
+	lastBackfill *time.Time
+	lastQueued   int
+}
+
+// NewThumbnailService cria uma nova instância de ThumbnailService. renderWorkers limita as gerações
+// simultâneas, somando as sob demanda e as da fila (mínimo 1); queueSize é a capacidade da fila de
+// geração em segundo plano e maxPending, o limite de fotos aguardando um worker de geração.
+func NewThumbnailService(db *gorm.DB, ps *PhotoService, policy string, workers, renderWorkers, queueSize, maxPending int) *ThumbnailService {
+	return &ThumbnailService{
+		DB:           db,
+		PhotoService: ps,
+		Policy:       policy,
+		Workers:      max(1, workers),
+		queue:        make(chan uint, max(1, queueSize)),
+		renders:      make(chan struct{}, max(1, renderWorkers)),
+		stop:         make(chan struct{}),
+		maxPending:   maxPending,
+		inflight:     make(map[uint]*thumbnailCall),
+	}
+}
+
+// Get retorna o caminho da miniatura da foto, gerando-a se ainda não existir. Se a geração não começar
+// em QueueTimeout, retorna ErrThumbnailBusy (a geração continua pendente).
+func (s *ThumbnailService) Get(ctx context.Context, photo *database.Photo) (string, error) {
+	return s.get(ctx, photo, s.QueueTimeout)
+}
+
+// get obtém a miniatura, compartilhando a geração em andamento da mesma foto ou iniciando uma nova.
+// queueTimeout 0 aguarda um worker pelo tempo que for preciso (usado pelos workers da fila).
+func (s *ThumbnailService) get(ctx context.Context, photo *database.Photo, queueTimeout time.Duration) (string, error) {
+	if s.Ready(photo) {
+		return photo.ThumbnailPath, nil
+	}
+
+	s.mu.Lock()
+	call, ok := s.inflight[photo.ID]
+	switch {
+	case ok:
+		s.shared++
+	case len(s.inflight) >= s.maxPending+cap(s.renders):
+		s.busy++
+		s.mu.Unlock()
+		return "", ErrThumbnailBusy
+	default:
+		call = &thumbnailCall{started: make(chan struct{}), done: make(chan struct{})}
+		s.inflight[photo.ID] = call
+		// A geração roda à parte e continua mesmo se todos desistirem de esperar: a próxima tentativa
+		// encontra a miniatura pronta ou a mesma geração em andamento
+		go s.render(context.WithoutCancel(ctx), *photo, call)
+	}
+	s.mu.Unlock()
+
+	if queueTimeout > 0 {
+		timer := time.NewTimer(queueTimeout)
+		defer timer.Stop()
+		select {
+		case <-call.started:
+		case <-timer.C:
+			s.mu.Lock()
+			s.busy++
+			s.mu.Unlock()
+			return "", ErrThumbnailBusy
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	select {
+	case <-call.done:
+		return call.path, call.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// render aguarda um worker livre e gera a miniatura da chamada.
+func (s *ThumbnailService) render(ctx context.Context, photo database.Photo, call *thumbnailCall) {
+	s.renders <- struct{}{}
+	close(call.started)
+
+	start := time.Now()
+	call.path, call.err = s.PhotoService.GetThumbnailPath(ctx, &photo)
+	elapsed := time.Since(start)
+	<-s.renders
+
+	s.mu.Lock()
+	delete(s.inflight, photo.ID)
+	if call.err != nil {
+		s.failed++
+	} else {
+		s.generated++
+		s.totalLatency += elapsed
+		s.maxLatency = max(s.maxLatency, elapsed)
+	}
+	s.mu.Unlock()
+	close(call.done)
+}
+
+// Ready indica se a miniatura da foto já foi gerada.
+func (s *ThumbnailService) Ready(photo *database.Photo) bool {
+	if photo.ThumbnailPath == "" {
+		return false
+	}
+	_, err := os.Stat(photo.ThumbnailPath)
+	return err == nil
+}
+
+// Enqueue agenda a geração da miniatura em segundo plano. Retorna false se a fila estiver cheia.
+func (s *ThumbnailService) Enqueue(photoID uint) bool {
+	select {
+	case s.queue <- photoID:
+		return true
+	default:
+		s.mu.Lock()
+		s.dropped++
+		s.mu.Unlock()
+		return false
+	}
+}
+
+// Subscribe enfileira as fotos novas, na política eager.
+func (s *ThumbnailService) Subscribe(bus *events.Bus) {
+	if s.Policy != thumbnail.PolicyEager {
+		return
+	}
+	bus.Subscribe(func(e events.Event) {
+		if e.Type != events.TypePhotoCreated {
+			return
+		}
+		if photoID, ok := e.Data["photo_id"].(uint); ok {
+			s.Enqueue(photoID)
+		}
+	})
+}
+
+// Start inicia os workers da fila e, na política scheduled, o lote diário no horário informado (0 a 23,
+// horário local), até o contexto ser cancelado.
+func (s *ThumbnailService) Start(ctx context.Context, backfillHour int) {
+	s.mu.Lock()
+	s.ctx = ctx
+	workers := s.Workers
+	s.mu.Unlock()
+	for i := 0; i < workers; i++ {
+		go s.work(ctx)
+	}
+	if s.Policy != thumbnail.PolicyScheduled {
+		return
+	}
+	go func() {
+		for {
+			timer := time.NewTimer(time.Until(nextRunAt(time.Now(), backfillHour)))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				if _, err := s.Backfill(ctx); err != nil {
+					log.Printf("Miniaturas: falha no lote programado: %v\n", err)
+				}
+			}
+		}
+	}()
+}
+
+// SetWorkers altera o número de workers da fila (mínimo 1) sem reiniciar o serviço. Os workers
+// dispensados terminam a miniatura em andamento antes de parar.
+func (s *ThumbnailService) SetWorkers(workers int) {
+	workers = max(1, workers)
+	s.mu.Lock()
+	diff := workers - s.Workers
+	s.Workers = workers
+	ctx := s.ctx
+	s.mu.Unlock()
+	if ctx == nil || diff == 0 {
+		return
+	}
+
+	log.Printf("Miniaturas: workers da fila ajustados para %d\n", workers)
+	for ; diff > 0; diff-- {
+		go s.work(ctx)
+	}
+	for ; diff < 0; diff++ {
+		go func() {
+			select {
+			case s.stop <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}()
+	}
+}
+
+// work gera as miniaturas da fila.
+func (s *ThumbnailService) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
+		case photoID := <-s.queue:
+			var photo database.Photo
+			result := s.DB.WithContext(ctx).Where("id = ?", photoID).Limit(1).Find(&photo)
+			if result.Error != nil || result.RowsAffected == 0 {
+				continue // Foto removida depois de enfileirada
+			}
+			s.mu.Lock()
+			s.working++
+			s.mu.Unlock()
+			if _, err := s.get(ctx, &photo, 0); err != nil {
+				log.Printf("Miniaturas: falha ao gerar a miniatura da foto %d: %v\n", photoID, err)
+			}
+			s.mu.Lock()
+			s.working--
+			s.processed++
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Backfill enfileira todas as fotos sem miniatura (exceto vídeos e fotos em quarentena), aguardando espaço
+// na fila em vez de descartar. Retorna quantas fotos foram enfileiradas.
+func (s *ThumbnailService) Backfill(ctx context.Context) (int, error) {
+	now := time.Now()
+	s.mu.Lock()
+	s.lastBackfill = &now
+	s.lastQueued = 0
+	s.mu.Unlock()
+
+	var photos []database.Photo
+	queued := 0
+	err := s.DB.WithContext(ctx).Select("id").
+		Where("(thumbnail_path = '' OR thumbnail_path IS NULL) AND quarantined = ? AND mime_type NOT LIKE ?", false, "video/%").
+		FindInBatches(&photos, 500, func(tx *gorm.DB, batch int) error {
+			for _, photo := range photos {
+				select {
+				case s.queue <- photo.ID:
+					queued++
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			s.mu.Lock()
+			s.lastQueued = queued
+			s.mu.Unlock()
+			return nil
+		}).Error
+	return queued, err
+}
+
+// Metrics retorna as métricas atuais da geração de miniaturas.
+func (s *ThumbnailService) Metrics() ThumbnailMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	metrics := ThumbnailMetrics{
+		Policy:             s.Policy,
+		Workers:            s.Workers,
+		QueueDepth:         len(s.queue),
+		QueueCapacity:      cap(s.queue),
+		InFlight:           len(s.inflight),
+		RenderWorkers:      cap(s.renders),
+		Rendering:          len(s.renders),
+		QueueTimeout:       s.QueueTimeout,
+		Busy:               s.busy,
+		Generated:          s.generated,
+		Failed:             s.failed,
+		Shared:             s.shared,
+		Dropped:            s.dropped,
+		MaxLatency:         s.maxLatency,
+		LastBackfillAt:     s.lastBackfill,
+		LastBackfillQueued: s.lastQueued,
+	}
+	if s.generated > 0 {
+		metrics.AvgLatency = s.totalLatency / time.Duration(s.generated)
+	}
+	return metrics
+}
+
+// PoolMetrics retorna as métricas dos workers da fila (geração em segundo plano) e dos workers de geração
+// (sob demanda e da fila), no formato comum aos demais serviços.
+func (s *ThumbnailService) PoolMetrics() []PoolMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return []PoolMetrics{
+		{
+			Name:          "thumbnail_queue",
+			Workers:       s.Workers,
+			Active:        s.working,
+			QueueDepth:    len(s.queue),
+			QueueCapacity: cap(s.queue),
+			Completed:     s.processed,
+			Rejected:      s.dropped,
+		},
+		{
+			Name:          "thumbnail_renders",
+			Workers:       cap(s.renders),
+			Active:        len(s.renders),
+			QueueDepth:    max(0, len(s.inflight)-len(s.renders)),
+			QueueCapacity: s.maxPending,
+			Completed:     s.generated + s.failed,
+			Rejected:      s.busy,
+		},
+	}
+}
+
+// RetryAfter estima em quanto tempo vale tentar de novo uma solicitação recusada com ErrThumbnailBusy:
+// o tempo para os workers esvaziarem as gerações pendentes, pela latência média (de 1 a 30 segundos).
+func (s *ThumbnailService) RetryAfter() time.Duration {
+	metrics := s.Metrics()
+	latency := metrics.AvgLatency
+	if latency <= 0 {
+		latency = time.Second
+	}
+	wait := latency * time.Duration(metrics.InFlight) / time.Duration(metrics.RenderWorkers)
+	return min(max(wait, time.Second), 30*time.Second).Round(time.Second)
+}
+
+// nextRunAt retorna a próxima ocorrência do horário informado (hora cheia, horário local) após now.
+func nextRunAt(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
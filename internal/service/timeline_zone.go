@@ -0,0 +1,101 @@
+package service
+
+import (
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Nomes especiais aceitos por ParseTimelineZone, além dos nomes IANA.
+const (
+	TimeZoneLocal = "local" // Fuso do servidor
+	TimeZonePhoto = "photo" // Fuso em que cada foto foi tirada (Photo.TimeZoneOffset), quando conhecido
+)
+
+// ErrInvalidTimeZone indica um fuso horário desconhecido.
+var ErrInvalidTimeZone = i18n.NewError(i18n.CodeInvalidTimeZone)
+
+// TimelineZone é o fuso em que as datas das fotos são situadas para agrupar a linha do tempo e filtrar por
+// ano e mês: a foto das 23h de 31/03 em São Paulo é de abril em UTC, mas de março para quem está no Brasil.
+type TimelineZone struct {
+	Location *time.Location // Fuso do usuário (nil = fuso do servidor)
+	// Situa cada foto no fuso em que foi tirada, mostrando a data que a câmera marcou. As fotos sem fuso
+	// conhecido (e a data de upload) continuam em Location.
+	PerPhoto bool
+}
+
+// ParseTimelineZone interpreta um fuso: um nome IANA (ex: America/Sao_Paulo, UTC), TimeZoneLocal ou
+// TimeZonePhoto. O nome vazio retorna fallback; no modo por foto, o fuso das fotos sem fuso é o de fallback.
+func ParseTimelineZone(name string, fallback TimelineZone) (TimelineZone, error) {
+	name = strings.TrimSpace(name)
+	switch strings.ToLower(name) {
+	case "":
+		return fallback, nil
+	case TimeZonePhoto:
+		return TimelineZone{Location: fallback.Location, PerPhoto: true}, nil
+	case TimeZoneLocal:
+		return TimelineZone{Location: time.Local}, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return TimelineZone{}, ErrInvalidTimeZone
+	}
+	return TimelineZone{Location: loc}, nil
+}
+
+// location retorna o fuso do usuário, ou o do servidor se não configurado.
+func (z TimelineZone) location() *time.Location {
+	if z.Location == nil {
+		return time.Local
+	}
+	return z.Location
+}
+
+// LocalDate retorna a data da foto (EXIF ou, na falta dela, de upload) situada no fuso da linha do tempo.
+// A data aproximada (Photo.CircaDate), se informada, prevalece: o início do período, no mesmo dia em qualquer
+// fuso.
+func (z TimelineZone) LocalDate(photo database.Photo) time.Time {
+	if photo.CircaDate != nil {
+		start := photo.CircaDate.UTC()
+		return time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, z.location())
+	}
+	if photo.ExifDate == nil {
+		return photo.UploadDate.In(z.location())
+	}
+	if z.PerPhoto && photo.TimeZoneOffset != nil {
+		return photo.ExifDate.In(time.FixedZone("", *photo.TimeZoneOffset*60))
+	}
+	return photo.ExifDate.In(z.location())
+}
+
+// sqliteTimeLayout é o formato de datetime() do SQLite, que normaliza as datas gravadas para UTC (ou, com um
+// deslocamento, para a hora local da captura) antes da comparação: a comparação direta das colunas seria
+// entre textos com fusos diferentes.
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+// dateRangeQuery restringe a consulta às fotos com data EXIF ou de upload entre start e end (inclusive), datas
+// locais do fuso da linha do tempo. No modo por foto, a data EXIF das fotos com fuso conhecido é comparada na
+// hora local da captura. As fotos com data aproximada entram só se todo o período estiver no intervalo: a
+// década de 1980 não aparece no filtro de 1985, mas "1985" aparece.
+func (z TimelineZone) dateRangeQuery(query *gorm.DB, start, end time.Time) *gorm.DB {
+	circa := query.Session(&gorm.Session{NewDB: true}).
+		Where("circa_date IS NOT NULL AND date(circa_date) >= ? AND "+circaEndSQL+" <= ?", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	return query.Where(z.exactDateQuery(query.Session(&gorm.Session{NewDB: true}).Where("circa_date IS NULL"), start, end).Or(circa))
+}
+
+// exactDateQuery restringe a consulta às fotos com data EXIF ou de upload entre start e end (ver dateRangeQuery).
+func (z TimelineZone) exactDateQuery(query *gorm.DB, start, end time.Time) *gorm.DB {
+	loc := z.location()
+	start = time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), start.Second(), 0, loc)
+	end = time.Date(end.Year(), end.Month(), end.Day(), end.Hour(), end.Minute(), end.Second(), 0, loc)
+	utcStart, utcEnd := start.UTC().Format(sqliteTimeLayout), end.UTC().Format(sqliteTimeLayout)
+	if !z.PerPhoto {
+		return query.Where("(datetime(exif_date) BETWEEN ? AND ?) OR (datetime(upload_date) BETWEEN ? AND ?)", utcStart, utcEnd, utcStart, utcEnd)
+	}
+	return query.Where("(time_zone_offset IS NOT NULL AND datetime(exif_date, printf('%+d minutes', time_zone_offset)) BETWEEN ? AND ?)"+
+		" OR (time_zone_offset IS NULL AND datetime(exif_date) BETWEEN ? AND ?) OR (datetime(upload_date) BETWEEN ? AND ?)",
+		start.Format(sqliteTimeLayout), end.Format(sqliteTimeLayout), utcStart, utcEnd, utcStart, utcEnd)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"mime/multipart"
+	"os"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/validation"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Resultados previstos para um arquivo na simulação de upload.
+const (
+	PreviewCreate    = "create"    // A foto seria criada
+	PreviewDuplicate = "duplicate" // O conteúdo já está na biblioteca; o arquivo seria rejeitado ou ignorado (ver Policy)
+	PreviewReject    = "reject"    // O arquivo seria rejeitado (validação, nome em uso ou falta de espaço)
+)
+
+// UploadPreview é o que aconteceria com um arquivo enviado, sem que nada seja gravado.
+type UploadPreview struct {
+	Action     string          // Ver constantes Preview*
+	Photo      database.Photo  // Metadados que seriam gravados (sem ID)
+	TargetPath string          // Caminho onde o arquivo seria armazenado
+	Volume     string          // Volume escolhido pela política de alocação
+	Downscale  bool            // A imagem seria reduzida pela política de redução
+	Tags       []string        // Tags das regras de organização
+	Albums     []string        // Álbuns das regras de organização e álbum padrão da origem
+	Duplicate  *database.Photo // Foto existente com o mesmo conteúdo (Action duplicate, ou create para uma cópia)
+	Policy     string          // Política de duplicatas da origem aplicada, se o conteúdo já existe
+	Rejection  error           // Motivo da rejeição (Action reject): *validation.Error ou i18n.Error
+}
+
+// filenameTakenError indica que já existe uma foto com o mesmo nome de arquivo, que é único na biblioteca.
+func filenameTakenError(filename string) error {
+	return i18n.NewError(i18n.CodeFilenameTaken, filename)
+}
+
+// PreviewUpload executa as etapas do upload que não gravam nada (checksum, validação, EXIF, hash, verificação
+// de duplicatas, regras de organização e escolha do caminho) e informa o que aconteceria com o arquivo.
+// Rejeições do arquivo vêm em UploadPreview.Rejection; o erro retornado indica falhas de infraestrutura.
+func (s *PhotoService) PreviewUpload(ctx context.Context, file *multipart.FileHeader, expectedSHA256 string, origin PhotoOrigin) (*UploadPreview, error) {
+	if origin.Source == "" {
+		origin.Source = database.SourceUpload
+	}
+	tempFilePath, err := s.receiveUpload(ctx, file, expectedSHA256)
+	if err != nil {
+		return rejectedPreview(file.Filename, err)
+	}
+	defer os.Remove(tempFilePath)
+
+	req := ingestRequest{
+		SourcePath: tempFilePath,
+		Filename:   file.Filename,
+		FileSize:   file.Size,
+		UploadDate: time.Now(),
+		Origin:     origin,
+	}
+	plan, err := s.planIngest(ctx, req)
+	if err != nil {
+		return rejectedPreview(file.Filename, err)
+	}
+	if plan.Duplicate != nil {
+		return &UploadPreview{Action: PreviewDuplicate, Photo: plan.Photo, Duplicate: plan.Duplicate, Policy: plan.Policy.Policy}, nil
+	}
+
+	preview := &UploadPreview{
+		Action:    PreviewCreate,
+		Photo:     plan.Photo,
+		Tags:      plan.Organized.Tags,
+		Albums:    plan.Organized.Albums,
+		Duplicate: plan.VersionOf,
+	}
+	if plan.VersionOf != nil {
+		preview.Policy = database.DuplicatePolicyVersion
+	}
+	// Uma cópia gravada pela política version recebe outro nome de arquivo
+	filename := plan.Photo.Filename
+	album, err := findSourceAlbum(s.DB.WithContext(ctx), origin.Source, origin.Device)
+	if err != nil {
+		return nil, err
+	}
+	if album != nil && !album.Locked && !slices.ContainsFunc(preview.Albums, func(name string) bool { return strings.EqualFold(name, album.Name) }) {
+		preview.Albums = append(preview.Albums, album.Name)
+	}
+
+	req.MimeType = plan.Photo.MimeType
+	preview.Downscale = s.Downscale.applies(req, plan.Photo.Width, plan.Photo.Height)
+
+	var taken int64
+	if err := s.DB.WithContext(ctx).Model(&database.Photo{}).Where("filename = ?", filename).Count(&taken).Error; err != nil {
+		return nil, err
+	}
+	if taken > 0 {
+		preview.Action, preview.Rejection = PreviewReject, filenameTakenError(filename)
+		return preview, nil
+	}
+
+	storageKey := copyStorageKey(plan.Photo.Hash, plan.Photo.CopyIndex)
+	preview.TargetPath, preview.Volume, err = s.FileManager.PlanPath(plan.Organized.Folder, filename, storageKey, file.Size, plan.OrganizeDate)
+	if err != nil {
+		// Sem espaço ou volume disponível, o upload falharia
+		preview.Action, preview.Rejection = PreviewReject, err
+	}
+	return preview, nil
+}
+
+// rejectedPreview converte as rejeições do arquivo (validação) em uma prévia rejeitada; os demais erros
+// são falhas de infraestrutura.
+func rejectedPreview(filename string, err error) (*UploadPreview, error) {
+	if _, ok := validation.AsError(err); ok {
+		return &UploadPreview{Action: PreviewReject, Photo: database.Photo{Filename: filename}, Rejection: err}, nil
+	}
+	return nil, err
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"photo-manager/internal/database"
+	"photo-manager/internal/i18n"
+	"photo-manager/internal/storage"
+
+	"gorm.io/gorm"
+)
+
+// VolumeService gerencia o registro de volumes de armazenamento.
+type VolumeService struct {
+	DB          *gorm.DB
+	FileManager *storage.FileManager
+}
+
+// NewVolumeService cria uma nova instância de VolumeService.
+func NewVolumeService(db *gorm.DB, fm *storage.FileManager) *VolumeService {
+	return &VolumeService{
+		DB:          db,
+		FileManager: fm,
+	}
+}
+
+// LoadVolumes garante que o volume padrão (PHOTO_STORAGE_PATH) esteja registrado e carrega
+// todos os volumes do banco de dados no FileManager. Deve ser chamado na inicialização.
+// Se PHOTO_STORAGE_PATH mudou desde o registro, o volume padrão passa a apontar para o novo caminho.
+func (s *VolumeService) LoadVolumes() error {
+	basePath, err := filepath.Abs(s.FileManager.BaseStoragePath)
+	if err != nil {
+		return fmt.Errorf("caminho de armazenamento inválido '%s': %w", s.FileManager.BaseStoragePath, err)
+	}
+
+	var defaultVolume database.StorageVolume
+	result := s.DB.Where(database.StorageVolume{Name: storage.DefaultVolumeName}).
+		Attrs(database.StorageVolume{Path: basePath}).
+		FirstOrCreate(&defaultVolume)
+	if result.Error != nil {
+		return fmt.Errorf("não foi possível registrar o volume padrão: %w", result.Error)
+	}
+
+	if defaultVolume.Path != basePath {
+		// Volumes registrados antes da normalização guardam o caminho relativo: só avisa se o diretório mudou
+		if previous, err := filepath.Abs(defaultVolume.Path); err != nil || previous != basePath {
+			log.Printf("Volume padrão movido de '%s' para '%s' (PHOTO_STORAGE_PATH); as fotos já gravadas continuam no caminho anterior\n", defaultVolume.Path, basePath)
+		}
+		if err := s.DB.Model(&defaultVolume).Update("path", basePath).Error; err != nil {
+			return fmt.Errorf("não foi possível atualizar o caminho do volume padrão: %w", err)
+		}
+	}
+
+	return s.reload()
+}
+
+// ListVolumes retorna os volumes registrados em ordem de prioridade.
+func (s *VolumeService) ListVolumes() ([]database.StorageVolume, error) {
+	var volumes []database.StorageVolume
+	if result := s.DB.Order("priority ASC").Order("id ASC").Find(&volumes); result.Error != nil {
+		return nil, fmt.Errorf("erro ao buscar volumes: %w", result.Error)
+	}
+	return volumes, nil
+}
+
+// CreateVolume registra um novo volume. O diretório é criado se ainda não existir.
+func (s *VolumeService) CreateVolume(volume database.StorageVolume) (*database.StorageVolume, error) {
+	if volume.Name == "" || volume.Path == "" {
+		return nil, i18n.NewError(i18n.CodeVolumeFieldsRequired)
+	}
+	if volume.DateFrom != nil && volume.DateTo != nil && volume.DateTo.Before(*volume.DateFrom) {
+		return nil, i18n.NewError(i18n.CodeVolumeDateRange)
+	}
+
+	absPath, err := filepath.Abs(volume.Path)
+	if err != nil {
+		return nil, fmt.Errorf("caminho de volume inválido: %w", err)
+	}
+	if err := os.MkdirAll(absPath, 0755); err != nil {
+		return nil, fmt.Errorf("não foi possível criar o diretório do volume '%s': %w", absPath, err)
+	}
+	volume.Path = absPath
+
+	if result := s.DB.Create(&volume); result.Error != nil {
+		return nil, fmt.Errorf("não foi possível registrar o volume: %w", result.Error)
+	}
+
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return &volume, nil
+}
+
+// DeleteVolume remove o registro de um volume. Volumes com fotos e o volume padrão não podem ser removidos.
+func (s *VolumeService) DeleteVolume(id uint) error {
+	var volume database.StorageVolume
+	if result := s.DB.First(&volume, id); result.Error != nil {
+		return fmt.Errorf("erro ao buscar volume: %w", result.Error)
+	}
+	if volume.Name == storage.DefaultVolumeName {
+		return i18n.NewError(i18n.CodeVolumeDefault)
+	}
+
+	var photoCount int64
+	if result := s.DB.Model(&database.Photo{}).Where("volume = ?", volume.Name).Count(&photoCount); result.Error != nil {
+		return fmt.Errorf("erro ao verificar fotos do volume: %w", result.Error)
+	}
+	if photoCount > 0 {
+		return i18n.NewError(i18n.CodeVolumeNotEmpty, volume.Name, photoCount)
+	}
+
+	if result := s.DB.Unscoped().Delete(&volume); result.Error != nil {
+		return fmt.Errorf("não foi possível remover o volume: %w", result.Error)
+	}
+	return s.reload()
+}
+
+// reload sincroniza os volumes do banco de dados com o FileManager.
+func (s *VolumeService) reload() error {
+	volumes, err := s.ListVolumes()
+	if err != nil {
+		return err
+	}
+
+	storageVolumes := make([]storage.Volume, 0, len(volumes))
+	for _, v := range volumes {
+		storageVolumes = append(storageVolumes, storage.Volume{
+			Name:     v.Name,
+			Path:     v.Path,
+			Priority: v.Priority,
+			DateFrom: v.DateFrom,
+			DateTo:   v.DateTo,
+		})
+	}
+	s.FileManager.SetVolumes(storageVolumes)
+	return nil
+}
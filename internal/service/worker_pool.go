@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// PoolMetrics são as métricas de um conjunto de workers em segundo plano, para ajuste do número de workers
+// e do tamanho das filas (GET /admin/workers).
+type PoolMetrics struct {
+	Name          string
+	Workers       int   // Tarefas executadas simultaneamente
+	Active        int   // Tarefas em execução agora
+	QueueDepth    int   // Tarefas aguardando um worker
+	QueueCapacity int   // Limite da fila; acima dele as novas tarefas são recusadas
+	Completed     int64 // Tarefas concluídas desde a inicialização
+	Rejected      int64 // Tarefas recusadas por fila cheia desde a inicialização
+}
+
+// PoolReporter é implementado pelos serviços com workers em segundo plano.
+type PoolReporter interface {
+	PoolMetrics() []PoolMetrics
+}
+
+// workerPool executa tarefas com um número fixo de workers e uma fila limitada. Com a fila cheia, submit
+// recusa a tarefa na hora em vez de acumular goroutines: quem envia decide se descarta, adia ou responde
+// "tente novamente".
+type workerPool struct {
+	name  string
+	tasks chan func(context.Context)
+	stop  chan struct{} // Cada valor recebido encerra um worker (ver resize)
+
+	mu        sync.Mutex
+	ctx       context.Context // Contexto de start; nil antes de os workers começarem
+	workers   int
+	active    int
+	completed int64
+	rejected  int64
+}
+
+// newWorkerPool cria um conjunto de workers (mínimo 1) com uma fila de queueSize tarefas (mínimo 1).
+// Os workers só começam a executar após start.
+func newWorkerPool(name string, workers, queueSize int) *workerPool {
+	return &workerPool{
+		name:    name,
+		workers: max(1, workers),
+		tasks:   make(chan func(context.Context), max(1, queueSize)),
+		stop:    make(chan struct{}),
+	}
+}
+
+// start inicia os workers, que executam as tarefas até o contexto ser cancelado.
+func (p *workerPool) start(ctx context.Context) {
+	p.mu.Lock()
+	p.ctx = ctx
+	workers := p.workers
+	p.mu.Unlock()
+	for i := 0; i < workers; i++ {
+		go p.work(ctx)
+	}
+}
+
+// work executa as tarefas da fila até o contexto ser cancelado ou o worker ser dispensado por resize.
+func (p *workerPool) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stop:
+			return
+		case task := <-p.tasks:
+			p.run(ctx, task)
+		}
+	}
+}
+
+// resize altera o número de workers (mínimo 1) sem reiniciar o conjunto. Os workers dispensados terminam
+// a tarefa em andamento antes de parar; a capacidade da fila não muda.
+func (p *workerPool) resize(workers int) {
+	workers = max(1, workers)
+	p.mu.Lock()
+	diff := workers - p.workers
+	p.workers = workers
+	ctx := p.ctx
+	p.mu.Unlock()
+	if ctx == nil || diff == 0 {
+		return // Antes de start, o novo número vale quando os workers começarem
+	}
+
+	log.Printf("Workers '%s': ajustados para %d\n", p.name, workers)
+	for ; diff > 0; diff-- {
+		go p.work(ctx)
+	}
+	for ; diff < 0; diff++ {
+		go func() {
+			select {
+			case p.stop <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}()
+	}
+}
+
+// run executa uma tarefa, contabilizando-a.
+func (p *workerPool) run(ctx context.Context, task func(context.Context)) {
+	p.mu.Lock()
+	p.active++
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.active--
+		p.completed++
+		p.mu.Unlock()
+	}()
+	task(ctx)
+}
+
+// submit enfileira a tarefa sem bloquear. Retorna false, contabilizando a recusa, se a fila estiver cheia.
+func (p *workerPool) submit(task func(context.Context)) bool {
+	select {
+	case p.tasks <- task:
+		return true
+	default:
+		p.refuse()
+		return false
+	}
+}
+
+// full indica se a fila está cheia (a próxima submit seria recusada, salvo se um worker a esvaziar antes).
+// Quem recusa uma tarefa por isso deve chamar refuse, para que a recusa apareça nas métricas.
+func (p *workerPool) full() bool {
+	return len(p.tasks) >= cap(p.tasks)
+}
+
+// refuse contabiliza uma tarefa recusada por fila cheia, avisando no log na primeira e a cada 100.
+func (p *workerPool) refuse() {
+	p.mu.Lock()
+	p.rejected++
+	rejected := p.rejected
+	p.mu.Unlock()
+	if rejected == 1 || rejected%100 == 0 {
+		log.Printf("Workers '%s': fila cheia (%d), %d tarefa(s) recusada(s) até agora\n", p.name, cap(p.tasks), rejected)
+	}
+}
+
+// metrics retorna as métricas atuais.
+func (p *workerPool) metrics() PoolMetrics {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PoolMetrics{
+		Name:          p.name,
+		Workers:       p.workers,
+		Active:        p.active,
+		QueueDepth:    len(p.tasks),
+		QueueCapacity: cap(p.tasks),
+		Completed:     p.completed,
+		Rejected:      p.rejected,
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !linux && !darwin
+
+package storage
+
+import (
+	"fmt"
+	"math"
+)
+
+// DiskUsage não é suportado nesta plataforma; o volume é tratado como tendo espaço ilimitado.
+func DiskUsage(path string) (free uint64, total uint64, err error) {
+	if path == "" {
+		return 0, 0, fmt.Errorf("caminho vazio")
+	}
+	return math.MaxUint64, math.MaxUint64, nil
+}
+
+// filesystemID não é suportado nesta plataforma; cada volume é tratado como um disco separado.
+func filesystemID(path string) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,30 @@
+//go:build linux || darwin
+
+package storage
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// DiskUsage retorna o espaço livre (disponível para usuários não privilegiados) e o total, em bytes,
+// do sistema de arquivos que contém path.
+func DiskUsage(path string) (free uint64, total uint64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, fmt.Errorf("não foi possível consultar o espaço em disco de '%s': %w", path, err)
+	}
+
+	blockSize := uint64(stat.Bsize)
+	return stat.Bavail * blockSize, stat.Blocks * blockSize, nil
+}
+
+// filesystemID identifica o sistema de arquivos (dispositivo) que contém path, para somar uma única vez o
+// espaço livre de volumes no mesmo disco.
+func filesystemID(path string) (uint64, bool) {
+	var stat syscall.Stat_t
+	if err := syscall.Stat(path, &stat); err != nil {
+		return 0, false
+	}
+	return uint64(stat.Dev), true
+}
@@ -1,62 +1,191 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"os"
 	"path/filepath"
+	"photo-manager/internal/events"
+	"sync"
 	"time"
 )
 
+// ErrFileExists indica que já existe um arquivo no caminho de destino. Nada é sobrescrito: o chamador deve
+// escolher outro nome.
+var ErrFileExists = errors.New("já existe um arquivo no caminho de destino")
+
 // FileManager gerencia o armazenamento de arquivos.
+// As fotos podem ser distribuídas entre vários volumes (ex: dois discos) conforme a política de alocação.
 type FileManager struct {
 	BaseStoragePath string
+	PlacementPolicy string // Política de alocação entre volumes (ver constantes Placement*)
+	Layout          string // Organização dos arquivos em cada volume (ver constantes Layout*)
+
+	ReserveBytes         uint64      // Espaço livre mínimo que deve permanecer em cada volume após uma gravação
+	LowSpaceWarningBytes uint64      // Abaixo deste espaço livre é emitido o evento storage.low_space (0 desativa)
+	Events               *events.Bus // Barramento para eventos de armazenamento (opcional)
+
+	SyncDir bool // Sincroniza também a pasta após gravar cada arquivo (mais lento, mais durável)
+
+	mu       sync.RWMutex
+	volumes  []Volume        // Volumes registrados, ordenados por prioridade
+	lowSpace map[string]bool // Volumes atualmente abaixo do limite de aviso, evita avisos repetidos
 }
 
 // NewFileManager cria uma nova instância de FileManager.
+// O caminho base é registrado como o volume padrão até que SetVolumes seja chamado.
 func NewFileManager(basePath string) *FileManager {
 	return &FileManager{
 		BaseStoragePath: basePath,
+		PlacementPolicy: PlacementFillFirst,
+		Layout:          LayoutDate,
+		volumes:         []Volume{{Name: DefaultVolumeName, Path: basePath}},
+		lowSpace:        make(map[string]bool),
 	}
 }
 
 // SavePhoto salva um arquivo de foto no sistema de arquivos, organizando-o por ano e mês.
-// Retorna o caminho completo onde a foto foi salva.
-func (fm *FileManager) SavePhoto(file *multipart.FileHeader, photoDate time.Time) (string, error) {
-	// Formato o caminho baseado na data da foto
-	year := photoDate.Format("2006") // Ano completo (YYYY)
-	month := photoDate.Format("01")  // Mês com dois dígitos (MM)
+// Retorna o caminho completo onde a foto foi salva e o nome do volume escolhido.
+func (fm *FileManager) SavePhoto(file *multipart.FileHeader, photoDate time.Time) (string, string, error) {
+	// Abre o arquivo enviado
+	src, err := file.Open()
+	if err != nil {
+		return "", "", fmt.Errorf("não foi possível abrir o arquivo enviado: %w", err)
+	}
+	defer src.Close()
+
+	return fm.SaveFromReader(src, file.Filename, "", file.Size, photoDate)
+}
 
-	// Cria o caminho completo para o diretório de destino
-	targetDir := filepath.Join(fm.BaseStoragePath, year, month)
+// SaveFromReader salva o conteúdo de um io.Reader no volume escolhido pela política de alocação, no caminho
+// definido pelo layout (por data ou pelo hash do conteúdo).
+// Retorna o caminho completo onde a foto foi salva e o nome do volume.
+func (fm *FileManager) SaveFromReader(src io.Reader, filename, hash string, size int64, photoDate time.Time) (string, string, error) {
+	return fm.SaveFromReaderIn(src, "", filename, hash, size, photoDate)
+}
+
+// SaveFromReaderIn é como SaveFromReader, mas grava o arquivo na pasta informada (relativa à raiz do volume)
+// em vez do diretório do layout; o nome do arquivo continua sendo o do layout. Pasta vazia usa o layout.
+func (fm *FileManager) SaveFromReaderIn(src io.Reader, folder, filename, hash string, size int64, photoDate time.Time) (string, string, error) {
+	volume, err := fm.SelectVolume(size, photoDate)
+	if err != nil {
+		return "", "", err
+	}
+	targetPath, err := fm.targetPath(volume, folder, filename, hash, photoDate)
+	if err != nil {
+		return "", "", err
+	}
 
 	// Garante que o diretório exista
+	targetDir := filepath.Dir(targetPath)
 	if err := os.MkdirAll(targetDir, 0755); err != nil {
-		return "", fmt.Errorf("não foi possível criar o diretório de destino '%s': %w", targetDir, err)
+		return "", "", fmt.Errorf("não foi possível criar o diretório de destino '%s': %w", targetDir, err)
 	}
 
-	// Cria o caminho completo para o arquivo de destino
-	filePath := filepath.Join(targetDir, file.Filename)
+	filePath, err := fm.writeFile(src, targetPath)
+	if err != nil {
+		return "", "", err
+	}
 
-	// Abre o arquivo enviado
-	src, err := file.Open()
+	fm.checkLowSpace(volume)
+	return filePath, volume.Name, nil
+}
+
+// PlanPath retorna o caminho completo e o volume onde SaveFromReaderIn gravaria o arquivo, sem gravar nada.
+func (fm *FileManager) PlanPath(folder, filename, hash string, size int64, photoDate time.Time) (string, string, error) {
+	volume, err := fm.SelectVolume(size, photoDate)
 	if err != nil {
-		return "", fmt.Errorf("não foi possível abrir o arquivo enviado: %w", err)
+		return "", "", err
 	}
-	defer src.Close()
+	targetPath, err := fm.targetPath(volume, folder, filename, hash, photoDate)
+	if err != nil {
+		return "", "", err
+	}
+	return targetPath, volume.Name, nil
+}
 
-	// Cria o arquivo de destino
-	dst, err := os.Create(filePath)
+// targetPath monta o caminho do arquivo no volume: na pasta informada ou no diretório do layout.
+func (fm *FileManager) targetPath(volume Volume, folder, filename, hash string, photoDate time.Time) (string, error) {
+	relPath := LayoutPath(fm.Layout, filename, hash, photoDate)
+	if folder != "" {
+		if !filepath.IsLocal(folder) {
+			return "", fmt.Errorf("pasta de destino inválida '%s': deve ser relativa ao volume", folder)
+		}
+		relPath = filepath.Join(folder, filepath.Base(relPath))
+	}
+	return filepath.Join(volume.Path, relPath), nil
+}
+
+// writeFile copia o conteúdo de src para o caminho de destino. O conteúdo é gravado num arquivo temporário
+// oculto na mesma pasta, sincronizado com o disco e só então publicado com o nome final, então uma queda no
+// meio da cópia nunca deixa um arquivo corrompido com o nome da foto (apenas o temporário, ignorado pela
+// verificação de divergências). Um arquivo existente no destino nunca é substituído: o resultado é
+// ErrFileExists. Com SyncDir, a pasta também é sincronizada para que o novo nome sobreviva a uma queda de
+// energia.
+func (fm *FileManager) writeFile(src io.Reader, filePath string) (string, error) {
+	dir := filepath.Dir(filePath)
+	tmp, err := os.CreateTemp(dir, ".upload-*")
 	if err != nil {
 		return "", fmt.Errorf("não foi possível criar o arquivo de destino '%s': %w", filePath, err)
 	}
-	defer dst.Close()
+	defer os.Remove(tmp.Name()) // Remove o arquivo parcial (ex: upload cancelado pelo cliente) ou, após a publicação, o nome temporário
 
-	// Copia o conteúdo do arquivo enviado para o arquivo de destino
-	if _, err := io.Copy(dst, src); err != nil {
+	// Copia o conteúdo do arquivo enviado para o arquivo temporário
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
 		return "", fmt.Errorf("não foi possível copiar o arquivo para '%s': %w", filePath, err)
 	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("não foi possível gravar o arquivo '%s' no disco: %w", filePath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("não foi possível gravar o arquivo '%s' no disco: %w", filePath, err)
+	}
+	// O temporário é criado com 0600; as fotos mantêm as permissões de antes
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return "", fmt.Errorf("não foi possível ajustar as permissões de '%s': %w", filePath, err)
+	}
+	if err := publishFile(tmp.Name(), filePath); err != nil {
+		if errors.Is(err, ErrFileExists) {
+			return "", fmt.Errorf("%w: '%s'", ErrFileExists, filePath)
+		}
+		return "", fmt.Errorf("não foi possível mover o arquivo para '%s': %w", filePath, err)
+	}
 
+	if fm.SyncDir {
+		if err := syncDir(dir); err != nil {
+			return "", fmt.Errorf("não foi possível sincronizar o diretório '%s': %w", dir, err)
+		}
+	}
 	return filePath, nil
 }
+
+// publishFile dá ao temporário o nome final sem substituir um arquivo existente. O hard link falha
+// atomicamente se o destino existir; em sistemas de arquivos sem hard links (ex: alguns compartilhamentos de
+// rede), o destino é verificado antes do rename.
+func publishFile(tmpPath, filePath string) error {
+	err := os.Link(tmpPath, filePath)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, os.ErrExist):
+		return ErrFileExists
+	}
+	if _, statErr := os.Lstat(filePath); statErr == nil {
+		return ErrFileExists
+	}
+	return os.Rename(tmpPath, filePath)
+}
+
+// syncDir sincroniza a entrada do diretório com o disco, tornando durável a criação ou o rename de um arquivo.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
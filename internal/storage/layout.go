@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Layouts de organização dos arquivos dentro de cada volume.
+const (
+	LayoutDate = "date" // ano/mês/nome-original (padrão)
+	LayoutHash = "hash" // hash[0:2]/hash[2:4]/hash.ext (endereçado por conteúdo); as datas ficam apenas no banco
+)
+
+// ValidLayout indica se o layout é suportado.
+func ValidLayout(layout string) bool {
+	return layout == LayoutDate || layout == LayoutHash
+}
+
+// LayoutPath retorna o caminho de um arquivo, relativo à raiz do volume, segundo o layout.
+// No layout por hash o nome é derivado do conteúdo, o que elimina colisões entre arquivos com o mesmo
+// nome; sem hash (ex: gravações legadas) o layout por data é usado.
+func LayoutPath(layout, filename, hash string, photoDate time.Time) string {
+	if layout == LayoutHash && len(hash) >= 4 {
+		return filepath.Join(hash[0:2], hash[2:4], hash+strings.ToLower(filepath.Ext(filename)))
+	}
+	// Ano completo (YYYY) e mês com dois dígitos (MM)
+	return filepath.Join(photoDate.Format("2006"), photoDate.Format("01"), filename)
+}
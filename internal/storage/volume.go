@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"photo-manager/internal/events"
+	"photo-manager/internal/i18n"
+	"sort"
+	"time"
+)
+
+// DefaultVolumeName é o nome do volume criado a partir de PHOTO_STORAGE_PATH.
+const DefaultVolumeName = "default"
+
+// Políticas de alocação de novas fotos entre volumes.
+const (
+	PlacementFillFirst = "fill-first" // Usa o volume de maior prioridade até que fique sem espaço
+	PlacementDateRange = "date-range" // Usa o volume cujo intervalo de datas contém a data da foto
+)
+
+// ErrNoVolumeAvailable indica que nenhum volume adequado para a nova foto está online.
+var ErrNoVolumeAvailable = i18n.NewError(i18n.CodeNoVolumeAvailable)
+
+// ErrInsufficientStorage indica que há volumes online, mas nenhum com espaço livre suficiente
+// (considerando a reserva configurada) para a nova foto.
+var ErrInsufficientStorage = i18n.NewError(i18n.CodeInsufficientStorage)
+
+// Volume representa uma raiz de armazenamento (ex: um disco montado).
+type Volume struct {
+	Name     string
+	Path     string
+	Priority int        // Menor valor = maior prioridade
+	DateFrom *time.Time // Início do intervalo de datas aceito (política date-range), opcional
+	DateTo   *time.Time // Fim do intervalo de datas aceito (política date-range), opcional
+}
+
+// VolumeStatus contém o estado atual de um volume, incluindo o espaço livre.
+type VolumeStatus struct {
+	Name       string
+	Path       string
+	Online     bool   // false se o caminho do volume não está acessível (ex: disco desmontado)
+	FreeBytes  uint64 // Espaço disponível para escrita
+	TotalBytes uint64 // Capacidade total do sistema de arquivos
+}
+
+// ValidPlacementPolicy indica se a política de alocação é suportada.
+func ValidPlacementPolicy(policy string) bool {
+	return policy == PlacementFillFirst || policy == PlacementDateRange
+}
+
+// SetVolumes substitui os volumes registrados.
+func (fm *FileManager) SetVolumes(volumes []Volume) {
+	sorted := append([]Volume(nil), volumes...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Priority < sorted[j].Priority })
+
+	fm.mu.Lock()
+	fm.volumes = sorted
+	fm.mu.Unlock()
+}
+
+// Volumes retorna uma cópia dos volumes registrados, em ordem de prioridade.
+func (fm *FileManager) Volumes() []Volume {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	return append([]Volume(nil), fm.volumes...)
+}
+
+// SelectVolume escolhe o volume onde uma nova foto de tamanho size deve ser gravada,
+// ignorando volumes offline ou sem espaço (considerando a reserva configurada).
+func (fm *FileManager) SelectVolume(size int64, photoDate time.Time) (Volume, error) {
+	volumes := fm.Volumes()
+
+	candidates := volumes
+	if fm.PlacementPolicy == PlacementDateRange {
+		// Primeiro os volumes cujo intervalo contém a data; depois, os volumes sem intervalo definido
+		candidates = nil
+		for _, v := range volumes {
+			if v.hasDateRange() && v.containsDate(photoDate) {
+				candidates = append(candidates, v)
+			}
+		}
+		for _, v := range volumes {
+			if !v.hasDateRange() {
+				candidates = append(candidates, v)
+			}
+		}
+	}
+
+	return fm.firstWithRoom(candidates, size)
+}
+
+// CheckCapacity verifica, antes de qualquer gravação, se algum volume online comporta size bytes
+// mantendo a reserva configurada. Não considera a data da foto (ainda desconhecida antes da leitura do EXIF).
+func (fm *FileManager) CheckCapacity(size int64) error {
+	_, err := fm.firstWithRoom(fm.Volumes(), size)
+	return err
+}
+
+// CheckBatchCapacity verifica, antes de qualquer gravação, se os volumes online comportam juntos um lote de
+// arquivos (ex: um upload com vários arquivos ou um ZIP), mantendo a reserva configurada em cada volume. Os
+// arquivos são distribuídos como na gravação: cada um no primeiro volume, em ordem de prioridade, com espaço
+// para ele, descontando o que os anteriores ocuparam. Volumes no mesmo sistema de arquivos dividem o espaço
+// livre. Como CheckCapacity, não considera a data das fotos.
+func (fm *FileManager) CheckBatchCapacity(sizes []int64) error {
+	type disk struct{ free uint64 }
+	var volumes []*disk
+	shared := make(map[uint64]*disk) // Por sistema de arquivos
+	for _, v := range fm.Volumes() {
+		status := fm.volumeStatus(v)
+		if !status.Online {
+			continue
+		}
+		id, ok := filesystemID(v.Path)
+		d := shared[id]
+		if !ok || d == nil {
+			d = &disk{free: status.FreeBytes}
+			if ok {
+				shared[id] = d
+			}
+		}
+		volumes = append(volumes, d)
+	}
+	if len(volumes) == 0 {
+		return ErrNoVolumeAvailable
+	}
+
+	var total int64
+	for _, size := range sizes {
+		size = max(0, size)
+		total += size
+		placed := false
+		for _, d := range volumes {
+			if d.free >= uint64(size)+fm.ReserveBytes {
+				d.free -= uint64(size)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			return fmt.Errorf("%w: os volumes não comportam juntos os %d bytes do lote mantendo a reserva de %d bytes em cada um", ErrInsufficientStorage, total, fm.ReserveBytes)
+		}
+	}
+	return nil
+}
+
+// firstWithRoom retorna o primeiro volume da lista com espaço para size bytes.
+func (fm *FileManager) firstWithRoom(candidates []Volume, size int64) (Volume, error) {
+	anyOnline := false
+	for _, v := range candidates {
+		status := fm.volumeStatus(v)
+		if !status.Online {
+			continue
+		}
+		anyOnline = true
+		if fm.hasRoomFor(status, size) {
+			return v, nil
+		}
+	}
+
+	if anyOnline {
+		return Volume{}, fmt.Errorf("%w: nenhum volume comporta %d bytes mantendo a reserva de %d bytes", ErrInsufficientStorage, size, fm.ReserveBytes)
+	}
+	return Volume{}, ErrNoVolumeAvailable
+}
+
+// checkLowSpace emite o evento storage.low_space quando o espaço livre do volume cai abaixo do
+// limite de aviso. O evento é emitido apenas na transição, não a cada gravação.
+func (fm *FileManager) checkLowSpace(v Volume) {
+	if fm.LowSpaceWarningBytes == 0 {
+		return
+	}
+
+	status := fm.volumeStatus(v)
+	low := status.Online && status.FreeBytes < fm.LowSpaceWarningBytes
+
+	fm.mu.Lock()
+	wasLow := fm.lowSpace[v.Name]
+	fm.lowSpace[v.Name] = low
+	fm.mu.Unlock()
+
+	if low && !wasLow {
+		log.Printf("Aviso: espaço livre do volume '%s' abaixo do limite: %d bytes livres (limite: %d bytes)\n",
+			v.Name, status.FreeBytes, fm.LowSpaceWarningBytes)
+		fm.Events.Publish(events.TypeStorageLowSpace, map[string]interface{}{
+			"volume":          v.Name,
+			"path":            v.Path,
+			"free_bytes":      status.FreeBytes,
+			"threshold_bytes": fm.LowSpaceWarningBytes,
+		})
+	}
+}
+
+// IsLowSpace indica se o volume está abaixo do limite de aviso de espaço livre.
+func (fm *FileManager) IsLowSpace(status VolumeStatus) bool {
+	return fm.LowSpaceWarningBytes > 0 && status.Online && status.FreeBytes < fm.LowSpaceWarningBytes
+}
+
+// VolumeStatuses retorna o estado (online e espaço livre) de todos os volumes registrados.
+func (fm *FileManager) VolumeStatuses() []VolumeStatus {
+	volumes := fm.Volumes()
+	statuses := make([]VolumeStatus, 0, len(volumes))
+	for _, v := range volumes {
+		statuses = append(statuses, fm.volumeStatus(v))
+	}
+	return statuses
+}
+
+// IsVolumeOnline indica se o volume com o nome informado está acessível.
+// Fotos sem volume registrado pertencem ao volume padrão.
+func (fm *FileManager) IsVolumeOnline(name string) bool {
+	if name == "" {
+		name = DefaultVolumeName
+	}
+	for _, v := range fm.Volumes() {
+		if v.Name == name {
+			return isDirAccessible(v.Path)
+		}
+	}
+	return false
+}
+
+// volumeStatus consulta o estado atual de um volume.
+func (fm *FileManager) volumeStatus(v Volume) VolumeStatus {
+	status := VolumeStatus{Name: v.Name, Path: v.Path}
+	if !isDirAccessible(v.Path) {
+		return status
+	}
+
+	free, total, err := DiskUsage(v.Path)
+	if err != nil {
+		return status
+	}
+	status.Online = true
+	status.FreeBytes = free
+	status.TotalBytes = total
+	return status
+}
+
+// hasRoomFor indica se o volume está online e comporta size bytes mantendo a reserva configurada.
+func (fm *FileManager) hasRoomFor(status VolumeStatus, size int64) bool {
+	if size < 0 {
+		size = 0
+	}
+	return status.Online && status.FreeBytes >= uint64(size)+fm.ReserveBytes
+}
+
+// hasDateRange indica se o volume restringe as datas aceitas.
+func (v Volume) hasDateRange() bool {
+	return v.DateFrom != nil || v.DateTo != nil
+}
+
+// containsDate indica se a data está dentro do intervalo do volume (limites inclusivos).
+func (v Volume) containsDate(t time.Time) bool {
+	if v.DateFrom != nil && t.Before(*v.DateFrom) {
+		return false
+	}
+	if v.DateTo != nil && t.After(*v.DateTo) {
+		return false
+	}
+	return true
+}
+
+// isDirAccessible indica se o caminho existe e é um diretório.
+func isDirAccessible(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,160 @@
+package thumbnail
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/bits"
+)
+
+// Parâmetros da comparação visual.
+const (
+	compareWidth    = 128  // Largura da grade em que as imagens são comparadas
+	compareMaxShift = 6    // Maior deslocamento testado no alinhamento, em pixels da grade
+	maxPSNR         = 99.0 // PSNR informado para imagens idênticas (o valor real é infinito)
+)
+
+// Comparison é o resultado da comparação visual de duas imagens.
+type Comparison struct {
+	Similarity         float64     // 0 a 1: 1 menos o erro médio absoluto por canal, no melhor alinhamento
+	PSNR               float64     // Relação sinal-ruído de pico, em dB, no melhor alinhamento (até 99)
+	OffsetX, OffsetY   int         // Deslocamento de B que melhor o alinha a A, em pixels de A
+	PerceptualDistance int         // Bits diferentes entre os dHash das imagens (0 a 64)
+	AspectMismatch     bool        // As proporções diferem mais de 2%: a comparação distorce uma das imagens
+	Diff               *image.RGBA // Mapa das diferenças: A em tons de cinza, com as diferenças em vermelho
+}
+
+// grid é uma imagem reduzida a valores RGB em ponto flutuante.
+type grid struct {
+	w, h int
+	px   []float64 // R, G, B por pixel, linha a linha
+}
+
+func (g grid) at(x, y int) []float64 {
+	i := (y*g.w + x) * 3
+	return g.px[i : i+3]
+}
+
+// Compare compara duas imagens (em geral as miniaturas de duas fotos). Ambas são reduzidas à mesma grade,
+// com as proporções de A, e B é deslocado alguns pixels em cada direção para compensar pequenos cortes ou
+// desalinhamentos; o resultado é o do deslocamento com o menor erro.
+func Compare(a, b image.Image) Comparison {
+	ab, bb := a.Bounds(), b.Bounds()
+	w := compareWidth
+	h := max(1, min(4*compareWidth, int(math.Round(float64(w)*float64(ab.Dy())/float64(max(1, ab.Dx()))))))
+	ga, gb := sampleGrid(a, w, h), sampleGrid(b, w, h)
+
+	aspectA := float64(ab.Dx()) / float64(max(1, ab.Dy()))
+	aspectB := float64(bb.Dx()) / float64(max(1, bb.Dy()))
+
+	bestMAE, bestMSE, bestDX, bestDY := math.MaxFloat64, 0.0, 0, 0
+	minOverlap := (w * h) * 3 / 4
+	for dy := -compareMaxShift; dy <= compareMaxShift; dy++ {
+		for dx := -compareMaxShift; dx <= compareMaxShift; dx++ {
+			if (w-abs(dx))*(h-abs(dy)) < minOverlap {
+				continue
+			}
+			mae, mse := gridError(ga, gb, dx, dy)
+			// Em empates, prefere o menor deslocamento
+			if mae < bestMAE-1e-9 || (math.Abs(mae-bestMAE) <= 1e-9 && abs(dx)+abs(dy) < abs(bestDX)+abs(bestDY)) {
+				bestMAE, bestMSE, bestDX, bestDY = mae, mse, dx, dy
+			}
+		}
+	}
+	if bestMAE == math.MaxFloat64 { // Grade pequena demais para deslocar
+		bestMAE, bestMSE = gridError(ga, gb, 0, 0)
+	}
+
+	psnr := maxPSNR
+	if bestMSE > 0 {
+		psnr = math.Min(maxPSNR, 10*math.Log10(255*255/bestMSE))
+	}
+	scale := float64(ab.Dx()) / float64(w)
+	return Comparison{
+		Similarity:         1 - bestMAE/255,
+		PSNR:               psnr,
+		OffsetX:            int(math.Round(float64(bestDX) * scale)),
+		OffsetY:            int(math.Round(float64(bestDY) * scale)),
+		PerceptualDistance: bits.OnesCount64(differenceHash(a) ^ differenceHash(b)),
+		AspectMismatch:     math.Abs(aspectA-aspectB)/aspectA > 0.02,
+		Diff:               diffImage(ga, gb, bestDX, bestDY),
+	}
+}
+
+// sampleGrid reduz a imagem a w x h pixels pela média de cada área (box filter).
+func sampleGrid(img image.Image, w, h int) grid {
+	bounds := img.Bounds()
+	g := grid{w: w, h: h, px: make([]float64, w*h*3)}
+	for gy := 0; gy < h; gy++ {
+		y0 := bounds.Min.Y + gy*bounds.Dy()/h
+		y1 := max(y0+1, bounds.Min.Y+(gy+1)*bounds.Dy()/h)
+		for gx := 0; gx < w; gx++ {
+			x0 := bounds.Min.X + gx*bounds.Dx()/w
+			x1 := max(x0+1, bounds.Min.X+(gx+1)*bounds.Dx()/w)
+			var r, gr, b float64
+			n := 0
+			for y := y0; y < y1 && y < bounds.Max.Y; y++ {
+				for x := x0; x < x1 && x < bounds.Max.X; x++ {
+					c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+					r, gr, b = r+float64(c.R), gr+float64(c.G), b+float64(c.B)
+					n++
+				}
+			}
+			if n > 0 {
+				px := g.at(gx, gy)
+				px[0], px[1], px[2] = r/float64(n), gr/float64(n), b/float64(n)
+			}
+		}
+	}
+	return g
+}
+
+// gridError calcula o erro médio absoluto e o erro quadrático médio por canal entre A e B deslocado de
+// (dx, dy), na área em que as grades se sobrepõem.
+func gridError(a, b grid, dx, dy int) (mae, mse float64) {
+	var sumAbs, sumSq float64
+	n := 0
+	for y := max(0, -dy); y < min(a.h, a.h-dy); y++ {
+		for x := max(0, -dx); x < min(a.w, a.w-dx); x++ {
+			pa, pb := a.at(x, y), b.at(x+dx, y+dy)
+			for c := 0; c < 3; c++ {
+				d := pa[c] - pb[c]
+				sumAbs += math.Abs(d)
+				sumSq += d * d
+			}
+			n += 3
+		}
+	}
+	if n == 0 {
+		return 255, 255 * 255
+	}
+	return sumAbs / float64(n), sumSq / float64(n)
+}
+
+// diffImage desenha o mapa de diferenças no alinhamento informado. Pixels fora da sobreposição ficam azuis.
+func diffImage(a, b grid, dx, dy int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, a.w, a.h))
+	for y := 0; y < a.h; y++ {
+		for x := 0; x < a.w; x++ {
+			pa := a.at(x, y)
+			gray := (0.299*pa[0] + 0.587*pa[1] + 0.114*pa[2]) * 0.5 // A esmaecida, como referência
+			bx, by := x+dx, y+dy
+			if bx < 0 || by < 0 || bx >= b.w || by >= b.h {
+				img.Set(x, y, color.RGBA{uint8(gray), uint8(gray), 255, 255})
+				continue
+			}
+			pb := b.at(bx, by)
+			diff := (math.Abs(pa[0]-pb[0]) + math.Abs(pa[1]-pb[1]) + math.Abs(pa[2]-pb[2])) / 3
+			red := math.Min(255, gray+diff*4) // Realça diferenças pequenas
+			img.Set(x, y, color.RGBA{uint8(red), uint8(gray), uint8(gray), 255})
+		}
+	}
+	return img
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
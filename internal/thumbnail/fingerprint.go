@@ -0,0 +1,77 @@
+package thumbnail
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// Fingerprint identifica o conteúdo visual de uma imagem, independentemente da codificação do arquivo.
+type Fingerprint struct {
+	PixelHash      string // SHA-256 dos pixels decodificados: igual apenas para imagens idênticas pixel a pixel
+	PerceptualHash string // dHash de 64 bits em hexadecimal: próximo para imagens visualmente parecidas
+}
+
+// ComputeFingerprint calcula as impressões digitais da imagem.
+func ComputeFingerprint(img image.Image) Fingerprint {
+	bounds := img.Bounds()
+	digest := sha256.New()
+	fmt.Fprintf(digest, "%dx%d;", bounds.Dx(), bounds.Dy())
+	row := make([]byte, 0, bounds.Dx()*4)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row = row[:0]
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			row = append(row, c.R, c.G, c.B, c.A)
+		}
+		digest.Write(row)
+	}
+
+	return Fingerprint{
+		PixelHash:      hex.EncodeToString(digest.Sum(nil)),
+		PerceptualHash: fmt.Sprintf("%016x", differenceHash(img)),
+	}
+}
+
+// differenceHash calcula o dHash: a imagem é reduzida a 9x8 tons de cinza e cada bit indica se um pixel
+// é mais claro que o vizinho à direita.
+func differenceHash(img image.Image) uint64 {
+	const w, h = 9, 8
+	bounds := img.Bounds()
+	var gray [h][w]float64
+	for gy := 0; gy < h; gy++ {
+		y0 := bounds.Min.Y + gy*bounds.Dy()/h
+		y1 := max(y0+1, bounds.Min.Y+(gy+1)*bounds.Dy()/h)
+		for gx := 0; gx < w; gx++ {
+			x0 := bounds.Min.X + gx*bounds.Dx()/w
+			x1 := max(x0+1, bounds.Min.X+(gx+1)*bounds.Dx()/w)
+
+			// Média por área, com amostragem limitada para não percorrer todos os pixels de imagens grandes
+			stepX, stepY := max(1, (x1-x0)/16), max(1, (y1-y0)/16)
+			var sum float64
+			n := 0
+			for y := y0; y < y1 && y < bounds.Max.Y; y += stepY {
+				for x := x0; x < x1 && x < bounds.Max.X; x += stepX {
+					sum += float64(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+					n++
+				}
+			}
+			if n > 0 {
+				gray[gy][gx] = sum / float64(n)
+			}
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < h; y++ {
+		for x := 0; x < w-1; x++ {
+			hash <<= 1
+			if gray[y][x] > gray[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
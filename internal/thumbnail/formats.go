@@ -0,0 +1,186 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ErrUnsupportedFormat indica um formato que os decodificadores em Go puro não leem (HEIC, TIFF, RAW).
+// Diferente de ErrInvalidImage, o arquivo não está corrompido: basta um conversor externo
+// (THUMBNAIL_RESIZER=vips).
+var ErrUnsupportedFormat = errors.New("formato de imagem não suportado sem um conversor externo (THUMBNAIL_RESIZER=vips)")
+
+// browserTypes são os formatos de imagem exibidos diretamente pelos navegadores atuais.
+var browserTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+	"image/avif": true,
+}
+
+// BrowserCompatible indica se a imagem pode ser servida como está aos navegadores. HEIC, TIFF e RAW
+// precisam de uma versão convertida (ver Generator.Rendition).
+func BrowserCompatible(mimeType string) bool {
+	return browserTypes[mimeType]
+}
+
+// DetectContentType identifica o tipo MIME pelos primeiros bytes, como http.DetectContentType, reconhecendo
+// também os formatos de câmera que ele não conhece: HEIC/HEIF, AVIF, TIFF e os RAW da Canon (CR2 e CR3).
+// Os demais RAW baseados em TIFF (DNG, NEF, ARW...) são identificados como image/tiff.
+func DetectContentType(data []byte) string {
+	if len(data) >= 12 && string(data[4:8]) == "ftyp" {
+		switch brand := string(data[8:12]); brand {
+		case "heic", "heix", "heim", "heis", "hevc", "hevx":
+			return "image/heic"
+		case "mif1", "msf1", "heif":
+			return "image/heif"
+		case "avif", "avis":
+			return "image/avif"
+		case "crx ":
+			return "image/x-canon-cr3"
+		}
+	}
+	if len(data) >= 4 && (bytes.HasPrefix(data, []byte("II*\x00")) || bytes.HasPrefix(data, []byte("MM\x00*"))) {
+		if len(data) >= 10 && string(data[8:10]) == "CR" {
+			return "image/x-canon-cr2"
+		}
+		return "image/tiff"
+	}
+	return http.DetectContentType(data)
+}
+
+// containerDimensions lê largura e altura de formatos que a biblioteca padrão não decodifica: o maior
+// quadro de um TIFF (incluindo as sub-imagens dos RAW) ou a maior propriedade "ispe" de um HEIF.
+func containerDimensions(file *os.File) (int, int, error) {
+	header := make([]byte, 12)
+	if _, err := file.ReadAt(header, 0); err != nil {
+		return 0, 0, fmt.Errorf("não foi possível ler o cabeçalho: %w", err)
+	}
+	switch {
+	case string(header[4:8]) == "ftyp":
+		return heifDimensions(file)
+	case bytes.HasPrefix(header, []byte("II*\x00")) || bytes.HasPrefix(header, []byte("MM\x00*")):
+		return tiffDimensions(file, header)
+	}
+	return 0, 0, ErrUnsupportedFormat
+}
+
+// heifScanSize é quanto do início de um HEIF é lido à procura das dimensões (a caixa "meta" fica no começo).
+const heifScanSize = 256 << 10
+
+// heifDimensions procura as propriedades "ispe" (largura e altura de cada imagem) e retorna a maior: a
+// imagem principal, e não as miniaturas ou os blocos da grade.
+func heifDimensions(file *os.File) (int, int, error) {
+	data := make([]byte, heifScanSize)
+	n, err := file.ReadAt(data, 0)
+	if err != nil && err != io.EOF {
+		return 0, 0, fmt.Errorf("não foi possível ler o arquivo: %w", err)
+	}
+	data = data[:n]
+
+	width, height := 0, 0
+	for i := 0; ; {
+		j := bytes.Index(data[i:], []byte("ispe"))
+		if j < 0 {
+			break
+		}
+		pos := i + j + 4 + 4 // Tipo da caixa, versão e flags
+		if pos+8 > len(data) {
+			break
+		}
+		w, h := int(binary.BigEndian.Uint32(data[pos:])), int(binary.BigEndian.Uint32(data[pos+4:]))
+		if w*h > width*height {
+			width, height = w, h
+		}
+		i = pos
+	}
+	if width == 0 || height == 0 {
+		return 0, 0, fmt.Errorf("dimensões não encontradas no HEIF")
+	}
+	return width, height, nil
+}
+
+// Tags do TIFF usadas por tiffDimensions.
+const (
+	tiffTagImageWidth  = 0x100
+	tiffTagImageLength = 0x101
+	tiffTagSubIFDs     = 0x14a
+)
+
+// maxTIFFDirs limita os diretórios (IFDs) visitados, contra arquivos com encadeamento circular.
+const maxTIFFDirs = 32
+
+// tiffDimensions percorre os diretórios do TIFF (e os SubIFDs, onde os RAW guardam a imagem do sensor)
+// e retorna o maior quadro.
+func tiffDimensions(file *os.File, header []byte) (int, int, error) {
+	var order binary.ByteOrder = binary.LittleEndian
+	if header[0] == 'M' {
+		order = binary.BigEndian
+	}
+
+	width, height := 0, 0
+	pending := []int64{int64(order.Uint32(header[4:8]))}
+	for visited := 0; len(pending) > 0 && visited < maxTIFFDirs; visited++ {
+		offset := pending[0]
+		pending = pending[1:]
+
+		count := make([]byte, 2)
+		if _, err := file.ReadAt(count, offset); err != nil {
+			break
+		}
+		entries := make([]byte, int(order.Uint16(count))*12+4)
+		if _, err := file.ReadAt(entries, offset+2); err != nil {
+			break
+		}
+
+		w, h := 0, 0
+		for e := 0; e+12 <= len(entries)-4; e += 12 {
+			entry := entries[e : e+12]
+			tag, kind := order.Uint16(entry[0:2]), order.Uint16(entry[2:4])
+			value := int64(order.Uint32(entry[8:12]))
+			if kind == 3 { // SHORT, alinhado à esquerda no campo de valor
+				value = int64(order.Uint16(entry[8:10]))
+			}
+			switch tag {
+			case tiffTagImageWidth:
+				w = int(value)
+			case tiffTagImageLength:
+				h = int(value)
+			case tiffTagSubIFDs:
+				if order.Uint32(entry[4:8]) == 1 {
+					pending = append(pending, value)
+				}
+			}
+		}
+		if w*h > width*height {
+			width, height = w, h
+		}
+		if next := int64(order.Uint32(entries[len(entries)-4:])); next != 0 {
+			pending = append(pending, next)
+		}
+	}
+	if width == 0 || height == 0 {
+		return 0, 0, fmt.Errorf("dimensões não encontradas no TIFF")
+	}
+	return width, height, nil
+}
+
+// sniffFile lê os primeiros bytes do arquivo e identifica o tipo com DetectContentType.
+func sniffFile(file *os.File) string {
+	buf := make([]byte, 512)
+	n, _ := file.ReadAt(buf, 0)
+	return DetectContentType(buf[:n])
+}
+
+// isContainerType indica os tipos lidos por containerDimensions.
+func isContainerType(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "image/hei") || mimeType == "image/avif" || mimeType == "image/tiff" ||
+		strings.HasPrefix(mimeType, "image/x-canon-")
+}
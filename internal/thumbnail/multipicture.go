@@ -0,0 +1,319 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// Marcadores JPEG usados na leitura dos segmentos.
+const (
+	markerSOI  = 0xD8
+	markerSOS  = 0xDA
+	markerAPP2 = 0xE2
+)
+
+// mpfSignature inicia o segmento APP2 do Multi-Picture Format (CIPA DC-007), que lista as imagens
+// secundárias gravadas depois da principal: mapas de ganho HDR, mapas de profundidade, máscaras de retrato.
+var mpfSignature = []byte("MPF\x00")
+
+// errNotMultiPicture indica um arquivo que não é um JPEG com imagens secundárias no MPF.
+var errNotMultiPicture = errors.New("o arquivo não é um JPEG com imagens secundárias (MPF)")
+
+// jpegSegment é um segmento de aplicação (APPn) do cabeçalho de um JPEG, com marcador e tamanho.
+type jpegSegment struct {
+	marker byte
+	offset int    // Posição do segmento no arquivo
+	data   []byte // Segmento completo, a partir do 0xFF
+}
+
+// multiPicture é um JPEG com imagens secundárias: a principal, cujo segmento MPF lista as demais, e as
+// secundárias, cada uma um JPEG completo.
+type multiPicture struct {
+	segments   []jpegSegment // Segmentos APPn da principal, em ordem
+	mpfIndex   int           // Posição do segmento MPF em segments
+	byteOrder  binary.ByteOrder
+	entryAt    int // Posição da tabela MPEntry no conteúdo do segmento MPF (a partir do cabeçalho TIFF)
+	primary    []byte
+	secondary  [][]byte
+	mpfPayload []byte // Conteúdo do segmento MPF a partir do cabeçalho TIFF
+}
+
+// readJPEGSegments retorna os segmentos APPn antes dos dados da imagem.
+func readJPEGSegments(data []byte) ([]jpegSegment, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != markerSOI {
+		return nil, errNotMultiPicture
+	}
+	var segments []jpegSegment
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			return nil, fmt.Errorf("%w: marcador inválido na posição %d", ErrInvalidImage, pos)
+		}
+		marker := data[pos+1]
+		if marker == 0xFF { // Bytes de preenchimento
+			pos++
+			continue
+		}
+		if marker == markerSOS {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, fmt.Errorf("%w: segmento JPEG truncado", ErrInvalidImage)
+		}
+		if marker >= 0xE0 && marker <= 0xEF {
+			segments = append(segments, jpegSegment{marker: marker, offset: pos, data: data[pos:end]})
+		}
+		pos = end
+	}
+	return segments, nil
+}
+
+// parseMultiPicture lê o índice MPF do JPEG e separa a imagem principal das secundárias.
+func parseMultiPicture(data []byte) (*multiPicture, error) {
+	segments, err := readJPEGSegments(data)
+	if err != nil {
+		return nil, err
+	}
+	mp := &multiPicture{segments: segments, mpfIndex: -1}
+	for i, segment := range segments {
+		if segment.marker == markerAPP2 && bytes.HasPrefix(segment.data[4:], mpfSignature) {
+			mp.mpfIndex = i
+			mp.mpfPayload = segment.data[4+len(mpfSignature):]
+			break
+		}
+	}
+	if mp.mpfIndex < 0 || len(mp.mpfPayload) < 8 {
+		return nil, errNotMultiPicture
+	}
+	payload := mp.mpfPayload
+	switch string(payload[:4]) {
+	case "II*\x00":
+		mp.byteOrder = binary.LittleEndian
+	case "MM\x00*":
+		mp.byteOrder = binary.BigEndian
+	default:
+		return nil, errNotMultiPicture
+	}
+
+	// A posição do cabeçalho TIFF no arquivo é a base dos deslocamentos das imagens secundárias
+	base := segments[mp.mpfIndex].offset + 4 + len(mpfSignature)
+	ifd := int(mp.byteOrder.Uint32(payload[4:]))
+	if ifd+2 > len(payload) {
+		return nil, errNotMultiPicture
+	}
+	count, entries := 0, -1
+	for i := 0; i < int(mp.byteOrder.Uint16(payload[ifd:])); i++ {
+		at := ifd + 2 + i*12
+		if at+12 > len(payload) {
+			return nil, errNotMultiPicture
+		}
+		tag := mp.byteOrder.Uint16(payload[at:])
+		switch tag {
+		case 0xB001: // NumberOfImages
+			count = int(mp.byteOrder.Uint32(payload[at+8:]))
+		case 0xB002: // MPEntry
+			entries = int(mp.byteOrder.Uint32(payload[at+8:]))
+		}
+	}
+	if count < 2 || entries < 0 || entries+16*count > len(payload) {
+		return nil, errNotMultiPicture
+	}
+	mp.entryAt = entries
+
+	primaryEnd := len(data)
+	for i := 1; i < count; i++ {
+		entry := payload[entries+16*i:]
+		size, offset := int(mp.byteOrder.Uint32(entry[4:])), int(mp.byteOrder.Uint32(entry[8:]))
+		start := base + offset
+		if offset == 0 || size <= 0 || start+size > len(data) {
+			return nil, fmt.Errorf("%w: imagem secundária fora do arquivo", ErrInvalidImage)
+		}
+		mp.secondary = append(mp.secondary, data[start:start+size])
+		primaryEnd = min(primaryEnd, start)
+	}
+	mp.primary = data[:primaryEnd]
+	return mp, nil
+}
+
+// resizeKeepingAuxiliary tenta ResizeMultiPicture e retorna false, sem gravar nada, se o arquivo não tiver
+// imagens secundárias. Uma foto com MPF que não possa ser remontada (ex: secundária em formato desconhecido)
+// também retorna false, com um aviso no log: a cópia perde as secundárias, mas é gerada.
+func resizeKeepingAuxiliary(srcPath, dstPath string, maxSize int, maxPixels int64) (width, height int, ok bool) {
+	width, height, err := ResizeMultiPicture(srcPath, dstPath, maxSize, maxPixels)
+	if err != nil {
+		os.Remove(dstPath)
+		if !errors.Is(err, errNotMultiPicture) {
+			log.Printf("Aviso: não foi possível preservar as imagens secundárias de '%s': %v\n", filepath.Base(srcPath), err)
+		}
+		return 0, 0, false
+	}
+	return width, height, true
+}
+
+// itemLengthPattern localiza o tamanho de cada imagem secundária no diretório GContainer do XMP
+// (Ultra HDR, Dynamic Depth), que precisa acompanhar os tamanhos novos.
+var itemLengthPattern = regexp.MustCompile(`Item:Length="\d+"`)
+
+// ResizeMultiPicture grava em dstPath uma cópia reduzida de um JPEG com imagens secundárias, com o maior
+// lado da principal em maxSize, preservando as secundárias (mapas de ganho e de profundidade) reduzidas na
+// mesma escala, os metadados (EXIF, XMP, perfil de cor) e o índice MPF atualizado. Sem isso, a cópia
+// reduzida de uma foto HDR perde o mapa de ganho e passa a ser exibida como SDR. Retorna as dimensões da
+// principal; para arquivos sem imagens secundárias retorna errNotMultiPicture.
+func ResizeMultiPicture(srcPath, dstPath string, maxSize int, maxPixels int64) (width, height int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: pânico ao decodificar a imagem: %v", ErrInvalidImage, r)
+		}
+	}()
+
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("não foi possível abrir a imagem original: %w", err)
+	}
+	mp, err := parseMultiPicture(data)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	primary, err := decodeChecked(mp.primary, maxPixels)
+	if err != nil {
+		return 0, 0, err
+	}
+	resized := Resize(primary, maxSize)
+	scale := float64(resized.Bounds().Dx()) / float64(primary.Bounds().Dx())
+
+	secondary := make([][]byte, len(mp.secondary))
+	for i, data := range mp.secondary {
+		if secondary[i], err = resizeSecondary(data, scale, maxPixels); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	// Os tamanhos das secundárias no XMP da principal seguem a ordem das imagens no arquivo
+	segments := make([][]byte, len(mp.segments))
+	next := 0
+	for i, segment := range mp.segments {
+		segments[i] = segment.data
+		if i == mp.mpfIndex || !bytes.Contains(segment.data, []byte("Item:Length")) {
+			continue
+		}
+		content := itemLengthPattern.ReplaceAllFunc(segment.data[4:], func(match []byte) []byte {
+			if next >= len(secondary) || string(match) == `Item:Length="0"` {
+				return match // A principal pode declarar tamanho 0
+			}
+			next++
+			return []byte(`Item:Length="` + strconv.Itoa(len(secondary[next-1])) + `"`)
+		})
+		if segments[i], err = buildSegment(segment.marker, content); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, resized, &jpeg.Options{Quality: 90}); err != nil {
+		return 0, 0, fmt.Errorf("não foi possível gravar a imagem reduzida: %w", err)
+	}
+
+	// Monta a principal (SOI, segmentos originais, imagem codificada sem o próprio SOI) e calcula os
+	// deslocamentos das secundárias em relação ao cabeçalho TIFF do MPF, que fica na mesma posição
+	var out bytes.Buffer
+	out.Write([]byte{0xFF, markerSOI})
+	mpfBase := 0
+	for i, segment := range segments {
+		if i == mp.mpfIndex {
+			mpfBase = out.Len() + 4 + len(mpfSignature)
+		}
+		out.Write(segment)
+	}
+	out.Write(encoded.Bytes()[2:])
+
+	payload := bytes.Clone(mp.mpfPayload)
+	primaryLen := out.Len()
+	mp.byteOrder.PutUint32(payload[mp.entryAt+4:], uint32(primaryLen))
+	offset := primaryLen
+	for i, image := range secondary {
+		entry := payload[mp.entryAt+16*(i+1):]
+		mp.byteOrder.PutUint32(entry[4:], uint32(len(image)))
+		mp.byteOrder.PutUint32(entry[8:], uint32(offset-mpfBase))
+		offset += len(image)
+	}
+	result := out.Bytes()
+	copy(result[mpfBase:], payload)
+
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("não foi possível criar a imagem reduzida '%s': %w", dstPath, err)
+	}
+	defer dst.Close()
+	for _, chunk := range append([][]byte{result}, secondary...) {
+		if _, err := dst.Write(chunk); err != nil {
+			os.Remove(dstPath)
+			return 0, 0, fmt.Errorf("não foi possível gravar a imagem reduzida: %w", err)
+		}
+	}
+	return resized.Bounds().Dx(), resized.Bounds().Dy(), nil
+}
+
+// resizeSecondary reduz uma imagem secundária na escala da principal, mantendo os seus segmentos APPn
+// (o XMP do mapa de ganho traz os parâmetros de reconstrução do HDR).
+func resizeSecondary(data []byte, scale float64, maxPixels int64) ([]byte, error) {
+	segments, err := readJPEGSegments(data)
+	if err != nil {
+		return nil, err
+	}
+	img, err := decodeChecked(data, maxPixels)
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	maxSize := max(1, int(math.Round(float64(max(bounds.Dx(), bounds.Dy()))*scale)))
+
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, Resize(img, maxSize), &jpeg.Options{Quality: 90}); err != nil {
+		return nil, fmt.Errorf("não foi possível gravar a imagem secundária reduzida: %w", err)
+	}
+	var out bytes.Buffer
+	out.Write([]byte{0xFF, markerSOI})
+	for _, segment := range segments {
+		out.Write(segment.data)
+	}
+	out.Write(encoded.Bytes()[2:])
+	return out.Bytes(), nil
+}
+
+// decodeChecked decodifica um JPEG em memória, recusando os que excedem maxPixels (0 = sem limite).
+func decodeChecked(data []byte, maxPixels int64) (image.Image, error) {
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: não foi possível ler o cabeçalho da imagem: %v", ErrInvalidImage, err)
+	}
+	if pixels := int64(cfg.Width) * int64(cfg.Height); maxPixels > 0 && pixels > maxPixels {
+		return nil, fmt.Errorf("%w: a imagem declara %d pixels, acima do limite de %d", ErrInvalidImage, pixels, maxPixels)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: não foi possível decodificar a imagem: %v", ErrInvalidImage, err)
+	}
+	return img, nil
+}
+
+// buildSegment monta um segmento APPn com o conteúdo informado.
+func buildSegment(marker byte, content []byte) ([]byte, error) {
+	if len(content)+2 > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: segmento JPEG grande demais após a atualização", ErrInvalidImage)
+	}
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(content)+2))
+	return append(segment, content...), nil
+}
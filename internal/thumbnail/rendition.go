@@ -0,0 +1,133 @@
+package thumbnail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Formatos das versões convertidas servidas aos navegadores.
+const (
+	RenditionJPEG = "jpeg"
+	RenditionWebP = "webp"
+)
+
+// renditionExtensions são as extensões de cada formato; o vipsthumbnail escolhe o formato pela extensão.
+var renditionExtensions = map[string]string{
+	RenditionJPEG: ".jpg",
+	RenditionWebP: ".webp",
+}
+
+// RenditionContentType retorna o tipo MIME de um formato de versão convertida.
+func RenditionContentType(format string) string {
+	if format == RenditionWebP {
+		return "image/webp"
+	}
+	return "image/jpeg"
+}
+
+// renditionLocks serializa a conversão de cada arquivo: solicitações simultâneas da mesma versão aguardam
+// a primeira conversão em vez de repeti-la.
+var renditionLocks = struct {
+	sync.Mutex
+	keys map[string]*sync.Mutex
+}{keys: make(map[string]*sync.Mutex)}
+
+// lockRendition bloqueia a geração do arquivo em path e retorna a função que o libera.
+func lockRendition(path string) func() {
+	renditionLocks.Lock()
+	lock, ok := renditionLocks.keys[path]
+	if !ok {
+		lock = &sync.Mutex{}
+		renditionLocks.keys[path] = lock
+	}
+	renditionLocks.Unlock()
+	lock.Lock()
+	return lock.Unlock
+}
+
+// RenditionPathFor retorna o caminho da versão convertida identificada por key (ex: ID e hash da foto).
+func (g *Generator) RenditionPathFor(key, format string) string {
+	return filepath.Join(g.Dir, "renditions", key+renditionExtensions[format])
+}
+
+// Rendition retorna uma versão do original em srcPath que os navegadores exibem (formato RenditionJPEG ou
+// RenditionWebP), convertendo-a na primeira solicitação. key identifica a versão do arquivo, para que um
+// original alterado gere nova conversão. Sem um Resizer externo, retorna ErrUnsupportedFormat. O
+// vipsthumbnail converte só a imagem principal: o mapa de ganho de um HEIC HDR não passa para a versão
+// convertida, que é exibida em SDR (o original continua intacto).
+func (g *Generator) Rendition(ctx context.Context, srcPath, key, format string) (string, error) {
+	converter, ok := g.Resizer.(Converter)
+	if !ok {
+		return "", ErrUnsupportedFormat
+	}
+	if _, ok := renditionExtensions[format]; !ok {
+		format = RenditionJPEG
+	}
+	dstPath := g.RenditionPathFor(key, format)
+	defer lockRendition(dstPath)()
+
+	if _, err := os.Stat(dstPath); err == nil {
+		return dstPath, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return "", fmt.Errorf("não foi possível criar o diretório de versões convertidas: %w", err)
+	}
+
+	// Converte para um arquivo temporário com a mesma extensão, para que uma conversão interrompida
+	// nunca seja servida
+	tmpPath := filepath.Join(filepath.Dir(dstPath), ".tmp-"+filepath.Base(dstPath))
+	if err := converter.Convert(ctx, srcPath, tmpPath); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpPath, dstPath); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("não foi possível gravar a versão convertida: %w", err)
+	}
+	return dstPath, nil
+}
+
+// ResizedPathFor retorna o caminho da cópia reduzida identificada por key, com o maior lado em maxSize.
+func (g *Generator) ResizedPathFor(key string, maxSize int) string {
+	return filepath.Join(g.Dir, "resized", fmt.Sprintf("%s-%d.jpg", key, maxSize))
+}
+
+// Resized retorna uma cópia JPEG do original em srcPath com o maior lado limitado a maxSize, gerada na
+// primeira solicitação. Como em Rendition, key identifica a versão do arquivo. JPEGs com imagens
+// secundárias (mapa de ganho HDR, mapa de profundidade) mantêm as secundárias reduzidas na mesma escala.
+func (g *Generator) Resized(ctx context.Context, srcPath, key string, maxSize int) (string, error) {
+	dstPath := g.ResizedPathFor(key, maxSize)
+	defer lockRendition(dstPath)()
+
+	if _, err := os.Stat(dstPath); err == nil {
+		return dstPath, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return "", fmt.Errorf("não foi possível criar o diretório de cópias reduzidas: %w", err)
+	}
+
+	tmpPath := filepath.Join(filepath.Dir(dstPath), ".tmp-"+filepath.Base(dstPath))
+	if _, _, ok := resizeKeepingAuxiliary(srcPath, tmpPath, maxSize, g.MaxPixels); !ok {
+		if err := g.resize(ctx, srcPath, tmpPath, maxSize); err != nil {
+			os.Remove(tmpPath)
+			return "", err
+		}
+	}
+	if err := os.Rename(tmpPath, dstPath); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("não foi possível gravar a cópia reduzida: %w", err)
+	}
+	return dstPath, nil
+}
+
+// RemoveRenditions apaga as versões convertidas e as cópias reduzidas de uma foto (chaves iniciadas por "<id>-").
+func (g *Generator) RemoveRenditions(photoID uint) {
+	for _, dir := range []string{"renditions", "resized"} {
+		matches, _ := filepath.Glob(filepath.Join(g.Dir, dir, fmt.Sprintf("%d-*", photoID)))
+		for _, path := range matches {
+			os.Remove(path)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package thumbnail
+
+import (
+	"context"
+	"fmt"
+	"image/jpeg"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Implementações de Resizer selecionáveis pela configuração (THUMBNAIL_RESIZER).
+const (
+	ResizerGo   = "go"   // Decodificação em Go puro (padrão, sem dependências)
+	ResizerVips = "vips" // Binário vipsthumbnail da libvips
+)
+
+// ValidResizer indica se o nome da implementação é suportado.
+func ValidResizer(name string) bool {
+	return name == ResizerGo || name == ResizerVips
+}
+
+// vipsTimeout é o tempo máximo do vipsthumbnail por imagem.
+const vipsTimeout = 2 * time.Minute
+
+// Resizer grava em dstPath uma miniatura JPEG da imagem em srcPath, com o maior lado de no máximo maxSize
+// pixels. Permite trocar a decodificação em Go puro por uma ferramenta externa mais rápida e econômica.
+type Resizer interface {
+	Name() string
+	Resize(ctx context.Context, srcPath, dstPath string, maxSize int) error
+}
+
+// Converter converte a imagem inteira, sem reduzir, para um formato exibido pelos navegadores (JPEG ou
+// WebP, pela extensão de dstPath). Implementado pelos Resizers externos, que leem HEIC, TIFF e RAW.
+type Converter interface {
+	Convert(ctx context.Context, srcPath, dstPath string) error
+}
+
+// CropResizer é implementado pelos Resizers que recortam a imagem antes de reduzi-la (ver Crop).
+type CropResizer interface {
+	ResizeCrop(ctx context.Context, srcPath, dstPath string, maxSize int, crop Crop) error
+}
+
+// GoResizer decodifica a imagem inteira em memória com os decodificadores da biblioteca padrão.
+// Não depende de nada externo, mas uma foto de 50 MP ocupa cerca de 200 MB durante a conversão.
+type GoResizer struct {
+	MaxPixels int64 // Máximo de pixels decodificados; 0 = sem limite
+}
+
+// Name retorna o nome da implementação.
+func (GoResizer) Name() string {
+	return ResizerGo
+}
+
+// Resize decodifica a imagem com Decode e grava a miniatura reduzida por Resize.
+func (r GoResizer) Resize(_ context.Context, srcPath, dstPath string, maxSize int) error {
+	img, _, err := Decode(srcPath, r.MaxPixels)
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return fmt.Errorf("não foi possível criar a miniatura '%s': %w", dstPath, err)
+	}
+	defer dst.Close()
+
+	if err := jpeg.Encode(dst, Resize(img, maxSize), &jpeg.Options{Quality: 80}); err != nil {
+		os.Remove(dstPath)
+		return fmt.Errorf("não foi possível gravar a miniatura: %w", err)
+	}
+	return nil
+}
+
+// ResizeCrop decodifica a imagem com Decode, recorta com CropImage e grava a miniatura reduzida por Resize.
+func (r GoResizer) ResizeCrop(_ context.Context, srcPath, dstPath string, maxSize int, crop Crop) error {
+	img, _, err := Decode(srcPath, r.MaxPixels)
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return fmt.Errorf("não foi possível criar a miniatura '%s': %w", dstPath, err)
+	}
+	defer dst.Close()
+
+	if err := jpeg.Encode(dst, Resize(CropImage(img, crop), maxSize), &jpeg.Options{Quality: 80}); err != nil {
+		os.Remove(dstPath)
+		return fmt.Errorf("não foi possível gravar a miniatura: %w", err)
+	}
+	return nil
+}
+
+// VipsResizer executa o vipsthumbnail da libvips, que reduz a imagem durante a decodificação (shrink-on-load
+// do JPEG) e processa em faixas: é várias vezes mais rápido e usa uma fração da memória do GoResizer em
+// fotos grandes. Diferente do GoResizer, aplica a orientação EXIF e converte o perfil de cor para sRGB.
+type VipsResizer struct {
+	Binary  string        // Caminho do executável (ex: "vipsthumbnail" ou "/usr/bin/vipsthumbnail")
+	Timeout time.Duration // Tempo máximo por imagem; 0 = sem limite
+}
+
+// NewVipsResizer verifica se o binário existe e cria o VipsResizer.
+func NewVipsResizer(binary string, timeout time.Duration) (*VipsResizer, error) {
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return nil, fmt.Errorf("vipsthumbnail não encontrado ('%s'): %w", binary, err)
+	}
+	return &VipsResizer{Binary: path, Timeout: timeout}, nil
+}
+
+// Name retorna o nome da implementação.
+func (*VipsResizer) Name() string {
+	return ResizerVips
+}
+
+// Resize executa o vipsthumbnail. O sufixo ">" do tamanho evita ampliar imagens menores que maxSize, como
+// o GoResizer, e "strip" remove os metadados da miniatura.
+func (r *VipsResizer) Resize(ctx context.Context, srcPath, dstPath string, maxSize int) error {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	size := fmt.Sprintf("%dx%d>", maxSize, maxSize)
+	output, err := exec.CommandContext(ctx, r.Binary, srcPath, "--size", size, "-o", dstPath+"[Q=80,strip]").CombinedOutput()
+	if err != nil {
+		os.Remove(dstPath)
+		return fmt.Errorf("erro ao executar o vipsthumbnail: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ResizeCrop executa o vipsthumbnail com um tamanho na proporção crop.Aspect e --smartcrop centre, que
+// recorta o centro da imagem durante a redução. O vipsthumbnail só recorta ao centro: CenterX e Band são
+// ignorados, e a miniatura de uma foto 360° mostra a altura inteira.
+func (r *VipsResizer) ResizeCrop(ctx context.Context, srcPath, dstPath string, maxSize int, crop Crop) error {
+	if crop.Aspect <= 0 {
+		return r.Resize(ctx, srcPath, dstPath, maxSize)
+	}
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	width, height := maxSize, max(1, int(float64(maxSize)/crop.Aspect))
+	if crop.Aspect < 1 {
+		width, height = max(1, int(float64(maxSize)*crop.Aspect)), maxSize
+	}
+	size := fmt.Sprintf("%dx%d", width, height)
+	output, err := exec.CommandContext(ctx, r.Binary, srcPath, "--size", size, "--smartcrop", "centre", "-o", dstPath+"[Q=80,strip]").CombinedOutput()
+	if err != nil {
+		os.Remove(dstPath)
+		return fmt.Errorf("erro ao executar o vipsthumbnail: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// SelectResizer retorna o Resizer configurado (THUMBNAIL_RESIZER): nil para go, o padrão do Generator.
+// Se o vipsthumbnail não for encontrado, registra um aviso e segue com o decodificador Go.
+func SelectResizer(name, vipsBinary string) Resizer {
+	if name != ResizerVips {
+		return nil
+	}
+	resizer, err := NewVipsResizer(vipsBinary, vipsTimeout)
+	if err != nil {
+		log.Printf("Aviso: %v; as miniaturas serão geradas pelo decodificador Go\n", err)
+		return nil
+	}
+	log.Printf("Miniaturas geradas pelo vipsthumbnail (%s)\n", resizer.Binary)
+	return resizer
+}
+
+// vipsMaxSize é o maior lado aceito pelo vipsthumbnail; com o sufixo ">", imagens menores mantêm o tamanho.
+const vipsMaxSize = 16384
+
+// Convert executa o vipsthumbnail sem reduzir a imagem, aplicando a orientação EXIF e preservando os
+// metadados. O formato de saída segue a extensão de dstPath (.jpg ou .webp).
+func (r *VipsResizer) Convert(ctx context.Context, srcPath, dstPath string) error {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	size := fmt.Sprintf("%dx%d>", vipsMaxSize, vipsMaxSize)
+	output, err := exec.CommandContext(ctx, r.Binary, srcPath, "--size", size, "-o", dstPath+"[Q=85]").CombinedOutput()
+	if err != nil {
+		os.Remove(dstPath)
+		return fmt.Errorf("erro ao executar o vipsthumbnail: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
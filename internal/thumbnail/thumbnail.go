@@ -0,0 +1,351 @@
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// DefaultMaxSize é o tamanho padrão (em pixels) do maior lado das miniaturas.
+const DefaultMaxSize = 320
+
+// Políticas de geração das miniaturas.
+const (
+	PolicyLazy      = "lazy"      // Na primeira solicitação (padrão)
+	PolicyEager     = "eager"     // Logo após a ingestão, em segundo plano
+	PolicyScheduled = "scheduled" // Em lote, no horário programado (ex: madrugada); as demais sob demanda
+)
+
+// ValidPolicy indica se a política de geração é suportada.
+func ValidPolicy(policy string) bool {
+	return policy == PolicyLazy || policy == PolicyEager || policy == PolicyScheduled
+}
+
+// ErrInvalidImage indica que a imagem não pode ser processada: está corrompida, excede o limite de pixels
+// ou fez o decodificador entrar em pânico. Diferente de erros de E/S, repetir a operação não adianta.
+var ErrInvalidImage = errors.New("imagem inválida ou corrompida")
+
+// Generator gera e armazena miniaturas JPEG das fotos.
+type Generator struct {
+	Dir     string // Diretório onde as miniaturas são gravadas
+	MaxSize int    // Tamanho máximo do maior lado da miniatura
+
+	MaxPixels int64 // Máximo de pixels (largura x altura) decodificados; 0 = sem limite
+
+	// Resizer gera as miniaturas; nil usa o GoResizer. Se um Resizer externo falhar, a miniatura é gerada
+	// pelo GoResizer, para que uma instalação quebrada da ferramenta não deixe fotos sem miniatura.
+	Resizer Resizer
+}
+
+// NewGenerator cria uma nova instância de Generator.
+func NewGenerator(dir string, maxSize int) *Generator {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	return &Generator{
+		Dir:     dir,
+		MaxSize: maxSize,
+	}
+}
+
+// PathFor retorna o caminho da miniatura de uma foto.
+func (g *Generator) PathFor(photoID uint) string {
+	return filepath.Join(g.Dir, fmt.Sprintf("%d.jpg", photoID))
+}
+
+// PreviewPathFor retorna o caminho da prévia de uma foto: a miniatura embutida no EXIF pela câmera.
+func (g *Generator) PreviewPathFor(photoID uint) string {
+	return filepath.Join(g.Dir, "preview", fmt.Sprintf("%d.jpg", photoID))
+}
+
+// maxPreviewSize é o maior lado aceito em uma miniatura embutida; acima disso o conteúdo é suspeito.
+const maxPreviewSize = 1024
+
+// SavePreview grava a miniatura embutida no EXIF como prévia da foto e retorna o caminho gravado. O conteúdo
+// é conferido antes: precisa ser um JPEG com dimensões plausíveis.
+func (g *Generator) SavePreview(photoID uint, data []byte) (string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || format != "jpeg" {
+		return "", fmt.Errorf("%w: a miniatura embutida não é um JPEG válido", ErrInvalidImage)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > maxPreviewSize || cfg.Height > maxPreviewSize {
+		return "", fmt.Errorf("%w: a miniatura embutida tem dimensões inválidas (%dx%d)", ErrInvalidImage, cfg.Width, cfg.Height)
+	}
+
+	dstPath := g.PreviewPathFor(photoID)
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return "", fmt.Errorf("não foi possível criar o diretório de prévias '%s': %w", filepath.Dir(dstPath), err)
+	}
+	if err := os.WriteFile(dstPath, data, 0644); err != nil {
+		return "", fmt.Errorf("não foi possível gravar a prévia '%s': %w", dstPath, err)
+	}
+	return dstPath, nil
+}
+
+// Crop recorta a imagem antes de reduzi-la, para que a miniatura de um panorama não vire uma faixa estreita.
+type Crop struct {
+	Aspect  float64 // Proporção máxima (largura/altura) da miniatura; imagens mais largas são recortadas
+	CenterX float64 // Centro horizontal do recorte, de 0 a 1 (0,5 = meio da imagem)
+	Band    float64 // Fração da altura mantida, centralizada (ex: 0,5 evita os polos de uma foto 360°); 0 = inteira
+}
+
+// Generate cria a miniatura da imagem em srcPath para a foto informada e retorna o caminho gerado.
+// O cabeçalho é conferido antes contra MaxPixels (ver Decode), qualquer que seja o Resizer.
+func (g *Generator) Generate(srcPath string, photoID uint) (string, error) {
+	return g.GenerateCropped(srcPath, photoID, nil)
+}
+
+// GenerateCropped cria a miniatura como Generate, recortando antes a imagem conforme crop (nil = sem recorte).
+func (g *Generator) GenerateCropped(srcPath string, photoID uint, crop *Crop) (string, error) {
+	if err := os.MkdirAll(g.Dir, 0755); err != nil {
+		return "", fmt.Errorf("não foi possível criar o diretório de miniaturas '%s': %w", g.Dir, err)
+	}
+	dstPath := g.PathFor(photoID)
+	if err := g.resizeCropped(context.Background(), srcPath, dstPath, g.MaxSize, crop); err != nil {
+		return "", err
+	}
+	return dstPath, nil
+}
+
+// resize grava em dstPath a imagem de srcPath reduzida a maxSize, com o Resizer configurado e o GoResizer
+// como alternativa.
+func (g *Generator) resize(ctx context.Context, srcPath, dstPath string, maxSize int) error {
+	return g.resizeCropped(ctx, srcPath, dstPath, maxSize, nil)
+}
+
+// resizeCropped é o resize com recorte opcional. Resizers externos que não recortam (ver CropResizer)
+// deixam o recorte com o GoResizer.
+func (g *Generator) resizeCropped(ctx context.Context, srcPath, dstPath string, maxSize int, crop *Crop) error {
+	fallback := GoResizer{MaxPixels: g.MaxPixels}
+	run := func(r Resizer) error {
+		if crop == nil {
+			return r.Resize(ctx, srcPath, dstPath, maxSize)
+		}
+		return r.(CropResizer).ResizeCrop(ctx, srcPath, dstPath, maxSize, *crop)
+	}
+	if g.Resizer == nil || g.Resizer.Name() == ResizerGo {
+		return run(fallback)
+	}
+	if _, ok := g.Resizer.(CropResizer); crop != nil && !ok {
+		return run(fallback)
+	}
+
+	if err := checkPixels(srcPath, g.MaxPixels); err != nil {
+		return err
+	}
+	if err := run(g.Resizer); err != nil {
+		log.Printf("Aviso: falha ao redimensionar '%s' com %s, usando o decodificador Go: %v\n", filepath.Base(srcPath), g.Resizer.Name(), err)
+		return run(fallback)
+	}
+	return nil
+}
+
+// CropImage recorta img conforme crop: mantém a faixa central da altura (Band) e limita a largura a Aspect
+// vezes a altura resultante, centralizada em CenterX.
+func CropImage(img image.Image, crop Crop) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	cropH := height
+	if crop.Band > 0 && crop.Band < 1 {
+		cropH = max(1, int(float64(height)*crop.Band))
+	}
+	cropW := width
+	if crop.Aspect > 0 {
+		cropW = min(width, max(1, int(float64(cropH)*crop.Aspect)))
+	}
+	if cropW == width && cropH == height {
+		return img
+	}
+
+	centerX := crop.CenterX
+	if centerX <= 0 || centerX >= 1 {
+		centerX = 0.5
+	}
+	left := min(max(0, int(float64(width)*centerX)-cropW/2), width-cropW)
+	top := (height - cropH) / 2
+	rect := image.Rect(left, top, left+cropW, top+cropH).Add(bounds.Min)
+
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, cropW, cropH))
+	for y := 0; y < cropH; y++ {
+		for x := 0; x < cropW; x++ {
+			dst.Set(x, y, img.At(rect.Min.X+x, rect.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// checkPixels lê apenas o cabeçalho da imagem e recusa as que excedem maxPixels (0 = sem limite). Formatos
+// que a biblioteca padrão não reconhece seguem para o Resizer externo, que aplica os próprios limites.
+func checkPixels(path string, maxPixels int64) error {
+	if maxPixels <= 0 {
+		return nil
+	}
+	width, height, err := Dimensions(path)
+	if err != nil {
+		return nil
+	}
+	if pixels := int64(width) * int64(height); pixels > maxPixels {
+		return fmt.Errorf("%w: a imagem declara %d pixels, acima do limite de %d", ErrInvalidImage, pixels, maxPixels)
+	}
+	return nil
+}
+
+// Decode decodifica a imagem em path. Antes, o cabeçalho é lido com image.DecodeConfig para recusar imagens
+// acima de maxPixels (bombas de descompressão; 0 = sem limite). Pânicos do decodificador são convertidos em
+// ErrInvalidImage; formatos que só um conversor externo lê (HEIC, TIFF, RAW) retornam ErrUnsupportedFormat.
+// Retorna também o formato ("jpeg", "png").
+func Decode(path string, maxPixels int64) (img image.Image, format string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			img, format = nil, ""
+			err = fmt.Errorf("%w: pânico ao decodificar a imagem: %v", ErrInvalidImage, r)
+		}
+	}()
+
+	src, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("não foi possível abrir a imagem original: %w", err)
+	}
+	defer src.Close()
+
+	cfg, _, err := image.DecodeConfig(src)
+	if errors.Is(err, image.ErrFormat) && isContainerType(sniffFile(src)) {
+		return nil, "", ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: não foi possível ler o cabeçalho da imagem: %v", ErrInvalidImage, err)
+	}
+	if pixels := int64(cfg.Width) * int64(cfg.Height); maxPixels > 0 && pixels > maxPixels {
+		return nil, "", fmt.Errorf("%w: a imagem declara %d pixels, acima do limite de %d", ErrInvalidImage, pixels, maxPixels)
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, "", fmt.Errorf("não foi possível reler a imagem original: %w", err)
+	}
+
+	img, format, err = image.Decode(src)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: não foi possível decodificar a imagem: %v", ErrInvalidImage, err)
+	}
+	return img, format, nil
+}
+
+// Resize reduz a imagem para que o maior lado tenha no máximo maxSize pixels, preservando a proporção.
+// Usa média por área (box filter), adequada para reduções. Imagens menores são retornadas sem alteração.
+func Resize(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW <= maxSize && srcH <= maxSize {
+		return img
+	}
+
+	dstW, dstH := maxSize, maxSize
+	if srcW > srcH {
+		dstH = max(1, srcH*maxSize/srcW)
+	} else {
+		dstW = max(1, srcW*maxSize/srcH)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcW/dstW)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
+
+// Dimensions lê apenas o cabeçalho da imagem para obter largura e altura, sem decodificá-la inteira.
+// Também lê as dimensões de HEIC/HEIF e TIFF (incluindo RAW baseados em TIFF), que não são decodificados.
+func Dimensions(path string) (int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("não foi possível abrir a imagem: %w", err)
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	if errors.Is(err, image.ErrFormat) && isContainerType(sniffFile(f)) {
+		return containerDimensions(f)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("não foi possível ler as dimensões da imagem: %w", err)
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// Downscale grava em dstPath uma versão reduzida da imagem em srcPath com no máximo maxPixels pixels,
+// preservando a proporção e o formato (JPEG com qualidade 90 ou PNG). JPEGs com imagens secundárias (mapa
+// de ganho HDR, mapa de profundidade) mantêm as secundárias e os metadados (ver ResizeMultiPicture).
+// Retorna as novas dimensões.
+func Downscale(srcPath, dstPath string, maxPixels int64) (width, height int, err error) {
+	if srcWidth, srcHeight, err := Dimensions(srcPath); err == nil && srcWidth > 0 && srcHeight > 0 {
+		scale := math.Sqrt(float64(maxPixels) / float64(srcWidth*srcHeight))
+		maxSide := int(float64(max(srcWidth, srcHeight)) * scale)
+		if width, height, ok := resizeKeepingAuxiliary(srcPath, dstPath, max(1, maxSide), 0); ok {
+			return width, height, nil
+		}
+	}
+
+	img, format, err := Decode(srcPath, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Escala que leva a área da imagem a maxPixels, aplicada ao maior lado
+	bounds := img.Bounds()
+	scale := math.Sqrt(float64(maxPixels) / float64(bounds.Dx()*bounds.Dy()))
+	maxSide := int(float64(max(bounds.Dx(), bounds.Dy())) * scale)
+	resized := Resize(img, max(1, maxSide))
+
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("não foi possível criar a imagem reduzida '%s': %w", dstPath, err)
+	}
+	defer dst.Close()
+
+	if format == "png" {
+		err = png.Encode(dst, resized)
+	} else {
+		err = jpeg.Encode(dst, resized, &jpeg.Options{Quality: 90})
+	}
+	if err != nil {
+		os.Remove(dstPath)
+		return 0, 0, fmt.Errorf("não foi possível gravar a imagem reduzida: %w", err)
+	}
+
+	return resized.Bounds().Dx(), resized.Bounds().Dy(), nil
+}
@@ -0,0 +1,205 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limites do envio em lote.
+const (
+	exportBatchSize = 512             // Spans por requisição ao coletor
+	exportInterval  = 5 * time.Second // Intervalo máximo entre envios
+	exportQueueSize = 4096            // Spans aguardando envio; acima disso os novos são descartados
+)
+
+// Options configura o tracing.
+type Options struct {
+	Endpoint    string            // URL base do coletor OTLP/HTTP, ex: http://localhost:4318 (os spans vão para /v1/traces)
+	ServiceName string            // service.name dos spans
+	SampleRatio float64           // Fração dos traces registrados, de 0 a 1
+	Headers     map[string]string // Cabeçalhos extras do envio (ex: autenticação do coletor)
+}
+
+// Init ativa o tracing e inicia o envio periódico dos spans. A função retornada envia os spans pendentes e
+// encerra o envio (ex: no desligamento do servidor).
+func Init(opts Options) func(context.Context) {
+	e := &exporter{
+		url:     strings.TrimSuffix(opts.Endpoint, "/") + "/v1/traces",
+		service: opts.ServiceName,
+		headers: opts.Headers,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		queue:   make(chan *Span, exportQueueSize),
+		done:    make(chan struct{}),
+	}
+	tracer.Store(&Tracer{SampleRatio: opts.SampleRatio, exporter: e})
+	go e.run()
+	log.Printf("Tracing ativo: spans enviados para %s (amostragem %.0f%%)\n", e.url, opts.SampleRatio*100)
+
+	var once sync.Once
+	return func(ctx context.Context) {
+		once.Do(func() {
+			tracer.Store(nil)
+			close(e.queue)
+			select {
+			case <-e.done:
+			case <-ctx.Done():
+			}
+		})
+	}
+}
+
+// exporter acumula os spans encerrados e os envia ao coletor em lotes.
+type exporter struct {
+	url     string
+	service string
+	headers map[string]string
+	client  *http.Client
+	queue   chan *Span
+	done    chan struct{}
+	dropped int // Spans descartados desde o último aviso
+	mu      sync.Mutex
+}
+
+// add enfileira um span sem bloquear a requisição; com a fila cheia (coletor lento ou fora do ar), o span
+// é descartado.
+func (e *exporter) add(span *Span) {
+	defer func() { recover() }() // A fila pode ter sido fechada pelo desligamento
+	select {
+	case e.queue <- span:
+	default:
+		e.mu.Lock()
+		e.dropped++
+		e.mu.Unlock()
+	}
+}
+
+func (e *exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	var batch []*Span
+	for {
+		select {
+		case span, ok := <-e.queue:
+			if !ok {
+				e.send(batch)
+				return
+			}
+			if batch = append(batch, span); len(batch) >= exportBatchSize {
+				e.send(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			e.send(batch)
+			batch = nil
+		}
+	}
+}
+
+// send envia um lote ao coletor. Falhas são registradas no log e o lote é descartado: o tracing nunca
+// deve afetar a aplicação.
+func (e *exporter) send(batch []*Span) {
+	e.mu.Lock()
+	if e.dropped > 0 {
+		log.Printf("Aviso: %d span(s) descartado(s) com a fila de envio cheia\n", e.dropped)
+		e.dropped = 0
+	}
+	e.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(e.payload(batch))
+	if err != nil {
+		log.Printf("Erro ao serializar os spans: %v\n", err)
+		return
+	}
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("Erro ao enviar os spans: %v\n", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		log.Printf("Erro ao enviar %d span(s) para o coletor: %v\n", len(batch), err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("Coletor recusou %d span(s): HTTP %d\n", len(batch), resp.StatusCode)
+	}
+}
+
+// payload monta o ExportTraceServiceRequest do OTLP na codificação JSON.
+func (e *exporter) payload(batch []*Span) map[string]interface{} {
+	spans := make([]map[string]interface{}, 0, len(batch))
+	for _, span := range batch {
+		item := map[string]interface{}{
+			"traceId":           span.TraceID.String(),
+			"spanId":            span.SpanID.String(),
+			"name":              span.Name,
+			"kind":              span.Kind,
+			"startTimeUnixNano": strconv.FormatInt(span.Start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(span.End.UnixNano(), 10),
+			"attributes":        otlpAttributes(span.Attrs),
+		}
+		if span.ParentID != (SpanID{}) {
+			item["parentSpanId"] = span.ParentID.String()
+		}
+		if span.Err != "" {
+			item["status"] = map[string]interface{}{"code": 2, "message": span.Err} // STATUS_CODE_ERROR
+		}
+		spans = append(spans, item)
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": otlpAttributes([]Attr{String("service.name", e.service)}),
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]interface{}{"name": "photo-manager/internal/tracing"},
+				"spans": spans,
+			}},
+		}},
+	}
+}
+
+// otlpAttributes converte os atributos para o formato KeyValue do OTLP.
+func otlpAttributes(attrs []Attr) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(attrs))
+	for _, attr := range attrs {
+		var value map[string]interface{}
+		switch v := attr.Value.(type) {
+		case string:
+			value = map[string]interface{}{"stringValue": v}
+		case bool:
+			value = map[string]interface{}{"boolValue": v}
+		case int:
+			value = map[string]interface{}{"intValue": strconv.Itoa(v)}
+		case int64:
+			value = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+		case uint:
+			value = map[string]interface{}{"intValue": strconv.FormatUint(uint64(v), 10)}
+		case float64:
+			value = map[string]interface{}{"doubleValue": v}
+		default:
+			value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+		}
+		result = append(result, map[string]interface{}{"key": attr.Key, "value": value})
+	}
+	return result
+}
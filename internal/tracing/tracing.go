@@ -0,0 +1,187 @@
+// Package tracing registra spans no formato do OpenTelemetry e os exporta por OTLP/HTTP (JSON) para um
+// coletor como Jaeger, Tempo ou o OpenTelemetry Collector. A propagação entre serviços usa o cabeçalho
+// W3C traceparent. Sem Init, Start não registra nada e os spans são nil (todos os métodos aceitam nil).
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Tipos de span (SpanKind do OpenTelemetry).
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+// TraceID identifica um trace; SpanID, um span dentro dele.
+type (
+	TraceID [16]byte
+	SpanID  [8]byte
+)
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (s SpanID) String() string  { return hex.EncodeToString(s[:]) }
+
+// Attr é um atributo de um span. Value aceita string, bool, inteiros e float64.
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+// String, Int e Bool criam atributos.
+func String(key, value string) Attr    { return Attr{key, value} }
+func Int(key string, value int64) Attr { return Attr{key, value} }
+func Bool(key string, value bool) Attr { return Attr{key, value} }
+
+// Span é uma operação cronometrada de um trace.
+type Span struct {
+	TraceID  TraceID
+	SpanID   SpanID
+	ParentID SpanID // Zero na raiz do trace
+	Name     string
+	Kind     int
+	Start    time.Time
+	End      time.Time
+	Attrs    []Attr
+	Err      string // Mensagem do erro registrado (vazio = sucesso)
+	sampled  bool
+	ended    atomic.Bool
+}
+
+type spanKey struct{}
+
+// tracer é a configuração ativa, definida por Init.
+var tracer atomic.Pointer[Tracer]
+
+// Tracer decide a amostragem e entrega os spans encerrados ao exportador.
+type Tracer struct {
+	SampleRatio float64 // Fração dos traces registrados, de 0 a 1
+	exporter    *exporter
+}
+
+// Enabled indica se o tracing foi configurado.
+func Enabled() bool {
+	return tracer.Load() != nil
+}
+
+// Start inicia um span filho do span em ctx (ou a raiz de um novo trace) e retorna o contexto que o carrega.
+// Com o tracing desativado, retorna ctx e nil.
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	return StartKind(ctx, name, KindInternal, attrs...)
+}
+
+// StartKind funciona como Start, com o tipo de span informado.
+func StartKind(ctx context.Context, name string, kind int, attrs ...Attr) (context.Context, *Span) {
+	t := tracer.Load()
+	if t == nil {
+		return ctx, nil
+	}
+
+	span := &Span{Name: name, Kind: kind, Start: time.Now(), Attrs: attrs}
+	if parent := FromContext(ctx); parent != nil {
+		span.TraceID, span.ParentID, span.sampled = parent.TraceID, parent.SpanID, parent.sampled
+	} else {
+		rand.Read(span.TraceID[:])
+		span.sampled = t.sample(span.TraceID)
+	}
+	rand.Read(span.SpanID[:])
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// StartRemote inicia um span servidor continuando o trace do cabeçalho traceparent recebido, se válido.
+func StartRemote(ctx context.Context, traceparent, name string, attrs ...Attr) (context.Context, *Span) {
+	if remote, ok := parseTraceparent(traceparent); ok && Enabled() {
+		ctx = context.WithValue(ctx, spanKey{}, remote)
+	}
+	return StartKind(ctx, name, KindServer, attrs...)
+}
+
+// FromContext retorna o span ativo em ctx (nil se não houver).
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// TraceIDFromContext retorna o ID do trace ativo em ctx, em hexadecimal (vazio se não houver).
+func TraceIDFromContext(ctx context.Context) string {
+	if span := FromContext(ctx); span != nil {
+		return span.TraceID.String()
+	}
+	return ""
+}
+
+// SetAttr acrescenta atributos ao span.
+func (s *Span) SetAttr(attrs ...Attr) {
+	if s != nil {
+		s.Attrs = append(s.Attrs, attrs...)
+	}
+}
+
+// RecordError marca o span como falho. err nil não tem efeito.
+func (s *Span) RecordError(err error) {
+	if s != nil && err != nil {
+		s.Err = err.Error()
+	}
+}
+
+// Finish encerra o span, registrando err (se houver), e o entrega ao exportador se o trace foi amostrado.
+// Chamadas repetidas não têm efeito.
+func (s *Span) Finish(err error) {
+	if s == nil || s.ended.Swap(true) {
+		return
+	}
+	s.RecordError(err)
+	s.End = time.Now()
+	if t := tracer.Load(); t != nil && s.sampled {
+		t.exporter.add(s)
+	}
+}
+
+// Traceparent retorna o cabeçalho W3C traceparent do span, para propagar o trace a outro serviço.
+func (s *Span) Traceparent() string {
+	if s == nil {
+		return ""
+	}
+	flags := "00"
+	if s.sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", s.TraceID, s.SpanID, flags)
+}
+
+// sample decide a amostragem pelo próprio ID do trace, como o TraceIdRatioBased do OpenTelemetry.
+func (t *Tracer) sample(id TraceID) bool {
+	if t.SampleRatio >= 1 {
+		return true
+	}
+	return float64(binary.BigEndian.Uint64(id[8:])>>1) < t.SampleRatio*float64(uint64(1)<<63)
+}
+
+// parseTraceparent lê um cabeçalho traceparent (versão 00) e retorna o span remoto que ele representa.
+func parseTraceparent(header string) (*Span, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return nil, false
+	}
+	span := &Span{}
+	if _, err := hex.Decode(span.TraceID[:], []byte(parts[1])); err != nil || span.TraceID == (TraceID{}) {
+		return nil, false
+	}
+	if _, err := hex.Decode(span.SpanID[:], []byte(parts[2])); err != nil || span.SpanID == (SpanID{}) {
+		return nil, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return nil, false
+	}
+	span.sampled = flags[0]&1 == 1
+	return span, true
+}
@@ -0,0 +1,127 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"photo-manager/internal/i18n"
+)
+
+// Códigos das falhas de validação, retornados ao cliente junto com a mensagem. As mensagens de cada
+// idioma ficam no catálogo do pacote i18n.
+const (
+	CodeFileTooLarge       = i18n.CodeFileTooLarge       // Arquivo maior que o limite configurado
+	CodeUnsupportedType    = i18n.CodeUnsupportedType    // Tipo detectado pelo conteúdo não é permitido
+	CodeUnreadableImage    = i18n.CodeUnreadableImage    // Cabeçalho da imagem não pôde ser lido
+	CodeDimensionsTooLarge = i18n.CodeDimensionsTooLarge // Largura ou altura acima do limite
+	CodeDecompressionBomb  = i18n.CodeDecompressionBomb  // Quantidade de pixels desproporcional (ex: PNG pequeno com dimensões gigantes)
+	CodeMalwareDetected    = i18n.CodeMalwareDetected    // O antivírus externo rejeitou o arquivo
+	CodeChecksumMismatch   = i18n.CodeChecksumMismatch   // O SHA-256 do conteúdo recebido difere do informado pelo cliente
+	CodeHookRejected       = i18n.CodeHookRejected       // O hook de pré-ingestão rejeitou o arquivo
+	CodeHookFailed         = i18n.CodeHookFailed         // O hook de pré-ingestão falhou e a política é rejeitar
+)
+
+// File descreve um arquivo local a ser validado antes da ingestão.
+type File struct {
+	Path     string // Caminho local do arquivo (temporário, no caso de uploads)
+	Filename string // Nome original do arquivo
+	Size     int64  // Tamanho em bytes
+	MimeType string // Tipo MIME detectado pelo conteúdo; preenchido pelo validador de tipos
+	Width    int    // Largura lida do cabeçalho; preenchida pelo validador de dimensões
+	Height   int    // Altura lida do cabeçalho; preenchida pelo validador de dimensões
+}
+
+// Error é a rejeição de um arquivo por um validador.
+// Erros que não são *Error indicam falhas de infraestrutura (ex: antivírus indisponível), não do arquivo.
+type Error struct {
+	Validator string        // Nome do validador que rejeitou o arquivo
+	Code      string        // Código da falha (ver constantes Code*)
+	Message   string        // Descrição legível da falha, no idioma padrão
+	Args      []interface{} // Valores da mensagem, usados ao traduzi-la
+}
+
+// NewError cria a rejeição de um arquivo, com a mensagem do código no idioma padrão.
+func NewError(validator, code string, args ...interface{}) *Error {
+	return &Error{Validator: validator, Code: code, Message: i18n.Message(i18n.DefaultLocale, code, args...), Args: args}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// MessageCode retorna o código da falha.
+func (e *Error) MessageCode() string {
+	return e.Code
+}
+
+// Localize retorna a mensagem da falha no idioma informado.
+func (e *Error) Localize(locale string) string {
+	return i18n.Message(locale, e.Code, e.Args...)
+}
+
+// AsError retorna o *Error contido em err, se houver.
+func AsError(err error) (*Error, bool) {
+	var validationErr *Error
+	if errors.As(err, &validationErr) {
+		return validationErr, true
+	}
+	return nil, false
+}
+
+// Validator verifica um arquivo. Retorna *Error quando o arquivo é rejeitado.
+type Validator interface {
+	Name() string
+	Validate(ctx context.Context, f *File) error
+}
+
+// Chain executa validadores em ordem, parando na primeira falha.
+type Chain []Validator
+
+// Validate executa a cadeia sobre o arquivo.
+func (c Chain) Validate(ctx context.Context, f *File) error {
+	for _, v := range c {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := v.Validate(ctx, f); err != nil {
+			if _, ok := AsError(err); ok {
+				return err
+			}
+			return fmt.Errorf("falha no validador '%s': %w", v.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Options reúne os limites da cadeia de validação padrão.
+type Options struct {
+	MaxFileSize  int64    // Tamanho máximo em bytes (0 = sem limite)
+	AllowedTypes []string // Tipos MIME aceitos, detectados pelo conteúdo
+	MaxDimension int      // Maior largura/altura aceita, em pixels (0 = sem limite)
+	MaxPixels    int64    // Máximo de pixels (largura x altura) aceito (0 = sem limite)
+	ScanCommand  []string // Comando de antivírus; o caminho do arquivo é acrescentado ao final (vazio = desativado)
+}
+
+// DefaultOptions retorna os limites padrão: JPG/PNG de até 10MB, 20000px por lado e 100 megapixels.
+func DefaultOptions() Options {
+	return Options{
+		MaxFileSize:  10 << 20,
+		AllowedTypes: []string{"image/jpeg", "image/png"},
+		MaxDimension: 20000,
+		MaxPixels:    100_000_000,
+	}
+}
+
+// NewChain monta a cadeia de validação padrão a partir das opções:
+// tamanho, tipo pelo conteúdo, dimensões, proteção contra bombas de descompressão e antivírus.
+func NewChain(opts Options) Chain {
+	chain := Chain{
+		MaxSize(opts.MaxFileSize),
+		AllowedTypes(opts.AllowedTypes...),
+		ImageLimits(opts.MaxDimension, opts.MaxPixels),
+	}
+	if len(opts.ScanCommand) > 0 {
+		chain = append(chain, CommandScanner(opts.ScanCommand...))
+	}
+	return chain
+}
@@ -0,0 +1,116 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	_ "image/jpeg" // Registra o decodificador JPEG
+	_ "image/png"  // Registra o decodificador PNG
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"photo-manager/internal/thumbnail"
+)
+
+// validatorFunc adapta uma função ao Validator.
+type validatorFunc struct {
+	name string
+	fn   func(ctx context.Context, f *File) error
+}
+
+func (v validatorFunc) Name() string { return v.name }
+
+func (v validatorFunc) Validate(ctx context.Context, f *File) error { return v.fn(ctx, f) }
+
+// MaxSize rejeita arquivos maiores que maxBytes (0 desativa o limite).
+func MaxSize(maxBytes int64) Validator {
+	return validatorFunc{name: "size", fn: func(_ context.Context, f *File) error {
+		if maxBytes > 0 && f.Size > maxBytes {
+			return NewError("size", CodeFileTooLarge, maxBytes>>20)
+		}
+		return nil
+	}}
+}
+
+// AllowedTypes identifica o tipo MIME pelos primeiros bytes do arquivo (ignorando o Content-Type
+// informado pelo cliente) e rejeita tipos fora da lista. Preenche File.MimeType.
+func AllowedTypes(types ...string) Validator {
+	return validatorFunc{name: "mime", fn: func(_ context.Context, f *File) error {
+		mimeType, err := sniffMimeType(f.Path)
+		if err != nil {
+			return err
+		}
+		f.MimeType = mimeType
+
+		for _, allowed := range types {
+			if mimeType == allowed {
+				return nil
+			}
+		}
+		return NewError("mime", CodeUnsupportedType, mimeType, strings.Join(types, ", "))
+	}}
+}
+
+// ImageLimits lê apenas o cabeçalho da imagem e rejeita larguras/alturas acima de maxDimension e imagens
+// com mais de maxPixels pixels. O limite de pixels protege contra bombas de descompressão: arquivos
+// pequenos que declaram dimensões enormes e consumiriam gigabytes de memória ao serem decodificados.
+// Preenche File.Width e File.Height. Vídeos (File.MimeType video/*) não são verificados.
+func ImageLimits(maxDimension int, maxPixels int64) Validator {
+	return validatorFunc{name: "image", fn: func(_ context.Context, f *File) error {
+		if strings.HasPrefix(f.MimeType, "video/") {
+			return nil
+		}
+
+		width, height, err := thumbnail.Dimensions(f.Path)
+		if err != nil {
+			return NewError("image", CodeUnreadableImage, err)
+		}
+		f.Width, f.Height = width, height
+
+		if maxDimension > 0 && (width > maxDimension || height > maxDimension) {
+			return NewError("image", CodeDimensionsTooLarge, width, height, maxDimension)
+		}
+		if pixels := int64(width) * int64(height); maxPixels > 0 && pixels > maxPixels {
+			return NewError("image", CodeDecompressionBomb, pixels, maxPixels)
+		}
+		return nil
+	}}
+}
+
+// CommandScanner executa um antivírus externo (ex: "clamdscan --no-summary") com o caminho do arquivo
+// como último argumento. Segue a convenção do ClamAV: saída 0 = limpo, 1 = infectado; qualquer outro
+// resultado é tratado como falha do antivírus, e não do arquivo.
+func CommandScanner(command ...string) Validator {
+	return validatorFunc{name: "scan", fn: func(ctx context.Context, f *File) error {
+		args := append(append([]string{}, command[1:]...), f.Path)
+		output, err := exec.CommandContext(ctx, command[0], args...).CombinedOutput()
+		if err == nil {
+			return nil
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return NewError("scan", CodeMalwareDetected, strings.TrimSpace(string(output)))
+		}
+		return fmt.Errorf("erro ao executar o antivírus: %w", err)
+	}}
+}
+
+// sniffMimeType identifica o tipo MIME de um arquivo a partir dos seus primeiros bytes (incluindo HEIC,
+// TIFF e RAW, ver thumbnail.DetectContentType).
+func sniffMimeType(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("não foi possível abrir o arquivo para detectar o tipo: %w", err)
+	}
+	defer file.Close()
+
+	buf := make([]byte, 512)
+	n, err := file.Read(buf)
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("não foi possível ler o arquivo para detectar o tipo: %w", err)
+	}
+	return thumbnail.DetectContentType(buf[:n]), nil
+}
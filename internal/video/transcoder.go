@@ -0,0 +1,208 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PlaylistName é o nome da playlist HLS gerada para cada vídeo.
+const PlaylistName = "index.m3u8"
+
+// ErrInvalidSegment indica um nome de arquivo HLS inválido (ex: tentativa de sair do diretório do cache).
+var ErrInvalidSegment = errors.New("segmento HLS inválido")
+
+// browserCompatibleTypes são os contêineres reproduzidos diretamente pelos navegadores, sem transcodificação.
+var browserCompatibleTypes = map[string]bool{
+	"video/mp4":  true,
+	"video/webm": true,
+}
+
+// IsVideo indica se o tipo MIME é de vídeo.
+func IsVideo(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "video/")
+}
+
+// IsBrowserCompatible indica se o vídeo pode ser servido diretamente ao navegador.
+// Apenas o contêiner é verificado; codecs incompatíveis (ex: HEVC em MP4) exigem ?format=hls.
+func IsBrowserCompatible(mimeType string) bool {
+	return browserCompatibleTypes[mimeType]
+}
+
+// Runner executa o ffmpeg com os argumentos informados. Permite trocar a execução local por
+// outra implementação (ex: um worker remoto ou um binário em contêiner).
+type Runner interface {
+	Run(ctx context.Context, args ...string) error
+}
+
+// FFmpegRunner executa o binário do ffmpeg localmente.
+type FFmpegRunner struct {
+	Binary string // Caminho do executável (ex: "ffmpeg" ou "/usr/bin/ffmpeg")
+}
+
+// Run executa o ffmpeg, incluindo a saída de erro na mensagem em caso de falha.
+func (r FFmpegRunner) Run(ctx context.Context, args ...string) error {
+	output, err := exec.CommandContext(ctx, r.Binary, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("erro ao executar o ffmpeg: %w: %s", err, lastLine(string(output)))
+	}
+	return nil
+}
+
+// PendingRetryAfter é o intervalo sugerido ao cliente para tentar de novo enquanto o vídeo é transcodificado.
+const PendingRetryAfter = 5 * time.Second
+
+// pendingWait é quanto a primeira solicitação aguarda a transcodificação antes de receber ErrTranscodePending:
+// o suficiente para vídeos curtos e para falhas imediatas (ex: arquivo corrompido).
+const pendingWait = 2 * time.Second
+
+// ErrTranscodePending indica que o vídeo está sendo transcodificado (ou aguardando sua vez); a playlist fica
+// disponível quando a transcodificação terminar.
+var ErrTranscodePending = errors.New("transcodificação do vídeo em andamento")
+
+// Transcoder converte vídeos para H.264/HLS e mantém os segmentos gerados em cache.
+// Cada versão do arquivo (identificada pelo hash) é transcodificada uma única vez, mesmo com
+// requisições simultâneas. A transcodificação roda à parte das requisições, então continua mesmo se o
+// cliente desistir de esperar, e no máximo Workers vídeos são transcodificados ao mesmo tempo.
+type Transcoder struct {
+	Runner   Runner
+	CacheDir string // Diretório onde as playlists e os segmentos são gravados
+
+	slots chan struct{} // Um token por transcodificação em execução
+
+	mu       sync.Mutex
+	jobs     map[string]*transcodeJob // Transcodificações em andamento ou aguardando vez, por vídeo
+	failures map[string]error         // Falha da última transcodificação de cada vídeo, entregue uma única vez
+}
+
+// transcodeJob é uma transcodificação em andamento, compartilhada pelas requisições do mesmo vídeo.
+type transcodeJob struct {
+	done chan struct{}
+	err  error
+}
+
+// NewTranscoder cria uma nova instância de Transcoder com até workers transcodificações simultâneas
+// (mínimo 1).
+func NewTranscoder(runner Runner, cacheDir string, workers int) *Transcoder {
+	return &Transcoder{
+		Runner:   runner,
+		CacheDir: cacheDir,
+		slots:    make(chan struct{}, max(1, workers)),
+		jobs:     make(map[string]*transcodeJob),
+		failures: make(map[string]error),
+	}
+}
+
+// HLS retorna o diretório com a playlist HLS do vídeo. Na primeira solicitação, inicia a transcodificação em
+// segundo plano e aguarda brevemente; se ela não terminar, retorna ErrTranscodePending, e as solicitações
+// seguintes acompanham a mesma transcodificação até a playlist ficar pronta. Uma falha é retornada à
+// solicitação seguinte, e a próxima tenta de novo.
+// key identifica a versão do arquivo (ex: ID da foto e hash), para que alterações no original gerem um novo cache.
+func (t *Transcoder) HLS(ctx context.Context, key, srcPath string) (string, error) {
+	dir := filepath.Join(t.CacheDir, key)
+	if _, err := os.Stat(filepath.Join(dir, PlaylistName)); err == nil {
+		return dir, nil
+	}
+
+	t.mu.Lock()
+	job, ok := t.jobs[key]
+	if !ok {
+		if err, failed := t.failures[key]; failed {
+			delete(t.failures, key)
+			t.mu.Unlock()
+			return "", err
+		}
+		// Outra solicitação pode ter concluído a transcodificação depois da verificação acima
+		if _, err := os.Stat(filepath.Join(dir, PlaylistName)); err == nil {
+			t.mu.Unlock()
+			return dir, nil
+		}
+		job = &transcodeJob{done: make(chan struct{})}
+		t.jobs[key] = job
+		go t.run(context.WithoutCancel(ctx), key, srcPath, dir, job)
+	}
+	t.mu.Unlock()
+
+	timer := time.NewTimer(pendingWait)
+	defer timer.Stop()
+	select {
+	case <-job.done:
+		if job.err != nil {
+			t.mu.Lock()
+			delete(t.failures, key) // Já entregue a esta solicitação
+			t.mu.Unlock()
+			return "", job.err
+		}
+		return dir, nil
+	case <-timer.C:
+		return "", ErrTranscodePending
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// run aguarda uma vaga e transcodifica o vídeo, removendo o registro da transcodificação ao terminar.
+func (t *Transcoder) run(ctx context.Context, key, srcPath, dir string, job *transcodeJob) {
+	t.slots <- struct{}{}
+	job.err = t.transcode(ctx, key, srcPath, dir)
+	<-t.slots
+
+	t.mu.Lock()
+	delete(t.jobs, key)
+	if job.err != nil {
+		t.failures[key] = job.err
+	}
+	t.mu.Unlock()
+	close(job.done)
+}
+
+// transcode converte o vídeo para HLS no diretório dir.
+func (t *Transcoder) transcode(ctx context.Context, key, srcPath, dir string) error {
+	if err := os.MkdirAll(t.CacheDir, 0755); err != nil {
+		return fmt.Errorf("não foi possível criar o diretório de cache de vídeos: %w", err)
+	}
+	// Transcodifica em um diretório temporário e renomeia ao final: um processo interrompido
+	// nunca deixa uma playlist incompleta no cache
+	tmpDir, err := os.MkdirTemp(t.CacheDir, key+".tmp-")
+	if err != nil {
+		return fmt.Errorf("não foi possível criar o diretório temporário de transcodificação: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	err = t.Runner.Run(ctx,
+		"-nostdin", "-y", "-i", srcPath,
+		"-c:v", "libx264", "-preset", "veryfast", "-pix_fmt", "yuv420p",
+		"-c:a", "aac",
+		"-f", "hls", "-hls_time", "6", "-hls_playlist_type", "vod",
+		"-hls_segment_filename", filepath.Join(tmpDir, "seg%05d.ts"),
+		filepath.Join(tmpDir, PlaylistName),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpDir, dir); err != nil {
+		return fmt.Errorf("não foi possível mover o vídeo transcodificado para o cache: %w", err)
+	}
+	return nil
+}
+
+// FilePath retorna o caminho de um arquivo (playlist ou segmento) dentro do diretório HLS.
+func FilePath(dir, name string) (string, error) {
+	if name != filepath.Base(name) || (name != PlaylistName && !strings.HasSuffix(name, ".ts")) {
+		return "", ErrInvalidSegment
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// lastLine retorna a última linha não vazia da saída do ffmpeg, que normalmente contém o erro.
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}